@@ -0,0 +1,74 @@
+package aws
+
+import (
+	"fmt"
+	"strings"
+)
+
+// forAccountSession is the role session name of ForAccount shown in
+// CloudTrail.
+const forAccountSession = "aws-for-account"
+
+// accountRole is the ForAccount cache key.
+type accountRole struct {
+	accountID string
+	roleName  string
+	opts      STSAssumeRoleOptions
+}
+
+// ForAccount returns the Aws which clients operate in other account with the
+// credentials of the role assumed in the account. The Aws are cached by the
+// account, role and options, and their temporary credentials are refreshed
+// automatically before they expire, so ForAccount may be called for every
+// request of the multi-account service:
+//
+//	tenant, err := a.ForAccount("123456789012", "TenantAccess",
+//		aws.STSAssumeRoleOptions{ExternalID: tenantExternalID})
+//	if err != nil {
+//		return err
+//	}
+//	data, err := tenant.S3.Get("tenant-data", key)
+//
+// The returned Aws ForAccount assumes the role with the account credentials,
+// so the roles may be chained. The role chaining limits the role session
+// duration to one hour.
+//
+// Parameters:
+//   - accountID: The 12 digits AWS account ID.
+//   - roleName: The role name in the account, the role path may be included,
+//     for example "service/TenantAccess".
+//   - opts: The optional assume role parameters. The MFA options can't be
+//     used.
+//
+// Returns:
+//   - accAws: The Aws with the role credentials.
+//   - err: An error if the operation fails.
+func (a Aws) ForAccount(accountID, roleName string,
+	opts ...STSAssumeRoleOptions) (accAws *Aws, err error) {
+
+	key := accountRole{accountID: accountID, roleName: roleName}
+	if len(opts) > 0 {
+		key.opts = opts[0]
+	}
+	return a.accounts.Get(key)
+}
+
+// forAccount assumes the role in the account and returns the Aws with the
+// role credentials.
+func (a *Aws) forAccount(key accountRole) (accAws *Aws, err error) {
+	roleArn := fmt.Sprintf("arn:%s:iam::%s:role/%s",
+		regionPartition(a.cfg.Region), key.accountID,
+		strings.TrimPrefix(key.roleName, "/"))
+	return a.STS.AssumeRoleAws(roleArn, forAccountSession, key.opts)
+}
+
+// regionPartition returns the AWS partition of the region.
+func regionPartition(region string) string {
+	switch {
+	case strings.HasPrefix(region, "cn-"):
+		return "aws-cn"
+	case strings.HasPrefix(region, "us-gov-"):
+		return "aws-us-gov"
+	}
+	return "aws"
+}
@@ -0,0 +1,67 @@
+package aws
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// TestForAccount checks the account Aws is cached, its credentials are
+// refreshed and the roles may be chained
+func TestForAccount(t *testing.T) {
+
+	creds := func(key string, expiration time.Time) string {
+		return `<AssumeRoleResponse><AssumeRoleResult><Credentials>` +
+			`<AccessKeyId>` + key + `</AccessKeyId>` +
+			`<SecretAccessKey>SK</SecretAccessKey>` +
+			`<SessionToken>ST</SessionToken><Expiration>` +
+			expiration.UTC().Format(time.RFC3339) + `</Expiration>` +
+			`</Credentials></AssumeRoleResult></AssumeRoleResponse>`
+	}
+	client := &pagesHTTPClient{bodies: []string{
+		creds("AK1", time.Now().Add(time.Minute)),
+		creds("AK2", time.Now().Add(time.Hour)),
+		creds("AK3", time.Now().Add(time.Hour)),
+	}}
+	a := newPagesTestAws(client)
+
+	// Cached account Aws
+	opts := STSAssumeRoleOptions{ExternalID: "tenant"}
+	acc, err := a.ForAccount("222", "Admin", opts)
+	if err != nil {
+		t.Fatal("for account:", err)
+	}
+	if cached, _ := a.ForAccount("222", "Admin", opts); cached != acc ||
+		len(client.requests) != 1 {
+		t.Error("account Aws is not cached:", len(client.requests))
+	}
+	values, _ := url.ParseQuery(client.requests[0])
+	if values.Get("RoleArn") != "arn:aws:iam::222:role/Admin" ||
+		values.Get("ExternalId") != "tenant" ||
+		values.Get("RoleSessionName") != forAccountSession {
+		t.Error("wrong assume role request:", values)
+	}
+
+	// The credentials are refreshed before they expire
+	ctx := context.Background()
+	for _, key := range []string{"AK1", "AK2", "AK2"} {
+		c, err := acc.Config().Credentials.Retrieve(ctx)
+		if err != nil || c.AccessKeyID != key {
+			t.Fatal("wrong credentials:", c.AccessKeyID, "want", key, err)
+		}
+	}
+
+	// Role chaining
+	if _, err = acc.ForAccount("333", "service/Next"); err != nil {
+		t.Fatal("chained account:", err)
+	}
+	values, _ = url.ParseQuery(client.requests[2])
+	if values.Get("RoleArn") != "arn:aws:iam::333:role/service/Next" {
+		t.Error("wrong chained role:", values)
+	}
+
+	if p := regionPartition("cn-north-1"); p != "aws-cn" {
+		t.Error("wrong china partition:", p)
+	}
+}
@@ -2,7 +2,12 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
-// Helper golang package to easy execute Lambda, S3 and Cognito AWS SDK functions.
+// Helper golang package to easy execute Lambda, S3, S3 Batch Operations,
+// Cognito, DynamoDB, SQS, SNS, SES, EventBridge, Kinesis, Firehose, Step
+// Functions, Secrets Manager, SSM Parameter Store, KMS, STS, IAM, ECR, ECS,
+// EC2, Route 53, API Gateway WebSocket, AppConfig, Bedrock, Rekognition,
+// Textract, Polly, Cost Explorer, Organizations, Timestream, CloudWatch and
+// CloudWatch Logs AWS SDK functions.
 package aws
 
 import (
@@ -10,25 +15,93 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/appconfigdata"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentity"
 	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodbstreams"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
+	"github.com/aws/aws-sdk-go-v2/service/firehose"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/kinesis"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
 	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/organizations"
+	"github.com/aws/aws-sdk-go-v2/service/polly"
+	"github.com/aws/aws-sdk-go-v2/service/rekognition"
+	"github.com/aws/aws-sdk-go-v2/service/route53"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3control"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2"
+	"github.com/aws/aws-sdk-go-v2/service/sfn"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/aws-sdk-go-v2/service/textract"
+	"github.com/aws/aws-sdk-go-v2/service/timestreamwrite"
 	"github.com/aws/smithy-go"
 )
 
 // Aws methods receiver and data structure
 type Aws struct {
-	S3      awsS3
-	Lambda  awsLambda
-	Cognito awsCognito
+	S3              awsS3
+	Lambda          awsLambda
+	Cognito         awsCognito
+	CognitoIdentity awsCognitoIdentity
+	Dynamo          awsDynamo
+	DynamoStreams   awsDynamoStreams
+	SQS             awsSQS
+	SNS             awsSNS
+	SES             awsSES
+	Secrets         awsSecrets
+	SSM             awsSSM
+	KMS             awsKMS
+	Logs            awsLogs
+	CloudWatch      awsCloudWatch
+	Events          awsEvents
+	Kinesis         awsKinesis
+	Firehose        awsFirehose
+	SFN             awsSFN
+	STS             awsSTS
+	IAM             awsIAM
+	ECR             awsECR
+	Route53         awsRoute53
+	S3Batch         awsS3Batch
+	EC2             awsEC2
+	ECS             awsECS
+	AppConfig       awsAppConfig
+	Bedrock         awsBedrock
+	Rekognition     awsRekognition
+	Textract        awsTextract
+	Polly           awsPolly
+	Cost            awsCost
+	Orgs            awsOrgs
+	Timestream      awsTimestream
+
+	// cfg is the AWS config used to create clients
+	cfg aws.Config
+
+	// opts are the options used to create clients
+	opts []Option
+
+	// accounts caches the Aws of the roles assumed by ForAccount
+	accounts *LookupCache[accountRole, *Aws]
 }
 
 // New creates AWS S3 and Lambda clients
 func New(region ...string) (a *Aws, err error) {
 
-	a = new(Aws)
-
 	// Load AWS config
 	ctx := context.TODO()
 	cfg, err := config.LoadDefaultConfig(ctx)
@@ -42,25 +115,200 @@ func New(region ...string) (a *Aws, err error) {
 		cfg.Region = region[0]
 	}
 
+	a = NewFromConfig(cfg)
+	return
+}
+
+// NewFromConfig creates AWS clients from the AWS config. Use it to create
+// clients with credentials or options which are not loaded by New.
+//
+// Parameters:
+//   - cfg: The AWS config.
+//   - opts: The Aws options, for example WithErrorHook.
+func NewFromConfig(cfg aws.Config, opts ...Option) (a *Aws) {
+
+	a = new(Aws)
+	a.cfg = cfg
+	a.opts = opts
+	a.accounts = NewLookupCache(a.forAccount, nil)
+
+	// Translate service errors to the package sentinel errors, trace the
+	// calls with X-Ray, reject the calls of the services with the open
+	// circuits, skip the destructive calls in dry-run mode, inject the
+	// faults, log the payloads and record or replay the HTTP requests
+	o := newOptions(opts...)
+	ctx := withDryRunMode(withClock(context.TODO(), o.clock), o)
+	cfg = withErrorTranslation(cfg, o)
+	cfg = withXRay(cfg, o)
+	cfg = withCircuitBreaker(cfg, o)
+	cfg = withDryRun(cfg, o)
+	cfg = withFaultInjection(cfg, o)
+	cfg = withPayloadLogging(cfg, o)
+	cfg = withVCR(cfg, o)
+
 	// Create new Lambda client
 	a.Lambda.ctx = ctx
 	a.Lambda.Client = lambda.NewFromConfig(cfg)
+	a.Lambda.Invoker = a.Lambda.Client
 
 	// Create new S3 client
 	a.S3.ctx = ctx
 	a.S3.Client = s3.NewFromConfig(cfg)
+	a.S3.Cache = newS3Cache(&a.S3)
+	a.S3.kms = &a.KMS
 
 	// Create new Cognito client
 	a.Cognito.ctx = ctx
 	a.Cognito.Cache.init(&a.Cognito)
 	a.Cognito.Client = cognitoidentityprovider.NewFromConfig(cfg)
 
+	// Create new Cognito Identity client
+	a.CognitoIdentity.ctx = ctx
+	a.CognitoIdentity.cfg = a.cfg
+	a.CognitoIdentity.opts = opts
+	a.CognitoIdentity.Client = cognitoidentity.NewFromConfig(cfg)
+
+	// Create new DynamoDB client
+	a.Dynamo.ctx = ctx
+	a.Dynamo.Client = dynamodb.NewFromConfig(cfg)
+
+	// Create new DynamoDB Streams client
+	a.DynamoStreams.ctx = ctx
+	a.DynamoStreams.Client = dynamodbstreams.NewFromConfig(cfg)
+
+	// Create new SQS client
+	a.SQS.ctx = ctx
+	a.SQS.Client = sqs.NewFromConfig(cfg)
+	a.SQS.init()
+
+	// Create new SNS client
+	a.SNS.ctx = ctx
+	a.SNS.Client = sns.NewFromConfig(cfg)
+
+	// Create new SES client
+	a.SES.ctx = ctx
+	a.SES.Client = sesv2.NewFromConfig(cfg)
+	a.SES.init()
+
+	// Create new Secrets Manager client
+	a.Secrets.ctx = ctx
+	a.Secrets.Client = secretsmanager.NewFromConfig(cfg)
+	a.Secrets.init()
+
+	// Create new SSM client
+	a.SSM.ctx = ctx
+	a.SSM.Client = ssm.NewFromConfig(cfg)
+	a.SSM.init()
+
+	// Create new KMS client
+	a.KMS.ctx = ctx
+	a.KMS.Client = kms.NewFromConfig(cfg)
+
+	// Create new CloudWatch Logs client
+	a.Logs.ctx = ctx
+	a.Logs.Client = cloudwatchlogs.NewFromConfig(cfg)
+
+	// Create new CloudWatch client
+	a.CloudWatch.ctx = ctx
+	a.CloudWatch.Client = cloudwatch.NewFromConfig(cfg)
+
+	// Create new EventBridge client
+	a.Events.ctx = ctx
+	a.Events.Client = eventbridge.NewFromConfig(cfg)
+
+	// Create new Kinesis client
+	a.Kinesis.ctx = ctx
+	a.Kinesis.Client = kinesis.NewFromConfig(cfg)
+
+	// Create new Firehose client
+	a.Firehose.ctx = ctx
+	a.Firehose.Client = firehose.NewFromConfig(cfg)
+
+	// Create new Step Functions client
+	a.SFN.ctx = ctx
+	a.SFN.Client = sfn.NewFromConfig(cfg)
+
+	// Create new STS client
+	a.STS.ctx = ctx
+	a.STS.cfg = a.cfg
+	a.STS.opts = opts
+	a.STS.Client = sts.NewFromConfig(cfg)
+
+	// Create new IAM client
+	a.IAM.ctx = ctx
+	a.IAM.Client = iam.NewFromConfig(cfg)
+
+	// Create new ECR client
+	a.ECR.ctx = ctx
+	a.ECR.Client = ecr.NewFromConfig(cfg)
+
+	// Create new Route 53 client
+	a.Route53.ctx = ctx
+	a.Route53.Client = route53.NewFromConfig(cfg)
+
+	// Create new S3 Batch Operations client
+	a.S3Batch.ctx = ctx
+	a.S3Batch.Client = s3control.NewFromConfig(cfg)
+	a.S3Batch.s3 = &a.S3
+	a.S3Batch.sts = &a.STS
+	a.S3Batch.account = new(s3BatchAccount)
+
+	// Create new EC2 client
+	a.EC2.ctx = ctx
+	a.EC2.Client = ec2.NewFromConfig(cfg)
+
+	// Create new ECS client
+	a.ECS.ctx = ctx
+	a.ECS.Client = ecs.NewFromConfig(cfg)
+
+	// Create new AppConfig Data client
+	a.AppConfig.ctx = ctx
+	a.AppConfig.Client = appconfigdata.NewFromConfig(cfg)
+
+	// Create new Bedrock Runtime client
+	a.Bedrock.ctx = ctx
+	a.Bedrock.Client = bedrockruntime.NewFromConfig(cfg)
+	a.Bedrock.usage = new(bedrockUsage)
+
+	// Create new Rekognition client
+	a.Rekognition.ctx = ctx
+	a.Rekognition.Client = rekognition.NewFromConfig(cfg)
+
+	// Create new Textract client
+	a.Textract.ctx = ctx
+	a.Textract.Client = textract.NewFromConfig(cfg)
+
+	// Create new Polly client
+	a.Polly.ctx = ctx
+	a.Polly.Client = polly.NewFromConfig(cfg)
+
+	// Create new Cost Explorer client
+	a.Cost.ctx = ctx
+	a.Cost.Client = costexplorer.NewFromConfig(cfg)
+
+	// Create new Organizations client
+	a.Orgs.ctx = ctx
+	a.Orgs.Client = organizations.NewFromConfig(cfg)
+	a.Orgs.sts = &a.STS
+
+	// Create new Timestream Write client
+	a.Timestream.ctx = ctx
+	a.Timestream.Client = timestreamwrite.NewFromConfig(cfg)
+
 	return
 }
 
+// Config returns the AWS config used to create clients.
+func (a Aws) Config() aws.Config {
+	return a.cfg
+}
+
 // AwsError return aws error.
 // This function check if err is aws smithy.APIError and return it and true in
 // ok. If err is not aws smithy.APIError, this function return false in ok.
+//
+// Deprecated: Use errors.As with *Error which contains the error code, message,
+// request ID and HTTP status of all services.
 func (a Aws) AwsError(err error) (awsErr smithy.APIError, ok bool) {
 	if errors.As(err, &awsErr) {
 		ok = true
@@ -0,0 +1,293 @@
+package aws
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/appconfigdata"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// appConfigPollInterval is the default interval of the configuration
+	// polls.
+	appConfigPollInterval = 60 * time.Second
+
+	// appConfigMinPollInterval is the minimum poll interval allowed by
+	// AppConfig.
+	appConfigMinPollInterval = 15 * time.Second
+)
+
+// awsAppConfig is the AWS AppConfig Data client struct.
+type awsAppConfig struct {
+	// ctx is the context.Context for AWS requests
+	ctx context.Context
+
+	// Client is the AWS AppConfig Data client
+	Client *appconfigdata.Client
+}
+
+// AppConfiguration is the AppConfig configuration version.
+type AppConfiguration struct {
+	// Content is the configuration content.
+	Content []byte
+
+	// ContentType is the content type, for example "application/json" or
+	// "application/x-yaml".
+	ContentType string
+
+	// Version is the configuration version label, may be empty.
+	Version string
+}
+
+// Decode decodes the JSON or YAML configuration content into v. The content
+// is decoded by its content type, the content of other types is decoded as
+// JSON if it starts with '{' or '[', and as YAML otherwise.
+func (c AppConfiguration) Decode(v any) error {
+	switch {
+	case strings.Contains(c.ContentType, "json"):
+	case strings.Contains(c.ContentType, "yaml"):
+		return yaml.Unmarshal(c.Content, v)
+	default:
+		content := bytes.TrimSpace(c.Content)
+		if len(content) > 0 && content[0] != '{' && content[0] != '[' {
+			return yaml.Unmarshal(c.Content, v)
+		}
+	}
+	return json.Unmarshal(c.Content, v)
+}
+
+// AppConfigAs decodes the JSON or YAML configuration content into the value
+// of type T, see AppConfiguration Decode.
+func AppConfigAs[T any](c AppConfiguration) (v T, err error) {
+	err = c.Decode(&v)
+	return
+}
+
+// AppConfigPollOptions are the optional parameters of the AppConfig Poll.
+type AppConfigPollOptions struct {
+	// Interval is the poll interval. Default is 60 seconds, minimum is 15
+	// seconds.
+	Interval time.Duration
+
+	// OnError is called on the poll errors. The polling continues after the
+	// error on the next interval.
+	OnError func(err error)
+}
+
+// AppConfigPoller polls the AppConfig configuration in background, see
+// AppConfig Poll.
+type AppConfigPoller struct {
+	mu     sync.RWMutex
+	latest AppConfiguration
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// Latest returns the latest configuration.
+func (p *AppConfigPoller) Latest() AppConfiguration {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.latest
+}
+
+// Decode decodes the latest configuration into v, see AppConfiguration
+// Decode.
+func (p *AppConfigPoller) Decode(v any) error {
+	return p.Latest().Decode(v)
+}
+
+// Stop stops the polling and waits for the poller goroutine to exit.
+func (p *AppConfigPoller) Stop() {
+	p.cancel()
+	<-p.done
+}
+
+// appConfigSession is the AppConfig configuration session.
+type appConfigSession struct {
+	application, environment, profile string
+
+	// interval is the required minimum poll interval
+	interval time.Duration
+
+	// token is the next poll configuration token
+	token string
+}
+
+// Get returns the latest configuration.
+//
+// Parameters:
+//   - application: The application ID or name.
+//   - environment: The environment ID or name.
+//   - profile: The configuration profile ID or name.
+//
+// Returns:
+//   - config: The configuration.
+//   - err: An error if the operation fails. The error wraps ErrNotFound if
+//     the application, environment or profile does not exist.
+func (a awsAppConfig) Get(application, environment, profile string) (
+	config AppConfiguration, err error) {
+
+	session := &appConfigSession{application: application,
+		environment: environment, profile: profile}
+	config, _, err = a.latest(a.ctx, session)
+	return
+}
+
+// Poll gets the configuration and polls it in background. The onChange is
+// called with the first configuration before Poll returns and with each new
+// configuration version. The configuration session is restarted when its
+// token is expired. Use the returned poller Latest to get the current
+// configuration and Stop to stop the polling.
+//
+// Parameters:
+//   - ctx: The context to stop the polling.
+//   - application: The application ID or name.
+//   - environment: The environment ID or name.
+//   - profile: The configuration profile ID or name.
+//   - onChange: The function called with the new configuration. May be nil.
+//   - opts: The optional poll parameters.
+//
+// Returns:
+//   - poller: The configuration poller.
+//   - err: An error if the first configuration get fails.
+func (a awsAppConfig) Poll(ctx context.Context, application, environment,
+	profile string, onChange func(config AppConfiguration),
+	opts ...AppConfigPollOptions) (poller *AppConfigPoller, err error) {
+
+	var o AppConfigPollOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	if o.Interval <= 0 {
+		o.Interval = appConfigPollInterval
+	}
+	o.Interval = max(o.Interval, appConfigMinPollInterval)
+	if onChange == nil {
+		onChange = func(AppConfiguration) {}
+	}
+	if o.OnError == nil {
+		o.OnError = func(error) {}
+	}
+
+	// Get the first configuration
+	session := &appConfigSession{application: application,
+		environment: environment, profile: profile, interval: o.Interval}
+	config, next, err := a.latest(ctx, session)
+	if err != nil {
+		return
+	}
+	onChange(config)
+
+	ctx, cancel := context.WithCancel(ctx)
+	poller = &AppConfigPoller{latest: config, cancel: cancel,
+		done: make(chan struct{})}
+	go func() {
+		defer close(poller.done)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-clock(a.ctx).After(max(next, o.Interval)):
+			}
+
+			config, changed, interval, err := a.poll(ctx, session)
+			switch {
+			case ctx.Err() != nil:
+				return
+			case err != nil:
+				o.OnError(err)
+				continue
+			case changed:
+				poller.mu.Lock()
+				poller.latest = config
+				poller.mu.Unlock()
+				onChange(config)
+			}
+			next = interval
+		}
+	}()
+
+	return
+}
+
+// latest starts the configuration session and gets the latest
+// configuration.
+func (a awsAppConfig) latest(ctx context.Context, session *appConfigSession) (
+	config AppConfiguration, next time.Duration, err error) {
+
+	if err = a.start(ctx, session); err != nil {
+		return
+	}
+	config, _, next, err = a.poll(ctx, session)
+	return
+}
+
+// start starts the configuration session.
+func (a awsAppConfig) start(ctx context.Context,
+	session *appConfigSession) (err error) {
+
+	input := &appconfigdata.StartConfigurationSessionInput{
+		ApplicationIdentifier:          aws.String(session.application),
+		EnvironmentIdentifier:          aws.String(session.environment),
+		ConfigurationProfileIdentifier: aws.String(session.profile),
+	}
+	if session.interval > 0 {
+		input.RequiredMinimumPollIntervalInSeconds = aws.Int32(
+			int32(session.interval / time.Second))
+	}
+	out, err := a.Client.StartConfigurationSession(ctx, input)
+	if err != nil {
+		return
+	}
+	session.token = aws.ToString(out.InitialConfigurationToken)
+	return
+}
+
+// poll gets the configuration of the session. The changed is false if the
+// configuration is not changed since the last poll. The session is
+// restarted if its token is expired.
+func (a awsAppConfig) poll(ctx context.Context, session *appConfigSession) (
+	config AppConfiguration, changed bool, next time.Duration, err error) {
+
+	for restarted := false; ; restarted = true {
+		var out *appconfigdata.GetLatestConfigurationOutput
+		out, err = a.Client.GetLatestConfiguration(ctx,
+			&appconfigdata.GetLatestConfigurationInput{
+				ConfigurationToken: aws.String(session.token),
+			},
+		)
+
+		// The token expires in 24 hours or after the failed poll
+		var e *Error
+		if !restarted && errors.As(err, &e) &&
+			e.Code() == "BadRequestException" {
+			if err = a.start(ctx, session); err != nil {
+				return
+			}
+			continue
+		}
+		if err != nil {
+			return
+		}
+
+		session.token = aws.ToString(out.NextPollConfigurationToken)
+		next = time.Duration(out.NextPollIntervalInSeconds) * time.Second
+		if len(out.Configuration) == 0 {
+			return
+		}
+		config = AppConfiguration{
+			Content:     out.Configuration,
+			ContentType: aws.ToString(out.ContentType),
+			Version:     aws.ToString(out.VersionLabel),
+		}
+		changed = true
+		return
+	}
+}
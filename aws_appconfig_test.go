@@ -0,0 +1,56 @@
+package aws
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// TestAppConfigPoll checks AppConfig YAML decoding, poller and expired
+// session restart
+func TestAppConfigPoll(t *testing.T) {
+
+	type flags struct {
+		Beta  bool `json:"beta" yaml:"beta"`
+		Limit int  `json:"limit" yaml:"limit"`
+	}
+	client := &pagesHTTPClient{bodies: []string{
+		`{"InitialConfigurationToken":"t1"}`,
+		"beta: true\nlimit: 5\n",
+		`{"__type":"BadRequestException","message":"expired token"}`,
+		`{"InitialConfigurationToken":"t2"}`,
+		`{"beta":false,"limit":7}`,
+	}, statuses: []int{200, 200, http.StatusBadRequest, 200, 200}}
+	a := newPagesTestAws(client)
+
+	var changes []flags
+	poller, err := a.AppConfig.Poll(context.Background(), "app", "prod",
+		"flags", func(config AppConfiguration) {
+			f, err := AppConfigAs[flags](config)
+			if err != nil {
+				t.Error("decode error:", err)
+			}
+			changes = append(changes, f)
+		})
+	if err != nil || len(changes) != 1 || !changes[0].Beta ||
+		changes[0].Limit != 5 {
+		t.Fatal("wrong first configuration:", changes, err)
+	}
+	poller.Stop()
+	if !strings.Contains(client.requests[0],
+		`"RequiredMinimumPollIntervalInSeconds":60`) {
+		t.Error("wrong start session request:", client.requests[0])
+	}
+
+	// Expired token restarts the session
+	session := &appConfigSession{application: "app", environment: "prod",
+		profile: "flags", token: "t1"}
+	config, changed, _, err := a.AppConfig.poll(context.Background(),
+		session)
+	var f flags
+	if err != nil || !changed || config.Decode(&f) != nil || f.Limit != 7 ||
+		!strings.Contains(client.requests[3], `"ApplicationIdentifier":"app"`) {
+		t.Error("wrong restarted poll:", config, f, err)
+	}
+}
@@ -0,0 +1,352 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+)
+
+// awsBedrock is the AWS Bedrock Runtime client struct.
+type awsBedrock struct {
+	// ctx is the context.Context for AWS requests
+	ctx context.Context
+
+	// Client is the AWS Bedrock Runtime client
+	Client *bedrockruntime.Client
+
+	// usage is the token usage of the models
+	usage *bedrockUsage
+}
+
+// bedrockUsage is the token usage of the models.
+type bedrockUsage struct {
+	sync.Mutex
+	models map[string]BedrockTokens
+}
+
+// BedrockTokens is the token usage of the model.
+type BedrockTokens struct {
+	// Input is the number of the input tokens.
+	Input int
+
+	// Output is the number of the output tokens.
+	Output int
+
+	// Total is the total number of tokens.
+	Total int
+}
+
+// add adds the token usage.
+func (t *BedrockTokens) add(u BedrockTokens) {
+	t.Input += u.Input
+	t.Output += u.Output
+	t.Total += u.Total
+}
+
+// BedrockMessage is the message of the conversation.
+type BedrockMessage struct {
+	// Role is the message role: "user" or "assistant".
+	Role string
+
+	// Text is the message text.
+	Text string
+}
+
+// BedrockRequest is the Converse request.
+type BedrockRequest struct {
+	// Model is the model ID or inference profile ID, for example
+	// "anthropic.claude-3-haiku-20240307-v1:0".
+	Model string
+
+	// System is the system prompt. May be empty.
+	System string
+
+	// Messages are the conversation messages, the last one is the user
+	// message.
+	Messages []BedrockMessage
+
+	// MaxTokens is the maximum number of the output tokens. Default is the
+	// model default.
+	MaxTokens int32
+
+	// Temperature is the sampling temperature. Nil for the model default.
+	Temperature *float32
+
+	// TopP is the nucleus sampling probability. Nil for the model default.
+	TopP *float32
+
+	// Stop are the stop sequences.
+	Stop []string
+}
+
+// input returns the Converse input.
+func (r BedrockRequest) input() *bedrockruntime.ConverseInput {
+	input := &bedrockruntime.ConverseInput{
+		ModelId: aws.String(r.Model),
+		InferenceConfig: &types.InferenceConfiguration{
+			StopSequences: r.Stop,
+			Temperature:   r.Temperature,
+			TopP:          r.TopP,
+		},
+	}
+	if r.MaxTokens > 0 {
+		input.InferenceConfig.MaxTokens = aws.Int32(r.MaxTokens)
+	}
+	if r.System != "" {
+		input.System = []types.SystemContentBlock{
+			&types.SystemContentBlockMemberText{Value: r.System},
+		}
+	}
+	for _, m := range r.Messages {
+		input.Messages = append(input.Messages, types.Message{
+			Role: types.ConversationRole(m.Role),
+			Content: []types.ContentBlock{
+				&types.ContentBlockMemberText{Value: m.Text},
+			},
+		})
+	}
+	return input
+}
+
+// BedrockResponse is the Converse response.
+type BedrockResponse struct {
+	// Text is the model output text.
+	Text string
+
+	// StopReason is the reason the model stopped, for example "end_turn" or
+	// "max_tokens".
+	StopReason string
+
+	// Usage is the token usage of the request.
+	Usage BedrockTokens
+}
+
+// Converse sends the conversation messages to the model and returns the
+// model response. The token usage is added to the model Usage.
+//
+// Parameters:
+//   - request: The Converse request.
+//
+// Returns:
+//   - response: The model response.
+//   - err: An error if the operation fails.
+func (a awsBedrock) Converse(request BedrockRequest) (
+	response BedrockResponse, err error) {
+
+	out, err := a.Client.Converse(a.ctx, request.input())
+	if err != nil {
+		return
+	}
+	if m, ok := out.Output.(*types.ConverseOutputMemberMessage); ok {
+		for _, c := range m.Value.Content {
+			if text, ok := c.(*types.ContentBlockMemberText); ok {
+				response.Text += text.Value
+			}
+		}
+	}
+	response.StopReason = string(out.StopReason)
+	response.Usage = bedrockTokens(out.Usage)
+	a.addUsage(request.Model, response.Usage)
+
+	return
+}
+
+// ConverseStream sends the conversation messages to the model and streams
+// the model response text. The token usage is added to the model Usage.
+//
+// Parameters:
+//   - ctx: The context to stop streaming.
+//   - request: The Converse request.
+//   - onText: The function called with each part of the response text. The
+//     streaming stops if it returns an error.
+//
+// Returns:
+//   - response: The model response with the full text.
+//   - err: An error if the operation fails or onText returns an error.
+func (a awsBedrock) ConverseStream(ctx context.Context, request BedrockRequest,
+	onText func(text string) error) (response BedrockResponse, err error) {
+
+	input := request.input()
+	out, err := a.Client.ConverseStream(ctx,
+		&bedrockruntime.ConverseStreamInput{
+			ModelId:         input.ModelId,
+			Messages:        input.Messages,
+			System:          input.System,
+			InferenceConfig: input.InferenceConfig,
+		},
+	)
+	if err != nil {
+		return
+	}
+	stream := out.GetStream()
+	defer stream.Close()
+
+	for event := range stream.Events() {
+		switch e := event.(type) {
+		case *types.ConverseStreamOutputMemberContentBlockDelta:
+			text, ok := e.Value.Delta.(*types.ContentBlockDeltaMemberText)
+			if !ok {
+				continue
+			}
+			response.Text += text.Value
+			if err = onText(text.Value); err != nil {
+				return
+			}
+		case *types.ConverseStreamOutputMemberMessageStop:
+			response.StopReason = string(e.Value.StopReason)
+		case *types.ConverseStreamOutputMemberMetadata:
+			response.Usage = bedrockTokens(e.Value.Usage)
+		}
+	}
+	if err = stream.Err(); err != nil {
+		return
+	}
+	a.addUsage(request.Model, response.Usage)
+
+	return
+}
+
+// InvokeModel invokes the model with the model native JSON request, see the
+// BedrockAnthropicRequest and BedrockTitanEmbedRequest for the common model
+// families. The token usage is not counted, use Converse for it.
+//
+// Parameters:
+//   - model: The model ID or inference profile ID.
+//   - request: The model request. The string and []byte must contain JSON
+//     and are sent as is, other values are marshaled to JSON.
+//   - response: The pointer to the value the model JSON response is
+//     unmarshaled to, for example *BedrockAnthropicResponse.
+//
+// Returns:
+//   - err: An error if the operation fails.
+func (a awsBedrock) InvokeModel(model string, request, response any) (
+	err error) {
+
+	body, err := messageBody(request)
+	if err != nil {
+		return
+	}
+	out, err := a.Client.InvokeModel(a.ctx, &bedrockruntime.InvokeModelInput{
+		ModelId:     aws.String(model),
+		Body:        []byte(body),
+		ContentType: aws.String("application/json"),
+		Accept:      aws.String("application/json"),
+	})
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(out.Body, response)
+	return
+}
+
+// Usage returns the token usage of the models by the model ID since the Aws
+// was created or the usage was reset.
+func (a awsBedrock) Usage() map[string]BedrockTokens {
+	a.usage.Lock()
+	defer a.usage.Unlock()
+	return maps.Clone(a.usage.models)
+}
+
+// ResetUsage resets the token usage of the models.
+func (a awsBedrock) ResetUsage() {
+	a.usage.Lock()
+	defer a.usage.Unlock()
+	clear(a.usage.models)
+}
+
+// addUsage adds the token usage of the model.
+func (a awsBedrock) addUsage(model string, u BedrockTokens) {
+	a.usage.Lock()
+	defer a.usage.Unlock()
+	if a.usage.models == nil {
+		a.usage.models = make(map[string]BedrockTokens)
+	}
+	t := a.usage.models[model]
+	t.add(u)
+	a.usage.models[model] = t
+}
+
+// bedrockTokens returns BedrockTokens from the token usage.
+func bedrockTokens(u *types.TokenUsage) BedrockTokens {
+	if u == nil {
+		return BedrockTokens{}
+	}
+	return BedrockTokens{
+		Input:  int(aws.ToInt32(u.InputTokens)),
+		Output: int(aws.ToInt32(u.OutputTokens)),
+		Total:  int(aws.ToInt32(u.TotalTokens)),
+	}
+}
+
+// BedrockAnthropicRequest is the InvokeModel request of the Anthropic Claude
+// models Messages API.
+type BedrockAnthropicRequest struct {
+	AnthropicVersion string             `json:"anthropic_version"`
+	MaxTokens        int                `json:"max_tokens"`
+	System           string             `json:"system,omitempty"`
+	Messages         []BedrockAnthropic `json:"messages"`
+	Temperature      *float32           `json:"temperature,omitempty"`
+	StopSequences    []string           `json:"stop_sequences,omitempty"`
+}
+
+// BedrockAnthropic is the text message of the Anthropic Claude models.
+type BedrockAnthropic struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// NewBedrockAnthropicRequest returns the Anthropic Claude models request
+// with the user prompt.
+func NewBedrockAnthropicRequest(prompt string, maxTokens int) (
+	r BedrockAnthropicRequest) {
+
+	return BedrockAnthropicRequest{
+		AnthropicVersion: "bedrock-2023-05-31",
+		MaxTokens:        maxTokens,
+		Messages:         []BedrockAnthropic{{Role: "user", Content: prompt}},
+	}
+}
+
+// BedrockAnthropicResponse is the InvokeModel response of the Anthropic
+// Claude models Messages API.
+type BedrockAnthropicResponse struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	StopReason string `json:"stop_reason"`
+	Usage      struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+}
+
+// Text returns the response text.
+func (r BedrockAnthropicResponse) Text() (text string) {
+	for _, c := range r.Content {
+		if c.Type == "text" {
+			text += c.Text
+		}
+	}
+	return
+}
+
+// BedrockTitanEmbedRequest is the InvokeModel request of the Amazon Titan
+// text embeddings models.
+type BedrockTitanEmbedRequest struct {
+	InputText  string `json:"inputText"`
+	Dimensions int    `json:"dimensions,omitempty"`
+	Normalize  bool   `json:"normalize,omitempty"`
+}
+
+// BedrockTitanEmbedResponse is the InvokeModel response of the Amazon Titan
+// text embeddings models.
+type BedrockTitanEmbedResponse struct {
+	Embedding           []float32 `json:"embedding"`
+	InputTextTokenCount int       `json:"inputTextTokenCount"`
+}
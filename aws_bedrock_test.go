@@ -0,0 +1,67 @@
+package aws
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestBedrockConverse checks Bedrock Converse response, token usage
+// accounting and InvokeModel with the Anthropic request
+func TestBedrockConverse(t *testing.T) {
+
+	const model = "anthropic.claude-3-haiku-20240307-v1:0"
+	client := &pagesHTTPClient{bodies: []string{
+		`{"output":{"message":{"role":"assistant","content":[{"text":"Hel"},` +
+			`{"text":"lo"}]}},"stopReason":"end_turn","usage":` +
+			`{"inputTokens":10,"outputTokens":2,"totalTokens":12}}`,
+		`{"output":{"message":{"role":"assistant","content":[{"text":"Hi"}]}},` +
+			`"stopReason":"max_tokens","usage":` +
+			`{"inputTokens":5,"outputTokens":1,"totalTokens":6}}`,
+		`{"content":[{"type":"text","text":"Hey"}],"stop_reason":"end_turn",` +
+			`"usage":{"input_tokens":3,"output_tokens":1}}`,
+	}}
+	a := newPagesTestAws(client)
+
+	resp, err := a.Bedrock.Converse(BedrockRequest{
+		Model:     model,
+		System:    "Be brief",
+		Messages:  []BedrockMessage{{Role: "user", Text: "Say hello"}},
+		MaxTokens: 100,
+	})
+	if err != nil || resp.Text != "Hello" || resp.StopReason != "end_turn" ||
+		resp.Usage != (BedrockTokens{Input: 10, Output: 2, Total: 12}) {
+		t.Fatal("wrong converse response:", resp, err)
+	}
+	for _, s := range []string{`"system":[{"text":"Be brief"}]`,
+		`"maxTokens":100`, `"role":"user"`} {
+		if !strings.Contains(client.requests[0], s) {
+			t.Error("converse request does not contain", s, client.requests[0])
+		}
+	}
+
+	// Token usage is accumulated by model
+	if _, err = a.Bedrock.Converse(BedrockRequest{Model: model,
+		Messages: []BedrockMessage{{Role: "user", Text: "Hi"}}}); err != nil {
+		t.Fatal("converse error:", err)
+	}
+	usage := a.Bedrock.Usage()
+	if usage[model] != (BedrockTokens{Input: 15, Output: 3, Total: 18}) {
+		t.Error("wrong usage:", usage)
+	}
+	a.Bedrock.ResetUsage()
+	if len(a.Bedrock.Usage()) != 0 {
+		t.Error("usage is not reset:", a.Bedrock.Usage())
+	}
+
+	// Native Anthropic request
+	var out BedrockAnthropicResponse
+	err = a.Bedrock.InvokeModel(model, NewBedrockAnthropicRequest("Hi", 50),
+		&out)
+	if err != nil || out.Text() != "Hey" || out.Usage.InputTokens != 3 {
+		t.Error("wrong invoke model response:", out, err)
+	}
+	if !strings.Contains(client.requests[2],
+		`"anthropic_version":"bedrock-2023-05-31"`) {
+		t.Error("wrong invoke model request:", client.requests[2])
+	}
+}
@@ -0,0 +1,251 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+)
+
+// cloudWatchBatchSize is the maximum number of metrics in PutMetricData
+// request.
+const cloudWatchBatchSize = 1000
+
+// awsCloudWatch is the AWS CloudWatch client struct.
+type awsCloudWatch struct {
+	// ctx is the context.Context for AWS requests
+	ctx context.Context
+
+	// Client is the AWS CloudWatch client
+	Client *cloudwatch.Client
+}
+
+// Metric is the CloudWatch metric value.
+type Metric struct {
+	// Name is the metric name.
+	Name string
+
+	// Value is the metric value.
+	Value float64
+
+	// Unit is the metric unit, for example "Count", "Seconds" or "Bytes".
+	// Default is "None".
+	Unit string
+
+	// Dimensions are the metric dimensions by names.
+	Dimensions map[string]string
+
+	// Timestamp is the metric value time. Default is the time the value is
+	// received by CloudWatch.
+	Timestamp time.Time
+
+	// HighResolution saves the value with 1 second resolution instead of 1
+	// minute.
+	HighResolution bool
+}
+
+// datum returns the metric datum.
+func (m Metric) datum() types.MetricDatum {
+	d := types.MetricDatum{
+		MetricName: aws.String(m.Name),
+		Value:      aws.Float64(m.Value),
+		Unit:       types.StandardUnit(m.Unit),
+		Dimensions: cloudWatchDimensions(m.Dimensions),
+	}
+	if !m.Timestamp.IsZero() {
+		d.Timestamp = aws.Time(m.Timestamp)
+	}
+	if m.HighResolution {
+		d.StorageResolution = aws.Int32(1)
+	}
+	return d
+}
+
+// MetricQuery is the CloudWatch GetMetricData query of the metric statistic
+// or the math expression.
+type MetricQuery struct {
+	// ID is the query ID used in the expressions and results. It must start
+	// with a lowercase letter.
+	ID string
+
+	// Namespace is the metric namespace.
+	Namespace string
+
+	// Name is the metric name.
+	Name string
+
+	// Dimensions are the metric dimensions by names.
+	Dimensions map[string]string
+
+	// Stat is the statistic, for example "Sum", "Average" or "p99".
+	Stat string
+
+	// Period is the statistic period, a multiple of 60 seconds or 1, 5, 10,
+	// 30 seconds for high resolution metrics.
+	Period time.Duration
+
+	// Expression is the math expression, for example "errors / requests".
+	// Namespace, Name, Dimensions and Stat are not used by the expression
+	// query.
+	Expression string
+
+	// Label is the series label.
+	Label string
+
+	// Hidden excludes the query from the results, for example the query
+	// used by expressions only.
+	Hidden bool
+}
+
+// query returns the metric data query.
+func (q MetricQuery) query() types.MetricDataQuery {
+	query := types.MetricDataQuery{
+		Id:         aws.String(q.ID),
+		Label:      optional(q.Label),
+		Expression: optional(q.Expression),
+		ReturnData: aws.Bool(!q.Hidden),
+	}
+	period := aws.Int32(int32(q.Period / time.Second))
+	if q.Expression != "" {
+		if q.Period > 0 {
+			query.Period = period
+		}
+		return query
+	}
+	query.MetricStat = &types.MetricStat{
+		Metric: &types.Metric{
+			Namespace:  aws.String(q.Namespace),
+			MetricName: aws.String(q.Name),
+			Dimensions: cloudWatchDimensions(q.Dimensions),
+		},
+		Stat:   aws.String(q.Stat),
+		Period: period,
+	}
+	return query
+}
+
+// MetricSeries is the time series returned by GetMetricData.
+type MetricSeries struct {
+	// ID is the query ID.
+	ID string
+
+	// Label is the series label.
+	Label string
+
+	// Points are the series points sorted by time.
+	Points []MetricPoint
+}
+
+// MetricPoint is the time series point.
+type MetricPoint struct {
+	Timestamp time.Time
+	Value     float64
+}
+
+// PutMetric saves the metric values to the namespace. The values are sent by
+// batches of 1000 values.
+//
+// Parameters:
+//   - namespace: The metrics namespace, for example "MyApp/Orders".
+//   - metrics: The metric values.
+//
+// Returns:
+//   - err: An error if the operation fails. The batches before the failed
+//     one are saved.
+func (a awsCloudWatch) PutMetric(namespace string, metrics ...Metric) (
+	err error) {
+
+	for batch := range slices.Chunk(metrics, cloudWatchBatchSize) {
+		data := make([]types.MetricDatum, len(batch))
+		for i, m := range batch {
+			data[i] = m.datum()
+		}
+		_, err = a.Client.PutMetricData(a.ctx, &cloudwatch.PutMetricDataInput{
+			Namespace:  aws.String(namespace),
+			MetricData: data,
+		})
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// GetMetricData returns the time series of the metric queries.
+//
+// Parameters:
+//   - start: The start time of the series, inclusive.
+//   - end: The end time of the series, exclusive.
+//   - queries: The metric queries.
+//
+// Returns:
+//   - series: The time series in order of not hidden queries.
+//   - err: An error if the operation fails or some query is not valid.
+func (a awsCloudWatch) GetMetricData(start, end time.Time,
+	queries ...MetricQuery) (series []MetricSeries, err error) {
+
+	input := &cloudwatch.GetMetricDataInput{
+		StartTime: aws.Time(start),
+		EndTime:   aws.Time(end),
+		ScanBy:    types.ScanByTimestampAscending,
+	}
+	index := make(map[string]int)
+	for _, q := range queries {
+		input.MetricDataQueries = append(input.MetricDataQueries, q.query())
+		if !q.Hidden {
+			index[q.ID] = len(series)
+			series = append(series, MetricSeries{ID: q.ID, Label: q.Label})
+		}
+	}
+
+	for {
+		var out *cloudwatch.GetMetricDataOutput
+		out, err = a.Client.GetMetricData(a.ctx, input)
+		if err != nil {
+			return
+		}
+		for _, r := range out.MetricDataResults {
+			i, ok := index[aws.ToString(r.Id)]
+			if !ok {
+				continue
+			}
+			if r.StatusCode == types.StatusCodeForbidden ||
+				r.StatusCode == types.StatusCodeInternalError {
+				err = fmt.Errorf("cloudwatch metric query %s status %s",
+					series[i].ID, r.StatusCode)
+				return
+			}
+			if label := aws.ToString(r.Label); label != "" {
+				series[i].Label = label
+			}
+			for j, ts := range r.Timestamps {
+				if j < len(r.Values) {
+					series[i].Points = append(series[i].Points,
+						MetricPoint{Timestamp: ts, Value: r.Values[j]})
+				}
+			}
+		}
+		if input.NextToken = out.NextToken; input.NextToken == nil {
+			break
+		}
+	}
+	return
+}
+
+// cloudWatchDimensions returns the metric dimensions sorted by names.
+func cloudWatchDimensions(dimensions map[string]string) (
+	list []types.Dimension) {
+
+	for _, name := range slices.Sorted(maps.Keys(dimensions)) {
+		list = append(list, types.Dimension{
+			Name:  aws.String(name),
+			Value: aws.String(dimensions[name]),
+		})
+	}
+	return
+}
@@ -0,0 +1,93 @@
+package aws
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// gunzip returns the request body compressed by CloudWatch client.
+func gunzip(t *testing.T, body string) string {
+	r, err := gzip.NewReader(strings.NewReader(body))
+	if err != nil {
+		t.Fatal("request is not compressed:", err)
+	}
+	data, _ := io.ReadAll(r)
+	return string(data)
+}
+
+// TestCloudWatchPutMetric checks CloudWatch metrics batches
+func TestCloudWatchPutMetric(t *testing.T) {
+
+	response := `<PutMetricDataResponse><ResponseMetadata>` +
+		`<RequestId>r</RequestId></ResponseMetadata></PutMetricDataResponse>`
+	client := &pagesHTTPClient{bodies: []string{response, response}}
+	a := newPagesTestAws(client)
+
+	metrics := make([]Metric, cloudWatchBatchSize+1)
+	for i := range metrics {
+		metrics[i] = Metric{Name: "Orders", Value: float64(i), Unit: "Count",
+			Dimensions:     map[string]string{"Shop": "s1", "Country": "de"},
+			HighResolution: true}
+	}
+	if err := a.CloudWatch.PutMetric("MyApp/Orders", metrics...); err != nil {
+		t.Fatal("put metric error:", err)
+	}
+	if len(client.requests) != 2 {
+		t.Fatal("wrong number of batches:", len(client.requests))
+	}
+	form, _ := url.ParseQuery(gunzip(t, client.requests[1]))
+	for name, value := range map[string]string{
+		"Namespace":                                    "MyApp/Orders",
+		"MetricData.member.1.Value":                    "1000",
+		"MetricData.member.1.StorageResolution":        "1",
+		"MetricData.member.1.Dimensions.member.1.Name": "Country",
+	} {
+		if form.Get(name) != value {
+			t.Error("wrong request value of", name, form.Get(name))
+		}
+	}
+}
+
+// TestCloudWatchGetMetricData checks CloudWatch time series pages
+func TestCloudWatchGetMetricData(t *testing.T) {
+
+	page := func(ts string, value float64, next string) string {
+		return fmt.Sprintf(`<GetMetricDataResponse><GetMetricDataResult>`+
+			`<MetricDataResults><member><Id>rate</Id><Label>Error rate</Label>`+
+			`<StatusCode>Complete</StatusCode><Timestamps><member>%s</member>`+
+			`</Timestamps><Values><member>%g</member></Values></member>`+
+			`</MetricDataResults>%s</GetMetricDataResult>`+
+			`</GetMetricDataResponse>`, ts, value, next)
+	}
+	client := &pagesHTTPClient{bodies: []string{
+		page("2024-01-01T00:00:00Z", 0.5, "<NextToken>t1</NextToken>"),
+		page("2024-01-01T00:01:00Z", 0.25, ""),
+	}}
+	a := newPagesTestAws(client)
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	series, err := a.CloudWatch.GetMetricData(start, start.Add(time.Hour),
+		MetricQuery{ID: "errors", Namespace: "MyApp", Name: "Errors",
+			Stat: "Sum", Period: time.Minute, Hidden: true},
+		MetricQuery{ID: "requests", Namespace: "MyApp", Name: "Requests",
+			Stat: "Sum", Period: time.Minute, Hidden: true},
+		MetricQuery{ID: "rate", Expression: "errors / requests"},
+	)
+	if err != nil || len(series) != 1 || series[0].Label != "Error rate" ||
+		len(series[0].Points) != 2 || series[0].Points[1].Value != 0.25 ||
+		!series[0].Points[1].Timestamp.Equal(start.Add(time.Minute)) {
+		t.Fatal("wrong series:", series, err)
+	}
+	form, _ := url.ParseQuery(client.requests[1])
+	if form.Get("NextToken") != "t1" ||
+		form.Get("MetricDataQueries.member.1.MetricStat.Period") != "60" ||
+		form.Get("MetricDataQueries.member.3.ReturnData") != "true" ||
+		!strings.Contains(client.requests[0], "ScanBy=TimestampAscending") {
+		t.Error("wrong get metric data request:", client.requests[1])
+	}
+}
@@ -3,13 +3,17 @@ package aws
 import (
 	"context"
 	"fmt"
+	"iter"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
 	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
 )
 
-var ErrCognitoUserNotFound = fmt.Errorf("not found")
+// ErrCognitoUserNotFound is returned by Get when the user with sub does not
+// exist. It is returned as is, not wrapped with OpError, so it may be compared
+// with == as well as checked with errors.Is. It wraps ErrNotFound.
+var ErrCognitoUserNotFound = fmt.Errorf("user %w", ErrNotFound)
 
 type awsCognito struct {
 	Cache
@@ -141,6 +145,44 @@ func (a awsCognito) List(userPoolId string, limit int, filter string, previous *
 	return
 }
 
+// Users returns an iterator over all Cognito users of a user pool matching
+// the filter. The iterator hides the List limit and pagination token
+// handling, so all users may be ranged over directly:
+//
+//	for user, err := range a.Cognito.Users(userPoolId, filter) {
+//		if err != nil {
+//			return err
+//		}
+//		...
+//	}
+//
+// The filter has the same syntax as the List function filter. The iteration
+// stops after the first error.
+func (a awsCognito) Users(userPoolId, filter string) iter.Seq2[UserType, error] {
+	return a.UsersPages(userPoolId, filter).Items()
+}
+
+// UsersPages returns the paginator over the pages of Cognito users of a user
+// pool matching the filter. The pages are read with the List function with
+// its maximum limit of 60 users.
+//
+// Parameters:
+//   - userPoolId: The ID of the user pool.
+//   - filter: The filter, it has the same syntax as the List function filter.
+//
+// Returns:
+//   - p: The users pages paginator.
+func (a awsCognito) UsersPages(userPoolId, filter string) (
+	p *Paginator[UserType]) {
+
+	var pagination *string
+	return NewPaginator(func() (users []UserType, more bool, err error) {
+		users, pagination, err = a.List(userPoolId, 60, filter, pagination)
+		more = aws.ToString(pagination) != ""
+		return
+	})
+}
+
 // UserAttributes returns a map of user attributes.
 //
 // Parameters:
@@ -163,3 +205,44 @@ func (awsCognito) UserAttributes(user *UserType) (m map[string]string) {
 	// Return the map of user attributes.
 	return
 }
+
+// ListUsersInGroup retrieves a list of Cognito users in a user pool group.
+//
+// Parameters:
+//
+// userPoolId: The ID of the user pool.
+//
+// groupName: The name of the group.
+//
+// limit: The maximum number of users to return.
+//
+// nextToken: An identifier that was returned from the previous call to this
+// operation, which can be used to return the next set of items in the list.
+//
+// Returns:
+//
+//   - users: A list of UserType objects representing the users.
+//   - next: A token to continue the list from if there are more users.
+//   - err: An error if the operation fails.
+func (a awsCognito) ListUsersInGroup(userPoolId, groupName string, limit int,
+	nextToken *string) (users []UserType, next *string, err error) {
+
+	// Set the user pool ID and group name.
+	input := &cognitoidentityprovider.ListUsersInGroupInput{
+		UserPoolId: aws.String(userPoolId),
+		GroupName:  aws.String(groupName),
+		Limit:      aws.Int32(int32(limit)),
+		NextToken:  nextToken,
+	}
+
+	// Call the ListUsersInGroup API to retrieve users of the group.
+	listUsers, err := a.Client.ListUsersInGroup(a.ctx, input)
+	if err != nil {
+		return // Return the error if there was an issue calling the API.
+	}
+
+	// Return the list of users and the next token.
+	next = listUsers.NextToken
+	users = listUsers.Users
+	return
+}
@@ -0,0 +1,204 @@
+package aws
+
+import (
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+)
+
+// AuthResult contains the tokens of successfully authenticated user.
+type AuthResult = types.AuthenticationResultType
+
+// AuthChallenge is an authentication challenge the user must pass to complete
+// the sign in.
+type AuthChallenge struct {
+	// Name is the challenge name, for example
+	// types.ChallengeNameTypeNewPasswordRequired.
+	Name types.ChallengeNameType
+
+	// Session is the session which should be passed to the challenge
+	// response.
+	Session string
+
+	// Username is the name of the user passing the challenge.
+	Username string
+
+	// Parameters are the challenge parameters returned by Cognito.
+	Parameters map[string]string
+}
+
+// AdminInitiateAuth signs in the user with the username and password on the
+// server side. The app client must have ALLOW_ADMIN_USER_PASSWORD_AUTH flow
+// enabled.
+//
+// Parameters:
+//   - userPoolId: The ID of the user pool.
+//   - clientId: The ID of the user pool app client.
+//   - username: The user name.
+//   - password: The user password.
+//   - clientSecret: The app client secret, if the app client has one.
+//
+// Returns:
+//   - result: The authentication result if the user is signed in.
+//   - challenge: The next challenge if the user must pass a challenge to sign
+//     in, nil otherwise. Use AdminRespondToAuthChallenge or the typed
+//     challenge helpers to respond.
+//   - err: An error if the operation fails.
+func (a awsCognito) AdminInitiateAuth(userPoolId, clientId, username,
+	password string, clientSecret ...string) (result *AuthResult,
+	challenge *AuthChallenge, err error) {
+
+	params := map[string]string{
+		"USERNAME": username,
+		"PASSWORD": password,
+	}
+	if hash := secretHash(username, clientId, clientSecret); hash != nil {
+		params["SECRET_HASH"] = *hash
+	}
+
+	out, err := a.Client.AdminInitiateAuth(a.ctx,
+		&cognitoidentityprovider.AdminInitiateAuthInput{
+			UserPoolId:     aws.String(userPoolId),
+			ClientId:       aws.String(clientId),
+			AuthFlow:       types.AuthFlowTypeAdminUserPasswordAuth,
+			AuthParameters: params,
+		},
+	)
+	if err != nil {
+		return
+	}
+
+	result, challenge = authResponse(username, out.AuthenticationResult,
+		out.ChallengeName, out.Session, out.ChallengeParameters)
+	return
+}
+
+// AdminRespondToAuthChallenge responds to the authentication challenge.
+//
+// Parameters:
+//   - userPoolId: The ID of the user pool.
+//   - clientId: The ID of the user pool app client.
+//   - challenge: The challenge to respond to.
+//   - responses: The challenge responses. The USERNAME and SECRET_HASH
+//     responses are added by this function.
+//   - clientSecret: The app client secret, if the app client has one.
+//
+// Returns:
+//   - result: The authentication result if the user is signed in.
+//   - next: The next challenge if the user must pass one more challenge,
+//     nil otherwise.
+//   - err: An error if the operation fails.
+func (a awsCognito) AdminRespondToAuthChallenge(userPoolId, clientId string,
+	challenge *AuthChallenge, responses map[string]string,
+	clientSecret ...string) (result *AuthResult, next *AuthChallenge, err error) {
+
+	// Add common responses
+	r := make(map[string]string, len(responses)+2)
+	for k, v := range responses {
+		r[k] = v
+	}
+	r["USERNAME"] = challenge.Username
+	if hash := secretHash(challenge.Username, clientId, clientSecret); hash != nil {
+		r["SECRET_HASH"] = *hash
+	}
+
+	out, err := a.Client.AdminRespondToAuthChallenge(a.ctx,
+		&cognitoidentityprovider.AdminRespondToAuthChallengeInput{
+			UserPoolId:         aws.String(userPoolId),
+			ClientId:           aws.String(clientId),
+			ChallengeName:      challenge.Name,
+			Session:            aws.String(challenge.Session),
+			ChallengeResponses: r,
+		},
+	)
+	if err != nil {
+		return
+	}
+
+	result, next = authResponse(challenge.Username, out.AuthenticationResult,
+		out.ChallengeName, out.Session, out.ChallengeParameters)
+	return
+}
+
+// AdminRespondNewPassword responds to the NEW_PASSWORD_REQUIRED challenge
+// with the new user password.
+//
+// Parameters:
+//   - userPoolId: The ID of the user pool.
+//   - clientId: The ID of the user pool app client.
+//   - challenge: The NEW_PASSWORD_REQUIRED challenge.
+//   - newPassword: The new user password.
+//   - clientSecret: The app client secret, if the app client has one.
+//
+// Returns the same values as AdminRespondToAuthChallenge.
+func (a awsCognito) AdminRespondNewPassword(userPoolId, clientId string,
+	challenge *AuthChallenge, newPassword string, clientSecret ...string) (
+	result *AuthResult, next *AuthChallenge, err error) {
+
+	return a.AdminRespondToAuthChallenge(userPoolId, clientId, challenge,
+		map[string]string{"NEW_PASSWORD": newPassword}, clientSecret...)
+}
+
+// AdminRespondSMSMFA responds to the SMS_MFA challenge with the code sent to
+// the user phone.
+//
+// Parameters:
+//   - userPoolId: The ID of the user pool.
+//   - clientId: The ID of the user pool app client.
+//   - challenge: The SMS_MFA challenge.
+//   - code: The MFA code.
+//   - clientSecret: The app client secret, if the app client has one.
+//
+// Returns the same values as AdminRespondToAuthChallenge.
+func (a awsCognito) AdminRespondSMSMFA(userPoolId, clientId string,
+	challenge *AuthChallenge, code string, clientSecret ...string) (
+	result *AuthResult, next *AuthChallenge, err error) {
+
+	return a.AdminRespondToAuthChallenge(userPoolId, clientId, challenge,
+		map[string]string{"SMS_MFA_CODE": code}, clientSecret...)
+}
+
+// AdminRespondSoftwareTokenMFA responds to the SOFTWARE_TOKEN_MFA challenge
+// with the code from the user authenticator app.
+//
+// Parameters:
+//   - userPoolId: The ID of the user pool.
+//   - clientId: The ID of the user pool app client.
+//   - challenge: The SOFTWARE_TOKEN_MFA challenge.
+//   - code: The TOTP code.
+//   - clientSecret: The app client secret, if the app client has one.
+//
+// Returns the same values as AdminRespondToAuthChallenge.
+func (a awsCognito) AdminRespondSoftwareTokenMFA(userPoolId, clientId string,
+	challenge *AuthChallenge, code string, clientSecret ...string) (
+	result *AuthResult, next *AuthChallenge, err error) {
+
+	return a.AdminRespondToAuthChallenge(userPoolId, clientId, challenge,
+		map[string]string{"SOFTWARE_TOKEN_MFA_CODE": code}, clientSecret...)
+}
+
+// authResponse returns authentication result or the next challenge from
+// Cognito initiate auth or respond to challenge output.
+func authResponse(username string, authResult *AuthResult,
+	name types.ChallengeNameType, session *string,
+	params map[string]string) (result *AuthResult, challenge *AuthChallenge) {
+
+	if authResult != nil {
+		result = authResult
+		return
+	}
+
+	// Cognito returns internal user name in the USER_ID_FOR_SRP parameter
+	// when user signs in with alias (email or phone number)
+	if id, ok := params["USER_ID_FOR_SRP"]; ok && id != "" {
+		username = id
+	}
+
+	challenge = &AuthChallenge{
+		Name:       name,
+		Session:    aws.ToString(session),
+		Username:   username,
+		Parameters: params,
+	}
+	return
+}
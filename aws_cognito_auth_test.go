@@ -0,0 +1,73 @@
+package aws
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+)
+
+// TestCognitoAuthChallenges checks the sign in passes the new password and
+// SMS MFA challenges with the alias user name and secret hash
+func TestCognitoAuthChallenges(t *testing.T) {
+
+	client := &pagesHTTPClient{bodies: []string{
+		`{"ChallengeName":"NEW_PASSWORD_REQUIRED","Session":"s1",` +
+			`"ChallengeParameters":{"USER_ID_FOR_SRP":"uuid"}}`,
+		`{"ChallengeName":"SMS_MFA","Session":"s2",` +
+			`"ChallengeParameters":{"CODE_DELIVERY_DESTINATION":"+***1"}}`,
+		`{"AuthenticationResult":{"AccessToken":"access",` +
+			`"IdToken":"id","RefreshToken":"refresh"}}`,
+	}}
+	a := newPagesTestAws(client)
+
+	// Sign in by the email alias
+	result, challenge, err := a.Cognito.AdminInitiateAuth("pool", "client",
+		"user@example.com", "temp", "secret")
+	if err != nil || result != nil || challenge == nil ||
+		challenge.Name != types.ChallengeNameTypeNewPasswordRequired ||
+		challenge.Session != "s1" || challenge.Username != "uuid" {
+		t.Fatal("wrong new password challenge:", result, challenge, err)
+	}
+	hash := aws.ToString(secretHash("user@example.com", "client",
+		[]string{"secret"}))
+	for _, want := range []string{`"AuthFlow":"ADMIN_USER_PASSWORD_AUTH"`,
+		`"USERNAME":"user@example.com"`, `"PASSWORD":"temp"`,
+		`"SECRET_HASH":"` + hash + `"`} {
+		if !strings.Contains(client.requests[0], want) {
+			t.Error("wrong initiate auth request:", want, client.requests[0])
+		}
+	}
+
+	// New password, the internal user name is used by the next requests
+	result, challenge, err = a.Cognito.AdminRespondNewPassword("pool",
+		"client", challenge, "new-password", "secret")
+	if err != nil || result != nil || challenge == nil ||
+		challenge.Name != types.ChallengeNameTypeSmsMfa ||
+		challenge.Session != "s2" || challenge.Username != "uuid" ||
+		challenge.Parameters["CODE_DELIVERY_DESTINATION"] != "+***1" {
+		t.Fatal("wrong SMS MFA challenge:", result, challenge, err)
+	}
+	hash = aws.ToString(secretHash("uuid", "client", []string{"secret"}))
+	for _, want := range []string{`"ChallengeName":"NEW_PASSWORD_REQUIRED"`,
+		`"Session":"s1"`, `"NEW_PASSWORD":"new-password"`,
+		`"USERNAME":"uuid"`, `"SECRET_HASH":"` + hash + `"`} {
+		if !strings.Contains(client.requests[1], want) {
+			t.Error("wrong new password request:", want, client.requests[1])
+		}
+	}
+
+	// SMS MFA code without the client secret
+	result, challenge, err = a.Cognito.AdminRespondSMSMFA("pool", "client",
+		challenge, "123456")
+	if err != nil || challenge != nil || result == nil ||
+		aws.ToString(result.AccessToken) != "access" {
+		t.Fatal("wrong auth result:", result, challenge, err)
+	}
+	if !strings.Contains(client.requests[2], `"SMS_MFA_CODE":"123456"`) ||
+		!strings.Contains(client.requests[2], `"Session":"s2"`) ||
+		strings.Contains(client.requests[2], "SECRET_HASH") {
+		t.Error("wrong SMS MFA request:", client.requests[2])
+	}
+}
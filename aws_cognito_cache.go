@@ -1,74 +1,207 @@
 package aws
 
 import (
+	"errors"
 	"sync"
+	"time"
 )
 
 type Cache struct {
+	// MaxEntries is the maximum number of users cached per user pool. When
+	// the limit is reached the least recently used user is evicted from the
+	// cache. Zero means no limit.
+	MaxEntries int
+
+	// TTL is the time to live of cached users. Expired users are read from
+	// Cognito by the next Get. Zero means users never expire.
+	TTL time.Duration
+
+	// RefreshAhead enables the refresh-ahead mode when TTL is set. The users
+	// requested less than RefreshAhead before expiry are returned from the
+	// cache and re-fetched from Cognito in background. The refresh time of
+	// each user is randomly shifted to spread the refreshes. Zero disables
+	// the refresh-ahead mode.
+	RefreshAhead time.Duration
+
+	// MaxRefreshes is the maximum number of concurrent background refreshes
+	// per user pool. The refresh is skipped when the limit is reached and is
+	// retried by the next Get. Default is 4.
+	MaxRefreshes int
+
+	// OnError is called when Cognito request of the cache fails with error
+	// other than ErrCognitoUserNotFound, including the background refreshes
+	// which errors are not returned to the caller. Use it to count and alert
+	// on upstream Cognito failures.
+	OnError func(userPoolId, sub string, err error)
+
 	mu      *sync.RWMutex
-	cache   map[string]map[string]cacheData
+	cache   map[string]*LookupCache[string, *UserType]
 	coginto *awsCognito
 }
-type cacheData struct {
-	*UserType
-	err error
-}
 
 func (c *Cache) init(coginto *awsCognito) {
 	c.coginto = coginto
-	c.cache = make(map[string]map[string]cacheData)
+	c.cache = make(map[string]*LookupCache[string, *UserType])
 	c.mu = new(sync.RWMutex)
 }
 
-func (c *Cache) Get(userPoolId, sub string) (user *UserType, err error) {
-
-	// Get user from cache using mutex read lock
+// pool returns the cache of the user pool. The cache is created with current
+// Cache settings if it does not exist and create is true.
+func (c *Cache) pool(userPoolId string, create bool) *LookupCache[string, *UserType] {
 	c.mu.RLock()
-	userCache, ok := c.cache[userPoolId][sub]
+	pool, ok := c.cache[userPoolId]
 	c.mu.RUnlock()
-	if ok {
-		user = userCache.UserType
-		err = userCache.err
-		return
+	if ok || !create {
+		return pool
 	}
 
-	// Lock cache Mutex to write
 	c.mu.Lock()
 	defer c.mu.Unlock()
+	if pool, ok = c.cache[userPoolId]; ok {
+		return pool
+	}
 
-	// Get user from Cognito
-	user, err = c.coginto.Get(userPoolId, sub)
-	if err != nil {
-		// Add not found user to cache
-		if err.Error() == ErrCognitoUserNotFound.Error() {
-			c.add(userPoolId, sub, user, err)
+	// Create user pool cache
+	pool = NewLookupCache(
+		func(sub string) (*UserType, error) {
+			return c.coginto.Get(userPoolId, sub)
+		},
+		func(err error) bool {
+			return errors.Is(err, ErrCognitoUserNotFound)
+		},
+	)
+	pool.MaxEntries = c.MaxEntries
+	pool.TTL = c.TTL
+	pool.RefreshAhead = c.RefreshAhead
+	pool.MaxRefreshes = c.MaxRefreshes
+	if c.coginto != nil {
+		pool.Clock = clock(c.coginto.ctx)
+	}
+	if c.OnError != nil {
+		onError := c.OnError
+		pool.OnError = func(sub string, err error) {
+			onError(userPoolId, sub, err)
 		}
-		return
 	}
+	c.cache[userPoolId] = pool
 
-	// Add user to cache
-	c.add(userPoolId, sub, user, nil)
-	return
+	return pool
+}
+
+func (c *Cache) Get(userPoolId, sub string) (user *UserType, err error) {
+	return c.pool(userPoolId, true).Get(sub)
 }
 
-func (c *Cache) add(userPoolId, sub string, user *UserType, err error) {
-	if _, ok := c.cache[userPoolId]; !ok {
-		c.cache[userPoolId] = make(map[string]cacheData)
+// Peek returns the user from the cache without reading it from Cognito, and
+// the time the user was added to the cache. The ok is false if the user is
+// not cached or expired. The user is nil and ok is true if the cache keeps
+// the user not found result.
+func (c *Cache) Peek(userPoolId, sub string) (user *UserType, cachedAt time.Time,
+	ok bool) {
+
+	pool := c.pool(userPoolId, false)
+	if pool == nil {
+		return
 	}
+	entry, ok := pool.Peek(sub)
+	if !ok {
+		return
+	}
+	user, cachedAt = entry.Value, entry.CachedAt
+	return
+}
 
-	c.cache[userPoolId][sub] = cacheData{user, err}
+// Contains returns true if the user (or the user not found result) is cached
+// and not expired.
+func (c *Cache) Contains(userPoolId, sub string) bool {
+	pool := c.pool(userPoolId, false)
+	return pool != nil && pool.Contains(sub)
+}
+
+// Warm pre-populates the cache with users of the user pool matching the
+// filter. The users are read with rate limited ListUsers calls, so the bulk
+// listing does not exhaust the user pool quota. Use it before batch jobs
+// which touch most of the users instead of reading users one by one.
+//
+// Parameters:
+//   - userPoolId: The ID of the user pool.
+//   - filter: The users filter, see awsCognito.List. Empty filter warms the
+//     cache with all users of the user pool.
+//
+// Returns:
+//   - n: The number of users added to the cache.
+//   - err: An error if the operation fails.
+func (c *Cache) Warm(userPoolId, filter string) (n int, err error) {
+
+	// Limit ListUsers calls rate
+	ticker := clock(c.coginto.ctx).NewTicker(time.Second / listUsersRate)
+	defer ticker.Stop()
+
+	pool := c.pool(userPoolId, true)
+	var pagination *string
+	for {
+		<-ticker.C()
+
+		// Get next page of users
+		out, err := c.coginto.listUsersPage(userPoolId, filter, nil, pagination)
+		if err != nil {
+			if pool.OnError != nil {
+				pool.OnError("", err)
+			}
+			return n, err
+		}
+
+		// Add users to cache
+		for i := range out.Users {
+			user := &out.Users[i]
+			sub, ok := c.coginto.UserAttributes(user)["sub"]
+			if !ok {
+				continue
+			}
+			pool.Set(sub, user)
+			n++
+		}
+
+		pagination = out.PaginationToken
+		if pagination == nil || *pagination == "" {
+			return n, nil
+		}
+	}
 }
 
 // Len returns the length of the cache for a given userPoolId.
 func (c *Cache) Len(userPoolId string) int {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-	return len(c.cache[userPoolId])
+	pool := c.pool(userPoolId, false)
+	if pool == nil {
+		return 0
+	}
+	return pool.Len()
+}
+
+// Stats returns the cache counters for a given userPoolId.
+func (c *Cache) Stats(userPoolId string) CacheStats {
+	pool := c.pool(userPoolId, false)
+	if pool == nil {
+		return CacheStats{}
+	}
+	return pool.Stats()
 }
 
-// Clear clears the cache for a given userPoolId.
+// Delete removes the user from the cache for a given userPoolId. Use it after
+// the user attributes update, the next Get reads the user from Cognito.
+func (c *Cache) Delete(userPoolId, sub string) {
+	if pool := c.pool(userPoolId, false); pool != nil {
+		pool.Delete(sub)
+	}
+}
+
+// Clear clears the cache for a given userPoolId. The cache settings changed
+// after the user pool cache was created are applied after Clear.
 func (c *Cache) Clear(userPoolId string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	delete(c.cache, userPoolId)
+	if pool, ok := c.cache[userPoolId]; ok {
+		pool.Clear()
+		delete(c.cache, userPoolId)
+	}
 }
@@ -6,10 +6,16 @@
 package aws
 
 import (
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
+
+	smithyhttp "github.com/aws/smithy-go/transport/http"
 )
 
 // var cognitoUserPool string
@@ -112,3 +118,201 @@ func TestCognitoCache(t *testing.T) {
 	}
 	fmt.Printf("get user by sub after clear cache: %s, %s\n", sub, time.Since(start))
 }
+
+func TestCognitoCacheMaxEntries(t *testing.T) {
+
+	fake := NewFakeCognito("pool")
+	var subs []string
+	for _, name := range []string{"user1", "user2", "user3"} {
+		subs = append(subs, fake.AddUser("pool", name, nil))
+	}
+	c := &NewFromConfig(fake.Config()).Cognito.Cache
+	c.MaxEntries = 2
+
+	// Get three users, the least recently used one is evicted
+	for _, sub := range []string{subs[0], subs[1], subs[0], subs[2]} {
+		if _, err := c.Get("pool", sub); err != nil {
+			t.Fatal("get:", err)
+		}
+	}
+
+	if l := c.Len("pool"); l != 2 {
+		t.Error("wrong cache length:", l)
+		return
+	}
+	if c.Contains("pool", subs[1]) {
+		t.Error("least recently used user is not evicted")
+	}
+	if !c.Contains("pool", subs[0]) {
+		t.Error("recently used user is evicted")
+	}
+}
+
+func TestCognitoCacheDelete(t *testing.T) {
+
+	var c Cache
+	c.init(nil)
+
+	pool := c.pool("pool", true)
+	pool.Set("sub1", &UserType{})
+	pool.Set("sub2", &UserType{})
+	c.Delete("pool", "sub1")
+	c.Delete("pool", "sub3")
+	c.Delete("other", "sub1")
+
+	if l := c.Len("pool"); l != 1 {
+		t.Error("wrong cache length:", l)
+	}
+	if l := c.Len("other"); l != 0 {
+		t.Error("wrong other pool cache length:", l)
+	}
+	if c.Contains("pool", "sub1") {
+		t.Error("deleted user is in cache")
+	}
+}
+
+func TestCognitoCacheTTL(t *testing.T) {
+
+	fake := NewFakeCognito("pool")
+	sub := fake.AddUser("pool", "user", nil)
+	clk := NewFakeClock(time.Now())
+	c := &NewFromConfig(fake.Config(), WithClock(clk)).Cognito.Cache
+	c.TTL = 50 * time.Millisecond
+	c.RefreshAhead = 20 * time.Millisecond
+
+	if _, err := c.Get("pool", sub); err != nil {
+		t.Fatal("get:", err)
+	}
+	if !c.Contains("pool", sub) {
+		t.Error("user is expired right after adding")
+		return
+	}
+
+	// Refresh time is between RefreshAhead and half of RefreshAhead before
+	// expiry
+	pool := c.pool("pool", false)
+	entry := pool.entries[sub].Value.(*lookupEntry[string, *UserType])
+	ahead := entry.cachedAt.Add(c.TTL).Sub(entry.refreshAt)
+	if ahead < c.RefreshAhead/2 || ahead > c.RefreshAhead {
+		t.Error("wrong refresh time:", ahead)
+	}
+
+	clk.Advance(c.TTL)
+	if c.Contains("pool", sub) {
+		t.Error("user is not expired after TTL")
+	}
+}
+
+func TestCognitoCacheOnError(t *testing.T) {
+
+	var calls []string
+	onError := func(userPoolId, sub string, err error) {
+		calls = append(calls, userPoolId+"/"+sub)
+	}
+
+	// The user not found result is not an error
+	c := &NewFromConfig(NewFakeCognito("pool").Config()).Cognito.Cache
+	c.OnError = onError
+	if _, err := c.Get("pool", "none"); !errors.Is(err,
+		ErrCognitoUserNotFound) {
+		t.Error("wrong not found error:", err)
+	}
+
+	// Cognito failure
+	c = &newErrorTestAws(http.StatusBadRequest,
+		`{"__type":"NotAuthorizedException","message":"denied"}`).Cognito.Cache
+	c.OnError = onError
+	if _, err := c.Get("pool", "sub"); err == nil {
+		t.Error("get does not return error")
+	}
+
+	if fmt.Sprint(calls) != "[pool/sub]" {
+		t.Error("wrong OnError calls:", calls)
+	}
+}
+
+// TestCognitoCacheSingleflight checks the concurrent Get calls of the same
+// user send one Cognito request and the request result is not cached if the
+// user is deleted from the cache during the request
+func TestCognitoCacheSingleflight(t *testing.T) {
+
+	fake := NewFakeCognito("pool")
+	sub := fake.AddUser("pool", "user", nil)
+	var requests atomic.Int32
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	cfg := fake.Config()
+	cfg.HTTPClient = smithyhttp.ClientDoFunc(
+		func(r *http.Request) (*http.Response, error) {
+			requests.Add(1)
+			select {
+			case started <- struct{}{}:
+			default:
+			}
+			<-release
+			return fake.Do(r)
+		})
+	c := &NewFromConfig(cfg).Cognito.Cache
+
+	// Concurrent Get calls wait for the first request
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if user, err := c.Get("pool", sub); err != nil ||
+				user.Username == nil || *user.Username != "user" {
+				t.Error("wrong user:", user, err)
+			}
+		}()
+	}
+	<-started
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if n := requests.Load(); n != 1 || !c.Contains("pool", sub) {
+		t.Error("wrong number of requests:", n)
+	}
+
+	// The user deleted during the request is not cached
+	c.Clear("pool")
+	release = make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		c.Get("pool", sub)
+	}()
+	<-started
+	c.Delete("pool", sub)
+	close(release)
+	<-done
+	if n := requests.Load(); n != 2 || c.Contains("pool", sub) {
+		t.Error("deleted user is cached:", n)
+	}
+}
+
+func TestCognitoCacheWarm(t *testing.T) {
+
+	if cognitoUserPool == "" {
+		t.Skip()
+		return
+	}
+
+	a, err := New()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	start := time.Now()
+	n, err := a.Cognito.Cache.Warm(cognitoUserPool, "")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	fmt.Printf("warm cache with %d users: %s\n", n, time.Since(start))
+
+	if l := a.Cognito.Cache.Len(cognitoUserPool); l != n {
+		t.Error("wrong cache length:", l)
+	}
+}
@@ -0,0 +1,150 @@
+package aws
+
+import (
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+)
+
+// DeviceType is a device remembered by a Cognito user pool.
+type DeviceType = types.DeviceType
+
+// AdminListDevices lists the devices remembered for a user.
+//
+// Parameters:
+//   - userPoolId: The ID of the user pool.
+//   - username: The user name.
+//   - limit: The maximum number of devices to return.
+//   - previous: An identifier that was returned from the previous call to this
+//     operation, which can be used to return the next set of items in the list.
+//
+// Returns:
+//   - devices: A list of user devices.
+//   - pagination: A token to continue the list from if there are more devices.
+//   - err: An error if the operation fails.
+func (a awsCognito) AdminListDevices(userPoolId, username string, limit int,
+	previous *string) (devices []DeviceType, pagination *string, err error) {
+
+	// Call the AdminListDevices API to retrieve the user devices.
+	out, err := a.Client.AdminListDevices(a.ctx,
+		&cognitoidentityprovider.AdminListDevicesInput{
+			UserPoolId:      aws.String(userPoolId),
+			Username:        aws.String(username),
+			Limit:           aws.Int32(int32(limit)),
+			PaginationToken: previous,
+		},
+	)
+	if err != nil {
+		return
+	}
+
+	// Return the list of devices and the pagination token.
+	pagination = out.PaginationToken
+	devices = out.Devices
+	return
+}
+
+// AdminForgetDevice forgets (revokes) the remembered device of a user.
+//
+// Parameters:
+//   - userPoolId: The ID of the user pool.
+//   - username: The user name.
+//   - deviceKey: The device key.
+//
+// Returns:
+//   - err: An error if the operation fails.
+func (a awsCognito) AdminForgetDevice(userPoolId, username,
+	deviceKey string) (err error) {
+
+	_, err = a.Client.AdminForgetDevice(a.ctx,
+		&cognitoidentityprovider.AdminForgetDeviceInput{
+			UserPoolId: aws.String(userPoolId),
+			Username:   aws.String(username),
+			DeviceKey:  aws.String(deviceKey),
+		},
+	)
+	return
+}
+
+// AdminForgetAllDevices forgets all remembered devices of a user. All devices
+// are forgotten even if some of them fail, the failed devices are returned in
+// the *BatchError.
+//
+// Parameters:
+//   - userPoolId: The ID of the user pool.
+//   - username: The user name.
+//
+// Returns:
+//   - forgotten: The number of forgotten devices.
+//   - err: An error if the operation fails.
+func (a awsCognito) AdminForgetAllDevices(userPoolId, username string) (
+	forgotten int, err error) {
+
+	// Get all user devices first, forgetting devices while listing them may
+	// break the pagination
+	var devices []DeviceType
+	var pagination *string
+	for {
+		var page []DeviceType
+		page, pagination, err = a.AdminListDevices(userPoolId, username, 60,
+			pagination)
+		if err != nil {
+			return
+		}
+		devices = append(devices, page...)
+		if pagination == nil {
+			break
+		}
+	}
+
+	// Forget devices
+	var batchErr BatchError
+	for _, device := range devices {
+		deviceKey := aws.ToString(device.DeviceKey)
+		e := a.AdminForgetDevice(userPoolId, username, deviceKey)
+		batchErr.add(deviceKey, e)
+		if e == nil {
+			forgotten++
+		}
+	}
+	err = batchErr.err()
+
+	return
+}
+
+// ConfirmDevice confirms a device tracking of the signed in user and sets the
+// device name.
+//
+// Parameters:
+//   - accessToken: The access token of the signed in user.
+//   - deviceKey: The device key from the NewDeviceMetadata of the
+//     authentication result.
+//   - deviceName: The device name.
+//   - passwordVerifier: The SRP password verifier of the device.
+//   - salt: The SRP salt of the device.
+//
+// Returns:
+//   - userConfirmationNecessary: Is true if the user must confirm the device
+//     remembering (the user pool device remembering is "user opt-in").
+//   - err: An error if the operation fails.
+func (a awsCognito) ConfirmDevice(accessToken, deviceKey, deviceName,
+	passwordVerifier, salt string) (userConfirmationNecessary bool, err error) {
+
+	out, err := a.Client.ConfirmDevice(a.ctx,
+		&cognitoidentityprovider.ConfirmDeviceInput{
+			AccessToken: aws.String(accessToken),
+			DeviceKey:   aws.String(deviceKey),
+			DeviceName:  aws.String(deviceName),
+			DeviceSecretVerifierConfig: &types.DeviceSecretVerifierConfigType{
+				PasswordVerifier: aws.String(passwordVerifier),
+				Salt:             aws.String(salt),
+			},
+		},
+	)
+	if err != nil {
+		return
+	}
+	userConfirmationNecessary = out.UserConfirmationNecessary
+
+	return
+}
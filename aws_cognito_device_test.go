@@ -0,0 +1,66 @@
+package aws
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// TestCognitoForgetAllDevices checks all pages of the devices are listed
+// before forgetting and the failed devices are returned in the batch error
+func TestCognitoForgetAllDevices(t *testing.T) {
+
+	client := &pagesHTTPClient{bodies: []string{
+		`{"Devices":[{"DeviceKey":"d-1"},{"DeviceKey":"d-2"}],` +
+			`"PaginationToken":"p1"}`,
+		`{"Devices":[{"DeviceKey":"d-3"}]}`,
+		`{}`,
+		`{"__type":"ResourceNotFoundException","message":"device not found"}`,
+		`{}`,
+	}, statuses: []int{http.StatusOK, http.StatusOK, http.StatusOK,
+		http.StatusBadRequest, http.StatusOK}}
+	a := newPagesTestAws(client)
+
+	forgotten, err := a.Cognito.AdminForgetAllDevices("pool", "user")
+	var batchErr *BatchError
+	if forgotten != 2 || !errors.As(err, &batchErr) || batchErr.Total != 3 ||
+		len(batchErr.Failed) != 1 || batchErr.Failed[0].Item != "d-2" ||
+		!IsNotFound(batchErr.Failed[0].Err) {
+		t.Fatal("wrong forget all devices:", forgotten, err)
+	}
+	if !strings.Contains(client.requests[0], `"Limit":60`) ||
+		!strings.Contains(client.requests[1], `"PaginationToken":"p1"`) {
+		t.Error("wrong list devices requests:", client.requests[:2])
+	}
+	for i, key := range []string{"d-1", "d-2", "d-3"} {
+		if req := client.requests[2+i]; !strings.Contains(req,
+			`"DeviceKey":"`+key+`"`) ||
+			!strings.Contains(req, `"Username":"user"`) {
+			t.Error("wrong forget device request:", req)
+		}
+	}
+}
+
+// TestCognitoConfirmDevice checks the device verifier is sent and the user
+// confirmation flag is returned
+func TestCognitoConfirmDevice(t *testing.T) {
+
+	client := &pagesHTTPClient{bodies: []string{
+		`{"UserConfirmationNecessary":true}`,
+	}}
+	a := newPagesTestAws(client)
+
+	necessary, err := a.Cognito.ConfirmDevice("token", "d-1", "phone",
+		"verifier", "salt")
+	if err != nil || !necessary {
+		t.Fatal("wrong confirm device:", necessary, err)
+	}
+	for _, want := range []string{`"AccessToken":"token"`,
+		`"DeviceName":"phone"`, `"PasswordVerifier":"verifier"`,
+		`"Salt":"salt"`} {
+		if !strings.Contains(client.requests[0], want) {
+			t.Error("wrong confirm device request:", want, client.requests[0])
+		}
+	}
+}
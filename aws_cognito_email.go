@@ -0,0 +1,173 @@
+package aws
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+)
+
+// ErrCognitoEmailExists is returned by the email change functions when the
+// new email is already used by another user of the user pool.
+var ErrCognitoEmailExists = errors.New("email already exists")
+
+// ErrCognitoInvalidEmail is returned by the email change functions when
+// Cognito rejects the new email.
+var ErrCognitoInvalidEmail = errors.New("invalid email")
+
+// ErrCognitoCodeMismatch is returned by ConfirmEmail when the verification
+// code is wrong.
+var ErrCognitoCodeMismatch = errors.New("verification code mismatch")
+
+// ErrCognitoCodeExpired is returned by ConfirmEmail when the verification
+// code is expired.
+var ErrCognitoCodeExpired = errors.New("verification code expired")
+
+// emailError translates Cognito email change errors to typed errors. The
+// original error is wrapped too.
+func emailError(err error) error {
+	var aliasExists *types.AliasExistsException
+	var invalidParameter *types.InvalidParameterException
+	var codeMismatch *types.CodeMismatchException
+	var expiredCode *types.ExpiredCodeException
+	switch {
+	case errors.As(err, &aliasExists):
+		return fmt.Errorf("%w: %w", ErrCognitoEmailExists, err)
+	case errors.As(err, &invalidParameter):
+		return fmt.Errorf("%w: %w", ErrCognitoInvalidEmail, err)
+	case errors.As(err, &codeMismatch):
+		return fmt.Errorf("%w: %w", ErrCognitoCodeMismatch, err)
+	case errors.As(err, &expiredCode):
+		return fmt.Errorf("%w: %w", ErrCognitoCodeExpired, err)
+	}
+	return cognitoThrottle(err)
+}
+
+// emailUsed checks whether the email is used by other user than username.
+func (a awsCognito) emailUsed(userPoolId, username, email string) (
+	used bool, err error) {
+
+	filter := "email = \"" + strings.ReplaceAll(email, `"`, `\"`) + "\""
+	users, _, err := a.List(userPoolId, 2, filter, nil)
+	if err != nil {
+		return
+	}
+	for _, user := range users {
+		if aws.ToString(user.Username) != username {
+			used = true
+			return
+		}
+	}
+	return
+}
+
+// AdminChangeEmail changes the user email.
+//
+// The email is checked for uniqueness in the user pool before update, so
+// it works for user pools where email is not an alias too.
+//
+// If verified is true the email_verified attribute is set to true and no
+// verification code is sent. If verified is false the email_verified
+// attribute is set to false and Cognito sends the verification code to the
+// new email (if the user pool verifies emails). The user confirms it with
+// the ConfirmEmail function.
+//
+// Parameters:
+//   - userPoolId: The ID of the user pool.
+//   - username: The user name.
+//   - email: The new email.
+//   - verified: Set the new email verified.
+//
+// Returns:
+//   - err: An error if the operation fails. The ErrCognitoEmailExists or
+//     ErrCognitoInvalidEmail may be checked with errors.Is.
+func (a awsCognito) AdminChangeEmail(userPoolId, username, email string,
+	verified bool) (err error) {
+
+	// Check email uniqueness
+	used, err := a.emailUsed(userPoolId, username, email)
+	if err != nil {
+		return
+	}
+	if used {
+		err = ErrCognitoEmailExists
+		return
+	}
+
+	// Update email and email_verified attributes
+	_, err = a.Client.AdminUpdateUserAttributes(a.ctx,
+		&cognitoidentityprovider.AdminUpdateUserAttributesInput{
+			UserPoolId: aws.String(userPoolId),
+			Username:   aws.String(username),
+			UserAttributes: []types.AttributeType{
+				{Name: aws.String("email"), Value: aws.String(email)},
+				{
+					Name:  aws.String("email_verified"),
+					Value: aws.String(strconv.FormatBool(verified)),
+				},
+			},
+		},
+	)
+	if err != nil {
+		err = emailError(err)
+	}
+
+	return
+}
+
+// ChangeEmail changes the email of the signed in user. Cognito sends the
+// verification code to the new email, and the user confirms it with the
+// ConfirmEmail function.
+//
+// Parameters:
+//   - accessToken: The access token of the signed in user.
+//   - email: The new email.
+//
+// Returns:
+//   - delivery: The code delivery details, nil if the code was not sent.
+//   - err: An error if the operation fails. The ErrCognitoEmailExists or
+//     ErrCognitoInvalidEmail may be checked with errors.Is.
+func (a awsCognito) ChangeEmail(accessToken, email string) (
+	delivery *CodeDelivery, err error) {
+
+	out, err := a.Client.UpdateUserAttributes(a.ctx,
+		&cognitoidentityprovider.UpdateUserAttributesInput{
+			AccessToken: aws.String(accessToken),
+			UserAttributes: []types.AttributeType{
+				{Name: aws.String("email"), Value: aws.String(email)},
+			},
+		},
+	)
+	if err != nil {
+		err = emailError(err)
+		return
+	}
+	if len(out.CodeDeliveryDetailsList) > 0 {
+		delivery = &out.CodeDeliveryDetailsList[0]
+	}
+
+	return
+}
+
+// ConfirmEmail confirms the email of the signed in user with the verification
+// code and sets email_verified to true.
+//
+// Parameters:
+//   - accessToken: The access token of the signed in user.
+//   - code: The verification code.
+//
+// Returns:
+//   - err: An error if the operation fails. The ErrCognitoCodeMismatch,
+//     ErrCognitoCodeExpired or ErrCognitoEmailExists may be checked with
+//     errors.Is.
+func (a awsCognito) ConfirmEmail(accessToken, code string) (err error) {
+	err = a.VerifyUserAttribute(accessToken, "email", code)
+	if err != nil {
+		err = emailError(err)
+	}
+	return
+}
@@ -0,0 +1,121 @@
+package aws
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// newCognitoTargetsTestAws creates Aws which clients receive the client
+// response bodies and saves the request targets.
+func newCognitoTargetsTestAws(client *pagesHTTPClient,
+	targets *[]string) *Aws {
+
+	cfg := newPagesTestAws(client).Config()
+	cfg.HTTPClient = smithyhttp.ClientDoFunc(
+		func(r *http.Request) (*http.Response, error) {
+			target := r.Header.Get("X-Amz-Target")
+			*targets = append(*targets, target[strings.Index(target, ".")+1:])
+			return client.Do(r)
+		})
+	return NewFromConfig(cfg)
+}
+
+// TestCognitoAdminChangeEmail checks the email uniqueness is checked and the
+// email and email_verified attributes are updated
+func TestCognitoAdminChangeEmail(t *testing.T) {
+
+	var targets []string
+	client := &pagesHTTPClient{bodies: []string{
+		`{"Users":[{"Username":"user"}]}`,
+		`{}`,
+		`{"Users":[{"Username":"other"}]}`,
+	}}
+	a := newCognitoTargetsTestAws(client, &targets)
+
+	err := a.Cognito.AdminChangeEmail("pool", "user", "new@example.com", true)
+	if err != nil {
+		t.Fatal("change email:", err)
+	}
+	if !strings.Contains(client.requests[0],
+		`"Filter":"email = \"new@example.com\""`) {
+		t.Error("wrong email check request:", client.requests[0])
+	}
+	if !strings.Contains(client.requests[1], `"UserAttributes":[`+
+		`{"Name":"email","Value":"new@example.com"},`+
+		`{"Name":"email_verified","Value":"true"}]`) ||
+		!strings.Contains(client.requests[1], `"Username":"user"`) {
+		t.Error("wrong update attributes request:", client.requests[1])
+	}
+
+	// The email of the other user is not updated
+	err = a.Cognito.AdminChangeEmail("pool", "user", "new@example.com", false)
+	if !errors.Is(err, ErrCognitoEmailExists) {
+		t.Error("wrong used email error:", err)
+	}
+	if s := strings.Join(targets, ","); s != "ListUsers,"+
+		"AdminUpdateUserAttributes,ListUsers" {
+		t.Error("wrong requests:", s)
+	}
+}
+
+// TestCognitoAdminChangeEmailUnverified checks the email_verified attribute
+// is set to false and the invalid email error is translated
+func TestCognitoAdminChangeEmailUnverified(t *testing.T) {
+
+	client := &pagesHTTPClient{bodies: []string{`{"Users":[]}`, `{}`,
+		`{"Users":[]}`,
+		`{"__type":"InvalidParameterException","message":"invalid email"}`,
+	}, statuses: []int{http.StatusOK, http.StatusOK, http.StatusOK,
+		http.StatusBadRequest}}
+	a := newPagesTestAws(client)
+
+	err := a.Cognito.AdminChangeEmail("pool", "user", "new@example.com", false)
+	if err != nil || !strings.Contains(client.requests[1],
+		`{"Name":"email_verified","Value":"false"}`) {
+		t.Error("wrong unverified email change:", err, client.requests[1])
+	}
+	err = a.Cognito.AdminChangeEmail("pool", "user", "bad", false)
+	if !errors.Is(err, ErrCognitoInvalidEmail) {
+		t.Error("wrong invalid email error:", err)
+	}
+}
+
+// TestCognitoConfirmEmail checks the email is verified by VerifyUserAttribute
+// and the code errors are translated
+func TestCognitoConfirmEmail(t *testing.T) {
+
+	var targets []string
+	client := &pagesHTTPClient{bodies: []string{`{}`,
+		`{"__type":"CodeMismatchException","message":"wrong code"}`,
+		`{"__type":"ExpiredCodeException","message":"expired"}`,
+	}, statuses: []int{http.StatusOK, http.StatusBadRequest,
+		http.StatusBadRequest}}
+	a := newCognitoTargetsTestAws(client, &targets)
+
+	if err := a.Cognito.ConfirmEmail("token", "123456"); err != nil {
+		t.Fatal("confirm email:", err)
+	}
+	for _, want := range []string{`"AccessToken":"token"`,
+		`"AttributeName":"email"`, `"Code":"123456"`} {
+		if !strings.Contains(client.requests[0], want) {
+			t.Error("wrong verify request:", want, client.requests[0])
+		}
+	}
+
+	if err := a.Cognito.ConfirmEmail("token", "1"); !errors.Is(err,
+		ErrCognitoCodeMismatch) {
+		t.Error("wrong code mismatch error:", err)
+	}
+	if err := a.Cognito.ConfirmEmail("token", "1"); !errors.Is(err,
+		ErrCognitoCodeExpired) {
+		t.Error("wrong expired code error:", err)
+	}
+	if s := strings.Join(targets, ","); s != "VerifyUserAttribute,"+
+		"VerifyUserAttribute,VerifyUserAttribute" {
+		t.Error("wrong requests:", s)
+	}
+}
@@ -0,0 +1,146 @@
+package aws
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+)
+
+// AuthEvent is a user authentication event.
+type AuthEvent struct {
+	// ID is the event ID.
+	ID string
+
+	// Type is the event type: SignIn, SignUp, ForgotPassword, PasswordChange,
+	// ResendCode or PasswordReset.
+	Type string
+
+	// Created is the event creation time.
+	Created time.Time
+
+	// Response is the event response: Pass, Fail or InProgress.
+	Response string
+
+	// Risk is the event risk evaluation.
+	Risk AuthEventRisk
+
+	// Context is the user context data captured at the time of the event.
+	Context AuthEventContext
+
+	// Challenges maps challenge names (Password, Mfa) to challenge
+	// responses (Success, Failure).
+	Challenges map[string]string
+
+	// Feedback is the user or admin feedback value (Valid, Invalid) for the
+	// event, empty if no feedback was provided.
+	Feedback string
+}
+
+// AuthEventRisk is a risk evaluation of the authentication event.
+type AuthEventRisk struct {
+	// Decision is the risk decision: NoRisk, AccountTakeover or Block.
+	Decision string
+
+	// Level is the risk level: Low, Medium or High.
+	Level string
+
+	// CompromisedCredentialsDetected indicates whether compromised
+	// credentials were detected during the authentication event.
+	CompromisedCredentialsDetected bool
+}
+
+// AuthEventContext is the user context data of the authentication event.
+type AuthEventContext struct {
+	IpAddress  string
+	DeviceName string
+	City       string
+	Country    string
+	Timezone   string
+}
+
+// AuthEvents returns the history of user authentication events, the newest
+// events first.
+//
+// Parameters:
+//   - userPoolId: The ID of the user pool.
+//   - username: The user name.
+//   - max: The maximum number of events to return.
+//
+// Returns:
+//   - events: The list of user authentication events.
+//   - err: An error if the operation fails.
+func (a awsCognito) AuthEvents(userPoolId, username string, max int) (
+	events []AuthEvent, err error) {
+
+	var next *string
+	for len(events) < max {
+
+		// Get next page of events
+		out, err := a.Client.AdminListUserAuthEvents(a.ctx,
+			&cognitoidentityprovider.AdminListUserAuthEventsInput{
+				UserPoolId: aws.String(userPoolId),
+				Username:   aws.String(username),
+				MaxResults: aws.Int32(int32(min(max-len(events), 60))),
+				NextToken:  next,
+			},
+		)
+		if err != nil {
+			return events, err
+		}
+
+		// Convert events
+		for i := range out.AuthEvents {
+			events = append(events, newAuthEvent(&out.AuthEvents[i]))
+		}
+
+		next = out.NextToken
+		if next == nil || len(out.AuthEvents) == 0 {
+			break
+		}
+	}
+
+	return
+}
+
+// newAuthEvent converts cognito AuthEventType to AuthEvent.
+func newAuthEvent(e *types.AuthEventType) (event AuthEvent) {
+	event = AuthEvent{
+		ID:       aws.ToString(e.EventId),
+		Type:     string(e.EventType),
+		Created:  aws.ToTime(e.CreationDate),
+		Response: string(e.EventResponse),
+	}
+
+	if r := e.EventRisk; r != nil {
+		event.Risk = AuthEventRisk{
+			Decision:                       string(r.RiskDecision),
+			Level:                          string(r.RiskLevel),
+			CompromisedCredentialsDetected: aws.ToBool(r.CompromisedCredentialsDetected),
+		}
+	}
+
+	if c := e.EventContextData; c != nil {
+		event.Context = AuthEventContext{
+			IpAddress:  aws.ToString(c.IpAddress),
+			DeviceName: aws.ToString(c.DeviceName),
+			City:       aws.ToString(c.City),
+			Country:    aws.ToString(c.Country),
+			Timezone:   aws.ToString(c.Timezone),
+		}
+	}
+
+	if len(e.ChallengeResponses) > 0 {
+		event.Challenges = make(map[string]string, len(e.ChallengeResponses))
+		for _, c := range e.ChallengeResponses {
+			event.Challenges[string(c.ChallengeName)] = string(c.ChallengeResponse)
+		}
+	}
+
+	if f := e.EventFeedback; f != nil {
+		event.Feedback = string(f.FeedbackValue)
+	}
+
+	return
+}
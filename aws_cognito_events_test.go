@@ -0,0 +1,41 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+)
+
+func TestCognitoNewAuthEvent(t *testing.T) {
+
+	event := newAuthEvent(&types.AuthEventType{
+		EventId:       aws.String("id"),
+		EventType:     types.EventTypeSignIn,
+		EventResponse: types.EventResponseTypeFail,
+		EventRisk: &types.EventRiskType{
+			RiskDecision: types.RiskDecisionTypeBlock,
+			RiskLevel:    types.RiskLevelTypeHigh,
+		},
+		EventContextData: &types.EventContextDataType{
+			IpAddress: aws.String("192.0.2.1"),
+		},
+		ChallengeResponses: []types.ChallengeResponseType{{
+			ChallengeName:     types.ChallengeNamePassword,
+			ChallengeResponse: types.ChallengeResponseFailure,
+		}},
+	})
+
+	if event.ID != "id" || event.Type != "SignIn" || event.Response != "Fail" {
+		t.Error("wrong event:", event)
+	}
+	if event.Risk.Decision != "Block" || event.Risk.Level != "High" {
+		t.Error("wrong event risk:", event.Risk)
+	}
+	if event.Context.IpAddress != "192.0.2.1" {
+		t.Error("wrong event context:", event.Context)
+	}
+	if event.Challenges["Password"] != "Failure" {
+		t.Error("wrong event challenges:", event.Challenges)
+	}
+}
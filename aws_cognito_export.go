@@ -0,0 +1,249 @@
+package aws
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+)
+
+// ExportFormat is the output format of the awsCognito.Export function.
+type ExportFormat int
+
+const (
+	// ExportCSV writes users as CSV rows with a header line.
+	ExportCSV ExportFormat = iota
+
+	// ExportJSON writes users as a JSON array of objects.
+	ExportJSON
+)
+
+const (
+	// listUsersPageSize is the maximum number of users returned by one
+	// ListUsers call.
+	listUsersPageSize = 60
+
+	// listUsersRate is the number of ListUsers calls per second used by Export
+	// and Cache.Warm. It keeps the pool scans below the default ListUsers
+	// quota when other services use the same user pool.
+	listUsersRate = 5
+
+	// listUsersRetries is the number of retries of a throttled ListUsers call.
+	listUsersRetries = 5
+)
+
+// exportColumns are the user fields exported before the user attributes.
+var exportColumns = []string{"username", "status", "enabled", "created",
+	"modified"}
+
+// ExportUser is the user record written by awsCognito.Export in JSON format.
+type ExportUser struct {
+	Username   string            `json:"username"`
+	Status     string            `json:"status"`
+	Enabled    bool              `json:"enabled"`
+	Created    time.Time         `json:"created"`
+	Modified   time.Time         `json:"modified"`
+	Attributes map[string]string `json:"attributes"`
+}
+
+// Export writes all users of a user pool to w.
+//
+// Users are read page by page with rate limited ListUsers calls and each page
+// is written to w before the next page is requested, so the whole pool is
+// never kept in memory. Throttled ListUsers calls are retried with backoff.
+//
+// Parameters:
+//   - userPoolId: The ID of the user pool.
+//   - w: The writer to write users to.
+//   - format: The output format, ExportCSV or ExportJSON.
+//   - attrs: The user attributes to export. If attrs is empty all attributes
+//     are exported in JSON format, and no attribute columns are written in
+//     CSV format.
+//
+// Returns:
+//   - err: An error if the operation fails.
+func (a awsCognito) Export(userPoolId string, w io.Writer, format ExportFormat,
+	attrs []string) (err error) {
+
+	// Create users writer for selected format
+	var ew exportWriter
+	switch format {
+	case ExportCSV:
+		ew = &exportCSV{w: csv.NewWriter(w), attrs: attrs}
+	case ExportJSON:
+		ew = &exportJSON{w: w, attrs: attrs}
+	default:
+		err = fmt.Errorf("unknown export format %d", format)
+		return
+	}
+
+	// Write header
+	if err = ew.header(); err != nil {
+		return
+	}
+
+	// Limit ListUsers calls rate
+	ticker := clock(a.ctx).NewTicker(time.Second / listUsersRate)
+	defer ticker.Stop()
+
+	// Read users page by page and write them
+	var pagination *string
+	for {
+		<-ticker.C()
+
+		var out *cognitoidentityprovider.ListUsersOutput
+		out, err = a.listUsersPage(userPoolId, "", attrs, pagination)
+		if err != nil {
+			return
+		}
+
+		for i := range out.Users {
+			if err = ew.user(&out.Users[i]); err != nil {
+				return
+			}
+		}
+
+		pagination = out.PaginationToken
+		if pagination == nil || *pagination == "" {
+			break
+		}
+	}
+
+	// Write footer
+	err = ew.footer()
+	return
+}
+
+// listUsersPage gets one page of users and retries throttled requests.
+func (a awsCognito) listUsersPage(userPoolId, filter string, attrs []string,
+	pagination *string) (out *cognitoidentityprovider.ListUsersOutput, err error) {
+
+	input := &cognitoidentityprovider.ListUsersInput{
+		UserPoolId:      aws.String(userPoolId),
+		Limit:           aws.Int32(listUsersPageSize),
+		PaginationToken: pagination,
+	}
+	if filter != "" {
+		input.Filter = aws.String(filter)
+	}
+	if len(attrs) > 0 {
+		input.AttributesToGet = attrs
+	}
+
+	delay := time.Second
+	for i := 0; ; i++ {
+		out, err = a.Client.ListUsers(a.ctx, input)
+
+		// Return if there is no throttling error or retries are over
+		var throttled *types.TooManyRequestsException
+		if err == nil || !errors.As(err, &throttled) || i >= listUsersRetries {
+			return
+		}
+
+		// Wait and retry
+		sleep(clock(a.ctx), delay)
+		delay *= 2
+	}
+}
+
+// exportWriter writes users in one of export formats.
+type exportWriter interface {
+	header() error
+	user(user *UserType) error
+	footer() error
+}
+
+// exportCSV writes users in CSV format.
+type exportCSV struct {
+	w     *csv.Writer
+	attrs []string
+}
+
+func (e *exportCSV) header() error {
+	e.w.Write(append(append([]string{}, exportColumns...), e.attrs...))
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func (e *exportCSV) user(user *UserType) error {
+	m := awsCognito{}.UserAttributes(user)
+
+	row := []string{
+		aws.ToString(user.Username),
+		string(user.UserStatus),
+		strconv.FormatBool(user.Enabled),
+		aws.ToTime(user.UserCreateDate).Format(time.RFC3339),
+		aws.ToTime(user.UserLastModifiedDate).Format(time.RFC3339),
+	}
+	for _, attr := range e.attrs {
+		row = append(row, m[attr])
+	}
+
+	e.w.Write(row)
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func (e *exportCSV) footer() error { return nil }
+
+// exportJSON writes users as JSON array.
+type exportJSON struct {
+	w     io.Writer
+	attrs []string
+	next  bool
+}
+
+func (e *exportJSON) header() (err error) {
+	_, err = io.WriteString(e.w, "[\n")
+	return
+}
+
+func (e *exportJSON) user(user *UserType) (err error) {
+	m := awsCognito{}.UserAttributes(user)
+
+	// Keep selected attributes only
+	if len(e.attrs) > 0 {
+		selected := make(map[string]string, len(e.attrs))
+		for _, attr := range e.attrs {
+			if v, ok := m[attr]; ok {
+				selected[attr] = v
+			}
+		}
+		m = selected
+	}
+
+	data, err := json.Marshal(ExportUser{
+		Username:   aws.ToString(user.Username),
+		Status:     string(user.UserStatus),
+		Enabled:    user.Enabled,
+		Created:    aws.ToTime(user.UserCreateDate),
+		Modified:   aws.ToTime(user.UserLastModifiedDate),
+		Attributes: m,
+	})
+	if err != nil {
+		return
+	}
+
+	// Add separator before all users except first
+	if e.next {
+		if _, err = io.WriteString(e.w, ",\n"); err != nil {
+			return
+		}
+	}
+	e.next = true
+
+	_, err = e.w.Write(data)
+	return
+}
+
+func (e *exportJSON) footer() (err error) {
+	_, err = io.WriteString(e.w, "\n]\n")
+	return
+}
@@ -0,0 +1,84 @@
+package aws
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// cognitoExportPages are the ListUsers responses of two users pages.
+var cognitoExportPages = []string{
+	`{"Users":[{"Username":"u1","UserStatus":"CONFIRMED","Enabled":true,` +
+		`"UserCreateDate":1577836800,"UserLastModifiedDate":1577923200,` +
+		`"Attributes":[{"Name":"email","Value":"u1@example.com"},` +
+		`{"Name":"name","Value":"User, One"}]}],"PaginationToken":"p1"}`,
+	`{"Users":[{"Username":"u2","UserStatus":"UNCONFIRMED","Enabled":false,` +
+		`"UserCreateDate":1577836800,"UserLastModifiedDate":1577836800,` +
+		`"Attributes":[{"Name":"name","Value":"User Two"}]}]}`,
+}
+
+// TestCognitoExportCSV checks the users pages are written as CSV rows with
+// the selected attributes columns
+func TestCognitoExportCSV(t *testing.T) {
+
+	client := &pagesHTTPClient{bodies: cognitoExportPages}
+	a := newPagesTestAws(client)
+
+	var buf bytes.Buffer
+	err := a.Cognito.Export("pool", &buf, ExportCSV, []string{"email", "name"})
+	if err != nil {
+		t.Fatal("export:", err)
+	}
+	want := "username,status,enabled,created,modified,email,name\n" +
+		"u1,CONFIRMED,true,2020-01-01T00:00:00Z,2020-01-02T00:00:00Z," +
+		"u1@example.com,\"User, One\"\n" +
+		"u2,UNCONFIRMED,false,2020-01-01T00:00:00Z,2020-01-01T00:00:00Z,," +
+		"User Two\n"
+	if buf.String() != want {
+		t.Errorf("wrong CSV export:\n%s\nwant:\n%s", buf.String(), want)
+	}
+
+	if len(client.requests) != 2 ||
+		!strings.Contains(client.requests[0], `"Limit":60`) ||
+		!strings.Contains(client.requests[0],
+			`"AttributesToGet":["email","name"]`) ||
+		!strings.Contains(client.requests[1], `"PaginationToken":"p1"`) {
+		t.Error("wrong list users requests:", client.requests)
+	}
+}
+
+// TestCognitoExportJSON checks the users pages are written as one JSON array
+// with all attributes
+func TestCognitoExportJSON(t *testing.T) {
+
+	client := &pagesHTTPClient{bodies: cognitoExportPages}
+	a := newPagesTestAws(client)
+
+	var buf bytes.Buffer
+	if err := a.Cognito.Export("pool", &buf, ExportJSON, nil); err != nil {
+		t.Fatal("export:", err)
+	}
+	var users []ExportUser
+	if err := json.Unmarshal(buf.Bytes(), &users); err != nil {
+		t.Fatal("wrong JSON export:", buf.String(), err)
+	}
+	if len(users) != 2 || users[0].Username != "u1" || !users[0].Enabled ||
+		users[0].Attributes["email"] != "u1@example.com" ||
+		users[0].Attributes["name"] != "User, One" ||
+		users[0].Modified.Unix() != 1577923200 ||
+		users[1].Status != "UNCONFIRMED" || users[1].Enabled ||
+		len(users[1].Attributes) != 1 {
+		t.Error("wrong exported users:", users)
+	}
+	if strings.Contains(client.requests[0], "AttributesToGet") {
+		t.Error("attributes are selected:", client.requests[0])
+	}
+
+	// Unknown format
+	var empty bytes.Buffer
+	if err := a.Cognito.Export("pool", &empty, ExportFormat(9), nil); err == nil ||
+		empty.Len() != 0 {
+		t.Error("wrong unknown format export:", err, empty.String())
+	}
+}
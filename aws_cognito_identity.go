@@ -0,0 +1,163 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentity"
+)
+
+// awsCognitoIdentity is the AWS Cognito Identity (federated identity pools)
+// client struct.
+type awsCognitoIdentity struct {
+	// ctx is the context.Context for AWS requests
+	ctx context.Context
+
+	// cfg is the AWS config used to create Aws from identity credentials
+	cfg aws.Config
+
+	// opts are the options used to create Aws from identity credentials
+	opts []Option
+
+	// Client is the AWS Cognito Identity client
+	Client *cognitoidentity.Client
+}
+
+// UserPoolLogin returns logins map for the Cognito user pool ID token.
+//
+// Parameters:
+//   - region: The user pool region.
+//   - userPoolId: The ID of the user pool.
+//   - idToken: The ID token of the signed in user.
+func UserPoolLogin(region, userPoolId, idToken string) map[string]string {
+	provider := fmt.Sprintf("cognito-idp.%s.amazonaws.com/%s", region,
+		userPoolId)
+	return map[string]string{provider: idToken}
+}
+
+// GetId returns the identity ID of the user in the identity pool. The
+// identity is created if it does not exist.
+//
+// Parameters:
+//   - identityPoolId: The ID of the identity pool.
+//   - logins: The provider tokens map, see UserPoolLogin. The nil logins
+//     returns unauthenticated identity.
+//
+// Returns:
+//   - identityId: The identity ID.
+//   - err: An error if the operation fails.
+func (a awsCognitoIdentity) GetId(identityPoolId string,
+	logins map[string]string) (identityId string, err error) {
+
+	out, err := a.Client.GetId(a.ctx, &cognitoidentity.GetIdInput{
+		IdentityPoolId: aws.String(identityPoolId),
+		Logins:         logins,
+	})
+	if err != nil {
+		return
+	}
+	identityId = aws.ToString(out.IdentityId)
+
+	return
+}
+
+// GetCredentialsForIdentity returns temporary AWS credentials of the identity.
+//
+// Parameters:
+//   - identityId: The identity ID returned by GetId.
+//   - logins: The provider tokens map, see UserPoolLogin.
+//
+// Returns:
+//   - creds: The temporary AWS credentials.
+//   - err: An error if the operation fails.
+func (a awsCognitoIdentity) GetCredentialsForIdentity(identityId string,
+	logins map[string]string) (creds aws.Credentials, err error) {
+
+	out, err := a.Client.GetCredentialsForIdentity(a.ctx,
+		&cognitoidentity.GetCredentialsForIdentityInput{
+			IdentityId: aws.String(identityId),
+			Logins:     logins,
+		},
+	)
+	if err != nil {
+		return
+	}
+
+	c := out.Credentials
+	creds = aws.Credentials{
+		AccessKeyID:     aws.ToString(c.AccessKeyId),
+		SecretAccessKey: aws.ToString(c.SecretKey),
+		SessionToken:    aws.ToString(c.SessionToken),
+		Source:          "CognitoIdentity",
+		CanExpire:       c.Expiration != nil,
+		Expires:         aws.ToTime(c.Expiration),
+	}
+
+	return
+}
+
+// Credentials returns temporary AWS credentials of the user in the identity
+// pool. It calls GetId and GetCredentialsForIdentity.
+//
+// Parameters:
+//   - identityPoolId: The ID of the identity pool.
+//   - logins: The provider tokens map, see UserPoolLogin.
+//
+// Returns:
+//   - creds: The temporary AWS credentials.
+//   - err: An error if the operation fails.
+func (a awsCognitoIdentity) Credentials(identityPoolId string,
+	logins map[string]string) (creds aws.Credentials, err error) {
+
+	identityId, err := a.GetId(identityPoolId, logins)
+	if err != nil {
+		return
+	}
+	return a.GetCredentialsForIdentity(identityId, logins)
+}
+
+// Aws returns new Aws which clients use the temporary AWS credentials of the
+// user in the identity pool. The credentials are refreshed with the same
+// logins when expired, so the tokens in logins must be valid while the
+// returned Aws is used.
+//
+// Parameters:
+//   - identityPoolId: The ID of the identity pool.
+//   - logins: The provider tokens map, see UserPoolLogin.
+//
+// Returns:
+//   - identityAws: The Aws with identity credentials.
+//   - err: An error if the operation fails.
+func (a awsCognitoIdentity) Aws(identityPoolId string,
+	logins map[string]string) (identityAws *Aws, err error) {
+
+	// Get identity ID once and check credentials
+	identityId, err := a.GetId(identityPoolId, logins)
+	if err != nil {
+		return
+	}
+	creds, err := a.GetCredentialsForIdentity(identityId, logins)
+	if err != nil {
+		return
+	}
+
+	// Create credentials provider which returns first credentials and
+	// refreshes them when expired
+	first := true
+	provider := aws.CredentialsProviderFunc(func(context.Context) (
+		aws.Credentials, error) {
+
+		if first {
+			first = false
+			return creds, nil
+		}
+		return a.GetCredentialsForIdentity(identityId, logins)
+	})
+
+	cfg := a.cfg.Copy()
+	cfg.Credentials = aws.NewCredentialsCache(provider)
+	identityAws = NewFromConfig(cfg, a.opts...)
+
+	return
+}
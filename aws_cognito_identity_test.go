@@ -0,0 +1,69 @@
+package aws
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// TestCognitoIdentityAws checks the identity credentials are used by the
+// identity Aws clients and refreshed with the same logins when expired
+func TestCognitoIdentityAws(t *testing.T) {
+
+	client := &pagesHTTPClient{bodies: []string{
+		`{"IdentityId":"us-east-1:id-1"}`,
+		`{"IdentityId":"us-east-1:id-1","Credentials":{"AccessKeyId":"AK1",` +
+			`"SecretKey":"s1","SessionToken":"t1","Expiration":1577836800}}`,
+		`{"Parameter":{"Value":"v1"}}`,
+		`{"IdentityId":"us-east-1:id-1","Credentials":{"AccessKeyId":"AK2",` +
+			`"SecretKey":"s2","SessionToken":"t2","Expiration":4102444800}}`,
+		`{"Parameter":{"Value":"v2"}}`,
+	}}
+	var keys []string
+	cfg := newPagesTestAws(client).Config()
+	cfg.HTTPClient = smithyhttp.ClientDoFunc(
+		func(r *http.Request) (*http.Response, error) {
+			auth := r.Header.Get("Authorization")
+			if key, ok := strings.CutPrefix(auth,
+				"AWS4-HMAC-SHA256 Credential="); ok {
+				keys = append(keys, strings.Split(key, "/")[0])
+			}
+			return client.Do(r)
+		})
+	a := NewFromConfig(cfg)
+
+	logins := UserPoolLogin("us-east-1", "us-east-1_pool", "id-token")
+	identityAws, err := a.CognitoIdentity.Aws("us-east-1:pool", logins)
+	if err != nil {
+		t.Fatal("identity aws:", err)
+	}
+	for i, want := range []string{
+		`"IdentityPoolId":"us-east-1:pool"`,
+		`"IdentityId":"us-east-1:id-1"`,
+	} {
+		if !strings.Contains(client.requests[i], want) ||
+			!strings.Contains(client.requests[i], `"Logins":{`+
+				`"cognito-idp.us-east-1.amazonaws.com/us-east-1_pool":`+
+				`"id-token"}`) {
+			t.Error("wrong identity request:", want, client.requests[i])
+		}
+	}
+
+	// The first credentials are expired and refreshed by the second request
+	for _, want := range []string{"v1", "v2"} {
+		v, err := identityAws.SSM.GetParameter("/app/key")
+		if err != nil || v != want {
+			t.Fatal("wrong identity request:", v, err)
+		}
+	}
+	if len(client.requests) != 5 ||
+		!strings.Contains(client.requests[3], `"IdentityId":"us-east-1:id-1"`) {
+		t.Error("wrong refresh requests:", client.requests)
+	}
+	// The identity requests are not signed
+	if k := strings.Join(keys, ","); k != "AK1,AK2" {
+		t.Error("wrong request credentials:", k)
+	}
+}
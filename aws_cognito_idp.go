@@ -0,0 +1,249 @@
+package aws
+
+import (
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+)
+
+// IdentityProvider is a Cognito user pool identity provider description.
+type IdentityProvider = types.IdentityProviderType
+
+// IdentityProviderType is a Cognito identity provider type, for example
+// types.IdentityProviderTypeTypeSaml or types.IdentityProviderTypeTypeOidc.
+type IdentityProviderType = types.IdentityProviderTypeType
+
+// AttributeMapping maps user pool attributes to identity provider attributes
+// (SAML assertion claims or OIDC token claims).
+type AttributeMapping map[string]string
+
+// Map adds user pool attribute mapping to provider attribute and returns the
+// mapping, so the calls may be chained:
+//
+//	m := AttributeMapping{}.Map("email", "mail").Map("name", "displayName")
+func (m AttributeMapping) Map(userPoolAttr, providerAttr string) AttributeMapping {
+	if m == nil {
+		m = make(AttributeMapping)
+	}
+	m[userPoolAttr] = providerAttr
+	return m
+}
+
+// SAML claims commonly used in attribute mapping.
+const (
+	SAMLClaimEmail     = "http://schemas.xmlsoap.org/ws/2005/05/identity/claims/emailaddress"
+	SAMLClaimGivenName = "http://schemas.xmlsoap.org/ws/2005/05/identity/claims/givenname"
+	SAMLClaimSurname   = "http://schemas.xmlsoap.org/ws/2005/05/identity/claims/surname"
+	SAMLClaimName      = "http://schemas.xmlsoap.org/ws/2005/05/identity/claims/name"
+)
+
+// SAMLAttributeMapping returns attribute mapping of standard SAML claims to
+// email, given_name, family_name and name user pool attributes.
+func SAMLAttributeMapping() AttributeMapping {
+	return AttributeMapping{}.
+		Map("email", SAMLClaimEmail).
+		Map("given_name", SAMLClaimGivenName).
+		Map("family_name", SAMLClaimSurname).
+		Map("name", SAMLClaimName)
+}
+
+// OIDCAttributeMapping returns attribute mapping of standard OIDC claims to
+// the same user pool attributes and maps provider sub to username.
+func OIDCAttributeMapping() AttributeMapping {
+	return AttributeMapping{}.
+		Map("username", "sub").
+		Map("email", "email").
+		Map("email_verified", "email_verified").
+		Map("given_name", "given_name").
+		Map("family_name", "family_name").
+		Map("name", "name")
+}
+
+// SAMLProviderDetails returns SAML identity provider details with the IdP
+// metadata document URL.
+func SAMLProviderDetails(metadataURL string) map[string]string {
+	return map[string]string{"MetadataURL": metadataURL}
+}
+
+// OIDCProviderDetails returns OIDC identity provider details. The endpoints
+// are discovered from the issuer URL.
+//
+// Parameters:
+//   - clientId: The OIDC client ID.
+//   - clientSecret: The OIDC client secret.
+//   - issuer: The OIDC issuer URL.
+//   - scopes: The space separated list of authorized scopes, for example
+//     "openid email profile".
+func OIDCProviderDetails(clientId, clientSecret, issuer,
+	scopes string) map[string]string {
+
+	return map[string]string{
+		"client_id":                 clientId,
+		"client_secret":             clientSecret,
+		"oidc_issuer":               issuer,
+		"authorize_scopes":          scopes,
+		"attributes_request_method": "GET",
+	}
+}
+
+// CreateIdentityProvider creates an identity provider for a user pool.
+//
+// Parameters:
+//   - userPoolId: The ID of the user pool.
+//   - name: The identity provider name.
+//   - providerType: The identity provider type.
+//   - details: The identity provider details, see SAMLProviderDetails and
+//     OIDCProviderDetails.
+//   - mapping: The attribute mapping, see SAMLAttributeMapping and
+//     OIDCAttributeMapping.
+//   - identifiers: The identity provider identifiers (may be nil).
+//
+// Returns:
+//   - provider: The created identity provider.
+//   - err: An error if the operation fails.
+func (a awsCognito) CreateIdentityProvider(userPoolId, name string,
+	providerType IdentityProviderType, details map[string]string,
+	mapping AttributeMapping, identifiers []string) (
+	provider *IdentityProvider, err error) {
+
+	out, err := a.Client.CreateIdentityProvider(a.ctx,
+		&cognitoidentityprovider.CreateIdentityProviderInput{
+			UserPoolId:       aws.String(userPoolId),
+			ProviderName:     aws.String(name),
+			ProviderType:     providerType,
+			ProviderDetails:  details,
+			AttributeMapping: mapping,
+			IdpIdentifiers:   identifiers,
+		},
+	)
+	if err != nil {
+		return
+	}
+	provider = out.IdentityProvider
+
+	return
+}
+
+// UpdateIdentityProvider updates an identity provider of a user pool. The
+// nil details, mapping or identifiers are not changed.
+//
+// Parameters:
+//   - userPoolId: The ID of the user pool.
+//   - name: The identity provider name.
+//   - details: The identity provider details.
+//   - mapping: The attribute mapping.
+//   - identifiers: The identity provider identifiers.
+//
+// Returns:
+//   - provider: The updated identity provider.
+//   - err: An error if the operation fails.
+func (a awsCognito) UpdateIdentityProvider(userPoolId, name string,
+	details map[string]string, mapping AttributeMapping,
+	identifiers []string) (provider *IdentityProvider, err error) {
+
+	out, err := a.Client.UpdateIdentityProvider(a.ctx,
+		&cognitoidentityprovider.UpdateIdentityProviderInput{
+			UserPoolId:       aws.String(userPoolId),
+			ProviderName:     aws.String(name),
+			ProviderDetails:  details,
+			AttributeMapping: mapping,
+			IdpIdentifiers:   identifiers,
+		},
+	)
+	if err != nil {
+		return
+	}
+	provider = out.IdentityProvider
+
+	return
+}
+
+// DescribeIdentityProvider returns an identity provider of a user pool.
+//
+// Parameters:
+//   - userPoolId: The ID of the user pool.
+//   - name: The identity provider name.
+//
+// Returns:
+//   - provider: The identity provider.
+//   - err: An error if the operation fails.
+func (a awsCognito) DescribeIdentityProvider(userPoolId, name string) (
+	provider *IdentityProvider, err error) {
+
+	out, err := a.Client.DescribeIdentityProvider(a.ctx,
+		&cognitoidentityprovider.DescribeIdentityProviderInput{
+			UserPoolId:   aws.String(userPoolId),
+			ProviderName: aws.String(name),
+		},
+	)
+	if err != nil {
+		return
+	}
+	provider = out.IdentityProvider
+
+	return
+}
+
+// providerSubject is the provider attribute name of the federated user subject
+// (OIDC sub or SAML NameID).
+const providerSubject = "Cognito_Subject"
+
+// AdminLinkProviderForUser links a federated user identity (Google, Facebook,
+// SAML, OIDC) to an existing native user pool user. When the federated user
+// signs in next time, the user pool uses the linked native user.
+//
+// Parameters:
+//   - userPoolId: The ID of the user pool.
+//   - username: The existing native user name.
+//   - providerName: The identity provider name, for example "Google" or the
+//     name of SAML or OIDC provider.
+//   - providerUserId: The federated user subject (OIDC sub or SAML NameID).
+//
+// Returns:
+//   - err: An error if the operation fails.
+func (a awsCognito) AdminLinkProviderForUser(userPoolId, username, providerName,
+	providerUserId string) (err error) {
+
+	_, err = a.Client.AdminLinkProviderForUser(a.ctx,
+		&cognitoidentityprovider.AdminLinkProviderForUserInput{
+			UserPoolId: aws.String(userPoolId),
+			DestinationUser: &types.ProviderUserIdentifierType{
+				ProviderName:           aws.String("Cognito"),
+				ProviderAttributeValue: aws.String(username),
+			},
+			SourceUser: &types.ProviderUserIdentifierType{
+				ProviderName:           aws.String(providerName),
+				ProviderAttributeName:  aws.String(providerSubject),
+				ProviderAttributeValue: aws.String(providerUserId),
+			},
+		},
+	)
+	return
+}
+
+// AdminDisableProviderForUser unlinks a federated user identity from the
+// native user it was linked to and prevents the federated user from signing
+// in.
+//
+// Parameters:
+//   - userPoolId: The ID of the user pool.
+//   - providerName: The identity provider name.
+//   - providerUserId: The federated user subject (OIDC sub or SAML NameID).
+//
+// Returns:
+//   - err: An error if the operation fails.
+func (a awsCognito) AdminDisableProviderForUser(userPoolId, providerName,
+	providerUserId string) (err error) {
+
+	_, err = a.Client.AdminDisableProviderForUser(a.ctx,
+		&cognitoidentityprovider.AdminDisableProviderForUserInput{
+			UserPoolId: aws.String(userPoolId),
+			User: &types.ProviderUserIdentifierType{
+				ProviderName:           aws.String(providerName),
+				ProviderAttributeName:  aws.String(providerSubject),
+				ProviderAttributeValue: aws.String(providerUserId),
+			},
+		},
+	)
+	return
+}
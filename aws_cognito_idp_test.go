@@ -0,0 +1,63 @@
+package aws
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestCognitoAttributeMapping(t *testing.T) {
+
+	var m AttributeMapping
+	m = m.Map("email", "mail").Map("name", "displayName")
+	if len(m) != 2 || m["email"] != "mail" || m["name"] != "displayName" {
+		t.Error("wrong attribute mapping:", m)
+		return
+	}
+
+	m = SAMLAttributeMapping()
+	if m["email"] != SAMLClaimEmail {
+		t.Error("wrong SAML email mapping:", m["email"])
+	}
+}
+
+// TestCognitoProviderLinking checks the federated user is linked to the
+// native user and disabled by the provider subject
+func TestCognitoProviderLinking(t *testing.T) {
+
+	client := &pagesHTTPClient{bodies: []string{`{}`, `{}`,
+		`{"__type":"InvalidParameterException","message":"already linked"}`,
+	}, statuses: []int{http.StatusOK, http.StatusOK, http.StatusBadRequest}}
+	a := newPagesTestAws(client)
+
+	err := a.Cognito.AdminLinkProviderForUser("pool", "user", "Google",
+		"1234")
+	if err != nil {
+		t.Fatal("link provider:", err)
+	}
+	for _, want := range []string{
+		`"DestinationUser":{"ProviderAttributeValue":"user",` +
+			`"ProviderName":"Cognito"}`,
+		`"SourceUser":{"ProviderAttributeName":"Cognito_Subject",` +
+			`"ProviderAttributeValue":"1234","ProviderName":"Google"}`,
+	} {
+		if !strings.Contains(client.requests[0], want) {
+			t.Error("wrong link request:", want, client.requests[0])
+		}
+	}
+
+	err = a.Cognito.AdminDisableProviderForUser("pool", "Google", "1234")
+	if err != nil || !strings.Contains(client.requests[1],
+		`"User":{"ProviderAttributeName":"Cognito_Subject",`+
+			`"ProviderAttributeValue":"1234","ProviderName":"Google"}`) {
+		t.Error("wrong disable provider:", err, client.requests[1])
+	}
+
+	// Service error
+	var e *Error
+	err = a.Cognito.AdminLinkProviderForUser("pool", "user", "Google", "1234")
+	if !errors.As(err, &e) || e.Code() != "InvalidParameterException" {
+		t.Error("wrong link error:", err)
+	}
+}
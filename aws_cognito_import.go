@@ -0,0 +1,254 @@
+package aws
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+)
+
+// importJobWaitInterval is the default status poll interval of the user
+// import job.
+const importJobWaitInterval = 10 * time.Second
+
+// UserImportJob is a Cognito user import job description.
+type UserImportJob = types.UserImportJobType
+
+// ImportCSVHeader writes the CSV header of user import file for the user pool
+// to w. The header contains all columns of the user pool schema in the order
+// expected by the user import job.
+//
+// Parameters:
+//   - userPoolId: The ID of the user pool.
+//   - w: The writer to write CSV header to.
+//
+// Returns:
+//   - err: An error if the operation fails.
+func (a awsCognito) ImportCSVHeader(userPoolId string, w io.Writer) (err error) {
+
+	// Get CSV header from user pool
+	out, err := a.Client.GetCSVHeader(a.ctx, &cognitoidentityprovider.GetCSVHeaderInput{
+		UserPoolId: aws.String(userPoolId),
+	})
+	if err != nil {
+		return
+	}
+
+	// Write header to output
+	cw := csv.NewWriter(w)
+	cw.Write(out.CSVHeader)
+	cw.Flush()
+	err = cw.Error()
+
+	return
+}
+
+// CreateImportJob creates a new user import job.
+//
+// Parameters:
+//   - userPoolId: The ID of the user pool.
+//   - jobName: The job name for the user import job.
+//   - roleArn: The role ARN of the IAM role used to write import logs to
+//     CloudWatch Logs.
+//
+// Returns:
+//   - job: The created user import job. The job.PreSignedUrl is the URL used
+//     to upload the CSV file with UploadImportCSV.
+//   - err: An error if the operation fails.
+func (a awsCognito) CreateImportJob(userPoolId, jobName, roleArn string) (
+	job *UserImportJob, err error) {
+
+	out, err := a.Client.CreateUserImportJob(a.ctx,
+		&cognitoidentityprovider.CreateUserImportJobInput{
+			UserPoolId:            aws.String(userPoolId),
+			JobName:               aws.String(jobName),
+			CloudWatchLogsRoleArn: aws.String(roleArn),
+		},
+	)
+	if err != nil {
+		return
+	}
+	job = out.UserImportJob
+
+	return
+}
+
+// UploadImportCSV uploads CSV file with users to the pre-signed URL of the
+// user import job. The file is uploaded by the HTTP client of the AWS config.
+//
+// Parameters:
+//   - preSignedUrl: The pre-signed URL from the user import job.
+//   - data: The CSV file content. Use ImportCSVHeader to get file header.
+//
+// Returns:
+//   - err: An error if the operation fails.
+func (a awsCognito) UploadImportCSV(preSignedUrl string, data []byte) (err error) {
+
+	// Create upload request
+	req, err := http.NewRequestWithContext(a.ctx, http.MethodPut, preSignedUrl,
+		bytes.NewReader(data))
+	if err != nil {
+		return
+	}
+	req.Header.Set("x-amz-server-side-encryption", "aws:kms")
+
+	// Upload CSV file by the configured HTTP client
+	var client aws.HTTPClient = http.DefaultClient
+	if c := a.Client.Options().HTTPClient; c != nil {
+		client = c
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	// Check response status
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		err = fmt.Errorf("can't upload import csv, status %d: %s",
+			resp.StatusCode, body)
+	}
+
+	return
+}
+
+// StartImportJob starts the user import job.
+//
+// Parameters:
+//   - userPoolId: The ID of the user pool.
+//   - jobId: The job ID for the user import job.
+//
+// Returns:
+//   - job: The started user import job.
+//   - err: An error if the operation fails.
+func (a awsCognito) StartImportJob(userPoolId, jobId string) (
+	job *UserImportJob, err error) {
+
+	out, err := a.Client.StartUserImportJob(a.ctx,
+		&cognitoidentityprovider.StartUserImportJobInput{
+			UserPoolId: aws.String(userPoolId),
+			JobId:      aws.String(jobId),
+		},
+	)
+	if err != nil {
+		return
+	}
+	job = out.UserImportJob
+
+	return
+}
+
+// ImportJob returns the user import job description.
+//
+// Parameters:
+//   - userPoolId: The ID of the user pool.
+//   - jobId: The job ID for the user import job.
+//
+// Returns:
+//   - job: The user import job.
+//   - err: An error if the operation fails.
+func (a awsCognito) ImportJob(userPoolId, jobId string) (
+	job *UserImportJob, err error) {
+
+	out, err := a.Client.DescribeUserImportJob(a.ctx,
+		&cognitoidentityprovider.DescribeUserImportJobInput{
+			UserPoolId: aws.String(userPoolId),
+			JobId:      aws.String(jobId),
+		},
+	)
+	if err != nil {
+		return
+	}
+	job = out.UserImportJob
+
+	return
+}
+
+// WaitImportJob polls the user import job status every interval until the
+// job is finished.
+//
+// Parameters:
+//   - userPoolId: The ID of the user pool.
+//   - jobId: The job ID for the user import job.
+//   - interval: The status poll interval. Default is 10 seconds, it is used
+//     if the interval is not positive.
+//
+// Returns:
+//   - job: The finished user import job. Check job.Status, job.ImportedUsers,
+//     job.FailedUsers and job.CompletionMessage for the result.
+//   - err: An error if the operation fails.
+func (a awsCognito) WaitImportJob(userPoolId, jobId string,
+	interval time.Duration) (job *UserImportJob, err error) {
+
+	if interval <= 0 {
+		interval = importJobWaitInterval
+	}
+
+	for {
+		job, err = a.ImportJob(userPoolId, jobId)
+		if err != nil {
+			return
+		}
+
+		switch job.Status {
+		case types.UserImportJobStatusTypeSucceeded,
+			types.UserImportJobStatusTypeFailed,
+			types.UserImportJobStatusTypeStopped,
+			types.UserImportJobStatusTypeExpired:
+			return
+		}
+
+		select {
+		case <-a.ctx.Done():
+			err = a.ctx.Err()
+			return
+		case <-clock(a.ctx).After(interval):
+		}
+	}
+}
+
+// Import imports users from CSV file to the user pool. It creates user import
+// job, uploads CSV file, starts the job and waits until it finished.
+//
+// Parameters:
+//   - userPoolId: The ID of the user pool.
+//   - jobName: The job name for the user import job.
+//   - roleArn: The role ARN of the IAM role used to write import logs to
+//     CloudWatch Logs.
+//   - data: The CSV file content. Use ImportCSVHeader to get file header.
+//
+// Returns:
+//   - job: The finished user import job.
+//   - err: An error if the operation fails.
+func (a awsCognito) Import(userPoolId, jobName, roleArn string, data []byte) (
+	job *UserImportJob, err error) {
+
+	// Create import job
+	job, err = a.CreateImportJob(userPoolId, jobName, roleArn)
+	if err != nil {
+		return
+	}
+
+	// Upload CSV file
+	err = a.UploadImportCSV(aws.ToString(job.PreSignedUrl), data)
+	if err != nil {
+		return
+	}
+
+	// Start import job
+	job, err = a.StartImportJob(userPoolId, aws.ToString(job.JobId))
+	if err != nil {
+		return
+	}
+
+	// Wait import job finished
+	return a.WaitImportJob(userPoolId, aws.ToString(job.JobId),
+		importJobWaitInterval)
+}
@@ -0,0 +1,129 @@
+package aws
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// TestCognitoImportCSVHeaderColumns checks the user pool CSV header columns
+// are written in the user pool order
+func TestCognitoImportCSVHeaderColumns(t *testing.T) {
+
+	client := &pagesHTTPClient{bodies: []string{`{"UserPoolId":"pool",` +
+		`"CSVHeader":["name","given_name","email","email_verified",` +
+		`"cognito:mfa_enabled","cognito:username"]}`}}
+	a := newPagesTestAws(client)
+
+	var buf strings.Builder
+	if err := a.Cognito.ImportCSVHeader("pool", &buf); err != nil {
+		t.Fatal("import CSV header:", err)
+	}
+	want := "name,given_name,email,email_verified,cognito:mfa_enabled," +
+		"cognito:username\n"
+	if buf.String() != want ||
+		!strings.Contains(client.requests[0], `"UserPoolId":"pool"`) {
+		t.Error("wrong CSV header:", buf.String(), client.requests[0])
+	}
+}
+
+// TestCognitoImport checks the CSV file is uploaded by the configured HTTP
+// client and the job is polled until it is finished
+func TestCognitoImport(t *testing.T) {
+
+	client := &pagesHTTPClient{bodies: []string{
+		`{"UserImportJob":{"JobId":"j1","Status":"Created",` +
+			`"PreSignedUrl":"https://upload.example.com/import?sig=1"}}`,
+		``,
+		`{"UserImportJob":{"JobId":"j1","Status":"Pending"}}`,
+		`{"UserImportJob":{"JobId":"j1","Status":"InProgress"}}`,
+		`{"UserImportJob":{"JobId":"j1","Status":"Succeeded",` +
+			`"ImportedUsers":2}}`,
+	}}
+	var upload *http.Request
+	cfg := newPagesTestAws(client).Config()
+	cfg.HTTPClient = smithyhttp.ClientDoFunc(
+		func(r *http.Request) (*http.Response, error) {
+			if r.URL.Host == "upload.example.com" {
+				upload = r
+			}
+			return client.Do(r)
+		})
+	clk := NewFakeClock(time.Now())
+	a := NewFromConfig(cfg, WithClock(clk))
+
+	type result struct {
+		job *UserImportJob
+		err error
+	}
+	done := make(chan result)
+	go func() {
+		job, err := a.Cognito.Import("pool", "import", "role", []byte("csv"))
+		done <- result{job, err}
+	}()
+
+	// The job status is polled every 10 seconds
+	clk.BlockUntil(1)
+	clk.Advance(importJobWaitInterval)
+	r := <-done
+	if r.err != nil || r.job.Status != types.UserImportJobStatusTypeSucceeded ||
+		r.job.ImportedUsers != 2 {
+		t.Fatal("wrong import job:", r.job, r.err)
+	}
+	if upload == nil || upload.Method != http.MethodPut ||
+		upload.Header.Get("X-Amz-Server-Side-Encryption") != "aws:kms" ||
+		client.requests[1] != "csv" {
+		t.Error("wrong CSV upload:", upload, client.requests[1])
+	}
+	if !strings.Contains(client.requests[0], `"CloudWatchLogsRoleArn":"role"`) ||
+		!strings.Contains(client.requests[2], `"JobId":"j1"`) {
+		t.Error("wrong import job requests:", client.requests)
+	}
+}
+
+// TestCognitoWaitImportJobInterval checks the not positive poll interval is
+// replaced by the default one instead of polling in a loop
+func TestCognitoWaitImportJobInterval(t *testing.T) {
+
+	client := &pagesHTTPClient{bodies: []string{
+		`{"UserImportJob":{"JobId":"j1","Status":"InProgress"}}`,
+		`{"UserImportJob":{"JobId":"j1","Status":"Failed"}}`,
+	}}
+	clk := NewFakeClock(time.Now())
+	a := NewFromConfig(newPagesTestAws(client).Config(), WithClock(clk))
+
+	done := make(chan error)
+	go func() {
+		_, err := a.Cognito.WaitImportJob("pool", "j1", 0)
+		done <- err
+	}()
+	clk.BlockUntil(1)
+	clk.Advance(importJobWaitInterval - time.Second)
+	select {
+	case err := <-done:
+		t.Fatal("job polled before interval:", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+	clk.Advance(time.Second)
+	if err := <-done; err != nil || len(client.requests) != 2 {
+		t.Error("wrong wait:", err, len(client.requests))
+	}
+}
+
+// TestCognitoUploadImportCSVError checks the upload error status is returned
+func TestCognitoUploadImportCSVError(t *testing.T) {
+
+	client := &pagesHTTPClient{bodies: []string{"denied"},
+		statuses: []int{http.StatusForbidden}}
+	a := newPagesTestAws(client)
+
+	err := a.Cognito.UploadImportCSV("https://upload.example.com/x", nil)
+	if err == nil || !strings.Contains(err.Error(), "403") ||
+		!strings.Contains(err.Error(), "denied") {
+		t.Error("wrong upload error:", err)
+	}
+}
@@ -0,0 +1,162 @@
+package aws
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+)
+
+// pendingStatuses are the user statuses in which the user can't sign in
+// until an action of the user or admin.
+var pendingStatuses = []types.UserStatusType{
+	types.UserStatusTypeForceChangePassword,
+	types.UserStatusTypeUnconfirmed,
+}
+
+// IsPending returns true if the user is stuck in FORCE_CHANGE_PASSWORD or
+// UNCONFIRMED status.
+func (awsCognito) IsPending(user *UserType) bool {
+	for _, status := range pendingStatuses {
+		if user.UserStatus == status {
+			return true
+		}
+	}
+	return false
+}
+
+// PendingUsers scans the whole user pool and returns users stuck in the
+// statuses. The FORCE_CHANGE_PASSWORD and UNCONFIRMED users are returned if
+// statuses is empty.
+//
+// Parameters:
+//   - userPoolId: The ID of the user pool.
+//   - statuses: The user statuses to look for.
+//
+// Returns:
+//   - users: The users in the statuses.
+//   - err: An error if the operation fails.
+func (a awsCognito) PendingUsers(userPoolId string,
+	statuses ...types.UserStatusType) (users []UserType, err error) {
+
+	return a.StalePendingUsers(userPoolId, 0, statuses...)
+}
+
+// StalePendingUsers scans the whole user pool and returns users stuck in the
+// statuses for at least the age, for example the users which have not
+// confirmed the sign up for a week:
+//
+//	users, err := a.Cognito.StalePendingUsers(userPoolId, 7*24*time.Hour,
+//		types.UserStatusTypeUnconfirmed)
+//
+// The user age is counted from the user last modification date. The
+// FORCE_CHANGE_PASSWORD and UNCONFIRMED users are returned if statuses is
+// empty.
+//
+// Parameters:
+//   - userPoolId: The ID of the user pool.
+//   - age: The minimum time the user is in the status, zero returns all
+//     users in the statuses.
+//   - statuses: The user statuses to look for.
+//
+// Returns:
+//   - users: The users in the statuses.
+//   - err: An error if the operation fails.
+func (a awsCognito) StalePendingUsers(userPoolId string, age time.Duration,
+	statuses ...types.UserStatusType) (users []UserType, err error) {
+
+	if len(statuses) == 0 {
+		statuses = pendingStatuses
+	}
+	before := clock(a.ctx).Now().Add(-age)
+
+	// The ListUsers filter accepts one value, so scan the pool for each status
+	for _, status := range statuses {
+		filter := "cognito:user_status = \"" + string(status) + "\""
+		for user, e := range a.Users(userPoolId, filter) {
+			if e != nil {
+				err = e
+				return
+			}
+			if age > 0 && aws.ToTime(user.UserLastModifiedDate).After(before) {
+				continue
+			}
+			users = append(users, user)
+		}
+	}
+
+	return
+}
+
+// ResendInvite resends the invitation message to the user created by admin
+// which has not changed the temporary password yet (FORCE_CHANGE_PASSWORD
+// status). A new temporary password is generated.
+//
+// Parameters:
+//   - userPoolId: The ID of the user pool.
+//   - username: The user name.
+//
+// Returns:
+//   - user: The user.
+//   - err: An error if the operation fails.
+func (a awsCognito) ResendInvite(userPoolId, username string) (
+	user *UserType, err error) {
+
+	out, err := a.Client.AdminCreateUser(a.ctx,
+		&cognitoidentityprovider.AdminCreateUserInput{
+			UserPoolId:    aws.String(userPoolId),
+			Username:      aws.String(username),
+			MessageAction: types.MessageActionTypeResend,
+		},
+	)
+	if err != nil {
+		return
+	}
+	user = out.User
+
+	return
+}
+
+// SetPermanentPassword sets the permanent user password. The user in the
+// FORCE_CHANGE_PASSWORD status moves to the CONFIRMED status.
+//
+// Parameters:
+//   - userPoolId: The ID of the user pool.
+//   - username: The user name.
+//   - password: The new user password.
+//
+// Returns:
+//   - err: An error if the operation fails.
+func (a awsCognito) SetPermanentPassword(userPoolId, username,
+	password string) (err error) {
+
+	_, err = a.Client.AdminSetUserPassword(a.ctx,
+		&cognitoidentityprovider.AdminSetUserPasswordInput{
+			UserPoolId: aws.String(userPoolId),
+			Username:   aws.String(username),
+			Password:   aws.String(password),
+			Permanent:  true,
+		},
+	)
+	return
+}
+
+// AdminConfirmSignUp confirms the UNCONFIRMED user sign up without the
+// confirmation code.
+//
+// Parameters:
+//   - userPoolId: The ID of the user pool.
+//   - username: The user name.
+//
+// Returns:
+//   - err: An error if the operation fails.
+func (a awsCognito) AdminConfirmSignUp(userPoolId, username string) (err error) {
+	_, err = a.Client.AdminConfirmSignUp(a.ctx,
+		&cognitoidentityprovider.AdminConfirmSignUpInput{
+			UserPoolId: aws.String(userPoolId),
+			Username:   aws.String(username),
+		},
+	)
+	return
+}
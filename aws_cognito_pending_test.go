@@ -0,0 +1,76 @@
+package aws
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+)
+
+// TestCognitoPendingUsersStatuses checks the pending users are listed by the
+// status filter for each status
+func TestCognitoPendingUsersStatuses(t *testing.T) {
+
+	client := &pagesHTTPClient{bodies: []string{
+		`{"Users":[{"Username":"u1","UserStatus":"FORCE_CHANGE_PASSWORD"}],` +
+			`"PaginationToken":"p1"}`,
+		`{"Users":[{"Username":"u2","UserStatus":"FORCE_CHANGE_PASSWORD"}]}`,
+		`{"Users":[{"Username":"u3","UserStatus":"UNCONFIRMED"}]}`,
+	}}
+	a := newPagesTestAws(client)
+
+	users, err := a.Cognito.PendingUsers("pool")
+	if err != nil || len(users) != 3 {
+		t.Fatal("wrong pending users:", users, err)
+	}
+	for _, user := range users {
+		if !a.Cognito.IsPending(&user) {
+			t.Error("user is not pending:", *user.Username, user.UserStatus)
+		}
+	}
+	for i, status := range []string{"FORCE_CHANGE_PASSWORD",
+		"FORCE_CHANGE_PASSWORD", "UNCONFIRMED"} {
+		if !strings.Contains(client.requests[i],
+			`"Filter":"cognito:user_status = \"`+status+`\""`) {
+			t.Error("wrong pending users request:", client.requests[i])
+		}
+	}
+	if !strings.Contains(client.requests[1], `"PaginationToken":"p1"`) {
+		t.Error("wrong next page request:", client.requests[1])
+	}
+	if a.Cognito.IsPending(&UserType{UserStatus: types.UserStatusTypeConfirmed}) {
+		t.Error("confirmed user is pending")
+	}
+}
+
+// TestCognitoStalePendingUsers checks the UNCONFIRMED users are filtered by
+// the last modification age
+func TestCognitoStalePendingUsers(t *testing.T) {
+
+	client := &pagesHTTPClient{bodies: []string{
+		`{"Users":[` +
+			`{"Username":"old","UserStatus":"UNCONFIRMED",` +
+			`"UserLastModifiedDate":1577836800},` +
+			`{"Username":"week","UserStatus":"UNCONFIRMED",` +
+			`"UserLastModifiedDate":1578268800},` +
+			`{"Username":"new","UserStatus":"UNCONFIRMED",` +
+			`"UserLastModifiedDate":1578441600}]}`,
+	}}
+	clk := NewFakeClock(time.Date(2020, 1, 13, 0, 0, 0, 0, time.UTC))
+	a := NewFromConfig(newPagesTestAws(client).Config(), WithClock(clk))
+
+	users, err := a.Cognito.StalePendingUsers("pool", 7*24*time.Hour,
+		types.UserStatusTypeUnconfirmed)
+	var names []string
+	for _, user := range users {
+		names = append(names, *user.Username)
+	}
+	if err != nil || strings.Join(names, ",") != "old,week" {
+		t.Error("wrong stale users:", names, err)
+	}
+	if len(client.requests) != 1 || !strings.Contains(client.requests[0],
+		`"Filter":"cognito:user_status = \"UNCONFIRMED\""`) {
+		t.Error("wrong stale users requests:", client.requests)
+	}
+}
@@ -2,11 +2,18 @@
 // user pool id:
 //
 //   POOL=XXX go test -v -count=1 .
+//
+// Set GROUP environment variable with name of a group in this pool to execute
+// the group tests.
 
 package aws
 
 import (
+	"bytes"
+	"encoding/json"
+	"net/http"
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -96,3 +103,225 @@ func TestCognitoListFilter(t *testing.T) {
 	t.Log("pagination =", p)
 	t.Log()
 }
+
+func TestCognitoExport(t *testing.T) {
+
+	if cognitoUserPool == "" {
+		t.Skip()
+		return
+	}
+
+	a, err := New()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	// Export users to CSV
+	var buf bytes.Buffer
+	err = a.Cognito.Export(cognitoUserPool, &buf, ExportCSV, []string{"email"})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	t.Log("CSV export length:", buf.Len())
+
+	// Export users to JSON
+	buf.Reset()
+	err = a.Cognito.Export(cognitoUserPool, &buf, ExportJSON, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	var users []ExportUser
+	if err = json.Unmarshal(buf.Bytes(), &users); err != nil {
+		t.Error(err)
+		return
+	}
+	t.Log("JSON export users:", len(users))
+}
+
+func TestCognitoImportCSVHeader(t *testing.T) {
+
+	if cognitoUserPool == "" {
+		t.Skip()
+		return
+	}
+
+	a, err := New()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err = a.Cognito.ImportCSVHeader(cognitoUserPool, &buf); err != nil {
+		t.Error(err)
+		return
+	}
+	t.Log("CSV header:", buf.String())
+}
+
+func TestCognitoListUsersInGroup(t *testing.T) {
+
+	group := os.Getenv("GROUP")
+	if cognitoUserPool == "" || group == "" {
+		t.Skip()
+		return
+	}
+
+	a, err := New()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	var next *string
+	for {
+		users, n, err := a.Cognito.ListUsersInGroup(cognitoUserPool, group, 10, next)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		for _, user := range users {
+			t.Log(*user.Username)
+		}
+		if n == nil {
+			break
+		}
+		next = n
+	}
+}
+
+func TestCognitoUsers(t *testing.T) {
+
+	if cognitoUserPool == "" {
+		t.Skip()
+		return
+	}
+
+	a, err := New()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	var n int
+	for user, err := range a.Cognito.Users(cognitoUserPool, "") {
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		t.Log(*user.Username)
+		n++
+	}
+	t.Log("Users:", n)
+}
+
+func TestCognitoPendingUsers(t *testing.T) {
+
+	if cognitoUserPool == "" {
+		t.Skip()
+		return
+	}
+
+	a, err := New()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	users, err := a.Cognito.PendingUsers(cognitoUserPool)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	for _, user := range users {
+		if !a.Cognito.IsPending(&user) {
+			t.Error("user is not pending:", *user.Username, user.UserStatus)
+		}
+		t.Log(*user.Username, user.UserStatus)
+	}
+}
+
+// TestCognitoListUsersInGroupPages checks the group users are listed page by
+// page with the next token
+func TestCognitoListUsersInGroupPages(t *testing.T) {
+
+	client := &pagesHTTPClient{bodies: []string{
+		`{"Users":[{"Username":"u1"},{"Username":"u2"}],"NextToken":"n1"}`,
+		`{"Users":[{"Username":"u3"}]}`,
+	}}
+	a := newPagesTestAws(client)
+
+	var names []string
+	var next *string
+	for {
+		users, n, err := a.Cognito.ListUsersInGroup("pool", "admins", 2, next)
+		if err != nil {
+			t.Fatal("list users in group:", err)
+		}
+		for _, user := range users {
+			names = append(names, *user.Username)
+		}
+		if n == nil {
+			break
+		}
+		next = n
+	}
+	if strings.Join(names, ",") != "u1,u2,u3" || len(client.requests) != 2 {
+		t.Error("wrong group users:", names)
+	}
+	if !strings.Contains(client.requests[0], `"GroupName":"admins"`) ||
+		!strings.Contains(client.requests[0], `"Limit":2`) ||
+		strings.Contains(client.requests[0], "NextToken") ||
+		!strings.Contains(client.requests[1], `"NextToken":"n1"`) {
+		t.Error("wrong list users in group requests:", client.requests)
+	}
+}
+
+// TestCognitoUsersPages checks the users iterator reads the pages of 60
+// users with the pagination token and stops on the error
+func TestCognitoUsersPages(t *testing.T) {
+
+	client := &pagesHTTPClient{bodies: []string{
+		`{"Users":[{"Username":"u1"},{"Username":"u2"}],` +
+			`"PaginationToken":"p1"}`,
+		`{"Users":[{"Username":"u3"}],"PaginationToken":"p2"}`,
+		`{"Users":[]}`,
+	}}
+	a := newPagesTestAws(client)
+
+	var names []string
+	for user, err := range a.Cognito.Users("pool", `status="Enabled"`) {
+		if err != nil {
+			t.Fatal("users:", err)
+		}
+		names = append(names, *user.Username)
+	}
+	if strings.Join(names, ",") != "u1,u2,u3" || len(client.requests) != 3 {
+		t.Error("wrong users:", names, len(client.requests))
+	}
+	for i, token := range []string{"", "p1", "p2"} {
+		req := client.requests[i]
+		if !strings.Contains(req, `"Limit":60`) ||
+			!strings.Contains(req, `"Filter":"status=\"Enabled\""`) ||
+			(token == "") == strings.Contains(req, `"PaginationToken":"`+
+				token+`"`) {
+			t.Error("wrong list users request:", i, req)
+		}
+	}
+
+	// The error stops the iteration
+	a = newErrorTestAws(http.StatusBadRequest,
+		`{"__type":"NotAuthorizedException","message":"denied"}`)
+	n := 0
+	for _, err := range a.Cognito.Users("pool", "") {
+		if n++; err == nil {
+			t.Error("users error is not returned")
+		}
+	}
+	if n != 1 {
+		t.Error("wrong number of iterations after error:", n)
+	}
+}
@@ -0,0 +1,281 @@
+package aws
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Cognito Lambda trigger sources.
+const (
+	TriggerPreSignUp                   = "PreSignUp_SignUp"
+	TriggerPreSignUpAdminCreateUser    = "PreSignUp_AdminCreateUser"
+	TriggerPreSignUpExternalProvider   = "PreSignUp_ExternalProvider"
+	TriggerPostConfirmation            = "PostConfirmation_ConfirmSignUp"
+	TriggerPostConfirmationForgotPass  = "PostConfirmation_ConfirmForgotPassword"
+	TriggerTokenGenerationHostedAuth   = "TokenGeneration_HostedAuth"
+	TriggerTokenGenerationAuth         = "TokenGeneration_Authentication"
+	TriggerTokenGenerationNewPassword  = "TokenGeneration_NewPasswordChallenge"
+	TriggerTokenGenerationRefresh      = "TokenGeneration_RefreshTokens"
+	TriggerCustomMessageSignUp         = "CustomMessage_SignUp"
+	TriggerCustomMessageAdminCreate    = "CustomMessage_AdminCreateUser"
+	TriggerCustomMessageResendCode     = "CustomMessage_ResendCode"
+	TriggerCustomMessageForgotPassword = "CustomMessage_ForgotPassword"
+	TriggerCustomMessageUpdateAttr     = "CustomMessage_UpdateUserAttribute"
+	TriggerCustomMessageVerifyAttr     = "CustomMessage_VerifyUserAttribute"
+	TriggerCustomMessageAuthentication = "CustomMessage_Authentication"
+)
+
+// TriggerHeader contains the fields common to all Cognito Lambda trigger
+// events.
+type TriggerHeader struct {
+	Version       string               `json:"version"`
+	TriggerSource string               `json:"triggerSource"`
+	Region        string               `json:"region"`
+	UserPoolId    string               `json:"userPoolId"`
+	UserName      string               `json:"userName"`
+	CallerContext TriggerCallerContext `json:"callerContext"`
+}
+
+// TriggerCallerContext contains the caller of the Cognito Lambda trigger.
+type TriggerCallerContext struct {
+	AwsSdkVersion string `json:"awsSdkVersion"`
+	ClientId      string `json:"clientId"`
+}
+
+// TriggerSource returns the trigger source of Cognito Lambda trigger event
+// payload. Use it to select the event type when one Lambda function serves
+// several triggers.
+func TriggerSource(payload []byte) (source string, err error) {
+	var header TriggerHeader
+	if err = json.Unmarshal(payload, &header); err != nil {
+		return
+	}
+	source = header.TriggerSource
+	return
+}
+
+// PreSignUpEvent is the Cognito pre sign-up Lambda trigger event.
+type PreSignUpEvent struct {
+	TriggerHeader
+	Request  PreSignUpRequest  `json:"request"`
+	Response PreSignUpResponse `json:"response"`
+}
+
+// PreSignUpRequest is the request of the pre sign-up trigger.
+type PreSignUpRequest struct {
+	UserAttributes map[string]string `json:"userAttributes"`
+	ValidationData map[string]string `json:"validationData"`
+	ClientMetadata map[string]string `json:"clientMetadata"`
+}
+
+// PreSignUpResponse is the response of the pre sign-up trigger.
+type PreSignUpResponse struct {
+	AutoConfirmUser bool `json:"autoConfirmUser"`
+	AutoVerifyEmail bool `json:"autoVerifyEmail"`
+	AutoVerifyPhone bool `json:"autoVerifyPhone"`
+}
+
+// AutoConfirm confirms the user and verifies the user email and phone number
+// if they are present in the user attributes.
+func (e *PreSignUpEvent) AutoConfirm() {
+	e.Response.AutoConfirmUser = true
+	if _, ok := e.Request.UserAttributes["email"]; ok {
+		e.Response.AutoVerifyEmail = true
+	}
+	if _, ok := e.Request.UserAttributes["phone_number"]; ok {
+		e.Response.AutoVerifyPhone = true
+	}
+}
+
+// PostConfirmationEvent is the Cognito post confirmation Lambda trigger
+// event.
+type PostConfirmationEvent struct {
+	TriggerHeader
+	Request  PostConfirmationRequest `json:"request"`
+	Response struct{}                `json:"response"`
+}
+
+// PostConfirmationRequest is the request of the post confirmation trigger.
+type PostConfirmationRequest struct {
+	UserAttributes map[string]string `json:"userAttributes"`
+	ClientMetadata map[string]string `json:"clientMetadata"`
+}
+
+// PreTokenGenerationEvent is the Cognito pre token generation Lambda trigger
+// event, version 2 (V2_0) which allows to customize access tokens too.
+type PreTokenGenerationEvent struct {
+	TriggerHeader
+	Request  PreTokenGenerationRequest  `json:"request"`
+	Response PreTokenGenerationResponse `json:"response"`
+}
+
+// PreTokenGenerationRequest is the request of the pre token generation
+// trigger.
+type PreTokenGenerationRequest struct {
+	UserAttributes     map[string]string  `json:"userAttributes"`
+	Scopes             []string           `json:"scopes"`
+	GroupConfiguration GroupConfiguration `json:"groupConfiguration"`
+	ClientMetadata     map[string]string  `json:"clientMetadata"`
+}
+
+// GroupConfiguration contains the user groups and IAM roles in the token.
+type GroupConfiguration struct {
+	GroupsToOverride   []string `json:"groupsToOverride,omitempty"`
+	IamRolesToOverride []string `json:"iamRolesToOverride,omitempty"`
+	PreferredRole      *string  `json:"preferredRole,omitempty"`
+}
+
+// PreTokenGenerationResponse is the response of the pre token generation
+// trigger.
+type PreTokenGenerationResponse struct {
+	ClaimsAndScopeOverrideDetails *ClaimsAndScopeOverrideDetails `json:"claimsAndScopeOverrideDetails"`
+}
+
+// ClaimsAndScopeOverrideDetails contains the token customizations.
+type ClaimsAndScopeOverrideDetails struct {
+	IdTokenGeneration     *TokenGeneration    `json:"idTokenGeneration,omitempty"`
+	AccessTokenGeneration *TokenGeneration    `json:"accessTokenGeneration,omitempty"`
+	GroupOverrideDetails  *GroupConfiguration `json:"groupOverrideDetails,omitempty"`
+}
+
+// TokenGeneration contains the customizations of one token. The scopes are
+// used for the access token only.
+type TokenGeneration struct {
+	ClaimsToAddOrOverride map[string]any `json:"claimsToAddOrOverride,omitempty"`
+	ClaimsToSuppress      []string       `json:"claimsToSuppress,omitempty"`
+	ScopesToAdd           []string       `json:"scopesToAdd,omitempty"`
+	ScopesToSuppress      []string       `json:"scopesToSuppress,omitempty"`
+}
+
+// details returns the response override details and creates it if needed.
+func (e *PreTokenGenerationEvent) details() *ClaimsAndScopeOverrideDetails {
+	if e.Response.ClaimsAndScopeOverrideDetails == nil {
+		e.Response.ClaimsAndScopeOverrideDetails = new(ClaimsAndScopeOverrideDetails)
+	}
+	return e.Response.ClaimsAndScopeOverrideDetails
+}
+
+// idToken returns the ID token generation and creates it if needed.
+func (e *PreTokenGenerationEvent) idToken() *TokenGeneration {
+	d := e.details()
+	if d.IdTokenGeneration == nil {
+		d.IdTokenGeneration = new(TokenGeneration)
+	}
+	return d.IdTokenGeneration
+}
+
+// accessToken returns the access token generation and creates it if needed.
+func (e *PreTokenGenerationEvent) accessToken() *TokenGeneration {
+	d := e.details()
+	if d.AccessTokenGeneration == nil {
+		d.AccessTokenGeneration = new(TokenGeneration)
+	}
+	return d.AccessTokenGeneration
+}
+
+// AddIdClaim adds or overrides the ID token claim.
+func (e *PreTokenGenerationEvent) AddIdClaim(name string, value any) {
+	t := e.idToken()
+	if t.ClaimsToAddOrOverride == nil {
+		t.ClaimsToAddOrOverride = make(map[string]any)
+	}
+	t.ClaimsToAddOrOverride[name] = value
+}
+
+// SuppressIdClaim removes the claim from the ID token.
+func (e *PreTokenGenerationEvent) SuppressIdClaim(name string) {
+	t := e.idToken()
+	t.ClaimsToSuppress = append(t.ClaimsToSuppress, name)
+}
+
+// AddAccessClaim adds or overrides the access token claim.
+func (e *PreTokenGenerationEvent) AddAccessClaim(name string, value any) {
+	t := e.accessToken()
+	if t.ClaimsToAddOrOverride == nil {
+		t.ClaimsToAddOrOverride = make(map[string]any)
+	}
+	t.ClaimsToAddOrOverride[name] = value
+}
+
+// SuppressAccessClaim removes the claim from the access token.
+func (e *PreTokenGenerationEvent) SuppressAccessClaim(name string) {
+	t := e.accessToken()
+	t.ClaimsToSuppress = append(t.ClaimsToSuppress, name)
+}
+
+// AddScope adds the scope to the access token.
+func (e *PreTokenGenerationEvent) AddScope(scope string) {
+	t := e.accessToken()
+	t.ScopesToAdd = append(t.ScopesToAdd, scope)
+}
+
+// SuppressScope removes the scope from the access token.
+func (e *PreTokenGenerationEvent) SuppressScope(scope string) {
+	t := e.accessToken()
+	t.ScopesToSuppress = append(t.ScopesToSuppress, scope)
+}
+
+// OverrideGroups overrides the user groups in the tokens.
+func (e *PreTokenGenerationEvent) OverrideGroups(groups ...string) {
+	d := e.details()
+	if d.GroupOverrideDetails == nil {
+		d.GroupOverrideDetails = new(GroupConfiguration)
+	}
+	d.GroupOverrideDetails.GroupsToOverride = groups
+}
+
+// CustomMessageEvent is the Cognito custom message Lambda trigger event.
+type CustomMessageEvent struct {
+	TriggerHeader
+	Request  CustomMessageRequest  `json:"request"`
+	Response CustomMessageResponse `json:"response"`
+}
+
+// CustomMessageRequest is the request of the custom message trigger.
+type CustomMessageRequest struct {
+	UserAttributes    map[string]string `json:"userAttributes"`
+	CodeParameter     string            `json:"codeParameter"`
+	LinkParameter     string            `json:"linkParameter"`
+	UsernameParameter string            `json:"usernameParameter"`
+	ClientMetadata    map[string]string `json:"clientMetadata"`
+}
+
+// CustomMessageResponse is the response of the custom message trigger.
+type CustomMessageResponse struct {
+	SMSMessage   string `json:"smsMessage,omitempty"`
+	EmailMessage string `json:"emailMessage,omitempty"`
+	EmailSubject string `json:"emailSubject,omitempty"`
+}
+
+// SetEmail sets the custom email subject and message. The message must
+// contain the code parameter placeholder (request CodeParameter), otherwise
+// Cognito rejects the message and the user does not receive the code.
+func (e *CustomMessageEvent) SetEmail(subject, message string) (err error) {
+	if err = e.checkCode(message); err != nil {
+		return
+	}
+	e.Response.EmailSubject = subject
+	e.Response.EmailMessage = message
+	return
+}
+
+// SetSMS sets the custom SMS message. The message must contain the code
+// parameter placeholder (request CodeParameter).
+func (e *CustomMessageEvent) SetSMS(message string) (err error) {
+	if err = e.checkCode(message); err != nil {
+		return
+	}
+	e.Response.SMSMessage = message
+	return
+}
+
+// checkCode checks that message contains the code parameter placeholder.
+func (e *CustomMessageEvent) checkCode(message string) error {
+	code := e.Request.CodeParameter
+	if code != "" && !strings.Contains(message, code) {
+		return fmt.Errorf("custom message does not contain code parameter %s",
+			code)
+	}
+	return nil
+}
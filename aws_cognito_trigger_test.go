@@ -0,0 +1,65 @@
+package aws
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestCognitoTriggerPreTokenGeneration(t *testing.T) {
+
+	payload := []byte(`{
+		"version": "2",
+		"triggerSource": "TokenGeneration_Authentication",
+		"userPoolId": "pool",
+		"userName": "user",
+		"request": {
+			"userAttributes": {"email": "user@example.com"},
+			"scopes": ["openid"]
+		},
+		"response": {"claimsAndScopeOverrideDetails": null}
+	}`)
+
+	source, err := TriggerSource(payload)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if source != TriggerTokenGenerationAuth {
+		t.Error("wrong trigger source:", source)
+		return
+	}
+
+	var event PreTokenGenerationEvent
+	if err = json.Unmarshal(payload, &event); err != nil {
+		t.Error(err)
+		return
+	}
+	event.AddAccessClaim("tenant", "t1")
+	event.AddScope("api/read")
+	event.SuppressIdClaim("email")
+
+	data, err := json.Marshal(event.Response)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	const expected = `{"claimsAndScopeOverrideDetails":{"idTokenGeneration":` +
+		`{"claimsToSuppress":["email"]},"accessTokenGeneration":` +
+		`{"claimsToAddOrOverride":{"tenant":"t1"},"scopesToAdd":["api/read"]}}}`
+	if string(data) != expected {
+		t.Error("wrong response:", string(data))
+	}
+}
+
+func TestCognitoTriggerCustomMessage(t *testing.T) {
+
+	var event CustomMessageEvent
+	event.Request.CodeParameter = "{####}"
+
+	if err := event.SetEmail("Code", "Your code"); err == nil {
+		t.Error("message without code parameter is accepted")
+	}
+	if err := event.SetEmail("Code", "Your code {####}"); err != nil {
+		t.Error(err)
+	}
+}
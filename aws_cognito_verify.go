@@ -0,0 +1,159 @@
+package aws
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+)
+
+// CodeDelivery describes where and how the verification code was sent.
+type CodeDelivery = types.CodeDeliveryDetailsType
+
+// CognitoThrottleError is returned by the confirmation and verification code
+// functions when Cognito rejects the request because too many codes were
+// requested or too many wrong codes were entered.
+type CognitoThrottleError struct {
+	// Code is the Cognito exception name: LimitExceededException,
+	// TooManyRequestsException or TooManyFailedAttemptsException.
+	Code string
+
+	// Err is the original Cognito error.
+	Err error
+}
+
+// Error returns the error message.
+func (e *CognitoThrottleError) Error() string {
+	return fmt.Sprintf("cognito throttled, %s: %s", e.Code, e.Err)
+}
+
+// Unwrap returns the original Cognito error.
+func (e *CognitoThrottleError) Unwrap() error { return e.Err }
+
+// cognitoThrottle wraps Cognito throttling errors into CognitoThrottleError
+// and returns other errors unchanged.
+func cognitoThrottle(err error) error {
+	var limitExceeded *types.LimitExceededException
+	var tooManyRequests *types.TooManyRequestsException
+	var tooManyFailed *types.TooManyFailedAttemptsException
+	var throttleErr *CognitoThrottleError
+	switch {
+	case errors.As(err, &throttleErr):
+		return err
+	case errors.As(err, &limitExceeded):
+		return &CognitoThrottleError{limitExceeded.ErrorCode(), err}
+	case errors.As(err, &tooManyRequests):
+		return &CognitoThrottleError{tooManyRequests.ErrorCode(), err}
+	case errors.As(err, &tooManyFailed):
+		return &CognitoThrottleError{tooManyFailed.ErrorCode(), err}
+	}
+	return err
+}
+
+// SecretHash returns the secret hash of the username for app clients with a
+// client secret.
+func SecretHash(username, clientId, clientSecret string) string {
+	mac := hmac.New(sha256.New, []byte(clientSecret))
+	mac.Write([]byte(username + clientId))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// secretHash returns pointer to secret hash if client secret is set or nil.
+func secretHash(username, clientId string, clientSecret []string) *string {
+	if len(clientSecret) == 0 || clientSecret[0] == "" {
+		return nil
+	}
+	return aws.String(SecretHash(username, clientId, clientSecret[0]))
+}
+
+// ResendConfirmationCode resends the sign up confirmation code to the user.
+//
+// Parameters:
+//   - clientId: The ID of the user pool app client.
+//   - username: The user name.
+//   - clientSecret: The app client secret, if the app client has one.
+//
+// Returns:
+//   - delivery: The code delivery details.
+//   - err: An error if the operation fails. The *CognitoThrottleError is
+//     returned if too many codes were requested.
+func (a awsCognito) ResendConfirmationCode(clientId, username string,
+	clientSecret ...string) (delivery *CodeDelivery, err error) {
+
+	out, err := a.Client.ResendConfirmationCode(a.ctx,
+		&cognitoidentityprovider.ResendConfirmationCodeInput{
+			ClientId:   aws.String(clientId),
+			Username:   aws.String(username),
+			SecretHash: secretHash(username, clientId, clientSecret),
+		},
+	)
+	if err != nil {
+		err = cognitoThrottle(err)
+		return
+	}
+	delivery = out.CodeDeliveryDetails
+
+	return
+}
+
+// GetUserAttributeVerificationCode sends the verification code of the user
+// attribute (email or phone_number) to the signed in user.
+//
+// Parameters:
+//   - accessToken: The access token of the signed in user.
+//   - attributeName: The attribute name, "email" or "phone_number".
+//
+// Returns:
+//   - delivery: The code delivery details.
+//   - err: An error if the operation fails. The *CognitoThrottleError is
+//     returned if too many codes were requested.
+func (a awsCognito) GetUserAttributeVerificationCode(accessToken,
+	attributeName string) (delivery *CodeDelivery, err error) {
+
+	out, err := a.Client.GetUserAttributeVerificationCode(a.ctx,
+		&cognitoidentityprovider.GetUserAttributeVerificationCodeInput{
+			AccessToken:   aws.String(accessToken),
+			AttributeName: aws.String(attributeName),
+		},
+	)
+	if err != nil {
+		err = cognitoThrottle(err)
+		return
+	}
+	delivery = out.CodeDeliveryDetails
+
+	return
+}
+
+// VerifyUserAttribute verifies the user attribute (email or phone_number) of
+// the signed in user with the code sent by GetUserAttributeVerificationCode.
+//
+// Parameters:
+//   - accessToken: The access token of the signed in user.
+//   - attributeName: The attribute name, "email" or "phone_number".
+//   - code: The verification code.
+//
+// Returns:
+//   - err: An error if the operation fails. The *CognitoThrottleError is
+//     returned if too many wrong codes were entered.
+func (a awsCognito) VerifyUserAttribute(accessToken, attributeName,
+	code string) (err error) {
+
+	_, err = a.Client.VerifyUserAttribute(a.ctx,
+		&cognitoidentityprovider.VerifyUserAttributeInput{
+			AccessToken:   aws.String(accessToken),
+			AttributeName: aws.String(attributeName),
+			Code:          aws.String(code),
+		},
+	)
+	if err != nil {
+		err = cognitoThrottle(err)
+	}
+
+	return
+}
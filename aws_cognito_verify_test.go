@@ -0,0 +1,52 @@
+package aws
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+)
+
+func TestCognitoThrottle(t *testing.T) {
+
+	// Throttling error is wrapped to CognitoThrottleError
+	err := cognitoThrottle(fmt.Errorf("op error: %w",
+		&types.LimitExceededException{Message: aws.String("limit")}))
+	var throttleErr *CognitoThrottleError
+	if !errors.As(err, &throttleErr) {
+		t.Error("throttle error is not wrapped:", err)
+		return
+	}
+	if throttleErr.Code != "LimitExceededException" {
+		t.Error("wrong throttle error code:", throttleErr.Code)
+	}
+
+	// Other errors are returned unchanged
+	err = cognitoThrottle(&types.CodeMismatchException{})
+	if errors.As(err, &throttleErr) {
+		t.Error("not throttle error is wrapped:", err)
+	}
+}
+
+func TestCognitoSecretHash(t *testing.T) {
+	// Secret hash is HMAC-SHA256 of username+clientId keyed by client secret
+	hash := SecretHash("user", "client", "secret")
+	if hash != "wvW87lzZoI+qQCVGmWVBJLlucdJ65huAVP1z+0MgA6E=" {
+		t.Error("wrong secret hash:", hash)
+	}
+}
+
+func TestCognitoEmailError(t *testing.T) {
+
+	err := emailError(&types.AliasExistsException{})
+	if !errors.Is(err, ErrCognitoEmailExists) {
+		t.Error("alias exists error is not translated:", err)
+	}
+
+	err = emailError(&types.CodeMismatchException{})
+	if !errors.Is(err, ErrCognitoCodeMismatch) {
+		t.Error("code mismatch error is not translated:", err)
+	}
+}
@@ -0,0 +1,308 @@
+package aws
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+)
+
+// Cost Explorer granularities.
+const (
+	CostDaily   = string(types.GranularityDaily)
+	CostMonthly = string(types.GranularityMonthly)
+)
+
+const (
+	// costDateFormat is the Cost Explorer date format.
+	costDateFormat = time.DateOnly
+
+	// costMetric is the default cost metric.
+	costMetric = "UnblendedCost"
+
+	// costTotal is the key of the not grouped cost series.
+	costTotal = "Total"
+)
+
+// awsCost is the AWS Cost Explorer client struct.
+type awsCost struct {
+	// ctx is the context.Context for AWS requests
+	ctx context.Context
+
+	// Client is the AWS Cost Explorer client
+	Client *costexplorer.Client
+}
+
+// CostQuery is the Cost Explorer cost and usage query.
+type CostQuery struct {
+	// Start is the first day of the period.
+	Start time.Time
+
+	// End is the day after the last day of the period.
+	End time.Time
+
+	// Granularity is CostDaily or CostMonthly. Default is CostMonthly.
+	Granularity string
+
+	// GroupBy is the dimension, for example "SERVICE" or "LINKED_ACCOUNT",
+	// or the tag key with the "tag:" prefix, for example "tag:tenant".
+	// Empty for the total cost.
+	GroupBy string
+
+	// Tags filters the costs by the tag values, for example
+	// {"env": {"prod"}}.
+	Tags map[string][]string
+
+	// Metric is the cost metric, for example "AmortizedCost" or
+	// "NetUnblendedCost". Default is "UnblendedCost".
+	Metric string
+}
+
+// CostSeries is the cost series of the group.
+type CostSeries struct {
+	// Key is the group key, for example the service name "Amazon Simple
+	// Storage Service" or the tag value. The not tagged costs key is empty,
+	// the not grouped series key is "Total".
+	Key string
+
+	// Points are the costs by periods.
+	Points []CostPoint
+}
+
+// Total returns the total amount of the series.
+func (s CostSeries) Total() (total float64) {
+	for _, p := range s.Points {
+		total += p.Amount
+	}
+	return
+}
+
+// CostPoint is the cost of the period.
+type CostPoint struct {
+	// Start is the first day of the period.
+	Start time.Time
+
+	// End is the day after the last day of the period.
+	End time.Time
+
+	// Amount is the cost amount.
+	Amount float64
+
+	// Unit is the amount unit, for example "USD".
+	Unit string
+
+	// Estimated is true if the period is not closed yet.
+	Estimated bool
+}
+
+// CostSummary is the month to date cost summary.
+type CostSummary struct {
+	// Start is the first day of the month.
+	Start time.Time
+
+	// End is the day after today.
+	End time.Time
+
+	// Total is the total cost.
+	Total float64
+
+	// Unit is the amount unit, for example "USD".
+	Unit string
+
+	// ByService is the cost by the service name.
+	ByService map[string]float64
+}
+
+// GetCostAndUsage returns the costs of the period grouped by the dimension
+// or tag.
+//
+// Parameters:
+//   - query: The cost and usage query.
+//
+// Returns:
+//   - series: The cost series sorted by key.
+//   - err: An error if the operation fails.
+func (a awsCost) GetCostAndUsage(query CostQuery) (series []CostSeries,
+	err error) {
+
+	if query.Granularity == "" {
+		query.Granularity = CostMonthly
+	}
+	if query.Metric == "" {
+		query.Metric = costMetric
+	}
+	input := &costexplorer.GetCostAndUsageInput{
+		Granularity: types.Granularity(query.Granularity),
+		Metrics:     []string{query.Metric},
+		TimePeriod: &types.DateInterval{
+			Start: aws.String(query.Start.Format(costDateFormat)),
+			End:   aws.String(query.End.Format(costDateFormat)),
+		},
+		Filter: costFilter(query.Tags),
+	}
+	tagKey, isTag := strings.CutPrefix(query.GroupBy, "tag:")
+	switch {
+	case isTag:
+		input.GroupBy = []types.GroupDefinition{{
+			Type: types.GroupDefinitionTypeTag,
+			Key:  aws.String(tagKey),
+		}}
+	case query.GroupBy != "":
+		input.GroupBy = []types.GroupDefinition{{
+			Type: types.GroupDefinitionTypeDimension,
+			Key:  aws.String(query.GroupBy),
+		}}
+	}
+
+	groups := make(map[string]*CostSeries)
+	add := func(key string, period *types.DateInterval, estimated bool,
+		metrics map[string]types.MetricValue) {
+
+		s, ok := groups[key]
+		if !ok {
+			s = &CostSeries{Key: key}
+			groups[key] = s
+		}
+		s.Points = append(s.Points, costPoint(period, estimated,
+			metrics[query.Metric]))
+	}
+	for {
+		var out *costexplorer.GetCostAndUsageOutput
+		out, err = a.Client.GetCostAndUsage(a.ctx, input)
+		if err != nil {
+			return
+		}
+		for _, r := range out.ResultsByTime {
+			if len(input.GroupBy) == 0 {
+				add(costTotal, r.TimePeriod, r.Estimated, r.Total)
+				continue
+			}
+			for _, g := range r.Groups {
+				key := strings.Join(g.Keys, ",")
+				if isTag {
+					// The tag group key is "tagKey$value"
+					key = strings.TrimPrefix(key, tagKey+"$")
+				}
+				add(key, r.TimePeriod, r.Estimated, g.Metrics)
+			}
+		}
+		if aws.ToString(out.NextPageToken) == "" {
+			break
+		}
+		input.NextPageToken = out.NextPageToken
+	}
+	for _, s := range groups {
+		series = append(series, *s)
+	}
+	slices.SortFunc(series, func(a, b CostSeries) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+
+	return
+}
+
+// ByService returns the costs of the period grouped by the service.
+//
+// Parameters:
+//   - start: The first day of the period.
+//   - end: The day after the last day of the period.
+//   - granularity: CostDaily or CostMonthly.
+//
+// Returns:
+//   - series: The cost series by the service name sorted by name.
+//   - err: An error if the operation fails.
+func (a awsCost) ByService(start, end time.Time, granularity string) (
+	series []CostSeries, err error) {
+
+	return a.GetCostAndUsage(CostQuery{Start: start, End: end,
+		Granularity: granularity, GroupBy: "SERVICE"})
+}
+
+// ByTag returns the costs of the period grouped by the tag value, for
+// example the cost per tenant.
+//
+// Parameters:
+//   - tagKey: The cost allocation tag key, for example "tenant".
+//   - start: The first day of the period.
+//   - end: The day after the last day of the period.
+//   - granularity: CostDaily or CostMonthly.
+//
+// Returns:
+//   - series: The cost series by the tag value sorted by value. The not
+//     tagged costs series key is empty.
+//   - err: An error if the operation fails.
+func (a awsCost) ByTag(tagKey string, start, end time.Time,
+	granularity string) (series []CostSeries, err error) {
+
+	return a.GetCostAndUsage(CostQuery{Start: start, End: end,
+		Granularity: granularity, GroupBy: "tag:" + tagKey})
+}
+
+// MonthToDate returns the cost summary of the current UTC month including
+// today.
+//
+// Returns:
+//   - summary: The month to date cost summary.
+//   - err: An error if the operation fails.
+func (a awsCost) MonthToDate() (summary CostSummary, err error) {
+	now := clock(a.ctx).Now().UTC()
+	summary.Start = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0,
+		time.UTC)
+	summary.End = time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0,
+		time.UTC)
+
+	series, err := a.ByService(summary.Start, summary.End, CostMonthly)
+	if err != nil {
+		return
+	}
+	summary.ByService = make(map[string]float64, len(series))
+	for _, s := range series {
+		total := s.Total()
+		summary.ByService[s.Key] = total
+		summary.Total += total
+		if summary.Unit == "" && len(s.Points) > 0 {
+			summary.Unit = s.Points[0].Unit
+		}
+	}
+
+	return
+}
+
+// costFilter returns the Cost Explorer filter of the tag values, nil if
+// tags is empty.
+func costFilter(tags map[string][]string) *types.Expression {
+	var filters []types.Expression
+	for _, key := range slices.Sorted(maps.Keys(tags)) {
+		filters = append(filters, types.Expression{Tags: &types.TagValues{
+			Key:    aws.String(key),
+			Values: tags[key],
+		}})
+	}
+	switch len(filters) {
+	case 0:
+		return nil
+	case 1:
+		return &filters[0]
+	}
+	return &types.Expression{And: filters}
+}
+
+// costPoint returns CostPoint from the Cost Explorer period metric.
+func costPoint(period *types.DateInterval, estimated bool,
+	metric types.MetricValue) (p CostPoint) {
+
+	p.Estimated = estimated
+	p.Unit = aws.ToString(metric.Unit)
+	p.Amount, _ = strconv.ParseFloat(aws.ToString(metric.Amount), 64)
+	if period != nil {
+		p.Start, _ = time.Parse(costDateFormat, aws.ToString(period.Start))
+		p.End, _ = time.Parse(costDateFormat, aws.ToString(period.End))
+	}
+	return
+}
@@ -0,0 +1,55 @@
+package aws
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestCostByTag checks Cost Explorer tag grouping, result pages and month to
+// date summary
+func TestCostByTag(t *testing.T) {
+
+	client := &pagesHTTPClient{bodies: []string{
+		`{"NextPageToken":"p2","ResultsByTime":[{"TimePeriod":` +
+			`{"Start":"2026-09-01","End":"2026-09-02"},"Groups":[` +
+			`{"Keys":["tenant$acme"],"Metrics":{"UnblendedCost":` +
+			`{"Amount":"1.5","Unit":"USD"}}},` +
+			`{"Keys":["tenant$"],"Metrics":{"UnblendedCost":` +
+			`{"Amount":"0.25","Unit":"USD"}}}]}]}`,
+		`{"ResultsByTime":[{"TimePeriod":` +
+			`{"Start":"2026-09-02","End":"2026-09-03"},"Estimated":true,` +
+			`"Groups":[{"Keys":["tenant$acme"],"Metrics":{"UnblendedCost":` +
+			`{"Amount":"2","Unit":"USD"}}}]}]}`,
+		`{"ResultsByTime":[{"TimePeriod":` +
+			`{"Start":"2026-10-01","End":"2026-10-17"},"Estimated":true,` +
+			`"Groups":[{"Keys":["Amazon S3"],"Metrics":{"UnblendedCost":` +
+			`{"Amount":"3","Unit":"USD"}}},{"Keys":["AWS Lambda"],` +
+			`"Metrics":{"UnblendedCost":{"Amount":"1","Unit":"USD"}}}]}]}`,
+	}}
+	a := newPagesTestAws(client)
+
+	start := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	series, err := a.Cost.ByTag("tenant", start, start.AddDate(0, 0, 2),
+		CostDaily)
+	if err != nil || len(series) != 2 || series[0].Key != "" ||
+		series[1].Key != "acme" || len(series[1].Points) != 2 ||
+		series[1].Total() != 3.5 || !series[1].Points[1].Estimated ||
+		!series[1].Points[1].Start.Equal(start.AddDate(0, 0, 1)) {
+		t.Fatal("wrong series:", series, err)
+	}
+	for _, s := range []string{`"Granularity":"DAILY"`,
+		`"GroupBy":[{"Key":"tenant","Type":"TAG"}]`,
+		`"TimePeriod":{"End":"2026-09-03","Start":"2026-09-01"}`} {
+		if !strings.Contains(client.requests[0], s) {
+			t.Error("request does not contain", s, client.requests[0])
+		}
+	}
+
+	summary, err := a.Cost.MonthToDate()
+	if err != nil || summary.Total != 4 || summary.Unit != "USD" ||
+		summary.ByService["Amazon S3"] != 3 || summary.Start.Day() != 1 ||
+		!summary.End.After(time.Now()) {
+		t.Error("wrong summary:", summary, err)
+	}
+}
@@ -0,0 +1,241 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"maps"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// ErrDynamoItemNotFound is returned by Dynamo Get wrapped with OpError when
+// the item does not exist. It wraps ErrNotFound.
+var ErrDynamoItemNotFound = fmt.Errorf("item %w", ErrNotFound)
+
+// awsDynamo is the AWS DynamoDB client struct.
+type awsDynamo struct {
+	// ctx is the context.Context for AWS requests
+	ctx context.Context
+
+	// Client is the AWS DynamoDB client
+	Client *dynamodb.Client
+}
+
+// DynamoExpression is the DynamoDB condition or update expression with its
+// attribute names and values placeholders, for example:
+//
+//	DynamoExpression{
+//		Expression: "#s = :status",
+//		Names:      map[string]string{"#s": "status"},
+//		Values:     map[string]any{":status": "active"},
+//	}
+//
+// The Values are marshaled with attributevalue package.
+type DynamoExpression struct {
+	// Expression is the DynamoDB expression.
+	Expression string
+
+	// Names are the expression attribute names placeholders.
+	Names map[string]string
+
+	// Values are the expression attribute values placeholders.
+	Values map[string]any
+}
+
+// Get reads the item by its primary key and unmarshals it into item.
+//
+// Parameters:
+//   - table: The table name.
+//   - key: The primary key, a struct or a map marshaled with attributevalue
+//     package, for example map[string]any{"id": "123"}.
+//   - item: The pointer to the struct or map to unmarshal the item into.
+//
+// Returns:
+//   - err: An error if the operation fails. The ErrDynamoItemNotFound is
+//     returned if the item does not exist, check it with IsNotFound.
+func (a awsDynamo) Get(table string, key, item any) (err error) {
+
+	// Marshal primary key
+	keyAv, err := attributevalue.MarshalMap(key)
+	if err != nil {
+		return
+	}
+
+	// Get item
+	out, err := a.Client.GetItem(a.ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(table),
+		Key:       keyAv,
+	})
+	if err != nil {
+		return
+	}
+	if out.Item == nil {
+		err = &OpError{
+			Service:   dynamodb.ServiceID,
+			Operation: "Get",
+			Resource:  table,
+			Err:       ErrDynamoItemNotFound,
+		}
+		return
+	}
+
+	// Unmarshal item
+	err = attributevalue.UnmarshalMap(out.Item, item)
+	return
+}
+
+// Put creates or replaces the item.
+//
+// Parameters:
+//   - table: The table name.
+//   - item: The struct or map marshaled with attributevalue package.
+//   - condition: The optional condition expression, for example
+//     "attribute_not_exists(id)" to create the item only if it does not exist.
+//
+// Returns:
+//   - err: An error if the operation fails. The error wraps
+//     ErrPreconditionFailed if the condition is not met.
+func (a awsDynamo) Put(table string, item any,
+	condition ...DynamoExpression) (err error) {
+
+	// Marshal item
+	itemAv, err := attributevalue.MarshalMap(item)
+	if err != nil {
+		return
+	}
+
+	input := &dynamodb.PutItemInput{
+		TableName: aws.String(table),
+		Item:      itemAv,
+	}
+	input.ConditionExpression, input.ExpressionAttributeNames,
+		input.ExpressionAttributeValues, err = dynamoExpressions(nil, condition)
+	if err != nil {
+		return
+	}
+
+	_, err = a.Client.PutItem(a.ctx, input)
+	return
+}
+
+// Delete deletes the item by its primary key. Deleting of not existing item
+// is not an error.
+//
+// Parameters:
+//   - table: The table name.
+//   - key: The primary key, a struct or a map marshaled with attributevalue
+//     package.
+//   - condition: The optional condition expression.
+//
+// Returns:
+//   - err: An error if the operation fails. The error wraps
+//     ErrPreconditionFailed if the condition is not met.
+func (a awsDynamo) Delete(table string, key any,
+	condition ...DynamoExpression) (err error) {
+
+	// Marshal primary key
+	keyAv, err := attributevalue.MarshalMap(key)
+	if err != nil {
+		return
+	}
+
+	input := &dynamodb.DeleteItemInput{
+		TableName: aws.String(table),
+		Key:       keyAv,
+	}
+	input.ConditionExpression, input.ExpressionAttributeNames,
+		input.ExpressionAttributeValues, err = dynamoExpressions(nil, condition)
+	if err != nil {
+		return
+	}
+
+	_, err = a.Client.DeleteItem(a.ctx, input)
+	return
+}
+
+// Update updates the item attributes by the update expression and unmarshals
+// the updated item into item.
+//
+// Parameters:
+//   - table: The table name.
+//   - key: The primary key, a struct or a map marshaled with attributevalue
+//     package.
+//   - update: The update expression, for example "SET #s = :status".
+//   - item: The pointer to the struct or map to unmarshal the updated item
+//     into. It may be nil if the updated item is not needed.
+//   - condition: The optional condition expression.
+//
+// Returns:
+//   - err: An error if the operation fails. The error wraps
+//     ErrPreconditionFailed if the condition is not met.
+func (a awsDynamo) Update(table string, key any, update DynamoExpression,
+	item any, condition ...DynamoExpression) (err error) {
+
+	// Marshal primary key
+	keyAv, err := attributevalue.MarshalMap(key)
+	if err != nil {
+		return
+	}
+
+	input := &dynamodb.UpdateItemInput{
+		TableName:        aws.String(table),
+		Key:              keyAv,
+		UpdateExpression: aws.String(update.Expression),
+	}
+	if item != nil {
+		input.ReturnValues = types.ReturnValueAllNew
+	}
+	input.ConditionExpression, input.ExpressionAttributeNames,
+		input.ExpressionAttributeValues, err = dynamoExpressions(&update,
+		condition)
+	if err != nil {
+		return
+	}
+
+	out, err := a.Client.UpdateItem(a.ctx, input)
+	if err != nil || item == nil {
+		return
+	}
+
+	// Unmarshal updated item
+	err = attributevalue.UnmarshalMap(out.Attributes, item)
+	return
+}
+
+// dynamoExpressions returns the condition expression and the merged attribute
+// names and values of the update and the condition expressions.
+func dynamoExpressions(update *DynamoExpression,
+	condition []DynamoExpression) (expression *string,
+	names map[string]string, values map[string]types.AttributeValue, err error) {
+
+	exprs := condition
+	if len(condition) > 0 {
+		expression = aws.String(condition[0].Expression)
+		exprs = condition[:1]
+	}
+	if update != nil {
+		exprs = append([]DynamoExpression{*update}, exprs...)
+	}
+
+	// Merge names and values placeholders
+	allValues := map[string]any{}
+	for _, expr := range exprs {
+		if len(expr.Names) > 0 {
+			if names == nil {
+				names = map[string]string{}
+			}
+			maps.Copy(names, expr.Names)
+		}
+		maps.Copy(allValues, expr.Values)
+	}
+	if len(allValues) == 0 {
+		return
+	}
+
+	// Marshal values
+	values, err = attributevalue.MarshalMap(allValues)
+	return
+}
@@ -0,0 +1,217 @@
+package aws
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// ErrDynamoUnprocessed is the error of the batch item which was not processed
+// by DynamoDB after all retries. It wraps ErrThrottled.
+var ErrDynamoUnprocessed = fmt.Errorf("item unprocessed, %w", ErrThrottled)
+
+const (
+	// dynamoBatchGetSize is the maximum number of keys in one BatchGetItem
+	// request.
+	dynamoBatchGetSize = 100
+
+	// dynamoBatchWriteSize is the maximum number of items in one
+	// BatchWriteItem request.
+	dynamoBatchWriteSize = 25
+
+	// dynamoBatchRetries is the number of retries of unprocessed items.
+	dynamoBatchRetries = 5
+
+	// dynamoBatchDelay is the first delay before the retry of unprocessed
+	// items, the delay is doubled on each retry.
+	dynamoBatchDelay = 50 * time.Millisecond
+)
+
+// dynamoBatchEntry is the marshaled item of the batch operation.
+type dynamoBatchEntry struct {
+	// name is the item name in the BatchError
+	name string
+
+	// item is the marshaled item or primary key
+	item map[string]types.AttributeValue
+
+	// delete is true for the delete requests of BatchWrite
+	delete bool
+}
+
+// BatchGet reads items by their primary keys. The keys are split to requests
+// of 100 keys, the unprocessed keys are retried with backoff. The items are
+// returned in any order.
+//
+// Parameters:
+//   - table: The table name.
+//   - keys: The primary keys, structs or maps marshaled with attributevalue
+//     package.
+//
+// Returns:
+//   - items: The found items, use DynamoItem.Unmarshal to unmarshal them.
+//   - err: The *BatchError if some of the keys failed. The Item field of the
+//     failed key contains its index in keys.
+func (a awsDynamo) BatchGet(table string, keys []any) (items []DynamoItem,
+	err error) {
+
+	var batchErr BatchError
+	entries := dynamoBatchEntries(keys, "", false, &batchErr)
+	clk := clock(a.ctx)
+	for chunk := range slices.Chunk(entries, dynamoBatchGetSize) {
+		dynamoBatch(clk, chunk, &batchErr, func(entries []dynamoBatchEntry) (
+			unprocessed []map[string]types.AttributeValue, err error) {
+
+			keys := make([]map[string]types.AttributeValue, len(entries))
+			for i, entry := range entries {
+				keys[i] = entry.item
+			}
+
+			out, err := a.Client.BatchGetItem(a.ctx, &dynamodb.BatchGetItemInput{
+				RequestItems: map[string]types.KeysAndAttributes{
+					table: {Keys: keys},
+				},
+			})
+			if err != nil {
+				return
+			}
+			for _, item := range out.Responses[table] {
+				items = append(items, item)
+			}
+			unprocessed = out.UnprocessedKeys[table].Keys
+			return
+		})
+	}
+	err = batchErr.err()
+
+	return
+}
+
+// BatchWrite puts and deletes items. The items are split to requests of 25
+// items, the unprocessed items are retried with backoff.
+//
+// Parameters:
+//   - table: The table name.
+//   - puts: The items to put, structs or maps marshaled with attributevalue
+//     package.
+//   - deletes: The primary keys of items to delete.
+//
+// Returns:
+//   - err: The *BatchError if some of the items failed. The Item field of the
+//     failed item contains its index in puts, or its index in deletes with
+//     the "delete " prefix.
+func (a awsDynamo) BatchWrite(table string, puts, deletes []any) (err error) {
+
+	var batchErr BatchError
+	entries := append(dynamoBatchEntries(puts, "", false, &batchErr),
+		dynamoBatchEntries(deletes, "delete ", true, &batchErr)...)
+	clk := clock(a.ctx)
+	for chunk := range slices.Chunk(entries, dynamoBatchWriteSize) {
+		dynamoBatch(clk, chunk, &batchErr, func(entries []dynamoBatchEntry) (
+			unprocessed []map[string]types.AttributeValue, err error) {
+
+			// Create write requests
+			requests := make([]types.WriteRequest, len(entries))
+			for i, entry := range entries {
+				if entry.delete {
+					requests[i].DeleteRequest = &types.DeleteRequest{Key: entry.item}
+				} else {
+					requests[i].PutRequest = &types.PutRequest{Item: entry.item}
+				}
+			}
+
+			out, err := a.Client.BatchWriteItem(a.ctx,
+				&dynamodb.BatchWriteItemInput{
+					RequestItems: map[string][]types.WriteRequest{table: requests},
+				},
+			)
+			if err != nil {
+				return
+			}
+			for _, request := range out.UnprocessedItems[table] {
+				switch {
+				case request.PutRequest != nil:
+					unprocessed = append(unprocessed, request.PutRequest.Item)
+				case request.DeleteRequest != nil:
+					unprocessed = append(unprocessed, request.DeleteRequest.Key)
+				}
+			}
+			return
+		})
+	}
+	err = batchErr.err()
+
+	return
+}
+
+// dynamoBatchEntries marshals the batch items. The items are named by their
+// indexes with prefix, the marshal errors are added to the batch error.
+func dynamoBatchEntries(items []any, prefix string, del bool,
+	batchErr *BatchError) (entries []dynamoBatchEntry) {
+
+	for i, item := range items {
+		name := prefix + strconv.Itoa(i)
+		av, err := attributevalue.MarshalMap(item)
+		if err != nil {
+			batchErr.add(name, err)
+			continue
+		}
+		entries = append(entries, dynamoBatchEntry{name, av, del})
+	}
+	return
+}
+
+// dynamoBatch executes the batch request for the entries and retries the
+// unprocessed entries with the clk backoff. The results of all entries are
+// added to the batch error.
+func dynamoBatch(clk Clock, entries []dynamoBatchEntry, batchErr *BatchError,
+	request func(entries []dynamoBatchEntry) (
+		unprocessed []map[string]types.AttributeValue, err error)) {
+
+	delay := dynamoBatchDelay
+	for retry := 0; len(entries) > 0; retry++ {
+
+		// Fail unprocessed entries when retries are over
+		if retry > dynamoBatchRetries {
+			for _, entry := range entries {
+				batchErr.add(entry.name, ErrDynamoUnprocessed)
+			}
+			return
+		}
+
+		// Wait before retry
+		if retry > 0 {
+			sleep(clk, delay)
+			delay *= 2
+		}
+
+		// Execute request, all entries fail on request error
+		unprocessed, err := request(entries)
+		if err != nil {
+			for _, entry := range entries {
+				batchErr.add(entry.name, err)
+			}
+			return
+		}
+
+		// Keep unprocessed entries for retry
+		var next []dynamoBatchEntry
+		for _, entry := range entries {
+			if slices.ContainsFunc(unprocessed,
+				func(item map[string]types.AttributeValue) bool {
+					return reflect.DeepEqual(item, entry.item)
+				}) {
+				next = append(next, entry)
+				continue
+			}
+			batchErr.add(entry.name, nil)
+		}
+		entries = next
+	}
+}
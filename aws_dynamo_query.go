@@ -0,0 +1,238 @@
+package aws
+
+import (
+	"iter"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// DynamoItem is the DynamoDB item returned by Dynamo Query and Scan.
+type DynamoItem map[string]types.AttributeValue
+
+// Unmarshal unmarshals the item into the struct or map pointed to by v.
+func (item DynamoItem) Unmarshal(v any) error {
+	return attributevalue.UnmarshalMap(item, v)
+}
+
+// DynamoQuery is the parameters of Dynamo Query and Scan. The conditions are
+// created with the expression package fluent builder, for example:
+//
+//	key := expression.Key("pk").Equal(expression.Value("user#1")).
+//		And(expression.Key("sk").BeginsWith("order#"))
+//	filter := expression.Name("status").Equal(expression.Value("paid"))
+//	q := aws.DynamoQuery{KeyCondition: &key, Filter: &filter}
+type DynamoQuery struct {
+	// IndexName is the name of the secondary index to query or scan.
+	IndexName string
+
+	// KeyCondition is the key condition of Query. It is ignored by Scan.
+	KeyCondition *expression.KeyConditionBuilder
+
+	// Filter is the condition applied to the items after they are read.
+	Filter *expression.ConditionBuilder
+
+	// Projection is the list of item attributes to return. All attributes
+	// are returned if it is empty.
+	Projection []string
+
+	// Limit is the maximum number of items read by one request (page size).
+	// Zero means DynamoDB default of 1 MB of data.
+	Limit int
+
+	// Descending returns the Query items in descending sort key order.
+	Descending bool
+
+	// ConsistentRead uses strongly consistent reads.
+	ConsistentRead bool
+}
+
+// build returns the expression built from the query conditions.
+func (q DynamoQuery) build(query bool) (expr expression.Expression, err error) {
+	builder := expression.NewBuilder()
+	empty := true
+	if query && q.KeyCondition != nil {
+		builder, empty = builder.WithKeyCondition(*q.KeyCondition), false
+	}
+	if q.Filter != nil {
+		builder, empty = builder.WithFilter(*q.Filter), false
+	}
+	if len(q.Projection) > 0 {
+		names := make([]expression.NameBuilder, 0, len(q.Projection))
+		for _, name := range q.Projection {
+			names = append(names, expression.Name(name))
+		}
+		proj := expression.NamesList(names[0], names[1:]...)
+		builder, empty = builder.WithProjection(proj), false
+	}
+	if empty {
+		return
+	}
+	return builder.Build()
+}
+
+// limit returns the Limit parameter value or nil if not set.
+func (q DynamoQuery) limit() *int32 {
+	if q.Limit <= 0 {
+		return nil
+	}
+	return aws.Int32(int32(q.Limit))
+}
+
+// optional returns pointer to s or nil if s is empty.
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return aws.String(s)
+}
+
+// Query returns iterator over the items of the table which match the query
+// key condition and filter. The next pages are requested automatically while
+// the items are iterated.
+//
+// Parameters:
+//   - table: The table name.
+//   - q: The query parameters, the KeyCondition is required.
+//
+// Returns:
+//   - iter.Seq2[DynamoItem, error]: The items iterator. Iteration stops after
+//     the first error.
+func (a awsDynamo) Query(table string, q DynamoQuery) iter.Seq2[DynamoItem, error] {
+	return a.QueryPages(table, q).Items()
+}
+
+// QueryPages returns the paginator over the pages of the items of the table
+// which match the query key condition and filter. The page size is the query
+// Limit.
+//
+// Parameters:
+//   - table: The table name.
+//   - q: The query parameters, the KeyCondition is required.
+//
+// Returns:
+//   - p: The items pages paginator.
+func (a awsDynamo) QueryPages(table string, q DynamoQuery) (
+	p *Paginator[DynamoItem]) {
+
+	expr, buildErr := q.build(true)
+	input := &dynamodb.QueryInput{
+		TableName:                 aws.String(table),
+		IndexName:                 optional(q.IndexName),
+		KeyConditionExpression:    expr.KeyCondition(),
+		FilterExpression:          expr.Filter(),
+		ProjectionExpression:      expr.Projection(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		Limit:                     q.limit(),
+		ScanIndexForward:          aws.Bool(!q.Descending),
+		ConsistentRead:            aws.Bool(q.ConsistentRead),
+	}
+	return NewPaginator(func() (items []DynamoItem, more bool, err error) {
+		if buildErr != nil {
+			err = buildErr
+			return
+		}
+		out, err := a.Client.Query(a.ctx, input)
+		if err != nil {
+			return
+		}
+		input.ExclusiveStartKey = out.LastEvaluatedKey
+		return dynamoItems(out.Items), len(out.LastEvaluatedKey) > 0, nil
+	})
+}
+
+// Scan returns iterator over all items of the table which match the filter.
+// The next pages are requested automatically while the items are iterated.
+//
+// Parameters:
+//   - table: The table name.
+//   - q: The scan parameters, the KeyCondition and Descending are ignored.
+//
+// Returns:
+//   - iter.Seq2[DynamoItem, error]: The items iterator. Iteration stops after
+//     the first error.
+func (a awsDynamo) Scan(table string, q DynamoQuery) iter.Seq2[DynamoItem, error] {
+	return a.ScanPages(table, q).Items()
+}
+
+// ScanPages returns the paginator over the pages of all items of the table
+// which match the filter. The page size is the scan Limit.
+//
+// Parameters:
+//   - table: The table name.
+//   - q: The scan parameters, the KeyCondition and Descending are ignored.
+//
+// Returns:
+//   - p: The items pages paginator.
+func (a awsDynamo) ScanPages(table string, q DynamoQuery) (
+	p *Paginator[DynamoItem]) {
+
+	expr, buildErr := q.build(false)
+	input := &dynamodb.ScanInput{
+		TableName:                 aws.String(table),
+		IndexName:                 optional(q.IndexName),
+		FilterExpression:          expr.Filter(),
+		ProjectionExpression:      expr.Projection(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		Limit:                     q.limit(),
+		ConsistentRead:            aws.Bool(q.ConsistentRead),
+	}
+	return NewPaginator(func() (items []DynamoItem, more bool, err error) {
+		if buildErr != nil {
+			err = buildErr
+			return
+		}
+		out, err := a.Client.Scan(a.ctx, input)
+		if err != nil {
+			return
+		}
+		input.ExclusiveStartKey = out.LastEvaluatedKey
+		return dynamoItems(out.Items), len(out.LastEvaluatedKey) > 0, nil
+	})
+}
+
+// dynamoItems converts the page items to the DynamoItem slice.
+func dynamoItems(items []map[string]types.AttributeValue) []DynamoItem {
+	list := make([]DynamoItem, len(items))
+	for i, item := range items {
+		list[i] = item
+	}
+	return list
+}
+
+// DynamoItems returns iterator over the items unmarshaled to type T:
+//
+//	for order, err := range aws.DynamoItems[Order](a.Dynamo.Query(table, q)) {
+//		...
+//	}
+func DynamoItems[T any](items iter.Seq2[DynamoItem, error]) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for item, err := range items {
+			var v T
+			if err == nil {
+				err = item.Unmarshal(&v)
+			}
+			if !yield(v, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// DynamoAll reads all items and returns them unmarshaled to slice of type T:
+//
+//	orders, err := aws.DynamoAll[Order](a.Dynamo.Query(table, q))
+func DynamoAll[T any](items iter.Seq2[DynamoItem, error]) (list []T, err error) {
+	for v, err := range DynamoItems[T](items) {
+		if err != nil {
+			return list, err
+		}
+		list = append(list, v)
+	}
+	return
+}
@@ -0,0 +1,306 @@
+package aws
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodbstreams"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodbstreams/types"
+)
+
+// dynamoStreamPoll is the delay between the stream reads when all shards
+// records are read.
+const dynamoStreamPoll = time.Second
+
+// awsDynamoStreams is the AWS DynamoDB Streams client struct.
+type awsDynamoStreams struct {
+	// ctx is the context.Context for AWS requests
+	ctx context.Context
+
+	// Client is the AWS DynamoDB Streams client
+	Client *dynamodbstreams.Client
+}
+
+// DynamoStreamEventType is the type of the DynamoDB stream event.
+type DynamoStreamEventType string
+
+// DynamoDB stream event types.
+const (
+	DynamoStreamInsert DynamoStreamEventType = "INSERT"
+	DynamoStreamModify DynamoStreamEventType = "MODIFY"
+	DynamoStreamRemove DynamoStreamEventType = "REMOVE"
+)
+
+// DynamoStreamEvent is the item change read from the DynamoDB stream.
+type DynamoStreamEvent struct {
+	// Type is the item change type: INSERT, MODIFY or REMOVE.
+	Type DynamoStreamEventType
+
+	// ShardID is the stream shard ID.
+	ShardID string
+
+	// SequenceNumber is the record sequence number in the shard.
+	SequenceNumber string
+
+	// CreatedAt is the approximate time when the item was changed.
+	CreatedAt time.Time
+
+	// Keys is the primary key of the changed item.
+	Keys DynamoItem
+
+	// NewImage is the item after the change. It is empty for REMOVE events
+	// and if the stream view type does not contain new images.
+	NewImage DynamoItem
+
+	// OldImage is the item before the change. It is empty for INSERT events
+	// and if the stream view type does not contain old images.
+	OldImage DynamoItem
+}
+
+// DynamoCheckpointStore saves the sequence numbers of the last handled
+// records of the stream shards. Implement it to keep checkpoints in a
+// database, a DynamoDB table for example, to continue the stream consuming
+// after restart.
+type DynamoCheckpointStore interface {
+	// Checkpoint returns the sequence number of the last handled record of
+	// the shard, or empty string if the shard was not read.
+	Checkpoint(streamArn, shardID string) (sequenceNumber string, err error)
+
+	// SetCheckpoint saves the sequence number of the last handled record of
+	// the shard.
+	SetCheckpoint(streamArn, shardID, sequenceNumber string) error
+}
+
+// DynamoMemoryCheckpoints is the in-memory DynamoCheckpointStore. The
+// checkpoints are lost when the program exits.
+type DynamoMemoryCheckpoints struct {
+	m  map[string]string
+	mu sync.RWMutex
+}
+
+// Checkpoint returns the sequence number of the last handled record of the
+// shard.
+func (s *DynamoMemoryCheckpoints) Checkpoint(streamArn, shardID string) (
+	sequenceNumber string, err error) {
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	sequenceNumber = s.m[streamArn+"/"+shardID]
+	return
+}
+
+// SetCheckpoint saves the sequence number of the last handled record of the
+// shard.
+func (s *DynamoMemoryCheckpoints) SetCheckpoint(streamArn, shardID,
+	sequenceNumber string) error {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.m == nil {
+		s.m = make(map[string]string)
+	}
+	s.m[streamArn+"/"+shardID] = sequenceNumber
+	return nil
+}
+
+// dynamoShard is the state of the stream shard read by Consume.
+type dynamoShard struct {
+	// parent is the parent shard ID
+	parent string
+
+	// iterator is the next shard iterator, empty if not requested yet
+	iterator string
+
+	// done is true when all records of the closed shard are read
+	done bool
+}
+
+// Consume reads the DynamoDB stream and calls handler for each item change.
+// The shards are discovered while the stream is read, the records of the
+// parent shard are handled before the records of its children. The sequence
+// number of each handled record is saved to the store, so the consuming
+// continues after the last handled record when Consume is restarted. The
+// shards without checkpoint are read from the oldest record.
+//
+// Get the stream ARN from the LatestStreamArn of the Dynamo DescribeTable.
+//
+// Parameters:
+//   - ctx: The context to stop consuming.
+//   - streamArn: The stream ARN.
+//   - store: The checkpoint store, for example &DynamoMemoryCheckpoints{}.
+//   - handler: The function called for each event. The consuming stops if
+//     it returns an error, the event is not checkpointed and is delivered
+//     again after restart.
+//
+// Returns:
+//   - err: The handler, store or stream read error. Nil is returned when ctx
+//     is canceled or all shards of the disabled stream are read.
+func (a awsDynamoStreams) Consume(ctx context.Context, streamArn string,
+	store DynamoCheckpointStore,
+	handler func(event DynamoStreamEvent) error) (err error) {
+
+	shards := make(map[string]*dynamoShard)
+	for ctx.Err() == nil {
+
+		// Discover new shards
+		if err = a.discoverShards(ctx, streamArn, shards); err != nil {
+			break
+		}
+
+		// Read shards whose parents are read
+		active := false
+		for id, shard := range shards {
+			if shard.done {
+				continue
+			}
+			if parent, ok := shards[shard.parent]; !ok || parent.done {
+				err = a.readShard(ctx, streamArn, id, shard, store, handler)
+				if err != nil {
+					break
+				}
+			}
+			active = active || !shard.done
+		}
+		if err != nil || !active {
+			break
+		}
+
+		// Wait for new records
+		select {
+		case <-ctx.Done():
+		case <-clock(a.ctx).After(dynamoStreamPoll):
+		}
+	}
+	if ctx.Err() != nil {
+		err = nil
+	}
+
+	return
+}
+
+// discoverShards adds the new stream shards to the shards map.
+func (a awsDynamoStreams) discoverShards(ctx context.Context, streamArn string,
+	shards map[string]*dynamoShard) (err error) {
+
+	input := &dynamodbstreams.DescribeStreamInput{
+		StreamArn: aws.String(streamArn),
+	}
+	for {
+		var out *dynamodbstreams.DescribeStreamOutput
+		out, err = a.Client.DescribeStream(ctx, input)
+		if err != nil {
+			return
+		}
+		for _, s := range out.StreamDescription.Shards {
+			id := aws.ToString(s.ShardId)
+			if _, ok := shards[id]; !ok {
+				shards[id] = &dynamoShard{parent: aws.ToString(s.ParentShardId)}
+			}
+		}
+		if out.StreamDescription.LastEvaluatedShardId == nil {
+			return
+		}
+		input.ExclusiveStartShardId = out.StreamDescription.LastEvaluatedShardId
+	}
+}
+
+// readShard reads the shard records until the end of the shard or the last
+// available record.
+func (a awsDynamoStreams) readShard(ctx context.Context, streamArn, id string,
+	shard *dynamoShard, store DynamoCheckpointStore,
+	handler func(event DynamoStreamEvent) error) (err error) {
+
+	// Get shard iterator after the checkpoint or at the oldest record
+	if shard.iterator == "" {
+		input := &dynamodbstreams.GetShardIteratorInput{
+			StreamArn:         aws.String(streamArn),
+			ShardId:           aws.String(id),
+			ShardIteratorType: types.ShardIteratorTypeTrimHorizon,
+		}
+		var sequenceNumber string
+		if sequenceNumber, err = store.Checkpoint(streamArn, id); err != nil {
+			return
+		}
+		if sequenceNumber != "" {
+			input.ShardIteratorType = types.ShardIteratorTypeAfterSequenceNumber
+			input.SequenceNumber = aws.String(sequenceNumber)
+		}
+		var out *dynamodbstreams.GetShardIteratorOutput
+		if out, err = a.Client.GetShardIterator(ctx, input); err != nil {
+			return
+		}
+		shard.iterator = aws.ToString(out.ShardIterator)
+	}
+
+	for ctx.Err() == nil {
+		var out *dynamodbstreams.GetRecordsOutput
+		out, err = a.Client.GetRecords(ctx, &dynamodbstreams.GetRecordsInput{
+			ShardIterator: aws.String(shard.iterator),
+		})
+		if err != nil {
+			return
+		}
+
+		// Handle records and save checkpoints
+		for _, r := range out.Records {
+			var event DynamoStreamEvent
+			if event, err = dynamoStreamEvent(id, r); err != nil {
+				return
+			}
+			if err = handler(event); err != nil {
+				return
+			}
+			err = store.SetCheckpoint(streamArn, id, event.SequenceNumber)
+			if err != nil {
+				return
+			}
+		}
+
+		// The shard is closed and all its records are read
+		if out.NextShardIterator == nil {
+			shard.done = true
+			return
+		}
+		shard.iterator = aws.ToString(out.NextShardIterator)
+
+		// All available records are read
+		if len(out.Records) == 0 {
+			return
+		}
+	}
+
+	return
+}
+
+// dynamoStreamEvent converts the stream record to the DynamoStreamEvent.
+func dynamoStreamEvent(shardID string, r types.Record) (
+	event DynamoStreamEvent, err error) {
+
+	event.Type = DynamoStreamEventType(r.EventName)
+	event.ShardID = shardID
+	if r.Dynamodb == nil {
+		return
+	}
+	event.SequenceNumber = aws.ToString(r.Dynamodb.SequenceNumber)
+	event.CreatedAt = aws.ToTime(r.Dynamodb.ApproximateCreationDateTime)
+	for _, image := range []struct {
+		to   *DynamoItem
+		from map[string]types.AttributeValue
+	}{
+		{&event.Keys, r.Dynamodb.Keys},
+		{&event.NewImage, r.Dynamodb.NewImage},
+		{&event.OldImage, r.Dynamodb.OldImage},
+	} {
+		if len(image.from) == 0 {
+			continue
+		}
+		if *image.to, err = attributevalue.FromDynamoDBStreamsMap(
+			image.from); err != nil {
+			return
+		}
+	}
+	return
+}
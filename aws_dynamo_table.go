@@ -0,0 +1,215 @@
+package aws
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// dynamoTableWait is the maximum time to wait for the table status change.
+const dynamoTableWait = 5 * time.Minute
+
+// DynamoTable is the DynamoDB table description.
+type DynamoTable = types.TableDescription
+
+// DynamoTableKey is the primary key schema of the DynamoDB table.
+type DynamoTableKey struct {
+	// PartitionKey is the partition key attribute name.
+	PartitionKey string
+
+	// PartitionKeyType is the partition key attribute type, "S", "N" or "B".
+	// Default is "S".
+	PartitionKeyType types.ScalarAttributeType
+
+	// SortKey is the optional sort key attribute name.
+	SortKey string
+
+	// SortKeyType is the sort key attribute type, "S", "N" or "B". Default is
+	// "S".
+	SortKeyType types.ScalarAttributeType
+}
+
+// DynamoCapacity is the provisioned throughput of the DynamoDB table.
+type DynamoCapacity struct {
+	// Read is the number of read capacity units.
+	Read int64
+
+	// Write is the number of write capacity units.
+	Write int64
+}
+
+// dynamoBilling returns the billing mode and the provisioned throughput of the
+// optional capacity. The on-demand mode is used if capacity is empty.
+func dynamoBilling(capacity []DynamoCapacity) (mode types.BillingMode,
+	throughput *types.ProvisionedThroughput) {
+
+	if len(capacity) == 0 {
+		mode = types.BillingModePayPerRequest
+		return
+	}
+	mode = types.BillingModeProvisioned
+	throughput = &types.ProvisionedThroughput{
+		ReadCapacityUnits:  aws.Int64(capacity[0].Read),
+		WriteCapacityUnits: aws.Int64(capacity[0].Write),
+	}
+	return
+}
+
+// CreateTable creates the table and waits until it is active.
+//
+// Parameters:
+//   - table: The table name.
+//   - key: The primary key schema.
+//   - capacity: The optional provisioned throughput. The table is created in
+//     the on-demand mode if capacity is not set.
+//
+// Returns:
+//   - desc: The table description.
+//   - err: An error if the operation fails.
+func (a awsDynamo) CreateTable(table string, key DynamoTableKey,
+	capacity ...DynamoCapacity) (desc *DynamoTable, err error) {
+
+	// Create key schema and attributes definitions
+	attrType := func(t types.ScalarAttributeType) types.ScalarAttributeType {
+		if t == "" {
+			return types.ScalarAttributeTypeS
+		}
+		return t
+	}
+	input := &dynamodb.CreateTableInput{
+		TableName: aws.String(table),
+		KeySchema: []types.KeySchemaElement{{
+			AttributeName: aws.String(key.PartitionKey),
+			KeyType:       types.KeyTypeHash,
+		}},
+		AttributeDefinitions: []types.AttributeDefinition{{
+			AttributeName: aws.String(key.PartitionKey),
+			AttributeType: attrType(key.PartitionKeyType),
+		}},
+	}
+	if key.SortKey != "" {
+		input.KeySchema = append(input.KeySchema, types.KeySchemaElement{
+			AttributeName: aws.String(key.SortKey),
+			KeyType:       types.KeyTypeRange,
+		})
+		input.AttributeDefinitions = append(input.AttributeDefinitions,
+			types.AttributeDefinition{
+				AttributeName: aws.String(key.SortKey),
+				AttributeType: attrType(key.SortKeyType),
+			},
+		)
+	}
+	input.BillingMode, input.ProvisionedThroughput = dynamoBilling(capacity)
+
+	// Create table and wait until it is active
+	if _, err = a.Client.CreateTable(a.ctx, input); err != nil {
+		return
+	}
+	desc, err = a.waitActive(table)
+
+	return
+}
+
+// DescribeTable returns the table description.
+//
+// Parameters:
+//   - table: The table name.
+//
+// Returns:
+//   - desc: The table description.
+//   - err: An error if the operation fails. The error wraps ErrNotFound if
+//     the table does not exist.
+func (a awsDynamo) DescribeTable(table string) (desc *DynamoTable, err error) {
+	out, err := a.Client.DescribeTable(a.ctx, &dynamodb.DescribeTableInput{
+		TableName: aws.String(table),
+	})
+	if err != nil {
+		return
+	}
+	desc = out.Table
+	return
+}
+
+// DeleteTable deletes the table and waits until it is deleted. The wait is
+// skipped in the dry-run mode, see WithDryRun.
+//
+// Parameters:
+//   - table: The table name.
+//
+// Returns:
+//   - err: An error if the operation fails.
+func (a awsDynamo) DeleteTable(table string) (err error) {
+	_, err = a.Client.DeleteTable(a.ctx, &dynamodb.DeleteTableInput{
+		TableName: aws.String(table),
+	})
+	if err != nil || isDryRun(a.ctx) {
+		return
+	}
+
+	err = dynamodb.NewTableNotExistsWaiter(a.Client).Wait(a.ctx,
+		&dynamodb.DescribeTableInput{TableName: aws.String(table)},
+		dynamoTableWait)
+	return
+}
+
+// UpdateTimeToLive enables or disables the time to live of the table items.
+//
+// Parameters:
+//   - table: The table name.
+//   - attribute: The name of the item attribute which contains the
+//     expiration time in Unix epoch seconds.
+//   - enabled: Enables or disables the time to live.
+//
+// Returns:
+//   - err: An error if the operation fails.
+func (a awsDynamo) UpdateTimeToLive(table, attribute string,
+	enabled bool) (err error) {
+
+	_, err = a.Client.UpdateTimeToLive(a.ctx, &dynamodb.UpdateTimeToLiveInput{
+		TableName: aws.String(table),
+		TimeToLiveSpecification: &types.TimeToLiveSpecification{
+			AttributeName: aws.String(attribute),
+			Enabled:       aws.Bool(enabled),
+		},
+	})
+	return
+}
+
+// UpdateCapacity switches the table between the on-demand and the
+// provisioned throughput modes or changes the provisioned throughput, and
+// waits until the table is active.
+//
+// Parameters:
+//   - table: The table name.
+//   - capacity: The provisioned throughput. The table is switched to the
+//     on-demand mode if capacity is not set.
+//
+// Returns:
+//   - desc: The table description.
+//   - err: An error if the operation fails.
+func (a awsDynamo) UpdateCapacity(table string, capacity ...DynamoCapacity) (
+	desc *DynamoTable, err error) {
+
+	input := &dynamodb.UpdateTableInput{TableName: aws.String(table)}
+	input.BillingMode, input.ProvisionedThroughput = dynamoBilling(capacity)
+	if _, err = a.Client.UpdateTable(a.ctx, input); err != nil {
+		return
+	}
+	desc, err = a.waitActive(table)
+
+	return
+}
+
+// waitActive waits until the table is active and returns its description.
+func (a awsDynamo) waitActive(table string) (desc *DynamoTable, err error) {
+	out, err := dynamodb.NewTableExistsWaiter(a.Client).WaitForOutput(a.ctx,
+		&dynamodb.DescribeTableInput{TableName: aws.String(table)},
+		dynamoTableWait)
+	if err != nil {
+		return
+	}
+	desc = out.Table
+	return
+}
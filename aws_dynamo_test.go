@@ -0,0 +1,342 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+)
+
+// TestDynamoErrors checks DynamoDB not found and conditional check errors
+func TestDynamoErrors(t *testing.T) {
+
+	// Conditional check failed
+	a := newErrorTestAws(http.StatusBadRequest, `{"__type":`+
+		`"com.amazonaws.dynamodb.v20120810#ConditionalCheckFailedException",`+
+		`"message":"The conditional request failed"}`)
+	err := a.Dynamo.Put("table", map[string]any{"id": "1"},
+		DynamoExpression{Expression: "attribute_not_exists(id)"})
+	if !errors.Is(err, ErrPreconditionFailed) {
+		t.Error("wrong put error:", err)
+	}
+	var opErr *OpError
+	if !errors.As(err, &opErr) || opErr.Resource != "table" {
+		t.Error("put error does not contain table name:", err)
+	}
+
+	// Item not found
+	a = newErrorTestAws(http.StatusOK, "{}")
+	var item map[string]any
+	err = a.Dynamo.Get("table", map[string]any{"id": "1"}, &item)
+	if !errors.Is(err, ErrDynamoItemNotFound) || !IsNotFound(err) {
+		t.Error("wrong get error:", err)
+	}
+}
+
+// TestDynamoExpressions checks merging of update and condition expressions
+func TestDynamoExpressions(t *testing.T) {
+
+	expression, names, values, err := dynamoExpressions(
+		&DynamoExpression{
+			Expression: "SET #s = :status",
+			Names:      map[string]string{"#s": "status"},
+			Values:     map[string]any{":status": "active"},
+		},
+		[]DynamoExpression{{
+			Expression: "#v = :version",
+			Names:      map[string]string{"#v": "version"},
+			Values:     map[string]any{":version": 1},
+		}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *expression != "#v = :version" {
+		t.Error("wrong condition expression:", *expression)
+	}
+	if len(names) != 2 || len(values) != 2 {
+		t.Errorf("wrong names %v or values %v", names, values)
+	}
+
+	// No expressions
+	expression, names, values, err = dynamoExpressions(nil, nil)
+	if err != nil || expression != nil || names != nil || values != nil {
+		t.Error("wrong empty expressions")
+	}
+}
+
+// TestDynamoQuery checks Query pagination and items unmarshaling
+func TestDynamoQuery(t *testing.T) {
+
+	client := &pagesHTTPClient{bodies: []string{
+		`{"Items":[{"id":{"S":"1"},"n":{"N":"1"}}],` +
+			`"LastEvaluatedKey":{"id":{"S":"1"}}}`,
+		`{"Items":[{"id":{"S":"2"},"n":{"N":"2"}}]}`,
+	}}
+	a := newPagesTestAws(client)
+
+	type item struct {
+		ID string `dynamodbav:"id"`
+		N  int    `dynamodbav:"n"`
+	}
+	key := expression.Key("id").Equal(expression.Value("1"))
+	items, err := DynamoAll[item](a.Dynamo.Query("table",
+		DynamoQuery{KeyCondition: &key, Limit: 1}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 || items[0].ID != "1" || items[1].N != 2 {
+		t.Errorf("wrong items: %+v", items)
+	}
+	if len(client.requests) != 2 ||
+		!strings.Contains(client.requests[1], "ExclusiveStartKey") {
+		t.Errorf("wrong requests: %v", client.requests)
+	}
+
+	// Stop iteration after first item
+	client = &pagesHTTPClient{bodies: []string{
+		`{"Items":[{"id":{"S":"1"}},{"id":{"S":"2"}}],` +
+			`"LastEvaluatedKey":{"id":{"S":"2"}}}`,
+	}}
+	a = newPagesTestAws(client)
+	for range a.Dynamo.Scan("table", DynamoQuery{}) {
+		break
+	}
+	if len(client.requests) != 1 {
+		t.Error("scan requests next page after iteration stop")
+	}
+}
+
+// TestDynamoBatchWrite checks BatchWrite chunks and unprocessed items retry
+func TestDynamoBatchWrite(t *testing.T) {
+
+	client := &pagesHTTPClient{bodies: []string{
+		`{"UnprocessedItems":{"table":[` +
+			`{"PutRequest":{"Item":{"id":{"S":"3"}}}}]}}`,
+		`{}`,
+		`{}`,
+	}}
+	a := newPagesTestAws(client)
+
+	var puts []any
+	for i := range 30 {
+		puts = append(puts, map[string]any{"id": strconv.Itoa(i)})
+	}
+	deletes := []any{map[string]any{"id": "100"}}
+	if err := a.Dynamo.BatchWrite("table", puts, deletes); err != nil {
+		t.Fatal(err)
+	}
+	if len(client.requests) != 3 {
+		t.Fatalf("wrong number of requests: %d", len(client.requests))
+	}
+	if !strings.Contains(client.requests[1], `"3"`) ||
+		strings.Contains(client.requests[1], `"4"`) {
+		t.Error("wrong retry request:", client.requests[1])
+	}
+	if !strings.Contains(client.requests[2], "DeleteRequest") {
+		t.Error("wrong delete request:", client.requests[2])
+	}
+
+	// All items of failed request are reported
+	a = newErrorTestAws(http.StatusBadRequest, `{"__type":`+
+		`"com.amazonaws.dynamodb.v20120810#ValidationException"}`)
+	err := a.Dynamo.BatchWrite("table", puts[:2], deletes)
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) || batchErr.Total != 3 ||
+		len(batchErr.Failed) != 3 || batchErr.Failed[2].Item != "delete 0" {
+		t.Errorf("wrong batch error: %v", err)
+	}
+}
+
+// TestDynamoTransactWrite checks transaction cancellation reasons
+func TestDynamoTransactWrite(t *testing.T) {
+
+	a := newErrorTestAws(http.StatusBadRequest, `{"__type":`+
+		`"com.amazonaws.dynamodb.v20120810#TransactionCanceledException",`+
+		`"Message":"Transaction cancelled","CancellationReasons":[`+
+		`{"Code":"None"},{"Code":"ConditionalCheckFailed",`+
+		`"Message":"The conditional request failed"}]}`)
+
+	tr := new(DynamoTransaction).
+		Put("orders", map[string]any{"id": "1"}).
+		Update("users", map[string]any{"id": "2"}, DynamoExpression{
+			Expression: "ADD orders :one",
+			Values:     map[string]any{":one": 1},
+		}, DynamoExpression{Expression: "attribute_exists(id)"})
+	if tr.Len() != 2 {
+		t.Fatal("wrong number of transaction items:", tr.Len())
+	}
+
+	err := a.Dynamo.TransactWrite(tr, "token")
+	var trErr *DynamoTransactionError
+	if !errors.As(err, &trErr) {
+		t.Fatal("wrong transaction error:", err)
+	}
+	t.Log(err)
+	if len(trErr.Reasons) != 1 || trErr.Reasons[0].Index != 1 ||
+		trErr.Reasons[0].Code != "ConditionalCheckFailed" {
+		t.Errorf("wrong cancellation reasons: %+v", trErr.Reasons)
+	}
+	if !errors.Is(err, ErrPreconditionFailed) || errors.Is(err, ErrConflict) {
+		t.Error("wrong transaction error sentinel")
+	}
+
+	// Builder errors are returned by TransactWrite
+	tr = new(DynamoTransaction).Put("orders", func() {})
+	if err = a.Dynamo.TransactWrite(tr); err == nil {
+		t.Error("marshal error is not returned")
+	}
+}
+
+// TestDynamoCreateTable checks CreateTable request and waiting for the table
+func TestDynamoCreateTable(t *testing.T) {
+
+	client := &pagesHTTPClient{bodies: []string{
+		`{"TableDescription":{"TableName":"table","TableStatus":"CREATING"}}`,
+		`{"Table":{"TableName":"table","TableStatus":"ACTIVE"}}`,
+	}}
+	a := newPagesTestAws(client)
+
+	desc, err := a.Dynamo.CreateTable("table", DynamoTableKey{
+		PartitionKey: "pk",
+		SortKey:      "sk",
+		SortKeyType:  "N",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if desc.TableStatus != "ACTIVE" {
+		t.Error("wrong table status:", desc.TableStatus)
+	}
+	for _, s := range []string{`"PAY_PER_REQUEST"`, `"RANGE"`,
+		`{"AttributeName":"sk","AttributeType":"N"}`} {
+		if !strings.Contains(client.requests[0], s) {
+			t.Errorf("create table request does not contain %s: %s", s,
+				client.requests[0])
+		}
+	}
+}
+
+// TestDynamoVersioned checks optimistic locking requests and conflict error
+func TestDynamoVersioned(t *testing.T) {
+
+	client := &pagesHTTPClient{bodies: []string{`{}`, `{}`}}
+	a := newPagesTestAws(client)
+
+	type item struct {
+		ID      string `dynamodbav:"id"`
+		Version int64  `dynamodbav:"version"`
+	}
+	version, err := a.Dynamo.PutVersioned("table", item{ID: "1"})
+	if err != nil || version != 1 {
+		t.Fatal("wrong put result:", version, err)
+	}
+	if !strings.Contains(client.requests[0], "attribute_not_exists") ||
+		!strings.Contains(client.requests[0], `"version":{"N":"1"}`) {
+		t.Error("wrong put request:", client.requests[0])
+	}
+
+	version, err = a.Dynamo.UpdateVersioned("table", map[string]any{"id": "1"},
+		1, DynamoExpression{
+			Expression: "set #s = :s REMOVE old",
+			Names:      map[string]string{"#s": "status"},
+			Values:     map[string]any{":s": "done"},
+		}, nil)
+	if err != nil || version != 2 {
+		t.Fatal("wrong update result:", version, err)
+	}
+	if !strings.Contains(client.requests[1],
+		`"UpdateExpression":"set #dynamoVersion = :dynamoNewVersion, #s = :s REMOVE old"`) {
+		t.Error("wrong update request:", client.requests[1])
+	}
+
+	// The SET keyword is not found in the placeholders
+	for _, tc := range []struct{ expression, want string }{
+		{"REMOVE #set SET a = :a",
+			"REMOVE #set SET #dynamoVersion = :dynamoNewVersion, a = :a"},
+		{"SET #Set_attr = :set",
+			"SET #dynamoVersion = :dynamoNewVersion, #Set_attr = :set"},
+		{"REMOVE #set, :set",
+			"SET #dynamoVersion = :dynamoNewVersion REMOVE #set, :set"},
+	} {
+		client.bodies = append(client.bodies, `{}`)
+		_, err = a.Dynamo.UpdateVersioned("table", map[string]any{"id": "1"},
+			1, DynamoExpression{Expression: tc.expression}, nil)
+		req := client.requests[len(client.requests)-1]
+		if err != nil ||
+			!strings.Contains(req, `"UpdateExpression":"`+tc.want+`"`) {
+			t.Error("wrong update expression:", tc.expression, req, err)
+		}
+	}
+
+	// Version conflict
+	a = newErrorTestAws(http.StatusBadRequest, `{"__type":`+
+		`"com.amazonaws.dynamodb.v20120810#ConditionalCheckFailedException"}`)
+	_, err = a.Dynamo.PutVersioned("table", item{ID: "1", Version: 3})
+	if !errors.Is(err, ErrDynamoVersionConflict) || !errors.Is(err, ErrConflict) {
+		t.Error("wrong conflict error:", err)
+	}
+}
+
+// TestDynamoStreamConsume checks the stream events and checkpoints
+func TestDynamoStreamConsume(t *testing.T) {
+
+	const arn = "arn:aws:dynamodb:us-east-1:1:table/t/stream/1"
+	describe := `{"StreamDescription":{"Shards":[{"ShardId":"s1",` +
+		`"SequenceNumberRange":{"StartingSequenceNumber":"1",` +
+		`"EndingSequenceNumber":"2"}}]}}`
+	records := `{"Records":[` +
+		`{"eventName":"INSERT","dynamodb":{"SequenceNumber":"1",` +
+		`"Keys":{"id":{"S":"1"}},"NewImage":{"id":{"S":"1"},"n":{"N":"1"}}}},` +
+		`{"eventName":"REMOVE","dynamodb":{"SequenceNumber":"2",` +
+		`"Keys":{"id":{"S":"1"}},"OldImage":{"id":{"S":"1"},"n":{"N":"1"}}}}]}`
+	client := &pagesHTTPClient{bodies: []string{
+		describe, `{"ShardIterator":"it1"}`, records,
+	}}
+	a := newPagesTestAws(client)
+
+	// Read closed shard, Consume returns when all records are read
+	store := &DynamoMemoryCheckpoints{}
+	var events []DynamoStreamEvent
+	err := a.DynamoStreams.Consume(context.Background(), arn, store,
+		func(event DynamoStreamEvent) error {
+			events = append(events, event)
+			return nil
+		})
+	if err != nil {
+		t.Fatal("consume error:", err)
+	}
+	if len(events) != 2 || events[0].Type != DynamoStreamInsert ||
+		events[1].Type != DynamoStreamRemove || events[1].ShardID != "s1" {
+		t.Fatal("wrong events:", events)
+	}
+	var v struct {
+		ID string `dynamodbav:"id"`
+		N  int    `dynamodbav:"n"`
+	}
+	if err = events[0].NewImage.Unmarshal(&v); err != nil || v.N != 1 ||
+		len(events[0].OldImage) != 0 || len(events[1].OldImage) != 2 {
+		t.Error("wrong event images:", v, err)
+	}
+	if seq, _ := store.Checkpoint(arn, "s1"); seq != "2" {
+		t.Error("wrong checkpoint:", seq)
+	}
+
+	// Restart from checkpoint, handler error stops consuming
+	client.bodies = []string{describe, `{"ShardIterator":"it2"}`, records}
+	client.requests = nil
+	errHandler := errors.New("handler error")
+	err = a.DynamoStreams.Consume(context.Background(), arn, store,
+		func(event DynamoStreamEvent) error { return errHandler })
+	if !errors.Is(err, errHandler) {
+		t.Error("wrong handler error:", err)
+	}
+	if !strings.Contains(client.requests[1], `"AFTER_SEQUENCE_NUMBER"`) ||
+		!strings.Contains(client.requests[1], `"SequenceNumber":"2"`) {
+		t.Error("wrong shard iterator request:", client.requests[1])
+	}
+}
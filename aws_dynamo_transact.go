@@ -0,0 +1,274 @@
+package aws
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// DynamoTransaction is the builder of the DynamoDB write transaction items:
+//
+//	t := new(aws.DynamoTransaction).
+//		Put("orders", order, aws.DynamoExpression{
+//			Expression: "attribute_not_exists(id)",
+//		}).
+//		Update("users", userKey, aws.DynamoExpression{
+//			Expression: "ADD orders :one",
+//			Values:     map[string]any{":one": 1},
+//		})
+//	err := a.Dynamo.TransactWrite(t)
+type DynamoTransaction struct {
+	items []types.TransactWriteItem
+	err   error
+}
+
+// Put adds the put item request to the transaction.
+func (t *DynamoTransaction) Put(table string, item any,
+	condition ...DynamoExpression) *DynamoTransaction {
+
+	av, err := t.marshal(item)
+	if err != nil {
+		return t
+	}
+	put := &types.Put{TableName: aws.String(table), Item: av}
+	put.ConditionExpression, put.ExpressionAttributeNames,
+		put.ExpressionAttributeValues, t.err = dynamoExpressions(nil, condition)
+	t.items = append(t.items, types.TransactWriteItem{Put: put})
+	return t
+}
+
+// Update adds the update item request to the transaction.
+func (t *DynamoTransaction) Update(table string, key any,
+	update DynamoExpression, condition ...DynamoExpression) *DynamoTransaction {
+
+	av, err := t.marshal(key)
+	if err != nil {
+		return t
+	}
+	upd := &types.Update{
+		TableName:        aws.String(table),
+		Key:              av,
+		UpdateExpression: aws.String(update.Expression),
+	}
+	upd.ConditionExpression, upd.ExpressionAttributeNames,
+		upd.ExpressionAttributeValues, t.err = dynamoExpressions(&update,
+		condition)
+	t.items = append(t.items, types.TransactWriteItem{Update: upd})
+	return t
+}
+
+// Delete adds the delete item request to the transaction.
+func (t *DynamoTransaction) Delete(table string, key any,
+	condition ...DynamoExpression) *DynamoTransaction {
+
+	av, err := t.marshal(key)
+	if err != nil {
+		return t
+	}
+	del := &types.Delete{TableName: aws.String(table), Key: av}
+	del.ConditionExpression, del.ExpressionAttributeNames,
+		del.ExpressionAttributeValues, t.err = dynamoExpressions(nil, condition)
+	t.items = append(t.items, types.TransactWriteItem{Delete: del})
+	return t
+}
+
+// ConditionCheck adds the condition check of the item which is not changed by
+// the transaction.
+func (t *DynamoTransaction) ConditionCheck(table string, key any,
+	condition DynamoExpression) *DynamoTransaction {
+
+	av, err := t.marshal(key)
+	if err != nil {
+		return t
+	}
+	check := &types.ConditionCheck{TableName: aws.String(table), Key: av}
+	check.ConditionExpression, check.ExpressionAttributeNames,
+		check.ExpressionAttributeValues, t.err = dynamoExpressions(nil,
+		[]DynamoExpression{condition})
+	t.items = append(t.items, types.TransactWriteItem{ConditionCheck: check})
+	return t
+}
+
+// Len returns the number of the transaction items.
+func (t *DynamoTransaction) Len() int { return len(t.items) }
+
+// marshal marshals the item or key and saves the first builder error.
+func (t *DynamoTransaction) marshal(v any) (av map[string]types.AttributeValue,
+	err error) {
+
+	if t.err != nil {
+		return nil, t.err
+	}
+	av, err = attributevalue.MarshalMap(v)
+	if err != nil {
+		t.err = err
+	}
+	return
+}
+
+// DynamoGet is the item of the DynamoDB read transaction.
+type DynamoGet struct {
+	// Table is the table name.
+	Table string
+
+	// Key is the primary key, a struct or a map marshaled with attributevalue
+	// package.
+	Key any
+}
+
+// DynamoCancelReason is the reason of the canceled transaction item.
+type DynamoCancelReason struct {
+	// Index is the index of the item in the transaction.
+	Index int
+
+	// Code is the cancellation reason code, for example
+	// "ConditionalCheckFailed" or "TransactionConflict".
+	Code string
+
+	// Message is the cancellation reason message.
+	Message string
+}
+
+// DynamoTransactionError is returned by TransactWrite and TransactGet when
+// DynamoDB cancels the transaction. It contains the reasons of the items which
+// caused the cancellation and wraps ErrPreconditionFailed, ErrConflict or
+// ErrThrottled depending on the reasons.
+type DynamoTransactionError struct {
+	// Reasons are the cancellation reasons of the items which caused the
+	// cancellation.
+	Reasons []DynamoCancelReason
+
+	// Err is the TransactionCanceledException error.
+	Err error
+}
+
+// dynamoCancelCodes maps the cancellation reason codes to the package
+// sentinel errors.
+var dynamoCancelCodes = map[string]error{
+	"ConditionalCheckFailed":        ErrPreconditionFailed,
+	"TransactionConflict":           ErrConflict,
+	"ProvisionedThroughputExceeded": ErrThrottled,
+	"ThrottlingError":               ErrThrottled,
+}
+
+// Error returns the error message with the cancellation reasons.
+func (e *DynamoTransactionError) Error() string {
+	reasons := make([]string, len(e.Reasons))
+	for i, r := range e.Reasons {
+		reasons[i] = fmt.Sprintf("item %d %s", r.Index, r.Code)
+	}
+	return fmt.Sprintf("transaction canceled (%s): %s",
+		strings.Join(reasons, ", "), e.Err)
+}
+
+// Unwrap returns the sentinel errors of the cancellation reasons and the
+// original error.
+func (e *DynamoTransactionError) Unwrap() (errs []error) {
+	for _, r := range e.Reasons {
+		if err, ok := dynamoCancelCodes[r.Code]; ok {
+			errs = append(errs, err)
+		}
+	}
+	return append(errs, e.Err)
+}
+
+// dynamoTransactionError returns DynamoTransactionError if err is the
+// TransactionCanceledException or err itself.
+func dynamoTransactionError(err error) error {
+	var canceled *types.TransactionCanceledException
+	if !errors.As(err, &canceled) {
+		return err
+	}
+
+	e := &DynamoTransactionError{Err: err}
+	for i, r := range canceled.CancellationReasons {
+		code := aws.ToString(r.Code)
+		if code == "" || code == "None" {
+			continue
+		}
+		e.Reasons = append(e.Reasons, DynamoCancelReason{
+			Index:   i,
+			Code:    code,
+			Message: aws.ToString(r.Message),
+		})
+	}
+	return e
+}
+
+// TransactWrite executes the write transaction. All items of the transaction
+// succeed or fail together.
+//
+// Parameters:
+//   - t: The transaction items.
+//   - token: The optional client request token which makes the transaction
+//     idempotent, the repeated transaction with the same token within 10
+//     minutes is not executed twice.
+//
+// Returns:
+//   - err: An error if the operation fails. The *DynamoTransactionError is
+//     returned if the transaction is canceled.
+func (a awsDynamo) TransactWrite(t *DynamoTransaction,
+	token ...string) (err error) {
+
+	if t.err != nil {
+		err = t.err
+		return
+	}
+
+	input := &dynamodb.TransactWriteItemsInput{TransactItems: t.items}
+	if len(token) > 0 {
+		input.ClientRequestToken = aws.String(token[0])
+	}
+	_, err = a.Client.TransactWriteItems(a.ctx, input)
+	err = dynamoTransactionError(err)
+
+	return
+}
+
+// TransactGet reads the items in one transaction.
+//
+// Parameters:
+//   - gets: The items to read.
+//
+// Returns:
+//   - items: The items in the order of gets. The item is nil if it does not
+//     exist.
+//   - err: An error if the operation fails. The *DynamoTransactionError is
+//     returned if the transaction is canceled.
+func (a awsDynamo) TransactGet(gets ...DynamoGet) (items []DynamoItem,
+	err error) {
+
+	// Create get requests
+	input := &dynamodb.TransactGetItemsInput{
+		TransactItems: make([]types.TransactGetItem, len(gets)),
+	}
+	for i, get := range gets {
+		var key map[string]types.AttributeValue
+		key, err = attributevalue.MarshalMap(get.Key)
+		if err != nil {
+			return
+		}
+		input.TransactItems[i].Get = &types.Get{
+			TableName: aws.String(get.Table),
+			Key:       key,
+		}
+	}
+
+	out, err := a.Client.TransactGetItems(a.ctx, input)
+	if err != nil {
+		err = dynamoTransactionError(err)
+		return
+	}
+
+	items = make([]DynamoItem, len(out.Responses))
+	for i, r := range out.Responses {
+		items[i] = r.Item
+	}
+
+	return
+}
@@ -0,0 +1,170 @@
+package aws
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// DynamoVersionAttribute is the name of the item attribute which contains the
+// item version used by PutVersioned and UpdateVersioned.
+var DynamoVersionAttribute = "version"
+
+// ErrDynamoVersionConflict is returned by PutVersioned and UpdateVersioned
+// when the item was changed by somebody else. It wraps ErrConflict.
+var ErrDynamoVersionConflict = fmt.Errorf("item version %w", ErrConflict)
+
+// dynamoSetClause finds the SET keyword of the update expression. The keyword
+// is at the expression start or after the white space, so the placeholders
+// like #set or :set are not matched.
+var dynamoSetClause = regexp.MustCompile(`(?i)(?:^|\s)(SET)(?:\s|$)`)
+
+// PutVersioned creates or replaces the item with the optimistic locking. The
+// item is written only if its version attribute in the table is equal to the
+// version attribute of the item, or the item does not exist if the version is
+// zero. The version is incremented on each write.
+//
+// Parameters:
+//   - table: The table name.
+//   - item: The struct or map marshaled with attributevalue package, which
+//     contains the DynamoVersionAttribute number attribute.
+//
+// Returns:
+//   - version: The new version of the item.
+//   - err: An error if the operation fails. The ErrDynamoVersionConflict is
+//     returned if the item version does not match.
+func (a awsDynamo) PutVersioned(table string, item any) (version int64,
+	err error) {
+
+	// Marshal item and get its version
+	av, err := attributevalue.MarshalMap(item)
+	if err != nil {
+		return
+	}
+	current, err := dynamoVersion(av)
+	if err != nil {
+		return
+	}
+	version = current + 1
+	av[DynamoVersionAttribute] = &types.AttributeValueMemberN{
+		Value: strconv.FormatInt(version, 10),
+	}
+
+	// Put item if version matches
+	input := &dynamodb.PutItemInput{TableName: aws.String(table), Item: av}
+	input.ConditionExpression, input.ExpressionAttributeNames,
+		input.ExpressionAttributeValues, err = dynamoExpressions(nil,
+		[]DynamoExpression{dynamoVersionCondition(current)})
+	if err != nil {
+		return
+	}
+	_, err = a.Client.PutItem(a.ctx, input)
+	err = dynamoVersionError(table, "PutVersioned", err)
+
+	return
+}
+
+// UpdateVersioned updates the item attributes with the optimistic locking.
+// The item is updated only if its version attribute in the table is equal to
+// version. The version attribute is incremented by the update.
+//
+// Parameters:
+//   - table: The table name.
+//   - key: The primary key, a struct or a map marshaled with attributevalue
+//     package.
+//   - version: The expected item version.
+//   - update: The update expression, for example "SET #s = :status".
+//   - item: The pointer to the struct or map to unmarshal the updated item
+//     into. It may be nil if the updated item is not needed.
+//
+// Returns:
+//   - newVersion: The new version of the item.
+//   - err: An error if the operation fails. The ErrDynamoVersionConflict is
+//     returned if the item version does not match.
+func (a awsDynamo) UpdateVersioned(table string, key any, version int64,
+	update DynamoExpression, item any) (newVersion int64, err error) {
+
+	// Add version increment to the SET clause of update expression
+	newVersion = version + 1
+	set := "#dynamoVersion = :dynamoNewVersion"
+	m := dynamoSetClause.FindStringSubmatchIndex(update.Expression)
+	if m != nil {
+		update.Expression = update.Expression[:m[3]] + " " + set + "," +
+			update.Expression[m[3]:]
+	} else {
+		update.Expression = "SET " + set + " " + update.Expression
+	}
+	update.Names = addMap(update.Names, "#dynamoVersion", DynamoVersionAttribute)
+	update.Values = addMap(update.Values, ":dynamoNewVersion", any(newVersion))
+
+	err = a.Update(table, key, update, item, dynamoVersionCondition(version))
+	err = dynamoVersionError(table, "UpdateVersioned", err)
+
+	return
+}
+
+// dynamoVersion returns the version attribute of the marshaled item. Zero is
+// returned if the item has no version attribute.
+func dynamoVersion(item map[string]types.AttributeValue) (version int64,
+	err error) {
+
+	av, ok := item[DynamoVersionAttribute]
+	if !ok {
+		return
+	}
+	n, ok := av.(*types.AttributeValueMemberN)
+	if !ok {
+		err = fmt.Errorf("item %s attribute is not a number",
+			DynamoVersionAttribute)
+		return
+	}
+	version, err = strconv.ParseInt(n.Value, 10, 64)
+	return
+}
+
+// dynamoVersionCondition returns the condition expression which checks the
+// item version.
+func dynamoVersionCondition(version int64) DynamoExpression {
+	names := map[string]string{"#dynamoVersion": DynamoVersionAttribute}
+	if version == 0 {
+		return DynamoExpression{
+			Expression: "attribute_not_exists(#dynamoVersion)",
+			Names:      names,
+		}
+	}
+	return DynamoExpression{
+		Expression: "#dynamoVersion = :dynamoVersion",
+		Names:      names,
+		Values:     map[string]any{":dynamoVersion": version},
+	}
+}
+
+// dynamoVersionError returns ErrDynamoVersionConflict wrapped with OpError if
+// err is the conditional check failed error.
+func dynamoVersionError(table, operation string, err error) error {
+	if !errors.Is(err, ErrPreconditionFailed) {
+		return err
+	}
+	return &OpError{
+		Service:   dynamodb.ServiceID,
+		Operation: operation,
+		Resource:  table,
+		Err:       fmt.Errorf("%w: %w", ErrDynamoVersionConflict, err),
+	}
+}
+
+// addMap returns the map m with added key and value. The map is copied, the
+// m is not changed.
+func addMap[K comparable, V any](m map[K]V, key K, value V) map[K]V {
+	res := make(map[K]V, len(m)+1)
+	maps.Copy(res, m)
+	res[key] = value
+	return res
+}
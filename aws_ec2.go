@@ -0,0 +1,228 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+// ErrEC2InstanceTerminated is returned by EC2 WaitForState when the instance
+// is terminated while waiting for other state.
+var ErrEC2InstanceTerminated = errors.New("ec2 instance terminated")
+
+// EC2 instance states.
+const (
+	EC2Pending  = string(types.InstanceStateNamePending)
+	EC2Running  = string(types.InstanceStateNameRunning)
+	EC2Stopping = string(types.InstanceStateNameStopping)
+	EC2Stopped  = string(types.InstanceStateNameStopped)
+)
+
+const (
+	// ec2WaitDelay is the first delay between the instances state polls, the
+	// delay is doubled up to ec2WaitMaxDelay.
+	ec2WaitDelay = 5 * time.Second
+
+	// ec2WaitMaxDelay is the maximum delay between the instances state
+	// polls.
+	ec2WaitMaxDelay = 30 * time.Second
+)
+
+// awsEC2 is the AWS EC2 client struct.
+type awsEC2 struct {
+	// ctx is the context.Context for AWS requests
+	ctx context.Context
+
+	// Client is the AWS EC2 client
+	Client *ec2.Client
+}
+
+// EC2Instance is the EC2 instance summary.
+type EC2Instance struct {
+	// ID is the instance ID.
+	ID string
+
+	// Name is the value of the instance Name tag.
+	Name string
+
+	// Type is the instance type, for example "t3.micro".
+	Type string
+
+	// State is the instance state: pending, running, stopping, stopped,
+	// shutting-down or terminated.
+	State string
+
+	// PrivateIP is the private IPv4 address.
+	PrivateIP string
+
+	// PublicIP is the public IPv4 address, empty if the instance has no
+	// public address or is stopped.
+	PublicIP string
+
+	// LaunchedAt is the time the instance was last started.
+	LaunchedAt time.Time
+
+	// Tags are the instance tags.
+	Tags map[string]string
+}
+
+// DescribeInstances returns the EC2 instances.
+//
+// Parameters:
+//   - filters: The EC2 filters, for example {"tag:env": {"preview"}} or
+//     {"instance-state-name": {"running"}}. Nil for all instances.
+//   - ids: The instance IDs. Empty for all instances.
+//
+// Returns:
+//   - instances: The instances sorted by ID.
+//   - err: An error if the operation fails. The error wraps ErrNotFound if
+//     one of the instances does not exist.
+func (a awsEC2) DescribeInstances(filters map[string][]string,
+	ids ...string) (instances []EC2Instance, err error) {
+
+	return a.describe(a.ctx, filters, ids...)
+}
+
+// StartInstances starts the stopped EC2 instances.
+//
+// Parameters:
+//   - ids: The instance IDs.
+//
+// Returns:
+//   - err: An error if the operation fails. The error wraps ErrNotFound if
+//     one of the instances does not exist, or ErrConflict if it can't be
+//     started in its current state.
+func (a awsEC2) StartInstances(ids ...string) (err error) {
+	_, err = a.Client.StartInstances(a.ctx, &ec2.StartInstancesInput{
+		InstanceIds: ids,
+	})
+	return
+}
+
+// StopInstances stops the running EC2 instances.
+//
+// Parameters:
+//   - ids: The instance IDs.
+//
+// Returns:
+//   - err: An error if the operation fails. The error wraps ErrNotFound if
+//     one of the instances does not exist, or ErrConflict if it can't be
+//     stopped in its current state.
+func (a awsEC2) StopInstances(ids ...string) (err error) {
+	_, err = a.Client.StopInstances(a.ctx, &ec2.StopInstancesInput{
+		InstanceIds: ids,
+	})
+	return
+}
+
+// WaitForState waits for all instances to be in the state. The instances
+// state is polled with the delay doubled from 5 up to 30 seconds.
+//
+// Parameters:
+//   - ctx: The context to stop waiting.
+//   - state: The expected state, for example EC2Running or EC2Stopped.
+//   - ids: The instance IDs.
+//
+// Returns:
+//   - instances: The instances in the state.
+//   - err: An error if the operation fails or ctx is canceled. The error
+//     wraps ErrEC2InstanceTerminated if one of the instances is terminated.
+func (a awsEC2) WaitForState(ctx context.Context, state string,
+	ids ...string) (instances []EC2Instance, err error) {
+
+	delay := ec2WaitDelay
+	for {
+		instances, err = a.describe(ctx, nil, ids...)
+		if err != nil && !IsRetryable(err) {
+			return
+		}
+		if err == nil {
+			done := true
+			for _, i := range instances {
+				switch i.State {
+				case state:
+				case string(types.InstanceStateNameShuttingDown),
+					string(types.InstanceStateNameTerminated):
+					err = fmt.Errorf("%w: %s", ErrEC2InstanceTerminated, i.ID)
+					return
+				default:
+					done = false
+				}
+			}
+			if done {
+				return
+			}
+		}
+
+		// Wait before next poll
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+			return
+		case <-clock(a.ctx).After(max(delay, RetryAfter(err))):
+		}
+		delay = min(delay*2, ec2WaitMaxDelay)
+	}
+}
+
+// describe returns the EC2 instances sorted by ID.
+func (a awsEC2) describe(ctx context.Context, filters map[string][]string,
+	ids ...string) (instances []EC2Instance, err error) {
+
+	input := &ec2.DescribeInstancesInput{InstanceIds: ids}
+	for _, name := range slices.Sorted(maps.Keys(filters)) {
+		input.Filters = append(input.Filters, types.Filter{
+			Name:   aws.String(name),
+			Values: filters[name],
+		})
+	}
+	for {
+		var out *ec2.DescribeInstancesOutput
+		out, err = a.Client.DescribeInstances(ctx, input)
+		if err != nil {
+			return
+		}
+		for _, r := range out.Reservations {
+			for i := range r.Instances {
+				instances = append(instances, ec2Instance(&r.Instances[i]))
+			}
+		}
+		if aws.ToString(out.NextToken) == "" {
+			break
+		}
+		input.NextToken = out.NextToken
+	}
+	slices.SortFunc(instances, func(a, b EC2Instance) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+
+	return
+}
+
+// ec2Instance returns EC2Instance from the EC2 instance.
+func ec2Instance(i *types.Instance) (instance EC2Instance) {
+	instance = EC2Instance{
+		ID:         aws.ToString(i.InstanceId),
+		Type:       string(i.InstanceType),
+		PrivateIP:  aws.ToString(i.PrivateIpAddress),
+		PublicIP:   aws.ToString(i.PublicIpAddress),
+		LaunchedAt: aws.ToTime(i.LaunchTime),
+		Tags:       make(map[string]string, len(i.Tags)),
+	}
+	if i.State != nil {
+		instance.State = string(i.State.Name)
+	}
+	for _, tag := range i.Tags {
+		instance.Tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+	}
+	instance.Name = instance.Tags["Name"]
+	return
+}
@@ -0,0 +1,51 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+)
+
+// TestEC2Instances checks EC2 instances pagination, filters and state wait
+func TestEC2Instances(t *testing.T) {
+
+	instance := func(id, state string) string {
+		return `<item><instanceId>` + id + `</instanceId>` +
+			`<instanceType>t3.micro</instanceType>` +
+			`<instanceState><code>16</code><name>` + state +
+			`</name></instanceState><tagSet><item><key>Name</key>` +
+			`<value>preview-` + id + `</value></item></tagSet></item>`
+	}
+	client := &pagesHTTPClient{bodies: []string{
+		`<DescribeInstancesResponse><reservationSet><item><instancesSet>` +
+			instance("i-2", "running") + `</instancesSet></item>` +
+			`</reservationSet><nextToken>n1</nextToken>` +
+			`</DescribeInstancesResponse>`,
+		`<DescribeInstancesResponse><reservationSet><item><instancesSet>` +
+			instance("i-1", "stopped") + `</instancesSet></item>` +
+			`</reservationSet></DescribeInstancesResponse>`,
+		`<DescribeInstancesResponse><reservationSet><item><instancesSet>` +
+			instance("i-1", "terminated") + `</instancesSet></item>` +
+			`</reservationSet></DescribeInstancesResponse>`,
+	}}
+	a := newPagesTestAws(client)
+
+	instances, err := a.EC2.DescribeInstances(
+		map[string][]string{"tag:env": {"preview"}})
+	if err != nil || len(instances) != 2 || instances[0].ID != "i-1" ||
+		instances[0].State != EC2Stopped ||
+		instances[1].Name != "preview-i-2" {
+		t.Fatal("wrong instances:", instances, err)
+	}
+	values, _ := url.ParseQuery(client.requests[0])
+	if values.Get("Filter.1.Name") != "tag:env" ||
+		values.Get("Filter.1.Value.1") != "preview" {
+		t.Error("wrong describe request:", values)
+	}
+
+	_, err = a.EC2.WaitForState(context.Background(), EC2Running, "i-1")
+	if !errors.Is(err, ErrEC2InstanceTerminated) {
+		t.Error("wrong wait for state error:", err)
+	}
+}
@@ -0,0 +1,242 @@
+package aws
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
+	"github.com/aws/aws-sdk-go-v2/service/ecr/types"
+)
+
+// ecrDeleteBatchSize is the maximum number of images in one
+// BatchDeleteImage request.
+const ecrDeleteBatchSize = 100
+
+// awsECR is the AWS Elastic Container Registry client struct.
+type awsECR struct {
+	// ctx is the context.Context for AWS requests
+	ctx context.Context
+
+	// Client is the AWS ECR client
+	Client *ecr.Client
+}
+
+// ECRAuth is the docker registry credentials of the ECR registry.
+type ECRAuth struct {
+	// Username is the docker login user name, always "AWS".
+	Username string
+
+	// Password is the docker login password.
+	Password string
+
+	// Endpoint is the registry URL, for example
+	// "https://123456789012.dkr.ecr.us-east-1.amazonaws.com".
+	Endpoint string
+
+	// ExpiresAt is the credentials expiration time.
+	ExpiresAt time.Time
+}
+
+// ECRImageID is the image digest or tag.
+type ECRImageID struct {
+	// Digest is the image digest, for example "sha256:...".
+	Digest string
+
+	// Tag is the image tag, empty for untagged image.
+	Tag string
+}
+
+// String returns the image tag or digest if the tag is empty.
+func (id ECRImageID) String() string {
+	if id.Tag != "" {
+		return id.Tag
+	}
+	return id.Digest
+}
+
+// identifier returns the ECR image identifier.
+func (id ECRImageID) identifier() types.ImageIdentifier {
+	return types.ImageIdentifier{
+		ImageDigest: optional(id.Digest),
+		ImageTag:    optional(id.Tag),
+	}
+}
+
+// ECRImage is the image in the ECR repository.
+type ECRImage struct {
+	// Digest is the image digest.
+	Digest string
+
+	// Tags are the image tags.
+	Tags []string
+
+	// Size is the image size in bytes.
+	Size int64
+
+	// PushedAt is the time the image was pushed.
+	PushedAt time.Time
+}
+
+// GetAuthorizationToken returns the docker registry credentials of the
+// account ECR registry. The credentials are valid for 12 hours.
+//
+// Returns:
+//   - auth: The registry credentials.
+//   - err: An error if the operation fails.
+func (a awsECR) GetAuthorizationToken() (auth ECRAuth, err error) {
+	out, err := a.Client.GetAuthorizationToken(a.ctx,
+		&ecr.GetAuthorizationTokenInput{})
+	if err != nil {
+		return
+	}
+	if len(out.AuthorizationData) == 0 {
+		err = errors.New("ecr authorization data is empty")
+		return
+	}
+
+	// The token is base64 encoded "user:password"
+	data := out.AuthorizationData[0]
+	token, err := base64.StdEncoding.DecodeString(
+		aws.ToString(data.AuthorizationToken))
+	if err != nil {
+		return
+	}
+	username, password, ok := strings.Cut(string(token), ":")
+	if !ok {
+		err = errors.New("wrong ecr authorization token")
+		return
+	}
+	auth = ECRAuth{
+		Username:  username,
+		Password:  password,
+		Endpoint:  aws.ToString(data.ProxyEndpoint),
+		ExpiresAt: aws.ToTime(data.ExpiresAt),
+	}
+	return
+}
+
+// ListImages returns the images of the repository. The tagged image is
+// returned once for each tag.
+//
+// Parameters:
+//   - repository: The repository name.
+//
+// Returns:
+//   - images: The image IDs.
+//   - err: An error if the operation fails. The error wraps ErrNotFound if
+//     the repository does not exist.
+func (a awsECR) ListImages(repository string) (images []ECRImageID,
+	err error) {
+
+	input := &ecr.ListImagesInput{RepositoryName: aws.String(repository)}
+	for {
+		var out *ecr.ListImagesOutput
+		out, err = a.Client.ListImages(a.ctx, input)
+		if err != nil {
+			return
+		}
+		for _, id := range out.ImageIds {
+			images = append(images, ECRImageID{
+				Digest: aws.ToString(id.ImageDigest),
+				Tag:    aws.ToString(id.ImageTag),
+			})
+		}
+		if out.NextToken == nil {
+			return
+		}
+		input.NextToken = out.NextToken
+	}
+}
+
+// DescribeImages returns the images details sorted by push time, the newest
+// first.
+//
+// Parameters:
+//   - repository: The repository name.
+//   - ids: The image IDs. Empty for all images of the repository.
+//
+// Returns:
+//   - images: The images.
+//   - err: An error if the operation fails. The error wraps ErrNotFound if
+//     the repository or one of the images does not exist.
+func (a awsECR) DescribeImages(repository string, ids ...ECRImageID) (
+	images []ECRImage, err error) {
+
+	input := &ecr.DescribeImagesInput{RepositoryName: aws.String(repository)}
+	for _, id := range ids {
+		input.ImageIds = append(input.ImageIds, id.identifier())
+	}
+	for {
+		var out *ecr.DescribeImagesOutput
+		out, err = a.Client.DescribeImages(a.ctx, input)
+		if err != nil {
+			return
+		}
+		for _, d := range out.ImageDetails {
+			images = append(images, ECRImage{
+				Digest:   aws.ToString(d.ImageDigest),
+				Tags:     d.ImageTags,
+				Size:     aws.ToInt64(d.ImageSizeInBytes),
+				PushedAt: aws.ToTime(d.ImagePushedAt),
+			})
+		}
+		if out.NextToken == nil {
+			break
+		}
+		input.NextToken = out.NextToken
+	}
+	slices.SortFunc(images, func(a, b ECRImage) int {
+		return b.PushedAt.Compare(a.PushedAt)
+	})
+
+	return
+}
+
+// BatchDeleteImage deletes the images from the repository. Deleting the tag
+// of the image with other tags removes the tag only, the image is deleted
+// with its last tag or by digest.
+//
+// Parameters:
+//   - repository: The repository name.
+//   - ids: The image IDs.
+//
+// Returns:
+//   - err: The *BatchError if some of the images were not deleted. The Item
+//     field of the failed image contains its tag or digest. An error if the
+//     operation fails.
+func (a awsECR) BatchDeleteImage(repository string, ids ...ECRImageID) (
+	err error) {
+
+	var batchErr BatchError
+	for batch := range slices.Chunk(ids, ecrDeleteBatchSize) {
+		input := &ecr.BatchDeleteImageInput{
+			RepositoryName: aws.String(repository),
+		}
+		for _, id := range batch {
+			input.ImageIds = append(input.ImageIds, id.identifier())
+		}
+		var out *ecr.BatchDeleteImageOutput
+		out, err = a.Client.BatchDeleteImage(a.ctx, input)
+		if err != nil {
+			return
+		}
+		for _, f := range out.Failures {
+			id := ECRImageID{}
+			if f.ImageId != nil {
+				id.Digest = aws.ToString(f.ImageId.ImageDigest)
+				id.Tag = aws.ToString(f.ImageId.ImageTag)
+			}
+			batchErr.add(id.String(), entryError(string(f.FailureCode),
+				aws.ToString(f.FailureReason)))
+		}
+		batchErr.Total += len(batch) - len(out.Failures)
+	}
+	err = batchErr.err()
+
+	return
+}
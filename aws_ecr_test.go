@@ -0,0 +1,57 @@
+package aws
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestECRAuthorizationToken checks ECR token decoding
+func TestECRAuthorizationToken(t *testing.T) {
+
+	client := &pagesHTTPClient{bodies: []string{
+		`{"authorizationData":[{"authorizationToken":"QVdTOnNlY3JldA==",` +
+			`"proxyEndpoint":"https://1.dkr.ecr.us-east-1.amazonaws.com",` +
+			`"expiresAt":1700000000}]}`,
+	}}
+	a := newPagesTestAws(client)
+
+	auth, err := a.ECR.GetAuthorizationToken()
+	if err != nil || auth.Username != "AWS" || auth.Password != "secret" ||
+		auth.Endpoint != "https://1.dkr.ecr.us-east-1.amazonaws.com" ||
+		auth.ExpiresAt.Unix() != 1700000000 {
+		t.Error("wrong authorization token:", auth, err)
+	}
+}
+
+// TestECRImages checks ECR images sorting and batch delete failures
+func TestECRImages(t *testing.T) {
+
+	client := &pagesHTTPClient{bodies: []string{
+		`{"imageDetails":[{"imageDigest":"sha256:a","imageTags":["v1"],` +
+			`"imagePushedAt":1700000000},{"imageDigest":"sha256:b",` +
+			`"imageTags":["v2"],"imagePushedAt":1700000100}]}`,
+		`{"imageIds":[{"imageDigest":"sha256:a","imageTag":"v1"}],` +
+			`"failures":[{"imageId":{"imageTag":"v0"},` +
+			`"failureCode":"ImageNotFound","failureReason":"not found"}]}`,
+	}}
+	a := newPagesTestAws(client)
+
+	images, err := a.ECR.DescribeImages("app")
+	if err != nil || len(images) != 2 || images[0].Digest != "sha256:b" {
+		t.Fatal("wrong images:", images, err)
+	}
+
+	err = a.ECR.BatchDeleteImage("app", ECRImageID{Tag: "v1"},
+		ECRImageID{Tag: "v0"})
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) || batchErr.Total != 2 ||
+		len(batchErr.Failed) != 1 || batchErr.Failed[0].Item != "v0" ||
+		!errors.Is(err, ErrNotFound) {
+		t.Error("wrong batch delete error:", err)
+	}
+	if !strings.Contains(client.requests[1], `"repositoryName":"app"`) ||
+		!strings.Contains(client.requests[1], `{"imageTag":"v0"}`) {
+		t.Error("wrong batch delete request:", client.requests[1])
+	}
+}
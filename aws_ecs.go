@@ -0,0 +1,337 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+)
+
+// ErrECSTaskFailed is returned by ECS RunTask when the task was not started.
+var ErrECSTaskFailed = errors.New("ecs task failed")
+
+const (
+	// ecsDescribeBatchSize is the maximum number of tasks in one
+	// DescribeTasks request.
+	ecsDescribeBatchSize = 100
+
+	// ecsWaitDelay is the first delay between the task status polls, the
+	// delay is doubled up to ecsWaitMaxDelay.
+	ecsWaitDelay = 5 * time.Second
+
+	// ecsWaitMaxDelay is the maximum delay between the task status polls.
+	ecsWaitMaxDelay = 30 * time.Second
+
+	// ecsStopped is the last status of the stopped task.
+	ecsStopped = "STOPPED"
+)
+
+// awsECS is the AWS Elastic Container Service client struct.
+type awsECS struct {
+	// ctx is the context.Context for AWS requests
+	ctx context.Context
+
+	// Client is the AWS ECS client
+	Client *ecs.Client
+}
+
+// ECSRunOptions are the optional parameters of the ECS RunTask.
+type ECSRunOptions struct {
+	// Subnets are the subnet IDs of the task network interface. Required for
+	// the Fargate tasks.
+	Subnets []string
+
+	// SecurityGroups are the security group IDs of the task network
+	// interface. Default is the VPC default security group.
+	SecurityGroups []string
+
+	// AssignPublicIP assigns the public IP to the task, required to pull
+	// images from the public subnet without NAT gateway.
+	AssignPublicIP bool
+
+	// Overrides are the container overrides.
+	Overrides []ECSContainerOverride
+
+	// StartedBy is the tag of the task, for example the job ID.
+	StartedBy string
+}
+
+// ECSContainerOverride overrides the container definition of the task.
+type ECSContainerOverride struct {
+	// Name is the container name in the task definition.
+	Name string
+
+	// Command replaces the container command.
+	Command []string
+
+	// Environment adds or replaces the container environment variables.
+	Environment map[string]string
+}
+
+// ECSTask is the ECS task.
+type ECSTask struct {
+	// ARN is the task ARN.
+	ARN string
+
+	// TaskDefinitionARN is the task definition ARN.
+	TaskDefinitionARN string
+
+	// LastStatus is the task status, for example "PROVISIONING", "RUNNING"
+	// or "STOPPED".
+	LastStatus string
+
+	// StopCode is the reason code of the stopped task, for example
+	// "EssentialContainerExited" or "TaskFailedToStart".
+	StopCode string
+
+	// StoppedReason is the reason of the stopped task.
+	StoppedReason string
+
+	// StartedAt is the task start time.
+	StartedAt time.Time
+
+	// StoppedAt is the task stop time.
+	StoppedAt time.Time
+
+	// Containers are the task containers.
+	Containers []ECSContainer
+}
+
+// Stopped returns true if the task is stopped.
+func (t ECSTask) Stopped() bool {
+	return t.LastStatus == ecsStopped
+}
+
+// ExitCode returns the first not zero exit code of the task containers, or
+// -1 if some container was not started, or 0 if all containers exited
+// successfully.
+func (t ECSTask) ExitCode() int {
+	code := 0
+	for _, c := range t.Containers {
+		switch {
+		case c.ExitCode > 0:
+			return c.ExitCode
+		case c.ExitCode < 0:
+			code = -1
+		}
+	}
+	return code
+}
+
+// ECSContainer is the container of the ECS task.
+type ECSContainer struct {
+	// Name is the container name.
+	Name string
+
+	// LastStatus is the container status.
+	LastStatus string
+
+	// ExitCode is the container exit code, -1 if the container was not
+	// started or is running.
+	ExitCode int
+
+	// Reason is the reason of the container stop.
+	Reason string
+}
+
+// RunTask runs the Fargate task.
+//
+// Parameters:
+//   - cluster: The cluster name or ARN.
+//   - taskDefinition: The task definition family, family:revision or ARN.
+//   - opts: The optional task parameters.
+//
+// Returns:
+//   - taskArn: The task ARN.
+//   - err: An error if the operation fails. The error wraps
+//     ErrECSTaskFailed with the failure reason if the task was not started.
+func (a awsECS) RunTask(cluster, taskDefinition string,
+	opts ...ECSRunOptions) (taskArn string, err error) {
+
+	var o ECSRunOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	input := &ecs.RunTaskInput{
+		Cluster:        aws.String(cluster),
+		TaskDefinition: aws.String(taskDefinition),
+		LaunchType:     types.LaunchTypeFargate,
+		StartedBy:      optional(o.StartedBy),
+	}
+	if len(o.Subnets) > 0 {
+		public := types.AssignPublicIpDisabled
+		if o.AssignPublicIP {
+			public = types.AssignPublicIpEnabled
+		}
+		input.NetworkConfiguration = &types.NetworkConfiguration{
+			AwsvpcConfiguration: &types.AwsVpcConfiguration{
+				Subnets:        o.Subnets,
+				SecurityGroups: o.SecurityGroups,
+				AssignPublicIp: public,
+			},
+		}
+	}
+	if len(o.Overrides) > 0 {
+		input.Overrides = &types.TaskOverride{}
+		for _, c := range o.Overrides {
+			override := types.ContainerOverride{
+				Name:    aws.String(c.Name),
+				Command: c.Command,
+			}
+			for _, k := range slices.Sorted(maps.Keys(c.Environment)) {
+				override.Environment = append(override.Environment,
+					types.KeyValuePair{
+						Name:  aws.String(k),
+						Value: aws.String(c.Environment[k]),
+					})
+			}
+			input.Overrides.ContainerOverrides = append(
+				input.Overrides.ContainerOverrides, override)
+		}
+	}
+
+	out, err := a.Client.RunTask(a.ctx, input)
+	if err != nil {
+		return
+	}
+	if len(out.Tasks) == 0 {
+		err = ErrECSTaskFailed
+		if len(out.Failures) > 0 {
+			f := out.Failures[0]
+			err = fmt.Errorf("%w: %s %s", ErrECSTaskFailed,
+				aws.ToString(f.Reason), aws.ToString(f.Detail))
+		}
+		return
+	}
+	taskArn = aws.ToString(out.Tasks[0].TaskArn)
+	return
+}
+
+// DescribeTasks returns the tasks.
+//
+// Parameters:
+//   - cluster: The cluster name or ARN.
+//   - taskArns: The task ARNs or IDs.
+//
+// Returns:
+//   - tasks: The found tasks. The stopped tasks are available about an hour
+//     after stop.
+//   - err: An error if the operation fails. The error wraps ErrNotFound if
+//     the cluster does not exist.
+func (a awsECS) DescribeTasks(cluster string, taskArns ...string) (
+	tasks []ECSTask, err error) {
+
+	return a.describe(a.ctx, cluster, taskArns...)
+}
+
+// StopTask stops the running task.
+//
+// Parameters:
+//   - cluster: The cluster name or ARN.
+//   - taskArn: The task ARN or ID.
+//   - reason: The stop reason shown in the task StoppedReason.
+//
+// Returns:
+//   - err: An error if the operation fails.
+func (a awsECS) StopTask(cluster, taskArn, reason string) (err error) {
+	_, err = a.Client.StopTask(a.ctx, &ecs.StopTaskInput{
+		Cluster: aws.String(cluster),
+		Task:    aws.String(taskArn),
+		Reason:  optional(reason),
+	})
+	return
+}
+
+// WaitUntilStopped waits for the task to stop. The task status is polled
+// with the delay doubled from 5 up to 30 seconds.
+//
+// Parameters:
+//   - ctx: The context to stop waiting.
+//   - cluster: The cluster name or ARN.
+//   - taskArn: The task ARN or ID.
+//
+// Returns:
+//   - task: The stopped task. Use its ExitCode or Containers to get the
+//     exit codes of the containers.
+//   - err: An error if the operation fails or ctx is canceled. The error
+//     wraps ErrNotFound if the task does not exist.
+func (a awsECS) WaitUntilStopped(ctx context.Context, cluster,
+	taskArn string) (task ECSTask, err error) {
+
+	delay := ecsWaitDelay
+	for {
+		var tasks []ECSTask
+		tasks, err = a.describe(ctx, cluster, taskArn)
+		switch {
+		case err != nil && !IsRetryable(err):
+			return
+		case err == nil && len(tasks) == 0:
+			err = fmt.Errorf("%w: ecs task %s", ErrNotFound, taskArn)
+			return
+		case err == nil && tasks[0].Stopped():
+			task = tasks[0]
+			return
+		}
+
+		// Wait before next poll
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+			return
+		case <-clock(a.ctx).After(max(delay, RetryAfter(err))):
+		}
+		delay = min(delay*2, ecsWaitMaxDelay)
+	}
+}
+
+// describe returns the tasks.
+func (a awsECS) describe(ctx context.Context, cluster string,
+	taskArns ...string) (tasks []ECSTask, err error) {
+
+	for batch := range slices.Chunk(taskArns, ecsDescribeBatchSize) {
+		var out *ecs.DescribeTasksOutput
+		out, err = a.Client.DescribeTasks(ctx, &ecs.DescribeTasksInput{
+			Cluster: aws.String(cluster),
+			Tasks:   batch,
+		})
+		if err != nil {
+			return
+		}
+		for i := range out.Tasks {
+			tasks = append(tasks, ecsTask(&out.Tasks[i]))
+		}
+	}
+	return
+}
+
+// ecsTask returns ECSTask from the ECS task.
+func ecsTask(t *types.Task) (task ECSTask) {
+	task = ECSTask{
+		ARN:               aws.ToString(t.TaskArn),
+		TaskDefinitionARN: aws.ToString(t.TaskDefinitionArn),
+		LastStatus:        aws.ToString(t.LastStatus),
+		StopCode:          string(t.StopCode),
+		StoppedReason:     aws.ToString(t.StoppedReason),
+		StartedAt:         aws.ToTime(t.StartedAt),
+		StoppedAt:         aws.ToTime(t.StoppedAt),
+	}
+	for _, c := range t.Containers {
+		container := ECSContainer{
+			Name:       aws.ToString(c.Name),
+			LastStatus: aws.ToString(c.LastStatus),
+			ExitCode:   -1,
+			Reason:     aws.ToString(c.Reason),
+		}
+		if c.ExitCode != nil {
+			container.ExitCode = int(*c.ExitCode)
+		}
+		task.Containers = append(task.Containers, container)
+	}
+	return
+}
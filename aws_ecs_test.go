@@ -0,0 +1,51 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestECSRunTask checks ECS Fargate task run request, failure and exit codes
+func TestECSRunTask(t *testing.T) {
+
+	client := &pagesHTTPClient{bodies: []string{
+		`{"tasks":[{"taskArn":"arn:task:1","lastStatus":"PROVISIONING"}]}`,
+		`{"tasks":[],"failures":[{"reason":"RESOURCE:MEMORY"}]}`,
+		`{"tasks":[{"taskArn":"arn:task:1","lastStatus":"STOPPED",` +
+			`"stopCode":"EssentialContainerExited","containers":[` +
+			`{"name":"sidecar","exitCode":0},{"name":"job","exitCode":3}]}]}`,
+	}}
+	a := newPagesTestAws(client)
+
+	taskArn, err := a.ECS.RunTask("jobs", "export:4", ECSRunOptions{
+		Subnets: []string{"subnet-1"},
+		Overrides: []ECSContainerOverride{{Name: "job",
+			Command:     []string{"export", "--all"},
+			Environment: map[string]string{"MODE": "full"}}},
+	})
+	if err != nil || taskArn != "arn:task:1" {
+		t.Fatal("wrong run task:", taskArn, err)
+	}
+	for _, s := range []string{`"launchType":"FARGATE"`,
+		`"subnets":["subnet-1"]`, `"assignPublicIp":"DISABLED"`,
+		`"command":["export","--all"]`, `{"name":"MODE","value":"full"}`} {
+		if !strings.Contains(client.requests[0], s) {
+			t.Error("wrong run task request:", s, client.requests[0])
+		}
+	}
+
+	_, err = a.ECS.RunTask("jobs", "export:4")
+	if !errors.Is(err, ErrECSTaskFailed) ||
+		!strings.Contains(err.Error(), "RESOURCE:MEMORY") {
+		t.Error("wrong run task failure:", err)
+	}
+
+	task, err := a.ECS.WaitUntilStopped(context.Background(), "jobs",
+		taskArn)
+	if err != nil || task.ExitCode() != 3 ||
+		task.StopCode != "EssentialContainerExited" {
+		t.Error("wrong stopped task:", task, err)
+	}
+}
@@ -0,0 +1,242 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge/types"
+)
+
+// ErrEventTooLarge is returned by Events PutEvents for the event which is
+// larger than the EventBridge event size limit.
+var ErrEventTooLarge = errors.New("eventbridge event is too large")
+
+const (
+	// eventsBatchSize is the maximum number of events in one PutEvents
+	// request.
+	eventsBatchSize = 10
+
+	// eventsBatchBytes is the maximum total size of the events in one
+	// PutEvents request.
+	eventsBatchBytes = 256 * 1024
+
+	// eventsTimeBytes is the size of the event time counted by EventBridge.
+	eventsTimeBytes = 14
+
+	// eventsBatchRetries is the number of retries of the failed events.
+	eventsBatchRetries = 3
+
+	// eventsBatchDelay is the first delay before the retry of failed events,
+	// the delay is doubled on each retry.
+	eventsBatchDelay = 100 * time.Millisecond
+)
+
+// awsEvents is the AWS EventBridge client struct.
+type awsEvents struct {
+	// ctx is the context.Context for AWS requests
+	ctx context.Context
+
+	// Client is the AWS EventBridge client
+	Client *eventbridge.Client
+}
+
+// Event is the EventBridge event.
+type Event struct {
+	// Bus is the event bus name or ARN. Default is the account default bus.
+	Bus string
+
+	// Source is the event source, for example "com.example.orders".
+	Source string
+
+	// DetailType is the event type, for example "OrderCreated".
+	DetailType string
+
+	// Detail is the event detail. The string and []byte must contain JSON
+	// object and are sent as is, other values are marshaled to JSON.
+	Detail any
+
+	// Resources are the ARNs of the resources the event is about.
+	Resources []string
+
+	// Time is the event time. Default is the time the event is received by
+	// EventBridge.
+	Time time.Time
+}
+
+// entry returns the PutEvents request entry and its size.
+func (e Event) entry() (entry types.PutEventsRequestEntry, size int,
+	err error) {
+
+	detail, err := messageBody(e.Detail)
+	if err != nil {
+		return
+	}
+	entry = types.PutEventsRequestEntry{
+		EventBusName: optional(e.Bus),
+		Source:       aws.String(e.Source),
+		DetailType:   aws.String(e.DetailType),
+		Detail:       aws.String(detail),
+		Resources:    e.Resources,
+	}
+	if !e.Time.IsZero() {
+		entry.Time = aws.Time(e.Time)
+		size += eventsTimeBytes
+	}
+	size += len(e.Source) + len(e.DetailType) + len(detail)
+	for _, resource := range e.Resources {
+		size += len(resource)
+	}
+	return
+}
+
+// Publish sends the event to the event bus.
+//
+// Parameters:
+//   - bus: The event bus name or ARN. Empty for the account default bus.
+//   - source: The event source, for example "com.example.orders".
+//   - detailType: The event type, for example "OrderCreated".
+//   - detail: The event detail. The string and []byte must contain JSON
+//     object and are sent as is, other values are marshaled to JSON.
+//
+// Returns:
+//   - eventID: The event ID.
+//   - err: An error if the operation fails or the event is rejected.
+func (a awsEvents) Publish(bus, source, detailType string, detail any) (
+	eventID string, err error) {
+
+	entry, _, err := Event{Bus: bus, Source: source, DetailType: detailType,
+		Detail: detail}.entry()
+	if err != nil {
+		return
+	}
+	out, err := a.Client.PutEvents(a.ctx, &eventbridge.PutEventsInput{
+		Entries: []types.PutEventsRequestEntry{entry},
+	})
+	if err != nil {
+		return
+	}
+	if r := out.Entries[0]; r.ErrorCode != nil {
+		err = entryError(aws.ToString(r.ErrorCode),
+			aws.ToString(r.ErrorMessage))
+		return
+	}
+	eventID = aws.ToString(out.Entries[0].EventId)
+	return
+}
+
+// PutEvents sends the events. The events are split to batches of up to 10
+// events and 256 KB, the events failed with the retryable errors are retried
+// with backoff.
+//
+// Parameters:
+//   - events: The events.
+//
+// Returns:
+//   - err: The *BatchError if some of the events failed. The Item field of
+//     the failed event contains its index in events.
+func (a awsEvents) PutEvents(events []Event) (err error) {
+
+	// Create entries and split them to batches
+	var batchErr BatchError
+	var batches [][]types.PutEventsRequestEntry
+	var batch []types.PutEventsRequestEntry
+	var ids []string
+	var batchIDs [][]string
+	size := 0
+	for i, event := range events {
+		id := strconv.Itoa(i)
+		entry, entrySize, err := event.entry()
+		switch {
+		case err != nil:
+			batchErr.add(id, err)
+			continue
+		case entrySize > eventsBatchBytes:
+			batchErr.add(id, ErrEventTooLarge)
+			continue
+		}
+		if len(batch) == eventsBatchSize ||
+			size+entrySize > eventsBatchBytes {
+			batches, batchIDs = append(batches, batch), append(batchIDs, ids)
+			batch, ids, size = nil, nil, 0
+		}
+		batch, ids = append(batch, entry), append(ids, id)
+		size += entrySize
+	}
+	if len(batch) > 0 {
+		batches, batchIDs = append(batches, batch), append(batchIDs, ids)
+	}
+
+	for i, batch := range batches {
+		a.putBatch(batch, batchIDs[i], &batchErr)
+	}
+	err = batchErr.err()
+
+	return
+}
+
+// putBatch sends the batch of events and retries the events failed with the
+// retryable errors. The results of all events are added to the batch error.
+// The ids are the names of the entries in the batch error.
+func (a awsEvents) putBatch(entries []types.PutEventsRequestEntry,
+	ids []string, batchErr *BatchError) {
+
+	var lastErr error
+	delay := eventsBatchDelay
+	for retry := 0; len(entries) > 0; retry++ {
+
+		// Fail events when retries are over
+		if retry > eventsBatchRetries {
+			for _, id := range ids {
+				batchErr.add(id, lastErr)
+			}
+			return
+		}
+
+		// Wait before retry
+		if retry > 0 {
+			sleep(clock(a.ctx), delay)
+			delay *= 2
+		}
+
+		// Send events, all events fail on not retryable request error
+		out, err := a.Client.PutEvents(a.ctx,
+			&eventbridge.PutEventsInput{Entries: entries})
+		if err != nil {
+			lastErr = err
+			if IsRetryable(err) {
+				continue
+			}
+			for _, id := range ids {
+				batchErr.add(id, err)
+			}
+			return
+		}
+
+		// Keep events failed by the service fault for retry. The results are
+		// in order of the request entries
+		var next []types.PutEventsRequestEntry
+		var nextIDs []string
+		for i, r := range out.Entries {
+			if i >= len(entries) {
+				break
+			}
+			if r.ErrorCode == nil {
+				batchErr.add(ids[i], nil)
+				continue
+			}
+			err := entryError(aws.ToString(r.ErrorCode),
+				aws.ToString(r.ErrorMessage))
+			if !IsRetryable(err) && err.Code() != "InternalFailure" {
+				batchErr.add(ids[i], err)
+				continue
+			}
+			lastErr = err
+			next, nextIDs = append(next, entries[i]), append(nextIDs, ids[i])
+		}
+		entries, ids = next, nextIDs
+	}
+}
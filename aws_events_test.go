@@ -0,0 +1,69 @@
+package aws
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestEventsPublish checks EventBridge publish request and entry errors
+func TestEventsPublish(t *testing.T) {
+
+	client := &pagesHTTPClient{bodies: []string{
+		`{"Entries":[{"EventId":"e1"}],"FailedEntryCount":0}`,
+		`{"Entries":[{"ErrorCode":"MalformedDetail","ErrorMessage":"bad"}],` +
+			`"FailedEntryCount":1}`,
+	}}
+	a := newPagesTestAws(client)
+
+	id, err := a.Events.Publish("orders", "com.example.orders",
+		"OrderCreated", map[string]string{"id": "o1"})
+	if err != nil || id != "e1" ||
+		!strings.Contains(client.requests[0], `"Detail":"{\"id\":\"o1\"}"`) ||
+		!strings.Contains(client.requests[0], `"EventBusName":"orders"`) {
+		t.Fatal("wrong publish result:", id, err, client.requests[0])
+	}
+	_, err = a.Events.Publish("", "com.example.orders", "OrderCreated", "{")
+	var e *Error
+	if !errors.As(err, &e) || e.Code() != "MalformedDetail" {
+		t.Error("wrong entry error:", err)
+	}
+}
+
+// TestEventsPutEvents checks EventBridge batches, retries and entry errors
+func TestEventsPutEvents(t *testing.T) {
+
+	first := make([]string, eventsBatchSize)
+	for i := range first {
+		first[i] = `{"EventId":"e"}`
+	}
+	first[3] = `{"ErrorCode":"ThrottlingException","ErrorMessage":"slow"}`
+	first[5] = `{"ErrorCode":"MalformedDetail","ErrorMessage":"bad"}`
+	client := &pagesHTTPClient{bodies: []string{
+		`{"Entries":[` + strings.Join(first, ",") + `],"FailedEntryCount":2}`,
+		`{"Entries":[{"EventId":"e"}]}`,
+		`{"Entries":[{"EventId":"e"},{"EventId":"e"}]}`,
+	}}
+	a := newPagesTestAws(client)
+
+	events := make([]Event, 13)
+	for i := range events {
+		events[i] = Event{Source: "s", DetailType: "t", Detail: `{}`}
+	}
+	events[12].Detail = strings.Repeat("x", eventsBatchBytes)
+	err := a.Events.PutEvents(events)
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) || batchErr.Total != 13 ||
+		len(batchErr.Failed) != 2 {
+		t.Fatal("wrong batch error:", err)
+	}
+	if !errors.Is(err, ErrEventTooLarge) || len(client.requests) != 3 ||
+		strings.Count(client.requests[1], `"Source"`) != 1 {
+		t.Error("wrong put events requests:", err, len(client.requests))
+	}
+	for _, item := range batchErr.Failed {
+		if item.Item != "5" && item.Item != "12" {
+			t.Error("wrong failed event:", item)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/firehose"
+	"github.com/aws/aws-sdk-go-v2/service/firehose/types"
+)
+
+// ErrFirehoseRecordTooLarge is returned by Firehose PutRecordBatch for the
+// record which is larger than the Firehose record size limit.
+var ErrFirehoseRecordTooLarge = errors.New("firehose record is too large")
+
+const (
+	// firehoseBatchSize is the maximum number of records in one
+	// PutRecordBatch request.
+	firehoseBatchSize = 500
+
+	// firehoseBatchBytes is the maximum total size of the records in one
+	// PutRecordBatch request.
+	firehoseBatchBytes = 4 * 1024 * 1024
+
+	// firehoseRecordBytes is the maximum size of the record.
+	firehoseRecordBytes = 1000 * 1024
+
+	// firehoseBatchRetries is the number of retries of the failed records.
+	firehoseBatchRetries = 3
+
+	// firehoseBatchDelay is the first delay before the retry of failed
+	// records, the delay is doubled on each retry.
+	firehoseBatchDelay = 100 * time.Millisecond
+)
+
+// awsFirehose is the AWS Data Firehose client struct.
+type awsFirehose struct {
+	// ctx is the context.Context for AWS requests
+	ctx context.Context
+
+	// Client is the AWS Firehose client
+	Client *firehose.Client
+}
+
+// PutRecord puts the record to the delivery stream.
+//
+// Parameters:
+//   - stream: The delivery stream name.
+//   - record: The record. The string and []byte are sent as is, other values
+//     are marshaled to JSON and framed by the newline, so the records
+//     delivered to S3 are JSON lines.
+//
+// Returns:
+//   - recordID: The record ID.
+//   - err: An error if the operation fails.
+func (a awsFirehose) PutRecord(stream string, record any) (recordID string,
+	err error) {
+
+	data, err := firehoseRecord(record)
+	if err != nil {
+		return
+	}
+	out, err := a.Client.PutRecord(a.ctx, &firehose.PutRecordInput{
+		DeliveryStreamName: aws.String(stream),
+		Record:             &types.Record{Data: data},
+	})
+	if err != nil {
+		return
+	}
+	recordID = aws.ToString(out.RecordId)
+	return
+}
+
+// PutRecordBatch puts the records to the delivery stream. The records are
+// split to batches of up to 500 records and 4 MB, the failed records are
+// retried with backoff.
+//
+// Parameters:
+//   - stream: The delivery stream name.
+//   - records: The records. The string and []byte are sent as is, other
+//     values are marshaled to JSON and framed by the newline.
+//
+// Returns:
+//   - err: The *BatchError if some of the records failed. The Item field of
+//     the failed record contains its index in records.
+func (a awsFirehose) PutRecordBatch(stream string, records []any) (
+	err error) {
+
+	// Create records and split them to batches
+	var batchErr BatchError
+	var batches [][]types.Record
+	var batch []types.Record
+	var ids []string
+	var batchIDs [][]string
+	size := 0
+	for i, record := range records {
+		id := strconv.Itoa(i)
+		data, err := firehoseRecord(record)
+		switch {
+		case err != nil:
+			batchErr.add(id, err)
+			continue
+		case len(data) > firehoseRecordBytes:
+			batchErr.add(id, ErrFirehoseRecordTooLarge)
+			continue
+		}
+		if len(batch) == firehoseBatchSize ||
+			size+len(data) > firehoseBatchBytes {
+			batches, batchIDs = append(batches, batch), append(batchIDs, ids)
+			batch, ids, size = nil, nil, 0
+		}
+		batch, ids = append(batch, types.Record{Data: data}), append(ids, id)
+		size += len(data)
+	}
+	if len(batch) > 0 {
+		batches, batchIDs = append(batches, batch), append(batchIDs, ids)
+	}
+
+	for i, batch := range batches {
+		a.putBatch(stream, batch, batchIDs[i], &batchErr)
+	}
+	err = batchErr.err()
+
+	return
+}
+
+// putBatch puts the batch of records and retries the failed records. The
+// results of all records are added to the batch error. The ids are the
+// names of the records in the batch error.
+func (a awsFirehose) putBatch(stream string, records []types.Record,
+	ids []string, batchErr *BatchError) {
+
+	var lastErr error
+	delay := firehoseBatchDelay
+	for retry := 0; len(records) > 0; retry++ {
+
+		// Fail records when retries are over
+		if retry > firehoseBatchRetries {
+			for _, id := range ids {
+				batchErr.add(id, lastErr)
+			}
+			return
+		}
+
+		// Wait before retry
+		if retry > 0 {
+			sleep(clock(a.ctx), delay)
+			delay *= 2
+		}
+
+		// Put records, all records fail on not retryable request error
+		out, err := a.Client.PutRecordBatch(a.ctx,
+			&firehose.PutRecordBatchInput{
+				DeliveryStreamName: aws.String(stream),
+				Records:            records,
+			},
+		)
+		if err != nil {
+			lastErr = err
+			if IsRetryable(err) {
+				continue
+			}
+			for _, id := range ids {
+				batchErr.add(id, err)
+			}
+			return
+		}
+
+		// Keep failed records for retry, Firehose fails records by
+		// throttling or service errors only. The results are in order of the
+		// request records
+		var next []types.Record
+		var nextIDs []string
+		for i, r := range out.RequestResponses {
+			if i >= len(records) {
+				break
+			}
+			if r.ErrorCode == nil {
+				batchErr.add(ids[i], nil)
+				continue
+			}
+			lastErr = entryError(aws.ToString(r.ErrorCode),
+				aws.ToString(r.ErrorMessage))
+			next, nextIDs = append(next, records[i]), append(nextIDs, ids[i])
+		}
+		records, ids = next, nextIDs
+	}
+}
+
+// firehoseRecord returns the record data. The string and []byte are returned
+// as is, other values are marshaled to JSON and framed by the newline.
+func firehoseRecord(record any) (data []byte, err error) {
+	switch v := record.(type) {
+	case string:
+		return []byte(v), nil
+	case []byte:
+		return v, nil
+	}
+	body, err := messageBody(record)
+	if err != nil {
+		return
+	}
+	data = []byte(body + "\n")
+	return
+}
@@ -0,0 +1,43 @@
+package aws
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestFirehosePutRecordBatch checks Firehose JSON lines and failed records
+// retry
+func TestFirehosePutRecordBatch(t *testing.T) {
+
+	client := &pagesHTTPClient{bodies: []string{
+		`{"FailedPutCount":1,"RequestResponses":[{"RecordId":"r1"},` +
+			`{"ErrorCode":"ServiceUnavailableException","ErrorMessage":"busy"}]}`,
+		`{"FailedPutCount":0,"RequestResponses":[{"RecordId":"r2"}]}`,
+	}}
+	a := newPagesTestAws(client)
+
+	err := a.Firehose.PutRecordBatch("analytics", []any{
+		map[string]string{"event": "click"},
+		"raw line\n",
+		make([]byte, firehoseRecordBytes+1),
+	})
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) || len(batchErr.Failed) != 1 ||
+		!errors.Is(err, ErrFirehoseRecordTooLarge) {
+		t.Fatal("wrong batch error:", err)
+	}
+
+	var input struct{ Records []struct{ Data []byte } }
+	json.Unmarshal([]byte(client.requests[0]), &input)
+	if len(input.Records) != 2 ||
+		string(input.Records[0].Data) != `{"event":"click"}`+"\n" {
+		t.Error("wrong batch request:", client.requests[0])
+	}
+	json.Unmarshal([]byte(client.requests[1]), &input)
+	if len(input.Records) != 1 || string(input.Records[0].Data) != "raw line\n" ||
+		!strings.Contains(client.requests[1], `"DeliveryStreamName":"analytics"`) {
+		t.Error("wrong retry request:", client.requests[1])
+	}
+}
@@ -0,0 +1,267 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/iam/types"
+)
+
+// awsIAM is the AWS Identity and Access Management client struct.
+type awsIAM struct {
+	// ctx is the context.Context for AWS requests
+	ctx context.Context
+
+	// Client is the AWS IAM client
+	Client *iam.Client
+}
+
+// IAMRole is the IAM role.
+type IAMRole struct {
+	// Name is the role name.
+	Name string
+
+	// ARN is the role ARN.
+	ARN string
+
+	// ID is the role unique ID.
+	ID string
+
+	// Path is the role path.
+	Path string
+
+	// Description is the role description.
+	Description string
+
+	// AssumeRolePolicy is the JSON trust policy of the role.
+	AssumeRolePolicy string
+
+	// MaxSessionDuration is the maximum session duration of the role.
+	MaxSessionDuration time.Duration
+
+	// CreatedAt is the role creation time.
+	CreatedAt time.Time
+}
+
+// IAMPolicy is the managed policy attached to the IAM role.
+type IAMPolicy struct {
+	// Name is the policy name.
+	Name string
+
+	// ARN is the policy ARN.
+	ARN string
+}
+
+// IAMDecision is the result of the policy simulation of one action on one
+// resource.
+type IAMDecision struct {
+	// Action is the simulated action, for example "s3:GetObject".
+	Action string
+
+	// Resource is the simulated resource ARN, "*" for all resources.
+	Resource string
+
+	// Decision is the simulation decision: allowed, explicitDeny or
+	// implicitDeny.
+	Decision string
+
+	// MissingContext are the context keys used by the policy conditions but
+	// not provided to the simulation. The decision may be wrong when they
+	// are missing.
+	MissingContext []string
+}
+
+// Allowed returns true if the action is allowed on the resource.
+func (d IAMDecision) Allowed() bool {
+	return d.Decision == string(types.PolicyEvaluationDecisionTypeAllowed)
+}
+
+// ListRoles returns the IAM roles.
+//
+// Parameters:
+//   - pathPrefix: The role path prefix, for example "/service-role/". Empty
+//     for all roles.
+//
+// Returns:
+//   - roles: The roles.
+//   - err: An error if the operation fails.
+func (a awsIAM) ListRoles(pathPrefix string) (roles []IAMRole, err error) {
+	input := &iam.ListRolesInput{PathPrefix: optional(pathPrefix)}
+	for {
+		var out *iam.ListRolesOutput
+		out, err = a.Client.ListRoles(a.ctx, input)
+		if err != nil {
+			return
+		}
+		for i := range out.Roles {
+			roles = append(roles, iamRole(&out.Roles[i]))
+		}
+		if !out.IsTruncated {
+			return
+		}
+		input.Marker = out.Marker
+	}
+}
+
+// GetRole returns the IAM role.
+//
+// Parameters:
+//   - name: The role name.
+//
+// Returns:
+//   - role: The role.
+//   - err: An error if the operation fails. The error wraps ErrNotFound if
+//     the role does not exist.
+func (a awsIAM) GetRole(name string) (role IAMRole, err error) {
+	out, err := a.Client.GetRole(a.ctx, &iam.GetRoleInput{
+		RoleName: aws.String(name),
+	})
+	if err != nil {
+		return
+	}
+	role = iamRole(out.Role)
+	return
+}
+
+// ListAttachedRolePolicies returns the managed policies attached to the IAM
+// role.
+//
+// Parameters:
+//   - roleName: The role name.
+//
+// Returns:
+//   - policies: The attached policies.
+//   - err: An error if the operation fails. The error wraps ErrNotFound if
+//     the role does not exist.
+func (a awsIAM) ListAttachedRolePolicies(roleName string) (
+	policies []IAMPolicy, err error) {
+
+	input := &iam.ListAttachedRolePoliciesInput{
+		RoleName: aws.String(roleName),
+	}
+	for {
+		var out *iam.ListAttachedRolePoliciesOutput
+		out, err = a.Client.ListAttachedRolePolicies(a.ctx, input)
+		if err != nil {
+			return
+		}
+		for _, p := range out.AttachedPolicies {
+			policies = append(policies, IAMPolicy{
+				Name: aws.ToString(p.PolicyName),
+				ARN:  aws.ToString(p.PolicyArn),
+			})
+		}
+		if !out.IsTruncated {
+			return
+		}
+		input.Marker = out.Marker
+	}
+}
+
+// SimulatePrincipalPolicy simulates the policies of the user, group or role
+// for the actions on the resources. Use it to check the role permissions
+// before the code which uses them is deployed.
+//
+// Parameters:
+//   - principalArn: The ARN of the user, group or role, for example the
+//     Lambda function execution role ARN.
+//   - actions: The actions, for example "s3:GetObject".
+//   - resources: The resource ARNs, for example "arn:aws:s3:::bucket/*".
+//     Empty for all resources.
+//
+// Returns:
+//   - decisions: The decisions for each action and resource.
+//   - err: An error if the operation fails.
+func (a awsIAM) SimulatePrincipalPolicy(principalArn string,
+	actions []string, resources ...string) (decisions []IAMDecision,
+	err error) {
+
+	input := &iam.SimulatePrincipalPolicyInput{
+		PolicySourceArn: aws.String(principalArn),
+		ActionNames:     actions,
+		ResourceArns:    resources,
+	}
+	for {
+		var out *iam.SimulatePrincipalPolicyOutput
+		out, err = a.Client.SimulatePrincipalPolicy(a.ctx, input)
+		if err != nil {
+			return
+		}
+		for _, r := range out.EvaluationResults {
+			decisions = append(decisions, IAMDecision{
+				Action:         aws.ToString(r.EvalActionName),
+				Resource:       aws.ToString(r.EvalResourceName),
+				Decision:       string(r.EvalDecision),
+				MissingContext: r.MissingContextValues,
+			})
+		}
+		if !out.IsTruncated {
+			return
+		}
+		input.Marker = out.Marker
+	}
+}
+
+// CheckPermissions checks that the user, group or role is allowed to
+// execute the actions on the resources.
+//
+// Parameters:
+//   - principalArn: The ARN of the user, group or role.
+//   - actions: The actions, for example "s3:GetObject".
+//   - resources: The resource ARNs. Empty for all resources.
+//
+// Returns:
+//   - err: An error which wraps ErrAccessDenied and lists the denied actions
+//     if some of the actions are not allowed, or an error if the operation
+//     fails.
+func (a awsIAM) CheckPermissions(principalArn string, actions []string,
+	resources ...string) (err error) {
+
+	decisions, err := a.SimulatePrincipalPolicy(principalArn, actions,
+		resources...)
+	if err != nil {
+		return
+	}
+	var denied []string
+	for _, d := range decisions {
+		if !d.Allowed() {
+			denied = append(denied, fmt.Sprintf("%s on %s: %s", d.Action,
+				d.Resource, d.Decision))
+		}
+	}
+	if len(denied) > 0 {
+		err = fmt.Errorf("%w: %s: %s", ErrAccessDenied, principalArn,
+			strings.Join(denied, ", "))
+	}
+	return
+}
+
+// iamRole returns IAMRole from the IAM role.
+func iamRole(r *types.Role) (role IAMRole) {
+	if r == nil {
+		return
+	}
+	role = IAMRole{
+		Name:        aws.ToString(r.RoleName),
+		ARN:         aws.ToString(r.Arn),
+		ID:          aws.ToString(r.RoleId),
+		Path:        aws.ToString(r.Path),
+		Description: aws.ToString(r.Description),
+		MaxSessionDuration: time.Duration(aws.ToInt32(r.MaxSessionDuration)) *
+			time.Second,
+		CreatedAt: aws.ToTime(r.CreateDate),
+	}
+
+	// The trust policy is URL encoded
+	policy := aws.ToString(r.AssumeRolePolicyDocument)
+	if s, err := url.QueryUnescape(policy); err == nil {
+		policy = s
+	}
+	role.AssumeRolePolicy = policy
+	return
+}
@@ -0,0 +1,86 @@
+package aws
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// TestIAMRoles checks IAM roles pagination and trust policy decoding
+func TestIAMRoles(t *testing.T) {
+
+	client := &pagesHTTPClient{bodies: []string{
+		`<ListRolesResponse><ListRolesResult><IsTruncated>true</IsTruncated>` +
+			`<Marker>m1</Marker><Roles><member><RoleName>app</RoleName>` +
+			`<Arn>arn:aws:iam::1:role/app</Arn><RoleId>R1</RoleId>` +
+			`<Path>/</Path><CreateDate>2024-01-01T00:00:00Z</CreateDate>` +
+			`<AssumeRolePolicyDocument>%7B%22Version%22%3A%222012-10-17%22%7D` +
+			`</AssumeRolePolicyDocument>` +
+			`<MaxSessionDuration>3600</MaxSessionDuration></member></Roles>` +
+			`</ListRolesResult></ListRolesResponse>`,
+		`<ListRolesResponse><ListRolesResult><IsTruncated>false</IsTruncated>` +
+			`<Roles><member><RoleName>job</RoleName>` +
+			`<Arn>arn:aws:iam::1:role/job</Arn><RoleId>R2</RoleId>` +
+			`<Path>/</Path><CreateDate>2024-01-01T00:00:00Z</CreateDate>` +
+			`</member></Roles></ListRolesResult></ListRolesResponse>`,
+	}}
+	a := newPagesTestAws(client)
+
+	roles, err := a.IAM.ListRoles("")
+	if err != nil || len(roles) != 2 || roles[1].Name != "job" {
+		t.Fatal("wrong roles:", roles, err)
+	}
+	if roles[0].AssumeRolePolicy != `{"Version":"2012-10-17"}` ||
+		roles[0].MaxSessionDuration.Hours() != 1 {
+		t.Error("wrong role:", roles[0])
+	}
+	values, _ := url.ParseQuery(client.requests[1])
+	if values.Get("Marker") != "m1" {
+		t.Error("wrong next page request:", values)
+	}
+}
+
+// TestIAMCheckPermissions checks IAM policy simulation denied actions
+func TestIAMCheckPermissions(t *testing.T) {
+
+	const role = "arn:aws:iam::1:role/app"
+	client := &pagesHTTPClient{bodies: []string{
+		`<SimulatePrincipalPolicyResponse><SimulatePrincipalPolicyResult>` +
+			`<IsTruncated>false</IsTruncated><EvaluationResults>` +
+			`<member><EvalActionName>s3:GetObject</EvalActionName>` +
+			`<EvalResourceName>arn:aws:s3:::b/*</EvalResourceName>` +
+			`<EvalDecision>allowed</EvalDecision></member>` +
+			`<member><EvalActionName>s3:DeleteObject</EvalActionName>` +
+			`<EvalResourceName>arn:aws:s3:::b/*</EvalResourceName>` +
+			`<EvalDecision>implicitDeny</EvalDecision></member>` +
+			`</EvaluationResults></SimulatePrincipalPolicyResult>` +
+			`</SimulatePrincipalPolicyResponse>`,
+	}}
+	a := newPagesTestAws(client)
+
+	err := a.IAM.CheckPermissions(role,
+		[]string{"s3:GetObject", "s3:DeleteObject"}, "arn:aws:s3:::b/*")
+	if !errors.Is(err, ErrAccessDenied) ||
+		!strings.Contains(err.Error(), "s3:DeleteObject") ||
+		strings.Contains(err.Error(), "s3:GetObject") {
+		t.Error("wrong check permissions error:", err)
+	}
+	values, _ := url.ParseQuery(client.requests[0])
+	if values.Get("PolicySourceArn") != role ||
+		values.Get("ActionNames.member.2") != "s3:DeleteObject" {
+		t.Error("wrong simulate request:", values)
+	}
+
+	// Role not found
+	a = newErrorTestAws(http.StatusNotFound, `<ErrorResponse><Error>`+
+		`<Code>NoSuchEntity</Code><Message>no role</Message></Error>`+
+		`</ErrorResponse>`)
+	_, err = a.IAM.GetRole("app")
+	var opErr *OpError
+	if !errors.Is(err, ErrNotFound) || !errors.As(err, &opErr) ||
+		opErr.Resource != "app" {
+		t.Error("wrong get role error:", err)
+	}
+}
@@ -0,0 +1,273 @@
+package aws
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kinesis"
+	"github.com/aws/aws-sdk-go-v2/service/kinesis/types"
+)
+
+// ErrKinesisRecordTooLarge is returned by Kinesis PutRecords for the record
+// which is larger than the Kinesis record size limit.
+var ErrKinesisRecordTooLarge = errors.New("kinesis record is too large")
+
+const (
+	// kinesisBatchSize is the maximum number of records in one PutRecords
+	// request.
+	kinesisBatchSize = 500
+
+	// kinesisBatchBytes is the maximum total size of the records in one
+	// PutRecords request.
+	kinesisBatchBytes = 5 * 1024 * 1024
+
+	// kinesisRecordBytes is the maximum size of the record data and
+	// partition key.
+	kinesisRecordBytes = 1024 * 1024
+
+	// kinesisBatchRetries is the number of retries of the failed records.
+	kinesisBatchRetries = 3
+
+	// kinesisBatchDelay is the first delay before the retry of failed
+	// records, the delay is doubled on each retry.
+	kinesisBatchDelay = 100 * time.Millisecond
+)
+
+// awsKinesis is the AWS Kinesis Data Streams client struct.
+type awsKinesis struct {
+	// ctx is the context.Context for AWS requests
+	ctx context.Context
+
+	// Client is the AWS Kinesis client
+	Client *kinesis.Client
+}
+
+// KinesisRecord is the record put to the Kinesis stream.
+type KinesisRecord struct {
+	// PartitionKey is the record partition key which selects the shard. The
+	// records with the same key are read in order. Default is random key.
+	PartitionKey string
+
+	// Data is the record data. The string and []byte are sent as is, other
+	// values are marshaled to JSON.
+	Data any
+}
+
+// entry returns the PutRecords request entry and its size.
+func (r KinesisRecord) entry() (entry types.PutRecordsRequestEntry, size int,
+	err error) {
+
+	data, err := messageBody(r.Data)
+	if err != nil {
+		return
+	}
+	entry = types.PutRecordsRequestEntry{
+		PartitionKey: aws.String(kinesisPartitionKey(r.PartitionKey)),
+		Data:         []byte(data),
+	}
+	size = len(data) + len(*entry.PartitionKey)
+	return
+}
+
+// KinesisEvent is the record read from the Kinesis stream.
+type KinesisEvent struct {
+	// ShardID is the stream shard ID.
+	ShardID string
+
+	// SequenceNumber is the record sequence number in the shard.
+	SequenceNumber string
+
+	// PartitionKey is the record partition key.
+	PartitionKey string
+
+	// Data is the record data.
+	Data []byte
+
+	// ArrivedAt is the approximate time the record was added to the stream.
+	ArrivedAt time.Time
+}
+
+// Unmarshal unmarshals the JSON record data into v.
+func (e KinesisEvent) Unmarshal(v any) error {
+	return json.Unmarshal(e.Data, v)
+}
+
+// PutRecord puts the record to the stream.
+//
+// Parameters:
+//   - stream: The stream name or ARN.
+//   - partitionKey: The record partition key. Empty for random key.
+//   - data: The record data. The string and []byte are sent as is, other
+//     values are marshaled to JSON.
+//
+// Returns:
+//   - shardID: The shard ID of the record.
+//   - sequenceNumber: The record sequence number in the shard.
+//   - err: An error if the operation fails.
+func (a awsKinesis) PutRecord(stream, partitionKey string, data any) (
+	shardID, sequenceNumber string, err error) {
+
+	body, err := messageBody(data)
+	if err != nil {
+		return
+	}
+	name, arn := kinesisStream(stream)
+	out, err := a.Client.PutRecord(a.ctx, &kinesis.PutRecordInput{
+		StreamName:   name,
+		StreamARN:    arn,
+		PartitionKey: aws.String(kinesisPartitionKey(partitionKey)),
+		Data:         []byte(body),
+	})
+	if err != nil {
+		return
+	}
+	shardID = aws.ToString(out.ShardId)
+	sequenceNumber = aws.ToString(out.SequenceNumber)
+	return
+}
+
+// PutRecords puts the records to the stream. The records are split to
+// batches of up to 500 records and 5 MB, the records failed with the
+// retryable errors are retried with backoff.
+//
+// Parameters:
+//   - stream: The stream name or ARN.
+//   - records: The records.
+//
+// Returns:
+//   - err: The *BatchError if some of the records failed. The Item field of
+//     the failed record contains its index in records.
+func (a awsKinesis) PutRecords(stream string, records []KinesisRecord) (
+	err error) {
+
+	// Create entries and split them to batches
+	var batchErr BatchError
+	var batches [][]types.PutRecordsRequestEntry
+	var batch []types.PutRecordsRequestEntry
+	var ids []string
+	var batchIDs [][]string
+	size := 0
+	for i, record := range records {
+		id := strconv.Itoa(i)
+		entry, entrySize, err := record.entry()
+		switch {
+		case err != nil:
+			batchErr.add(id, err)
+			continue
+		case entrySize > kinesisRecordBytes:
+			batchErr.add(id, ErrKinesisRecordTooLarge)
+			continue
+		}
+		if len(batch) == kinesisBatchSize ||
+			size+entrySize > kinesisBatchBytes {
+			batches, batchIDs = append(batches, batch), append(batchIDs, ids)
+			batch, ids, size = nil, nil, 0
+		}
+		batch, ids = append(batch, entry), append(ids, id)
+		size += entrySize
+	}
+	if len(batch) > 0 {
+		batches, batchIDs = append(batches, batch), append(batchIDs, ids)
+	}
+
+	for i, batch := range batches {
+		a.putBatch(stream, batch, batchIDs[i], &batchErr)
+	}
+	err = batchErr.err()
+
+	return
+}
+
+// putBatch puts the batch of records and retries the records failed with the
+// retryable errors. The results of all records are added to the batch error.
+// The ids are the names of the entries in the batch error.
+func (a awsKinesis) putBatch(stream string,
+	entries []types.PutRecordsRequestEntry, ids []string,
+	batchErr *BatchError) {
+
+	name, arn := kinesisStream(stream)
+	var lastErr error
+	delay := kinesisBatchDelay
+	for retry := 0; len(entries) > 0; retry++ {
+
+		// Fail records when retries are over
+		if retry > kinesisBatchRetries {
+			for _, id := range ids {
+				batchErr.add(id, lastErr)
+			}
+			return
+		}
+
+		// Wait before retry
+		if retry > 0 {
+			sleep(clock(a.ctx), delay)
+			delay *= 2
+		}
+
+		// Put records, all records fail on not retryable request error
+		out, err := a.Client.PutRecords(a.ctx, &kinesis.PutRecordsInput{
+			StreamName: name,
+			StreamARN:  arn,
+			Records:    entries,
+		})
+		if err != nil {
+			lastErr = err
+			if IsRetryable(err) {
+				continue
+			}
+			for _, id := range ids {
+				batchErr.add(id, err)
+			}
+			return
+		}
+
+		// Keep records failed by throttling or service fault for retry. The
+		// results are in order of the request entries
+		var next []types.PutRecordsRequestEntry
+		var nextIDs []string
+		for i, r := range out.Records {
+			if i >= len(entries) {
+				break
+			}
+			if r.ErrorCode == nil {
+				batchErr.add(ids[i], nil)
+				continue
+			}
+			err := entryError(aws.ToString(r.ErrorCode),
+				aws.ToString(r.ErrorMessage))
+			if !IsRetryable(err) && err.Code() != "InternalFailure" {
+				batchErr.add(ids[i], err)
+				continue
+			}
+			lastErr = err
+			next, nextIDs = append(next, entries[i]), append(nextIDs, ids[i])
+		}
+		entries, ids = next, nextIDs
+	}
+}
+
+// kinesisStream returns the stream name or ARN input parameter.
+func kinesisStream(stream string) (name, arn *string) {
+	if strings.HasPrefix(stream, "arn:") {
+		return nil, aws.String(stream)
+	}
+	return aws.String(stream), nil
+}
+
+// kinesisPartitionKey returns the partition key or the random key if it is
+// empty.
+func kinesisPartitionKey(key string) string {
+	if key != "" {
+		return key
+	}
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,276 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kinesis"
+	"github.com/aws/aws-sdk-go-v2/service/kinesis/types"
+)
+
+// kinesisConsumePoll is the default delay between the stream reads when all
+// shards records are read.
+const kinesisConsumePoll = time.Second
+
+// KinesisCheckpointStore saves the sequence numbers of the last handled
+// records of the stream shards. It has the same methods as the
+// DynamoCheckpointStore, so one store may keep the checkpoints of both
+// streams.
+type KinesisCheckpointStore interface {
+	// Checkpoint returns the sequence number of the last handled record of
+	// the shard, or empty string if the shard was not read.
+	Checkpoint(stream, shardID string) (sequenceNumber string, err error)
+
+	// SetCheckpoint saves the sequence number of the last handled record of
+	// the shard.
+	SetCheckpoint(stream, shardID, sequenceNumber string) error
+}
+
+// KinesisMemoryCheckpoints is the in-memory KinesisCheckpointStore. The
+// checkpoints are lost when the program exits.
+type KinesisMemoryCheckpoints = DynamoMemoryCheckpoints
+
+// KinesisConsumeOptions are the optional parameters of the Kinesis Consume.
+type KinesisConsumeOptions struct {
+	// Latest reads the shards without checkpoint from the latest record
+	// instead of the oldest one.
+	Latest bool
+
+	// Poll is the delay between the stream reads when all shards records
+	// are read. Default is 1 second.
+	Poll time.Duration
+}
+
+// kinesisShard is the state of the stream shard read by Consume.
+type kinesisShard struct {
+	// parents are the parent shard IDs
+	parents []string
+
+	// iterator is the next shard iterator, empty if not requested yet or
+	// expired
+	iterator string
+
+	// last is the sequence number of the last handled record
+	last string
+
+	// latest is true when the shard without checkpoint is read from the
+	// latest record
+	latest bool
+
+	// done is true when all records of the closed shard are read
+	done bool
+}
+
+// Consume reads the Kinesis stream and calls handler for each record. The
+// shards are discovered while the stream is read, so the resharding is
+// handled: the records of the parent shards are handled before the records
+// of their children. The sequence number of each handled record is saved to
+// the store, so the consuming continues after the last handled record when
+// Consume is restarted. The expired shard iterators are renewed after the
+// last handled record.
+//
+// Parameters:
+//   - ctx: The context to stop consuming.
+//   - stream: The stream name or ARN.
+//   - store: The checkpoint store, for example &KinesisMemoryCheckpoints{}.
+//   - handler: The function called for each record. The consuming stops if
+//     it returns an error, the record is not checkpointed and is delivered
+//     again after restart.
+//   - opts: The optional consume parameters.
+//
+// Returns:
+//   - err: The handler, store or not retryable stream read error. Nil is
+//     returned when ctx is canceled.
+func (a awsKinesis) Consume(ctx context.Context, stream string,
+	store KinesisCheckpointStore, handler func(event KinesisEvent) error,
+	opts ...KinesisConsumeOptions) (err error) {
+
+	var o KinesisConsumeOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	if o.Poll <= 0 {
+		o.Poll = kinesisConsumePoll
+	}
+
+	shards := make(map[string]*kinesisShard)
+	for ctx.Err() == nil {
+
+		// Discover new shards and read shards whose parents are read
+		err = a.discoverShards(ctx, stream, shards, o.Latest)
+		for _, id := range slices.Sorted(maps.Keys(shards)) {
+			if err != nil {
+				break
+			}
+			shard := shards[id]
+			if !shard.done && kinesisParentsDone(shard, shards) {
+				err = a.readShard(ctx, stream, id, shard, store, handler)
+			}
+		}
+		if err != nil && !IsRetryable(err) {
+			break
+		}
+
+		// Wait for new records
+		select {
+		case <-ctx.Done():
+		case <-clock(a.ctx).After(max(o.Poll, RetryAfter(err))):
+		}
+	}
+	if ctx.Err() != nil {
+		err = nil
+	}
+
+	return
+}
+
+// discoverShards adds the new stream shards to the shards map. The shards
+// of the first discovery are read from the latest record if latest is set,
+// the shards created later by resharding are read from the oldest record.
+func (a awsKinesis) discoverShards(ctx context.Context, stream string,
+	shards map[string]*kinesisShard, latest bool) (err error) {
+
+	latest = latest && len(shards) == 0
+	name, arn := kinesisStream(stream)
+	input := &kinesis.ListShardsInput{StreamName: name, StreamARN: arn}
+	for {
+		var out *kinesis.ListShardsOutput
+		out, err = a.Client.ListShards(ctx, input)
+		if err != nil {
+			return
+		}
+		for _, s := range out.Shards {
+			id := aws.ToString(s.ShardId)
+			if _, ok := shards[id]; ok {
+				continue
+			}
+			shard := &kinesisShard{latest: latest}
+			for _, parent := range []*string{s.ParentShardId,
+				s.AdjacentParentShardId} {
+				if parent != nil {
+					shard.parents = append(shard.parents, *parent)
+				}
+			}
+			shards[id] = shard
+		}
+		if out.NextToken == nil {
+			return
+		}
+
+		// The stream can't be set with the next token
+		input = &kinesis.ListShardsInput{NextToken: out.NextToken}
+	}
+}
+
+// kinesisParentsDone returns true if all parents of the shard are read or
+// expired.
+func kinesisParentsDone(shard *kinesisShard,
+	shards map[string]*kinesisShard) bool {
+
+	for _, id := range shard.parents {
+		if parent, ok := shards[id]; ok && !parent.done {
+			return false
+		}
+	}
+	return true
+}
+
+// readShard reads the shard records until the end of the shard or the last
+// available record.
+func (a awsKinesis) readShard(ctx context.Context, stream, id string,
+	shard *kinesisShard, store KinesisCheckpointStore,
+	handler func(event KinesisEvent) error) (err error) {
+
+	for ctx.Err() == nil {
+
+		// Get shard iterator after the last handled record
+		if shard.iterator == "" {
+			err = a.shardIterator(ctx, stream, id, shard, store)
+			if err != nil {
+				return
+			}
+		}
+
+		var out *kinesis.GetRecordsOutput
+		out, err = a.Client.GetRecords(ctx, &kinesis.GetRecordsInput{
+			ShardIterator: aws.String(shard.iterator),
+		})
+		var e *Error
+		if errors.As(err, &e) && e.Code() == "ExpiredIteratorException" {
+			shard.iterator = ""
+			continue
+		}
+		if err != nil {
+			return
+		}
+
+		// Handle records and save checkpoints
+		for _, r := range out.Records {
+			event := KinesisEvent{
+				ShardID:        id,
+				SequenceNumber: aws.ToString(r.SequenceNumber),
+				PartitionKey:   aws.ToString(r.PartitionKey),
+				Data:           r.Data,
+				ArrivedAt:      aws.ToTime(r.ApproximateArrivalTimestamp),
+			}
+			if err = handler(event); err != nil {
+				return
+			}
+			err = store.SetCheckpoint(stream, id, event.SequenceNumber)
+			if err != nil {
+				return
+			}
+			shard.last = event.SequenceNumber
+		}
+
+		// The shard is closed and all its records are read
+		if out.NextShardIterator == nil {
+			shard.done = true
+			return
+		}
+		shard.iterator = aws.ToString(out.NextShardIterator)
+
+		// All available records are read
+		if aws.ToInt64(out.MillisBehindLatest) == 0 || len(out.Records) == 0 {
+			return
+		}
+	}
+
+	return
+}
+
+// shardIterator gets the shard iterator after the last handled record, the
+// checkpoint or at the start position of the shard.
+func (a awsKinesis) shardIterator(ctx context.Context, stream, id string,
+	shard *kinesisShard, store KinesisCheckpointStore) (err error) {
+
+	if shard.last == "" {
+		if shard.last, err = store.Checkpoint(stream, id); err != nil {
+			return
+		}
+	}
+	name, arn := kinesisStream(stream)
+	input := &kinesis.GetShardIteratorInput{
+		StreamName:        name,
+		StreamARN:         arn,
+		ShardId:           aws.String(id),
+		ShardIteratorType: types.ShardIteratorTypeTrimHorizon,
+	}
+	switch {
+	case shard.last != "":
+		input.ShardIteratorType = types.ShardIteratorTypeAfterSequenceNumber
+		input.StartingSequenceNumber = aws.String(shard.last)
+	case shard.latest:
+		input.ShardIteratorType = types.ShardIteratorTypeLatest
+	}
+	out, err := a.Client.GetShardIterator(ctx, input)
+	if err != nil {
+		return
+	}
+	shard.iterator = aws.ToString(out.ShardIterator)
+	return
+}
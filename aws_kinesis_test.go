@@ -0,0 +1,90 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// TestKinesisPutRecords checks Kinesis partition keys and failed records retry
+func TestKinesisPutRecords(t *testing.T) {
+
+	client := &pagesHTTPClient{bodies: []string{
+		`{"FailedRecordCount":1,"Records":[{"SequenceNumber":"1",` +
+			`"ShardId":"s0"},{"ErrorCode":` +
+			`"ProvisionedThroughputExceededException","ErrorMessage":"slow"},` +
+			`{"SequenceNumber":"2","ShardId":"s0"}]}`,
+		`{"FailedRecordCount":0,"Records":[{"SequenceNumber":"3",` +
+			`"ShardId":"s0"}]}`,
+	}}
+	a := newPagesTestAws(client)
+
+	err := a.Kinesis.PutRecords("clicks", []KinesisRecord{
+		{PartitionKey: "u1", Data: map[string]string{"page": "/"}},
+		{Data: "raw"},
+		{PartitionKey: "u2", Data: []byte("bytes")},
+	})
+	if err != nil || len(client.requests) != 2 {
+		t.Fatal("wrong put records result:", err, len(client.requests))
+	}
+	var input struct {
+		StreamName string
+		Records    []struct {
+			PartitionKey string
+			Data         []byte
+		}
+	}
+	json.Unmarshal([]byte(client.requests[1]), &input)
+	if input.StreamName != "clicks" || len(input.Records) != 1 ||
+		string(input.Records[0].Data) != "raw" ||
+		len(input.Records[0].PartitionKey) != 32 {
+		t.Error("wrong retry request:", client.requests[1])
+	}
+}
+
+// TestKinesisConsume checks Kinesis resharding, checkpoints and expired
+// iterators
+func TestKinesisConsume(t *testing.T) {
+
+	ok := http.StatusOK
+	client := &pagesHTTPClient{bodies: []string{
+		`{"Shards":[{"ShardId":"shard-0"},` +
+			`{"ShardId":"shard-1","ParentShardId":"shard-0"}]}`,
+		`{"ShardIterator":"it0"}`,
+		`{"Records":[{"SequenceNumber":"s1","PartitionKey":"u1",` +
+			`"Data":"YQ=="}],"MillisBehindLatest":0}`,
+		`{"ShardIterator":"it1"}`,
+		`{"__type":"ExpiredIteratorException","message":"expired"}`,
+		`{"ShardIterator":"it2"}`,
+		`{"Records":[{"SequenceNumber":"s2","PartitionKey":"u2",` +
+			`"Data":"Yg=="}],"NextShardIterator":"it3",` +
+			`"MillisBehindLatest":0}`,
+	}, statuses: []int{ok, ok, ok, ok, http.StatusBadRequest}}
+	a := newPagesTestAws(client)
+
+	store := &KinesisMemoryCheckpoints{}
+	store.SetCheckpoint("clicks", "shard-0", "s0")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var data []string
+	err := a.Kinesis.Consume(ctx, "clicks", store, func(e KinesisEvent) error {
+		data = append(data, e.ShardID+":"+string(e.Data))
+		if e.SequenceNumber == "s2" {
+			cancel()
+		}
+		return nil
+	})
+	if err != nil || strings.Join(data, ",") != "shard-0:a,shard-1:b" {
+		t.Fatal("wrong consumed records:", data, err)
+	}
+	if !strings.Contains(client.requests[1], `"StartingSequenceNumber":"s0"`) ||
+		!strings.Contains(client.requests[5], `"TRIM_HORIZON"`) {
+		t.Error("wrong shard iterator requests:", client.requests[1],
+			client.requests[5])
+	}
+	if seq, _ := store.Checkpoint("clicks", "shard-1"); seq != "s2" {
+		t.Error("wrong checkpoint:", seq)
+	}
+}
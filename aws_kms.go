@@ -0,0 +1,146 @@
+package aws
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+)
+
+// ErrKMSInvalidCiphertext is returned by KMS decrypt functions when the
+// ciphertext is damaged, was encrypted by other key or with other encryption
+// context.
+var ErrKMSInvalidCiphertext = errors.New("kms ciphertext is not valid")
+
+// awsKMS is the AWS KMS client struct.
+type awsKMS struct {
+	// ctx is the context.Context for AWS requests
+	ctx context.Context
+
+	// Client is the AWS KMS client
+	Client *kms.Client
+}
+
+// Encrypt encrypts the data up to 4 KB by the KMS key.
+//
+// Parameters:
+//   - keyID: The key ID, ARN or alias, for example "alias/tokens".
+//   - data: The data to encrypt.
+//   - encryptionContext: The optional encryption context. The same context
+//     is required to decrypt the data.
+//
+// Returns:
+//   - ciphertext: The encrypted data.
+//   - err: An error if the operation fails. The error wraps ErrNotFound if
+//     the key does not exist.
+func (a awsKMS) Encrypt(keyID string, data []byte,
+	encryptionContext map[string]string) (ciphertext []byte, err error) {
+
+	out, err := a.Client.Encrypt(a.ctx, &kms.EncryptInput{
+		KeyId:             aws.String(keyID),
+		Plaintext:         data,
+		EncryptionContext: encryptionContext,
+	})
+	if err != nil {
+		return
+	}
+	ciphertext = out.CiphertextBlob
+	return
+}
+
+// Decrypt decrypts the data encrypted by Encrypt.
+//
+// Parameters:
+//   - keyID: The key ID, ARN or alias. May be empty for symmetric keys,
+//     the key is taken from the ciphertext.
+//   - ciphertext: The encrypted data.
+//   - encryptionContext: The encryption context used to encrypt the data.
+//
+// Returns:
+//   - data: The decrypted data.
+//   - err: An error if the operation fails. The error wraps
+//     ErrKMSInvalidCiphertext if the ciphertext or encryption context is not
+//     valid.
+func (a awsKMS) Decrypt(keyID string, ciphertext []byte,
+	encryptionContext map[string]string) (data []byte, err error) {
+
+	out, err := a.Client.Decrypt(a.ctx, &kms.DecryptInput{
+		KeyId:             optional(keyID),
+		CiphertextBlob:    ciphertext,
+		EncryptionContext: encryptionContext,
+	})
+	if err != nil {
+		err = kmsError(err)
+		return
+	}
+	data = out.Plaintext
+	return
+}
+
+// EncryptString encrypts the string by the KMS key and returns the base64
+// encoded ciphertext which may be saved as a string, for example to DynamoDB
+// or JSON.
+//
+// Parameters:
+//   - keyID: The key ID, ARN or alias, for example "alias/tokens".
+//   - s: The string to encrypt.
+//   - encryptionContext: The optional encryption context.
+//
+// Returns:
+//   - ciphertext: The base64 encoded encrypted string.
+//   - err: An error if the operation fails.
+func (a awsKMS) EncryptString(keyID, s string,
+	encryptionContext map[string]string) (ciphertext string, err error) {
+
+	data, err := a.Encrypt(keyID, []byte(s), encryptionContext)
+	if err != nil {
+		return
+	}
+	ciphertext = base64.StdEncoding.EncodeToString(data)
+	return
+}
+
+// DecryptString decrypts the base64 encoded ciphertext returned by
+// EncryptString.
+//
+// Parameters:
+//   - keyID: The key ID, ARN or alias. May be empty for symmetric keys.
+//   - ciphertext: The base64 encoded encrypted string.
+//   - encryptionContext: The encryption context used to encrypt the string.
+//
+// Returns:
+//   - s: The decrypted string.
+//   - err: An error if the operation fails. The error wraps
+//     ErrKMSInvalidCiphertext if the ciphertext or encryption context is not
+//     valid.
+func (a awsKMS) DecryptString(keyID, ciphertext string,
+	encryptionContext map[string]string) (s string, err error) {
+
+	data, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		err = fmt.Errorf("%w: %w", ErrKMSInvalidCiphertext, err)
+		return
+	}
+	data, err = a.Decrypt(keyID, data, encryptionContext)
+	if err != nil {
+		return
+	}
+	s = string(data)
+	return
+}
+
+// kmsError wraps the KMS decrypt error with ErrKMSInvalidCiphertext.
+func kmsError(err error) error {
+	var e *Error
+	if !errors.As(err, &e) {
+		return err
+	}
+	switch e.Code() {
+	case "InvalidCiphertextException", "IncorrectKeyException":
+		return fmt.Errorf("%w: %w", ErrKMSInvalidCiphertext, err)
+	}
+	return err
+}
@@ -0,0 +1,153 @@
+package aws
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"slices"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+)
+
+// kmsEnvelopeMagic starts the envelope created by EncryptLarge.
+const kmsEnvelopeMagic = "KMSE"
+
+// kmsEnvelopeVersion is the envelope format version.
+const kmsEnvelopeVersion = 1
+
+// kmsEnvelopeHeader is the length of the envelope header: magic, version
+// and wrapped key length.
+const kmsEnvelopeHeader = len(kmsEnvelopeMagic) + 1 + 2
+
+// EncryptLarge encrypts the data of any size by the envelope encryption. The
+// data is encrypted locally by AES-256-GCM with the new data key generated
+// by the KMS key, the data key is saved in the envelope encrypted by the KMS
+// key. The envelope format is:
+//
+//	"KMSE" | version (1 byte) | wrapped key length (2 bytes, big endian) |
+//	wrapped key | nonce (12 bytes) | ciphertext and GCM tag
+//
+// The envelope header and wrapped key are authenticated by GCM.
+//
+// Parameters:
+//   - keyID: The key ID, ARN or alias, for example "alias/data".
+//   - data: The data to encrypt.
+//   - encryptionContext: The optional encryption context. The same context
+//     is required to decrypt the data.
+//
+// Returns:
+//   - envelope: The envelope with the encrypted data.
+//   - err: An error if the operation fails. The error wraps ErrNotFound if
+//     the key does not exist.
+func (a awsKMS) EncryptLarge(keyID string, data []byte,
+	encryptionContext map[string]string) (envelope []byte, err error) {
+
+	// Generate data key
+	out, err := a.Client.GenerateDataKey(a.ctx, &kms.GenerateDataKeyInput{
+		KeyId:             aws.String(keyID),
+		KeySpec:           types.DataKeySpecAes256,
+		EncryptionContext: encryptionContext,
+	})
+	if err != nil {
+		return
+	}
+	defer clear(out.Plaintext)
+	gcm, err := kmsGCM(out.Plaintext)
+	if err != nil {
+		return
+	}
+
+	// Create envelope header
+	wrapped := out.CiphertextBlob
+	size := kmsEnvelopeHeader + len(wrapped) + gcm.NonceSize() + len(data) +
+		gcm.Overhead()
+	envelope = make([]byte, 0, size)
+	envelope = append(envelope, kmsEnvelopeMagic...)
+	envelope = append(envelope, kmsEnvelopeVersion)
+	envelope = binary.BigEndian.AppendUint16(envelope, uint16(len(wrapped)))
+	envelope = append(envelope, wrapped...)
+	header := slices.Clone(envelope)
+
+	// Encrypt data
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	envelope = append(envelope, nonce...)
+	envelope = gcm.Seal(envelope, nonce, data, header)
+
+	return
+}
+
+// DecryptLarge decrypts the envelope created by EncryptLarge.
+//
+// Parameters:
+//   - keyID: The key ID, ARN or alias. May be empty, the key is taken from
+//     the wrapped data key.
+//   - envelope: The envelope with the encrypted data.
+//   - encryptionContext: The encryption context used to encrypt the data.
+//
+// Returns:
+//   - data: The decrypted data.
+//   - err: An error if the operation fails. The error wraps
+//     ErrKMSInvalidCiphertext if the envelope or encryption context is not
+//     valid.
+func (a awsKMS) DecryptLarge(keyID string, envelope []byte,
+	encryptionContext map[string]string) (data []byte, err error) {
+
+	// Parse envelope header
+	if len(envelope) < kmsEnvelopeHeader ||
+		string(envelope[:len(kmsEnvelopeMagic)]) != kmsEnvelopeMagic {
+		err = fmt.Errorf("%w: not an envelope", ErrKMSInvalidCiphertext)
+		return
+	}
+	if v := envelope[len(kmsEnvelopeMagic)]; v != kmsEnvelopeVersion {
+		err = fmt.Errorf("%w: unknown envelope version %d",
+			ErrKMSInvalidCiphertext, v)
+		return
+	}
+	keyEnd := kmsEnvelopeHeader + int(binary.BigEndian.Uint16(
+		envelope[kmsEnvelopeHeader-2:]))
+	if len(envelope) < keyEnd {
+		err = fmt.Errorf("%w: envelope is truncated", ErrKMSInvalidCiphertext)
+		return
+	}
+
+	// Decrypt data key
+	key, err := a.Decrypt(keyID, envelope[kmsEnvelopeHeader:keyEnd],
+		encryptionContext)
+	if err != nil {
+		return
+	}
+	defer clear(key)
+	gcm, err := kmsGCM(key)
+	if err != nil {
+		return
+	}
+
+	// Decrypt data
+	if len(envelope) < keyEnd+gcm.NonceSize()+gcm.Overhead() {
+		err = fmt.Errorf("%w: envelope is truncated", ErrKMSInvalidCiphertext)
+		return
+	}
+	nonceEnd := keyEnd + gcm.NonceSize()
+	data, err = gcm.Open(nil, envelope[keyEnd:nonceEnd], envelope[nonceEnd:],
+		envelope[:keyEnd])
+	if err != nil {
+		err = fmt.Errorf("%w: %w", ErrKMSInvalidCiphertext, err)
+	}
+	return
+}
+
+// kmsGCM creates the AES-GCM cipher of the data key.
+func kmsGCM(key []byte) (gcm cipher.AEAD, err error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,222 @@
+package aws
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+)
+
+// ErrKMSInvalidSignature is returned by KMS Verify and KMSPublicKey Verify
+// when the signature is not valid.
+var ErrKMSInvalidSignature = errors.New("kms signature is not valid")
+
+// kmsMaxMessage is the maximum size of the message signed by KMS, the larger
+// messages are hashed locally and their digests are signed.
+const kmsMaxMessage = 4096
+
+// KMSPublicKey is the public key of the KMS asymmetric key.
+type KMSPublicKey struct {
+	// KeyID is the key ARN.
+	KeyID string
+
+	// Key is the parsed public key, *rsa.PublicKey or *ecdsa.PublicKey.
+	Key crypto.PublicKey
+
+	// DER is the DER encoded X.509 SubjectPublicKeyInfo of the key.
+	DER []byte
+
+	// KeySpec is the key type, for example "RSA_2048" or "ECC_NIST_P256".
+	KeySpec string
+
+	// SigningAlgorithms are the signing algorithms supported by the key.
+	SigningAlgorithms []string
+}
+
+// PEM returns the PEM encoded public key which may be used by other parties
+// to verify the signatures.
+func (k KMSPublicKey) PEM() []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: k.DER})
+}
+
+// Verify verifies the signature created by KMS Sign locally, without KMS
+// request.
+//
+// Parameters:
+//   - message: The signed message.
+//   - signature: The signature.
+//   - algorithm: The signing algorithm, for example "RSASSA_PSS_SHA_256" or
+//     "ECDSA_SHA_256".
+//
+// Returns:
+//   - err: An error which wraps ErrKMSInvalidSignature if the signature is
+//     not valid, or the unsupported algorithm error.
+func (k KMSPublicKey) Verify(message, signature []byte,
+	algorithm string) (err error) {
+
+	hash, err := kmsHash(algorithm)
+	if err != nil {
+		return
+	}
+	h := hash.New()
+	h.Write(message)
+	digest := h.Sum(nil)
+
+	switch key := k.Key.(type) {
+	case *rsa.PublicKey:
+		if strings.HasPrefix(algorithm, "RSASSA_PSS_") {
+			err = rsa.VerifyPSS(key, hash, digest, signature,
+				&rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		} else {
+			err = rsa.VerifyPKCS1v15(key, hash, digest, signature)
+		}
+		if err != nil {
+			err = fmt.Errorf("%w: %w", ErrKMSInvalidSignature, err)
+		}
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(key, digest, signature) {
+			err = ErrKMSInvalidSignature
+		}
+	default:
+		err = fmt.Errorf("kms public key type %T is not supported", k.Key)
+	}
+	return
+}
+
+// Sign signs the message by the KMS asymmetric key. The messages larger than
+// 4 KB are hashed locally and their digests are signed.
+//
+// Parameters:
+//   - keyID: The key ID, ARN or alias, for example "alias/webhooks".
+//   - message: The message to sign.
+//   - algorithm: The signing algorithm supported by the key, for example
+//     "RSASSA_PSS_SHA_256", "RSASSA_PKCS1_V1_5_SHA_256" or "ECDSA_SHA_256".
+//
+// Returns:
+//   - signature: The signature. The ECDSA signature is DER encoded.
+//   - err: An error if the operation fails.
+func (a awsKMS) Sign(keyID string, message []byte, algorithm string) (
+	signature []byte, err error) {
+
+	input := &kms.SignInput{
+		KeyId:            aws.String(keyID),
+		SigningAlgorithm: types.SigningAlgorithmSpec(algorithm),
+	}
+	if input.Message, input.MessageType, err = kmsMessage(message,
+		algorithm); err != nil {
+		return
+	}
+	out, err := a.Client.Sign(a.ctx, input)
+	if err != nil {
+		return
+	}
+	signature = out.Signature
+	return
+}
+
+// Verify verifies the signature by KMS. Use GetPublicKey and KMSPublicKey
+// Verify to verify signatures without KMS requests.
+//
+// Parameters:
+//   - keyID: The key ID, ARN or alias.
+//   - message: The signed message.
+//   - signature: The signature.
+//   - algorithm: The signing algorithm used to sign the message.
+//
+// Returns:
+//   - err: An error if the operation fails. The error wraps
+//     ErrKMSInvalidSignature if the signature is not valid.
+func (a awsKMS) Verify(keyID string, message, signature []byte,
+	algorithm string) (err error) {
+
+	input := &kms.VerifyInput{
+		KeyId:            aws.String(keyID),
+		Signature:        signature,
+		SigningAlgorithm: types.SigningAlgorithmSpec(algorithm),
+	}
+	if input.Message, input.MessageType, err = kmsMessage(message,
+		algorithm); err != nil {
+		return
+	}
+	out, err := a.Client.Verify(a.ctx, input)
+	var e *Error
+	switch {
+	case errors.As(err, &e) && e.Code() == "KMSInvalidSignatureException":
+		err = fmt.Errorf("%w: %w", ErrKMSInvalidSignature, err)
+	case err == nil && !out.SignatureValid:
+		err = ErrKMSInvalidSignature
+	}
+	return
+}
+
+// GetPublicKey returns the public key of the KMS asymmetric key.
+//
+// Parameters:
+//   - keyID: The key ID, ARN or alias.
+//
+// Returns:
+//   - key: The public key.
+//   - err: An error if the operation fails. The error wraps ErrNotFound if
+//     the key does not exist.
+func (a awsKMS) GetPublicKey(keyID string) (key KMSPublicKey, err error) {
+	out, err := a.Client.GetPublicKey(a.ctx, &kms.GetPublicKeyInput{
+		KeyId: aws.String(keyID),
+	})
+	if err != nil {
+		return
+	}
+	key = KMSPublicKey{
+		KeyID:   aws.ToString(out.KeyId),
+		DER:     out.PublicKey,
+		KeySpec: string(out.KeySpec),
+	}
+	for _, algorithm := range out.SigningAlgorithms {
+		key.SigningAlgorithms = append(key.SigningAlgorithms,
+			string(algorithm))
+	}
+	key.Key, err = x509.ParsePKIXPublicKey(out.PublicKey)
+	return
+}
+
+// kmsMessage returns the message and its type for the KMS Sign and Verify.
+// The messages larger than kmsMaxMessage are replaced by their digests.
+func kmsMessage(message []byte, algorithm string) (m []byte,
+	messageType types.MessageType, err error) {
+
+	if len(message) <= kmsMaxMessage {
+		return message, types.MessageTypeRaw, nil
+	}
+	hash, err := kmsHash(algorithm)
+	if err != nil {
+		return
+	}
+	h := hash.New()
+	h.Write(message)
+	return h.Sum(nil), types.MessageTypeDigest, nil
+}
+
+// kmsHash returns the hash function of the signing algorithm.
+func kmsHash(algorithm string) (hash crypto.Hash, err error) {
+	switch {
+	case strings.HasSuffix(algorithm, "_SHA_256"):
+		hash = crypto.SHA256
+	case strings.HasSuffix(algorithm, "_SHA_384"):
+		hash = crypto.SHA384
+	case strings.HasSuffix(algorithm, "_SHA_512"):
+		hash = crypto.SHA512
+	default:
+		err = fmt.Errorf("kms signing algorithm %q is not supported",
+			algorithm)
+	}
+	return
+}
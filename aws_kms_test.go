@@ -0,0 +1,153 @@
+package aws
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// TestKMSEncrypt checks KMS base64 strings and invalid ciphertext errors
+func TestKMSEncrypt(t *testing.T) {
+
+	client := &pagesHTTPClient{bodies: []string{
+		`{"CiphertextBlob":"AQID","KeyId":"k1"}`,
+		`{"Plaintext":"dG9rZW4=","KeyId":"k1"}`,
+	}}
+	a := newPagesTestAws(client)
+
+	ctx := map[string]string{"user": "u1"}
+	ciphertext, err := a.KMS.EncryptString("alias/tokens", "token", ctx)
+	if err != nil || ciphertext != "AQID" {
+		t.Fatal("wrong encrypt result:", ciphertext, err)
+	}
+	if !strings.Contains(client.requests[0], `"Plaintext":"dG9rZW4="`) ||
+		!strings.Contains(client.requests[0], `"EncryptionContext":{"user":"u1"}`) {
+		t.Error("wrong encrypt request:", client.requests[0])
+	}
+	s, err := a.KMS.DecryptString("", ciphertext, ctx)
+	if err != nil || s != "token" ||
+		!strings.Contains(client.requests[1], `"CiphertextBlob":"AQID"`) ||
+		strings.Contains(client.requests[1], `"KeyId"`) {
+		t.Error("wrong decrypt result:", s, err, client.requests[1])
+	}
+
+	// Invalid ciphertext
+	_, err = a.KMS.DecryptString("", "not base64!", nil)
+	if !errors.Is(err, ErrKMSInvalidCiphertext) {
+		t.Error("wrong base64 error:", err)
+	}
+	a = newErrorTestAws(http.StatusBadRequest,
+		`{"__type":"InvalidCiphertextException","message":""}`)
+	_, err = a.KMS.Decrypt("", []byte{1, 2, 3}, nil)
+	if !errors.Is(err, ErrKMSInvalidCiphertext) {
+		t.Error("wrong invalid ciphertext error:", err)
+	}
+}
+
+// TestKMSEnvelope checks envelope encryption of S3 objects
+func TestKMSEnvelope(t *testing.T) {
+
+	key := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{7}, 32))
+	client := &pagesHTTPClient{bodies: []string{
+		`{"CiphertextBlob":"d3JhcHBlZA==","Plaintext":"` + key + `"}`,
+		``,
+	}}
+	a := newPagesTestAws(client)
+
+	data := bytes.Repeat([]byte("regulated data "), 1000)
+	ctx := map[string]string{"dataset": "d1"}
+	err := a.S3.Set("bucket", "key", data,
+		S3Options{KMSKeyID: "alias/data", EncryptionContext: ctx})
+	if err != nil {
+		t.Fatal("set error:", err)
+	}
+	envelope := client.requests[1]
+	if !strings.HasPrefix(envelope, "KMSE\x01\x00\x07wrapped") ||
+		strings.Contains(envelope, "regulated") {
+		t.Fatal("wrong envelope:", envelope[:16])
+	}
+
+	// Get decrypts the envelope
+	client.bodies = []string{envelope, `{"Plaintext":"` + key + `"}`}
+	got, err := a.S3.Get("bucket", "key",
+		S3Options{KMSKeyID: "alias/data", EncryptionContext: ctx})
+	if err != nil || !bytes.Equal(got, data) {
+		t.Fatal("wrong decrypted data:", len(got), err)
+	}
+	if !strings.Contains(client.requests[3], `"CiphertextBlob":"d3JhcHBlZA=="`) ||
+		!strings.Contains(client.requests[3], `"EncryptionContext":{"dataset":"d1"}`) {
+		t.Error("wrong decrypt request:", client.requests[3])
+	}
+
+	// Tampered envelope
+	tampered := []byte(envelope)
+	tampered[len(tampered)-1] ^= 1
+	client.bodies = []string{`{"Plaintext":"` + key + `"}`}
+	_, err = a.KMS.DecryptLarge("", tampered, ctx)
+	if !errors.Is(err, ErrKMSInvalidCiphertext) {
+		t.Error("wrong tampered envelope error:", err)
+	}
+	_, err = a.KMS.DecryptLarge("", data, ctx)
+	if !errors.Is(err, ErrKMSInvalidCiphertext) {
+		t.Error("wrong not envelope error:", err)
+	}
+}
+
+// TestKMSSign checks KMS sign digests and local signature verification
+func TestKMSSign(t *testing.T) {
+
+	priv, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	der, _ := x509.MarshalPKIXPublicKey(&priv.PublicKey)
+	client := &pagesHTTPClient{bodies: []string{
+		`{"KeyId":"arn:k1","KeySpec":"ECC_NIST_P256","PublicKey":"` +
+			base64.StdEncoding.EncodeToString(der) +
+			`","SigningAlgorithms":["ECDSA_SHA_256"]}`,
+		`{"Signature":"AQID"}`,
+		`{"SignatureValid":false}`,
+	}}
+	a := newPagesTestAws(client)
+
+	// Public key
+	key, err := a.KMS.GetPublicKey("alias/webhooks")
+	if err != nil || key.KeyID != "arn:k1" || len(key.SigningAlgorithms) != 1 {
+		t.Fatal("wrong public key:", key, err)
+	}
+	if block, _ := pem.Decode(key.PEM()); block == nil ||
+		!bytes.Equal(block.Bytes, der) {
+		t.Error("wrong public key PEM")
+	}
+	message := []byte("manifest")
+	digest := sha256.Sum256(message)
+	signature, _ := ecdsa.SignASN1(rand.Reader, priv, digest[:])
+	if err = key.Verify(message, signature, "ECDSA_SHA_256"); err != nil {
+		t.Error("valid signature error:", err)
+	}
+	err = key.Verify([]byte("other"), signature, "ECDSA_SHA_256")
+	if !errors.Is(err, ErrKMSInvalidSignature) {
+		t.Error("wrong invalid signature error:", err)
+	}
+
+	// Large messages are signed by digest
+	large := bytes.Repeat([]byte{1}, 5000)
+	_, err = a.KMS.Sign("alias/webhooks", large, "ECDSA_SHA_256")
+	digest = sha256.Sum256(large)
+	if err != nil || !strings.Contains(client.requests[1],
+		`"Message":"`+base64.StdEncoding.EncodeToString(digest[:])+`"`) ||
+		!strings.Contains(client.requests[1], `"MessageType":"DIGEST"`) {
+		t.Error("wrong sign request:", err, client.requests[1])
+	}
+	err = a.KMS.Verify("alias/webhooks", message, []byte{1}, "ECDSA_SHA_256")
+	if !errors.Is(err, ErrKMSInvalidSignature) ||
+		!strings.Contains(client.requests[2], `"MessageType":"RAW"`) {
+		t.Error("wrong verify error:", err)
+	}
+}
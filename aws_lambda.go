@@ -4,11 +4,20 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"iter"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
 )
 
+// LambdaInvoker invokes the AWS Lambda functions. It is implemented by the
+// AWS Lambda client and by the FakeLambda test double.
+type LambdaInvoker interface {
+	Invoke(ctx context.Context, params *lambda.InvokeInput,
+		optFns ...func(*lambda.Options)) (*lambda.InvokeOutput, error)
+}
+
 // awsLambda is a struct that represents AWS Lambda client.
 // It contains context and AWS Lambda client.
 type awsLambda struct {
@@ -17,6 +26,10 @@ type awsLambda struct {
 
 	// Client represents the client for the AWS Lambda service
 	Client *lambda.Client
+
+	// Invoker invokes the functions in Get. It is the Client by default,
+	// set it to the FakeLambda in tests
+	Invoker LambdaInvoker
 }
 
 // Get executes the AWS Lambda function with the given function name and request.
@@ -36,20 +49,52 @@ func (a awsLambda) Get(funcName string, request any) (
 	payload, err := json.Marshal(request)
 	if err != nil {
 		// Return an error if the request payload cannot be marshaled
-		err = fmt.Errorf("can't marshal lambda %s request, error %s",
+		err = fmt.Errorf("can't marshal lambda %s request, error %w",
 			funcName, err)
 		return
 	}
 
 	// Execute the AWS Lambda function
-	result, err = a.Client.Invoke(a.ctx, &lambda.InvokeInput{
+	result, err = a.Invoker.Invoke(a.ctx, &lambda.InvokeInput{
 		FunctionName: aws.String(funcName), // Set the function name
 		Payload:      payload,              // Set the payload
 	})
 	if err != nil {
 		// Return an error if the function execution fails
-		err = fmt.Errorf("error calling lambda %s: %s", funcName, err)
+		err = fmt.Errorf("error calling lambda %s: %w", funcName, err)
 	}
 
 	return
 }
+
+// Functions returns iterator over the configurations of all Lambda functions
+// of the region. The next pages are requested automatically while the
+// functions are iterated.
+//
+// Returns:
+//   - iter.Seq2[types.FunctionConfiguration, error]: The functions iterator.
+//     Iteration stops after the first error.
+func (a awsLambda) Functions() iter.Seq2[types.FunctionConfiguration, error] {
+	return a.FunctionsPages().Items()
+}
+
+// FunctionsPages returns the paginator over the pages of the configurations
+// of all Lambda functions of the region.
+//
+// Returns:
+//   - p: The functions pages paginator.
+func (a awsLambda) FunctionsPages() (
+	p *Paginator[types.FunctionConfiguration]) {
+
+	input := &lambda.ListFunctionsInput{}
+	return NewPaginator(func() (functions []types.FunctionConfiguration,
+		more bool, err error) {
+
+		out, err := a.Client.ListFunctions(a.ctx, input)
+		if err != nil {
+			return
+		}
+		input.Marker = out.NextMarker
+		return out.Functions, aws.ToString(out.NextMarker) != "", nil
+	})
+}
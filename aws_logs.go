@@ -0,0 +1,205 @@
+package aws
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+)
+
+const (
+	// logsTailInterval is the default polling interval of the Logs Tail.
+	logsTailInterval = 2 * time.Second
+
+	// logsTailSince is the default time before the Logs Tail start from
+	// which the events are returned.
+	logsTailSince = time.Minute
+)
+
+// awsLogs is the AWS CloudWatch Logs client struct.
+type awsLogs struct {
+	// ctx is the context.Context for AWS requests
+	ctx context.Context
+
+	// Client is the AWS CloudWatch Logs client
+	Client *cloudwatchlogs.Client
+}
+
+// LogEvent is the CloudWatch Logs event.
+type LogEvent struct {
+	// ID is the event ID.
+	ID string
+
+	// Stream is the log stream name.
+	Stream string
+
+	// Timestamp is the event time.
+	Timestamp time.Time
+
+	// Message is the event message.
+	Message string
+}
+
+// LogsFilterOptions are the optional parameters of the Logs Filter.
+type LogsFilterOptions struct {
+	// Streams are the log stream names to search. Default is all streams of
+	// the group.
+	Streams []string
+
+	// StreamPrefix is the log streams name prefix to search. It can't be
+	// used with Streams.
+	StreamPrefix string
+
+	// Start is the start time of the events, inclusive.
+	Start time.Time
+
+	// End is the end time of the events, exclusive.
+	End time.Time
+
+	// Limit is the maximum number of returned events. Zero means no limit.
+	Limit int
+}
+
+// input creates the FilterLogEvents input.
+func (o LogsFilterOptions) input(group,
+	filterPattern string) *cloudwatchlogs.FilterLogEventsInput {
+
+	input := &cloudwatchlogs.FilterLogEventsInput{
+		LogGroupName:        aws.String(group),
+		FilterPattern:       optional(filterPattern),
+		LogStreamNames:      o.Streams,
+		LogStreamNamePrefix: optional(o.StreamPrefix),
+	}
+	if !o.Start.IsZero() {
+		input.StartTime = aws.Int64(o.Start.UnixMilli())
+	}
+	if !o.End.IsZero() {
+		input.EndTime = aws.Int64(o.End.UnixMilli())
+	}
+	return input
+}
+
+// LogsTailOptions are the optional parameters of the Logs Tail.
+type LogsTailOptions struct {
+	// LogsFilterOptions select the tailed streams. The Start is the time from
+	// which the events are returned, default is 1 minute before the Tail
+	// start. End and Limit are not used.
+	LogsFilterOptions
+
+	// Interval is the polling interval. Default is 2 seconds.
+	Interval time.Duration
+}
+
+// Filter returns the log group events matched by the filter pattern.
+//
+// Parameters:
+//   - group: The log group name.
+//   - filterPattern: The CloudWatch Logs filter pattern, for example
+//     "ERROR" or `{ $.level = "error" }`. Empty pattern matches all events.
+//   - opts: The optional filter parameters.
+//
+// Returns:
+//   - events: The events sorted by time.
+//   - err: An error if the operation fails. The error wraps ErrNotFound if
+//     the log group does not exist.
+func (a awsLogs) Filter(group, filterPattern string,
+	opts ...LogsFilterOptions) (events []LogEvent, err error) {
+
+	var o LogsFilterOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	input := o.input(group, filterPattern)
+	for {
+		var out *cloudwatchlogs.FilterLogEventsOutput
+		out, err = a.Client.FilterLogEvents(a.ctx, input)
+		if err != nil {
+			return
+		}
+		for _, e := range out.Events {
+			events = append(events, LogEvent{
+				ID:        aws.ToString(e.EventId),
+				Stream:    aws.ToString(e.LogStreamName),
+				Timestamp: time.UnixMilli(aws.ToInt64(e.Timestamp)),
+				Message:   aws.ToString(e.Message),
+			})
+			if o.Limit > 0 && len(events) == o.Limit {
+				return
+			}
+		}
+		if input.NextToken = out.NextToken; input.NextToken == nil {
+			break
+		}
+	}
+	return
+}
+
+// Tail calls handler for the new log group events matched by the filter
+// pattern until ctx is canceled. The events are polled by FilterLogEvents,
+// the events delivered to CloudWatch Logs with delay after the newer events
+// may be skipped.
+//
+// Parameters:
+//   - ctx: The context to stop tailing.
+//   - group: The log group name.
+//   - filterPattern: The CloudWatch Logs filter pattern. Empty pattern
+//     matches all events.
+//   - handler: The function called for each event. Tail stops and returns
+//     the handler error.
+//   - opts: The optional tail parameters.
+//
+// Returns:
+//   - err: The handler error or not retryable operation error. Nil is
+//     returned when ctx is canceled.
+func (a awsLogs) Tail(ctx context.Context, group, filterPattern string,
+	handler func(event LogEvent) error, opts ...LogsTailOptions) (err error) {
+
+	var o LogsTailOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	if o.Interval <= 0 {
+		o.Interval = logsTailInterval
+	}
+	clk := clock(a.ctx)
+	if o.Start.IsZero() {
+		o.Start = clk.Now().Add(-logsTailSince)
+	}
+	o.End, o.Limit = time.Time{}, 0
+	a.ctx = ctx
+
+	// The seen contains IDs of the events with the last timestamp which are
+	// returned again by the next poll
+	seen := make(map[string]bool)
+	for {
+		var events []LogEvent
+		events, err = a.Filter(group, filterPattern, o.LogsFilterOptions)
+		switch {
+		case ctx.Err() != nil:
+			return nil
+		case err != nil && !IsRetryable(err):
+			return
+		}
+
+		for _, e := range events {
+			if seen[e.ID] {
+				continue
+			}
+			if e.Timestamp.After(o.Start) {
+				o.Start = e.Timestamp
+				clear(seen)
+			}
+			seen[e.ID] = true
+			if err = handler(e); err != nil {
+				return
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-clk.After(max(o.Interval, RetryAfter(err))):
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package aws
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestLogsFilter checks CloudWatch Logs filter pages and limit
+func TestLogsFilter(t *testing.T) {
+
+	client := &pagesHTTPClient{bodies: []string{
+		`{"events":[{"eventId":"1","logStreamName":"s1","timestamp":1000,` +
+			`"message":"ERROR a"}],"nextToken":"t1"}`,
+		`{"events":[{"eventId":"2","logStreamName":"s2","timestamp":2000,` +
+			`"message":"ERROR b"},{"eventId":"3","timestamp":3000}],` +
+			`"nextToken":"t2"}`,
+	}}
+	a := newPagesTestAws(client)
+
+	events, err := a.Logs.Filter("/app/api", "ERROR", LogsFilterOptions{
+		Start: time.UnixMilli(500),
+		Limit: 2,
+	})
+	if err != nil || len(events) != 2 || events[1].Stream != "s2" ||
+		events[1].Message != "ERROR b" ||
+		!events[1].Timestamp.Equal(time.UnixMilli(2000)) {
+		t.Fatal("wrong events:", events, err)
+	}
+	if !strings.Contains(client.requests[1], `"nextToken":"t1"`) ||
+		!strings.Contains(client.requests[1], `"startTime":500`) {
+		t.Error("wrong next page request:", client.requests[1])
+	}
+}
+
+// TestLogsTail checks CloudWatch Logs tail does not repeat events
+func TestLogsTail(t *testing.T) {
+
+	client := &pagesHTTPClient{bodies: []string{
+		`{"events":[{"eventId":"1","timestamp":1000},` +
+			`{"eventId":"2","timestamp":2000}]}`,
+		`{"events":[{"eventId":"2","timestamp":2000},` +
+			`{"eventId":"3","timestamp":2000},{"eventId":"4","timestamp":3000}]}`,
+	}}
+	a := newPagesTestAws(client)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var ids []string
+	err := a.Logs.Tail(ctx, "/app/api", "", func(e LogEvent) error {
+		ids = append(ids, e.ID)
+		if e.ID == "4" {
+			cancel()
+		}
+		return nil
+	}, LogsTailOptions{
+		LogsFilterOptions: LogsFilterOptions{Start: time.UnixMilli(0)},
+		Interval:          time.Millisecond,
+	})
+	if err != nil || strings.Join(ids, ",") != "1,2,3,4" {
+		t.Error("wrong tail events:", ids, err)
+	}
+	if !strings.Contains(client.requests[1], `"startTime":2000`) {
+		t.Error("wrong next poll request:", client.requests[1])
+	}
+}
@@ -0,0 +1,187 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/organizations"
+	"github.com/aws/aws-sdk-go-v2/service/organizations/types"
+)
+
+// awsOrgs is the AWS Organizations client struct.
+type awsOrgs struct {
+	// ctx is the context.Context for AWS requests
+	ctx context.Context
+
+	// Client is the AWS Organizations client
+	Client *organizations.Client
+
+	// sts is the STS client used to assume roles in member accounts
+	sts *awsSTS
+}
+
+// Organization is the AWS organization.
+type Organization struct {
+	// ID is the organization ID, for example "o-exampleorgid".
+	ID string
+
+	// ARN is the organization ARN.
+	ARN string
+
+	// FeatureSet is "ALL" or "CONSOLIDATED_BILLING".
+	FeatureSet string
+
+	// ManagementAccountID is the management account ID.
+	ManagementAccountID string
+
+	// ManagementAccountEmail is the management account email.
+	ManagementAccountEmail string
+}
+
+// OrgAccount is the account of the organization.
+type OrgAccount struct {
+	// ID is the account ID.
+	ID string
+
+	// ARN is the account ARN.
+	ARN string
+
+	// Name is the account name.
+	Name string
+
+	// Email is the account root user email.
+	Email string
+
+	// Status is "ACTIVE", "SUSPENDED" or "PENDING_CLOSURE".
+	Status string
+
+	// JoinedAt is the time the account joined the organization.
+	JoinedAt time.Time
+}
+
+// Active returns true if the account is active.
+func (a OrgAccount) Active() bool {
+	return a.Status == string(types.AccountStatusActive)
+}
+
+// OrgAccountAws is the organization account with the Aws which clients use
+// the account role credentials.
+type OrgAccountAws struct {
+	// Account is the organization account.
+	Account OrgAccount
+
+	// Aws is the Aws with the account role credentials, nil if the role
+	// can't be assumed.
+	Aws *Aws
+}
+
+// DescribeOrganization returns the organization of the caller account.
+//
+// Returns:
+//   - org: The organization.
+//   - err: An error if the operation fails.
+func (a awsOrgs) DescribeOrganization() (org Organization, err error) {
+	out, err := a.Client.DescribeOrganization(a.ctx,
+		&organizations.DescribeOrganizationInput{})
+	if err != nil || out.Organization == nil {
+		return
+	}
+	o := out.Organization
+	org = Organization{
+		ID:                     aws.ToString(o.Id),
+		ARN:                    aws.ToString(o.Arn),
+		FeatureSet:             string(o.FeatureSet),
+		ManagementAccountID:    aws.ToString(o.MasterAccountId),
+		ManagementAccountEmail: aws.ToString(o.MasterAccountEmail),
+	}
+	return
+}
+
+// ListAccounts returns all accounts of the organization. It must be called
+// in the management account or the delegated administrator account.
+//
+// Returns:
+//   - accounts: The organization accounts.
+//   - err: An error if the operation fails.
+func (a awsOrgs) ListAccounts() (accounts []OrgAccount, err error) {
+	input := &organizations.ListAccountsInput{}
+	for {
+		var out *organizations.ListAccountsOutput
+		out, err = a.Client.ListAccounts(a.ctx, input)
+		if err != nil {
+			return
+		}
+		for _, acc := range out.Accounts {
+			accounts = append(accounts, OrgAccount{
+				ID:       aws.ToString(acc.Id),
+				ARN:      aws.ToString(acc.Arn),
+				Name:     aws.ToString(acc.Name),
+				Email:    aws.ToString(acc.Email),
+				Status:   string(acc.Status),
+				JoinedAt: aws.ToTime(acc.JoinedTimestamp),
+			})
+		}
+		if aws.ToString(out.NextToken) == "" {
+			break
+		}
+		input.NextToken = out.NextToken
+	}
+	return
+}
+
+// AccountsAws returns an iterator over the active organization accounts
+// with the Aws which clients use the credentials of the role assumed in the
+// account:
+//
+//	for acc, err := range a.Orgs.AccountsAws("AuditRole", "audit") {
+//		if err != nil {
+//			log.Println(acc.Account.ID, err)
+//			continue
+//		}
+//		buckets, err := acc.Aws.S3.ListBuckets()
+//		...
+//	}
+//
+// The role assume error is yielded with the account and the iteration
+// continues, the accounts list error stops the iteration.
+//
+// Parameters:
+//   - roleName: The role name in each account, for example
+//     "OrganizationAccountAccessRole".
+//   - sessionName: The role session name shown in CloudTrail.
+//   - opts: The optional assume role parameters.
+func (a awsOrgs) AccountsAws(roleName, sessionName string,
+	opts ...STSAssumeRoleOptions) iter.Seq2[OrgAccountAws, error] {
+
+	return func(yield func(OrgAccountAws, error) bool) {
+		accounts, err := a.ListAccounts()
+		if err != nil {
+			yield(OrgAccountAws{}, err)
+			return
+		}
+		for _, acc := range accounts {
+			if !acc.Active() {
+				continue
+			}
+			roleArn := fmt.Sprintf("arn:%s:iam::%s:role/%s",
+				orgPartition(acc.ARN), acc.ID, roleName)
+			accAws, err := a.sts.AssumeRoleAws(roleArn, sessionName, opts...)
+			if !yield(OrgAccountAws{Account: acc, Aws: accAws}, err) {
+				return
+			}
+		}
+	}
+}
+
+// orgPartition returns the partition of the account ARN, "aws" by default.
+func orgPartition(arn string) string {
+	if fields := strings.Split(arn, ":"); len(fields) > 1 &&
+		fields[1] != "" {
+		return fields[1]
+	}
+	return "aws"
+}
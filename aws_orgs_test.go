@@ -0,0 +1,52 @@
+package aws
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// TestOrgsAccountsAws checks Organizations accounts pages, inactive accounts
+// skip and the role assumed in each account
+func TestOrgsAccountsAws(t *testing.T) {
+
+	const creds = `<AssumeRoleResponse><AssumeRoleResult><Credentials>` +
+		`<AccessKeyId>AK</AccessKeyId><SecretAccessKey>SK</SecretAccessKey>` +
+		`<SessionToken>ST</SessionToken>` +
+		`<Expiration>2030-01-01T00:00:00Z</Expiration>` +
+		`</Credentials></AssumeRoleResult></AssumeRoleResponse>`
+	client := &pagesHTTPClient{bodies: []string{
+		`{"NextToken":"p2","Accounts":[{"Id":"111","Name":"prod",` +
+			`"Arn":"arn:aws:organizations::000:account/o-1/111",` +
+			`"Status":"ACTIVE"},{"Id":"222","Name":"old",` +
+			`"Arn":"arn:aws:organizations::000:account/o-1/222",` +
+			`"Status":"SUSPENDED"}]}`,
+		`{"Accounts":[{"Id":"333","Name":"dev",` +
+			`"Arn":"arn:aws:organizations::000:account/o-1/333",` +
+			`"Status":"ACTIVE"}]}`,
+		creds,
+		`<ErrorResponse><Error><Type>Sender</Type><Code>AccessDenied</Code>` +
+			`<Message>not authorized</Message></Error></ErrorResponse>`,
+	}, statuses: []int{200, 200, 200, http.StatusForbidden}}
+	a := newPagesTestAws(client)
+
+	var ids []string
+	var failed []string
+	for acc, err := range a.Orgs.AccountsAws("AuditRole", "audit") {
+		if err != nil {
+			failed = append(failed, acc.Account.ID)
+			continue
+		}
+		if acc.Aws == nil {
+			t.Fatal("no account Aws:", acc.Account)
+		}
+		ids = append(ids, acc.Account.ID)
+	}
+	if strings.Join(ids, ",") != "111" || strings.Join(failed, ",") != "333" {
+		t.Error("wrong accounts:", ids, failed)
+	}
+	if !strings.Contains(client.requests[2],
+		"RoleArn=arn%3Aaws%3Aiam%3A%3A111%3Arole%2FAuditRole") {
+		t.Error("wrong assume role request:", client.requests[2])
+	}
+}
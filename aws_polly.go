@@ -0,0 +1,282 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/polly"
+	"github.com/aws/aws-sdk-go-v2/service/polly/types"
+)
+
+// ErrPollyTaskFailed is returned by Polly WaitForTask when the speech
+// synthesis task failed.
+var ErrPollyTaskFailed = errors.New("polly task failed")
+
+const (
+	// pollyWaitDelay is the first delay between the task status polls, the
+	// delay is doubled up to pollyWaitMaxDelay.
+	pollyWaitDelay = 2 * time.Second
+
+	// pollyWaitMaxDelay is the maximum delay between the task status polls.
+	pollyWaitMaxDelay = 30 * time.Second
+)
+
+// awsPolly is the AWS Polly client struct.
+type awsPolly struct {
+	// ctx is the context.Context for AWS requests
+	ctx context.Context
+
+	// Client is the AWS Polly client
+	Client *polly.Client
+}
+
+// PollyOptions are the optional parameters of the Polly speech synthesis.
+type PollyOptions struct {
+	// Engine is the synthesis engine: "standard", "neural", "long-form" or
+	// "generative". Default is the service default "standard".
+	Engine string
+
+	// SSML is true if the text is the SSML document.
+	SSML bool
+
+	// LanguageCode is the language of the bilingual voices, for example
+	// "en-IN" for the "Aditi" voice.
+	LanguageCode string
+
+	// SampleRate is the audio sample rate in Hz, for example "22050".
+	SampleRate string
+
+	// SNSTopicARN is the SNS topic the SynthesizeToS3 task status is
+	// published to.
+	SNSTopicARN string
+}
+
+// PollyTask is the Polly speech synthesis task.
+type PollyTask struct {
+	// ID is the task ID.
+	ID string
+
+	// Status is the task status: "scheduled", "inProgress", "completed" or
+	// "failed".
+	Status string
+
+	// Reason is the reason of the failed task.
+	Reason string
+
+	// Bucket and Key are the S3 location of the audio.
+	Bucket, Key string
+
+	// Characters is the number of the synthesized characters.
+	Characters int
+}
+
+// Done returns true if the task is completed or failed.
+func (t PollyTask) Done() bool {
+	return t.Status == string(types.TaskStatusCompleted) ||
+		t.Status == string(types.TaskStatusFailed)
+}
+
+// Synthesize synthesizes the speech of the short text up to 3000
+// characters, use SynthesizeToS3 for the long texts.
+//
+// Parameters:
+//   - text: The text or SSML document.
+//   - voice: The voice ID, for example "Joanna".
+//   - format: The audio format: "mp3", "ogg_vorbis" or "pcm".
+//   - opts: The optional synthesis parameters.
+//
+// Returns:
+//   - audio: The audio data.
+//   - err: An error if the operation fails.
+func (a awsPolly) Synthesize(text, voice, format string,
+	opts ...PollyOptions) (audio []byte, err error) {
+
+	stream, _, err := a.SynthesizeStream(text, voice, format, opts...)
+	if err != nil {
+		return
+	}
+	defer stream.Close()
+	audio, err = io.ReadAll(stream)
+	return
+}
+
+// SynthesizeStream synthesizes the speech of the short text up to 3000
+// characters and returns the audio stream, for example to write it to the
+// HTTP response.
+//
+// Parameters:
+//   - text: The text or SSML document.
+//   - voice: The voice ID, for example "Joanna".
+//   - format: The audio format: "mp3", "ogg_vorbis" or "pcm".
+//   - opts: The optional synthesis parameters.
+//
+// Returns:
+//   - stream: The audio stream, the caller must close it.
+//   - contentType: The audio content type, for example "audio/mpeg".
+//   - err: An error if the operation fails.
+func (a awsPolly) SynthesizeStream(text, voice, format string,
+	opts ...PollyOptions) (stream io.ReadCloser, contentType string,
+	err error) {
+
+	var o PollyOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	out, err := a.Client.SynthesizeSpeech(a.ctx, &polly.SynthesizeSpeechInput{
+		Text:         aws.String(text),
+		VoiceId:      types.VoiceId(voice),
+		OutputFormat: types.OutputFormat(format),
+		Engine:       types.Engine(o.Engine),
+		LanguageCode: types.LanguageCode(o.LanguageCode),
+		SampleRate:   optional(o.SampleRate),
+		TextType:     pollyTextType(o.SSML),
+	})
+	if err != nil {
+		return
+	}
+	stream, contentType = out.AudioStream, aws.ToString(out.ContentType)
+	return
+}
+
+// SynthesizeToS3 starts the speech synthesis task of the long text up to
+// 100000 characters. The audio is saved to the S3 bucket when the task is
+// completed, use WaitForTask to wait for it.
+//
+// Parameters:
+//   - bucket: The S3 bucket name.
+//   - prefix: The S3 key prefix of the audio, the task ID and format
+//     extension are added to it.
+//   - text: The text or SSML document.
+//   - voice: The voice ID, for example "Joanna".
+//   - format: The audio format: "mp3", "ogg_vorbis" or "pcm".
+//   - opts: The optional synthesis parameters.
+//
+// Returns:
+//   - task: The scheduled task.
+//   - err: An error if the operation fails.
+func (a awsPolly) SynthesizeToS3(bucket, prefix, text, voice, format string,
+	opts ...PollyOptions) (task PollyTask, err error) {
+
+	var o PollyOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	out, err := a.Client.StartSpeechSynthesisTask(a.ctx,
+		&polly.StartSpeechSynthesisTaskInput{
+			OutputS3BucketName: aws.String(bucket),
+			OutputS3KeyPrefix:  optional(prefix),
+			Text:               aws.String(text),
+			VoiceId:            types.VoiceId(voice),
+			OutputFormat:       types.OutputFormat(format),
+			Engine:             types.Engine(o.Engine),
+			LanguageCode:       types.LanguageCode(o.LanguageCode),
+			SampleRate:         optional(o.SampleRate),
+			SnsTopicArn:        optional(o.SNSTopicARN),
+			TextType:           pollyTextType(o.SSML),
+		},
+	)
+	if err != nil {
+		return
+	}
+	task = pollyTask(out.SynthesisTask)
+	return
+}
+
+// GetTask returns the speech synthesis task.
+//
+// Parameters:
+//   - taskID: The task ID.
+//
+// Returns:
+//   - task: The task.
+//   - err: An error if the operation fails. The error wraps ErrNotFound if
+//     the task does not exist.
+func (a awsPolly) GetTask(taskID string) (task PollyTask, err error) {
+	return a.task(a.ctx, taskID)
+}
+
+// WaitForTask waits for the speech synthesis task to complete. The task
+// status is polled with the delay doubled from 2 up to 30 seconds.
+//
+// Parameters:
+//   - ctx: The context to stop waiting.
+//   - taskID: The task ID.
+//
+// Returns:
+//   - task: The completed task with the audio S3 location.
+//   - err: An error if the operation fails or ctx is canceled. The error
+//     wraps ErrPollyTaskFailed if the task failed.
+func (a awsPolly) WaitForTask(ctx context.Context, taskID string) (
+	task PollyTask, err error) {
+
+	delay := pollyWaitDelay
+	for {
+		task, err = a.task(ctx, taskID)
+		switch {
+		case err != nil && !IsRetryable(err):
+			return
+		case err == nil && task.Status == string(types.TaskStatusFailed):
+			err = fmt.Errorf("%w: %s", ErrPollyTaskFailed, task.Reason)
+			return
+		case err == nil && task.Done():
+			return
+		}
+
+		// Wait before next poll
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+			return
+		case <-clock(a.ctx).After(max(delay, RetryAfter(err))):
+		}
+		delay = min(delay*2, pollyWaitMaxDelay)
+	}
+}
+
+// task returns the speech synthesis task.
+func (a awsPolly) task(ctx context.Context, taskID string) (task PollyTask,
+	err error) {
+
+	out, err := a.Client.GetSpeechSynthesisTask(ctx,
+		&polly.GetSpeechSynthesisTaskInput{TaskId: aws.String(taskID)})
+	if err != nil {
+		return
+	}
+	task = pollyTask(out.SynthesisTask)
+	return
+}
+
+// pollyTask returns PollyTask from the Polly synthesis task.
+func pollyTask(t *types.SynthesisTask) (task PollyTask) {
+	if t == nil {
+		return
+	}
+	task = PollyTask{
+		ID:         aws.ToString(t.TaskId),
+		Status:     string(t.TaskStatus),
+		Reason:     aws.ToString(t.TaskStatusReason),
+		Characters: int(t.RequestCharacters),
+	}
+
+	// The output URI is the path style S3 URL:
+	// https://s3.region.amazonaws.com/bucket/key
+	if u, err := url.Parse(aws.ToString(t.OutputUri)); err == nil {
+		path := strings.TrimPrefix(u.Path, "/")
+		task.Bucket, task.Key, _ = strings.Cut(path, "/")
+	}
+	return
+}
+
+// pollyTextType returns the Polly text type.
+func pollyTextType(ssml bool) types.TextType {
+	if ssml {
+		return types.TextTypeSsml
+	}
+	return types.TextTypeText
+}
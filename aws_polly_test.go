@@ -0,0 +1,57 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestPollySynthesize checks Polly audio bytes, S3 synthesis task location
+// and failed task
+func TestPollySynthesize(t *testing.T) {
+
+	client := &pagesHTTPClient{bodies: []string{
+		"ID3-audio",
+		`{"SynthesisTask":{"TaskId":"t-1","TaskStatus":"scheduled",` +
+			`"OutputUri":"https://s3.us-east-1.amazonaws.com/media/` +
+			`speech/t-1.mp3","RequestCharacters":12}}`,
+		`{"SynthesisTask":{"TaskId":"t-1","TaskStatus":"completed",` +
+			`"OutputUri":"https://s3.us-east-1.amazonaws.com/media/` +
+			`speech/t-1.mp3","RequestCharacters":12}}`,
+		`{"SynthesisTask":{"TaskId":"t-2","TaskStatus":"failed",` +
+			`"TaskStatusReason":"Invalid SSML"}}`,
+	}}
+	a := newPagesTestAws(client)
+
+	audio, err := a.Polly.Synthesize("<speak>Hi</speak>", "Joanna", "mp3",
+		PollyOptions{Engine: "neural", SSML: true})
+	if err != nil || string(audio) != "ID3-audio" {
+		t.Fatal("wrong audio:", string(audio), err)
+	}
+	for _, s := range []string{`"Engine":"neural"`, `"TextType":"ssml"`,
+		`"VoiceId":"Joanna"`} {
+		if !strings.Contains(client.requests[0], s) {
+			t.Error("synthesize request does not contain", s,
+				client.requests[0])
+		}
+	}
+
+	task, err := a.Polly.SynthesizeToS3("media", "speech/", "Hello, world",
+		"Joanna", "mp3")
+	if err != nil || task.ID != "t-1" || task.Done() ||
+		task.Bucket != "media" || task.Key != "speech/t-1.mp3" {
+		t.Fatal("wrong task:", task, err)
+	}
+
+	task, err = a.Polly.WaitForTask(context.Background(), task.ID)
+	if err != nil || !task.Done() || task.Characters != 12 {
+		t.Error("wrong completed task:", task, err)
+	}
+
+	_, err = a.Polly.WaitForTask(context.Background(), "t-2")
+	if !errors.Is(err, ErrPollyTaskFailed) ||
+		!strings.Contains(err.Error(), "Invalid SSML") {
+		t.Error("wrong failed task error:", err)
+	}
+}
@@ -0,0 +1,256 @@
+package aws
+
+import (
+	"context"
+	"slices"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/rekognition"
+	"github.com/aws/aws-sdk-go-v2/service/rekognition/types"
+)
+
+// awsRekognition is the AWS Rekognition client struct.
+type awsRekognition struct {
+	// ctx is the context.Context for AWS requests
+	ctx context.Context
+
+	// Client is the AWS Rekognition client
+	Client *rekognition.Client
+}
+
+// RekognitionBox is the bounding box of the detected object. The values are
+// the ratios of the image width and height, from 0 to 1.
+type RekognitionBox struct {
+	Left, Top, Width, Height float64
+}
+
+// RekognitionLabel is the label detected in the image.
+type RekognitionLabel struct {
+	// Name is the label name, for example "Person" or "Car".
+	Name string
+
+	// Confidence is the label confidence, from 0 to 100.
+	Confidence float64
+
+	// Parents are the names of the parent labels, for example "Vehicle" for
+	// the "Car".
+	Parents []string
+
+	// Boxes are the bounding boxes of the label instances, empty if the
+	// label is not an object, for example "Outdoors".
+	Boxes []RekognitionBox
+}
+
+// RekognitionModerationLabel is the unsafe content label detected in the
+// image.
+type RekognitionModerationLabel struct {
+	// Name is the label name, for example "Explicit Nudity".
+	Name string
+
+	// ParentName is the parent label name, empty for the top level labels.
+	ParentName string
+
+	// Confidence is the label confidence, from 0 to 100.
+	Confidence float64
+
+	// Level is the label taxonomy level: 1 for the top level labels.
+	Level int
+}
+
+// RekognitionFace is the face detected in the image.
+type RekognitionFace struct {
+	// Confidence is the face confidence, from 0 to 100.
+	Confidence float64
+
+	// Box is the face bounding box.
+	Box RekognitionBox
+
+	// AgeLow and AgeHigh are the estimated age range.
+	AgeLow, AgeHigh int
+
+	// Smile is true if the face is smiling.
+	Smile bool
+
+	// EyesOpen is true if the eyes are open.
+	EyesOpen bool
+
+	// Emotions are the emotions confidences by the emotion type, for example
+	// "HAPPY" or "CALM".
+	Emotions map[string]float64
+}
+
+// DetectLabels detects the labels in the S3 image.
+//
+// Parameters:
+//   - bucket: The S3 bucket name.
+//   - key: The S3 object key of the JPEG or PNG image.
+//   - minConfidence: The minimum confidence of the returned labels, from 0
+//     to 100. Zero for the service default 55.
+//
+// Returns:
+//   - labels: The detected labels sorted by confidence, highest first.
+//   - err: An error if the operation fails.
+func (a awsRekognition) DetectLabels(bucket, key string,
+	minConfidence float64) (labels []RekognitionLabel, err error) {
+
+	out, err := a.Client.DetectLabels(a.ctx, &rekognition.DetectLabelsInput{
+		Image:         rekognitionImage(bucket, key),
+		MinConfidence: rekognitionConfidence(minConfidence),
+	})
+	if err != nil {
+		return
+	}
+	for _, l := range out.Labels {
+		label := RekognitionLabel{
+			Name:       aws.ToString(l.Name),
+			Confidence: float64(aws.ToFloat32(l.Confidence)),
+		}
+		if label.Confidence < minConfidence {
+			continue
+		}
+		for _, p := range l.Parents {
+			label.Parents = append(label.Parents, aws.ToString(p.Name))
+		}
+		for _, i := range l.Instances {
+			label.Boxes = append(label.Boxes, rekognitionBox(i.BoundingBox))
+		}
+		labels = append(labels, label)
+	}
+	slices.SortStableFunc(labels, func(a, b RekognitionLabel) int {
+		return cmpConfidence(a.Confidence, b.Confidence)
+	})
+
+	return
+}
+
+// DetectModerationLabels detects the unsafe content in the S3 image.
+//
+// Parameters:
+//   - bucket: The S3 bucket name.
+//   - key: The S3 object key of the JPEG or PNG image.
+//   - minConfidence: The minimum confidence of the returned labels, from 0
+//     to 100. Zero for the service default 50.
+//
+// Returns:
+//   - labels: The detected labels sorted by confidence, highest first. Empty
+//     if the image is safe.
+//   - err: An error if the operation fails.
+func (a awsRekognition) DetectModerationLabels(bucket, key string,
+	minConfidence float64) (labels []RekognitionModerationLabel, err error) {
+
+	out, err := a.Client.DetectModerationLabels(a.ctx,
+		&rekognition.DetectModerationLabelsInput{
+			Image:         rekognitionImage(bucket, key),
+			MinConfidence: rekognitionConfidence(minConfidence),
+		},
+	)
+	if err != nil {
+		return
+	}
+	for _, l := range out.ModerationLabels {
+		label := RekognitionModerationLabel{
+			Name:       aws.ToString(l.Name),
+			ParentName: aws.ToString(l.ParentName),
+			Confidence: float64(aws.ToFloat32(l.Confidence)),
+			Level:      int(aws.ToInt32(l.TaxonomyLevel)),
+		}
+		if label.Confidence < minConfidence {
+			continue
+		}
+		labels = append(labels, label)
+	}
+	slices.SortStableFunc(labels, func(a, b RekognitionModerationLabel) int {
+		return cmpConfidence(a.Confidence, b.Confidence)
+	})
+
+	return
+}
+
+// DetectFaces detects the faces and their attributes in the S3 image.
+//
+// Parameters:
+//   - bucket: The S3 bucket name.
+//   - key: The S3 object key of the JPEG or PNG image.
+//   - minConfidence: The minimum confidence of the returned faces, from 0 to
+//     100. Zero for all detected faces.
+//
+// Returns:
+//   - faces: The detected faces sorted by confidence, highest first.
+//   - err: An error if the operation fails.
+func (a awsRekognition) DetectFaces(bucket, key string,
+	minConfidence float64) (faces []RekognitionFace, err error) {
+
+	out, err := a.Client.DetectFaces(a.ctx, &rekognition.DetectFacesInput{
+		Image:      rekognitionImage(bucket, key),
+		Attributes: []types.Attribute{types.AttributeAll},
+	})
+	if err != nil {
+		return
+	}
+	for _, f := range out.FaceDetails {
+		face := RekognitionFace{
+			Confidence: float64(aws.ToFloat32(f.Confidence)),
+			Box:        rekognitionBox(f.BoundingBox),
+			Emotions:   make(map[string]float64, len(f.Emotions)),
+		}
+		if face.Confidence < minConfidence {
+			continue
+		}
+		if f.AgeRange != nil {
+			face.AgeLow = int(aws.ToInt32(f.AgeRange.Low))
+			face.AgeHigh = int(aws.ToInt32(f.AgeRange.High))
+		}
+		face.Smile = f.Smile != nil && f.Smile.Value
+		face.EyesOpen = f.EyesOpen != nil && f.EyesOpen.Value
+		for _, e := range f.Emotions {
+			face.Emotions[string(e.Type)] = float64(aws.ToFloat32(e.Confidence))
+		}
+		faces = append(faces, face)
+	}
+	slices.SortStableFunc(faces, func(a, b RekognitionFace) int {
+		return cmpConfidence(a.Confidence, b.Confidence)
+	})
+
+	return
+}
+
+// rekognitionImage returns the Rekognition image of the S3 object.
+func rekognitionImage(bucket, key string) *types.Image {
+	return &types.Image{S3Object: &types.S3Object{
+		Bucket: aws.String(bucket),
+		Name:   aws.String(key),
+	}}
+}
+
+// rekognitionConfidence returns the Rekognition minimum confidence, nil for
+// the service default.
+func rekognitionConfidence(confidence float64) *float32 {
+	if confidence <= 0 {
+		return nil
+	}
+	return aws.Float32(float32(confidence))
+}
+
+// rekognitionBox returns RekognitionBox from the Rekognition bounding box.
+func rekognitionBox(b *types.BoundingBox) RekognitionBox {
+	if b == nil {
+		return RekognitionBox{}
+	}
+	return RekognitionBox{
+		Left:   float64(aws.ToFloat32(b.Left)),
+		Top:    float64(aws.ToFloat32(b.Top)),
+		Width:  float64(aws.ToFloat32(b.Width)),
+		Height: float64(aws.ToFloat32(b.Height)),
+	}
+}
+
+// cmpConfidence compares the confidences in the descending order.
+func cmpConfidence(a, b float64) int {
+	switch {
+	case a > b:
+		return -1
+	case a < b:
+		return 1
+	}
+	return 0
+}
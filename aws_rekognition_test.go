@@ -0,0 +1,61 @@
+package aws
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// TestRekognitionDetect checks Rekognition S3 image input, typed results and
+// confidence filtering
+func TestRekognitionDetect(t *testing.T) {
+
+	client := &pagesHTTPClient{bodies: []string{
+		`{"Labels":[{"Name":"Outdoors","Confidence":70.5},` +
+			`{"Name":"Car","Confidence":98.2,"Parents":[{"Name":"Vehicle"}],` +
+			`"Instances":[{"BoundingBox":{"Left":0.1,"Top":0.2,"Width":0.3,` +
+			`"Height":0.4},"Confidence":98.2}]}]}`,
+		`{"ModerationLabels":[{"Name":"Violence","ParentName":"",` +
+			`"Confidence":61,"TaxonomyLevel":1},{"Name":"Weapons",` +
+			`"ParentName":"Violence","Confidence":92,"TaxonomyLevel":2}]}`,
+		`{"FaceDetails":[{"Confidence":40},{"Confidence":99.9,` +
+			`"AgeRange":{"Low":25,"High":32},"Smile":{"Value":true},` +
+			`"Emotions":[{"Type":"HAPPY","Confidence":95}]}]}`,
+	}}
+	a := newPagesTestAws(client)
+
+	labels, err := a.Rekognition.DetectLabels("uploads", "img.jpg", 60)
+	if err != nil || len(labels) != 2 || labels[0].Name != "Car" ||
+		labels[0].Parents[0] != "Vehicle" || len(labels[0].Boxes) != 1 ||
+		labels[0].Boxes[0].Width < 0.29 {
+		t.Fatal("wrong labels:", labels, err)
+	}
+	if !strings.Contains(client.requests[0],
+		`"S3Object":{"Bucket":"uploads","Name":"img.jpg"}`) ||
+		!strings.Contains(client.requests[0], `"MinConfidence":60`) {
+		t.Error("wrong detect labels request:", client.requests[0])
+	}
+
+	moderation, err := a.Rekognition.DetectModerationLabels("uploads",
+		"img.jpg", 0)
+	if err != nil || len(moderation) != 2 || moderation[0].Name != "Weapons" ||
+		moderation[0].ParentName != "Violence" || moderation[0].Level != 2 {
+		t.Error("wrong moderation labels:", moderation, err)
+	}
+
+	// Faces are filtered by confidence locally
+	faces, err := a.Rekognition.DetectFaces("uploads", "img.jpg", 90)
+	if err != nil || len(faces) != 1 || faces[0].AgeLow != 25 ||
+		!faces[0].Smile || faces[0].Emotions["HAPPY"] != 95 {
+		t.Error("wrong faces:", faces, err)
+	}
+
+	// Missing S3 object
+	a = newErrorTestAws(http.StatusBadRequest, `{"__type":`+
+		`"InvalidS3ObjectException","Message":"Unable to get object"}`)
+	_, err = a.Rekognition.DetectLabels("uploads", "missing.jpg", 0)
+	if !errors.Is(err, ErrNotFound) {
+		t.Error("wrong missing object error:", err)
+	}
+}
@@ -0,0 +1,244 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/route53"
+	"github.com/aws/aws-sdk-go-v2/service/route53/types"
+)
+
+const (
+	// route53WaitDelay is the first delay between the change status polls,
+	// the delay is doubled up to route53WaitMaxDelay.
+	route53WaitDelay = 2 * time.Second
+
+	// route53WaitMaxDelay is the maximum delay between the change status
+	// polls.
+	route53WaitMaxDelay = 30 * time.Second
+)
+
+// awsRoute53 is the AWS Route 53 client struct.
+type awsRoute53 struct {
+	// ctx is the context.Context for AWS requests
+	ctx context.Context
+
+	// Client is the AWS Route 53 client
+	Client *route53.Client
+}
+
+// Route53Record is the DNS record set of the hosted zone.
+type Route53Record struct {
+	// Name is the fully qualified record name with the trailing dot, for
+	// example "tenant.example.com.".
+	Name string
+
+	// Type is the record type, for example "A", "CNAME" or "TXT".
+	Type string
+
+	// TTL is the record time to live.
+	TTL time.Duration
+
+	// Values are the record values. The alias records have no values.
+	Values []string
+}
+
+// UpsertRecord creates the DNS record set or replaces its values and TTL if
+// it exists.
+//
+// Parameters:
+//   - zoneID: The hosted zone ID.
+//   - name: The record name, for example "tenant.example.com".
+//   - recordType: The record type, for example "A", "CNAME" or "TXT". The
+//     TXT values must be quoted.
+//   - values: The record values.
+//   - ttl: The record time to live.
+//
+// Returns:
+//   - changeID: The change ID, use it with WaitForChange.
+//   - err: An error if the operation fails. The error wraps ErrNotFound if
+//     the hosted zone does not exist.
+func (a awsRoute53) UpsertRecord(zoneID, name, recordType string,
+	values []string, ttl time.Duration) (changeID string, err error) {
+
+	return a.change(zoneID, types.ChangeActionUpsert,
+		route53RecordSet(Route53Record{
+			Name:   name,
+			Type:   recordType,
+			TTL:    ttl,
+			Values: values,
+		}),
+	)
+}
+
+// DeleteRecord deletes the DNS record set. The current record set is read
+// first because Route 53 deletes the record set with exactly the same values
+// and TTL only.
+//
+// Parameters:
+//   - zoneID: The hosted zone ID.
+//   - name: The record name, for example "tenant.example.com".
+//   - recordType: The record type, for example "A".
+//
+// Returns:
+//   - changeID: The change ID, use it with WaitForChange.
+//   - err: An error if the operation fails. The error wraps ErrNotFound if
+//     the hosted zone or the record does not exist.
+func (a awsRoute53) DeleteRecord(zoneID, name, recordType string) (
+	changeID string, err error) {
+
+	out, err := a.Client.ListResourceRecordSets(a.ctx,
+		&route53.ListResourceRecordSetsInput{
+			HostedZoneId:    aws.String(zoneID),
+			StartRecordName: aws.String(name),
+			StartRecordType: types.RRType(recordType),
+			MaxItems:        aws.Int32(1),
+		},
+	)
+	if err != nil {
+		return
+	}
+	if len(out.ResourceRecordSets) == 0 ||
+		!route53SameName(aws.ToString(out.ResourceRecordSets[0].Name), name) ||
+		string(out.ResourceRecordSets[0].Type) != recordType {
+		err = fmt.Errorf("%w: route53 record %s %s", ErrNotFound, name,
+			recordType)
+		return
+	}
+
+	set := out.ResourceRecordSets[0]
+	return a.change(zoneID, types.ChangeActionDelete, &set)
+}
+
+// ListRecords returns the DNS record sets of the hosted zone.
+//
+// Parameters:
+//   - zoneID: The hosted zone ID.
+//
+// Returns:
+//   - records: The record sets.
+//   - err: An error if the operation fails. The error wraps ErrNotFound if
+//     the hosted zone does not exist.
+func (a awsRoute53) ListRecords(zoneID string) (records []Route53Record,
+	err error) {
+
+	input := &route53.ListResourceRecordSetsInput{
+		HostedZoneId: aws.String(zoneID),
+	}
+	for {
+		var out *route53.ListResourceRecordSetsOutput
+		out, err = a.Client.ListResourceRecordSets(a.ctx, input)
+		if err != nil {
+			return
+		}
+		for _, set := range out.ResourceRecordSets {
+			record := Route53Record{
+				Name: route53Name(aws.ToString(set.Name)),
+				Type: string(set.Type),
+				TTL:  time.Duration(aws.ToInt64(set.TTL)) * time.Second,
+			}
+			for _, r := range set.ResourceRecords {
+				record.Values = append(record.Values, aws.ToString(r.Value))
+			}
+			records = append(records, record)
+		}
+		if !out.IsTruncated {
+			return
+		}
+		input.StartRecordName = out.NextRecordName
+		input.StartRecordType = out.NextRecordType
+		input.StartRecordIdentifier = out.NextRecordIdentifier
+	}
+}
+
+// WaitForChange waits for the change to be applied to all Route 53 DNS
+// servers. The change status is polled with the delay doubled from 2 up to
+// 30 seconds.
+//
+// Parameters:
+//   - ctx: The context to stop waiting.
+//   - changeID: The change ID returned by UpsertRecord or DeleteRecord.
+//
+// Returns:
+//   - err: An error if the operation fails or ctx is canceled.
+func (a awsRoute53) WaitForChange(ctx context.Context, changeID string) (
+	err error) {
+
+	delay := route53WaitDelay
+	for {
+		var out *route53.GetChangeOutput
+		out, err = a.Client.GetChange(ctx, &route53.GetChangeInput{
+			Id: aws.String(changeID),
+		})
+		switch {
+		case err != nil && !IsRetryable(err):
+			return
+		case err == nil && out.ChangeInfo != nil &&
+			out.ChangeInfo.Status == types.ChangeStatusInsync:
+			return
+		}
+
+		// Wait before next poll
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+			return
+		case <-clock(a.ctx).After(max(delay, RetryAfter(err))):
+		}
+		delay = min(delay*2, route53WaitMaxDelay)
+	}
+}
+
+// change executes the change batch with one change.
+func (a awsRoute53) change(zoneID string, action types.ChangeAction,
+	set *types.ResourceRecordSet) (changeID string, err error) {
+
+	out, err := a.Client.ChangeResourceRecordSets(a.ctx,
+		&route53.ChangeResourceRecordSetsInput{
+			HostedZoneId: aws.String(zoneID),
+			ChangeBatch: &types.ChangeBatch{
+				Changes: []types.Change{{
+					Action:            action,
+					ResourceRecordSet: set,
+				}},
+			},
+		},
+	)
+	if err != nil {
+		return
+	}
+	if out.ChangeInfo != nil {
+		changeID = aws.ToString(out.ChangeInfo.Id)
+	}
+	return
+}
+
+// route53RecordSet returns the Route 53 record set of the record.
+func route53RecordSet(record Route53Record) *types.ResourceRecordSet {
+	set := &types.ResourceRecordSet{
+		Name: aws.String(record.Name),
+		Type: types.RRType(record.Type),
+		TTL:  aws.Int64(int64(record.TTL / time.Second)),
+	}
+	for _, value := range record.Values {
+		set.ResourceRecords = append(set.ResourceRecords,
+			types.ResourceRecord{Value: aws.String(value)})
+	}
+	return set
+}
+
+// route53Name returns the record name with the unescaped asterisk of the
+// wildcard record.
+func route53Name(name string) string {
+	return strings.ReplaceAll(name, `\052`, "*")
+}
+
+// route53SameName returns true if the record names are equal. The names are
+// case insensitive and the trailing dot is optional.
+func route53SameName(a, b string) bool {
+	return strings.EqualFold(strings.TrimSuffix(route53Name(a), "."),
+		strings.TrimSuffix(b, "."))
+}
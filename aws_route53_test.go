@@ -0,0 +1,68 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestRoute53Records checks Route 53 record upsert, delete and change wait
+func TestRoute53Records(t *testing.T) {
+
+	const changed = `<ChangeResourceRecordSetsResponse><ChangeInfo>` +
+		`<Id>/change/C1</Id><Status>PENDING</Status>` +
+		`<SubmittedAt>2024-01-01T00:00:00Z</SubmittedAt></ChangeInfo>` +
+		`</ChangeResourceRecordSetsResponse>`
+	client := &pagesHTTPClient{bodies: []string{
+		changed,
+		`<ListResourceRecordSetsResponse><ResourceRecordSets>` +
+			`<ResourceRecordSet><Name>t1.example.com.</Name><Type>A</Type>` +
+			`<TTL>60</TTL><ResourceRecords><ResourceRecord>` +
+			`<Value>10.0.0.1</Value></ResourceRecord></ResourceRecords>` +
+			`</ResourceRecordSet></ResourceRecordSets>` +
+			`<IsTruncated>false</IsTruncated><MaxItems>1</MaxItems>` +
+			`</ListResourceRecordSetsResponse>`,
+		changed,
+		`<GetChangeResponse><ChangeInfo><Id>/change/C1</Id>` +
+			`<Status>INSYNC</Status>` +
+			`<SubmittedAt>2024-01-01T00:00:00Z</SubmittedAt></ChangeInfo>` +
+			`</GetChangeResponse>`,
+		`<ListResourceRecordSetsResponse><ResourceRecordSets>` +
+			`<ResourceRecordSet><Name>t2.example.com.</Name><Type>A</Type>` +
+			`<TTL>60</TTL></ResourceRecordSet></ResourceRecordSets>` +
+			`<IsTruncated>false</IsTruncated><MaxItems>1</MaxItems>` +
+			`</ListResourceRecordSetsResponse>`,
+	}}
+	a := newPagesTestAws(client)
+
+	changeID, err := a.Route53.UpsertRecord("Z1", "t1.example.com", "A",
+		[]string{"10.0.0.1"}, time.Minute)
+	if err != nil || changeID != "/change/C1" {
+		t.Fatal("wrong upsert:", changeID, err)
+	}
+	if !strings.Contains(client.requests[0], "<Action>UPSERT</Action>") ||
+		!strings.Contains(client.requests[0], "<TTL>60</TTL>") {
+		t.Error("wrong upsert request:", client.requests[0])
+	}
+
+	// Delete the existing record with its values
+	_, err = a.Route53.DeleteRecord("Z1", "t1.example.com", "A")
+	if err != nil {
+		t.Fatal("delete error:", err)
+	}
+	if !strings.Contains(client.requests[2], "<Action>DELETE</Action>") ||
+		!strings.Contains(client.requests[2], "<Value>10.0.0.1</Value>") {
+		t.Error("wrong delete request:", client.requests[2])
+	}
+	if err = a.Route53.WaitForChange(context.Background(), changeID); err != nil {
+		t.Error("wait for change error:", err)
+	}
+
+	// Delete the missing record
+	_, err = a.Route53.DeleteRecord("Z1", "t1.example.com", "A")
+	if !errors.Is(err, ErrNotFound) {
+		t.Error("wrong delete missing record error:", err)
+	}
+}
@@ -4,10 +4,12 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"slices"
 	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 )
 
 // awsS3 is the AWS S3 client struct
@@ -17,10 +19,31 @@ type awsS3 struct {
 
 	// Client is the AWS S3 client
 	Client *s3.Client
+
+	// Cache is the S3 objects content and metadata cache
+	Cache *S3Cache
+
+	// kms is the KMS client used to encrypt objects client-side
+	kms *awsKMS
+}
+
+// S3Options are the optional parameters of S3 Get and Set.
+type S3Options struct {
+	// KMSKeyID enables the client-side envelope encryption of the object
+	// content by the KMS key, see KMS EncryptLarge. The key ID, ARN or alias
+	// is required by Set, Get may use any not empty value. The S3 Cache
+	// keeps the encrypted content.
+	KMSKeyID string
+
+	// EncryptionContext is the KMS encryption context of the client-side
+	// encryption. The same context is required to decrypt the object.
+	EncryptionContext map[string]string
 }
 
-// Get return content of S3 object
-func (a awsS3) Get(bucket, objectName string) (data []byte, err error) {
+// Get return content of S3 object. The object encrypted client-side by Set
+// is decrypted if the KMSKeyID option is set.
+func (a awsS3) Get(bucket, objectName string, opts ...S3Options) (
+	data []byte, err error) {
 
 	// Get s3 object
 	rawObject, err := a.Client.GetObject(
@@ -39,6 +62,11 @@ func (a awsS3) Get(bucket, objectName string) (data []byte, err error) {
 	buf.ReadFrom(rawObject.Body)
 	data = buf.Bytes()
 
+	// Decrypt client-side encrypted object
+	if len(opts) > 0 && opts[0].KMSKeyID != "" {
+		data, err = a.kms.DecryptLarge("", data, opts[0].EncryptionContext)
+	}
+
 	return
 }
 
@@ -64,8 +92,19 @@ func (a awsS3) Info(bucket, objectName string) (result *s3.HeadObjectOutput,
 	return a.Client.HeadObject(a.ctx, &headObj)
 }
 
-// Set save S3 object content
-func (a awsS3) Set(bucket, objectName string, data []byte) (err error) {
+// Set save S3 object content. The content is encrypted client-side if the
+// KMSKeyID option is set.
+func (a awsS3) Set(bucket, objectName string, data []byte,
+	opts ...S3Options) (err error) {
+
+	// Encrypt object client-side
+	if len(opts) > 0 && opts[0].KMSKeyID != "" {
+		data, err = a.kms.EncryptLarge(opts[0].KMSKeyID, data,
+			opts[0].EncryptionContext)
+		if err != nil {
+			return
+		}
+	}
 
 	buf := bytes.NewReader(data)
 
@@ -75,6 +114,11 @@ func (a awsS3) Set(bucket, objectName string, data []byte) (err error) {
 		Body:   buf,
 	})
 
+	// Remove changed object from cache
+	if a.Cache != nil {
+		a.Cache.Delete(bucket, objectName)
+	}
+
 	return
 }
 
@@ -86,39 +130,133 @@ func (a awsS3) Delete(bucket, objectName string) (err error) {
 		Key:    aws.String(objectName),
 	})
 
+	// Remove deleted object from cache
+	if a.Cache != nil {
+		a.Cache.Delete(bucket, objectName)
+	}
+
+	return
+}
+
+// s3DeleteBatchSize is the maximum number of keys deleted by one S3
+// DeleteObjects request.
+const s3DeleteBatchSize = 1000
+
+// DeleteObjects deletes the S3 objects by the DeleteObjects requests of up
+// to 1000 keys. The not existing keys are deleted successfully.
+//
+// Parameters:
+//   - bucket: The name of the S3 bucket.
+//   - keys: The keys of the S3 objects.
+//
+// Returns:
+//   - deleted: The number of keys reported deleted by S3, it includes the
+//     not existing keys.
+//   - err: The *BatchError if some of the keys were not deleted. An error if
+//     the operation fails.
+func (a awsS3) DeleteObjects(bucket string, keys ...string) (deleted int,
+	err error) {
+
+	var batchErr BatchError
+	deleted, err = a.deleteObjects(bucket, keys, &batchErr)
+	if err == nil {
+		err = batchErr.err()
+	}
 	return
 }
 
-// Delete S3 folder
-func (a awsS3) DeleteFolder(bucket, folderName string) (err error) {
+// Delete S3 folder. All objects of the folder are listed page by page and
+// deleted by the DeleteObjects requests of up to 1000 keys, the folder
+// objects "folder/" and "folder" are deleted last.
+//
+// Parameters:
+//   - bucket: The name of the S3 bucket.
+//   - folderName: The folder name with or without trailing slash.
+//
+// Returns:
+//   - deleted: The number of the listed objects of the folder deleted, the
+//     folder objects are not counted.
+//   - err: The *BatchError if some of the objects were not deleted, the
+//     deletion continues with other objects. An error if the listing or a
+//     DeleteObjects request fails.
+func (a awsS3) DeleteFolder(bucket, folderName string) (deleted int,
+	err error) {
 
 	// Check folder length and Add slash to folder name
-	l := len(folderName)
-	if l == 0 {
+	folderName = strings.TrimRight(folderName, "/")
+	if folderName == "" {
 		return
 	}
-	if folderName[l-1] != '/' {
-		folderName += "/"
+	prefix := folderName + "/"
+
+	// Delete objects of the listed pages by the full batches
+	var batchErr BatchError
+	var keys []string
+	for page, err := range a.ListPages(bucket, prefix).Pages() {
+		if err != nil {
+			return deleted, err
+		}
+		keys = append(keys, page...)
+		if len(keys) < s3DeleteBatchSize {
+			continue
+		}
+		n, err := a.deleteObjects(bucket, keys, &batchErr)
+		deleted += n
+		if err != nil {
+			return deleted, err
+		}
+		keys = keys[:0]
 	}
 
-	// Get list objects in folder
-	// TODO: MaxKeys 0 - define 1000 in aws libray by default. Make a loop to
-	// delete all records
-	keys, err := a.List(bucket, folderName)
-	if err != nil {
-		return
+	// Delete the rest objects and the folder
+	keys = append(keys, prefix, folderName)
+	n, err := a.deleteObjects(bucket, keys, &batchErr, prefix, folderName)
+	deleted += n
+	if err == nil {
+		err = batchErr.err()
 	}
 
-	// Delete all objects in floder
-	for i := len(keys) - 1; i >= 0; i-- {
-		a.Delete(bucket, keys[i])
-	}
+	return
+}
 
-	// Remove trailing slash in folder name
-	folderName = strings.TrimRight(folderName, "/")
+// deleteObjects deletes the objects by the DeleteObjects batches and adds
+// the failed keys to the batchErr. The deleted keys are counted except the
+// uncounted keys.
+func (a awsS3) deleteObjects(bucket string, keys []string,
+	batchErr *BatchError, uncounted ...string) (deleted int, err error) {
+
+	for batch := range slices.Chunk(keys, s3DeleteBatchSize) {
+		input := &s3.DeleteObjectsInput{
+			Bucket: aws.String(bucket),
+			Delete: &types.Delete{},
+		}
+		for _, key := range batch {
+			input.Delete.Objects = append(input.Delete.Objects,
+				types.ObjectIdentifier{Key: aws.String(key)})
+		}
+		var out *s3.DeleteObjectsOutput
+		out, err = a.Client.DeleteObjects(a.ctx, input)
+		if err != nil {
+			return
+		}
+		for _, e := range out.Errors {
+			batchErr.add(aws.ToString(e.Key), entryError(
+				aws.ToString(e.Code), aws.ToString(e.Message)))
+		}
+		batchErr.Total += len(batch) - len(out.Errors)
+		for _, d := range out.Deleted {
+			if !slices.Contains(uncounted, aws.ToString(d.Key)) {
+				deleted++
+			}
+		}
 
-	// Delete folder
-	a.Delete(bucket, folderName)
+		// Remove deleted objects from cache
+		if a.Cache != nil {
+			for _, key := range batch {
+				a.Cache.Delete(bucket, key)
+			}
+		}
+	}
 
 	return
 }
@@ -134,10 +272,22 @@ type ListObjects struct {
 
 	// Marker is where you want Amazon S3 to start listing from. Amazon S3 starts
 	// listing after this specified key. Marker can be any key in the bucket.
+	//
+	// Deprecated: Use StartAfter, Marker is used as StartAfter if StartAfter
+	// is empty.
 	Marker string
 
 	// A delimiter is a character that you use to group keys.
 	Delimiter string
+
+	// ContinuationToken is the token of the next page returned by the
+	// previous listing, see ListPages which passes it automatically.
+	ContinuationToken string
+
+	// StartAfter is where you want Amazon S3 to start listing from. Amazon S3
+	// starts listing after this specified key. StartAfter can be any key in
+	// the bucket, it is ignored if ContinuationToken is set.
+	StartAfter string
 }
 
 // List return list of S3 objects keys in folder or returm list of prefixes if
@@ -148,11 +298,13 @@ type ListObjects struct {
 //   - prefix - limits the response to keys that begin with the specified prefix
 //   - params - additional parameters:
 //   - MaxKeys - maximum number of keys to return (default if 1000)
-//   - Marker - marker to use for pagination
+//   - StartAfter - key to start listing after, use it for pagination
 //   - Delimiter - is a character you use to group keys
+//   - ContinuationToken - token of the next page
 //
-// Marker is where you want Amazon S3 to start listing from. Amazon S3 starts
-// listing after this specified key. Marker can be any key in the bucket.
+// StartAfter is where you want Amazon S3 to start listing from. Amazon S3
+// starts listing after this specified key. StartAfter can be any key in the
+// bucket.
 //
 // Returns:
 //   - keys - list of S3 objects keys
@@ -160,88 +312,165 @@ type ListObjects struct {
 func (a awsS3) List(bucket, prefix string, params ...ListObjects) (
 	keys []string, err error) {
 
-	// Get parameters
-	var delimiter string
-	var maxKeysPtr *int32
-	var markerPtr, delimiterPtr *string
+	var p ListObjects
 	if len(params) > 0 {
-		// Get maxKeys
-		if params[0].MaxKeys > 0 {
-			maxKeysPtr = aws.Int32(int32(params[0].MaxKeys))
-		}
+		p = params[0]
+	}
+	page, err := a.list(bucket, prefix, p)
+	keys = page.keys
+	return
+}
 
-		// Get marker
-		if params[0].Marker != "" {
-			markerPtr = aws.String(params[0].Marker)
-		}
+// ListPages returns the paginator over the pages of S3 objects keys in
+// folder, or over the pages of prefixes if delimiter is not empty. The next
+// pages are listed by the continuation token of the previous page.
+//
+// Parameters:
+//   - bucket - S3 bucket name
+//   - prefix - limits the response to keys that begin with the specified prefix
+//   - params - additional parameters, MaxKeys is the page size and StartAfter
+//     is the key to start listing after
+//
+// Returns:
+//   - p - the keys pages paginator
+func (a awsS3) ListPages(bucket, prefix string, params ...ListObjects) (
+	p *Paginator[string]) {
 
-		// Get delimiter
-		if params[0].Delimiter != "" {
-			delimiterPtr = aws.String(params[0].Delimiter)
-			delimiter = params[0].Delimiter
-		}
+	var lp ListObjects
+	if len(params) > 0 {
+		lp = params[0]
 	}
+	return NewPaginator(func() (keys []string, more bool, err error) {
+		page, err := a.list(bucket, prefix, lp)
+		if err != nil {
+			return
+		}
+		lp.ContinuationToken = page.next
+		return page.keys, page.next != "", nil
+	})
+}
 
-	// Get s3 object
-	listObjects, err := a.Client.ListObjects(
-		a.ctx,
-		&s3.ListObjectsInput{
-			Bucket:    aws.String(bucket),
-			Prefix:    aws.String(prefix),
-			Delimiter: delimiterPtr,
-			MaxKeys:   maxKeysPtr,
-			Marker:    markerPtr,
-		},
-	)
-	if err != nil {
-		return
-	}
+// ListChan returns the channel of S3 objects keys in folder, or of prefixes
+// if delimiter is not empty. The pages are listed lazily by the goroutine
+// while the keys are received, the channels are closed when all pages are
+// listed, the listing fails or ctx is canceled:
+//
+//	ctx, cancel := context.WithCancel(context.Background())
+//	defer cancel()
+//	keys, errc := a.S3.ListChan(ctx, "bucket", "dir/")
+//	for key := range keys {
+//		...
+//	}
+//	if err := <-errc; err != nil {
+//		...
+//	}
+//
+// Parameters:
+//   - ctx - the context to stop listing, the listing requests use it
+//   - bucket - S3 bucket name
+//   - prefix - limits the response to keys that begin with the specified prefix
+//   - params - additional parameters, MaxKeys is the page size and StartAfter
+//     is the key to start listing after
+//
+// Returns:
+//   - ch - the channel of keys
+//   - errc - the channel which receives the listing error. Nothing is
+//     received when all pages are listed or ctx is canceled.
+func (a awsS3) ListChan(ctx context.Context, bucket, prefix string,
+	params ...ListObjects) (ch <-chan string, errc <-chan error) {
 
-	// Generate output array of prefixes
-	if delimiter != "" {
-		for _, p := range listObjects.CommonPrefixes {
-			keys = append(keys, *p.Prefix)
-		}
-		return
-	}
+	keys := make(chan string, 10)
+	errs := make(chan error, 1)
+	a.ctx = ctx
 
-	// Generate output array of keys
-	for _, obj := range listObjects.Contents {
-		if *obj.Key == prefix {
-			continue
+	// Send keys of the listed pages to output channel
+	go func() {
+		defer close(errs)
+		defer close(keys)
+		for key, err := range a.ListPages(bucket, prefix, params...).Items() {
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				errs <- err
+				return
+			}
+			select {
+			case keys <- key:
+			case <-ctx.Done():
+				return
+			}
 		}
-		keys = append(keys, *obj.Key)
-	}
+	}()
 
-	return
+	return keys, errs
 }
 
-// listS3 return channel with list of S3 objects keys in folder
-func (a awsS3) ListChan(bucket, prefix string) (ch chan string, err error) {
-	ch = make(chan string, 10)
+// s3ListPage is the page of the listed S3 objects.
+type s3ListPage struct {
+	// keys are the objects keys, or the common prefixes if the delimiter is
+	// set
+	keys []string
 
-	// Get s3 object
-	listObjects, err := a.Client.ListObjects(
-		a.ctx,
-		&s3.ListObjectsInput{
-			Bucket: aws.String(bucket),
-			Prefix: aws.String(prefix),
-		},
-	)
+	// etags are the objects ETags in the keys order, empty if the delimiter
+	// is set
+	etags []string
+
+	// next is the continuation token of the next page, empty if it is the
+	// last page
+	next string
+}
+
+// list returns the page of S3 objects keys with the prefix listed by the
+// ListObjectsV2. The prefix object itself is skipped. The keys and ETags are
+// collected in one pass to the slices preallocated by the page length.
+func (a awsS3) list(bucket, prefix string, params ListObjects) (
+	page s3ListPage, err error) {
+
+	startAfter := params.StartAfter
+	if startAfter == "" {
+		startAfter = params.Marker
+	}
+	input := &s3.ListObjectsV2Input{
+		Bucket:            aws.String(bucket),
+		Prefix:            aws.String(prefix),
+		Delimiter:         optional(params.Delimiter),
+		ContinuationToken: optional(params.ContinuationToken),
+		StartAfter:        optional(startAfter),
+	}
+	if params.MaxKeys > 0 {
+		input.MaxKeys = aws.Int32(int32(params.MaxKeys))
+	}
+	out, err := a.Client.ListObjectsV2(a.ctx, input)
 	if err != nil {
 		return
 	}
 
-	// Send keys to output channel
-	go func() {
-		for _, obj := range listObjects.Contents {
-			if *obj.Key == prefix {
-				continue
-			}
-			ch <- *obj.Key
+	// Next page continuation token
+	if aws.ToBool(out.IsTruncated) {
+		page.next = aws.ToString(out.NextContinuationToken)
+	}
+
+	// Common prefixes
+	if params.Delimiter != "" {
+		page.keys = make([]string, 0, len(out.CommonPrefixes))
+		for _, p := range out.CommonPrefixes {
+			page.keys = append(page.keys, aws.ToString(p.Prefix))
 		}
-		close(ch)
-	}()
+		return
+	}
+
+	// Objects keys and ETags
+	page.keys = make([]string, 0, len(out.Contents))
+	page.etags = make([]string, 0, len(out.Contents))
+	for i := range out.Contents {
+		obj := &out.Contents[i]
+		if aws.ToString(obj.Key) == prefix {
+			continue
+		}
+		page.keys = append(page.keys, aws.ToString(obj.Key))
+		page.etags = append(page.etags, aws.ToString(obj.ETag))
+	}
 
 	return
 }
@@ -249,6 +478,9 @@ func (a awsS3) ListChan(bucket, prefix string) (ch chan string, err error) {
 // ResponseError return aws error.
 // This function check if err is aws s3.ResponseError and return it and true in
 // ok. If err is not aws s3.ResponseError, this function return false in ok.
+//
+// Deprecated: Use errors.As with *Error which contains the error code, message,
+// request ID, host ID and HTTP status of all services.
 func (awsS3) ResponseError(err error) (re s3.ResponseError, ok bool) {
 	if errors.As(err, &re) {
 		ok = true
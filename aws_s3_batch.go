@@ -0,0 +1,580 @@
+package aws
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/url"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3control"
+	"github.com/aws/aws-sdk-go-v2/service/s3control/types"
+)
+
+// ErrS3BatchJobFailed is returned by S3Batch WaitForJob when the job is
+// failed or cancelled.
+var ErrS3BatchJobFailed = errors.New("s3 batch job failed")
+
+const (
+	// s3BatchReportPrefix is the default prefix of the job completion
+	// reports in the manifest bucket.
+	s3BatchReportPrefix = "s3-batch-reports"
+
+	// s3BatchPriority is the default job priority.
+	s3BatchPriority = 10
+
+	// s3BatchWaitDelay is the first delay between the job status polls, the
+	// delay is doubled up to s3BatchWaitMaxDelay.
+	s3BatchWaitDelay = 5 * time.Second
+
+	// s3BatchWaitMaxDelay is the maximum delay between the job status polls.
+	s3BatchWaitMaxDelay = time.Minute
+)
+
+// awsS3Batch is the AWS S3 Batch Operations client struct.
+type awsS3Batch struct {
+	// ctx is the context.Context for AWS requests
+	ctx context.Context
+
+	// Client is the AWS S3 Control client
+	Client *s3control.Client
+
+	// s3 is the S3 client used to write manifests and read reports
+	s3 *awsS3
+
+	// sts is the STS client used to get the account ID
+	sts *awsSTS
+
+	// account is the account ID of the jobs
+	account *s3BatchAccount
+}
+
+// s3BatchAccount is the account ID got from STS once.
+type s3BatchAccount struct {
+	sync.Mutex
+	id string
+}
+
+// S3BatchObject is the object of the job manifest.
+type S3BatchObject struct {
+	// Bucket is the object bucket name.
+	Bucket string
+
+	// Key is the object key.
+	Key string
+}
+
+// S3BatchManifest is the job manifest CSV object.
+type S3BatchManifest struct {
+	// Bucket is the manifest bucket name.
+	Bucket string
+
+	// Key is the manifest object key.
+	Key string
+
+	// ETag is the manifest object ETag.
+	ETag string
+}
+
+// S3BatchOperation is the operation executed by the job for each object of
+// the manifest. Only one of the operations may be set.
+type S3BatchOperation struct {
+	// CopyTo is the target bucket name or ARN of the copy operation.
+	CopyTo string
+
+	// CopyPrefix is the prefix added to the keys of the copied objects.
+	CopyPrefix string
+
+	// Tags replaces the tags of the objects.
+	Tags map[string]string
+
+	// RestoreDays is the number of days the objects restored from the
+	// Glacier storage classes are available.
+	RestoreDays int32
+
+	// RestoreTier is the restore tier: "BULK" or "STANDARD". Default is
+	// "BULK".
+	RestoreTier string
+
+	// LambdaARN is the ARN of the Lambda function invoked for each object.
+	LambdaARN string
+}
+
+// operation returns the S3 Control job operation.
+func (o S3BatchOperation) operation() (op *types.JobOperation, err error) {
+	op = new(types.JobOperation)
+	n := 0
+	if o.CopyTo != "" {
+		op.S3PutObjectCopy = &types.S3CopyObjectOperation{
+			TargetResource:  aws.String(s3BatchBucketARN(o.CopyTo)),
+			TargetKeyPrefix: optional(o.CopyPrefix),
+		}
+		n++
+	}
+	if o.Tags != nil {
+		tags := &types.S3SetObjectTaggingOperation{TagSet: []types.S3Tag{}}
+		for _, k := range slices.Sorted(maps.Keys(o.Tags)) {
+			tags.TagSet = append(tags.TagSet, types.S3Tag{
+				Key:   aws.String(k),
+				Value: aws.String(o.Tags[k]),
+			})
+		}
+		op.S3PutObjectTagging = tags
+		n++
+	}
+	if o.RestoreDays > 0 {
+		tier := types.S3GlacierJobTierBulk
+		if o.RestoreTier != "" {
+			tier = types.S3GlacierJobTier(o.RestoreTier)
+		}
+		op.S3InitiateRestoreObject = &types.S3InitiateRestoreObjectOperation{
+			ExpirationInDays: aws.Int32(o.RestoreDays),
+			GlacierJobTier:   tier,
+		}
+		n++
+	}
+	if o.LambdaARN != "" {
+		op.LambdaInvoke = &types.LambdaInvokeOperation{
+			FunctionArn: aws.String(o.LambdaARN),
+		}
+		n++
+	}
+	if n != 1 {
+		err = fmt.Errorf("s3 batch job requires one operation, got %d", n)
+	}
+	return
+}
+
+// S3BatchJobOptions are the optional parameters of the S3Batch CreateJob.
+type S3BatchJobOptions struct {
+	// Description is the job description.
+	Description string
+
+	// Priority is the job priority, the higher number is executed first.
+	// Default is 10.
+	Priority int32
+
+	// ConfirmationRequired creates the suspended job which is started after
+	// confirmation in the S3 console.
+	ConfirmationRequired bool
+
+	// ReportBucket is the bucket name of the job completion report. Default
+	// is the manifest bucket.
+	ReportBucket string
+
+	// ReportPrefix is the prefix of the job completion report. Default is
+	// "s3-batch-reports".
+	ReportPrefix string
+
+	// ReportFailedOnly reports the failed tasks only.
+	ReportFailedOnly bool
+}
+
+// S3BatchJob is the S3 Batch Operations job.
+type S3BatchJob struct {
+	// ID is the job ID.
+	ID string
+
+	// Status is the job status, for example "Active", "Complete" or
+	// "Failed".
+	Status string
+
+	// StatusReason is the reason of the last status change.
+	StatusReason string
+
+	// FailureReasons are the reasons of the failed job.
+	FailureReasons []string
+
+	// Total is the number of the job tasks.
+	Total int64
+
+	// Succeeded is the number of the succeeded tasks.
+	Succeeded int64
+
+	// Failed is the number of the failed tasks.
+	Failed int64
+
+	// ReportBucket is the bucket name of the completion report.
+	ReportBucket string
+
+	// ReportPrefix is the prefix of the completion report.
+	ReportPrefix string
+
+	// CreatedAt is the job creation time.
+	CreatedAt time.Time
+
+	// TerminatedAt is the time the job is completed, failed or cancelled.
+	TerminatedAt time.Time
+}
+
+// Done returns true if the job is completed, failed or cancelled.
+func (j S3BatchJob) Done() bool {
+	switch types.JobStatus(j.Status) {
+	case types.JobStatusComplete, types.JobStatusFailed,
+		types.JobStatusCancelled:
+		return true
+	}
+	return false
+}
+
+// S3BatchResult is the result of the job task from the completion report.
+type S3BatchResult struct {
+	// Bucket is the object bucket name.
+	Bucket string
+
+	// Key is the object key.
+	Key string
+
+	// VersionID is the object version ID.
+	VersionID string
+
+	// Status is the task status: "succeeded" or "failed".
+	Status string
+
+	// ErrorCode is the error code of the failed task.
+	ErrorCode string
+
+	// HTTPStatus is the HTTP status of the task.
+	HTTPStatus int
+
+	// Message is the task result message.
+	Message string
+}
+
+// WriteManifest writes the CSV job manifest of the objects to S3.
+//
+// Parameters:
+//   - bucket: The manifest bucket name.
+//   - key: The manifest object key.
+//   - objects: The objects of the job.
+//
+// Returns:
+//   - manifest: The manifest, use it with CreateJob.
+//   - err: An error if the operation fails.
+func (a awsS3Batch) WriteManifest(bucket, key string,
+	objects []S3BatchObject) (manifest S3BatchManifest, err error) {
+
+	// The keys are URL encoded
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	for _, o := range objects {
+		w.Write([]string{o.Bucket, url.PathEscape(o.Key)})
+	}
+	w.Flush()
+
+	out, err := a.s3.Client.PutObject(a.ctx, &s3.PutObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+		Body:   bytes.NewReader(buf.Bytes()),
+	})
+	if err != nil {
+		return
+	}
+	manifest = S3BatchManifest{
+		Bucket: bucket,
+		Key:    key,
+		ETag:   strings.Trim(aws.ToString(out.ETag), `"`),
+	}
+	return
+}
+
+// CreateJob creates the S3 Batch Operations job which executes the
+// operation for each object of the manifest. The job completion report is
+// written to the manifest bucket by default.
+//
+// Parameters:
+//   - roleArn: The ARN of the IAM role the job uses to execute the
+//     operation, read the manifest and write the report.
+//   - manifest: The manifest written by WriteManifest.
+//   - op: The job operation.
+//   - opts: The optional job parameters.
+//
+// Returns:
+//   - jobID: The job ID.
+//   - err: An error if the operation fails.
+func (a awsS3Batch) CreateJob(roleArn string, manifest S3BatchManifest,
+	op S3BatchOperation, opts ...S3BatchJobOptions) (jobID string,
+	err error) {
+
+	var o S3BatchJobOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	if o.Priority == 0 {
+		o.Priority = s3BatchPriority
+	}
+	if o.ReportBucket == "" {
+		o.ReportBucket = manifest.Bucket
+	}
+	if o.ReportPrefix == "" {
+		o.ReportPrefix = s3BatchReportPrefix
+	}
+	scope := types.JobReportScopeAllTasks
+	if o.ReportFailedOnly {
+		scope = types.JobReportScopeFailedTasksOnly
+	}
+
+	operation, err := op.operation()
+	if err != nil {
+		return
+	}
+	account, err := a.accountID()
+	if err != nil {
+		return
+	}
+	token := make([]byte, 16)
+	rand.Read(token)
+
+	out, err := a.Client.CreateJob(a.ctx, &s3control.CreateJobInput{
+		AccountId:            aws.String(account),
+		ClientRequestToken:   aws.String(hex.EncodeToString(token)),
+		RoleArn:              aws.String(roleArn),
+		Operation:            operation,
+		Priority:             aws.Int32(o.Priority),
+		ConfirmationRequired: aws.Bool(o.ConfirmationRequired),
+		Description:          optional(o.Description),
+		Manifest: &types.JobManifest{
+			Spec: &types.JobManifestSpec{
+				Format: types.JobManifestFormatS3BatchOperationsCsv20180820,
+				Fields: []types.JobManifestFieldName{
+					types.JobManifestFieldNameBucket,
+					types.JobManifestFieldNameKey,
+				},
+			},
+			Location: &types.JobManifestLocation{
+				ObjectArn: aws.String(s3BatchBucketARN(manifest.Bucket) +
+					"/" + manifest.Key),
+				ETag: aws.String(manifest.ETag),
+			},
+		},
+		Report: &types.JobReport{
+			Enabled:     true,
+			Bucket:      aws.String(s3BatchBucketARN(o.ReportBucket)),
+			Prefix:      aws.String(o.ReportPrefix),
+			Format:      types.JobReportFormatReportCsv20180820,
+			ReportScope: scope,
+		},
+	})
+	if err != nil {
+		return
+	}
+	jobID = aws.ToString(out.JobId)
+	return
+}
+
+// DescribeJob returns the job status and progress.
+//
+// Parameters:
+//   - jobID: The job ID.
+//
+// Returns:
+//   - job: The job.
+//   - err: An error if the operation fails. The error wraps ErrNotFound if
+//     the job does not exist.
+func (a awsS3Batch) DescribeJob(jobID string) (job S3BatchJob, err error) {
+	return a.describe(a.ctx, jobID)
+}
+
+// WaitForJob waits for the job to be completed, failed or cancelled. The
+// job status is polled with the delay doubled from 5 seconds up to 1 minute.
+// The job created with ConfirmationRequired waits in the Suspended status
+// until it is confirmed.
+//
+// Parameters:
+//   - ctx: The context to stop waiting.
+//   - jobID: The job ID.
+//
+// Returns:
+//   - job: The finished job. Its Failed field contains the number of the
+//     failed tasks, use GetReport to get them.
+//   - err: An error if the operation fails or ctx is canceled. The error
+//     wraps ErrS3BatchJobFailed if the job is failed or cancelled.
+func (a awsS3Batch) WaitForJob(ctx context.Context, jobID string) (
+	job S3BatchJob, err error) {
+
+	delay := s3BatchWaitDelay
+	for {
+		job, err = a.describe(ctx, jobID)
+		switch {
+		case err != nil && !IsRetryable(err):
+			return
+		case err == nil && job.Status == string(types.JobStatusComplete):
+			return
+		case err == nil && job.Done():
+			err = fmt.Errorf("%w: %s %s: %s", ErrS3BatchJobFailed, job.Status,
+				job.StatusReason, strings.Join(job.FailureReasons, ", "))
+			return
+		}
+
+		// Wait before next poll
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+			return
+		case <-clock(a.ctx).After(max(delay, RetryAfter(err))):
+		}
+		delay = min(delay*2, s3BatchWaitMaxDelay)
+	}
+}
+
+// GetReport returns the task results from the completion report of the
+// finished job.
+//
+// Parameters:
+//   - jobID: The job ID.
+//
+// Returns:
+//   - results: The task results.
+//   - err: An error if the operation fails. The error wraps ErrNotFound if
+//     the job or its report does not exist.
+func (a awsS3Batch) GetReport(jobID string) (results []S3BatchResult,
+	err error) {
+
+	job, err := a.describe(a.ctx, jobID)
+	if err != nil {
+		return
+	}
+
+	// Read the report manifest which lists the result CSV objects
+	data, err := a.s3.Get(job.ReportBucket,
+		path.Join(job.ReportPrefix, "job-"+jobID, "manifest.json"))
+	if err != nil {
+		return
+	}
+	var manifest struct {
+		Results []struct{ Bucket, Key string }
+	}
+	if err = json.Unmarshal(data, &manifest); err != nil {
+		return
+	}
+
+	// Read the result CSV objects
+	for _, r := range manifest.Results {
+		if data, err = a.s3.Get(r.Bucket, r.Key); err != nil {
+			return
+		}
+		var part []S3BatchResult
+		if part, err = s3BatchReport(data); err != nil {
+			return
+		}
+		results = append(results, part...)
+	}
+
+	return
+}
+
+// describe returns the job.
+func (a awsS3Batch) describe(ctx context.Context, jobID string) (
+	job S3BatchJob, err error) {
+
+	account, err := a.accountID()
+	if err != nil {
+		return
+	}
+	out, err := a.Client.DescribeJob(ctx, &s3control.DescribeJobInput{
+		AccountId: aws.String(account),
+		JobId:     aws.String(jobID),
+	})
+	if err != nil {
+		return
+	}
+	d := out.Job
+	if d == nil {
+		err = fmt.Errorf("%w: s3 batch job %s", ErrNotFound, jobID)
+		return
+	}
+	job = S3BatchJob{
+		ID:           aws.ToString(d.JobId),
+		Status:       string(d.Status),
+		StatusReason: aws.ToString(d.StatusUpdateReason),
+		CreatedAt:    aws.ToTime(d.CreationTime),
+		TerminatedAt: aws.ToTime(d.TerminationDate),
+	}
+	for _, f := range d.FailureReasons {
+		job.FailureReasons = append(job.FailureReasons,
+			aws.ToString(f.FailureReason))
+	}
+	if p := d.ProgressSummary; p != nil {
+		job.Total = aws.ToInt64(p.TotalNumberOfTasks)
+		job.Succeeded = aws.ToInt64(p.NumberOfTasksSucceeded)
+		job.Failed = aws.ToInt64(p.NumberOfTasksFailed)
+	}
+	if r := d.Report; r != nil {
+		job.ReportBucket = strings.TrimPrefix(aws.ToString(r.Bucket),
+			"arn:aws:s3:::")
+		job.ReportPrefix = aws.ToString(r.Prefix)
+	}
+	return
+}
+
+// accountID returns the account ID of the jobs. The account ID of the
+// credentials is got from STS once.
+func (a awsS3Batch) accountID() (id string, err error) {
+	a.account.Lock()
+	defer a.account.Unlock()
+	if a.account.id == "" {
+		var identity CallerIdentity
+		if identity, err = a.sts.GetCallerIdentity(); err != nil {
+			return
+		}
+		a.account.id = identity.Account
+	}
+	id = a.account.id
+	return
+}
+
+// s3BatchReport parses the completion report CSV. The columns are Bucket,
+// Key, VersionId, TaskStatus, ErrorCode, HTTPStatusCode and ResultMessage,
+// the keys are URL encoded.
+func s3BatchReport(data []byte) (results []S3BatchResult, err error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	for {
+		var record []string
+		record, err = r.Read()
+		if err == io.EOF {
+			err = nil
+			return
+		}
+		if err != nil {
+			return
+		}
+		record = append(record, make([]string, 7)...)[:7]
+		key, e := url.QueryUnescape(record[1])
+		if e != nil {
+			key = record[1]
+		}
+		status, _ := strconv.Atoi(record[5])
+		results = append(results, S3BatchResult{
+			Bucket:     record[0],
+			Key:        key,
+			VersionID:  record[2],
+			Status:     record[3],
+			ErrorCode:  record[4],
+			HTTPStatus: status,
+			Message:    record[6],
+		})
+	}
+}
+
+// s3BatchBucketARN returns the bucket ARN of the bucket name or ARN.
+func s3BatchBucketARN(bucket string) string {
+	if strings.HasPrefix(bucket, "arn:") {
+		return bucket
+	}
+	return "arn:aws:s3:::" + bucket
+}
@@ -0,0 +1,66 @@
+package aws
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestS3BatchJob checks S3 Batch Operations job creation and completion
+// report
+func TestS3BatchJob(t *testing.T) {
+
+	const job = `<DescribeJobResult><Job><JobId>j1</JobId>` +
+		`<Status>Complete</Status><ProgressSummary>` +
+		`<TotalNumberOfTasks>2</TotalNumberOfTasks>` +
+		`<NumberOfTasksSucceeded>1</NumberOfTasksSucceeded>` +
+		`<NumberOfTasksFailed>1</NumberOfTasksFailed></ProgressSummary>` +
+		`<Report><Enabled>true</Enabled><Bucket>arn:aws:s3:::data</Bucket>` +
+		`<Prefix>reports</Prefix></Report></Job></DescribeJobResult>`
+	client := &pagesHTTPClient{bodies: []string{
+		`<GetCallerIdentityResponse><GetCallerIdentityResult>` +
+			`<Account>111122223333</Account></GetCallerIdentityResult>` +
+			`</GetCallerIdentityResponse>`,
+		`<CreateJobResult><JobId>j1</JobId></CreateJobResult>`,
+		job,
+		job,
+		`{"Results":[{"TaskExecutionStatus":"failed","Bucket":"data",` +
+			`"Key":"reports/job-j1/results/r1.csv"}]}`,
+		"data,a%20b.txt,,succeeded,,200,Successful\n" +
+			"data,c.txt,,failed,AccessDenied,403,Access Denied\n",
+	}}
+	a := newPagesTestAws(client)
+
+	jobID, err := a.S3Batch.CreateJob("arn:aws:iam::1:role/batch",
+		S3BatchManifest{Bucket: "data", Key: "manifest.csv", ETag: "e1"},
+		S3BatchOperation{Tags: map[string]string{"tier": "cold"}})
+	if err != nil || jobID != "j1" {
+		t.Fatal("wrong create job:", jobID, err)
+	}
+	for _, s := range []string{"<Key>tier</Key>",
+		"<ObjectArn>arn:aws:s3:::data/manifest.csv</ObjectArn>",
+		"<Bucket>arn:aws:s3:::data</Bucket>"} {
+		if !strings.Contains(client.requests[1], s) {
+			t.Error("wrong create job request:", client.requests[1])
+		}
+	}
+
+	j, err := a.S3Batch.DescribeJob(jobID)
+	if err != nil || !j.Done() || j.Failed != 1 || j.ReportBucket != "data" {
+		t.Error("wrong job:", j, err)
+	}
+
+	results, err := a.S3Batch.GetReport(jobID)
+	if err != nil || len(results) != 2 || results[0].Key != "a b.txt" ||
+		results[1].ErrorCode != "AccessDenied" ||
+		results[1].HTTPStatus != 403 {
+		t.Error("wrong report:", results, err)
+	}
+
+	// One operation is required
+	_, err = a.S3Batch.CreateJob("arn:aws:iam::1:role/batch",
+		S3BatchManifest{Bucket: "data", Key: "manifest.csv"},
+		S3BatchOperation{CopyTo: "backup", LambdaARN: "arn:fn"})
+	if err == nil {
+		t.Error("job with two operations is created")
+	}
+}
@@ -0,0 +1,65 @@
+package aws
+
+import (
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// S3Key is the S3 object key in the bucket.
+type S3Key struct {
+	// Bucket is the name of the S3 bucket.
+	Bucket string
+
+	// Key is the key of the S3 object.
+	Key string
+}
+
+// S3Cache is the cache of S3 objects content and metadata. The objects
+// changed or deleted by awsS3 Set, Delete, DeleteObjects, Copy and Move
+// functions are removed from the cache.
+type S3Cache struct {
+	// Objects is the S3 objects content cache.
+	Objects *LookupCache[S3Key, []byte]
+
+	// Heads is the S3 objects metadata cache.
+	Heads *LookupCache[S3Key, *s3.HeadObjectOutput]
+}
+
+// newS3Cache creates S3 objects cache.
+func newS3Cache(a *awsS3) *S3Cache {
+	return &S3Cache{
+		Objects: NewLookupCache(
+			func(k S3Key) ([]byte, error) { return a.Get(k.Bucket, k.Key) },
+			IsNotFound,
+		),
+		Heads: NewLookupCache(
+			func(k S3Key) (*s3.HeadObjectOutput, error) {
+				return a.Info(k.Bucket, k.Key)
+			},
+			IsNotFound,
+		),
+	}
+}
+
+// Get returns content of S3 object from the cache or gets it from S3.
+func (c *S3Cache) Get(bucket, objectName string) (data []byte, err error) {
+	return c.Objects.Get(S3Key{bucket, objectName})
+}
+
+// Info returns metadata of S3 object from the cache or gets it from S3.
+func (c *S3Cache) Info(bucket, objectName string) (
+	result *s3.HeadObjectOutput, err error) {
+
+	return c.Heads.Get(S3Key{bucket, objectName})
+}
+
+// Delete removes S3 object content and metadata from the cache.
+func (c *S3Cache) Delete(bucket, objectName string) {
+	c.Objects.Delete(S3Key{bucket, objectName})
+	c.Heads.Delete(S3Key{bucket, objectName})
+}
+
+// Clear removes all S3 objects from the cache.
+func (c *S3Cache) Clear() {
+	c.Objects.Clear()
+	c.Heads.Clear()
+}
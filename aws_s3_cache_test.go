@@ -0,0 +1,104 @@
+package aws
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// TestS3Cache checks the S3 objects content and metadata are read once and
+// the objects changed by Set, Delete and Copy are read again
+func TestS3Cache(t *testing.T) {
+
+	fake := NewFakeS3("bucket")
+	var requests []string
+	cfg := fake.Config()
+	cfg.HTTPClient = smithyhttp.ClientDoFunc(
+		func(r *http.Request) (*http.Response, error) {
+			requests = append(requests, r.Method)
+			return fake.Do(r)
+		})
+	a := NewFromConfig(cfg)
+	c := a.S3.Cache
+	if err := a.S3.Set("bucket", "a", []byte("v1")); err != nil {
+		t.Fatal("set:", err)
+	}
+
+	// check checks the requests sent after the previous check
+	check := func(step, want string) {
+		t.Helper()
+		if got := strings.Join(requests, ","); got != want {
+			t.Errorf("%s: wrong requests %q, want %q", step, got, want)
+		}
+		requests = nil
+	}
+	check("set", "PUT")
+
+	// Miss and hit
+	for range 2 {
+		data, err := c.Get("bucket", "a")
+		if err != nil || string(data) != "v1" {
+			t.Fatal("wrong cached object:", string(data), err)
+		}
+		info, err := c.Info("bucket", "a")
+		if err != nil || aws.ToInt64(info.ContentLength) != 2 {
+			t.Fatal("wrong cached info:", info, err)
+		}
+	}
+	check("get", "GET,HEAD")
+	if c.Objects.Len() != 1 || c.Heads.Len() != 1 ||
+		!c.Objects.Contains(S3Key{Bucket: "bucket", Key: "a"}) {
+		t.Error("object is not cached")
+	}
+
+	// Set removes the object from cache
+	if err := a.S3.Set("bucket", "a", []byte("v2")); err != nil {
+		t.Fatal("set:", err)
+	}
+	data, _ := c.Get("bucket", "a")
+	info, _ := c.Info("bucket", "a")
+	if string(data) != "v2" || aws.ToInt64(info.ContentLength) != 2 {
+		t.Error("wrong object after set:", string(data))
+	}
+	check("set", "PUT,GET,HEAD")
+
+	// Copy removes the destination object from cache
+	c.Get("bucket", "b")
+	check("missing", "GET")
+	if err := a.S3.Copy("bucket", "a", "bucket", "b"); err != nil {
+		t.Fatal("copy:", err)
+	}
+	data, _ = c.Get("bucket", "b")
+	c.Get("bucket", "b")
+	if string(data) != "v2" {
+		t.Error("wrong object after copy:", string(data))
+	}
+	check("copy", "HEAD,PUT,GET")
+
+	// Delete removes the object from cache, the not found result is cached
+	if err := a.S3.Delete("bucket", "a"); err != nil {
+		t.Fatal("delete:", err)
+	}
+	for range 2 {
+		if _, err := c.Get("bucket", "a"); !IsNotFound(err) {
+			t.Error("wrong deleted object error:", err)
+		}
+	}
+	check("delete", "DELETE,GET")
+
+	// DeleteObjects removes the objects from cache
+	if _, err := a.S3.DeleteObjects("bucket", "b"); err != nil {
+		t.Fatal("delete objects:", err)
+	}
+	if _, err := c.Get("bucket", "b"); !IsNotFound(err) {
+		t.Error("wrong deleted objects error:", err)
+	}
+	check("delete objects", "POST,GET")
+
+	if c.Clear(); c.Objects.Len() != 0 || c.Heads.Len() != 0 {
+		t.Error("cache is not cleared")
+	}
+}
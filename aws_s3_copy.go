@@ -0,0 +1,281 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"golang.org/x/sync/errgroup"
+)
+
+// ErrS3CopyMismatch is returned by S3 Move when the copied object does not
+// match the source object.
+var ErrS3CopyMismatch = errors.New("s3 copied object does not match source")
+
+const (
+	// s3CopyPartSize is the default size of the part copied by the multipart
+	// copy.
+	s3CopyPartSize = 512 << 20
+
+	// s3CopyConcurrency is the default number of the parts copied
+	// concurrently.
+	s3CopyConcurrency = 8
+
+	// s3MaxParts is the maximum number of the multipart upload parts.
+	s3MaxParts = 10_000
+)
+
+// S3CopyOptions are the optional parameters of S3 Copy.
+type S3CopyOptions struct {
+	// MultipartThreshold is the object size above which the object is copied
+	// by the multipart copy. Default and maximum is 5 GiB, the maximum size
+	// of the object copied by one CopyObject request.
+	MultipartThreshold int64
+
+	// PartSize is the size of the part copied by one UploadPartCopy request.
+	// Default is 512 MiB, it is increased if the object has more than 10000
+	// parts.
+	PartSize int64
+
+	// Concurrency is the number of the parts copied concurrently. Default
+	// is 8.
+	Concurrency int
+}
+
+// Copy copies the S3 object to the destination bucket and key on the S3
+// side without downloading the object content. The objects larger than 5 GiB
+// are copied by the multipart copy with the concurrent parts:
+//
+//	err := a.S3.Copy("bucket", "data/report.csv", "backup", "report.csv")
+//
+// The content type and metadata of the source object are copied. The source
+// is copied with its ETag, so the copy fails with the PreconditionFailed
+// error if the source object is changed during the copy. The SSE-C encrypted
+// objects are not supported, S3 rejects their HeadObject and copy requests
+// without the customer key headers.
+//
+// Parameters:
+//   - srcBucket: The name of the source S3 bucket.
+//   - srcKey: The key of the source S3 object.
+//   - dstBucket: The name of the destination S3 bucket.
+//   - dstKey: The key of the destination S3 object.
+//   - opts: The optional copy parameters.
+//
+// Returns:
+//   - err: An error if the operation fails. The error wraps ErrNotFound if
+//     the source object does not exist.
+func (a awsS3) Copy(srcBucket, srcKey, dstBucket, dstKey string,
+	opts ...S3CopyOptions) (err error) {
+
+	// Get the source object size and ETag
+	info, err := a.Info(srcBucket, srcKey)
+	if err != nil {
+		return
+	}
+	return a.copy(info, srcBucket, srcKey, dstBucket, dstKey, opts)
+}
+
+// Move renames the S3 object in the bucket. The object is copied to the new
+// key on the S3 side by Copy, the copy is verified and the old object is
+// deleted:
+//
+//	err := a.S3.Move("bucket", "incoming/data.csv", "processed/data.csv")
+//
+// The old object is not deleted if the copy fails or the copy size or ETag
+// does not match the old object. The ETags of the SSE-KMS encrypted objects
+// are not the content MD5 and differ between the object and its copy, so
+// these objects are verified by the size only. Their content is guaranteed
+// by the copy of the old object ETag. The SSE-C encrypted objects are not
+// supported, as by Copy.
+//
+// Parameters:
+//   - bucket: The name of the S3 bucket.
+//   - oldKey: The key of the S3 object to move.
+//   - newKey: The new key of the S3 object.
+//   - opts: The optional copy parameters.
+//
+// Returns:
+//   - err: An error if the operation fails. The error wraps ErrNotFound if
+//     the object does not exist, or ErrS3CopyMismatch if the copy is not
+//     verified.
+func (a awsS3) Move(bucket, oldKey, newKey string,
+	opts ...S3CopyOptions) (err error) {
+
+	if oldKey == newKey {
+		return
+	}
+
+	// Copy object
+	info, err := a.Info(bucket, oldKey)
+	if err != nil {
+		return
+	}
+	err = a.copy(info, bucket, oldKey, bucket, newKey, opts)
+	if err != nil {
+		return
+	}
+
+	// The copy is not created in the dry-run mode
+	if isDryRun(a.ctx) {
+		return a.Delete(bucket, oldKey)
+	}
+
+	// Verify the copy size and ETag, the multipart and encrypted objects
+	// ETags are not comparable
+	copied, err := a.Info(bucket, newKey)
+	if err != nil {
+		return
+	}
+	srcETag, dstETag := aws.ToString(info.ETag), aws.ToString(copied.ETag)
+	comparable := !strings.Contains(srcETag, "-") &&
+		!strings.Contains(dstETag, "-") &&
+		!s3ETagEncrypted(info.ServerSideEncryption) &&
+		!s3ETagEncrypted(copied.ServerSideEncryption)
+	if aws.ToInt64(copied.ContentLength) != aws.ToInt64(info.ContentLength) ||
+		(comparable && srcETag != dstETag) {
+		err = fmt.Errorf("s3 move %s/%s to %s: %w", bucket, oldKey, newKey,
+			ErrS3CopyMismatch)
+		return
+	}
+
+	// Delete old object
+	err = a.Delete(bucket, oldKey)
+
+	return
+}
+
+// copy copies the source object with the info to the destination.
+func (a awsS3) copy(info *s3.HeadObjectOutput, srcBucket, srcKey, dstBucket,
+	dstKey string, opts []S3CopyOptions) (err error) {
+
+	var o S3CopyOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	if o.MultipartThreshold <= 0 || o.MultipartThreshold > s3MaxSingleSize {
+		o.MultipartThreshold = s3MaxSingleSize
+	}
+	if o.PartSize <= 0 {
+		o.PartSize = s3CopyPartSize
+	}
+	if o.Concurrency <= 0 {
+		o.Concurrency = s3CopyConcurrency
+	}
+
+	source := s3CopySource(srcBucket, srcKey)
+
+	// Remove changed object from cache
+	if a.Cache != nil {
+		defer a.Cache.Delete(dstBucket, dstKey)
+	}
+
+	// Copy object by one request, the multipart upload is not created in the
+	// dry-run mode
+	size := aws.ToInt64(info.ContentLength)
+	if size <= o.MultipartThreshold || isDryRun(a.ctx) {
+		_, err = a.Client.CopyObject(a.ctx, &s3.CopyObjectInput{
+			Bucket:            aws.String(dstBucket),
+			Key:               aws.String(dstKey),
+			CopySource:        aws.String(source),
+			CopySourceIfMatch: info.ETag,
+		})
+		return
+	}
+
+	// Multipart copy
+	upload, err := a.Client.CreateMultipartUpload(a.ctx,
+		&s3.CreateMultipartUploadInput{
+			Bucket:      aws.String(dstBucket),
+			Key:         aws.String(dstKey),
+			ContentType: info.ContentType,
+			Metadata:    info.Metadata,
+		})
+	if err != nil {
+		return
+	}
+	parts, err := a.copyParts(upload, source, info.ETag, size, o)
+	if err != nil {
+		// Abort the upload to free the copied parts storage
+		a.Client.AbortMultipartUpload(context.WithoutCancel(a.ctx),
+			&s3.AbortMultipartUploadInput{
+				Bucket:   upload.Bucket,
+				Key:      upload.Key,
+				UploadId: upload.UploadId,
+			})
+		return
+	}
+	_, err = a.Client.CompleteMultipartUpload(a.ctx,
+		&s3.CompleteMultipartUploadInput{
+			Bucket:          upload.Bucket,
+			Key:             upload.Key,
+			UploadId:        upload.UploadId,
+			MultipartUpload: &types.CompletedMultipartUpload{Parts: parts},
+		})
+
+	return
+}
+
+// copyParts copies the source object byte ranges to the multipart upload
+// parts concurrently and returns the completed parts in the parts order.
+func (a awsS3) copyParts(upload *s3.CreateMultipartUploadOutput, source string,
+	etag *string, size int64, o S3CopyOptions) (parts []types.CompletedPart,
+	err error) {
+
+	partSize := max(o.PartSize, (size+s3MaxParts-1)/s3MaxParts)
+	parts = make([]types.CompletedPart, (size+partSize-1)/partSize)
+
+	// Copy the parts, the first error cancels other parts
+	g, ctx := errgroup.WithContext(a.ctx)
+	g.SetLimit(o.Concurrency)
+	for i := range parts {
+		if ctx.Err() != nil {
+			break
+		}
+		first := int64(i) * partSize
+		last := min(first+partSize, size) - 1
+		g.Go(func() error {
+			out, err := a.Client.UploadPartCopy(ctx, &s3.UploadPartCopyInput{
+				Bucket:            upload.Bucket,
+				Key:               upload.Key,
+				UploadId:          upload.UploadId,
+				PartNumber:        aws.Int32(int32(i + 1)),
+				CopySource:        aws.String(source),
+				CopySourceIfMatch: etag,
+				CopySourceRange: aws.String(
+					fmt.Sprintf("bytes=%d-%d", first, last)),
+			})
+			if err != nil {
+				return err
+			}
+			parts[i] = types.CompletedPart{
+				PartNumber: aws.Int32(int32(i + 1)),
+				ETag:       out.CopyPartResult.ETag,
+			}
+			return nil
+		})
+	}
+	err = g.Wait()
+
+	return
+}
+
+// s3ETagEncrypted returns true if the ETag of the object encrypted with the
+// server-side encryption is not the content MD5.
+func s3ETagEncrypted(sse types.ServerSideEncryption) bool {
+	return sse == types.ServerSideEncryptionAwsKms ||
+		sse == types.ServerSideEncryptionAwsKmsDsse
+}
+
+// s3CopySource returns the URL-encoded "bucket/key" copy source.
+func s3CopySource(bucket, key string) string {
+	segments := strings.Split(key, "/")
+	for i := range segments {
+		segments[i] = url.PathEscape(segments[i])
+	}
+	return bucket + "/" + strings.Join(segments, "/")
+}
@@ -0,0 +1,165 @@
+package aws
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// TestS3Copy checks the object is copied by one request and by the
+// multipart copy
+func TestS3Copy(t *testing.T) {
+
+	fake := NewFakeS3("bucket", "backup")
+	a := NewFromConfig(fake.Config())
+	data := bytes.Repeat([]byte("0123456789"), 1050)
+	if err := a.S3.Set("bucket", "dir/large file", data); err != nil {
+		t.Fatal("set:", err)
+	}
+
+	// Single request copy
+	if err := a.S3.Copy("bucket", "dir/large file", "backup",
+		"copy"); err != nil {
+		t.Fatal("copy:", err)
+	}
+	if got, _ := a.S3.Get("backup", "copy"); !bytes.Equal(got, data) {
+		t.Error("wrong copied data")
+	}
+
+	// Multipart copy
+	err := a.S3.Copy("bucket", "dir/large file", "backup", "parts",
+		S3CopyOptions{MultipartThreshold: 1000, PartSize: 1000,
+			Concurrency: 3})
+	if err != nil {
+		t.Fatal("multipart copy:", err)
+	}
+	if got, _ := a.S3.Get("backup", "parts"); !bytes.Equal(got, data) {
+		t.Error("wrong multipart copied data")
+	}
+	info, _ := a.S3.Info("backup", "parts")
+	if !strings.HasSuffix(aws.ToString(info.ETag), `-11"`) ||
+		fake.Uploads() != 0 {
+		t.Error("wrong multipart copy:", aws.ToString(info.ETag),
+			fake.Uploads())
+	}
+
+	// Missing source and changed source
+	if err = a.S3.Copy("bucket", "none", "backup", "none"); !IsNotFound(err) {
+		t.Error("wrong missing source error:", err)
+	}
+	upload, _ := a.S3.Client.CreateMultipartUpload(a.S3.ctx,
+		&s3.CreateMultipartUploadInput{Bucket: aws.String("backup"),
+			Key: aws.String("changed")})
+	_, err = a.S3.copyParts(upload, s3CopySource("bucket", "dir/large file"),
+		aws.String(`"changed"`), int64(len(data)), S3CopyOptions{
+			PartSize: 1000, Concurrency: 1})
+	var e *Error
+	if !errors.As(err, &e) || e.Code() != "PreconditionFailed" {
+		t.Error("wrong changed source error:", err)
+	}
+
+	if s := s3CopySource("b", "a b/c?d"); s != "b/a%20b/c%3Fd" {
+		t.Error("wrong copy source:", s)
+	}
+}
+
+// TestS3Move checks the object is renamed and the old object is deleted
+func TestS3Move(t *testing.T) {
+
+	fake := NewFakeS3("bucket")
+	a := NewFromConfig(fake.Config())
+	data := bytes.Repeat([]byte("data"), 500)
+	if err := a.S3.Set("bucket", "incoming/a", data); err != nil {
+		t.Fatal("set:", err)
+	}
+
+	if err := a.S3.Move("bucket", "incoming/a", "incoming/a"); err != nil {
+		t.Error("move to same key:", err)
+	}
+	if err := a.S3.Move("bucket", "incoming/a", "processed/a"); err != nil {
+		t.Fatal("move:", err)
+	}
+	err := a.S3.Move("bucket", "processed/a", "archive/a",
+		S3CopyOptions{MultipartThreshold: 1000, PartSize: 1000})
+	if err != nil {
+		t.Fatal("multipart move:", err)
+	}
+	if keys := fake.Keys("bucket"); strings.Join(keys, ",") != "archive/a" {
+		t.Error("wrong keys after move:", keys)
+	}
+	if got, _ := a.S3.Get("bucket", "archive/a"); !bytes.Equal(got, data) {
+		t.Error("wrong moved data")
+	}
+
+	if err = a.S3.Move("bucket", "none", "other"); !IsNotFound(err) {
+		t.Error("wrong missing object error:", err)
+	}
+}
+
+// TestS3MoveEncrypted checks the SSE-KMS object which copy has the other
+// ETag is moved
+func TestS3MoveEncrypted(t *testing.T) {
+
+	fake := NewFakeS3("bucket")
+	a := NewFromConfig(fake.Config())
+	_, err := a.S3.Client.PutObject(a.S3.ctx, &s3.PutObjectInput{
+		Bucket:               aws.String("bucket"),
+		Key:                  aws.String("incoming/a"),
+		Body:                 strings.NewReader("secret"),
+		ServerSideEncryption: types.ServerSideEncryptionAwsKms,
+	})
+	if err != nil {
+		t.Fatal("put:", err)
+	}
+	if err = a.S3.Move("bucket", "incoming/a", "processed/a"); err != nil {
+		t.Fatal("move:", err)
+	}
+	if keys := fake.Keys("bucket"); strings.Join(keys, ",") != "processed/a" {
+		t.Error("wrong keys after move:", keys)
+	}
+	info, _ := a.S3.Info("bucket", "processed/a")
+	if info.ServerSideEncryption != types.ServerSideEncryptionAwsKms ||
+		aws.ToString(info.ETag) == fakeS3ETag([]byte("secret")) {
+		t.Error("wrong encrypted copy:", info.ServerSideEncryption,
+			aws.ToString(info.ETag))
+	}
+
+	if !s3ETagEncrypted(types.ServerSideEncryptionAwsKmsDsse) ||
+		s3ETagEncrypted(types.ServerSideEncryptionAes256) ||
+		s3ETagEncrypted("") {
+		t.Error("wrong encrypted ETag check")
+	}
+}
+
+// TestS3MoveSSEC checks the SSE-C object which HeadObject is rejected without
+// the customer key is not moved and not deleted
+func TestS3MoveSSEC(t *testing.T) {
+
+	fake := NewFakeS3("bucket")
+	var methods []string
+	cfg := fake.Config()
+	cfg.HTTPClient = smithyhttp.ClientDoFunc(
+		func(r *http.Request) (*http.Response, error) {
+			methods = append(methods, r.Method)
+			if strings.HasSuffix(r.URL.Path, "/customer") {
+				return &http.Response{StatusCode: http.StatusBadRequest,
+					Header: http.Header{}, Body: http.NoBody, Request: r}, nil
+			}
+			return fake.Do(r)
+		})
+	a := NewFromConfig(cfg)
+
+	if err := a.S3.Move("bucket", "customer", "moved"); err == nil {
+		t.Error("SSE-C object is moved")
+	}
+	if m := strings.Join(methods, ","); m != "HEAD" {
+		t.Error("wrong SSE-C move requests:", m)
+	}
+}
@@ -0,0 +1,60 @@
+package aws
+
+import (
+	"bytes"
+	"errors"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+// TestS3Download checks the object is downloaded by the concurrent parts
+func TestS3Download(t *testing.T) {
+
+	fake := NewFakeS3("bucket")
+	a := NewFromConfig(fake.Config())
+	data := make([]byte, 10_500)
+	for i := range data {
+		data[i] = byte(rand.IntN(256))
+	}
+	if err := a.S3.Set("bucket", "large", data); err != nil {
+		t.Fatal("set:", err)
+	}
+	if err := a.S3.Set("bucket", "empty", nil); err != nil {
+		t.Fatal("set empty:", err)
+	}
+
+	f, err := os.Create(filepath.Join(t.TempDir(), "large"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	n, err := a.S3.Download("bucket", "large", f,
+		S3DownloadOptions{PartSize: 1000, Concurrency: 3})
+	if err != nil || n != int64(len(data)) {
+		t.Fatal("download:", n, err)
+	}
+	got, _ := os.ReadFile(f.Name())
+	if !bytes.Equal(got, data) {
+		t.Error("wrong downloaded data")
+	}
+
+	// Empty and missing objects
+	if n, err = a.S3.Download("bucket", "empty", f); err != nil || n != 0 {
+		t.Error("wrong empty download:", n, err)
+	}
+	if _, err = a.S3.Download("bucket", "none", f); !IsNotFound(err) {
+		t.Error("wrong missing download:", err)
+	}
+
+	// Changed object
+	_, err = a.S3.downloadPart(a.S3.ctx, "bucket", "large",
+		aws.String(`"changed"`), 0, 9, f)
+	var e *Error
+	if !errors.As(err, &e) || e.Code() != "PreconditionFailed" {
+		t.Error("wrong changed object error:", err)
+	}
+}
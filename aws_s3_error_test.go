@@ -1,8 +1,13 @@
 package aws
 
 import (
+	"errors"
 	"fmt"
+	"net/http"
 	"testing"
+
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
 )
 
 // TestGetS3 checks aws error when get S3 data
@@ -27,17 +32,98 @@ func TestGetS3Error(t *testing.T) {
 
 		t.Log(err)
 
-		// Get aws s3 error
-		resErr, _ := a.S3.ResponseError(err)
-		t.Logf("\nError: %s\nServiceHostID: %s\nServiceRequestID: %s\n", resErr.Error(), resErr.ServiceHostID(), resErr.ServiceRequestID())
-
-		// Get aws common error
-		awsErr, _ := a.AwsError(err)
-		t.Logf("\nErrorCode: %s, ErrorMessage: %s, ErrorFault: %d", awsErr.ErrorCode(), awsErr.ErrorMessage(), awsErr.ErrorFault())
+		// Get aws error
+		var awsErr *Error
+		if !errors.As(err, &awsErr) {
+			return
+		}
+		t.Logf("\nErrorCode: %s, ErrorMessage: %s\nHTTPStatus: %d\nRequestID: %s\nHostID: %s\n", awsErr.Code(), awsErr.Message(), awsErr.HTTPStatus(), awsErr.RequestID(), awsErr.HostID())
 
-		// The awsErr.ErrorCode() return responsible error code
+		// The awsErr.Code() return responsible error code
 
 		return
 	}
 	t.Log(len(data))
 }
+
+// TestIsErrors checks the error classification helpers
+func TestIsErrors(t *testing.T) {
+
+	apiErr := func(code string) error {
+		return fmt.Errorf("operation: %w", &smithy.GenericAPIError{Code: code})
+	}
+	statusErr := &smithyhttp.ResponseError{
+		Response: &smithyhttp.Response{Response: &http.Response{StatusCode: 404}},
+		Err:      errors.New("head object"),
+	}
+
+	tests := []struct {
+		name string
+		is   func(error) bool
+		err  error
+		want bool
+	}{
+		{"NoSuchKey", IsNotFound, apiErr("NoSuchKey"), true},
+		{"HeadObject 404", IsNotFound, statusErr, true},
+		{"UserNotFoundException", IsNotFound, apiErr("UserNotFoundException"), true},
+		{"ErrCognitoUserNotFound", IsNotFound, ErrCognitoUserNotFound, true},
+		{"AccessDenied not found", IsNotFound, apiErr("AccessDenied"), false},
+		{"TooManyRequestsException", IsThrottled, apiErr("TooManyRequestsException"), true},
+		{"SlowDown", IsThrottled, apiErr("SlowDown"), true},
+		{"AccessDenied", IsAccessDenied, apiErr("AccessDenied"), true},
+		{"ExpiredToken", IsAccessDenied, apiErr("ExpiredToken"), true},
+		{"nil", IsAccessDenied, nil, false},
+		{"not aws error", IsThrottled, errors.New("an error"), false},
+	}
+	for _, test := range tests {
+		if got := test.is(test.err); got != test.want {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+// TestTranslateError checks service errors translation to sentinel errors
+func TestTranslateError(t *testing.T) {
+
+	tests := []struct {
+		code     string
+		sentinel error
+	}{
+		{"NoSuchKey", ErrNotFound},
+		{"UserNotFoundException", ErrNotFound},
+		{"AccessDenied", ErrAccessDenied},
+		{"TooManyRequestsException", ErrThrottled},
+		{"SlowDown", ErrThrottled},
+		{"PreconditionFailed", ErrPreconditionFailed},
+		{"ResourceConflictException", ErrConflict},
+	}
+	for _, test := range tests {
+		apiErr := &smithy.GenericAPIError{Code: test.code}
+		err := translateError(fmt.Errorf("operation: %w", apiErr))
+		if !errors.Is(err, test.sentinel) {
+			t.Errorf("%s is not %v", test.code, test.sentinel)
+		}
+		if a, ok := (Aws{}).AwsError(err); !ok || a.ErrorCode() != test.code {
+			t.Errorf("%s original error is lost", test.code)
+		}
+		if translateError(err) != err {
+			t.Errorf("%s is translated twice", test.code)
+		}
+	}
+
+	// Unknown service errors have no sentinel
+	err := translateError(&smithy.GenericAPIError{Code: "Unknown"})
+	var awsErr *Error
+	if !errors.As(err, &awsErr) || awsErr.Code() != "Unknown" {
+		t.Error("unknown service error is not translated")
+	}
+	if IsNotFound(err) || IsThrottled(err) || IsAccessDenied(err) {
+		t.Error("unknown service error has sentinel")
+	}
+
+	// Not service errors are not changed
+	err = errors.New("an error")
+	if translateError(err) != err {
+		t.Error("not service error is translated")
+	}
+}
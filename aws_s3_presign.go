@@ -0,0 +1,234 @@
+package aws
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+const (
+	// s3PresignExpires is the default presigned request expiration.
+	s3PresignExpires = 15 * time.Minute
+
+	// s3PostFilename is the POST form upload key variable replaced by S3
+	// with the uploaded file name.
+	s3PostFilename = "${filename}"
+
+	// s3MaxSingleSize is the maximum size of the object uploaded or copied
+	// by one request.
+	s3MaxSingleSize = 5 << 30
+)
+
+// S3PresignOptions are the optional parameters of S3 PresignPut and
+// PresignPost.
+type S3PresignOptions struct {
+	// Expires is the presigned request expiration. Default is 15 minutes.
+	Expires time.Duration
+
+	// ContentType is the required content type of the uploaded object. The
+	// PresignPost content type may end with "*" to allow the content types
+	// with the prefix, for example "image/*".
+	ContentType string
+
+	// MinSize is the minimum size of the object uploaded by PresignPost.
+	MinSize int64
+
+	// MaxSize is the maximum size of the object uploaded by PresignPost.
+	// Zero means no limit.
+	MaxSize int64
+
+	// Metadata is the required metadata of the uploaded object.
+	Metadata map[string]string
+}
+
+// S3PresignedPost is the browser-upload POST form of PresignPost. The form
+// is posted to the URL with the Fields and the "file" field with the object
+// content last.
+type S3PresignedPost struct {
+	// URL is the form action URL.
+	URL string
+
+	// Fields are the form fields.
+	Fields map[string]string
+}
+
+// PresignPut returns the presigned URL which uploads the object with the
+// HTTP PUT request without the AWS credentials. The request must contain the
+// returned headers, for example the Content-Type header if the ContentType
+// option is set:
+//
+//	url, header, err := a.S3.PresignPut("bucket", "uploads/photo.jpg",
+//		aws.S3PresignOptions{ContentType: "image/jpeg"})
+//
+// Parameters:
+//   - bucket: The name of the S3 bucket.
+//   - objectName: The key of the S3 object.
+//   - opts: The optional presign parameters, the MinSize and MaxSize are
+//     not used.
+//
+// Returns:
+//   - url: The presigned URL.
+//   - header: The headers which must be sent with the request.
+//   - err: An error if the operation fails.
+func (a awsS3) PresignPut(bucket, objectName string,
+	opts ...S3PresignOptions) (url string, header http.Header, err error) {
+
+	o := s3PresignOptions(opts)
+	req, err := s3.NewPresignClient(a.Client).PresignPutObject(a.ctx,
+		&s3.PutObjectInput{
+			Bucket:      aws.String(bucket),
+			Key:         aws.String(objectName),
+			ContentType: optional(o.ContentType),
+			Metadata:    o.Metadata,
+		},
+		s3.WithPresignExpires(o.Expires),
+		func(po *s3.PresignOptions) {
+			if clk := clock(a.ctx); clk != SystemClock {
+				po.Presigner = clockPresigner{clk, v4.NewSigner(
+					func(so *v4.SignerOptions) {
+						so.DisableURIPathEscaping = true
+					})}
+			}
+		},
+	)
+	if err != nil {
+		return
+	}
+	url = req.URL
+	header = req.SignedHeader.Clone()
+	header.Del("Host")
+	if o.ContentType != "" {
+		header.Set("Content-Type", o.ContentType)
+	}
+	return
+}
+
+// PresignPost returns the browser-upload POST form which uploads the object
+// without the AWS credentials. The form policy limits the object size,
+// content type and metadata by the options. The key ending with
+// "${filename}" allows any key with the prefix, S3 replaces the variable
+// with the uploaded file name:
+//
+//	post, err := a.S3.PresignPost("bucket", "uploads/${filename}",
+//		aws.S3PresignOptions{ContentType: "image/*", MaxSize: 10 << 20})
+//
+// Parameters:
+//   - bucket: The name of the S3 bucket.
+//   - objectName: The key of the S3 object, may end with "${filename}".
+//   - opts: The optional presign parameters.
+//
+// Returns:
+//   - post: The POST form URL and fields.
+//   - err: An error if the operation fails.
+func (a awsS3) PresignPost(bucket, objectName string,
+	opts ...S3PresignOptions) (post S3PresignedPost, err error) {
+
+	o := s3PresignOptions(opts)
+	fields := make(map[string]string)
+	var conditions []any
+
+	// Key prefix
+	if prefix, ok := strings.CutSuffix(objectName, s3PostFilename); ok {
+		conditions = append(conditions,
+			[]any{"starts-with", "$key", prefix})
+	}
+
+	// Content type and size
+	if prefix, ok := strings.CutSuffix(o.ContentType, "*"); ok {
+		conditions = append(conditions,
+			[]any{"starts-with", "$Content-Type", prefix})
+	} else if o.ContentType != "" {
+		fields["Content-Type"] = o.ContentType
+		conditions = append(conditions,
+			map[string]string{"Content-Type": o.ContentType})
+	}
+	if o.MinSize > 0 || o.MaxSize > 0 {
+		maxSize := o.MaxSize
+		if maxSize <= 0 {
+			maxSize = s3MaxSingleSize
+		}
+		conditions = append(conditions,
+			[]any{"content-length-range", o.MinSize, maxSize})
+	}
+
+	// Metadata
+	for name, value := range o.Metadata {
+		field := "x-amz-meta-" + strings.ToLower(name)
+		fields[field] = value
+		conditions = append(conditions, map[string]string{field: value})
+	}
+
+	req, err := s3.NewPresignClient(a.Client).PresignPostObject(a.ctx,
+		&s3.PutObjectInput{
+			Bucket: aws.String(bucket),
+			Key:    aws.String(objectName),
+		},
+		func(po *s3.PresignPostOptions) {
+			po.Expires = o.Expires
+			po.Conditions = conditions
+			if clk := clock(a.ctx); clk != SystemClock {
+				po.PostPresigner = clockPostPresigner{clk, po.PostPresigner}
+			}
+		},
+	)
+	if err != nil {
+		return
+	}
+	for name, value := range req.Values {
+		fields[name] = value
+	}
+	post = S3PresignedPost{URL: req.URL, Fields: fields}
+	return
+}
+
+// s3PresignOptions returns the presign options with the defaults.
+func s3PresignOptions(opts []S3PresignOptions) (o S3PresignOptions) {
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	if o.Expires <= 0 {
+		o.Expires = s3PresignExpires
+	}
+	return
+}
+
+// clockPresigner is the S3 presigner which signs the requests at the Clock
+// time, so the presigned URLs expire by the Clock.
+type clockPresigner struct {
+	clock     Clock
+	presigner s3.HTTPPresignerV4
+}
+
+// PresignHTTP presigns the request at the Clock time.
+func (p clockPresigner) PresignHTTP(ctx context.Context,
+	credentials aws.Credentials, r *http.Request, payloadHash, service,
+	region string, _ time.Time, optFns ...func(*v4.SignerOptions)) (
+	string, http.Header, error) {
+
+	return p.presigner.PresignHTTP(ctx, credentials, r, payloadHash,
+		service, region, p.clock.Now(), optFns...)
+}
+
+// clockPostPresigner is the S3 POST form presigner which signs the policy at
+// the Clock time, so the forms expire by the Clock.
+type clockPostPresigner struct {
+	clock     Clock
+	presigner s3.PresignPost
+}
+
+// PresignPost presigns the POST form policy at the Clock time.
+func (p clockPostPresigner) PresignPost(credentials aws.Credentials, bucket,
+	key, region, service string, signingTime time.Time, conditions []any,
+	expirationTime time.Time, optFns ...func(*v4.SignerOptions)) (
+	map[string]string, error) {
+
+	now := p.clock.Now()
+	return p.presigner.PresignPost(credentials, bucket, key, region,
+		service, now, conditions, now.Add(expirationTime.Sub(signingTime)),
+		optFns...)
+}
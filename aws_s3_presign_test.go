@@ -0,0 +1,75 @@
+package aws
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// TestS3PresignPut checks the object is uploaded by the presigned URL
+func TestS3PresignPut(t *testing.T) {
+
+	fake := NewFakeS3("bucket")
+	a := NewFromConfig(fake.Config())
+	url, header, err := a.S3.PresignPut("bucket", "photo.jpg",
+		S3PresignOptions{ContentType: "image/jpeg",
+			Metadata: map[string]string{"owner": "user1"}})
+	if err != nil {
+		t.Fatal("presign put:", err)
+	}
+	if header.Get("Content-Type") != "image/jpeg" ||
+		header.Get("X-Amz-Meta-Owner") != "user1" || header.Get("Host") != "" ||
+		!strings.Contains(url, "X-Amz-Expires=900") {
+		t.Fatal("wrong presigned put:", url, header)
+	}
+
+	req, _ := http.NewRequest(http.MethodPut, url, strings.NewReader("jpeg"))
+	req.Header = header
+	if resp, err := fake.Do(req); err != nil ||
+		resp.StatusCode != http.StatusOK {
+		t.Fatal("presigned put request:", resp, err)
+	}
+	info, err := a.S3.Info("bucket", "photo.jpg")
+	if err != nil || *info.ContentType != "image/jpeg" ||
+		info.Metadata["owner"] != "user1" {
+		t.Error("wrong uploaded object:", info, err)
+	}
+}
+
+// TestS3PresignPost checks the POST form fields and policy conditions
+func TestS3PresignPost(t *testing.T) {
+
+	a := NewFromConfig(NewFakeS3("bucket").Config())
+	post, err := a.S3.PresignPost("bucket", "uploads/${filename}",
+		S3PresignOptions{ContentType: "image/*", MinSize: 1, MaxSize: 1024,
+			Metadata: map[string]string{"Owner": "user1"}})
+	if err != nil {
+		t.Fatal("presign post:", err)
+	}
+	if post.URL != "https://bucket.s3.us-east-1.amazonaws.com" ||
+		post.Fields["key"] != "uploads/${filename}" ||
+		post.Fields["x-amz-meta-owner"] != "user1" ||
+		post.Fields["X-Amz-Signature"] == "" {
+		t.Fatal("wrong post form:", post)
+	}
+
+	data, _ := base64.StdEncoding.DecodeString(post.Fields["policy"])
+	var policy struct{ Conditions []any }
+	if err = json.Unmarshal(data, &policy); err != nil {
+		t.Fatal("policy:", err)
+	}
+	conditions, _ := json.Marshal(policy.Conditions)
+	for _, want := range []string{`["starts-with","$key","uploads/"]`,
+		`["starts-with","$Content-Type","image/"]`,
+		`["content-length-range",1,1024]`,
+		`{"x-amz-meta-owner":"user1"}`} {
+		if !strings.Contains(string(conditions), want) {
+			t.Error("condition not found:", want, string(conditions))
+		}
+	}
+	if strings.Contains(string(conditions), `{"key":`) {
+		t.Error("exact key condition:", string(conditions))
+	}
+}
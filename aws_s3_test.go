@@ -1,7 +1,12 @@
 package aws
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -21,7 +26,7 @@ func TestS3List(t *testing.T) {
 	}
 
 	// Get list of folders from S3
-	keys, err := a.S3.List(bucket, "dust-", ListObjects{0, "", "/"})
+	keys, err := a.S3.List(bucket, "dust-", ListObjects{Delimiter: "/"})
 	if err != nil {
 		t.Log("List error:", err)
 		return
@@ -41,7 +46,7 @@ func TestS3List(t *testing.T) {
 	for {
 		t.Log("list after lastKey:", lastKey)
 
-		keys, err = a.S3.List(bucket, prefix, ListObjects{MaxKeys: maxKeys, Marker: lastKey})
+		keys, err = a.S3.List(bucket, prefix, ListObjects{MaxKeys: maxKeys, StartAfter: lastKey})
 		if err != nil {
 			t.Log("List error:", err)
 			return
@@ -59,3 +64,152 @@ func TestS3List(t *testing.T) {
 	// t.Log("all keys", list)
 	t.Log("all keys length:", len(list))
 }
+
+// s3ListBody returns the ListObjects response body with n keys.
+func s3ListBody(n int) string {
+	var b strings.Builder
+	b.WriteString(`<ListBucketResult><Contents><Key>dir/</Key></Contents>`)
+	for i := range n {
+		fmt.Fprintf(&b, `<Contents><Key>dir/%06d</Key><ETag>"e%d"</ETag>`+
+			`<Size>1</Size></Contents>`, i, i)
+	}
+	b.WriteString(`</ListBucketResult>`)
+	return b.String()
+}
+
+// TestS3ListPage checks the listed keys and ETags without the prefix object
+func TestS3ListPage(t *testing.T) {
+
+	a := newErrorTestAws(http.StatusOK, s3ListBody(3))
+	page, err := a.S3.list("bucket", "dir/", ListObjects{})
+	if err != nil || strings.Join(page.keys, ",") !=
+		"dir/000000,dir/000001,dir/000002" ||
+		strings.Join(page.etags, ",") != `"e0","e1","e2"` {
+		t.Error("wrong page:", page, err)
+	}
+}
+
+// TestS3ListStartAfter checks the listing starts after the StartAfter or
+// Marker key and the delimiter pages are continued by the token
+func TestS3ListStartAfter(t *testing.T) {
+
+	a := NewFromConfig(NewFakeS3("bucket").Config())
+	for _, key := range []string{"a/1", "b/1", "b/2", "c/1", "d"} {
+		a.S3.Set("bucket", key, nil)
+	}
+	for _, params := range []ListObjects{{StartAfter: "b/1"},
+		{Marker: "b/1"}} {
+		params.MaxKeys = 2
+		keys, err := a.S3.List("bucket", "", params)
+		if err != nil || strings.Join(keys, ",") != "b/2,c/1" {
+			t.Error("wrong list after:", params, keys, err)
+		}
+	}
+	prefixes, err := a.S3.ListPages("bucket", "",
+		ListObjects{MaxKeys: 1, Delimiter: "/"}).All()
+	if err != nil || strings.Join(prefixes, ",") != "a/,b/,c/" {
+		t.Error("wrong prefixes pages:", prefixes, err)
+	}
+}
+
+// TestS3ListChan checks all pages are sent to the channel, the listing error
+// is received and the listing stops when ctx is canceled
+func TestS3ListChan(t *testing.T) {
+
+	a := NewFromConfig(NewFakeS3("bucket").Config())
+	for i := range 25 {
+		a.S3.Set("bucket", fmt.Sprintf("dir/%02d", i), nil)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var keys []string
+	ch, errc := a.S3.ListChan(ctx, "bucket", "dir/", ListObjects{MaxKeys: 10})
+	for key := range ch {
+		keys = append(keys, key)
+	}
+	if err := <-errc; err != nil || len(keys) != 25 || keys[24] != "dir/24" {
+		t.Error("wrong listed keys:", len(keys), err)
+	}
+
+	// Listing error
+	ch, errc = a.S3.ListChan(ctx, "none", "dir/")
+	for range ch {
+		t.Error("key of missing bucket")
+	}
+	if err := <-errc; !IsNotFound(err) {
+		t.Error("wrong listing error:", err)
+	}
+
+	// Canceled listing
+	ch, errc = a.S3.ListChan(ctx, "bucket", "dir/", ListObjects{MaxKeys: 1})
+	<-ch
+	cancel()
+	n := 0
+	for range ch {
+		n++
+	}
+	if err := <-errc; err != nil || n > 11 {
+		t.Error("wrong canceled listing:", n, err)
+	}
+}
+
+// TestS3DeleteFolder checks all pages of the folder are deleted by the
+// DeleteObjects batches and the failed keys are returned
+func TestS3DeleteFolder(t *testing.T) {
+
+	fake := NewFakeS3("bucket")
+	a := NewFromConfig(fake.Config())
+	for i := range 2500 {
+		a.S3.Set("bucket", fmt.Sprintf("big/%04d", i), nil)
+	}
+	a.S3.Set("bucket", "big/", nil)
+	a.S3.Set("bucket", "bigger", nil)
+	n, err := a.S3.DeleteFolder("bucket", "big")
+	if err != nil || n != 2500 {
+		t.Error("delete folder:", n, err)
+	}
+	if keys := fake.Keys("bucket"); strings.Join(keys, ",") != "bigger" {
+		t.Error("wrong keys after delete:", keys)
+	}
+
+	// Failed keys
+	client := &pagesHTTPClient{bodies: []string{
+		`<ListBucketResult><Contents><Key>dir/a</Key></Contents>` +
+			`<Contents><Key>dir/b</Key></Contents></ListBucketResult>`,
+		`<DeleteResult><Deleted><Key>dir/a</Key></Deleted>` +
+			`<Deleted><Key>dir/</Key></Deleted><Deleted><Key>dir</Key>` +
+			`</Deleted><Error><Key>dir/b</Key><Code>AccessDenied</Code>` +
+			`<Message>Access Denied</Message></Error></DeleteResult>`,
+	}}
+	n, err = newPagesTestAws(client).S3.DeleteFolder("bucket", "dir")
+	var batchErr *BatchError
+	if n != 1 || !errors.As(err, &batchErr) || batchErr.Total != 4 ||
+		batchErr.Failed[0].Item != "dir/b" ||
+		!errors.Is(err, ErrAccessDenied) {
+		t.Error("wrong failed keys:", n, err)
+	}
+
+	// Not existing keys are counted by DeleteObjects
+	n, err = a.S3.DeleteObjects("bucket", "none", "bigger")
+	if err != nil || n != 2 {
+		t.Error("delete objects:", n, err)
+	}
+}
+
+// BenchmarkS3List measures the List of 1k to 100k keys pages
+func BenchmarkS3List(b *testing.B) {
+	for _, n := range []int{1000, 10000, 100000} {
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			a := newErrorTestAws(http.StatusOK, s3ListBody(n))
+			b.ReportAllocs()
+			b.ResetTimer()
+			for range b.N {
+				keys, err := a.S3.List("bucket", "dir/")
+				if err != nil || len(keys) != n {
+					b.Fatal("wrong list:", len(keys), err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,299 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+)
+
+// Secret version stages.
+const (
+	SecretCurrent  = "AWSCURRENT"
+	SecretPending  = "AWSPENDING"
+	SecretPrevious = "AWSPREVIOUS"
+)
+
+// secretsCacheTTL is the default time to live of the cached secret values.
+const secretsCacheTTL = 5 * time.Minute
+
+// awsSecrets is the AWS Secrets Manager client struct.
+type awsSecrets struct {
+	// ctx is the context.Context for AWS requests
+	ctx context.Context
+
+	// Client is the AWS Secrets Manager client
+	Client *secretsmanager.Client
+
+	// Cache is the secret values cache. The values are cached for 5 minutes
+	// by default, the secrets changed by Put and Rotate are removed from the
+	// cache.
+	Cache *LookupCache[secretKey, Secret]
+}
+
+// secretKey is the secret version stage.
+type secretKey struct {
+	secretID, stage string
+}
+
+// Secret is the secret value.
+type Secret struct {
+	// ARN is the secret ARN.
+	ARN string
+
+	// Name is the secret name.
+	Name string
+
+	// VersionID is the secret version ID.
+	VersionID string
+
+	// Stages are the version stages of the secret version.
+	Stages []string
+
+	// String is the secret string value.
+	String string
+
+	// Binary is the secret binary value.
+	Binary []byte
+
+	// CreatedAt is the secret version creation time.
+	CreatedAt time.Time
+}
+
+// SecretsRotateOptions are the optional parameters of the Secrets Rotate.
+type SecretsRotateOptions struct {
+	// LambdaARN is the ARN of the rotation function. It may be empty if the
+	// rotation is already configured or the secret is managed by other
+	// service.
+	LambdaARN string
+
+	// Every is the rotation interval, rounded up to days.
+	Every time.Duration
+
+	// Schedule is the rotation schedule expression, for example
+	// "rate(10 days)" or "cron(0 16 1,15 * ? *)".
+	Schedule string
+
+	// Later rotates the secret at the next scheduled rotation instead of
+	// immediately.
+	Later bool
+}
+
+// init initializes the Secrets Manager client cache.
+func (a *awsSecrets) init() {
+	a.Cache = NewLookupCache(
+		func(k secretKey) (Secret, error) { return a.get(k.secretID, k.stage) },
+		IsNotFound,
+	)
+	a.Cache.TTL = secretsCacheTTL
+	a.Cache.Clock = clock(a.ctx)
+}
+
+// Get returns the secret value from the cache or reads it from Secrets
+// Manager.
+//
+// Parameters:
+//   - secretID: The secret name or ARN.
+//   - stage: The optional version stage. Default is SecretCurrent.
+//
+// Returns:
+//   - secret: The secret value.
+//   - err: An error if the operation fails. The error wraps ErrNotFound if
+//     the secret or its version stage does not exist.
+func (a awsSecrets) Get(secretID string, stage ...string) (secret Secret,
+	err error) {
+
+	key := secretKey{secretID, SecretCurrent}
+	if len(stage) > 0 && stage[0] != "" {
+		key.stage = stage[0]
+	}
+	return a.Cache.Get(key)
+}
+
+// GetString returns the secret string value from the cache or reads it from
+// Secrets Manager.
+//
+// Parameters:
+//   - secretID: The secret name or ARN.
+//   - stage: The optional version stage. Default is SecretCurrent.
+//
+// Returns:
+//   - value: The secret string value.
+//   - err: An error if the operation fails. The error wraps ErrNotFound if
+//     the secret or its version stage does not exist.
+func (a awsSecrets) GetString(secretID string, stage ...string) (
+	value string, err error) {
+
+	secret, err := a.Get(secretID, stage...)
+	if err != nil {
+		return
+	}
+	value = secret.String
+	return
+}
+
+// GetJSON unmarshals the secret JSON string value into v. The secret value is
+// read from the cache or from Secrets Manager.
+//
+// Parameters:
+//   - secretID: The secret name or ARN.
+//   - v: The pointer to the value to unmarshal to.
+//   - stage: The optional version stage. Default is SecretCurrent.
+//
+// Returns:
+//   - err: An error if the operation or unmarshal fails. The error wraps
+//     ErrNotFound if the secret or its version stage does not exist.
+func (a awsSecrets) GetJSON(secretID string, v any, stage ...string) (
+	err error) {
+
+	value, err := a.GetString(secretID, stage...)
+	if err != nil {
+		return
+	}
+	return json.Unmarshal([]byte(value), v)
+}
+
+// SecretJSON returns the secret JSON string value unmarshaled into T, for
+// example the database credentials struct.
+//
+// Parameters:
+//   - secrets: The Aws Secrets client.
+//   - secretID: The secret name or ARN.
+//   - stage: The optional version stage. Default is SecretCurrent.
+//
+// Returns:
+//   - v: The unmarshaled secret value.
+//   - err: An error if the operation or unmarshal fails. The error wraps
+//     ErrNotFound if the secret or its version stage does not exist.
+func SecretJSON[T any](secrets awsSecrets, secretID string,
+	stage ...string) (v T, err error) {
+
+	err = secrets.GetJSON(secretID, &v, stage...)
+	return
+}
+
+// Put sets the new secret value. The secret is created if it does not exist.
+// The new value becomes the SecretCurrent version.
+//
+// Parameters:
+//   - secretID: The secret name or ARN.
+//   - value: The secret value. The string is saved as is, the []byte is
+//     saved as the binary value, other values are marshaled to JSON.
+//
+// Returns:
+//   - versionID: The new secret version ID.
+//   - err: An error if the operation fails.
+func (a awsSecrets) Put(secretID string, value any) (versionID string,
+	err error) {
+
+	var secretString *string
+	secretBinary, ok := value.([]byte)
+	if !ok {
+		var s string
+		if s, err = messageBody(value); err != nil {
+			return
+		}
+		secretString = aws.String(s)
+	}
+	defer a.Cache.Clear()
+
+	// Put new version of the existing secret
+	out, err := a.Client.PutSecretValue(a.ctx,
+		&secretsmanager.PutSecretValueInput{
+			SecretId:     aws.String(secretID),
+			SecretString: secretString,
+			SecretBinary: secretBinary,
+		},
+	)
+	if err == nil {
+		versionID = aws.ToString(out.VersionId)
+		return
+	}
+	if !errors.Is(err, ErrNotFound) {
+		return
+	}
+
+	// Create the secret
+	created, err := a.Client.CreateSecret(a.ctx,
+		&secretsmanager.CreateSecretInput{
+			Name:         aws.String(secretID),
+			SecretString: secretString,
+			SecretBinary: secretBinary,
+		},
+	)
+	if err != nil {
+		return
+	}
+	versionID = aws.ToString(created.VersionId)
+	return
+}
+
+// Rotate starts the secret rotation and configures the rotation function
+// and schedule if they are set in options. The rotation function creates the
+// SecretPending version and moves it to SecretCurrent when the rotation is
+// completed.
+//
+// Parameters:
+//   - secretID: The secret name or ARN.
+//   - opts: The optional rotation parameters.
+//
+// Returns:
+//   - versionID: The pending secret version ID.
+//   - err: An error if the operation fails.
+func (a awsSecrets) Rotate(secretID string, opts ...SecretsRotateOptions) (
+	versionID string, err error) {
+
+	var o SecretsRotateOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	input := &secretsmanager.RotateSecretInput{
+		SecretId:          aws.String(secretID),
+		RotationLambdaARN: optional(o.LambdaARN),
+		RotateImmediately: aws.Bool(!o.Later),
+	}
+	if o.Every > 0 || o.Schedule != "" {
+		input.RotationRules = &types.RotationRulesType{
+			ScheduleExpression: optional(o.Schedule),
+		}
+		if o.Every > 0 {
+			days := int64((o.Every + 24*time.Hour - 1) / (24 * time.Hour))
+			input.RotationRules.AutomaticallyAfterDays = aws.Int64(days)
+		}
+	}
+	defer a.Cache.Clear()
+
+	out, err := a.Client.RotateSecret(a.ctx, input)
+	if err != nil {
+		return
+	}
+	versionID = aws.ToString(out.VersionId)
+	return
+}
+
+// get reads the secret version stage value from Secrets Manager.
+func (a awsSecrets) get(secretID, stage string) (secret Secret, err error) {
+	out, err := a.Client.GetSecretValue(a.ctx,
+		&secretsmanager.GetSecretValueInput{
+			SecretId:     aws.String(secretID),
+			VersionStage: aws.String(stage),
+		},
+	)
+	if err != nil {
+		return
+	}
+	secret = Secret{
+		ARN:       aws.ToString(out.ARN),
+		Name:      aws.ToString(out.Name),
+		VersionID: aws.ToString(out.VersionId),
+		Stages:    out.VersionStages,
+		String:    aws.ToString(out.SecretString),
+		Binary:    out.SecretBinary,
+		CreatedAt: aws.ToTime(out.CreatedDate),
+	}
+	return
+}
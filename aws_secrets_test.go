@@ -0,0 +1,84 @@
+package aws
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// TestSecrets checks Secrets Manager cached values and version stages
+func TestSecrets(t *testing.T) {
+
+	client := &pagesHTTPClient{bodies: []string{
+		`{"Name":"db","VersionId":"v1","VersionStages":["AWSCURRENT"],` +
+			`"SecretString":"{\"user\":\"admin\",\"password\":\"p1\"}"}`,
+		`{"Name":"db","VersionId":"v2","VersionStages":["AWSPENDING"],` +
+			`"SecretString":"{\"user\":\"admin\",\"password\":\"p2\"}"}`,
+		`{"__type":"ResourceNotFoundException","message":"not found"}`,
+	}, statuses: []int{http.StatusOK, http.StatusOK, http.StatusBadRequest}}
+	a := newPagesTestAws(client)
+
+	// Current version is read once
+	type credentials struct {
+		User     string `json:"user"`
+		Password string `json:"password"`
+	}
+	for range 2 {
+		c, err := SecretJSON[credentials](a.Secrets, "db")
+		if err != nil || c.User != "admin" || c.Password != "p1" {
+			t.Fatal("wrong current secret:", c, err)
+		}
+	}
+	if len(client.requests) != 1 ||
+		!strings.Contains(client.requests[0], `"VersionStage":"AWSCURRENT"`) {
+		t.Error("wrong current secret requests:", client.requests)
+	}
+
+	// Pending version
+	s, err := a.Secrets.Get("db", SecretPending)
+	if err != nil || s.VersionID != "v2" || s.Stages[0] != SecretPending {
+		t.Error("wrong pending secret:", s, err)
+	}
+
+	// Not found is cached
+	for range 2 {
+		_, err = a.Secrets.GetString("missing")
+		if !errors.Is(err, ErrNotFound) {
+			t.Error("wrong not found error:", err)
+		}
+	}
+	if len(client.requests) != 3 {
+		t.Error("not found is not cached:", client.requests)
+	}
+	if stats := a.Secrets.Cache.Stats(); stats.Hits != 2 || stats.Misses != 3 {
+		t.Error("wrong cache stats:", stats)
+	}
+}
+
+// TestSecretsPut checks the secret is created by Put and the cache is cleared
+func TestSecretsPut(t *testing.T) {
+
+	client := &pagesHTTPClient{bodies: []string{
+		`{"SecretString":"old"}`,
+		`{"__type":"ResourceNotFoundException","message":"not found"}`,
+		`{"Name":"token","VersionId":"v1"}`,
+		`{"SecretString":"new"}`,
+	}, statuses: []int{http.StatusOK, http.StatusBadRequest}}
+	a := newPagesTestAws(client)
+
+	if v, err := a.Secrets.GetString("token"); err != nil || v != "old" {
+		t.Fatal("wrong secret:", v, err)
+	}
+	versionID, err := a.Secrets.Put("token", "new")
+	if err != nil || versionID != "v1" {
+		t.Fatal("wrong put result:", versionID, err)
+	}
+	if !strings.Contains(client.requests[2], `"Name":"token"`) ||
+		!strings.Contains(client.requests[2], `"SecretString":"new"`) {
+		t.Error("wrong create request:", client.requests[2])
+	}
+	if v, err := a.Secrets.GetString("token"); err != nil || v != "new" {
+		t.Error("cache is not cleared by put:", v, err)
+	}
+}
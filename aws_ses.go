@@ -0,0 +1,152 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2/types"
+)
+
+var (
+	// ErrSESRejected is returned by SES send functions when SES rejects the
+	// message, for example the sender address is not verified or the message
+	// contains a virus.
+	ErrSESRejected = errors.New("ses message rejected")
+
+	// ErrSESSandbox is returned by SES send functions when the account is in
+	// the SES sandbox and the recipient address is not verified. It wraps
+	// ErrSESRejected.
+	ErrSESSandbox = fmt.Errorf("%w, account is in the sandbox", ErrSESRejected)
+
+	// ErrSESSendingPaused is returned by SES send functions when the sending
+	// is paused for the account or the configuration set.
+	ErrSESSendingPaused = errors.New("ses sending paused")
+)
+
+// awsSES is the AWS SES (v2) client struct.
+type awsSES struct {
+	// ctx is the context.Context for AWS requests
+	ctx context.Context
+
+	// Client is the AWS SESv2 client
+	Client *sesv2.Client
+
+	// templates caches the email templates by names
+	templates *LookupCache[string, SESTemplate]
+}
+
+// SESOptions are the optional parameters of SES send functions.
+type SESOptions struct {
+	// ConfigurationSet is the configuration set name used to send the email,
+	// for example to publish the delivery events.
+	ConfigurationSet string
+
+	// ReplyTo are the reply-to addresses.
+	ReplyTo []string
+
+	// Cc are the carbon copy recipients.
+	Cc []string
+
+	// Bcc are the blind carbon copy recipients.
+	Bcc []string
+
+	// Tags are the message tags published with the delivery events.
+	Tags map[string]string
+}
+
+// input sets the options to the SendEmail input.
+func (o SESOptions) input(input *sesv2.SendEmailInput) {
+	input.ConfigurationSetName = optional(o.ConfigurationSet)
+	input.ReplyToAddresses = o.ReplyTo
+	input.Destination.CcAddresses = o.Cc
+	input.Destination.BccAddresses = o.Bcc
+	for name, value := range o.Tags {
+		input.EmailTags = append(input.EmailTags, types.MessageTag{
+			Name:  aws.String(name),
+			Value: aws.String(value),
+		})
+	}
+}
+
+// SendEmail sends the email with the text and/or HTML body.
+//
+// Parameters:
+//   - from: The sender address, for example "Name <noreply@example.com>".
+//   - to: The recipient addresses.
+//   - subject: The email subject.
+//   - textBody: The text body. May be empty if htmlBody is set.
+//   - htmlBody: The HTML body. May be empty if textBody is set.
+//   - opts: The optional send parameters.
+//
+// Returns:
+//   - messageId: The sent message ID.
+//   - err: An error if the operation fails. The error wraps ErrSESRejected,
+//     ErrSESSandbox or ErrSESSendingPaused if SES does not accept the
+//     message.
+func (a awsSES) SendEmail(from string, to []string, subject, textBody,
+	htmlBody string, opts ...SESOptions) (messageId string, err error) {
+
+	body := &types.Body{}
+	if textBody != "" {
+		body.Text = &types.Content{Data: aws.String(textBody),
+			Charset: aws.String("UTF-8")}
+	}
+	if htmlBody != "" {
+		body.Html = &types.Content{Data: aws.String(htmlBody),
+			Charset: aws.String("UTF-8")}
+	}
+	return a.send(from, to, &types.EmailContent{
+		Simple: &types.Message{
+			Subject: &types.Content{Data: aws.String(subject),
+				Charset: aws.String("UTF-8")},
+			Body: body,
+		},
+	}, opts)
+}
+
+// send sends the email content.
+func (a awsSES) send(from string, to []string, content *types.EmailContent,
+	opts []SESOptions) (messageId string, err error) {
+
+	input := &sesv2.SendEmailInput{
+		FromEmailAddress: optional(from),
+		Destination:      &types.Destination{ToAddresses: to},
+		Content:          content,
+	}
+	if len(opts) > 0 {
+		opts[0].input(input)
+	}
+	out, err := a.Client.SendEmail(a.ctx, input)
+	if err != nil {
+		err = sesError(err)
+		return
+	}
+	messageId = aws.ToString(out.MessageId)
+
+	return
+}
+
+// sesError wraps the SES send error with ErrSESRejected, ErrSESSandbox or
+// ErrSESSendingPaused.
+func sesError(err error) error {
+	var e *Error
+	if !errors.As(err, &e) {
+		return err
+	}
+	switch e.Code() {
+	case "MessageRejected":
+		if strings.Contains(e.Message(), "not verified") {
+			return fmt.Errorf("%w: %w", ErrSESSandbox, err)
+		}
+		return fmt.Errorf("%w: %w", ErrSESRejected, err)
+	case "MailFromDomainNotVerifiedException":
+		return fmt.Errorf("%w: %w", ErrSESRejected, err)
+	case "AccountSuspendedException", "SendingPausedException":
+		return fmt.Errorf("%w: %w", ErrSESSendingPaused, err)
+	}
+	return err
+}
@@ -0,0 +1,272 @@
+package aws
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/sesv2/types"
+)
+
+// ErrSESAttachment is returned by SES SendRaw when the attachment can't be
+// added to the message, for example its content type is not valid.
+var ErrSESAttachment = errors.New("ses attachment is not valid")
+
+// sesLineLength is the maximum length of the base64 encoded lines.
+const sesLineLength = 76
+
+// SESAttachment is the file attached to the SES raw email.
+type SESAttachment struct {
+	// Filename is the attachment file name.
+	Filename string
+
+	// ContentType is the attachment MIME type, for example "image/png".
+	// Default is the type of the Filename extension or
+	// "application/octet-stream".
+	ContentType string
+
+	// Data is the attachment content.
+	Data []byte
+
+	// ContentID makes the attachment inline. The inline attachment is used
+	// in the HTML body by its content ID, for example <img src="cid:logo">
+	// for the "logo" ContentID.
+	ContentID string
+}
+
+// part returns the attachment MIME part.
+func (f SESAttachment) part() (p sesPart, err error) {
+	contentType := f.ContentType
+	if contentType == "" {
+		contentType = mime.TypeByExtension(path.Ext(f.Filename))
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	if _, _, err = mime.ParseMediaType(contentType); err != nil {
+		err = fmt.Errorf("%w: %s: %w", ErrSESAttachment, f.Filename, err)
+		return
+	}
+
+	disposition := "attachment"
+	p.header = textproto.MIMEHeader{}
+	if f.ContentID != "" {
+		if strings.ContainsAny(f.ContentID, "<>\r\n") {
+			err = fmt.Errorf("%w: %s: wrong content id %q", ErrSESAttachment,
+				f.Filename, f.ContentID)
+			return
+		}
+		disposition = "inline"
+		p.header.Set("Content-ID", "<"+f.ContentID+">")
+	}
+	p.header.Set("Content-Type", contentType)
+	p.header.Set("Content-Transfer-Encoding", "base64")
+	p.header.Set("Content-Disposition", mime.FormatMediaType(disposition,
+		map[string]string{"filename": f.Filename}))
+
+	// Encode data by base64 lines
+	data := base64.StdEncoding.EncodeToString(f.Data)
+	for len(data) > sesLineLength {
+		p.body = append(p.body, data[:sesLineLength]+"\r\n"...)
+		data = data[sesLineLength:]
+	}
+	p.body = append(p.body, data+"\r\n"...)
+
+	return
+}
+
+// SendRaw sends the multipart MIME email with the text and/or HTML body and
+// attachments. The attachments with ContentID are the inline attachments
+// used in the HTML body.
+//
+// Parameters:
+//   - from: The sender address, for example "Name <noreply@example.com>".
+//   - to: The recipient addresses.
+//   - subject: The email subject.
+//   - textBody: The text body. May be empty if htmlBody is set.
+//   - htmlBody: The HTML body. May be empty if textBody is set.
+//   - attachments: The attachments and inline attachments.
+//   - opts: The optional send parameters.
+//
+// Returns:
+//   - messageId: The sent message ID.
+//   - err: An error if the operation fails. The error wraps ErrSESAttachment
+//     if the attachment is not valid, or the errors of SendEmail.
+func (a awsSES) SendRaw(from string, to []string, subject, textBody,
+	htmlBody string, attachments []SESAttachment,
+	opts ...SESOptions) (messageId string, err error) {
+
+	var o SESOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	data, err := sesRawMessage(from, to, subject, textBody, htmlBody,
+		attachments, o)
+	if err != nil {
+		return
+	}
+	return a.send(from, to, &types.EmailContent{
+		Raw: &types.RawMessage{Data: data},
+	}, opts)
+}
+
+// sesRawMessage creates the RFC 5322 multipart MIME message. The message
+// parts are:
+//
+//	multipart/mixed           if there are attachments
+//	  multipart/related       if there are inline attachments
+//	    multipart/alternative if there are both text and HTML bodies
+//	      text/plain
+//	      text/html
+//	    inline attachments
+//	  attachments
+func sesRawMessage(from string, to []string, subject, textBody,
+	htmlBody string, attachments []SESAttachment, o SESOptions) (
+	data []byte, err error) {
+
+	var buf bytes.Buffer
+
+	// Write message headers
+	header := func(name string, addresses ...string) (err error) {
+		if len(addresses) == 0 {
+			return
+		}
+		list := make([]string, len(addresses))
+		for i, address := range addresses {
+			var addr *mail.Address
+			if addr, err = mail.ParseAddress(address); err != nil {
+				return fmt.Errorf("%s address %q: %w", name, address, err)
+			}
+			list[i] = addr.String()
+		}
+		fmt.Fprintf(&buf, "%s: %s\r\n", name, strings.Join(list, ", "))
+		return
+	}
+	for _, h := range []struct {
+		name      string
+		addresses []string
+	}{{"From", []string{from}}, {"To", to}, {"Cc", o.Cc},
+		{"Reply-To", o.ReplyTo}} {
+		if err = header(h.name, h.addresses...); err != nil {
+			return
+		}
+	}
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("UTF-8",
+		subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "MIME-Version: 1.0\r\n")
+
+	// Create message parts
+	var body sesPart
+	switch {
+	case textBody != "" && htmlBody != "":
+		body = sesMultipart("alternative", sesText("plain", textBody),
+			sesText("html", htmlBody))
+	case htmlBody != "":
+		body = sesText("html", htmlBody)
+	default:
+		body = sesText("plain", textBody)
+	}
+	var inline, attached []sesPart
+	for _, f := range attachments {
+		var p sesPart
+		if p, err = f.part(); err != nil {
+			return
+		}
+		if f.ContentID != "" {
+			inline = append(inline, p)
+		} else {
+			attached = append(attached, p)
+		}
+	}
+	if len(inline) > 0 {
+		body = sesMultipart("related", append([]sesPart{body}, inline...)...)
+	}
+	if len(attached) > 0 {
+		body = sesMultipart("mixed", append([]sesPart{body}, attached...)...)
+	}
+
+	// Write body headers and body
+	keys := make([]string, 0, len(body.header))
+	for key := range body.header {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		fmt.Fprintf(&buf, "%s: %s\r\n", key, body.header.Get(key))
+	}
+	buf.WriteString("\r\n")
+	if err = body.write(&buf); err != nil {
+		return
+	}
+
+	data = buf.Bytes()
+	return
+}
+
+// sesPart is the MIME message part. The part contains the encoded body or
+// the parts of the multipart body.
+type sesPart struct {
+	header   textproto.MIMEHeader
+	body     []byte
+	parts    []sesPart
+	boundary string
+}
+
+// sesText creates the quoted-printable encoded text part.
+func sesText(subtype, text string) (p sesPart) {
+	var buf bytes.Buffer
+	w := quotedprintable.NewWriter(&buf)
+	w.Write([]byte(text))
+	w.Close()
+
+	p.header = textproto.MIMEHeader{}
+	p.header.Set("Content-Type", "text/"+subtype+"; charset=UTF-8")
+	p.header.Set("Content-Transfer-Encoding", "quoted-printable")
+	p.body = buf.Bytes()
+	return
+}
+
+// sesMultipart creates the multipart part of the subtype, for example
+// "mixed".
+func sesMultipart(subtype string, parts ...sesPart) (p sesPart) {
+	p.boundary = multipart.NewWriter(io.Discard).Boundary()
+	p.header = textproto.MIMEHeader{}
+	p.header.Set("Content-Type", mime.FormatMediaType("multipart/"+subtype,
+		map[string]string{"boundary": p.boundary}))
+	p.parts = parts
+	return
+}
+
+// write writes the part body to w.
+func (p sesPart) write(w io.Writer) (err error) {
+	if p.parts == nil {
+		_, err = w.Write(p.body)
+		return
+	}
+	mw := multipart.NewWriter(w)
+	if err = mw.SetBoundary(p.boundary); err != nil {
+		return
+	}
+	for _, part := range p.parts {
+		var pw io.Writer
+		if pw, err = mw.CreatePart(part.header); err != nil {
+			return
+		}
+		if err = part.write(pw); err != nil {
+			return
+		}
+	}
+	return mw.Close()
+}
@@ -0,0 +1,215 @@
+package aws
+
+import (
+	"iter"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2/types"
+)
+
+const (
+	// SESSuppressedBounce is the suppression reason of the hard bounced
+	// address.
+	SESSuppressedBounce = string(types.SuppressionListReasonBounce)
+
+	// SESSuppressedComplaint is the suppression reason of the address which
+	// recipient marked the message as spam.
+	SESSuppressedComplaint = string(types.SuppressionListReasonComplaint)
+)
+
+// SESSuppressedAddress is the address on the account-level suppression
+// list. SES does not send messages to the suppressed addresses.
+type SESSuppressedAddress struct {
+	// Email is the suppressed email address.
+	Email string
+
+	// Reason is SESSuppressedBounce or SESSuppressedComplaint.
+	Reason string
+
+	// UpdatedAt is the time the address was added to the list.
+	UpdatedAt time.Time
+
+	// MessageID is the ID of the message which bounced or was complained
+	// about. It is returned by SES GetSuppressedAddress only, and is empty
+	// for the addresses added by SuppressAddress.
+	MessageID string
+
+	// FeedbackID is the ID of the bounce or complaint notification. It is
+	// returned by SES GetSuppressedAddress only.
+	FeedbackID string
+}
+
+// SESSuppressionListOptions are the optional parameters of SES
+// SuppressedAddresses.
+type SESSuppressionListOptions struct {
+	// Reasons filters the addresses by the suppression reasons. Default is
+	// all reasons.
+	Reasons []string
+
+	// Start filters the addresses added after the time.
+	Start time.Time
+
+	// End filters the addresses added before the time.
+	End time.Time
+}
+
+// GetSuppressedAddress returns the address from the account-level
+// suppression list with the bounce or complaint reason, use it to explain
+// why the messages to the address are not delivered.
+//
+// Parameters:
+//   - email: The email address.
+//
+// Returns:
+//   - addr: The suppressed address.
+//   - err: An error if the operation fails. The error wraps ErrNotFound if
+//     the address is not suppressed.
+func (a awsSES) GetSuppressedAddress(email string) (
+	addr SESSuppressedAddress, err error) {
+
+	out, err := a.Client.GetSuppressedDestination(a.ctx,
+		&sesv2.GetSuppressedDestinationInput{
+			EmailAddress: aws.String(email),
+		},
+	)
+	if err != nil || out.SuppressedDestination == nil {
+		return
+	}
+	d := out.SuppressedDestination
+	addr = SESSuppressedAddress{
+		Email:     aws.ToString(d.EmailAddress),
+		Reason:    string(d.Reason),
+		UpdatedAt: aws.ToTime(d.LastUpdateTime),
+	}
+	if d.Attributes != nil {
+		addr.MessageID = aws.ToString(d.Attributes.MessageId)
+		addr.FeedbackID = aws.ToString(d.Attributes.FeedbackId)
+	}
+	return
+}
+
+// IsSuppressed returns true if the address is on the account-level
+// suppression list.
+//
+// Parameters:
+//   - email: The email address.
+//
+// Returns:
+//   - suppressed: True if the address is suppressed.
+//   - err: An error if the operation fails.
+func (a awsSES) IsSuppressed(email string) (suppressed bool, err error) {
+	_, err = a.GetSuppressedAddress(email)
+	switch {
+	case IsNotFound(err):
+		return false, nil
+	case err != nil:
+		return
+	}
+	return true, nil
+}
+
+// SuppressAddress adds the address to the account-level suppression list or
+// changes the reason of the suppressed address.
+//
+// Parameters:
+//   - email: The email address.
+//   - reason: SESSuppressedBounce or SESSuppressedComplaint.
+//
+// Returns:
+//   - err: An error if the operation fails.
+func (a awsSES) SuppressAddress(email, reason string) (err error) {
+	_, err = a.Client.PutSuppressedDestination(a.ctx,
+		&sesv2.PutSuppressedDestinationInput{
+			EmailAddress: aws.String(email),
+			Reason:       types.SuppressionListReason(strings.ToUpper(reason)),
+		},
+	)
+	return
+}
+
+// UnsuppressAddress removes the address from the account-level suppression
+// list, so SES sends the messages to it again.
+//
+// Parameters:
+//   - email: The email address.
+//
+// Returns:
+//   - err: An error if the operation fails. The error wraps ErrNotFound if
+//     the address is not suppressed.
+func (a awsSES) UnsuppressAddress(email string) (err error) {
+	_, err = a.Client.DeleteSuppressedDestination(a.ctx,
+		&sesv2.DeleteSuppressedDestinationInput{
+			EmailAddress: aws.String(email),
+		},
+	)
+	return
+}
+
+// SuppressedAddresses returns iterator over the addresses of the
+// account-level suppression list. The next pages are requested automatically
+// while the addresses are iterated. The MessageID and FeedbackID of the
+// addresses are empty, use GetSuppressedAddress to get them.
+//
+// Parameters:
+//   - opts: The optional list filters.
+//
+// Returns:
+//   - iter.Seq2[SESSuppressedAddress, error]: The addresses iterator.
+//     Iteration stops after the first error.
+func (a awsSES) SuppressedAddresses(
+	opts ...SESSuppressionListOptions) iter.Seq2[SESSuppressedAddress, error] {
+
+	return a.SuppressedAddressesPages(opts...).Items()
+}
+
+// SuppressedAddressesPages returns the paginator over the pages of the
+// addresses of the account-level suppression list.
+//
+// Parameters:
+//   - opts: The optional list filters.
+//
+// Returns:
+//   - p: The addresses pages paginator.
+func (a awsSES) SuppressedAddressesPages(opts ...SESSuppressionListOptions) (
+	p *Paginator[SESSuppressedAddress]) {
+
+	var o SESSuppressionListOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	input := &sesv2.ListSuppressedDestinationsInput{}
+	for _, reason := range o.Reasons {
+		input.Reasons = append(input.Reasons,
+			types.SuppressionListReason(strings.ToUpper(reason)))
+	}
+	if !o.Start.IsZero() {
+		input.StartDate = aws.Time(o.Start)
+	}
+	if !o.End.IsZero() {
+		input.EndDate = aws.Time(o.End)
+	}
+
+	return NewPaginator(func() (addrs []SESSuppressedAddress, more bool,
+		err error) {
+
+		out, err := a.Client.ListSuppressedDestinations(a.ctx, input)
+		if err != nil {
+			return
+		}
+		addrs = make([]SESSuppressedAddress, 0,
+			len(out.SuppressedDestinationSummaries))
+		for _, d := range out.SuppressedDestinationSummaries {
+			addrs = append(addrs, SESSuppressedAddress{
+				Email:     aws.ToString(d.EmailAddress),
+				Reason:    string(d.Reason),
+				UpdatedAt: aws.ToTime(d.LastUpdateTime),
+			})
+		}
+		input.NextToken = out.NextToken
+		more = aws.ToString(out.NextToken) != ""
+		return
+	})
+}
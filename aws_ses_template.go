@@ -0,0 +1,231 @@
+package aws
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2/types"
+)
+
+// ErrSESTemplateData is returned by SES SendTemplated and SESTemplate
+// Validate when the template data does not contain the template variables.
+var ErrSESTemplateData = errors.New("ses template data has no variables")
+
+// sesTag matches the template tags: {{name}}, {{{name}}}, {{#each items}}
+// and {{/each}}.
+var sesTag = regexp.MustCompile(`{{{?\s*([#/^]?)\s*([^}\s]*)[^}]*}}}?`)
+
+// SESTemplate is the SES email template. The template parts use the
+// Handlebars syntax, for example "Hello, {{name}}!".
+type SESTemplate struct {
+	// Name is the template name.
+	Name string
+
+	// Subject is the email subject template.
+	Subject string
+
+	// Text is the email text body template.
+	Text string
+
+	// HTML is the email HTML body template.
+	HTML string
+}
+
+// Variables returns the names of the variables used by the template outside
+// of the block helpers, for example "name" or "user.email". The variables
+// inside the blocks like {{#each}} depend on the block context and are not
+// returned.
+func (t SESTemplate) Variables() (variables []string) {
+	for _, part := range []string{t.Subject, t.Text, t.HTML} {
+		depth := 0
+		for _, m := range sesTag.FindAllStringSubmatch(part, -1) {
+			switch kind, name := m[1], m[2]; {
+			case kind == "#" || kind == "^":
+				depth++
+			case kind == "/":
+				depth--
+			case depth > 0, name == "", name == "else", name == "this",
+				strings.HasPrefix(name, "@"), strings.HasPrefix(name, "!"):
+			case !slices.Contains(variables, name):
+				variables = append(variables, name)
+			}
+		}
+	}
+	return
+}
+
+// Validate checks that the template data contains all template variables.
+//
+// Parameters:
+//   - data: The template data, the struct or map marshaled to JSON.
+//
+// Returns:
+//   - err: An error which wraps ErrSESTemplateData if some variables are
+//     missing.
+func (t SESTemplate) Validate(data any) (err error) {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return
+	}
+	var values map[string]any
+	if err = json.Unmarshal(b, &values); err != nil {
+		return
+	}
+
+	var missing []string
+	for _, variable := range t.Variables() {
+		var v any = values
+		for _, key := range strings.Split(variable, ".") {
+			m, ok := v.(map[string]any)
+			if !ok {
+				v = nil
+				break
+			}
+			v = m[key]
+		}
+		if v == nil {
+			missing = append(missing, variable)
+		}
+	}
+	if len(missing) > 0 {
+		err = fmt.Errorf("%w: %s", ErrSESTemplateData,
+			strings.Join(missing, ", "))
+	}
+	return
+}
+
+// content returns the SES template content.
+func (t SESTemplate) content() *types.EmailTemplateContent {
+	return &types.EmailTemplateContent{
+		Subject: optional(t.Subject),
+		Text:    optional(t.Text),
+		Html:    optional(t.HTML),
+	}
+}
+
+// init initializes the SES client templates cache.
+func (a *awsSES) init() {
+	a.templates = NewLookupCache(a.GetTemplate, IsNotFound)
+}
+
+// SendTemplated sends the email created from the template. The template data
+// is validated before sending, the templates are cached.
+//
+// Parameters:
+//   - from: The sender address.
+//   - to: The recipient addresses.
+//   - templateName: The template name.
+//   - data: The template data, the struct or map marshaled to JSON.
+//   - opts: The optional send parameters.
+//
+// Returns:
+//   - messageId: The sent message ID.
+//   - err: An error if the operation fails. The error wraps
+//     ErrSESTemplateData if the data does not contain the template
+//     variables, ErrNotFound if the template does not exist, or the errors
+//     of SendEmail.
+func (a awsSES) SendTemplated(from string, to []string, templateName string,
+	data any, opts ...SESOptions) (messageId string, err error) {
+
+	// Validate template data
+	t, err := a.templates.Get(templateName)
+	if err != nil {
+		return
+	}
+	if err = t.Validate(data); err != nil {
+		return
+	}
+
+	b, err := json.Marshal(data)
+	if err != nil {
+		return
+	}
+	return a.send(from, to, &types.EmailContent{
+		Template: &types.Template{
+			TemplateName: aws.String(templateName),
+			TemplateData: aws.String(string(b)),
+		},
+	}, opts)
+}
+
+// GetTemplate returns the template.
+//
+// Parameters:
+//   - name: The template name.
+//
+// Returns:
+//   - t: The template.
+//   - err: An error if the operation fails. The error wraps ErrNotFound if
+//     the template does not exist.
+func (a awsSES) GetTemplate(name string) (t SESTemplate, err error) {
+	out, err := a.Client.GetEmailTemplate(a.ctx,
+		&sesv2.GetEmailTemplateInput{TemplateName: aws.String(name)})
+	if err != nil {
+		return
+	}
+	t.Name = aws.ToString(out.TemplateName)
+	if c := out.TemplateContent; c != nil {
+		t.Subject = aws.ToString(c.Subject)
+		t.Text = aws.ToString(c.Text)
+		t.HTML = aws.ToString(c.Html)
+	}
+	return
+}
+
+// CreateTemplate creates the template.
+//
+// Parameters:
+//   - t: The template.
+//
+// Returns:
+//   - err: An error if the operation fails. The error wraps ErrConflict if
+//     the template already exists.
+func (a awsSES) CreateTemplate(t SESTemplate) (err error) {
+	_, err = a.Client.CreateEmailTemplate(a.ctx,
+		&sesv2.CreateEmailTemplateInput{
+			TemplateName:    aws.String(t.Name),
+			TemplateContent: t.content(),
+		},
+	)
+	a.templates.Delete(t.Name)
+	return
+}
+
+// UpdateTemplate updates the template.
+//
+// Parameters:
+//   - t: The template.
+//
+// Returns:
+//   - err: An error if the operation fails. The error wraps ErrNotFound if
+//     the template does not exist.
+func (a awsSES) UpdateTemplate(t SESTemplate) (err error) {
+	_, err = a.Client.UpdateEmailTemplate(a.ctx,
+		&sesv2.UpdateEmailTemplateInput{
+			TemplateName:    aws.String(t.Name),
+			TemplateContent: t.content(),
+		},
+	)
+	a.templates.Delete(t.Name)
+	return
+}
+
+// DeleteTemplate deletes the template.
+//
+// Parameters:
+//   - name: The template name.
+//
+// Returns:
+//   - err: An error if the operation fails.
+func (a awsSES) DeleteTemplate(name string) (err error) {
+	_, err = a.Client.DeleteEmailTemplate(a.ctx,
+		&sesv2.DeleteEmailTemplateInput{TemplateName: aws.String(name)})
+	a.templates.Delete(name)
+	return
+}
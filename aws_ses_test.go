@@ -0,0 +1,235 @@
+package aws
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/mail"
+	"strings"
+	"testing"
+)
+
+// TestSESSendEmail checks SES send request and rejection errors
+func TestSESSendEmail(t *testing.T) {
+
+	client := &pagesHTTPClient{bodies: []string{`{"MessageId":"m1"}`}}
+	a := newPagesTestAws(client)
+
+	id, err := a.SES.SendEmail("noreply@example.com",
+		[]string{"user@example.com"}, "Hello", "text", "<p>html</p>",
+		SESOptions{ConfigurationSet: "events", ReplyTo: []string{"a@b.c"}})
+	if err != nil || id != "m1" {
+		t.Fatal("wrong send result:", id, err)
+	}
+	for _, s := range []string{`"ConfigurationSetName":"events"`,
+		`"ReplyToAddresses":["a@b.c"]`, `"Html":{"Charset":"UTF-8",` +
+			`"Data":"<p>html</p>"}`} {
+		if !strings.Contains(client.requests[0], s) {
+			t.Error("send request does not contain", s, client.requests[0])
+		}
+	}
+
+	// Sandbox and paused errors
+	a = newErrorTestAws(http.StatusBadRequest, `{"__type":"MessageRejected",`+
+		`"message":"Email address is not verified."}`)
+	_, err = a.SES.SendEmail("noreply@example.com",
+		[]string{"user@example.com"}, "Hello", "text", "")
+	if !errors.Is(err, ErrSESSandbox) || !errors.Is(err, ErrSESRejected) {
+		t.Error("wrong sandbox error:", err)
+	}
+	a = newErrorTestAws(http.StatusBadRequest,
+		`{"__type":"SendingPausedException","message":"paused"}`)
+	_, err = a.SES.SendEmail("noreply@example.com",
+		[]string{"user@example.com"}, "Hello", "text", "")
+	if !errors.Is(err, ErrSESSendingPaused) {
+		t.Error("wrong sending paused error:", err)
+	}
+}
+
+// TestSESTemplate checks SES template variables validation
+func TestSESTemplate(t *testing.T) {
+
+	tmpl := SESTemplate{
+		Name:    "invite",
+		Subject: "Hello, {{name}}",
+		HTML: "<p>{{{user.email}}}</p>{{#each items}}<li>{{title}}</li>" +
+			"{{/each}}{{! comment }}",
+	}
+	if v := tmpl.Variables(); len(v) != 2 || v[0] != "name" ||
+		v[1] != "user.email" {
+		t.Error("wrong variables:", v)
+	}
+	type user struct {
+		Email string `json:"email"`
+	}
+	err := tmpl.Validate(map[string]any{"name": "A", "user": user{"a@b.c"}})
+	if err != nil {
+		t.Error("valid data error:", err)
+	}
+	err = tmpl.Validate(struct {
+		Name string `json:"name"`
+	}{"A"})
+	if !errors.Is(err, ErrSESTemplateData) ||
+		!strings.Contains(err.Error(), "user.email") {
+		t.Error("wrong missing variables error:", err)
+	}
+
+	// Send templated email, the template is read once
+	client := &pagesHTTPClient{bodies: []string{
+		`{"TemplateName":"invite","TemplateContent":` +
+			`{"Subject":"Hello, {{name}}"}}`,
+		`{"MessageId":"m1"}`,
+		`{"MessageId":"m2"}`,
+	}}
+	a := newPagesTestAws(client)
+	for range 2 {
+		_, err = a.SES.SendTemplated("noreply@example.com",
+			[]string{"user@example.com"}, "invite", map[string]string{"name": "A"})
+		if err != nil {
+			t.Fatal("send templated error:", err)
+		}
+	}
+	_, err = a.SES.SendTemplated("noreply@example.com",
+		[]string{"user@example.com"}, "invite", map[string]string{})
+	if !errors.Is(err, ErrSESTemplateData) || len(client.requests) != 3 ||
+		!strings.Contains(client.requests[1], `"TemplateData":"{\"name\":\"A\"}"`) {
+		t.Error("wrong send templated requests:", err, client.requests)
+	}
+}
+
+// TestSESSendRaw checks SES raw MIME message structure
+func TestSESSendRaw(t *testing.T) {
+
+	client := &pagesHTTPClient{bodies: []string{`{"MessageId":"m1"}`}}
+	a := newPagesTestAws(client)
+
+	png := bytes.Repeat([]byte{0x89, 'P', 'N', 'G'}, 100)
+	_, err := a.SES.SendRaw("Sender <noreply@example.com>",
+		[]string{"user@example.com"}, "Привет\r\nBcc: x@y.z", "text",
+		`<img src="cid:logo">`, []SESAttachment{
+			{Filename: "logo.png", Data: png, ContentID: "logo"},
+			{Filename: "отчет.csv", ContentType: "text/csv",
+				Data: []byte("a,b\n")},
+		})
+	if err != nil {
+		t.Fatal("send raw error:", err)
+	}
+
+	// Read message from request
+	var input struct {
+		Content struct{ Raw struct{ Data []byte } }
+	}
+	if err = json.Unmarshal([]byte(client.requests[0]), &input); err != nil {
+		t.Fatal("wrong request:", err)
+	}
+	msg, err := mail.ReadMessage(bytes.NewReader(input.Content.Raw.Data))
+	if err != nil {
+		t.Fatal("wrong message:", err)
+	}
+	subject, _ := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if subject != "Привет\r\nBcc: x@y.z" || msg.Header.Get("Bcc") != "" {
+		t.Error("wrong subject:", subject)
+	}
+
+	// Check parts: mixed(related(alternative(text, html), logo), csv)
+	parts := func(r io.Reader, contentType string) (parts []*multipart.Part,
+		bodies [][]byte) {
+		media, params, _ := mime.ParseMediaType(contentType)
+		if !strings.HasPrefix(media, "multipart/") {
+			t.Fatal("not multipart:", contentType)
+		}
+		mr := multipart.NewReader(r, params["boundary"])
+		for {
+			p, err := mr.NextPart()
+			if err != nil {
+				return
+			}
+			data, _ := io.ReadAll(p)
+			parts, bodies = append(parts, p), append(bodies, data)
+		}
+	}
+	mixed, bodies := parts(msg.Body, msg.Header.Get("Content-Type"))
+	if len(mixed) != 2 {
+		t.Fatal("wrong mixed parts:", len(mixed))
+	}
+	_, params, _ := mime.ParseMediaType(mixed[1].Header.Get(
+		"Content-Disposition"))
+	if params["filename"] != "отчет.csv" ||
+		mixed[1].Header.Get("Content-Type") != "text/csv" {
+		t.Error("wrong attachment:", mixed[1].Header)
+	}
+	related, relatedBodies := parts(bytes.NewReader(bodies[0]),
+		mixed[0].Header.Get("Content-Type"))
+	if len(related) != 2 || related[1].Header.Get("Content-ID") != "<logo>" ||
+		related[1].Header.Get("Content-Type") != "image/png" {
+		t.Fatal("wrong related parts:", related)
+	}
+	logo, _ := base64.StdEncoding.DecodeString(string(relatedBodies[1]))
+	if !bytes.Equal(logo, png) {
+		t.Error("wrong inline attachment data")
+	}
+	alternative, _ := parts(bytes.NewReader(relatedBodies[0]),
+		related[0].Header.Get("Content-Type"))
+	if len(alternative) != 2 {
+		t.Error("wrong alternative parts:", len(alternative))
+	}
+
+	// Wrong attachment
+	_, err = a.SES.SendRaw("noreply@example.com", []string{"user@example.com"},
+		"Hello", "text", "", []SESAttachment{{Filename: "a",
+			ContentType: "text/plain\r\nBcc: x@y.z"}})
+	if !errors.Is(err, ErrSESAttachment) {
+		t.Error("wrong attachment error:", err)
+	}
+}
+
+// TestSESSuppression checks the suppressed address reason, the list pages
+// and the not suppressed address
+func TestSESSuppression(t *testing.T) {
+
+	client := &pagesHTTPClient{bodies: []string{
+		`{"SuppressedDestination":{"EmailAddress":"a@b.c",` +
+			`"Reason":"BOUNCE","LastUpdateTime":1700000000,` +
+			`"Attributes":{"MessageId":"m1","FeedbackId":"f1"}}}`,
+		`{"message":"not found"}`,
+		`{"NextToken":"p2","SuppressedDestinationSummaries":[` +
+			`{"EmailAddress":"a@b.c","Reason":"BOUNCE"}]}`,
+		`{"SuppressedDestinationSummaries":[` +
+			`{"EmailAddress":"d@e.f","Reason":"COMPLAINT"}]}`,
+		`{}`,
+	}, statuses: []int{200, http.StatusNotFound, 200, 200, 200}}
+	a := newPagesTestAws(client)
+
+	addr, err := a.SES.GetSuppressedAddress("a@b.c")
+	if err != nil || addr.Reason != SESSuppressedBounce ||
+		addr.MessageID != "m1" || addr.FeedbackID != "f1" ||
+		addr.UpdatedAt.Unix() != 1700000000 {
+		t.Fatal("wrong suppressed address:", addr, err)
+	}
+	if suppressed, err := a.SES.IsSuppressed("x@y.z"); err != nil ||
+		suppressed {
+		t.Error("wrong not suppressed address:", suppressed, err)
+	}
+
+	var emails []string
+	for addr, err := range a.SES.SuppressedAddresses(
+		SESSuppressionListOptions{Reasons: []string{"bounce", "complaint"}}) {
+		if err != nil {
+			t.Fatal("list:", err)
+		}
+		emails = append(emails, addr.Email+" "+addr.Reason)
+	}
+	if strings.Join(emails, ",") != "a@b.c BOUNCE,d@e.f COMPLAINT" {
+		t.Error("wrong suppressed addresses:", emails)
+	}
+
+	if err = a.SES.SuppressAddress("g@h.i", "complaint"); err != nil ||
+		!strings.Contains(client.requests[4], `"Reason":"COMPLAINT"`) {
+		t.Error("wrong suppress:", client.requests[4], err)
+	}
+}
@@ -0,0 +1,216 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sfn"
+	"github.com/aws/aws-sdk-go-v2/service/sfn/types"
+)
+
+// ErrSFNExecutionFailed is returned by SFN WaitForCompletion when the
+// execution is failed, timed out or aborted.
+var ErrSFNExecutionFailed = errors.New("sfn execution failed")
+
+const (
+	// sfnWaitDelay is the first delay between the execution status polls,
+	// the delay is doubled up to sfnWaitMaxDelay.
+	sfnWaitDelay = time.Second
+
+	// sfnWaitMaxDelay is the maximum delay between the execution status
+	// polls.
+	sfnWaitMaxDelay = 30 * time.Second
+)
+
+// awsSFN is the AWS Step Functions client struct.
+type awsSFN struct {
+	// ctx is the context.Context for AWS requests
+	ctx context.Context
+
+	// Client is the AWS Step Functions client
+	Client *sfn.Client
+}
+
+// SFNExecution is the state machine execution.
+type SFNExecution struct {
+	// ARN is the execution ARN.
+	ARN string
+
+	// StateMachineARN is the state machine ARN.
+	StateMachineARN string
+
+	// Name is the execution name.
+	Name string
+
+	// Status is the execution status: RUNNING, SUCCEEDED, FAILED, TIMED_OUT,
+	// ABORTED or PENDING_REDRIVE.
+	Status string
+
+	// StartedAt is the execution start time.
+	StartedAt time.Time
+
+	// StoppedAt is the execution stop time, zero if it is running.
+	StoppedAt time.Time
+
+	// Input is the execution JSON input.
+	Input string
+
+	// Output is the JSON output of the succeeded execution.
+	Output string
+
+	// Error is the error code of the failed execution.
+	Error string
+
+	// Cause is the error cause of the failed execution.
+	Cause string
+}
+
+// Unmarshal unmarshals the execution JSON output into v.
+func (e SFNExecution) Unmarshal(v any) error {
+	return json.Unmarshal([]byte(e.Output), v)
+}
+
+// Running returns true if the execution is running.
+func (e SFNExecution) Running() bool {
+	return e.Status == string(types.ExecutionStatusRunning)
+}
+
+// StartExecution starts the state machine execution.
+//
+// Parameters:
+//   - stateMachineArn: The state machine ARN.
+//   - name: The execution name, unique for the state machine. Empty for
+//     the generated name.
+//   - input: The execution input. The string and []byte must contain JSON
+//     and are sent as is, other values are marshaled to JSON.
+//
+// Returns:
+//   - executionArn: The execution ARN.
+//   - err: An error if the operation fails. The error wraps ErrNotFound if
+//     the state machine does not exist, or ErrConflict if the execution with
+//     the same name and other input exists.
+func (a awsSFN) StartExecution(stateMachineArn, name string, input any) (
+	executionArn string, err error) {
+
+	body, err := messageBody(input)
+	if err != nil {
+		return
+	}
+	out, err := a.Client.StartExecution(a.ctx, &sfn.StartExecutionInput{
+		StateMachineArn: aws.String(stateMachineArn),
+		Name:            optional(name),
+		Input:           aws.String(body),
+	})
+	if err != nil {
+		return
+	}
+	executionArn = aws.ToString(out.ExecutionArn)
+	return
+}
+
+// DescribeExecution returns the execution.
+//
+// Parameters:
+//   - executionArn: The execution ARN.
+//
+// Returns:
+//   - execution: The execution.
+//   - err: An error if the operation fails. The error wraps ErrNotFound if
+//     the execution does not exist.
+func (a awsSFN) DescribeExecution(executionArn string) (
+	execution SFNExecution, err error) {
+
+	return a.describe(a.ctx, executionArn)
+}
+
+// StopExecution stops the running execution.
+//
+// Parameters:
+//   - executionArn: The execution ARN.
+//   - errorCode: The error code of the stopped execution. May be empty.
+//   - cause: The error cause of the stopped execution. May be empty.
+//
+// Returns:
+//   - err: An error if the operation fails. The error wraps ErrNotFound if
+//     the execution does not exist.
+func (a awsSFN) StopExecution(executionArn, errorCode, cause string) (
+	err error) {
+
+	_, err = a.Client.StopExecution(a.ctx, &sfn.StopExecutionInput{
+		ExecutionArn: aws.String(executionArn),
+		Error:        optional(errorCode),
+		Cause:        optional(cause),
+	})
+	return
+}
+
+// WaitForCompletion waits for the execution to complete. The execution
+// status is polled with the delay doubled from 1 up to 30 seconds.
+//
+// Parameters:
+//   - ctx: The context to stop waiting.
+//   - executionArn: The execution ARN.
+//
+// Returns:
+//   - execution: The completed execution. Use its Unmarshal to get the
+//     output of the succeeded execution.
+//   - err: An error if the operation fails or ctx is canceled. The error
+//     wraps ErrSFNExecutionFailed with the error code and cause if the
+//     execution is failed, timed out or aborted.
+func (a awsSFN) WaitForCompletion(ctx context.Context, executionArn string) (
+	execution SFNExecution, err error) {
+
+	delay := sfnWaitDelay
+	for {
+		execution, err = a.describe(ctx, executionArn)
+		switch {
+		case err != nil && !IsRetryable(err):
+			return
+		case err != nil, execution.Running():
+		case execution.Status == string(types.ExecutionStatusSucceeded):
+			return
+		default:
+			err = fmt.Errorf("%w: %s %s: %s", ErrSFNExecutionFailed,
+				execution.Status, execution.Error, execution.Cause)
+			return
+		}
+
+		// Wait before next poll
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+			return
+		case <-clock(a.ctx).After(max(delay, RetryAfter(err))):
+		}
+		delay = min(delay*2, sfnWaitMaxDelay)
+	}
+}
+
+// describe returns the execution.
+func (a awsSFN) describe(ctx context.Context, executionArn string) (
+	execution SFNExecution, err error) {
+
+	out, err := a.Client.DescribeExecution(ctx, &sfn.DescribeExecutionInput{
+		ExecutionArn: aws.String(executionArn),
+	})
+	if err != nil {
+		return
+	}
+	execution = SFNExecution{
+		ARN:             aws.ToString(out.ExecutionArn),
+		StateMachineARN: aws.ToString(out.StateMachineArn),
+		Name:            aws.ToString(out.Name),
+		Status:          string(out.Status),
+		StartedAt:       aws.ToTime(out.StartDate),
+		StoppedAt:       aws.ToTime(out.StopDate),
+		Input:           aws.ToString(out.Input),
+		Output:          aws.ToString(out.Output),
+		Error:           aws.ToString(out.Error),
+		Cause:           aws.ToString(out.Cause),
+	}
+	return
+}
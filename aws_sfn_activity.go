@@ -0,0 +1,244 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sfn"
+)
+
+const (
+	// sfnActivityHeartbeat is the default interval of the activity task
+	// heartbeats.
+	sfnActivityHeartbeat = 30 * time.Second
+
+	// sfnActivityRetryDelay is the delay before the next activity task poll
+	// after the retryable poll error.
+	sfnActivityRetryDelay = time.Second
+
+	// sfnErrorBytes and sfnCauseBytes are the maximum sizes of the task
+	// failure error code and cause.
+	sfnErrorBytes = 256
+	sfnCauseBytes = 32768
+)
+
+// SFNActivityTask is the activity task received by the activity worker.
+type SFNActivityTask struct {
+	// Token is the task token.
+	Token string
+
+	// Input is the task JSON input.
+	Input string
+}
+
+// Unmarshal unmarshals the task JSON input into v.
+func (t SFNActivityTask) Unmarshal(v any) error {
+	return json.Unmarshal([]byte(t.Input), v)
+}
+
+// SFNActivityHandler handles the activity task. The returned output is sent
+// as the task success output: the string and []byte must contain JSON and
+// are sent as is, other values are marshaled to JSON. The returned error is
+// sent as the task failure, its error code is the Code() of the error if it
+// has one, or "Error". The ctx is canceled when the task is timed out.
+type SFNActivityHandler func(ctx context.Context, task SFNActivityTask) (
+	output any, err error)
+
+// SFNActivityOptions are the optional parameters of the SFN RunActivity.
+type SFNActivityOptions struct {
+	// WorkerName is the worker name shown in the execution history.
+	WorkerName string
+
+	// Workers is the number of the tasks handled concurrently. Default is 1.
+	Workers int
+
+	// Heartbeat is the interval of the task heartbeats sent while the task
+	// is handled. Default is 30 seconds.
+	Heartbeat time.Duration
+}
+
+// RunActivity runs the activity worker. The worker long polls the activity
+// tasks, calls handler for each task, sends the task heartbeats while the
+// handler is running and sends the handler result as the task success or
+// failure. The handler panic is recovered and sent as the task failure with
+// the "Panic" error code.
+//
+// When ctx is canceled the worker stops polling, waits for the running
+// handlers and sends their results, so the worker is shut down gracefully.
+//
+// Parameters:
+//   - ctx: The context to stop the worker.
+//   - activityArn: The activity ARN.
+//   - handler: The function called for each task.
+//   - opts: The optional worker parameters.
+//
+// Returns:
+//   - err: The not retryable poll error. Nil is returned when ctx is
+//     canceled.
+func (a awsSFN) RunActivity(ctx context.Context, activityArn string,
+	handler SFNActivityHandler, opts ...SFNActivityOptions) (err error) {
+
+	var o SFNActivityOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	o.Workers = max(o.Workers, 1)
+	if o.Heartbeat <= 0 {
+		o.Heartbeat = sfnActivityHeartbeat
+	}
+
+	// Stop all workers on the first poll error
+	pollCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var once sync.Once
+	var wg sync.WaitGroup
+	for range o.Workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if e := a.activityWorker(pollCtx, activityArn, handler,
+				o); e != nil {
+				once.Do(func() { err = e; cancel() })
+			}
+		}()
+	}
+	wg.Wait()
+
+	return
+}
+
+// activityWorker polls and handles the activity tasks one by one until ctx
+// is canceled or the not retryable poll error.
+func (a awsSFN) activityWorker(ctx context.Context, activityArn string,
+	handler SFNActivityHandler, o SFNActivityOptions) (err error) {
+
+	for ctx.Err() == nil {
+		var out *sfn.GetActivityTaskOutput
+		out, err = a.Client.GetActivityTask(ctx, &sfn.GetActivityTaskInput{
+			ActivityArn: aws.String(activityArn),
+			WorkerName:  optional(o.WorkerName),
+		})
+		switch {
+		case ctx.Err() != nil:
+			return nil
+		case err != nil && !IsRetryable(err):
+			return
+		case err != nil:
+			select {
+			case <-ctx.Done():
+			case <-clock(a.ctx).After(max(sfnActivityRetryDelay, RetryAfter(err))):
+			}
+			continue
+		}
+
+		// No task during the long poll
+		if aws.ToString(out.TaskToken) == "" {
+			continue
+		}
+
+		// The task is finished after shutdown
+		task := SFNActivityTask{
+			Token: aws.ToString(out.TaskToken),
+			Input: aws.ToString(out.Input),
+		}
+		a.handleTask(context.WithoutCancel(ctx), task, handler, o.Heartbeat)
+	}
+
+	return nil
+}
+
+// handleTask calls handler for the task, sends the task heartbeats while the
+// handler is running and sends the task result.
+func (a awsSFN) handleTask(ctx context.Context, task SFNActivityTask,
+	handler SFNActivityHandler, heartbeat time.Duration) {
+
+	taskCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Send heartbeats, the task is canceled when it is timed out
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := clock(a.ctx).NewTicker(heartbeat)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C():
+			}
+			_, err := a.Client.SendTaskHeartbeat(taskCtx,
+				&sfn.SendTaskHeartbeatInput{TaskToken: aws.String(task.Token)})
+			if err != nil && !IsRetryable(err) {
+				cancel()
+				return
+			}
+		}
+	}()
+
+	output, err := sfnCallHandler(taskCtx, task, handler)
+	if taskCtx.Err() != nil {
+		return
+	}
+	var body string
+	if err == nil {
+		body, err = messageBody(output)
+	}
+	if err != nil {
+		code := sfnTruncate(sfnErrorCode(err), sfnErrorBytes)
+		a.Client.SendTaskFailure(ctx, &sfn.SendTaskFailureInput{
+			TaskToken: aws.String(task.Token),
+			Error:     aws.String(code),
+			Cause:     aws.String(sfnTruncate(err.Error(), sfnCauseBytes)),
+		})
+		return
+	}
+	a.Client.SendTaskSuccess(ctx, &sfn.SendTaskSuccessInput{
+		TaskToken: aws.String(task.Token),
+		Output:    aws.String(body),
+	})
+}
+
+// sfnPanicError is the recovered handler panic.
+type sfnPanicError struct{ value any }
+
+// Error returns the panic error message.
+func (e sfnPanicError) Error() string { return fmt.Sprint("panic: ", e.value) }
+
+// Code returns the "Panic" task failure error code.
+func (e sfnPanicError) Code() string { return "Panic" }
+
+// sfnCallHandler calls handler and returns the recovered panic as error.
+func sfnCallHandler(ctx context.Context, task SFNActivityTask,
+	handler SFNActivityHandler) (output any, err error) {
+
+	defer func() {
+		if v := recover(); v != nil {
+			err = sfnPanicError{v}
+		}
+	}()
+	return handler(ctx, task)
+}
+
+// sfnErrorCode returns the task failure error code: the Code() of the error
+// if it has one, or "Error".
+func sfnErrorCode(err error) string {
+	var e interface{ Code() string }
+	if errors.As(err, &e) && e.Code() != "" {
+		return e.Code()
+	}
+	return "Error"
+}
+
+// sfnTruncate returns s truncated to n bytes.
+func sfnTruncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
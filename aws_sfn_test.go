@@ -0,0 +1,103 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// TestSFNExecution checks Step Functions execution start and completion wait
+func TestSFNExecution(t *testing.T) {
+
+	client := &pagesHTTPClient{bodies: []string{
+		`{"executionArn":"arn:exec:1","startDate":1700000000}`,
+		`{"executionArn":"arn:exec:1","status":"RUNNING"}`,
+		`{"executionArn":"arn:exec:1","status":"SUCCEEDED",` +
+			`"output":"{\"orderId\":\"42\"}"}`,
+		`{"executionArn":"arn:exec:2","status":"FAILED",` +
+			`"error":"PaymentDeclined","cause":"card expired"}`,
+	}}
+	a := newPagesTestAws(client)
+
+	arn, err := a.SFN.StartExecution("arn:sm:orders", "order-42",
+		map[string]string{"orderId": "42"})
+	if err != nil || arn != "arn:exec:1" {
+		t.Fatal("wrong start execution:", arn, err)
+	}
+	if !strings.Contains(client.requests[0], `"name":"order-42"`) ||
+		!strings.Contains(client.requests[0], `"input":"{\"orderId\":\"42\"}"`) {
+		t.Error("wrong start request:", client.requests[0])
+	}
+
+	// Succeeded after one poll
+	execution, err := a.SFN.WaitForCompletion(context.Background(), arn)
+	var output struct{ OrderID string }
+	if err != nil || execution.Unmarshal(&output) != nil ||
+		output.OrderID != "42" {
+		t.Error("wrong succeeded execution:", execution, err)
+	}
+
+	// Failed
+	execution, err = a.SFN.WaitForCompletion(context.Background(),
+		"arn:exec:2")
+	if !errors.Is(err, ErrSFNExecutionFailed) ||
+		!strings.Contains(err.Error(), "card expired") ||
+		execution.Error != "PaymentDeclined" {
+		t.Error("wrong failed execution:", execution, err)
+	}
+}
+
+// TestSFNErrors checks Step Functions not found error
+func TestSFNErrors(t *testing.T) {
+
+	a := newErrorTestAws(http.StatusBadRequest,
+		`{"__type":"ExecutionDoesNotExist","message":"not found"}`)
+	_, err := a.SFN.DescribeExecution("arn:exec:1")
+	var opErr *OpError
+	if !errors.Is(err, ErrNotFound) || !errors.As(err, &opErr) ||
+		opErr.Resource != "arn:exec:1" {
+		t.Error("wrong describe error:", err)
+	}
+}
+
+// TestSFNActivity checks Step Functions activity task success, panic
+// recovery and shutdown
+func TestSFNActivity(t *testing.T) {
+
+	client := &pagesHTTPClient{bodies: []string{
+		`{"taskToken":"t1","input":"{\"orderId\":\"42\"}"}`,
+		`{}`,
+		`{"taskToken":"t2","input":"{}"}`,
+		`{}`,
+	}}
+	a := newPagesTestAws(client)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	err := a.SFN.RunActivity(ctx, "arn:activity:ship",
+		func(ctx context.Context, task SFNActivityTask) (any, error) {
+			var input struct{ OrderID string }
+			if task.Unmarshal(&input); input.OrderID == "42" {
+				return map[string]bool{"shipped": true}, nil
+			}
+			cancel()
+			panic("no order")
+		},
+		SFNActivityOptions{WorkerName: "shipper"},
+	)
+	if err != nil || len(client.requests) != 4 {
+		t.Fatal("wrong run activity:", len(client.requests), err)
+	}
+	if !strings.Contains(client.requests[0], `"workerName":"shipper"`) {
+		t.Error("wrong poll request:", client.requests[0])
+	}
+	if !strings.Contains(client.requests[1], `"taskToken":"t1"`) ||
+		!strings.Contains(client.requests[1], `{\"shipped\":true}`) {
+		t.Error("wrong success request:", client.requests[1])
+	}
+	if !strings.Contains(client.requests[3], `"error":"Panic"`) ||
+		!strings.Contains(client.requests[3], `"cause":"panic: no order"`) {
+		t.Error("wrong failure request:", client.requests[3])
+	}
+}
@@ -0,0 +1,67 @@
+package aws
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/aws-sdk-go-v2/service/sns/types"
+)
+
+// awsSNS is the AWS SNS client struct.
+type awsSNS struct {
+	// ctx is the context.Context for AWS requests
+	ctx context.Context
+
+	// Client is the AWS SNS client
+	Client *sns.Client
+}
+
+// Publish publishes the message to the topic.
+//
+// Parameters:
+//   - topicARN: The topic ARN.
+//   - msg: The message. The string and []byte are sent as is, other values
+//     are marshaled to JSON.
+//   - attrs: The message attributes with string values, used by the
+//     subscriptions filter policies. May be nil.
+//
+// Returns:
+//   - messageId: The published message ID.
+//   - err: An error if the operation fails.
+func (a awsSNS) Publish(topicARN string, msg any,
+	attrs map[string]string) (messageId string, err error) {
+
+	message, err := messageBody(msg)
+	if err != nil {
+		return
+	}
+	out, err := a.Client.Publish(a.ctx, &sns.PublishInput{
+		TopicArn:          aws.String(topicARN),
+		Message:           aws.String(message),
+		MessageAttributes: snsAttributes(attrs),
+	})
+	if err != nil {
+		return
+	}
+	messageId = aws.ToString(out.MessageId)
+
+	return
+}
+
+// snsAttributes converts the string attributes to the SNS message attributes.
+func snsAttributes(attributes map[string]string) (
+	res map[string]types.MessageAttributeValue) {
+
+	if len(attributes) == 0 {
+		return
+	}
+	res = make(map[string]types.MessageAttributeValue, len(attributes))
+	for name, value := range attributes {
+		res[name] = types.MessageAttributeValue{
+			DataType:    aws.String("String"),
+			StringValue: aws.String(value),
+		}
+	}
+	return
+}
@@ -0,0 +1,252 @@
+package aws
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+)
+
+// ErrSNSEndpointDisabled is returned by SNS PublishPush when the platform
+// endpoint is disabled by the push service after re-enabling, usually
+// because the device token is expired. Register the new device token with
+// RefreshEndpoint.
+var ErrSNSEndpointDisabled = errors.New("sns endpoint disabled")
+
+const (
+	// snsEndpointDisabled is the SNS error code of the disabled endpoint.
+	snsEndpointDisabled = "EndpointDisabled"
+
+	// snsInvalidParameter is the SNS error code of the invalid parameter.
+	snsInvalidParameter = "InvalidParameter"
+)
+
+// snsEndpointExists matches the ARN of the existing endpoint in the
+// CreatePlatformEndpoint error message.
+var snsEndpointExists = regexp.MustCompile(
+	`Endpoint (arn:\S+) already exists with the same [Tt]oken`)
+
+// PushMessage is the mobile push notification. The Title, Body and Data are
+// sent in the APNS and FCM payloads, the APNS and FCM replace them with the
+// platform specific payloads.
+type PushMessage struct {
+	// Title is the notification title.
+	Title string
+
+	// Body is the notification text. It is also the default message of the
+	// other platforms.
+	Body string
+
+	// Data is the custom data passed to the application.
+	Data map[string]string
+
+	// APNS is the Apple Push Notification service payload, for example
+	// {"aps": {"alert": "Hi", "badge": 1}}. It replaces the Title, Body and
+	// Data payload on the APNS and APNS_SANDBOX platforms.
+	APNS any
+
+	// FCM is the Firebase Cloud Messaging payload, for example
+	// {"fcmV1Message": {"message": {...}}}. It replaces the Title, Body and
+	// Data payload on the GCM (FCM) platform.
+	FCM any
+}
+
+// message returns the SNS JSON message with the platform payloads.
+func (m PushMessage) message() (message string, err error) {
+	apns, fcm := m.APNS, m.FCM
+	if apns == nil {
+		payload := map[string]any{"aps": map[string]any{
+			"alert": map[string]string{"title": m.Title, "body": m.Body},
+		}}
+		for k, v := range m.Data {
+			payload[k] = v
+		}
+		apns = payload
+	}
+	if fcm == nil {
+		fcm = map[string]any{"fcmV1Message": map[string]any{
+			"message": map[string]any{
+				"notification": map[string]string{"title": m.Title,
+					"body": m.Body},
+				"data": maps.Clone(m.Data),
+			},
+		}}
+	}
+
+	// The platform payloads are JSON strings in the SNS message
+	envelope := map[string]string{"default": m.Body}
+	for platform, payload := range map[string]any{"APNS": apns,
+		"APNS_SANDBOX": apns, "GCM": fcm} {
+
+		var data []byte
+		if data, err = json.Marshal(payload); err != nil {
+			return
+		}
+		envelope[platform] = string(data)
+	}
+	data, err := json.Marshal(envelope)
+	message = string(data)
+	return
+}
+
+// CreateEndpoint creates the platform application endpoint of the device
+// token. The existing endpoint of the token is updated with the userData
+// and enabled.
+//
+// Parameters:
+//   - appArn: The platform application ARN.
+//   - token: The device token.
+//   - userData: The optional user data of the endpoint, for example the
+//     user ID.
+//
+// Returns:
+//   - endpointArn: The endpoint ARN.
+//   - err: An error if the operation fails.
+func (a awsSNS) CreateEndpoint(appArn, token, userData string) (
+	endpointArn string, err error) {
+
+	out, err := a.Client.CreatePlatformEndpoint(a.ctx,
+		&sns.CreatePlatformEndpointInput{
+			PlatformApplicationArn: aws.String(appArn),
+			Token:                  aws.String(token),
+			CustomUserData:         optional(userData),
+		},
+	)
+
+	// The endpoint of the token exists with other attributes
+	var e *Error
+	if errors.As(err, &e) && e.Code() == snsInvalidParameter {
+		if m := snsEndpointExists.FindStringSubmatch(e.Message()); m != nil {
+			endpointArn = m[1]
+			err = a.setEndpoint(endpointArn, token, userData)
+			return
+		}
+	}
+	if err != nil {
+		return
+	}
+	endpointArn = aws.ToString(out.EndpointArn)
+
+	return
+}
+
+// RefreshEndpoint updates the endpoint with the current device token and
+// enables it. Call it on each application start with the stored endpoint
+// ARN, the endpoint is created if endpointArn is empty or the endpoint was
+// deleted.
+//
+// Parameters:
+//   - appArn: The platform application ARN.
+//   - endpointArn: The stored endpoint ARN. May be empty.
+//   - token: The current device token.
+//   - userData: The optional user data of the endpoint.
+//
+// Returns:
+//   - arn: The endpoint ARN, store it if it differs from endpointArn.
+//   - err: An error if the operation fails.
+func (a awsSNS) RefreshEndpoint(appArn, endpointArn, token,
+	userData string) (arn string, err error) {
+
+	if endpointArn == "" {
+		return a.CreateEndpoint(appArn, token, userData)
+	}
+	out, err := a.Client.GetEndpointAttributes(a.ctx,
+		&sns.GetEndpointAttributesInput{EndpointArn: aws.String(endpointArn)})
+	switch {
+	case IsNotFound(err):
+		return a.CreateEndpoint(appArn, token, userData)
+	case err != nil:
+		return
+	}
+	arn = endpointArn
+	if out.Attributes["Token"] != token || out.Attributes["Enabled"] != "true" {
+		err = a.setEndpoint(endpointArn, token, userData)
+	}
+
+	return
+}
+
+// DeleteEndpoint deletes the platform application endpoint.
+//
+// Parameters:
+//   - endpointArn: The endpoint ARN.
+//
+// Returns:
+//   - err: An error if the operation fails.
+func (a awsSNS) DeleteEndpoint(endpointArn string) (err error) {
+	_, err = a.Client.DeleteEndpoint(a.ctx, &sns.DeleteEndpointInput{
+		EndpointArn: aws.String(endpointArn),
+	})
+	return
+}
+
+// PublishPush publishes the push notification to the platform endpoint.
+// The disabled endpoint is enabled and the notification is published again.
+//
+// Parameters:
+//   - endpointArn: The endpoint ARN.
+//   - msg: The push notification.
+//
+// Returns:
+//   - messageId: The published message ID.
+//   - err: An error if the operation fails. The error wraps
+//     ErrSNSEndpointDisabled if the endpoint is disabled again.
+func (a awsSNS) PublishPush(endpointArn string, msg PushMessage) (
+	messageId string, err error) {
+
+	message, err := msg.message()
+	if err != nil {
+		return
+	}
+	for enabled := false; ; enabled = true {
+		var out *sns.PublishOutput
+		out, err = a.Client.Publish(a.ctx, &sns.PublishInput{
+			TargetArn:        aws.String(endpointArn),
+			Message:          aws.String(message),
+			MessageStructure: aws.String("json"),
+		})
+		var e *Error
+		switch {
+		case err == nil:
+			messageId = aws.ToString(out.MessageId)
+			return
+		case !errors.As(err, &e) || e.Code() != snsEndpointDisabled:
+			return
+		case enabled:
+			err = fmt.Errorf("%w: %w", ErrSNSEndpointDisabled, err)
+			return
+		}
+
+		// Enable the endpoint and publish again
+		_, err = a.Client.SetEndpointAttributes(a.ctx,
+			&sns.SetEndpointAttributesInput{
+				EndpointArn: aws.String(endpointArn),
+				Attributes:  map[string]string{"Enabled": "true"},
+			},
+		)
+		if err != nil {
+			return
+		}
+	}
+}
+
+// setEndpoint sets the endpoint token and user data and enables it.
+func (a awsSNS) setEndpoint(endpointArn, token, userData string) (
+	err error) {
+
+	attributes := map[string]string{"Token": token, "Enabled": "true"}
+	if userData != "" {
+		attributes["CustomUserData"] = userData
+	}
+	_, err = a.Client.SetEndpointAttributes(a.ctx,
+		&sns.SetEndpointAttributesInput{
+			EndpointArn: aws.String(endpointArn),
+			Attributes:  attributes,
+		},
+	)
+	return
+}
@@ -0,0 +1,106 @@
+package aws
+
+import (
+	"errors"
+	"regexp"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/aws-sdk-go-v2/service/sns/types"
+)
+
+// SMS types of the SNS SendSMS.
+const (
+	// SMSTransactional is the critical message, for example one-time
+	// password, delivered with the highest reliability.
+	SMSTransactional = "Transactional"
+
+	// SMSPromotional is the noncritical message, for example marketing
+	// message, delivered with the lowest cost.
+	SMSPromotional = "Promotional"
+)
+
+var (
+	// ErrSNSInvalidPhone is returned by SNS SendSMS when the phone number is
+	// not in the E.164 format.
+	ErrSNSInvalidPhone = errors.New("phone number is not in E.164 format")
+
+	// ErrSNSOptedOut is returned by SNS SendSMS when the phone number owner
+	// opted out of receiving SMS messages from the account.
+	ErrSNSOptedOut = errors.New("phone number opted out")
+)
+
+// e164 matches the phone number in the E.164 format, for example
+// +14155552671.
+var e164 = regexp.MustCompile(`^\+[1-9]\d{1,14}$`)
+
+// SendSMS sends the SMS message directly to the phone number. The opt-out
+// status of the phone number is checked before sending.
+//
+// Parameters:
+//   - phone: The phone number in the E.164 format, for example +14155552671.
+//   - message: The message text.
+//   - senderID: The optional sender ID shown as the message sender, up to 11
+//     alphanumeric characters. It is not supported in some countries.
+//   - smsType: The optional SMS type, SMSTransactional or SMSPromotional.
+//     The account default is used if empty.
+//
+// Returns:
+//   - messageId: The sent message ID.
+//   - err: An error if the operation fails. The error wraps
+//     ErrSNSInvalidPhone if the phone number format is wrong and
+//     ErrSNSOptedOut if the phone number opted out.
+func (a awsSNS) SendSMS(phone, message, senderID, smsType string) (
+	messageId string, err error) {
+
+	// Check phone number format and opt-out status
+	if !e164.MatchString(phone) {
+		err = smsError(phone, ErrSNSInvalidPhone)
+		return
+	}
+	out, err := a.Client.CheckIfPhoneNumberIsOptedOut(a.ctx,
+		&sns.CheckIfPhoneNumberIsOptedOutInput{PhoneNumber: aws.String(phone)})
+	if err != nil {
+		return
+	}
+	if out.IsOptedOut {
+		err = smsError(phone, ErrSNSOptedOut)
+		return
+	}
+
+	// Send SMS
+	attributes := map[string]types.MessageAttributeValue{}
+	for name, value := range map[string]string{
+		"AWS.SNS.SMS.SenderID": senderID,
+		"AWS.SNS.SMS.SMSType":  smsType,
+	} {
+		if value == "" {
+			continue
+		}
+		attributes[name] = types.MessageAttributeValue{
+			DataType:    aws.String("String"),
+			StringValue: aws.String(value),
+		}
+	}
+	res, err := a.Client.Publish(a.ctx, &sns.PublishInput{
+		PhoneNumber:       aws.String(phone),
+		Message:           aws.String(message),
+		MessageAttributes: attributes,
+	})
+	if err != nil {
+		return
+	}
+	messageId = aws.ToString(res.MessageId)
+
+	return
+}
+
+// smsError returns the SendSMS error of the phone number.
+func smsError(phone string, err error) error {
+	return &OpError{
+		Service:   sns.ServiceID,
+		Operation: "SendSMS",
+		Resource:  phone,
+		Err:       err,
+	}
+}
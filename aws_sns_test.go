@@ -0,0 +1,179 @@
+package aws
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// TestSNSPublish checks SNS publish request
+func TestSNSPublish(t *testing.T) {
+
+	const topic = "arn:aws:sns:us-east-1:1:events"
+	client := &pagesHTTPClient{bodies: []string{
+		`<PublishResponse><PublishResult><MessageId>m1</MessageId>` +
+			`</PublishResult></PublishResponse>`,
+	}}
+	a := newPagesTestAws(client)
+
+	id, err := a.SNS.Publish(topic, map[string]int{"id": 7},
+		map[string]string{"kind": "order"})
+	if err != nil || id != "m1" {
+		t.Fatal("wrong publish result:", id, err)
+	}
+	values, _ := url.ParseQuery(client.requests[0])
+	if values.Get("Message") != `{"id":7}` ||
+		values.Get("TopicArn") != topic ||
+		values.Get("MessageAttributes.entry.1.Name") != "kind" ||
+		values.Get("MessageAttributes.entry.1.Value.StringValue") != "order" {
+		t.Error("wrong publish request:", values)
+	}
+}
+
+// TestSNSSendSMS checks SNS SMS phone validation and opt-out errors
+func TestSNSSendSMS(t *testing.T) {
+
+	client := &pagesHTTPClient{bodies: []string{
+		`<CheckIfPhoneNumberIsOptedOutResponse>` +
+			`<CheckIfPhoneNumberIsOptedOutResult><isOptedOut>false</isOptedOut>` +
+			`</CheckIfPhoneNumberIsOptedOutResult>` +
+			`</CheckIfPhoneNumberIsOptedOutResponse>`,
+		`<PublishResponse><PublishResult><MessageId>m1</MessageId>` +
+			`</PublishResult></PublishResponse>`,
+		`<CheckIfPhoneNumberIsOptedOutResponse>` +
+			`<CheckIfPhoneNumberIsOptedOutResult><isOptedOut>true</isOptedOut>` +
+			`</CheckIfPhoneNumberIsOptedOutResult>` +
+			`</CheckIfPhoneNumberIsOptedOutResponse>`,
+	}}
+	a := newPagesTestAws(client)
+
+	id, err := a.SNS.SendSMS("+14155552671", "code 1234", "", SMSTransactional)
+	if err != nil || id != "m1" {
+		t.Fatal("wrong send result:", id, err)
+	}
+	values, _ := url.ParseQuery(client.requests[1])
+	if values.Get("PhoneNumber") != "+14155552671" ||
+		values.Get("MessageAttributes.entry.1.Name") != "AWS.SNS.SMS.SMSType" {
+		t.Error("wrong send request:", values)
+	}
+
+	// Opted out and invalid phone numbers
+	_, err = a.SNS.SendSMS("+14155552671", "code 1234", "", "")
+	if !errors.Is(err, ErrSNSOptedOut) {
+		t.Error("wrong opted out error:", err)
+	}
+	_, err = a.SNS.SendSMS("84155552671", "code 1234", "", "")
+	if !errors.Is(err, ErrSNSInvalidPhone) || len(client.requests) != 3 {
+		t.Error("wrong invalid phone error:", err)
+	}
+}
+
+// TestSNSSubscriptions checks SNS subscribe attributes and subscriptions list
+func TestSNSSubscriptions(t *testing.T) {
+
+	const topic = "arn:aws:sns:us-east-1:1:events"
+	client := &pagesHTTPClient{bodies: []string{
+		`<SubscribeResponse><SubscribeResult>` +
+			`<SubscriptionArn>` + topic + `:s1</SubscriptionArn>` +
+			`</SubscribeResult></SubscribeResponse>`,
+		`<ListSubscriptionsByTopicResponse><ListSubscriptionsByTopicResult>` +
+			`<Subscriptions><member><SubscriptionArn>` + topic + `:s1` +
+			`</SubscriptionArn><Protocol>sqs</Protocol></member></Subscriptions>` +
+			`<NextToken>n1</NextToken>` +
+			`</ListSubscriptionsByTopicResult></ListSubscriptionsByTopicResponse>`,
+		`<ListSubscriptionsByTopicResponse><ListSubscriptionsByTopicResult>` +
+			`<Subscriptions><member><SubscriptionArn>PendingConfirmation` +
+			`</SubscriptionArn><Protocol>https</Protocol></member>` +
+			`</Subscriptions>` +
+			`</ListSubscriptionsByTopicResult></ListSubscriptionsByTopicResponse>`,
+	}}
+	a := newPagesTestAws(client)
+
+	raw := true
+	arn, err := a.SNS.Subscribe(topic, "sqs", "arn:aws:sqs:us-east-1:1:q",
+		SNSSubscriptionAttributes{
+			FilterPolicy: map[string][]string{"kind": {"order"}},
+			RawDelivery:  &raw,
+		})
+	if err != nil || arn != topic+":s1" {
+		t.Fatal("wrong subscribe result:", arn, err)
+	}
+	values, _ := url.ParseQuery(client.requests[0])
+	attributes := map[string]string{}
+	for i := 1; i <= 2; i++ {
+		n := strconv.Itoa(i)
+		attributes[values.Get("Attributes.entry."+n+".key")] =
+			values.Get("Attributes.entry." + n + ".value")
+	}
+	if attributes["FilterPolicy"] != `{"kind":["order"]}` ||
+		attributes["RawMessageDelivery"] != "true" {
+		t.Error("wrong subscribe request:", values)
+	}
+
+	subscriptions, err := a.SNS.ListSubscriptionsByTopic(topic)
+	if err != nil || len(subscriptions) != 2 ||
+		subscriptions[1].Protocol != "https" {
+		t.Error("wrong subscriptions:", subscriptions, err)
+	}
+}
+
+// TestSNSPush checks SNS platform endpoint create with existing token and
+// push publish to the disabled endpoint
+func TestSNSPush(t *testing.T) {
+
+	const endpoint = "arn:aws:sns:us-east-1:1:endpoint/APNS/app/e1"
+	snsError := func(code, message string) string {
+		return `<ErrorResponse><Error><Type>Sender</Type><Code>` + code +
+			`</Code><Message>` + message + `</Message></Error></ErrorResponse>`
+	}
+	publish := `<PublishResponse><PublishResult><MessageId>m1</MessageId>` +
+		`</PublishResult></PublishResponse>`
+	setAttributes := `<SetEndpointAttributesResponse>` +
+		`</SetEndpointAttributesResponse>`
+	client := &pagesHTTPClient{bodies: []string{
+		snsError("InvalidParameter", "Invalid parameter: Token Reason: "+
+			"Endpoint "+endpoint+" already exists with the same Token, "+
+			"but different attributes."),
+		setAttributes,
+		snsError("EndpointDisabled", "Endpoint is disabled"),
+		setAttributes,
+		publish,
+		snsError("EndpointDisabled", "Endpoint is disabled"),
+		setAttributes,
+		snsError("EndpointDisabled", "Endpoint is disabled"),
+	}, statuses: []int{400, 200, 400, 200, 200, 400, 200, 400}}
+	a := newPagesTestAws(client)
+
+	arn, err := a.SNS.CreateEndpoint("arn:aws:sns:us-east-1:1:app/APNS/app",
+		"token1", "user1")
+	values, _ := url.ParseQuery(client.requests[1])
+	if err != nil || arn != endpoint ||
+		values.Get("EndpointArn") != endpoint {
+		t.Fatal("wrong existing endpoint:", arn, err, values)
+	}
+
+	// Disabled endpoint is enabled
+	id, err := a.SNS.PublishPush(endpoint, PushMessage{Title: "Hi",
+		Body: "Hello", Data: map[string]string{"order": "7"}})
+	if err != nil || id != "m1" {
+		t.Fatal("wrong publish result:", id, err)
+	}
+	values, _ = url.ParseQuery(client.requests[4])
+	var message map[string]string
+	if err = json.Unmarshal([]byte(values.Get("Message")), &message); err != nil ||
+		values.Get("MessageStructure") != "json" ||
+		message["default"] != "Hello" ||
+		message["APNS"] != `{"aps":{"alert":{"body":"Hello","title":"Hi"}},`+
+			`"order":"7"}` || !strings.Contains(message["GCM"], "fcmV1Message") {
+		t.Error("wrong push message:", message, err)
+	}
+
+	// Endpoint is disabled again
+	_, err = a.SNS.PublishPush(endpoint, PushMessage{Body: "Hello"})
+	if !errors.Is(err, ErrSNSEndpointDisabled) {
+		t.Error("wrong disabled endpoint error:", err)
+	}
+}
@@ -0,0 +1,218 @@
+package aws
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+)
+
+// SNSSubscriptionAttributes are the attributes of the SNS subscription. The
+// empty attributes are not set.
+type SNSSubscriptionAttributes struct {
+	// FilterPolicy is the filter policy of the subscription. The string and
+	// []byte are set as is, other values are marshaled to JSON, for example:
+	//
+	//	map[string]any{"kind": []string{"order", "refund"}}
+	FilterPolicy any
+
+	// FilterPolicyScope is the scope of the filter policy: "MessageAttributes"
+	// (default) or "MessageBody".
+	FilterPolicyScope string
+
+	// RawDelivery enables or disables the raw message delivery to the SQS
+	// and HTTP/S endpoints, without the SNS JSON envelope.
+	RawDelivery *bool
+}
+
+// attributes returns the SNS subscription attributes map.
+func (s SNSSubscriptionAttributes) attributes() (
+	attributes map[string]string, err error) {
+
+	attributes = make(map[string]string)
+	if s.FilterPolicy != nil {
+		if attributes["FilterPolicy"], err = messageBody(
+			s.FilterPolicy); err != nil {
+			return
+		}
+	}
+	if s.FilterPolicyScope != "" {
+		attributes["FilterPolicyScope"] = s.FilterPolicyScope
+	}
+	if s.RawDelivery != nil {
+		attributes["RawMessageDelivery"] = strconv.FormatBool(*s.RawDelivery)
+	}
+	return
+}
+
+// SNSSubscription is the subscription of the SNS topic.
+type SNSSubscription struct {
+	// SubscriptionArn is the subscription ARN. It is "PendingConfirmation"
+	// for not confirmed subscriptions.
+	SubscriptionArn string
+
+	// TopicArn is the topic ARN.
+	TopicArn string
+
+	// Protocol is the subscription protocol, for example "sqs", "lambda" or
+	// "https".
+	Protocol string
+
+	// Endpoint is the subscription endpoint, for example the SQS queue ARN.
+	Endpoint string
+
+	// Owner is the subscription owner account ID.
+	Owner string
+}
+
+// CreateTopic creates the topic or returns the ARN of the existing topic with
+// the same name. The FIFO topic is created if the name ends with ".fifo".
+//
+// Parameters:
+//   - name: The topic name.
+//
+// Returns:
+//   - topicArn: The topic ARN.
+//   - err: An error if the operation fails.
+func (a awsSNS) CreateTopic(name string) (topicArn string, err error) {
+	input := &sns.CreateTopicInput{Name: aws.String(name)}
+	if strings.HasSuffix(name, ".fifo") {
+		input.Attributes = map[string]string{"FifoTopic": "true"}
+	}
+	out, err := a.Client.CreateTopic(a.ctx, input)
+	if err != nil {
+		return
+	}
+	topicArn = aws.ToString(out.TopicArn)
+	return
+}
+
+// DeleteTopic deletes the topic and all its subscriptions.
+//
+// Parameters:
+//   - topicArn: The topic ARN.
+//
+// Returns:
+//   - err: An error if the operation fails.
+func (a awsSNS) DeleteTopic(topicArn string) (err error) {
+	_, err = a.Client.DeleteTopic(a.ctx, &sns.DeleteTopicInput{
+		TopicArn: aws.String(topicArn),
+	})
+	return
+}
+
+// Subscribe subscribes the endpoint to the topic.
+//
+// Parameters:
+//   - topicArn: The topic ARN.
+//   - protocol: The endpoint protocol, for example "sqs", "lambda", "https"
+//     or "email".
+//   - endpoint: The endpoint, for example the SQS queue ARN or the URL.
+//   - attrs: The optional subscription attributes.
+//
+// Returns:
+//   - subscriptionArn: The subscription ARN. The ARN is returned even if the
+//     subscription is pending confirmation.
+//   - err: An error if the operation fails.
+func (a awsSNS) Subscribe(topicArn, protocol, endpoint string,
+	attrs ...SNSSubscriptionAttributes) (subscriptionArn string, err error) {
+
+	input := &sns.SubscribeInput{
+		TopicArn:              aws.String(topicArn),
+		Protocol:              aws.String(protocol),
+		Endpoint:              aws.String(endpoint),
+		ReturnSubscriptionArn: true,
+	}
+	if len(attrs) > 0 {
+		if input.Attributes, err = attrs[0].attributes(); err != nil {
+			return
+		}
+	}
+	out, err := a.Client.Subscribe(a.ctx, input)
+	if err != nil {
+		return
+	}
+	subscriptionArn = aws.ToString(out.SubscriptionArn)
+
+	return
+}
+
+// Unsubscribe deletes the subscription.
+//
+// Parameters:
+//   - subscriptionArn: The subscription ARN.
+//
+// Returns:
+//   - err: An error if the operation fails.
+func (a awsSNS) Unsubscribe(subscriptionArn string) (err error) {
+	_, err = a.Client.Unsubscribe(a.ctx, &sns.UnsubscribeInput{
+		SubscriptionArn: aws.String(subscriptionArn),
+	})
+	return
+}
+
+// SetSubscriptionAttributes sets the not empty attributes of the
+// subscription, for example the filter policy or the raw delivery.
+//
+// Parameters:
+//   - subscriptionArn: The subscription ARN.
+//   - attrs: The subscription attributes.
+//
+// Returns:
+//   - err: An error if the operation fails.
+func (a awsSNS) SetSubscriptionAttributes(subscriptionArn string,
+	attrs SNSSubscriptionAttributes) (err error) {
+
+	attributes, err := attrs.attributes()
+	if err != nil {
+		return
+	}
+	for name, value := range attributes {
+		_, err = a.Client.SetSubscriptionAttributes(a.ctx,
+			&sns.SetSubscriptionAttributesInput{
+				SubscriptionArn: aws.String(subscriptionArn),
+				AttributeName:   aws.String(name),
+				AttributeValue:  aws.String(value),
+			},
+		)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// ListSubscriptionsByTopic returns all subscriptions of the topic.
+//
+// Parameters:
+//   - topicArn: The topic ARN.
+//
+// Returns:
+//   - subscriptions: The topic subscriptions.
+//   - err: An error if the operation fails.
+func (a awsSNS) ListSubscriptionsByTopic(topicArn string) (
+	subscriptions []SNSSubscription, err error) {
+
+	input := &sns.ListSubscriptionsByTopicInput{TopicArn: aws.String(topicArn)}
+	for {
+		var out *sns.ListSubscriptionsByTopicOutput
+		out, err = a.Client.ListSubscriptionsByTopic(a.ctx, input)
+		if err != nil {
+			return
+		}
+		for _, s := range out.Subscriptions {
+			subscriptions = append(subscriptions, SNSSubscription{
+				SubscriptionArn: aws.ToString(s.SubscriptionArn),
+				TopicArn:        aws.ToString(s.TopicArn),
+				Protocol:        aws.ToString(s.Protocol),
+				Endpoint:        aws.ToString(s.Endpoint),
+				Owner:           aws.ToString(s.Owner),
+			})
+		}
+		if out.NextToken == nil {
+			return
+		}
+		input.NextToken = out.NextToken
+	}
+}
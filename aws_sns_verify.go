@@ -0,0 +1,295 @@
+package aws
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+)
+
+// ErrSNSSignature is returned by SNSVerifier when the SNS message signature
+// or its signing certificate is not valid.
+var ErrSNSSignature = errors.New("sns message signature is not valid")
+
+// SNS message types.
+const (
+	SNSNotification             = "Notification"
+	SNSSubscriptionConfirmation = "SubscriptionConfirmation"
+	SNSUnsubscribeConfirmation  = "UnsubscribeConfirmation"
+)
+
+const (
+	// snsCertCacheTTL is the time to live of the cached signing certificates.
+	snsCertCacheTTL = 24 * time.Hour
+
+	// snsCertCacheSize is the maximum number of cached signing certificates.
+	snsCertCacheSize = 32
+
+	// snsHTTPTimeout is the timeout of the certificate and subscribe URL
+	// requests.
+	snsHTTPTimeout = 10 * time.Second
+)
+
+// snsHost matches the host of the SNS signing certificate and subscribe URLs.
+var snsHost = regexp.MustCompile(`^sns\.[a-z0-9-]+\.amazonaws\.com(\.cn)?$`)
+
+// SNSMessage is the SNS message delivered to the HTTP/S endpoint.
+type SNSMessage struct {
+	// The fields of the SNS message JSON document, see the SNS HTTP/S
+	// notifications documentation.
+	Type             string
+	MessageId        string
+	Token            string
+	TopicArn         string
+	Subject          string
+	Message          string
+	Timestamp        string
+	SignatureVersion string
+	Signature        string
+	SigningCertURL   string
+	SubscribeURL     string
+	UnsubscribeURL   string
+
+	// MessageAttributes are the message attributes.
+	MessageAttributes map[string]struct {
+		Type  string
+		Value string
+	}
+}
+
+// Unmarshal unmarshals the JSON message into v.
+func (m SNSMessage) Unmarshal(v any) error {
+	return json.Unmarshal([]byte(m.Message), v)
+}
+
+// stringToSign returns the message string signed by SNS.
+func (m SNSMessage) stringToSign() (s string, err error) {
+	var fields []string
+	switch m.Type {
+	case SNSNotification:
+		fields = []string{"Message", m.Message, "MessageId", m.MessageId}
+		if m.Subject != "" {
+			fields = append(fields, "Subject", m.Subject)
+		}
+		fields = append(fields, "Timestamp", m.Timestamp, "TopicArn",
+			m.TopicArn, "Type", m.Type)
+	case SNSSubscriptionConfirmation, SNSUnsubscribeConfirmation:
+		fields = []string{"Message", m.Message, "MessageId", m.MessageId,
+			"SubscribeURL", m.SubscribeURL, "Timestamp", m.Timestamp,
+			"Token", m.Token, "TopicArn", m.TopicArn, "Type", m.Type}
+	default:
+		err = fmt.Errorf("%w: unknown message type %q", ErrSNSSignature,
+			m.Type)
+		return
+	}
+	return strings.Join(fields, "\n") + "\n", nil
+}
+
+// SNSVerifier verifies the signatures of SNS messages received by HTTP/S
+// endpoints. The signing certificates are downloaded from the SNS hosts only
+// and are cached. The SNSVerifier is safe for concurrent use.
+type SNSVerifier struct {
+	// HTTPClient is the client used to download the signing certificates and
+	// confirm subscriptions.
+	HTTPClient *http.Client
+
+	// certs caches the signing certificates by URL
+	certs *LookupCache[string, *x509.Certificate]
+}
+
+// NewSNSVerifier creates new SNSVerifier.
+func NewSNSVerifier() (v *SNSVerifier) {
+	v = &SNSVerifier{HTTPClient: &http.Client{Timeout: snsHTTPTimeout}}
+	v.certs = NewLookupCache(v.certificate, nil)
+	v.certs.TTL = snsCertCacheTTL
+	v.certs.MaxEntries = snsCertCacheSize
+	return
+}
+
+// Parse reads the SNS message from the HTTP request body and verifies its
+// signature.
+//
+// Parameters:
+//   - r: The HTTP request body.
+//
+// Returns:
+//   - m: The verified message.
+//   - err: An error if the message can't be read or parsed, or wraps
+//     ErrSNSSignature if the message signature is not valid.
+func (v *SNSVerifier) Parse(r io.Reader) (m SNSMessage, err error) {
+	if err = json.NewDecoder(r).Decode(&m); err != nil {
+		return
+	}
+	err = v.Verify(m)
+	return
+}
+
+// Verify verifies the SNS message signature. The signature versions 1
+// (SHA1) and 2 (SHA256) are supported.
+//
+// Parameters:
+//   - m: The message.
+//
+// Returns:
+//   - err: An error which wraps ErrSNSSignature if the message signature is
+//     not valid, or the signing certificate download error.
+func (v *SNSVerifier) Verify(m SNSMessage) (err error) {
+
+	// Get hash of the signature version
+	var hash crypto.Hash
+	switch m.SignatureVersion {
+	case "1":
+		hash = crypto.SHA1
+	case "2":
+		hash = crypto.SHA256
+	default:
+		return fmt.Errorf("%w: unknown signature version %q",
+			ErrSNSSignature, m.SignatureVersion)
+	}
+
+	// Get signed string, signature and signing certificate
+	s, err := m.stringToSign()
+	if err != nil {
+		return
+	}
+	signature, err := base64.StdEncoding.DecodeString(m.Signature)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrSNSSignature, err)
+	}
+	if err = snsURL(m.SigningCertURL); err != nil {
+		return
+	}
+	if !strings.HasSuffix(m.SigningCertURL, ".pem") {
+		return fmt.Errorf("%w: wrong signing certificate url %s",
+			ErrSNSSignature, m.SigningCertURL)
+	}
+	cert, err := v.certs.Get(m.SigningCertURL)
+	if err != nil {
+		return
+	}
+	key, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return fmt.Errorf("%w: signing certificate key is not RSA",
+			ErrSNSSignature)
+	}
+
+	// Verify signature
+	var digest []byte
+	if hash == crypto.SHA1 {
+		sum := sha1.Sum([]byte(s))
+		digest = sum[:]
+	} else {
+		sum := sha256.Sum256([]byte(s))
+		digest = sum[:]
+	}
+	if err = rsa.VerifyPKCS1v15(key, hash, digest, signature); err != nil {
+		return fmt.Errorf("%w: %w", ErrSNSSignature, err)
+	}
+
+	return
+}
+
+// ConfirmSubscription confirms the subscription of the verified
+// SubscriptionConfirmation message by the request to its SubscribeURL.
+//
+// Parameters:
+//   - m: The verified SubscriptionConfirmation message.
+//
+// Returns:
+//   - err: An error if the message is not a subscription confirmation or
+//     the confirmation request fails.
+func (v *SNSVerifier) ConfirmSubscription(m SNSMessage) (err error) {
+	if m.Type != SNSSubscriptionConfirmation {
+		return fmt.Errorf("sns message type %s is not %s", m.Type,
+			SNSSubscriptionConfirmation)
+	}
+	if err = snsURL(m.SubscribeURL); err != nil {
+		return
+	}
+	resp, err := v.HTTPClient.Get(m.SubscribeURL)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		err = fmt.Errorf("sns subscription confirmation status %s",
+			resp.Status)
+	}
+	return
+}
+
+// certificate downloads and parses the signing certificate.
+func (v *SNSVerifier) certificate(certURL string) (cert *x509.Certificate,
+	err error) {
+
+	resp, err := v.HTTPClient.Get(certURL)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		err = fmt.Errorf("sns signing certificate status %s", resp.Status)
+		return
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		err = fmt.Errorf("%w: wrong signing certificate", ErrSNSSignature)
+		return
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// snsURL checks the URL is the https URL of the SNS host.
+func snsURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "https" || !snsHost.MatchString(u.Host) {
+		return fmt.Errorf("%w: url %q is not the sns url", ErrSNSSignature,
+			rawURL)
+	}
+	return nil
+}
+
+// ConfirmSubscription confirms the subscription by the token sent to the
+// endpoint in the SubscriptionConfirmation message.
+//
+// Parameters:
+//   - topicArn: The topic ARN.
+//   - token: The confirmation token.
+//
+// Returns:
+//   - subscriptionArn: The confirmed subscription ARN.
+//   - err: An error if the operation fails.
+func (a awsSNS) ConfirmSubscription(topicArn, token string) (
+	subscriptionArn string, err error) {
+
+	out, err := a.Client.ConfirmSubscription(a.ctx,
+		&sns.ConfirmSubscriptionInput{
+			TopicArn: aws.String(topicArn),
+			Token:    aws.String(token),
+		},
+	)
+	if err != nil {
+		return
+	}
+	subscriptionArn = aws.ToString(out.SubscriptionArn)
+	return
+}
@@ -0,0 +1,84 @@
+package aws
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// TestSNSVerifier checks SNS message signatures verification
+func TestSNSVerifier(t *testing.T) {
+
+	// Create signing certificate
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "sns.amazonaws.com"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template,
+		&key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	const certURL = "https://sns.us-east-1.amazonaws.com/SimpleNotificationService-1.pem"
+	v := NewSNSVerifier()
+	v.certs.Set(certURL, cert)
+
+	sign := func(m SNSMessage) SNSMessage {
+		s, _ := m.stringToSign()
+		var sig []byte
+		if m.SignatureVersion == "1" {
+			sum := sha1.Sum([]byte(s))
+			sig, _ = rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA1, sum[:])
+		} else {
+			sum := sha256.Sum256([]byte(s))
+			sig, _ = rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, sum[:])
+		}
+		m.Signature = base64.StdEncoding.EncodeToString(sig)
+		return m
+	}
+
+	// Valid signatures of both versions
+	m := SNSMessage{
+		Type:           SNSNotification,
+		MessageId:      "m1",
+		TopicArn:       "arn:aws:sns:us-east-1:1:events",
+		Message:        `{"id":7}`,
+		Timestamp:      "2024-01-01T00:00:00.000Z",
+		SigningCertURL: certURL,
+	}
+	for _, version := range []string{"1", "2"} {
+		m.SignatureVersion = version
+		if err = v.Verify(sign(m)); err != nil {
+			t.Error("valid signature version", version, "error:", err)
+		}
+	}
+
+	// Tampered message
+	signed := sign(m)
+	signed.Message = `{"id":8}`
+	if err = v.Verify(signed); !errors.Is(err, ErrSNSSignature) {
+		t.Error("wrong tampered message error:", err)
+	}
+
+	// Signing certificate from not SNS host
+	m.SigningCertURL = "https://sns.us-east-1.example.com/cert.pem"
+	if err = v.Verify(sign(m)); !errors.Is(err, ErrSNSSignature) {
+		t.Error("wrong certificate host error:", err)
+	}
+}
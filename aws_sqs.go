@@ -0,0 +1,369 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"iter"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+const (
+	// sqsMaxMessages is the maximum number of messages received by one
+	// ReceiveMessage request.
+	sqsMaxMessages = 10
+
+	// sqsMaxWait is the maximum long polling wait time.
+	sqsMaxWait = 20 * time.Second
+)
+
+// awsSQS is the AWS SQS client struct.
+type awsSQS struct {
+	// ctx is the context.Context for AWS requests
+	ctx context.Context
+
+	// Client is the AWS SQS client
+	Client *sqs.Client
+
+	// urls caches the queue URLs by queue names
+	urls *LookupCache[string, string]
+}
+
+// SQSMessage is the message received from the SQS queue.
+type SQSMessage struct {
+	// ID is the message ID.
+	ID string
+
+	// ReceiptHandle is the handle used to delete the message or change its
+	// visibility timeout.
+	ReceiptHandle string
+
+	// Body is the message body.
+	Body string
+
+	// Attributes are the message attributes with string values.
+	Attributes map[string]string
+
+	// GroupID is the message group ID of the FIFO queue message.
+	GroupID string
+
+	// ReceiveCount is the number of times the message was received.
+	ReceiveCount int
+
+	// SentAt is the time when the message was sent.
+	SentAt time.Time
+}
+
+// Unmarshal unmarshals the JSON message body into v.
+func (m SQSMessage) Unmarshal(v any) error {
+	return json.Unmarshal([]byte(m.Body), v)
+}
+
+// SQSSendOptions are the optional parameters of the SQS SendMessage.
+type SQSSendOptions struct {
+	// Delay is the time the message is invisible after sending, up to 15
+	// minutes.
+	Delay time.Duration
+
+	// GroupID is the message group ID, required for FIFO queues.
+	GroupID string
+
+	// DeduplicationID is the message deduplication ID of the FIFO queue
+	// message. It may be empty if the content-based deduplication is enabled.
+	DeduplicationID string
+
+	// Attributes are the message attributes with string values.
+	Attributes map[string]string
+}
+
+// init initializes the SQS client queue URLs cache.
+func (a *awsSQS) init() {
+	a.urls = NewLookupCache(a.queueURL, IsNotFound)
+}
+
+// QueueURL returns the queue URL by the queue name. The URLs are cached.
+//
+// Parameters:
+//   - queue: The queue name or URL. The URL is returned as is.
+//
+// Returns:
+//   - url: The queue URL.
+//   - err: An error if the operation fails. The error wraps ErrNotFound if
+//     the queue does not exist.
+func (a awsSQS) QueueURL(queue string) (url string, err error) {
+	if strings.HasPrefix(queue, "https://") ||
+		strings.HasPrefix(queue, "http://") {
+
+		url = queue
+		return
+	}
+	return a.urls.Get(queue)
+}
+
+// queueURL reads the queue URL by the queue name.
+func (a awsSQS) queueURL(name string) (url string, err error) {
+	out, err := a.Client.GetQueueUrl(a.ctx, &sqs.GetQueueUrlInput{
+		QueueName: aws.String(name),
+	})
+	if err != nil {
+		return
+	}
+	url = aws.ToString(out.QueueUrl)
+	return
+}
+
+// Queues returns iterator over the URLs of the queues which names start with
+// the prefix. The next pages are requested automatically while the URLs are
+// iterated.
+//
+// Parameters:
+//   - prefix: The queue name prefix. Empty prefix returns all queues.
+//
+// Returns:
+//   - iter.Seq2[string, error]: The queue URLs iterator. Iteration stops
+//     after the first error.
+func (a awsSQS) Queues(prefix string) iter.Seq2[string, error] {
+	return a.QueuesPages(prefix).Items()
+}
+
+// QueuesPages returns the paginator over the pages of the URLs of the queues
+// which names start with the prefix.
+//
+// Parameters:
+//   - prefix: The queue name prefix. Empty prefix returns all queues.
+//
+// Returns:
+//   - p: The queue URLs pages paginator.
+func (a awsSQS) QueuesPages(prefix string) (p *Paginator[string]) {
+	input := &sqs.ListQueuesInput{
+		QueueNamePrefix: optional(prefix),
+		MaxResults:      aws.Int32(1000),
+	}
+	return NewPaginator(func() (urls []string, more bool, err error) {
+		out, err := a.Client.ListQueues(a.ctx, input)
+		if err != nil {
+			return
+		}
+		input.NextToken = out.NextToken
+		return out.QueueUrls, aws.ToString(out.NextToken) != "", nil
+	})
+}
+
+// SendMessage sends the message to the queue.
+//
+// Parameters:
+//   - queue: The queue name or URL.
+//   - message: The message body. The string and []byte are sent as is, other
+//     values are marshaled to JSON.
+//   - opts: The optional send parameters.
+//
+// Returns:
+//   - id: The sent message ID.
+//   - err: An error if the operation fails.
+func (a awsSQS) SendMessage(queue string, message any,
+	opts ...SQSSendOptions) (id string, err error) {
+
+	url, err := a.QueueURL(queue)
+	if err != nil {
+		return
+	}
+	body, err := messageBody(message)
+	if err != nil {
+		return
+	}
+
+	input := &sqs.SendMessageInput{
+		QueueUrl:    aws.String(url),
+		MessageBody: aws.String(body),
+	}
+	if len(opts) > 0 {
+		o := opts[0]
+		input.DelaySeconds = int32(o.Delay / time.Second)
+		input.MessageGroupId = optional(o.GroupID)
+		input.MessageDeduplicationId = optional(o.DeduplicationID)
+		input.MessageAttributes = sqsAttributes(o.Attributes)
+	}
+	out, err := a.Client.SendMessage(a.ctx, input)
+	if err != nil {
+		return
+	}
+	id = aws.ToString(out.MessageId)
+
+	return
+}
+
+// ReceiveMessages receives messages from the queue. The received messages are
+// invisible for other receivers during the visibility timeout, delete them
+// with DeleteMessage after processing.
+//
+// Parameters:
+//   - queue: The queue name or URL.
+//   - maxMessages: The maximum number of messages, from 1 to 10.
+//   - wait: The long polling wait time, up to 20 seconds. The request returns
+//     as soon as any message is available or when the wait time expires.
+//     Zero means short polling.
+//   - visibility: The optional visibility timeout of the received messages.
+//     The queue visibility timeout is used if not set.
+//
+// Returns:
+//   - messages: The received messages, empty if there are no messages.
+//   - err: An error if the operation fails.
+func (a awsSQS) ReceiveMessages(queue string, maxMessages int,
+	wait time.Duration, visibility ...time.Duration) (messages []SQSMessage,
+	err error) {
+
+	url, err := a.QueueURL(queue)
+	if err != nil {
+		return
+	}
+	var v time.Duration
+	if len(visibility) > 0 {
+		v = visibility[0]
+	}
+	return a.receive(a.ctx, url, maxMessages, wait, v)
+}
+
+// receive receives messages from the queue URL.
+func (a awsSQS) receive(ctx context.Context, url string, maxMessages int,
+	wait, visibility time.Duration) (messages []SQSMessage, err error) {
+
+	input := &sqs.ReceiveMessageInput{
+		QueueUrl:            aws.String(url),
+		MaxNumberOfMessages: int32(min(max(maxMessages, 1), sqsMaxMessages)),
+		WaitTimeSeconds:     int32(min(wait, sqsMaxWait) / time.Second),
+		MessageSystemAttributeNames: []types.MessageSystemAttributeName{
+			types.MessageSystemAttributeNameAll,
+		},
+		MessageAttributeNames: []string{"All"},
+	}
+	if visibility > 0 {
+		input.VisibilityTimeout = int32(visibility / time.Second)
+	}
+	out, err := a.Client.ReceiveMessage(ctx, input)
+	if err != nil {
+		return
+	}
+
+	messages = make([]SQSMessage, len(out.Messages))
+	for i, m := range out.Messages {
+		messages[i] = sqsMessage(m)
+	}
+
+	return
+}
+
+// DeleteMessage deletes the received message from the queue.
+//
+// Parameters:
+//   - queue: The queue name or URL.
+//   - receiptHandle: The receipt handle of the received message.
+//
+// Returns:
+//   - err: An error if the operation fails.
+func (a awsSQS) DeleteMessage(queue, receiptHandle string) (err error) {
+	url, err := a.QueueURL(queue)
+	if err != nil {
+		return
+	}
+	_, err = a.Client.DeleteMessage(a.ctx, &sqs.DeleteMessageInput{
+		QueueUrl:      aws.String(url),
+		ReceiptHandle: aws.String(receiptHandle),
+	})
+	return
+}
+
+// ChangeMessageVisibility changes the visibility timeout of the received
+// message. Use it to extend the processing time of the message or to make it
+// visible immediately with zero timeout.
+//
+// Parameters:
+//   - queue: The queue name or URL.
+//   - receiptHandle: The receipt handle of the received message.
+//   - timeout: The new visibility timeout counted from now, up to 12 hours.
+//
+// Returns:
+//   - err: An error if the operation fails.
+func (a awsSQS) ChangeMessageVisibility(queue, receiptHandle string,
+	timeout time.Duration) (err error) {
+
+	url, err := a.QueueURL(queue)
+	if err != nil {
+		return
+	}
+	_, err = a.Client.ChangeMessageVisibility(a.ctx,
+		&sqs.ChangeMessageVisibilityInput{
+			QueueUrl:          aws.String(url),
+			ReceiptHandle:     aws.String(receiptHandle),
+			VisibilityTimeout: int32(timeout / time.Second),
+		},
+	)
+	return
+}
+
+// messageBody returns the message body: strings and []byte as is, other
+// values marshaled to JSON.
+func messageBody(message any) (body string, err error) {
+	switch m := message.(type) {
+	case string:
+		body = m
+	case []byte:
+		body = string(m)
+	default:
+		var data []byte
+		data, err = json.Marshal(message)
+		body = string(data)
+	}
+	return
+}
+
+// sqsAttributes converts the string attributes to the SQS message attributes.
+func sqsAttributes(attributes map[string]string) (
+	res map[string]types.MessageAttributeValue) {
+
+	if len(attributes) == 0 {
+		return
+	}
+	res = make(map[string]types.MessageAttributeValue, len(attributes))
+	for name, value := range attributes {
+		res[name] = types.MessageAttributeValue{
+			DataType:    aws.String("String"),
+			StringValue: aws.String(value),
+		}
+	}
+	return
+}
+
+// sqsMessage converts the SQS message to the SQSMessage.
+func sqsMessage(m types.Message) (message SQSMessage) {
+	attr := func(name types.MessageSystemAttributeName) string {
+		return m.Attributes[string(name)]
+	}
+	message = SQSMessage{
+		ID:            aws.ToString(m.MessageId),
+		ReceiptHandle: aws.ToString(m.ReceiptHandle),
+		Body:          aws.ToString(m.Body),
+		GroupID:       attr(types.MessageSystemAttributeNameMessageGroupId),
+	}
+	message.ReceiveCount, _ = strconv.Atoi(
+		attr(types.MessageSystemAttributeNameApproximateReceiveCount))
+	ms, err := strconv.ParseInt(
+		attr(types.MessageSystemAttributeNameSentTimestamp), 10, 64)
+	if err == nil {
+		message.SentAt = time.UnixMilli(ms)
+	}
+	for name, value := range m.MessageAttributes {
+		if value.StringValue == nil {
+			continue
+		}
+		if message.Attributes == nil {
+			message.Attributes = make(map[string]string)
+		}
+		message.Attributes[name] = *value.StringValue
+	}
+	return
+}
@@ -0,0 +1,150 @@
+package aws
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+// ErrSQSMessageTooLarge is returned by SQS SendBatch for the message which
+// is larger than the SQS message size limit.
+var ErrSQSMessageTooLarge = errors.New("sqs message is too large")
+
+const (
+	// sqsBatchSize is the maximum number of messages in one SendMessageBatch
+	// request.
+	sqsBatchSize = 10
+
+	// sqsBatchBytes is the maximum total size of the messages in one
+	// SendMessageBatch request.
+	sqsBatchBytes = 256 * 1024
+
+	// sqsBatchRetries is the number of retries of the failed messages.
+	sqsBatchRetries = 3
+
+	// sqsBatchDelay is the first delay before the retry of failed messages,
+	// the delay is doubled on each retry.
+	sqsBatchDelay = 100 * time.Millisecond
+)
+
+// SendBatch sends the messages to the queue. The messages are split to
+// batches of up to 10 messages and 256 KB, the messages failed with the
+// retryable errors are retried with backoff.
+//
+// Parameters:
+//   - queue: The queue name or URL.
+//   - msgs: The message bodies. The string and []byte are sent as is, other
+//     values are marshaled to JSON.
+//
+// Returns:
+//   - err: The *BatchError if some of the messages failed. The Item field of
+//     the failed message contains its index in msgs.
+func (a awsSQS) SendBatch(queue string, msgs []any) (err error) {
+	url, err := a.QueueURL(queue)
+	if err != nil {
+		return
+	}
+
+	// Marshal messages and split them to batches
+	var batchErr BatchError
+	var batches [][]types.SendMessageBatchRequestEntry
+	var batch []types.SendMessageBatchRequestEntry
+	size := 0
+	for i, msg := range msgs {
+		id := strconv.Itoa(i)
+		body, err := messageBody(msg)
+		switch {
+		case err != nil:
+			batchErr.add(id, err)
+			continue
+		case len(body) > sqsBatchBytes:
+			batchErr.add(id, ErrSQSMessageTooLarge)
+			continue
+		}
+		if len(batch) == sqsBatchSize || size+len(body) > sqsBatchBytes {
+			batches = append(batches, batch)
+			batch, size = nil, 0
+		}
+		batch = append(batch, types.SendMessageBatchRequestEntry{
+			Id:          aws.String(id),
+			MessageBody: aws.String(body),
+		})
+		size += len(body)
+	}
+	if len(batch) > 0 {
+		batches = append(batches, batch)
+	}
+
+	for _, batch := range batches {
+		a.sendBatch(url, batch, &batchErr)
+	}
+	err = batchErr.err()
+
+	return
+}
+
+// sendBatch sends the batch of messages and retries the messages failed with
+// the retryable errors. The results of all messages are added to the batch
+// error.
+func (a awsSQS) sendBatch(url string,
+	entries []types.SendMessageBatchRequestEntry, batchErr *BatchError) {
+
+	var lastErr error
+	delay := sqsBatchDelay
+	for retry := 0; len(entries) > 0; retry++ {
+
+		// Fail messages when retries are over
+		if retry > sqsBatchRetries {
+			for _, entry := range entries {
+				batchErr.add(aws.ToString(entry.Id), lastErr)
+			}
+			return
+		}
+
+		// Wait before retry
+		if retry > 0 {
+			sleep(clock(a.ctx), delay)
+			delay *= 2
+		}
+
+		// Send messages, all messages fail on not retryable request error
+		out, err := a.Client.SendMessageBatch(a.ctx,
+			&sqs.SendMessageBatchInput{QueueUrl: aws.String(url),
+				Entries: entries})
+		if err != nil {
+			lastErr = err
+			if IsRetryable(err) {
+				continue
+			}
+			for _, entry := range entries {
+				batchErr.add(aws.ToString(entry.Id), err)
+			}
+			return
+		}
+		for _, entry := range out.Successful {
+			batchErr.add(aws.ToString(entry.Id), nil)
+		}
+
+		// Keep messages failed by the service fault for retry
+		var next []types.SendMessageBatchRequestEntry
+		for _, failed := range out.Failed {
+			err := entryError(aws.ToString(failed.Code),
+				aws.ToString(failed.Message))
+			if failed.SenderFault {
+				batchErr.add(aws.ToString(failed.Id), err)
+				continue
+			}
+			lastErr = err
+			for _, entry := range entries {
+				if aws.ToString(entry.Id) == aws.ToString(failed.Id) {
+					next = append(next, entry)
+				}
+			}
+		}
+		entries = next
+	}
+}
@@ -0,0 +1,170 @@
+package aws
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	// sqsConsumeVisibility is the default visibility timeout of the messages
+	// received by Consume.
+	sqsConsumeVisibility = 30 * time.Second
+
+	// sqsConsumeRetry is the delay before the retry of the failed receive.
+	sqsConsumeRetry = time.Second
+)
+
+// SQSConsumeOptions are the optional parameters of the SQS Consume.
+type SQSConsumeOptions struct {
+	// Concurrency is the maximum number of concurrently running handlers.
+	// Default is 1.
+	Concurrency int
+
+	// Wait is the long polling wait time, up to 20 seconds. Default is 20
+	// seconds.
+	Wait time.Duration
+
+	// Visibility is the visibility timeout of the received messages. The
+	// visibility timeout of the message is extended while its handler is
+	// running. Default is 30 seconds.
+	Visibility time.Duration
+
+	// OnError is called when the handler, delete or visibility change of the
+	// message fails. The failed message becomes visible in the queue after
+	// the visibility timeout and is received again.
+	OnError func(message SQSMessage, err error)
+}
+
+// Consume receives messages from the queue and calls handler for each of
+// them until ctx is canceled. The message is deleted from the queue when its
+// handler succeeds, and becomes visible again after the visibility timeout
+// when the handler fails. The visibility timeout of the message is extended
+// while its handler is running.
+//
+// When ctx is canceled Consume stops receiving messages, waits for the
+// running handlers and returns.
+//
+// Parameters:
+//   - ctx: The context to stop consuming.
+//   - queue: The queue name or URL.
+//   - handler: The function called for each message.
+//   - opts: The optional consume parameters.
+//
+// Returns:
+//   - err: The queue URL resolution or not retryable receive error. Nil is
+//     returned when ctx is canceled.
+func (a awsSQS) Consume(ctx context.Context, queue string,
+	handler func(message SQSMessage) error,
+	opts ...SQSConsumeOptions) (err error) {
+
+	url, err := a.QueueURL(queue)
+	if err != nil {
+		return
+	}
+
+	// Set default options
+	var o SQSConsumeOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	o.Concurrency = max(o.Concurrency, 1)
+	if o.Wait <= 0 {
+		o.Wait = sqsMaxWait
+	}
+	if o.Visibility <= 0 {
+		o.Visibility = sqsConsumeVisibility
+	}
+	if o.OnError == nil {
+		o.OnError = func(SQSMessage, error) {}
+	}
+
+	// The sem contains the slots of running handlers
+	sem := make(chan struct{}, o.Concurrency)
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for ctx.Err() == nil {
+		// Wait for free handler slot and take all free slots
+		select {
+		case <-ctx.Done():
+			return nil
+		case sem <- struct{}{}:
+		}
+		slots := 1
+	take:
+		for ; slots < sqsMaxMessages; slots++ {
+			select {
+			case sem <- struct{}{}:
+			default:
+				break take
+			}
+		}
+
+		// Receive messages
+		var messages []SQSMessage
+		messages, err = a.receive(ctx, url, slots, o.Wait, o.Visibility)
+		for range slots - len(messages) {
+			<-sem
+		}
+		switch {
+		case err == nil:
+		case ctx.Err() != nil:
+			return nil
+		case !IsRetryable(err):
+			return
+		default:
+			select {
+			case <-ctx.Done():
+			case <-clock(a.ctx).After(max(RetryAfter(err), sqsConsumeRetry)):
+			}
+			continue
+		}
+
+		// Execute handlers
+		for _, m := range messages {
+			wg.Add(1)
+			go func() {
+				defer func() { <-sem; wg.Done() }()
+				a.handle(url, m, handler, o)
+			}()
+		}
+	}
+
+	return
+}
+
+// handle executes the message handler, extends the message visibility timeout
+// while the handler is running and deletes the message when the handler
+// succeeds.
+func (a awsSQS) handle(url string, m SQSMessage,
+	handler func(message SQSMessage) error, o SQSConsumeOptions) {
+
+	// Extend visibility timeout while the handler is running
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := clock(a.ctx).NewTicker(o.Visibility / 2)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C():
+				err := a.ChangeMessageVisibility(url, m.ReceiptHandle,
+					o.Visibility)
+				if err != nil {
+					o.OnError(m, err)
+				}
+			}
+		}
+	}()
+
+	if err := handler(m); err != nil {
+		o.OnError(m, err)
+		return
+	}
+	if err := a.DeleteMessage(url, m.ReceiptHandle); err != nil {
+		o.OnError(m, err)
+	}
+}
@@ -0,0 +1,467 @@
+package aws
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+// sqsCopyEmptyReceives is the default number of consecutive empty receives
+// which stop the SQS CopyMessages.
+const sqsCopyEmptyReceives = 3
+
+// ErrSQSNoRedrivePolicy is returned by SQS RedrivePolicy and DeadLetterQueue
+// when the queue has no dead-letter queue. It wraps ErrNotFound.
+var ErrSQSNoRedrivePolicy = fmt.Errorf("sqs redrive policy %w", ErrNotFound)
+
+// SQSRedrivePolicy is the dead-letter queue configuration of the SQS queue.
+type SQSRedrivePolicy struct {
+	// DeadLetterQueueArn is the ARN of the dead-letter queue.
+	DeadLetterQueueArn string
+
+	// MaxReceiveCount is the number of receives after which the message is
+	// moved to the dead-letter queue.
+	MaxReceiveCount int
+}
+
+// SQSQueueCounts is the approximate number of messages in the SQS queue.
+type SQSQueueCounts struct {
+	// Visible is the number of messages available for receive.
+	Visible int
+
+	// InFlight is the number of received but not deleted messages.
+	InFlight int
+
+	// Delayed is the number of delayed messages not yet available for
+	// receive.
+	Delayed int
+}
+
+// SQSMoveTask is the status of the message move task started by SQS Redrive.
+type SQSMoveTask struct {
+	// TaskHandle is the task handle. It is set for the running tasks only.
+	TaskHandle string
+
+	// Status is the task status: RUNNING, COMPLETED, CANCELLING, CANCELLED
+	// or FAILED.
+	Status string
+
+	// SourceArn is the ARN of the dead-letter queue.
+	SourceArn string
+
+	// DestinationArn is the ARN of the destination queue. It is empty if the
+	// messages are moved to their original source queues.
+	DestinationArn string
+
+	// Moved is the approximate number of moved messages.
+	Moved int64
+
+	// ToMove is the approximate number of messages to move.
+	ToMove int64
+
+	// FailureReason is the reason of the failed task.
+	FailureReason string
+
+	// StartedAt is the time when the task was started.
+	StartedAt time.Time
+}
+
+// SQSCopyOptions are the optional parameters of SQS CopyMessages.
+type SQSCopyOptions struct {
+	// Wait is the long polling wait time of the receives. Default and maximum
+	// is 20 seconds.
+	Wait time.Duration
+
+	// EmptyReceives is the number of consecutive empty receives which stop
+	// the copy even if the queue approximate number of messages is not zero.
+	// Default is 3.
+	EmptyReceives int
+}
+
+// Running returns true if the task is running or cancelling.
+func (t SQSMoveTask) Running() bool {
+	return t.Status == "RUNNING" || t.Status == "CANCELLING"
+}
+
+// attributes returns the queue attributes.
+func (a awsSQS) attributes(queue string, names ...types.QueueAttributeName) (
+	attributes map[string]string, err error) {
+
+	url, err := a.QueueURL(queue)
+	if err != nil {
+		return
+	}
+	out, err := a.Client.GetQueueAttributes(a.ctx,
+		&sqs.GetQueueAttributesInput{
+			QueueUrl:       aws.String(url),
+			AttributeNames: names,
+		},
+	)
+	if err != nil {
+		return
+	}
+	attributes = out.Attributes
+	return
+}
+
+// QueueArn returns the queue ARN.
+//
+// Parameters:
+//   - queue: The queue name or URL.
+//
+// Returns:
+//   - arn: The queue ARN.
+//   - err: An error if the operation fails.
+func (a awsSQS) QueueArn(queue string) (arn string, err error) {
+	attributes, err := a.attributes(queue, types.QueueAttributeNameQueueArn)
+	if err != nil {
+		return
+	}
+	arn = attributes[string(types.QueueAttributeNameQueueArn)]
+	return
+}
+
+// RedrivePolicy returns the dead-letter queue configuration of the queue.
+//
+// Parameters:
+//   - queue: The queue name or URL.
+//
+// Returns:
+//   - policy: The redrive policy.
+//   - err: An error if the operation fails. The ErrSQSNoRedrivePolicy is
+//     returned if the queue has no dead-letter queue.
+func (a awsSQS) RedrivePolicy(queue string) (policy SQSRedrivePolicy,
+	err error) {
+
+	attributes, err := a.attributes(queue,
+		types.QueueAttributeNameRedrivePolicy)
+	if err != nil {
+		return
+	}
+	data, ok := attributes[string(types.QueueAttributeNameRedrivePolicy)]
+	if !ok || data == "" {
+		err = ErrSQSNoRedrivePolicy
+		return
+	}
+	var v struct {
+		DeadLetterTargetArn string
+		MaxReceiveCount     json.Number
+	}
+	if err = json.Unmarshal([]byte(data), &v); err != nil {
+		return
+	}
+	policy.DeadLetterQueueArn = v.DeadLetterTargetArn
+	policy.MaxReceiveCount, _ = strconv.Atoi(v.MaxReceiveCount.String())
+
+	return
+}
+
+// DeadLetterQueue returns the dead-letter queue URL of the queue.
+//
+// Parameters:
+//   - queue: The queue name or URL.
+//
+// Returns:
+//   - url: The dead-letter queue URL.
+//   - err: An error if the operation fails. The ErrSQSNoRedrivePolicy is
+//     returned if the queue has no dead-letter queue.
+func (a awsSQS) DeadLetterQueue(queue string) (url string, err error) {
+	policy, err := a.RedrivePolicy(queue)
+	if err != nil {
+		return
+	}
+
+	// Get queue name and account from the ARN
+	// arn:aws:sqs:us-east-1:123456789012:queue-name
+	parts := strings.Split(policy.DeadLetterQueueArn, ":")
+	if len(parts) != 6 {
+		err = fmt.Errorf("wrong dead-letter queue arn %s",
+			policy.DeadLetterQueueArn)
+		return
+	}
+	out, err := a.Client.GetQueueUrl(a.ctx, &sqs.GetQueueUrlInput{
+		QueueName:              aws.String(parts[5]),
+		QueueOwnerAWSAccountId: aws.String(parts[4]),
+	})
+	if err != nil {
+		return
+	}
+	url = aws.ToString(out.QueueUrl)
+
+	return
+}
+
+// Counts returns the approximate number of messages in the queue.
+//
+// Parameters:
+//   - queue: The queue name or URL.
+//
+// Returns:
+//   - counts: The number of visible, in flight and delayed messages.
+//   - err: An error if the operation fails.
+func (a awsSQS) Counts(queue string) (counts SQSQueueCounts, err error) {
+	attributes, err := a.attributes(queue,
+		types.QueueAttributeNameApproximateNumberOfMessages,
+		types.QueueAttributeNameApproximateNumberOfMessagesNotVisible,
+		types.QueueAttributeNameApproximateNumberOfMessagesDelayed,
+	)
+	if err != nil {
+		return
+	}
+	count := func(name types.QueueAttributeName) (n int) {
+		n, _ = strconv.Atoi(attributes[string(name)])
+		return
+	}
+	counts.Visible = count(
+		types.QueueAttributeNameApproximateNumberOfMessages)
+	counts.InFlight = count(
+		types.QueueAttributeNameApproximateNumberOfMessagesNotVisible)
+	counts.Delayed = count(
+		types.QueueAttributeNameApproximateNumberOfMessagesDelayed)
+
+	return
+}
+
+// PeekMessages receives messages from the queue and makes them visible
+// again immediately, so they are not deleted and stay available to other
+// receivers. The receive count of the messages is incremented, so do not peek
+// the queues with the redrive policy.
+//
+// Parameters:
+//   - queue: The queue name or URL, usually the dead-letter queue.
+//   - maxMessages: The maximum number of messages, from 1 to 10.
+//
+// Returns:
+//   - messages: The received messages.
+//   - err: An error if the operation fails.
+func (a awsSQS) PeekMessages(queue string, maxMessages int) (
+	messages []SQSMessage, err error) {
+
+	url, err := a.QueueURL(queue)
+	if err != nil {
+		return
+	}
+	messages, err = a.receive(a.ctx, url, maxMessages, 0,
+		sqsConsumeVisibility)
+	if err != nil || len(messages) == 0 {
+		return
+	}
+
+	// Make messages visible
+	entries := make([]types.ChangeMessageVisibilityBatchRequestEntry,
+		len(messages))
+	for i, m := range messages {
+		entries[i] = types.ChangeMessageVisibilityBatchRequestEntry{
+			Id:            aws.String(strconv.Itoa(i)),
+			ReceiptHandle: aws.String(m.ReceiptHandle),
+		}
+	}
+	_, err = a.Client.ChangeMessageVisibilityBatch(a.ctx,
+		&sqs.ChangeMessageVisibilityBatchInput{
+			QueueUrl: aws.String(url),
+			Entries:  entries,
+		},
+	)
+
+	return
+}
+
+// Redrive starts the task which moves the messages from the dead-letter queue
+// back to their source queues. Use RedriveStatus or WaitRedrive to get the
+// task progress.
+//
+// Parameters:
+//   - dlq: The dead-letter queue name or URL.
+//   - maxPerSecond: The optional maximum number of messages moved per
+//     second. The rate is set by SQS if not set.
+//
+// Returns:
+//   - taskHandle: The task handle.
+//   - err: An error if the operation fails.
+func (a awsSQS) Redrive(dlq string, maxPerSecond ...int) (taskHandle string,
+	err error) {
+
+	arn, err := a.QueueArn(dlq)
+	if err != nil {
+		return
+	}
+	input := &sqs.StartMessageMoveTaskInput{SourceArn: aws.String(arn)}
+	if len(maxPerSecond) > 0 {
+		input.MaxNumberOfMessagesPerSecond = aws.Int32(int32(maxPerSecond[0]))
+	}
+	out, err := a.Client.StartMessageMoveTask(a.ctx, input)
+	if err != nil {
+		return
+	}
+	taskHandle = aws.ToString(out.TaskHandle)
+
+	return
+}
+
+// RedriveStatus returns the status of the last message move task of the
+// dead-letter queue.
+//
+// Parameters:
+//   - dlq: The dead-letter queue name or URL.
+//
+// Returns:
+//   - task: The last task status.
+//   - err: An error if the operation fails. The error wraps ErrNotFound if
+//     the queue has no message move tasks.
+func (a awsSQS) RedriveStatus(dlq string) (task SQSMoveTask, err error) {
+	arn, err := a.QueueArn(dlq)
+	if err != nil {
+		return
+	}
+	out, err := a.Client.ListMessageMoveTasks(a.ctx,
+		&sqs.ListMessageMoveTasksInput{
+			SourceArn:  aws.String(arn),
+			MaxResults: aws.Int32(1),
+		},
+	)
+	if err != nil {
+		return
+	}
+	if len(out.Results) == 0 {
+		err = fmt.Errorf("sqs message move task %w", ErrNotFound)
+		return
+	}
+
+	r := out.Results[0]
+	task = SQSMoveTask{
+		TaskHandle:     aws.ToString(r.TaskHandle),
+		Status:         aws.ToString(r.Status),
+		SourceArn:      aws.ToString(r.SourceArn),
+		DestinationArn: aws.ToString(r.DestinationArn),
+		Moved:          r.ApproximateNumberOfMessagesMoved,
+		ToMove:         aws.ToInt64(r.ApproximateNumberOfMessagesToMove),
+		FailureReason:  aws.ToString(r.FailureReason),
+		StartedAt:      time.UnixMilli(r.StartedTimestamp),
+	}
+
+	return
+}
+
+// WaitRedrive polls the status of the last message move task of the
+// dead-letter queue every interval until the task is finished.
+//
+// Parameters:
+//   - dlq: The dead-letter queue name or URL.
+//   - interval: The polling interval.
+//   - progress: The optional function called with the task status after
+//     each poll. May be nil.
+//
+// Returns:
+//   - task: The finished task status.
+//   - err: An error if the operation fails or the task failed.
+func (a awsSQS) WaitRedrive(dlq string, interval time.Duration,
+	progress func(task SQSMoveTask)) (task SQSMoveTask, err error) {
+
+	for {
+		if task, err = a.RedriveStatus(dlq); err != nil {
+			return
+		}
+		if progress != nil {
+			progress(task)
+		}
+		if !task.Running() {
+			break
+		}
+		sleep(clock(a.ctx), interval)
+	}
+	if task.Status == "FAILED" {
+		err = fmt.Errorf("sqs message move task failed: %s",
+			task.FailureReason)
+	}
+
+	return
+}
+
+// CopyMessages moves the messages from one queue to another by receiving,
+// sending and deleting them. Use it instead of Redrive when the message move
+// task is not supported, for example for FIFO queues, or to move messages to
+// the queue which is not their source queue. The messages are received by
+// the long polling, which queries all SQS servers, and moved until the
+// receive is empty and the source queue approximate number of messages is
+// zero, or until the EmptyReceives consecutive receives are empty.
+//
+// Parameters:
+//   - from: The source queue name or URL, usually the dead-letter queue.
+//   - to: The destination queue name or URL.
+//   - progress: The optional function called with the number of moved
+//     messages after each batch. May be nil.
+//   - opts: The optional copy parameters.
+//
+// Returns:
+//   - moved: The number of moved messages.
+//   - err: An error if the operation fails. The messages which were sent
+//     but not deleted are received again and duplicated in the destination
+//     queue by the next CopyMessages call.
+func (a awsSQS) CopyMessages(from, to string, progress func(moved int),
+	opts ...SQSCopyOptions) (moved int, err error) {
+
+	var o SQSCopyOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	if o.Wait <= 0 {
+		o.Wait = sqsMaxWait
+	}
+	if o.EmptyReceives <= 0 {
+		o.EmptyReceives = sqsCopyEmptyReceives
+	}
+
+	fromURL, err := a.QueueURL(from)
+	if err != nil {
+		return
+	}
+	toURL, err := a.QueueURL(to)
+	if err != nil {
+		return
+	}
+
+	for empty := 0; ; {
+		var messages []SQSMessage
+		messages, err = a.receive(a.ctx, fromURL, sqsMaxMessages, o.Wait,
+			sqsConsumeVisibility)
+		if err != nil {
+			return
+		}
+
+		// Stop when the queue is empty
+		if len(messages) == 0 {
+			if empty++; empty >= o.EmptyReceives {
+				return
+			}
+			var counts SQSQueueCounts
+			if counts, err = a.Counts(fromURL); err != nil ||
+				counts.Visible == 0 {
+				return
+			}
+			continue
+		}
+		empty = 0
+		for _, m := range messages {
+			opts := SQSSendOptions{Attributes: m.Attributes}
+			if m.GroupID != "" {
+				opts.GroupID, opts.DeduplicationID = m.GroupID, m.ID
+			}
+			if _, err = a.SendMessage(toURL, m.Body, opts); err != nil {
+				return
+			}
+			if err = a.DeleteMessage(fromURL, m.ReceiptHandle); err != nil {
+				return
+			}
+			moved++
+		}
+		if progress != nil {
+			progress(moved)
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestSQSMessages checks SQS queue URL resolution, send and receive
+func TestSQSMessages(t *testing.T) {
+
+	const url = "https://sqs.us-east-1.amazonaws.com/1/jobs"
+	client := &pagesHTTPClient{bodies: []string{
+		`{"QueueUrl":"` + url + `"}`,
+		`{"MessageId":"m1"}`,
+		`{"MessageId":"m2"}`,
+		`{"Messages":[{"MessageId":"m1","ReceiptHandle":"r1",` +
+			`"Body":"{\"id\":7}","Attributes":{"ApproximateReceiveCount":"2",` +
+			`"SentTimestamp":"1700000000000"},"MessageAttributes":` +
+			`{"kind":{"DataType":"String","StringValue":"job"}}}]}`,
+	}}
+	a := newPagesTestAws(client)
+
+	// Send messages, the queue URL is resolved once
+	id, err := a.SQS.SendMessage("jobs", map[string]int{"id": 7},
+		SQSSendOptions{Attributes: map[string]string{"kind": "job"}})
+	if err != nil || id != "m1" {
+		t.Fatal("wrong send result:", id, err)
+	}
+	if _, err = a.SQS.SendMessage("jobs", "text"); err != nil {
+		t.Fatal("send error:", err)
+	}
+	if len(client.requests) != 3 ||
+		!strings.Contains(client.requests[1], `"MessageBody":"{\"id\":7}"`) ||
+		!strings.Contains(client.requests[2], `"QueueUrl":"`+url+`"`) {
+		t.Error("wrong send requests:", client.requests)
+	}
+
+	// Receive messages
+	messages, err := a.SQS.ReceiveMessages(url, 20, time.Minute)
+	if err != nil || len(messages) != 1 {
+		t.Fatal("wrong receive result:", messages, err)
+	}
+	var v struct{ ID int }
+	m := messages[0]
+	if err = m.Unmarshal(&v); err != nil || v.ID != 7 || m.ReceiveCount != 2 ||
+		m.Attributes["kind"] != "job" || m.SentAt.UnixMilli() != 1700000000000 {
+		t.Error("wrong message:", m, v, err)
+	}
+	if !strings.Contains(client.requests[3], `"MaxNumberOfMessages":10`) ||
+		!strings.Contains(client.requests[3], `"WaitTimeSeconds":20`) {
+		t.Error("wrong receive request:", client.requests[3])
+	}
+
+	// Queue does not exist
+	a = newErrorTestAws(http.StatusBadRequest, `{"__type":`+
+		`"com.amazonaws.sqs#QueueDoesNotExist","message":"no queue"}`)
+	if _, err = a.SQS.SendMessage("missing", "text"); !IsNotFound(err) {
+		t.Error("wrong not found error:", err)
+	}
+}
+
+// TestSQSConsume checks SQS consumer deletes handled messages only
+func TestSQSConsume(t *testing.T) {
+
+	const url = "https://sqs.us-east-1.amazonaws.com/1/jobs"
+	client := &pagesHTTPClient{bodies: []string{
+		`{"Messages":[{"MessageId":"m1","ReceiptHandle":"r1","Body":"1"}]}`,
+		`{"Messages":[{"MessageId":"m2","ReceiptHandle":"r2","Body":"2"}]}`,
+		`{}`,
+	}}
+	a := newPagesTestAws(client)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var failed []string
+	err := a.SQS.Consume(ctx, url, func(m SQSMessage) error {
+		if m.ID == "m1" {
+			return errors.New("handler error")
+		}
+		cancel()
+		return nil
+	}, SQSConsumeOptions{OnError: func(m SQSMessage, err error) {
+		failed = append(failed, m.ID)
+	}})
+	if err != nil {
+		t.Fatal("consume error:", err)
+	}
+	if len(failed) != 1 || failed[0] != "m1" {
+		t.Error("wrong failed messages:", failed)
+	}
+	if len(client.requests) != 3 ||
+		!strings.Contains(client.requests[0], `"MaxNumberOfMessages":1`) ||
+		!strings.Contains(client.requests[2], `"ReceiptHandle":"r2"`) {
+		t.Error("wrong consume requests:", client.requests)
+	}
+}
+
+// TestSQSSendBatch checks SQS batches split and failed messages retry
+func TestSQSSendBatch(t *testing.T) {
+
+	const url = "https://sqs.us-east-1.amazonaws.com/1/jobs"
+	client := &pagesHTTPClient{bodies: []string{
+		`{"Successful":[{"Id":"0"},{"Id":"1"},{"Id":"2"},{"Id":"3"},` +
+			`{"Id":"4"},{"Id":"5"},{"Id":"6"},{"Id":"7"}],"Failed":[` +
+			`{"Id":"8","Code":"InternalError","SenderFault":false},` +
+			`{"Id":"9","Code":"InvalidParameterValue","SenderFault":true}]}`,
+		`{"Successful":[{"Id":"8"}]}`,
+		`{"Successful":[{"Id":"10"}]}`,
+	}}
+	a := newPagesTestAws(client)
+
+	msgs := make([]any, 12)
+	for i := range msgs {
+		msgs[i] = i
+	}
+	msgs[11] = strings.Repeat("x", sqsBatchBytes+1)
+	err := a.SQS.SendBatch(url, msgs)
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) || batchErr.Total != 12 ||
+		len(batchErr.Failed) != 2 {
+		t.Fatal("wrong batch error:", err)
+	}
+	failed := map[string]error{}
+	for _, item := range batchErr.Failed {
+		failed[item.Item] = item.Err
+	}
+	var awsErr *Error
+	if !errors.Is(failed["11"], ErrSQSMessageTooLarge) ||
+		!errors.As(failed["9"], &awsErr) ||
+		awsErr.Code() != "InvalidParameterValue" {
+		t.Error("wrong failed messages:", batchErr.Failed)
+	}
+	if len(client.requests) != 3 ||
+		!strings.Contains(client.requests[1], `"Id":"8"`) ||
+		strings.Contains(client.requests[1], `"Id":"9"`) {
+		t.Error("wrong batch requests:", client.requests)
+	}
+}
+
+// TestSQSDeadLetterQueue checks SQS redrive policy, counts and redrive
+func TestSQSDeadLetterQueue(t *testing.T) {
+
+	const url = "https://sqs.us-east-1.amazonaws.com/1/jobs"
+	const dlqURL = "https://sqs.us-east-1.amazonaws.com/1/jobs-dlq"
+	const dlqArn = "arn:aws:sqs:us-east-1:1:jobs-dlq"
+	client := &pagesHTTPClient{bodies: []string{
+		`{"Attributes":{"RedrivePolicy":"{\"deadLetterTargetArn\":\"` +
+			dlqArn + `\",\"maxReceiveCount\":5}"}}`,
+		`{"QueueUrl":"` + dlqURL + `"}`,
+		`{"Attributes":{"ApproximateNumberOfMessages":"3",` +
+			`"ApproximateNumberOfMessagesNotVisible":"1"}}`,
+		`{"Attributes":{"QueueArn":"` + dlqArn + `"}}`,
+		`{"TaskHandle":"task1"}`,
+		`{"Attributes":{"QueueArn":"` + dlqArn + `"}}`,
+		`{"Results":[{"Status":"COMPLETED","ApproximateNumberOfMessagesMoved":3,` +
+			`"ApproximateNumberOfMessagesToMove":3,"SourceArn":"` + dlqArn + `"}]}`,
+		`{"Attributes":{}}`,
+	}}
+	a := newPagesTestAws(client)
+
+	dlq, err := a.SQS.DeadLetterQueue(url)
+	if err != nil || dlq != dlqURL {
+		t.Fatal("wrong dead-letter queue:", dlq, err)
+	}
+	if !strings.Contains(client.requests[1], `"QueueName":"jobs-dlq"`) {
+		t.Error("wrong queue url request:", client.requests[1])
+	}
+	counts, err := a.SQS.Counts(dlq)
+	if err != nil || counts.Visible != 3 || counts.InFlight != 1 {
+		t.Error("wrong counts:", counts, err)
+	}
+
+	// Redrive and wait for the task
+	task, err := a.SQS.Redrive(dlq)
+	if err != nil || task != "task1" {
+		t.Fatal("wrong redrive result:", task, err)
+	}
+	var progress []int64
+	status, err := a.SQS.WaitRedrive(dlq, time.Millisecond,
+		func(task SQSMoveTask) { progress = append(progress, task.Moved) })
+	if err != nil || status.Status != "COMPLETED" || len(progress) != 1 ||
+		progress[0] != 3 {
+		t.Error("wrong redrive status:", status, progress, err)
+	}
+
+	// Queue without dead-letter queue
+	_, err = a.SQS.RedrivePolicy(url)
+	if !errors.Is(err, ErrSQSNoRedrivePolicy) || !IsNotFound(err) {
+		t.Error("wrong no redrive policy error:", err)
+	}
+}
+
+// TestSQSCopyMessages checks the messages are received by the long polling
+// until the queue is empty
+func TestSQSCopyMessages(t *testing.T) {
+
+	const from = "https://sqs.us-east-1.amazonaws.com/1/jobs-dlq"
+	const to = "https://sqs.us-east-1.amazonaws.com/1/jobs"
+	message := func(id string) string {
+		return `{"Messages":[{"MessageId":"` + id + `","ReceiptHandle":"r` +
+			id + `","Body":"b` + id + `"}]}`
+	}
+	counts := func(n string) string {
+		return `{"Attributes":{"ApproximateNumberOfMessages":"` + n + `"}}`
+	}
+	client := &pagesHTTPClient{bodies: []string{
+		message("1"), `{"MessageId":"n1"}`, `{}`,
+		`{}`, counts("1"),
+		message("2"), `{"MessageId":"n2"}`, `{}`,
+		`{}`, counts("0"),
+	}}
+	a := newPagesTestAws(client)
+
+	// The empty receive continues while the queue has messages
+	var progress []int
+	moved, err := a.SQS.CopyMessages(from, to,
+		func(moved int) { progress = append(progress, moved) })
+	if err != nil || moved != 2 || len(progress) != 2 ||
+		len(client.requests) != 10 {
+		t.Fatal("wrong copy result:", moved, progress, err,
+			len(client.requests))
+	}
+	if !strings.Contains(client.requests[0], `"WaitTimeSeconds":20`) ||
+		!strings.Contains(client.requests[1], `"MessageBody":"b1"`) {
+		t.Error("wrong copy requests:", client.requests[:2])
+	}
+
+	// The consecutive empty receives stop the copy
+	client = &pagesHTTPClient{bodies: []string{`{}`, counts("5"), `{}`}}
+	moved, err = newPagesTestAws(client).SQS.CopyMessages(from, to, nil,
+		SQSCopyOptions{Wait: 5 * time.Second, EmptyReceives: 2})
+	if err != nil || moved != 0 || len(client.requests) != 3 ||
+		!strings.Contains(client.requests[2], `"WaitTimeSeconds":5`) {
+		t.Error("wrong empty copy:", moved, err, client.requests)
+	}
+}
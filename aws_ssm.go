@@ -0,0 +1,172 @@
+package aws
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+)
+
+// ssmCacheTTL is the default time to live of the cached parameter values.
+const ssmCacheTTL = 5 * time.Minute
+
+// awsSSM is the AWS SSM Parameter Store client struct.
+type awsSSM struct {
+	// ctx is the context.Context for AWS requests
+	ctx context.Context
+
+	// Client is the AWS SSM client
+	Client *ssm.Client
+
+	// Cache is the parameter values cache used by Get. The values are cached
+	// for 5 minutes by default, the parameters changed by PutParameter are
+	// removed from the cache.
+	Cache *LookupCache[string, string]
+}
+
+// SSMPutOptions are the optional parameters of the SSM PutParameter.
+type SSMPutOptions struct {
+	// Secure saves the value as the SecureString parameter encrypted by KMS.
+	Secure bool
+
+	// KeyID is the KMS key ID used to encrypt the SecureString parameter.
+	// Default is the AWS managed key.
+	KeyID string
+
+	// Description is the parameter description.
+	Description string
+
+	// NoOverwrite fails with ErrConflict if the parameter already exists.
+	NoOverwrite bool
+}
+
+// init initializes the SSM client cache.
+func (a *awsSSM) init() {
+	a.Cache = NewLookupCache(
+		func(name string) (string, error) { return a.GetParameter(name) },
+		IsNotFound,
+	)
+	a.Cache.TTL = ssmCacheTTL
+	a.Cache.Clock = clock(a.ctx)
+}
+
+// Get returns the parameter value from the cache or reads it from the
+// Parameter Store. The SecureString values are decrypted.
+//
+// Parameters:
+//   - name: The parameter name, for example "/app/prod/db/host".
+//
+// Returns:
+//   - value: The parameter value.
+//   - err: An error if the operation fails. The error wraps ErrNotFound if
+//     the parameter does not exist.
+func (a awsSSM) Get(name string) (value string, err error) {
+	return a.Cache.Get(name)
+}
+
+// GetParameter reads the parameter value from the Parameter Store. The
+// SecureString values are decrypted.
+//
+// Parameters:
+//   - name: The parameter name, for example "/app/prod/db/host".
+//
+// Returns:
+//   - value: The parameter value.
+//   - err: An error if the operation fails. The error wraps ErrNotFound if
+//     the parameter does not exist.
+func (a awsSSM) GetParameter(name string) (value string, err error) {
+	out, err := a.Client.GetParameter(a.ctx, &ssm.GetParameterInput{
+		Name:           aws.String(name),
+		WithDecryption: aws.Bool(true),
+	})
+	if err != nil {
+		return
+	}
+	value = aws.ToString(out.Parameter.Value)
+	return
+}
+
+// GetParametersByPath reads all parameters under the path recursively. The
+// SecureString values are decrypted. The read values are added to the Get
+// cache.
+//
+// Parameters:
+//   - path: The parameters path, for example "/app/prod".
+//
+// Returns:
+//   - params: The parameter values by the names relative to the path, for
+//     example "db/host" for the "/app/prod/db/host" parameter.
+//   - err: An error if the operation fails.
+func (a awsSSM) GetParametersByPath(path string) (params map[string]string,
+	err error) {
+
+	params = make(map[string]string)
+	prefix := strings.TrimSuffix(path, "/") + "/"
+	var nextToken *string
+	for {
+		var out *ssm.GetParametersByPathOutput
+		out, err = a.Client.GetParametersByPath(a.ctx,
+			&ssm.GetParametersByPathInput{
+				Path:           aws.String(path),
+				Recursive:      aws.Bool(true),
+				WithDecryption: aws.Bool(true),
+				NextToken:      nextToken,
+			},
+		)
+		if err != nil {
+			return
+		}
+		for _, p := range out.Parameters {
+			name, value := aws.ToString(p.Name), aws.ToString(p.Value)
+			params[strings.TrimPrefix(name, prefix)] = value
+			a.Cache.Set(name, value)
+		}
+		if nextToken = out.NextToken; nextToken == nil {
+			break
+		}
+	}
+	return
+}
+
+// PutParameter saves the parameter value. The existing parameter is
+// overwritten unless NoOverwrite option is set.
+//
+// Parameters:
+//   - name: The parameter name, for example "/app/prod/db/host".
+//   - value: The parameter value.
+//   - opts: The optional put parameters.
+//
+// Returns:
+//   - version: The new parameter version.
+//   - err: An error if the operation fails. The error wraps ErrConflict if
+//     the parameter exists and NoOverwrite option is set.
+func (a awsSSM) PutParameter(name, value string, opts ...SSMPutOptions) (
+	version int64, err error) {
+
+	var o SSMPutOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	input := &ssm.PutParameterInput{
+		Name:        aws.String(name),
+		Value:       aws.String(value),
+		Type:        types.ParameterTypeString,
+		Description: optional(o.Description),
+		Overwrite:   aws.Bool(!o.NoOverwrite),
+	}
+	if o.Secure {
+		input.Type = types.ParameterTypeSecureString
+		input.KeyId = optional(o.KeyID)
+	}
+	defer a.Cache.Delete(name)
+
+	out, err := a.Client.PutParameter(a.ctx, input)
+	if err != nil {
+		return
+	}
+	version = out.Version
+	return
+}
@@ -0,0 +1,93 @@
+package aws
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// TestSSMParameters checks SSM parameters path pages and cache
+func TestSSMParameters(t *testing.T) {
+
+	client := &pagesHTTPClient{bodies: []string{
+		`{"Parameters":[{"Name":"/app/prod/db/host","Value":"db.local"}],` +
+			`"NextToken":"t1"}`,
+		`{"Parameters":[{"Name":"/app/prod/db/password","Value":"secret"}]}`,
+		`{"Version":2}`,
+		`{"Parameter":{"Name":"/app/prod/db/host","Value":"db2.local"}}`,
+	}}
+	a := newPagesTestAws(client)
+
+	// Read parameters by path, the values are cached
+	params, err := a.SSM.GetParametersByPath("/app/prod/")
+	if err != nil || len(params) != 2 || params["db/host"] != "db.local" ||
+		params["db/password"] != "secret" {
+		t.Fatal("wrong parameters:", params, err)
+	}
+	if !strings.Contains(client.requests[1], `"NextToken":"t1"`) ||
+		!strings.Contains(client.requests[1], `"WithDecryption":true`) {
+		t.Error("wrong next page request:", client.requests[1])
+	}
+	if v, err := a.SSM.Get("/app/prod/db/password"); err != nil ||
+		v != "secret" || len(client.requests) != 2 {
+		t.Error("wrong cached parameter:", v, err)
+	}
+
+	// Put removes parameter from cache
+	version, err := a.SSM.PutParameter("/app/prod/db/host", "db2.local",
+		SSMPutOptions{Secure: true})
+	if err != nil || version != 2 ||
+		!strings.Contains(client.requests[2], `"Type":"SecureString"`) {
+		t.Error("wrong put parameter:", version, err, client.requests[2])
+	}
+	if v, err := a.SSM.Get("/app/prod/db/host"); err != nil ||
+		v != "db2.local" {
+		t.Error("wrong parameter after put:", v, err)
+	}
+
+	// Not found error
+	a = newErrorTestAws(http.StatusBadRequest,
+		`{"__type":"ParameterNotFound","message":""}`)
+	_, err = a.SSM.Get("/app/prod/missing")
+	var opErr *OpError
+	if !errors.Is(err, ErrNotFound) || !errors.As(err, &opErr) ||
+		opErr.Resource != "/app/prod/missing" {
+		t.Error("wrong not found error:", err)
+	}
+}
+
+// TestSSMCache checks the parameter values and not found errors are read
+// once by Get
+func TestSSMCache(t *testing.T) {
+
+	client := &pagesHTTPClient{bodies: []string{
+		`{"Parameter":{"Name":"/app/url","Value":"https://app.local"}}`,
+		`{"__type":"ParameterNotFound","message":""}`,
+	}, statuses: []int{http.StatusOK, http.StatusBadRequest}}
+	a := newPagesTestAws(client)
+
+	for range 2 {
+		if v, err := a.SSM.Get("/app/url"); err != nil ||
+			v != "https://app.local" {
+			t.Fatal("wrong parameter:", v, err)
+		}
+		if _, err := a.SSM.Get("/app/missing"); !errors.Is(err, ErrNotFound) {
+			t.Fatal("wrong not found error:", err)
+		}
+	}
+	stats := a.SSM.Cache.Stats()
+	if len(client.requests) != 2 || stats.Hits != 2 || stats.Misses != 2 ||
+		stats.Errors != 0 {
+		t.Error("wrong cache requests:", len(client.requests), stats)
+	}
+
+	// GetParameter is not cached
+	client.bodies = append(client.bodies,
+		`{"Parameter":{"Name":"/app/url","Value":"https://new.local"}}`)
+	client.statuses = append(client.statuses, http.StatusOK)
+	if v, err := a.SSM.GetParameter("/app/url"); err != nil ||
+		v != "https://new.local" || len(client.requests) != 3 {
+		t.Error("wrong uncached parameter:", v, err)
+	}
+}
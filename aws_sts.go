@@ -0,0 +1,208 @@
+package aws
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/aws-sdk-go-v2/service/sts/types"
+)
+
+// stsExpiryWindow is the time before the role credentials expiration when
+// the role is assumed again.
+const stsExpiryWindow = 5 * time.Minute
+
+// awsSTS is the AWS Security Token Service client struct.
+type awsSTS struct {
+	// ctx is the context.Context for AWS requests
+	ctx context.Context
+
+	// cfg is the AWS config used to create Aws from role credentials
+	cfg aws.Config
+
+	// opts are the options used to create Aws from role credentials
+	opts []Option
+
+	// Client is the AWS STS client
+	Client *sts.Client
+}
+
+// CallerIdentity is the identity of the credentials used to call AWS.
+type CallerIdentity struct {
+	// Account is the AWS account ID.
+	Account string
+
+	// ARN is the ARN of the user or assumed role.
+	ARN string
+
+	// UserID is the unique ID of the user or role session.
+	UserID string
+}
+
+// STSAssumeRoleOptions are the optional parameters of the STS AssumeRole.
+type STSAssumeRoleOptions struct {
+	// Duration is the role session duration. Default is 1 hour.
+	Duration time.Duration
+
+	// ExternalID is the external ID required by the role trust policy of
+	// the other account.
+	ExternalID string
+
+	// Policy is the JSON session policy which limits the role permissions.
+	Policy string
+
+	// SerialNumber is the MFA device serial number or ARN, required if the
+	// role trust policy requires MFA.
+	SerialNumber string
+
+	// TokenCode is the MFA device token code.
+	TokenCode string
+}
+
+// GetCallerIdentity returns the identity of the credentials used to call
+// AWS. Use it to check which account and user or role are used.
+//
+// Returns:
+//   - identity: The caller identity.
+//   - err: An error if the operation fails.
+func (a awsSTS) GetCallerIdentity() (identity CallerIdentity, err error) {
+	out, err := a.Client.GetCallerIdentity(a.ctx,
+		&sts.GetCallerIdentityInput{})
+	if err != nil {
+		return
+	}
+	identity = CallerIdentity{
+		Account: aws.ToString(out.Account),
+		ARN:     aws.ToString(out.Arn),
+		UserID:  aws.ToString(out.UserId),
+	}
+	return
+}
+
+// AssumeRole returns the temporary AWS credentials of the role.
+//
+// Parameters:
+//   - roleArn: The ARN of the role, may be in other account.
+//   - sessionName: The role session name shown in CloudTrail.
+//   - opts: The optional assume role parameters.
+//
+// Returns:
+//   - creds: The temporary AWS credentials.
+//   - err: An error if the operation fails.
+func (a awsSTS) AssumeRole(roleArn, sessionName string,
+	opts ...STSAssumeRoleOptions) (creds aws.Credentials, err error) {
+
+	var o STSAssumeRoleOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	out, err := a.Client.AssumeRole(a.ctx, &sts.AssumeRoleInput{
+		RoleArn:         aws.String(roleArn),
+		RoleSessionName: aws.String(sessionName),
+		DurationSeconds: stsDuration(o.Duration),
+		ExternalId:      optional(o.ExternalID),
+		Policy:          optional(o.Policy),
+		SerialNumber:    optional(o.SerialNumber),
+		TokenCode:       optional(o.TokenCode),
+	})
+	if err != nil {
+		return
+	}
+	creds = stsCredentials(out.Credentials, "AssumeRole")
+	return
+}
+
+// AssumeRoleAws returns new Aws which clients use the temporary AWS
+// credentials of the role. The role is assumed again five minutes before
+// the credentials expire, so the MFA options can't be used.
+//
+// Parameters:
+//   - roleArn: The ARN of the role, may be in other account.
+//   - sessionName: The role session name shown in CloudTrail.
+//   - opts: The optional assume role parameters.
+//
+// Returns:
+//   - roleAws: The Aws with role credentials.
+//   - err: An error if the operation fails.
+func (a awsSTS) AssumeRoleAws(roleArn, sessionName string,
+	opts ...STSAssumeRoleOptions) (roleAws *Aws, err error) {
+
+	// Assume role once and check credentials
+	creds, err := a.AssumeRole(roleArn, sessionName, opts...)
+	if err != nil {
+		return
+	}
+
+	// Create credentials provider which returns first credentials and
+	// assumes role again when they are expired
+	first := true
+	provider := aws.CredentialsProviderFunc(func(context.Context) (
+		aws.Credentials, error) {
+
+		if first {
+			first = false
+			return creds, nil
+		}
+		return a.AssumeRole(roleArn, sessionName, opts...)
+	})
+
+	cfg := a.cfg.Copy()
+	cfg.Credentials = aws.NewCredentialsCache(provider,
+		func(o *aws.CredentialsCacheOptions) {
+			o.ExpiryWindow = stsExpiryWindow
+		},
+	)
+	roleAws = NewFromConfig(cfg, a.opts...)
+
+	return
+}
+
+// GetSessionToken returns the temporary AWS credentials of the IAM user.
+// Use it to get MFA authenticated credentials.
+//
+// Parameters:
+//   - serialNumber: The MFA device serial number or ARN. Empty without MFA.
+//   - tokenCode: The MFA device token code. Empty without MFA.
+//   - duration: The credentials duration. Zero for the default 12 hours.
+//
+// Returns:
+//   - creds: The temporary AWS credentials.
+//   - err: An error if the operation fails.
+func (a awsSTS) GetSessionToken(serialNumber, tokenCode string,
+	duration time.Duration) (creds aws.Credentials, err error) {
+
+	out, err := a.Client.GetSessionToken(a.ctx, &sts.GetSessionTokenInput{
+		DurationSeconds: stsDuration(duration),
+		SerialNumber:    optional(serialNumber),
+		TokenCode:       optional(tokenCode),
+	})
+	if err != nil {
+		return
+	}
+	creds = stsCredentials(out.Credentials, "GetSessionToken")
+	return
+}
+
+// stsDuration returns the duration seconds parameter, nil for zero duration.
+func stsDuration(d time.Duration) *int32 {
+	if d <= 0 {
+		return nil
+	}
+	return aws.Int32(int32(d / time.Second))
+}
+
+// stsCredentials returns AWS credentials from the STS credentials.
+func stsCredentials(c *types.Credentials, source string) aws.Credentials {
+	if c == nil {
+		return aws.Credentials{}
+	}
+	return aws.Credentials{
+		AccessKeyID:     aws.ToString(c.AccessKeyId),
+		SecretAccessKey: aws.ToString(c.SecretAccessKey),
+		SessionToken:    aws.ToString(c.SessionToken),
+		Source:          source,
+		CanExpire:       c.Expiration != nil,
+		Expires:         aws.ToTime(c.Expiration),
+	}
+}
@@ -0,0 +1,47 @@
+package aws
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// TestSTSAssumeRole checks STS caller identity and role Aws credentials
+func TestSTSAssumeRole(t *testing.T) {
+
+	const role = "arn:aws:iam::222:role/deploy"
+	client := &pagesHTTPClient{bodies: []string{
+		`<GetCallerIdentityResponse><GetCallerIdentityResult>` +
+			`<Account>111</Account><Arn>arn:aws:iam::111:user/ci</Arn>` +
+			`<UserId>AID1</UserId></GetCallerIdentityResult>` +
+			`</GetCallerIdentityResponse>`,
+		`<AssumeRoleResponse><AssumeRoleResult><Credentials>` +
+			`<AccessKeyId>AK</AccessKeyId><SecretAccessKey>SK</SecretAccessKey>` +
+			`<SessionToken>ST</SessionToken>` +
+			`<Expiration>2030-01-01T00:00:00Z</Expiration>` +
+			`</Credentials></AssumeRoleResult></AssumeRoleResponse>`,
+	}}
+	a := newPagesTestAws(client)
+
+	identity, err := a.STS.GetCallerIdentity()
+	if err != nil || identity.Account != "111" || identity.UserID != "AID1" {
+		t.Fatal("wrong caller identity:", identity, err)
+	}
+
+	roleAws, err := a.STS.AssumeRoleAws(role, "deploy",
+		STSAssumeRoleOptions{Duration: 15 * time.Minute, ExternalID: "x1"})
+	if err != nil {
+		t.Fatal("assume role error:", err)
+	}
+	values, _ := url.ParseQuery(client.requests[1])
+	if values.Get("RoleArn") != role || values.Get("ExternalId") != "x1" ||
+		values.Get("DurationSeconds") != "900" {
+		t.Error("wrong assume role request:", values)
+	}
+	creds, err := roleAws.Config().Credentials.Retrieve(context.Background())
+	if err != nil || creds.AccessKeyID != "AK" || creds.SessionToken != "ST" ||
+		!creds.CanExpire {
+		t.Error("wrong role credentials:", creds, err)
+	}
+}
@@ -0,0 +1,419 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/textract"
+	"github.com/aws/aws-sdk-go-v2/service/textract/types"
+)
+
+// ErrTextractJobFailed is returned by Textract GetDocumentAnalysis and
+// WaitForAnalysis when the analysis job failed.
+var ErrTextractJobFailed = errors.New("textract job failed")
+
+const (
+	// textractWaitDelay is the first delay between the job status polls, the
+	// delay is doubled up to textractWaitMaxDelay.
+	textractWaitDelay = 5 * time.Second
+
+	// textractWaitMaxDelay is the maximum delay between the job status
+	// polls.
+	textractWaitMaxDelay = 30 * time.Second
+)
+
+// awsTextract is the AWS Textract client struct.
+type awsTextract struct {
+	// ctx is the context.Context for AWS requests
+	ctx context.Context
+
+	// Client is the AWS Textract client
+	Client *textract.Client
+}
+
+// TextractDocument is the text detected in the document.
+type TextractDocument struct {
+	// Pages is the number of the document pages.
+	Pages int
+
+	// Lines are the text lines in the reading order.
+	Lines []TextractLine
+
+	// KeyValues are the form fields, empty for DetectDocumentText.
+	KeyValues []TextractKeyValue
+
+	// Tables are the tables, empty for DetectDocumentText.
+	Tables []TextractTable
+}
+
+// Text returns the document text, the lines are separated by new lines.
+func (d TextractDocument) Text() string {
+	lines := make([]string, len(d.Lines))
+	for i, l := range d.Lines {
+		lines[i] = l.Text
+	}
+	return strings.Join(lines, "\n")
+}
+
+// TextractLine is the text line of the document.
+type TextractLine struct {
+	// Page is the page number starting from 1.
+	Page int
+
+	// Text is the line text.
+	Text string
+
+	// Confidence is the line confidence, from 0 to 100.
+	Confidence float64
+}
+
+// TextractKeyValue is the form field of the document.
+type TextractKeyValue struct {
+	// Page is the page number starting from 1.
+	Page int
+
+	// Key is the field name, for example "Name:".
+	Key string
+
+	// Value is the field value. The selected check box value is "[X]" and
+	// not selected is "[ ]".
+	Value string
+
+	// Confidence is the field confidence, from 0 to 100.
+	Confidence float64
+}
+
+// TextractTable is the table of the document.
+type TextractTable struct {
+	// Page is the page number starting from 1.
+	Page int
+
+	// Rows are the table cells text by rows and columns. The merged cells
+	// text is repeated in each spanned cell.
+	Rows [][]string
+}
+
+// TextractAnalysisOptions are the optional parameters of the Textract
+// StartDocumentAnalysis.
+type TextractAnalysisOptions struct {
+	// SNSTopicARN is the SNS topic the job completion is published to, see
+	// ParseTextractNotification. Empty to poll the job status.
+	SNSTopicARN string
+
+	// RoleARN is the IAM role Textract assumes to publish to the SNS topic.
+	// Required with SNSTopicARN.
+	RoleARN string
+
+	// JobTag is the tag of the job returned in the notification.
+	JobTag string
+}
+
+// TextractNotification is the Textract job completion message published to
+// the SNS topic.
+type TextractNotification struct {
+	// JobID is the job ID.
+	JobID string `json:"JobId"`
+
+	// Status is the job status: "SUCCEEDED", "FAILED" or "ERROR".
+	Status string `json:"Status"`
+
+	// API is the job start operation, for example "StartDocumentAnalysis".
+	API string `json:"API"`
+
+	// JobTag is the job tag.
+	JobTag string `json:"JobTag"`
+}
+
+// ParseTextractNotification parses the Textract job completion message
+// received from the SNS topic.
+func ParseTextractNotification(message string) (n TextractNotification,
+	err error) {
+
+	err = json.Unmarshal([]byte(message), &n)
+	return
+}
+
+// DetectDocumentText detects the text lines in the single page document.
+//
+// Parameters:
+//   - bucket: The S3 bucket name.
+//   - key: The S3 object key of the JPEG, PNG, TIFF or PDF document.
+//
+// Returns:
+//   - doc: The document with the text lines.
+//   - err: An error if the operation fails.
+func (a awsTextract) DetectDocumentText(bucket, key string) (
+	doc TextractDocument, err error) {
+
+	out, err := a.Client.DetectDocumentText(a.ctx,
+		&textract.DetectDocumentTextInput{
+			Document: &types.Document{S3Object: &types.S3Object{
+				Bucket: aws.String(bucket),
+				Name:   aws.String(key),
+			}},
+		},
+	)
+	if err != nil {
+		return
+	}
+	doc = textractDocument(out.Blocks)
+	return
+}
+
+// StartDocumentAnalysis starts the asynchronous analysis of the multipage
+// document forms and tables. Get the result with GetDocumentAnalysis after
+// the SNS notification or with WaitForAnalysis.
+//
+// Parameters:
+//   - bucket: The S3 bucket name.
+//   - key: The S3 object key of the JPEG, PNG, TIFF or PDF document.
+//   - opts: The optional job parameters.
+//
+// Returns:
+//   - jobID: The analysis job ID.
+//   - err: An error if the operation fails.
+func (a awsTextract) StartDocumentAnalysis(bucket, key string,
+	opts ...TextractAnalysisOptions) (jobID string, err error) {
+
+	var o TextractAnalysisOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	input := &textract.StartDocumentAnalysisInput{
+		DocumentLocation: &types.DocumentLocation{
+			S3Object: &types.S3Object{
+				Bucket: aws.String(bucket),
+				Name:   aws.String(key),
+			},
+		},
+		FeatureTypes: []types.FeatureType{types.FeatureTypeForms,
+			types.FeatureTypeTables},
+		JobTag: optional(o.JobTag),
+	}
+	if o.SNSTopicARN != "" {
+		input.NotificationChannel = &types.NotificationChannel{
+			SNSTopicArn: aws.String(o.SNSTopicARN),
+			RoleArn:     aws.String(o.RoleARN),
+		}
+	}
+	out, err := a.Client.StartDocumentAnalysis(a.ctx, input)
+	if err != nil {
+		return
+	}
+	jobID = aws.ToString(out.JobId)
+	return
+}
+
+// GetDocumentAnalysis returns the document analysis job result.
+//
+// Parameters:
+//   - jobID: The analysis job ID.
+//
+// Returns:
+//   - doc: The document with the text lines, forms and tables, empty if the
+//     job is in progress.
+//   - done: True if the job is completed.
+//   - err: An error if the operation fails. The error wraps
+//     ErrTextractJobFailed if the job failed.
+func (a awsTextract) GetDocumentAnalysis(jobID string) (
+	doc TextractDocument, done bool, err error) {
+
+	return a.analysis(a.ctx, jobID)
+}
+
+// WaitForAnalysis waits for the document analysis job to complete and
+// returns its result. The job status is polled with the delay doubled from
+// 5 up to 30 seconds.
+//
+// Parameters:
+//   - ctx: The context to stop waiting.
+//   - jobID: The analysis job ID.
+//
+// Returns:
+//   - doc: The document with the text lines, forms and tables.
+//   - err: An error if the operation fails or ctx is canceled. The error
+//     wraps ErrTextractJobFailed if the job failed.
+func (a awsTextract) WaitForAnalysis(ctx context.Context, jobID string) (
+	doc TextractDocument, err error) {
+
+	delay := textractWaitDelay
+	for {
+		var done bool
+		doc, done, err = a.analysis(ctx, jobID)
+		switch {
+		case err != nil && !IsRetryable(err):
+			return
+		case err == nil && done:
+			return
+		}
+
+		// Wait before next poll
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+			return
+		case <-clock(a.ctx).After(max(delay, RetryAfter(err))):
+		}
+		delay = min(delay*2, textractWaitMaxDelay)
+	}
+}
+
+// analysis returns the document analysis job result.
+func (a awsTextract) analysis(ctx context.Context, jobID string) (
+	doc TextractDocument, done bool, err error) {
+
+	input := &textract.GetDocumentAnalysisInput{JobId: aws.String(jobID)}
+	var blocks []types.Block
+	for {
+		var out *textract.GetDocumentAnalysisOutput
+		out, err = a.Client.GetDocumentAnalysis(ctx, input)
+		if err != nil {
+			return
+		}
+		switch out.JobStatus {
+		case types.JobStatusInProgress:
+			return
+		case types.JobStatusFailed:
+			err = fmt.Errorf("%w: %s", ErrTextractJobFailed,
+				aws.ToString(out.StatusMessage))
+			return
+		}
+		blocks = append(blocks, out.Blocks...)
+		if aws.ToString(out.NextToken) == "" {
+			break
+		}
+		input.NextToken = out.NextToken
+	}
+	doc, done = textractDocument(blocks), true
+
+	return
+}
+
+// textractDocument returns TextractDocument from the Textract blocks.
+func textractDocument(blocks []types.Block) (doc TextractDocument) {
+	ids := make(map[string]*types.Block, len(blocks))
+	for i := range blocks {
+		ids[aws.ToString(blocks[i].Id)] = &blocks[i]
+	}
+
+	for i := range blocks {
+		b := &blocks[i]
+		page := int(aws.ToInt32(b.Page))
+		switch b.BlockType {
+		case types.BlockTypePage:
+			doc.Pages++
+		case types.BlockTypeLine:
+			doc.Lines = append(doc.Lines, TextractLine{
+				Page:       page,
+				Text:       aws.ToString(b.Text),
+				Confidence: float64(aws.ToFloat32(b.Confidence)),
+			})
+		case types.BlockTypeKeyValueSet:
+			if !textractHasEntity(b, types.EntityTypeKey) {
+				continue
+			}
+			kv := TextractKeyValue{
+				Page:       page,
+				Key:        textractText(ids, b),
+				Confidence: float64(aws.ToFloat32(b.Confidence)),
+			}
+			values := textractRelated(b, types.RelationshipTypeValue)
+			for _, id := range values {
+				if v, ok := ids[id]; ok {
+					kv.Value = textractText(ids, v)
+				}
+			}
+			doc.KeyValues = append(doc.KeyValues, kv)
+		case types.BlockTypeTable:
+			doc.Tables = append(doc.Tables, textractTable(ids, b, page))
+		}
+	}
+	if doc.Pages == 0 && len(doc.Lines) > 0 {
+		doc.Pages = 1
+	}
+
+	return
+}
+
+// textractTable returns TextractTable from the Textract table block.
+func textractTable(ids map[string]*types.Block, b *types.Block,
+	page int) (table TextractTable) {
+
+	table.Page = page
+	for _, id := range textractRelated(b, types.RelationshipTypeChild) {
+		cell, ok := ids[id]
+		if !ok || cell.BlockType != types.BlockTypeCell {
+			continue
+		}
+		row := int(aws.ToInt32(cell.RowIndex)) - 1
+		col := int(aws.ToInt32(cell.ColumnIndex)) - 1
+		if row < 0 || col < 0 {
+			continue
+		}
+		text := textractText(ids, cell)
+		rows := max(int(aws.ToInt32(cell.RowSpan)), 1)
+		cols := max(int(aws.ToInt32(cell.ColumnSpan)), 1)
+		for r := row; r < row+rows; r++ {
+			for len(table.Rows) <= r {
+				table.Rows = append(table.Rows, nil)
+			}
+			for c := col; c < col+cols; c++ {
+				for len(table.Rows[r]) <= c {
+					table.Rows[r] = append(table.Rows[r], "")
+				}
+				table.Rows[r][c] = text
+			}
+		}
+	}
+	return
+}
+
+// textractText returns the text of the block child words and selection
+// elements separated by spaces.
+func textractText(ids map[string]*types.Block, b *types.Block) string {
+	var words []string
+	for _, id := range textractRelated(b, types.RelationshipTypeChild) {
+		child, ok := ids[id]
+		if !ok {
+			continue
+		}
+		switch child.BlockType {
+		case types.BlockTypeWord:
+			words = append(words, aws.ToString(child.Text))
+		case types.BlockTypeSelectionElement:
+			if child.SelectionStatus == types.SelectionStatusSelected {
+				words = append(words, "[X]")
+			} else {
+				words = append(words, "[ ]")
+			}
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// textractRelated returns the IDs of the related blocks of the type.
+func textractRelated(b *types.Block,
+	typ types.RelationshipType) (ids []string) {
+
+	for _, r := range b.Relationships {
+		if r.Type == typ {
+			ids = append(ids, r.Ids...)
+		}
+	}
+	return
+}
+
+// textractHasEntity returns true if the block has the entity type.
+func textractHasEntity(b *types.Block, typ types.EntityType) bool {
+	for _, e := range b.EntityTypes {
+		if e == typ {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package aws
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestTextractAnalysis checks Textract lines, key-value pairs and tables
+// parsing, result pages and failed job
+func TestTextractAnalysis(t *testing.T) {
+
+	client := &pagesHTTPClient{bodies: []string{
+		`{"JobId":"job-1"}`,
+		`{"JobStatus":"IN_PROGRESS"}`,
+		`{"JobStatus":"SUCCEEDED","NextToken":"p2","Blocks":[` +
+			`{"BlockType":"PAGE","Id":"p","Page":1},` +
+			`{"BlockType":"LINE","Id":"l1","Page":1,"Text":"Name: Ann",` +
+			`"Confidence":99},` +
+			`{"BlockType":"KEY_VALUE_SET","Id":"k","Page":1,` +
+			`"EntityTypes":["KEY"],"Confidence":90,"Relationships":[` +
+			`{"Type":"CHILD","Ids":["w1"]},{"Type":"VALUE","Ids":["v"]}]},` +
+			`{"BlockType":"KEY_VALUE_SET","Id":"v","Page":1,` +
+			`"EntityTypes":["VALUE"],"Relationships":[` +
+			`{"Type":"CHILD","Ids":["w2"]}]},` +
+			`{"BlockType":"WORD","Id":"w1","Text":"Name:"},` +
+			`{"BlockType":"WORD","Id":"w2","Text":"Ann"}]}`,
+		`{"JobStatus":"SUCCEEDED","Blocks":[` +
+			`{"BlockType":"PAGE","Id":"p2","Page":2},` +
+			`{"BlockType":"TABLE","Id":"t","Page":2,"Relationships":[` +
+			`{"Type":"CHILD","Ids":["c1","c2","c3"]}]},` +
+			`{"BlockType":"CELL","Id":"c1","RowIndex":1,"ColumnIndex":1,` +
+			`"ColumnSpan":2,"Relationships":[{"Type":"CHILD","Ids":["w3"]}]},` +
+			`{"BlockType":"CELL","Id":"c2","RowIndex":2,"ColumnIndex":1,` +
+			`"Relationships":[{"Type":"CHILD","Ids":["s"]}]},` +
+			`{"BlockType":"CELL","Id":"c3","RowIndex":2,"ColumnIndex":2},` +
+			`{"BlockType":"WORD","Id":"w3","Text":"Total"},` +
+			`{"BlockType":"SELECTION_ELEMENT","Id":"s",` +
+			`"SelectionStatus":"SELECTED"}]}`,
+		`{"JobStatus":"FAILED","StatusMessage":"unsupported document"}`,
+	}}
+	a := newPagesTestAws(client)
+
+	jobID, err := a.Textract.StartDocumentAnalysis("docs", "form.pdf",
+		TextractAnalysisOptions{JobTag: "upload"})
+	if err != nil || jobID != "job-1" ||
+		!strings.Contains(client.requests[0], `"FeatureTypes":["FORMS","TABLES"]`) {
+		t.Fatal("wrong start analysis:", jobID, err, client.requests[0])
+	}
+
+	// In progress job
+	_, done, err := a.Textract.GetDocumentAnalysis(jobID)
+	if err != nil || done {
+		t.Fatal("wrong in progress analysis:", done, err)
+	}
+
+	// Succeeded job with two result pages
+	doc, done, err := a.Textract.GetDocumentAnalysis(jobID)
+	if err != nil || !done || doc.Pages != 2 || doc.Text() != "Name: Ann" {
+		t.Fatal("wrong analysis:", doc, done, err)
+	}
+	if len(doc.KeyValues) != 1 || doc.KeyValues[0].Key != "Name:" ||
+		doc.KeyValues[0].Value != "Ann" {
+		t.Error("wrong key values:", doc.KeyValues)
+	}
+	if len(doc.Tables) != 1 || doc.Tables[0].Page != 2 ||
+		strings.Join(doc.Tables[0].Rows[0], "|") != "Total|Total" ||
+		strings.Join(doc.Tables[0].Rows[1], "|") != "[X]|" {
+		t.Error("wrong tables:", doc.Tables)
+	}
+
+	// Failed job
+	_, _, err = a.Textract.GetDocumentAnalysis(jobID)
+	if !errors.Is(err, ErrTextractJobFailed) {
+		t.Error("wrong failed job error:", err)
+	}
+
+	n, err := ParseTextractNotification(`{"JobId":"job-1",` +
+		`"Status":"SUCCEEDED","API":"StartDocumentAnalysis","JobTag":"upload"}`)
+	if err != nil || n.JobID != "job-1" || n.Status != "SUCCEEDED" {
+		t.Error("wrong notification:", n, err)
+	}
+}
@@ -0,0 +1,315 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/timestreamwrite"
+	"github.com/aws/aws-sdk-go-v2/service/timestreamwrite/types"
+)
+
+const (
+	// timestreamBatchSize is the maximum number of records in one
+	// WriteRecords request.
+	timestreamBatchSize = 100
+
+	// timestreamFlushInterval is the default flush interval of the
+	// TimestreamWriter.
+	timestreamFlushInterval = time.Second
+)
+
+// awsTimestream is the AWS Timestream for LiveAnalytics write client struct.
+type awsTimestream struct {
+	// ctx is the context.Context for AWS requests
+	ctx context.Context
+
+	// Client is the AWS Timestream Write client
+	Client *timestreamwrite.Client
+}
+
+// TimestreamRecord is the Timestream record.
+type TimestreamRecord struct {
+	// Time is the record time. Zero for the common attributes time or, in
+	// the TimestreamWriter, for the current time.
+	Time time.Time
+
+	// Dimensions are the record dimensions, for example {"device": "d1"}.
+	Dimensions map[string]string
+
+	// Measure is the measure name of the multi-measure record, for example
+	// "metrics". The single measure record measure name is the Values key.
+	Measure string
+
+	// Values are the measure values by name. The float, integer, bool,
+	// string and time.Time values are written as DOUBLE, BIGINT, BOOLEAN,
+	// VARCHAR and TIMESTAMP, other values as VARCHAR. The record with one
+	// value and empty Measure is the single measure record, other records
+	// are the multi-measure records.
+	Values map[string]any
+
+	// Version is the record version, the record with higher version
+	// updates the existing record. Zero for the service default.
+	Version int64
+}
+
+// record returns the Timestream record.
+func (r TimestreamRecord) record() (record types.Record) {
+	if !r.Time.IsZero() {
+		record.Time = aws.String(strconv.FormatInt(r.Time.UnixMilli(), 10))
+		record.TimeUnit = types.TimeUnitMilliseconds
+	}
+	if r.Version > 0 {
+		record.Version = aws.Int64(r.Version)
+	}
+	for _, name := range slices.Sorted(maps.Keys(r.Dimensions)) {
+		record.Dimensions = append(record.Dimensions, types.Dimension{
+			Name:  aws.String(name),
+			Value: aws.String(r.Dimensions[name]),
+		})
+	}
+	record.MeasureName = optional(r.Measure)
+
+	// Single measure record
+	if len(r.Values) == 1 && r.Measure == "" {
+		for name, v := range r.Values {
+			value, typ := timestreamValue(v)
+			record.MeasureName = aws.String(name)
+			record.MeasureValue = aws.String(value)
+			record.MeasureValueType = typ
+		}
+		return
+	}
+
+	// Multi-measure record
+	if len(r.Values) > 0 {
+		record.MeasureValueType = types.MeasureValueTypeMulti
+	}
+	for _, name := range slices.Sorted(maps.Keys(r.Values)) {
+		value, typ := timestreamValue(r.Values[name])
+		record.MeasureValues = append(record.MeasureValues,
+			types.MeasureValue{
+				Name:  aws.String(name),
+				Value: aws.String(value),
+				Type:  typ,
+			})
+	}
+	return
+}
+
+// WriteRecords writes the records to the Timestream table. The records are
+// written in batches of 100 records.
+//
+// Parameters:
+//   - database: The database name.
+//   - table: The table name.
+//   - records: The records.
+//   - common: The optional attributes common to all records, for example
+//     the time or dimensions of the records batch. The records attributes
+//     override the common attributes.
+//
+// Returns:
+//   - err: The *BatchError if some of the records failed. The Item field of
+//     the failed record contains its index in records.
+func (a awsTimestream) WriteRecords(database, table string,
+	records []TimestreamRecord, common ...TimestreamRecord) (err error) {
+
+	input := timestreamwrite.WriteRecordsInput{
+		DatabaseName: aws.String(database),
+		TableName:    aws.String(table),
+	}
+	if len(common) > 0 {
+		record := common[0].record()
+		input.CommonAttributes = &record
+	}
+
+	var batchErr BatchError
+	for start := 0; start < len(records); start += timestreamBatchSize {
+		batch := records[start:min(start+timestreamBatchSize, len(records))]
+		input.Records = make([]types.Record, len(batch))
+		for i, r := range batch {
+			input.Records[i] = r.record()
+		}
+		a.writeBatch(&input, start, &batchErr)
+	}
+	err = batchErr.err()
+
+	return
+}
+
+// writeBatch writes the batch of records which starts from the start index
+// of the records. The results of all records are added to the batch error.
+func (a awsTimestream) writeBatch(input *timestreamwrite.WriteRecordsInput,
+	start int, batchErr *BatchError) {
+
+	_, err := a.Client.WriteRecords(a.ctx, input)
+
+	// The rejected records are failed, other records are written
+	rejected := make(map[int]error)
+	var e *types.RejectedRecordsException
+	if errors.As(err, &e) {
+		for _, r := range e.RejectedRecords {
+			rejected[int(r.RecordIndex)] = entryError("RejectedRecord",
+				aws.ToString(r.Reason))
+		}
+		err = nil
+	}
+	for i := range input.Records {
+		id := strconv.Itoa(start + i)
+		switch {
+		case err != nil:
+			batchErr.add(id, err)
+		case rejected[i] != nil:
+			batchErr.add(id, rejected[i])
+		default:
+			batchErr.add(id, nil)
+		}
+	}
+}
+
+// TimestreamWriterOptions are the optional parameters of the Timestream
+// Writer.
+type TimestreamWriterOptions struct {
+	// FlushInterval is the interval of the buffered records writes. Default
+	// is 1 second.
+	FlushInterval time.Duration
+
+	// Common are the attributes common to all records.
+	Common *TimestreamRecord
+
+	// OnError is called with the write errors. The failed records are not
+	// written again.
+	OnError func(err error)
+}
+
+// TimestreamWriter buffers the records and writes them to the Timestream
+// table in batches, see Timestream Writer.
+type TimestreamWriter struct {
+	a               awsTimestream
+	database, table string
+	opts            TimestreamWriterOptions
+
+	mu      sync.Mutex
+	records []TimestreamRecord
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+// Writer creates the Timestream writer which buffers the records and writes
+// them when 100 records are buffered or on the flush interval. Close the
+// writer to write the buffered records and stop it.
+//
+// Parameters:
+//   - database: The database name.
+//   - table: The table name.
+//   - opts: The optional writer parameters.
+//
+// Returns:
+//   - w: The Timestream writer.
+func (a awsTimestream) Writer(database, table string,
+	opts ...TimestreamWriterOptions) (w *TimestreamWriter) {
+
+	var o TimestreamWriterOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	if o.FlushInterval <= 0 {
+		o.FlushInterval = timestreamFlushInterval
+	}
+	if o.OnError == nil {
+		o.OnError = func(error) {}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w = &TimestreamWriter{a: a, database: database, table: table, opts: o,
+		cancel: cancel, done: make(chan struct{})}
+	go func() {
+		defer close(w.done)
+		ticker := clock(a.ctx).NewTicker(o.FlushInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C():
+				w.Flush()
+			}
+		}
+	}()
+
+	return
+}
+
+// Add adds the records to the buffer. The zero record time is set to the
+// current time. The buffer is written when it has 100 records.
+func (w *TimestreamWriter) Add(records ...TimestreamRecord) {
+	now := clock(w.a.ctx).Now()
+	w.mu.Lock()
+	for _, r := range records {
+		if r.Time.IsZero() {
+			r.Time = now
+		}
+		w.records = append(w.records, r)
+	}
+	full := len(w.records) >= timestreamBatchSize
+	w.mu.Unlock()
+
+	if full {
+		w.Flush()
+	}
+}
+
+// Flush writes the buffered records.
+func (w *TimestreamWriter) Flush() {
+	w.mu.Lock()
+	records := w.records
+	w.records = nil
+	w.mu.Unlock()
+	if len(records) == 0 {
+		return
+	}
+
+	var common []TimestreamRecord
+	if w.opts.Common != nil {
+		common = append(common, *w.opts.Common)
+	}
+	err := w.a.WriteRecords(w.database, w.table, records, common...)
+	if err != nil {
+		w.opts.OnError(fmt.Errorf("write %d records: %w", len(records), err))
+	}
+}
+
+// Close writes the buffered records and stops the writer.
+func (w *TimestreamWriter) Close() {
+	w.cancel()
+	<-w.done
+	w.Flush()
+}
+
+// timestreamValue returns the Timestream measure value and its type.
+func timestreamValue(v any) (value string, typ types.MeasureValueType) {
+	switch v := v.(type) {
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), types.MeasureValueTypeDouble
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32),
+			types.MeasureValueTypeDouble
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32,
+		uint64:
+		return fmt.Sprint(v), types.MeasureValueTypeBigint
+	case bool:
+		return strconv.FormatBool(v), types.MeasureValueTypeBoolean
+	case time.Time:
+		return strconv.FormatInt(v.UnixMilli(), 10),
+			types.MeasureValueTypeTimestamp
+	case string:
+		return v, types.MeasureValueTypeVarchar
+	}
+	return fmt.Sprint(v), types.MeasureValueTypeVarchar
+}
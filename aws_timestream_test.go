@@ -0,0 +1,65 @@
+package aws
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestTimestreamWriteRecords checks Timestream single and multi-measure
+// records, common attributes, rejected records and the buffered writer
+func TestTimestreamWriteRecords(t *testing.T) {
+
+	const endpoints = `{"Endpoints":[{"Address":` +
+		`"ingest-cell1.timestream.us-east-1.amazonaws.com",` +
+		`"CachePeriodInMinutes":1440}]}`
+	client := &pagesHTTPClient{bodies: []string{
+		endpoints,
+		`{"__type":"RejectedRecordsException","message":"rejected",` +
+			`"RejectedRecords":[{"RecordIndex":1,"Reason":"Duplicate"}]}`,
+		`{"RecordsIngested":{"Total":2}}`,
+	}, statuses: []int{200, http.StatusBadRequest, 200}}
+	a := newPagesTestAws(client)
+
+	at := time.UnixMilli(1700000000000)
+	err := a.Timestream.WriteRecords("telemetry", "devices",
+		[]TimestreamRecord{
+			{Values: map[string]any{"temp": 21.5}},
+			{Measure: "metrics", Values: map[string]any{"cpu": 3,
+				"ok": true}},
+		},
+		TimestreamRecord{Time: at, Dimensions: map[string]string{
+			"device": "d1"}},
+	)
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) || batchErr.Total != 2 ||
+		len(batchErr.Failed) != 1 || batchErr.Failed[0].Item != "1" {
+		t.Fatal("wrong rejected records error:", err)
+	}
+	req := client.requests[1]
+	for _, s := range []string{`"Time":"1700000000000"`,
+		`"Dimensions":[{"Name":"device","Value":"d1"}]`,
+		`"MeasureName":"temp","MeasureValue":"21.5",` +
+			`"MeasureValueType":"DOUBLE"`,
+		`{"Name":"cpu","Type":"BIGINT","Value":"3"}`,
+		`"MeasureValueType":"MULTI"`} {
+		if !strings.Contains(req, s) {
+			t.Error("write request does not contain", s, req)
+		}
+	}
+
+	// Buffered writer writes on close
+	var writeErr error
+	w := a.Timestream.Writer("telemetry", "devices",
+		TimestreamWriterOptions{FlushInterval: time.Hour,
+			OnError: func(err error) { writeErr = err }})
+	w.Add(TimestreamRecord{Values: map[string]any{"temp": 20}},
+		TimestreamRecord{Values: map[string]any{"temp": 22}})
+	w.Close()
+	if writeErr != nil || len(client.requests) != 3 ||
+		strings.Count(client.requests[2], `"MeasureName":"temp"`) != 2 {
+		t.Error("wrong writer request:", writeErr, client.requests)
+	}
+}
@@ -0,0 +1,145 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/apigatewaymanagementapi"
+)
+
+// ErrWebSocketGone is returned by the WebSocket methods when the client of
+// the connection is disconnected.
+var ErrWebSocketGone = errors.New("websocket client disconnected")
+
+// awsWebSocket is the AWS API Gateway Management API client struct bound to
+// the WebSocket API endpoint.
+type awsWebSocket struct {
+	// ctx is the context.Context for AWS requests
+	ctx context.Context
+
+	// Client is the AWS API Gateway Management API client
+	Client *apigatewaymanagementapi.Client
+}
+
+// WebSocketConnection is the connection of the WebSocket API client.
+type WebSocketConnection struct {
+	// ID is the connection ID.
+	ID string
+
+	// ConnectedAt is the time the client connected.
+	ConnectedAt time.Time
+
+	// LastActiveAt is the time of the last client message.
+	LastActiveAt time.Time
+
+	// SourceIP is the client IP address.
+	SourceIP string
+
+	// UserAgent is the client user agent.
+	UserAgent string
+}
+
+// WebSocket returns the client of the API Gateway WebSocket API connections.
+//
+// Parameters:
+//   - endpoint: The WebSocket API connections endpoint, for example
+//     "https://{api-id}.execute-api.{region}.amazonaws.com/{stage}" or the
+//     custom domain URL.
+func (a Aws) WebSocket(endpoint string) (ws awsWebSocket) {
+	cfg := withErrorTranslation(a.cfg, newOptions(a.opts...))
+	ws.ctx = context.TODO()
+	ws.Client = apigatewaymanagementapi.NewFromConfig(cfg,
+		func(o *apigatewaymanagementapi.Options) {
+			o.BaseEndpoint = aws.String(endpoint)
+		},
+	)
+	return
+}
+
+// Send sends the message to the connection client.
+//
+// Parameters:
+//   - connectionID: The connection ID.
+//   - data: The message. The string and []byte are sent as is, other values
+//     are marshaled to JSON.
+//
+// Returns:
+//   - err: An error if the operation fails. The error wraps ErrWebSocketGone
+//     if the client is disconnected.
+func (a awsWebSocket) Send(connectionID string, data any) (err error) {
+	body, err := messageBody(data)
+	if err != nil {
+		return
+	}
+	_, err = a.Client.PostToConnection(a.ctx,
+		&apigatewaymanagementapi.PostToConnectionInput{
+			ConnectionId: aws.String(connectionID),
+			Data:         []byte(body),
+		},
+	)
+	err = webSocketError(err)
+	return
+}
+
+// GetConnection returns the connection.
+//
+// Parameters:
+//   - connectionID: The connection ID.
+//
+// Returns:
+//   - connection: The connection.
+//   - err: An error if the operation fails. The error wraps ErrWebSocketGone
+//     if the client is disconnected.
+func (a awsWebSocket) GetConnection(connectionID string) (
+	connection WebSocketConnection, err error) {
+
+	out, err := a.Client.GetConnection(a.ctx,
+		&apigatewaymanagementapi.GetConnectionInput{
+			ConnectionId: aws.String(connectionID),
+		},
+	)
+	if err != nil {
+		err = webSocketError(err)
+		return
+	}
+	connection = WebSocketConnection{
+		ID:           connectionID,
+		ConnectedAt:  aws.ToTime(out.ConnectedAt),
+		LastActiveAt: aws.ToTime(out.LastActiveAt),
+	}
+	if out.Identity != nil {
+		connection.SourceIP = aws.ToString(out.Identity.SourceIp)
+		connection.UserAgent = aws.ToString(out.Identity.UserAgent)
+	}
+	return
+}
+
+// Delete disconnects the connection client.
+//
+// Parameters:
+//   - connectionID: The connection ID.
+//
+// Returns:
+//   - err: An error if the operation fails. The error wraps ErrWebSocketGone
+//     if the client is already disconnected.
+func (a awsWebSocket) Delete(connectionID string) (err error) {
+	_, err = a.Client.DeleteConnection(a.ctx,
+		&apigatewaymanagementapi.DeleteConnectionInput{
+			ConnectionId: aws.String(connectionID),
+		},
+	)
+	err = webSocketError(err)
+	return
+}
+
+// webSocketError wraps ErrWebSocketGone into the GoneException error.
+func webSocketError(err error) error {
+	var e *Error
+	if errors.As(err, &e) && e.Code() == "GoneException" {
+		return fmt.Errorf("%w: %w", ErrWebSocketGone, err)
+	}
+	return err
+}
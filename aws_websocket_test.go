@@ -0,0 +1,32 @@
+package aws
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// TestWebSocketSend checks API Gateway WebSocket message and disconnected
+// client error
+func TestWebSocketSend(t *testing.T) {
+
+	client := &pagesHTTPClient{bodies: []string{""}}
+	ws := newPagesTestAws(client).WebSocket(
+		"https://api1.execute-api.us-east-1.amazonaws.com/prod")
+	err := ws.Send("c1", map[string]string{"type": "ping"})
+	if err != nil || client.requests[0] != `{"type":"ping"}` {
+		t.Error("wrong send:", client.requests, err)
+	}
+
+	// Disconnected client
+	a := newErrorTestAws(http.StatusGone,
+		`{"__type":"GoneException","message":"gone"}`)
+	err = a.WebSocket("https://api1.execute-api.us-east-1.amazonaws.com/prod").
+		Send("c1", "ping")
+	var opErr *OpError
+	if !errors.Is(err, ErrWebSocketGone) || !errors.As(err, &opErr) ||
+		!strings.Contains(err.Error(), "c1") {
+		t.Error("wrong gone error:", err)
+	}
+}
@@ -0,0 +1,288 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/smithy-go/middleware"
+)
+
+const (
+	// circuitThreshold is the default number of consecutive failures which
+	// opens the circuit.
+	circuitThreshold = 5
+
+	// circuitOpenTimeout is the default time the circuit is open before the
+	// probe call.
+	circuitOpenTimeout = 30 * time.Second
+)
+
+// ErrCircuitOpen means the call was rejected because the circuit breaker of
+// the service is open.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitState is the state of the service circuit breaker.
+type CircuitState int
+
+const (
+	// CircuitClosed is the normal state, the calls are sent to the service.
+	CircuitClosed CircuitState = iota
+
+	// CircuitOpen is the state after the service failures, the calls are
+	// rejected with ErrCircuitOpen.
+	CircuitOpen
+
+	// CircuitHalfOpen is the state after the open timeout, one probe call is
+	// sent to the service and other calls are rejected.
+	CircuitHalfOpen
+)
+
+// String returns the state name.
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return "closed"
+}
+
+// CircuitBreakerOptions are the optional parameters of WithCircuitBreaker.
+type CircuitBreakerOptions struct {
+	// Threshold is the number of consecutive failed calls of the service
+	// which opens its circuit. Default is 5.
+	Threshold int
+
+	// OpenTimeout is the time the circuit is open before the probe call.
+	// Default is 30 seconds.
+	OpenTimeout time.Duration
+
+	// Services are the AWS service IDs with the circuit breakers, for
+	// example "S3" or "Cognito Identity Provider". Empty means all services.
+	Services []string
+
+	// Fallback is called for every call rejected by the open circuit with
+	// the ErrCircuitOpen error. The returned not nil error is returned by the
+	// call instead, use it to switch to the fallback and return the caller
+	// error.
+	Fallback func(service, op string, err error) error
+
+	// OnStateChange is called when the service circuit state changes. It
+	// should not block.
+	OnStateChange func(service string, state CircuitState)
+}
+
+// WithCircuitBreaker enables the circuit breakers of the services: after the
+// Threshold consecutive failures of the service calls its circuit opens and
+// the calls fail fast with ErrCircuitOpen instead of waiting for the
+// timeouts. After the OpenTimeout one probe call is sent, its success closes
+// the circuit. The failures are the timeouts, connection, throttling and 5xx
+// errors left after the AWS SDK retries, the client errors like not found do
+// not open the circuit:
+//
+//	a := aws.NewFromConfig(cfg, aws.WithCircuitBreaker(
+//		aws.CircuitBreakerOptions{Services: []string{"S3"}}))
+//	...
+//	if errors.Is(err, aws.ErrCircuitOpen) { ... }
+//
+// The circuits are shared by all Aws created with the returned option.
+//
+// Parameters:
+//   - opts: The optional circuit breaker parameters.
+func WithCircuitBreaker(opts ...CircuitBreakerOptions) Option {
+	var o CircuitBreakerOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	if o.Threshold <= 0 {
+		o.Threshold = circuitThreshold
+	}
+	if o.OpenTimeout <= 0 {
+		o.OpenTimeout = circuitOpenTimeout
+	}
+	b := &circuitBreaker{opts: o, circuits: make(map[string]*circuit)}
+	return func(o *options) { o.breaker = b }
+}
+
+// circuitBreaker contains the circuits of the services.
+type circuitBreaker struct {
+	opts CircuitBreakerOptions
+
+	mu       sync.Mutex
+	circuits map[string]*circuit
+	probes   uint64
+}
+
+// circuit is the circuit of the service.
+type circuit struct {
+	state    CircuitState
+	failures int
+	openedAt time.Time
+
+	// probe is the token of the probe call in flight, zero if there is no
+	// probe call
+	probe uint64
+}
+
+// allow returns true if the service call may be sent. The half-open circuit
+// allows one probe call, its not zero probe token is passed to done.
+func (b *circuitBreaker) allow(service string, now time.Time) (probe uint64,
+	ok bool) {
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c := b.circuit(service)
+	switch c.state {
+	case CircuitOpen:
+		if now.Sub(c.openedAt) < b.opts.OpenTimeout {
+			return
+		}
+		b.setState(service, c, CircuitHalfOpen)
+		fallthrough
+	case CircuitHalfOpen:
+		if c.probe != 0 {
+			return
+		}
+		b.probes++
+		c.probe, probe = b.probes, b.probes
+	}
+	ok = true
+	return
+}
+
+// done records the result of the allowed service call with the probe token
+// returned by allow. The canceled call result does not change the circuit
+// state. The results of the calls sent before the circuit opened are
+// ignored until the probe call closes it.
+func (b *circuitBreaker) done(service string, now time.Time, probe uint64,
+	err error) {
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c := b.circuit(service)
+	if probe != c.probe || (probe == 0 && c.state != CircuitClosed) {
+		return
+	}
+	c.probe = 0
+	switch {
+	case errors.Is(err, context.Canceled):
+	case !circuitFailure(err):
+		c.failures = 0
+		b.setState(service, c, CircuitClosed)
+	case c.state == CircuitHalfOpen:
+		c.openedAt = now
+		b.setState(service, c, CircuitOpen)
+	case c.state == CircuitClosed:
+		c.failures++
+		if c.failures >= b.opts.Threshold {
+			c.openedAt = now
+			b.setState(service, c, CircuitOpen)
+		}
+	}
+}
+
+// circuit returns the service circuit, it is created on the first call.
+func (b *circuitBreaker) circuit(service string) *circuit {
+	c, ok := b.circuits[service]
+	if !ok {
+		c = &circuit{}
+		b.circuits[service] = c
+	}
+	return c
+}
+
+// setState sets the circuit state and calls the state change hook.
+func (b *circuitBreaker) setState(service string, c *circuit,
+	state CircuitState) {
+
+	if c.state == state {
+		return
+	}
+	c.state = state
+	if b.opts.OnStateChange != nil {
+		b.opts.OnStateChange(service, state)
+	}
+}
+
+// states returns the states of the services circuits.
+func (b *circuitBreaker) states() (states map[string]CircuitState) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	states = make(map[string]CircuitState, len(b.circuits))
+	for service, c := range b.circuits {
+		states[service] = c.state
+	}
+	return
+}
+
+// circuitFailure returns true if the call error means the service outage.
+func circuitFailure(err error) bool {
+	return IsRetryable(err) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// withCircuitBreaker returns the AWS config with the middleware which
+// rejects the calls of the services with the open circuits.
+func withCircuitBreaker(cfg aws.Config, o options) aws.Config {
+	b := o.breaker
+	if b == nil {
+		return cfg
+	}
+	cfg.APIOptions = append(slices.Clip(cfg.APIOptions),
+		func(stack *middleware.Stack) error {
+			return stack.Initialize.Add(middleware.InitializeMiddlewareFunc(
+				"CircuitBreaker",
+				func(ctx context.Context, in middleware.InitializeInput,
+					next middleware.InitializeHandler) (
+					out middleware.InitializeOutput, md middleware.Metadata,
+					err error) {
+
+					service := middleware.GetServiceID(ctx)
+					if len(b.opts.Services) > 0 && !slices.ContainsFunc(
+						b.opts.Services, func(s string) bool {
+							return strings.EqualFold(s, service)
+						}) {
+						return next.HandleInitialize(ctx, in)
+					}
+
+					clk := clock(ctx)
+					probe, ok := b.allow(service, clk.Now())
+					if !ok {
+						err = ErrCircuitOpen
+						if b.opts.Fallback == nil {
+							return
+						}
+						if e := b.opts.Fallback(service,
+							middleware.GetOperationName(ctx), err); e != nil {
+							err = e
+						}
+						return
+					}
+					out, md, err = next.HandleInitialize(ctx, in)
+					b.done(service, clk.Now(), probe, err)
+					return
+				},
+			), middleware.After)
+		},
+	)
+	return cfg
+}
+
+// CircuitStates returns the circuit breaker states of the services called
+// by the Aws clients, use it in the health endpoints. It returns nil if the
+// circuit breaker is not enabled with WithCircuitBreaker.
+//
+// Returns:
+//   - states: The circuit states by the AWS service IDs.
+func (a Aws) CircuitStates() (states map[string]CircuitState) {
+	b := newOptions(a.opts...).breaker
+	if b == nil {
+		return
+	}
+	return b.states()
+}
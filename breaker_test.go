@@ -0,0 +1,133 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+)
+
+// TestCircuitBreaker checks the circuit opens after the service failures and
+// closes after the successful probe call
+func TestCircuitBreaker(t *testing.T) {
+
+	const failure = `{"__type":"InternalFailure","message":"outage"}`
+	client := &pagesHTTPClient{bodies: []string{
+		failure,
+		`{"__type":"com.amazonaws.sqs#QueueDoesNotExist","message":"no"}`,
+		failure, failure,
+		failure,
+		`{"QueueUrl":"https://sqs/1/jobs"}`,
+	}, statuses: []int{500, 400, 500, 500, 500, 200}}
+	cfg := newPagesTestAws(client).Config()
+	cfg.RetryMaxAttempts = 1
+
+	fallbackErr := errors.New("use cache")
+	var changes []string
+	clk := NewFakeClock(time.Now())
+	a := NewFromConfig(cfg, WithClock(clk), WithCircuitBreaker(
+		CircuitBreakerOptions{
+			Threshold:   2,
+			OpenTimeout: time.Minute,
+			Services:    []string{"sqs"},
+			Fallback: func(service, op string, err error) error {
+				if service != "SQS" || op != "GetQueueUrl" ||
+					!errors.Is(err, ErrCircuitOpen) {
+					t.Error("wrong fallback call:", service, op, err)
+				}
+				return fallbackErr
+			},
+			OnStateChange: func(service string, state CircuitState) {
+				changes = append(changes, service+" "+state.String())
+			},
+		},
+	))
+	ctx := withClock(context.Background(), clk)
+	call := func() error {
+		_, err := a.SQS.Client.GetQueueUrl(ctx,
+			&sqs.GetQueueUrlInput{QueueName: aws.String("jobs")})
+		return err
+	}
+
+	// The not found error resets the failures
+	for i, check := range []func(error) bool{IsRetryable, IsNotFound,
+		IsRetryable, IsRetryable} {
+		if err := call(); !check(err) {
+			t.Fatal("wrong call", i, "error:", err)
+		}
+	}
+	if a.CircuitStates()["SQS"] != CircuitOpen {
+		t.Fatal("circuit is not open:", a.CircuitStates())
+	}
+
+	// Open circuit rejects the calls
+	if err := call(); !errors.Is(err, fallbackErr) ||
+		len(client.requests) != 4 {
+		t.Fatal("call is not rejected:", err, len(client.requests))
+	}
+
+	// Failed probe opens the circuit again
+	clk.Advance(time.Minute)
+	if err := call(); !IsRetryable(err) ||
+		a.CircuitStates()["SQS"] != CircuitOpen {
+		t.Fatal("wrong failed probe:", err, a.CircuitStates())
+	}
+	if err := call(); !errors.Is(err, fallbackErr) {
+		t.Fatal("call is not rejected after probe:", err)
+	}
+
+	// Successful probe closes the circuit
+	clk.Advance(time.Minute)
+	if err := call(); err != nil {
+		t.Fatal("wrong successful probe:", err)
+	}
+	if s := a.CircuitStates()["SQS"]; s != CircuitClosed {
+		t.Error("circuit is not closed:", s)
+	}
+	want := "[SQS open SQS half-open SQS open SQS half-open SQS closed]"
+	if got := fmt.Sprint(changes); got != want {
+		t.Error("wrong state changes:", got)
+	}
+
+	// Not enabled circuit breaker
+	if states := newPagesTestAws(client).CircuitStates(); states != nil {
+		t.Error("wrong states without breaker:", states)
+	}
+}
+
+// TestCircuitBreakerProbe checks the call sent before the circuit opened
+// does not end the probe call
+func TestCircuitBreakerProbe(t *testing.T) {
+
+	b := &circuitBreaker{opts: CircuitBreakerOptions{Threshold: 1,
+		OpenTimeout: time.Minute}, circuits: make(map[string]*circuit)}
+	now := time.Now()
+	outage := context.DeadlineExceeded
+
+	// The slow call is in flight when the circuit opens
+	slow, _ := b.allow("S3", now)
+	failed, _ := b.allow("S3", now)
+	b.done("S3", now, failed, outage)
+
+	// The slow call result does not end the probe
+	now = now.Add(time.Minute)
+	probe, ok := b.allow("S3", now)
+	if !ok || probe == 0 {
+		t.Fatal("probe is not allowed:", probe, ok)
+	}
+	b.done("S3", now, slow, nil)
+	if _, ok = b.allow("S3", now); ok ||
+		b.states()["S3"] != CircuitHalfOpen {
+		t.Fatal("second probe is allowed:", b.states())
+	}
+
+	// The probe result closes the circuit
+	b.done("S3", now, probe, nil)
+	if _, ok = b.allow("S3", now); !ok || b.states()["S3"] != CircuitClosed {
+		t.Error("circuit is not closed:", b.states())
+	}
+}
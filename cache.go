@@ -0,0 +1,348 @@
+package aws
+
+import (
+	"container/list"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// LookupCache is a generic cache of values read by the lookup function.
+//
+// The cache keeps the values and the negative lookup results (errors which
+// mean the value does not exist, for example not found errors), evicts the
+// least recently used values when MaxEntries is reached, expires the values
+// after TTL and coalesces concurrent lookups of the same key into one lookup
+// call. The other lookup errors are returned to the caller and are not
+// cached.
+//
+// The exported fields should be set before the cache is used.
+type LookupCache[K comparable, V any] struct {
+	// MaxEntries is the maximum number of cached values. When the limit is
+	// reached the least recently used value is evicted from the cache. Zero
+	// means no limit.
+	MaxEntries int
+
+	// TTL is the time to live of cached values. Expired values are read by
+	// the lookup function on the next Get. Zero means values never expire.
+	TTL time.Duration
+
+	// RefreshAhead enables the refresh-ahead mode when TTL is set. The values
+	// requested less than RefreshAhead before expiry are returned from the
+	// cache and re-read in background. The refresh time of each value is
+	// randomly shifted to spread the refreshes. Zero disables the
+	// refresh-ahead mode.
+	RefreshAhead time.Duration
+
+	// MaxRefreshes is the maximum number of concurrent background refreshes.
+	// The refresh is skipped when the limit is reached and is retried by the
+	// next Get. Default is 4.
+	MaxRefreshes int
+
+	// OnError is called when the lookup function fails with not negative
+	// error, including the background refreshes which errors are not returned
+	// to the caller.
+	OnError func(key K, err error)
+
+	// Clock is the time source of the TTLs and refreshes. Default is the
+	// SystemClock.
+	Clock Clock
+
+	lookup   func(key K) (V, error)
+	negative func(err error) bool
+
+	mu      sync.Mutex
+	entries map[K]*list.Element
+	lru     *list.List
+
+	// group coalesces concurrent lookups of the same key
+	group singleflight.Group
+
+	// generation is incremented by Delete and Clear to drop results of the
+	// lookups started before them
+	generation uint64
+
+	// refreshing is the number of running background refreshes
+	refreshing atomic.Int32
+
+	hits, misses, errors, evictions, refreshes atomic.Int64
+}
+
+// CacheStats contains the LookupCache counters.
+type CacheStats struct {
+	// Hits is the number of Get calls returned from the cache.
+	Hits int64
+
+	// Misses is the number of Get calls which called the lookup function.
+	Misses int64
+
+	// Errors is the number of failed lookups, not counting negative results.
+	Errors int64
+
+	// Evictions is the number of values evicted by MaxEntries limit.
+	Evictions int64
+
+	// Refreshes is the number of background refreshes.
+	Refreshes int64
+}
+
+// CacheEntry is the cached value returned by LookupCache.Peek.
+type CacheEntry[V any] struct {
+	// Value is the cached value.
+	Value V
+
+	// Err is the cached negative lookup result.
+	Err error
+
+	// CachedAt is the time the value was added to the cache.
+	CachedAt time.Time
+}
+
+// lookupEntry is the LookupCache entry.
+type lookupEntry[K comparable, V any] struct {
+	key   K
+	value V
+	err   error
+
+	// cachedAt is the time the value was added to the cache
+	cachedAt time.Time
+
+	// refreshAt is the time after which the value is refreshed in background
+	refreshAt time.Time
+}
+
+// NewLookupCache creates new LookupCache.
+//
+// Parameters:
+//   - lookup: The function which reads the value of the key.
+//   - negative: The function which checks if the lookup error is a negative
+//     result which should be cached, for example not found error. May be nil
+//     if negative results are not cached.
+func NewLookupCache[K comparable, V any](lookup func(key K) (V, error),
+	negative func(err error) bool) *LookupCache[K, V] {
+
+	return &LookupCache[K, V]{
+		lookup:   lookup,
+		negative: negative,
+		entries:  make(map[K]*list.Element),
+		lru:      list.New(),
+	}
+}
+
+// Get returns the value of the key from the cache or reads it by the lookup
+// function and adds it to the cache.
+func (c *LookupCache[K, V]) Get(key K) (value V, err error) {
+
+	// Get value from cache and mark it as recently used
+	c.mu.Lock()
+	entry, ok := c.get(key)
+	c.mu.Unlock()
+	if ok && !c.expired(entry) {
+		c.hits.Add(1)
+		if c.RefreshAhead > 0 && c.now().After(entry.refreshAt) {
+			c.refresh(key)
+		}
+		return entry.value, entry.err
+	}
+
+	c.misses.Add(1)
+	return c.fetch(key)
+}
+
+// Peek returns the cached entry of the key without the lookup function call
+// and without changing the least recently used order. The ok is false if the
+// key is not cached or expired.
+func (c *LookupCache[K, V]) Peek(key K) (entry CacheEntry[V], ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return
+	}
+	e := elem.Value.(*lookupEntry[K, V])
+	if c.expired(e) {
+		ok = false
+		return
+	}
+	entry = CacheEntry[V]{Value: e.value, Err: e.err, CachedAt: e.cachedAt}
+	return
+}
+
+// Contains returns true if the key is cached and not expired.
+func (c *LookupCache[K, V]) Contains(key K) (ok bool) {
+	_, ok = c.Peek(key)
+	return
+}
+
+// Set adds the value of the key to the cache.
+func (c *LookupCache[K, V]) Set(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.add(key, value, nil)
+}
+
+// Delete removes the key from the cache, the next Get reads the value by the
+// lookup function.
+func (c *LookupCache[K, V]) Delete(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+
+	if elem, ok := c.entries[key]; ok {
+		c.lru.Remove(elem)
+		delete(c.entries, key)
+	}
+}
+
+// Clear removes all values from the cache.
+func (c *LookupCache[K, V]) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	c.entries = make(map[K]*list.Element)
+	c.lru.Init()
+}
+
+// Len returns the number of cached values.
+func (c *LookupCache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+// Stats returns the cache counters.
+func (c *LookupCache[K, V]) Stats() CacheStats {
+	return CacheStats{
+		Hits:      c.hits.Load(),
+		Misses:    c.misses.Load(),
+		Errors:    c.errors.Load(),
+		Evictions: c.evictions.Load(),
+		Refreshes: c.refreshes.Load(),
+	}
+}
+
+// fetch reads the value by the lookup function and adds it to the cache.
+// Concurrent lookups of the same key wait for the first one, the cache is not
+// locked during the lookup.
+func (c *LookupCache[K, V]) fetch(key K) (value V, err error) {
+	type result struct {
+		value V
+		err   error
+	}
+
+	c.mu.Lock()
+	generation := c.generation
+	c.mu.Unlock()
+	v, _, _ := c.group.Do(fmt.Sprintf("%d/%v", generation, key),
+		func() (any, error) {
+			value, err := c.lookup(key)
+			negative := err != nil && c.negative != nil && c.negative(err)
+			if err != nil && !negative {
+				c.errors.Add(1)
+				if c.OnError != nil {
+					c.OnError(key, err)
+				}
+			}
+
+			// Add value or negative result to cache if the key was not
+			// deleted and the cache was not cleared during the lookup
+			if err == nil || negative {
+				c.mu.Lock()
+				if generation == c.generation {
+					c.add(key, value, err)
+				}
+				c.mu.Unlock()
+			}
+
+			return result{value, err}, nil
+		},
+	)
+
+	r := v.(result)
+	return r.value, r.err
+}
+
+// refresh starts background fetch of the key if the number of running
+// refreshes is less than MaxRefreshes.
+func (c *LookupCache[K, V]) refresh(key K) {
+	maxRefreshes := c.MaxRefreshes
+	if maxRefreshes <= 0 {
+		maxRefreshes = 4
+	}
+	if c.refreshing.Add(1) > int32(maxRefreshes) {
+		c.refreshing.Add(-1)
+		return
+	}
+	c.refreshes.Add(1)
+
+	go func() {
+		defer c.refreshing.Add(-1)
+		c.fetch(key)
+	}()
+}
+
+// expired returns true if the cache entry is expired.
+func (c *LookupCache[K, V]) expired(entry *lookupEntry[K, V]) bool {
+	return c.TTL > 0 && c.now().Sub(entry.cachedAt) >= c.TTL
+}
+
+// now returns the current time of the cache Clock.
+func (c *LookupCache[K, V]) now() time.Time {
+	if c.Clock == nil {
+		return SystemClock.Now()
+	}
+	return c.Clock.Now()
+}
+
+// get returns the cache entry and moves it to the front of the LRU list.
+func (c *LookupCache[K, V]) get(key K) (entry *lookupEntry[K, V], ok bool) {
+	elem, ok := c.entries[key]
+	if !ok {
+		return
+	}
+	c.lru.MoveToFront(elem)
+	entry = elem.Value.(*lookupEntry[K, V])
+	return
+}
+
+// add adds the cache entry and evicts the least recently used entries.
+func (c *LookupCache[K, V]) add(key K, value V, err error) {
+	entry := c.newEntry(key, value, err)
+
+	// Update existing entry
+	if elem, ok := c.entries[key]; ok {
+		elem.Value = entry
+		c.lru.MoveToFront(elem)
+		return
+	}
+
+	// Add new entry
+	c.entries[key] = c.lru.PushFront(entry)
+
+	// Evict least recently used entries
+	for c.MaxEntries > 0 && c.lru.Len() > c.MaxEntries {
+		elem := c.lru.Back()
+		c.lru.Remove(elem)
+		delete(c.entries, elem.Value.(*lookupEntry[K, V]).key)
+		c.evictions.Add(1)
+	}
+}
+
+// newEntry creates the cache entry. The refresh time is set randomly between
+// RefreshAhead and half of RefreshAhead before expiry.
+func (c *LookupCache[K, V]) newEntry(key K, value V,
+	err error) *lookupEntry[K, V] {
+
+	now := c.now()
+	entry := &lookupEntry[K, V]{key: key, value: value, err: err, cachedAt: now}
+	if c.TTL > 0 && c.RefreshAhead > 0 {
+		jitter := time.Duration(rand.Int64N(int64(c.RefreshAhead/2) + 1))
+		entry.refreshAt = now.Add(c.TTL - c.RefreshAhead + jitter)
+	}
+	return entry
+}
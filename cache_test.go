@@ -0,0 +1,194 @@
+package aws
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var errTestNotFound = errors.New("not found")
+
+// newTestCache creates LookupCache which lookup returns key length or
+// errTestNotFound for empty key and counts lookup calls.
+func newTestCache(calls *atomic.Int32, delay time.Duration) *LookupCache[string, int] {
+	return NewLookupCache(
+		func(key string) (int, error) {
+			calls.Add(1)
+			time.Sleep(delay)
+			if key == "" {
+				return 0, errTestNotFound
+			}
+			if key == "error" {
+				return 0, errors.New("lookup error")
+			}
+			return len(key), nil
+		},
+		func(err error) bool { return errors.Is(err, errTestNotFound) },
+	)
+}
+
+func TestLookupCacheGet(t *testing.T) {
+
+	var calls atomic.Int32
+	c := newTestCache(&calls, 0)
+
+	// Values and negative results are cached, other errors are not
+	for i := 0; i < 2; i++ {
+		if v, err := c.Get("key"); err != nil || v != 3 {
+			t.Error("wrong value:", v, err)
+		}
+		if _, err := c.Get(""); !errors.Is(err, errTestNotFound) {
+			t.Error("wrong negative result:", err)
+		}
+		if _, err := c.Get("error"); err == nil {
+			t.Error("lookup error is not returned")
+		}
+	}
+	if n := calls.Load(); n != 4 {
+		t.Error("wrong number of lookups:", n)
+	}
+
+	stats := c.Stats()
+	if stats.Hits != 2 || stats.Misses != 4 || stats.Errors != 2 {
+		t.Error("wrong stats:", stats)
+	}
+}
+
+func TestLookupCacheMaxEntries(t *testing.T) {
+
+	var calls atomic.Int32
+	c := newTestCache(&calls, 0)
+	c.MaxEntries = 2
+
+	// Add three values, the least recently used one is evicted
+	c.Get("a")
+	c.Get("bb")
+	c.Get("a")
+	c.Get("ccc")
+
+	if l := c.Len(); l != 2 {
+		t.Error("wrong cache length:", l)
+		return
+	}
+	if _, ok := c.get("bb"); ok {
+		t.Error("least recently used value is not evicted")
+	}
+	if _, ok := c.get("a"); !ok {
+		t.Error("recently used value is evicted")
+	}
+	if e := c.Stats().Evictions; e != 1 {
+		t.Error("wrong number of evictions:", e)
+	}
+}
+
+func TestLookupCacheDelete(t *testing.T) {
+
+	var calls atomic.Int32
+	c := newTestCache(&calls, 0)
+
+	c.Get("a")
+	c.Get("bb")
+	c.Delete("a")
+	c.Delete("ccc")
+	if l := c.Len(); l != 1 {
+		t.Error("wrong cache length:", l)
+	}
+
+	c.Clear()
+	if l := c.Len(); l != 0 {
+		t.Error("wrong cache length after clear:", l)
+	}
+}
+
+func TestLookupCacheTTL(t *testing.T) {
+
+	var calls atomic.Int32
+	c := newTestCache(&calls, 0)
+	c.TTL = 50 * time.Millisecond
+	c.RefreshAhead = 20 * time.Millisecond
+
+	c.Get("key")
+	entry, _ := c.get("key")
+	if c.expired(entry) {
+		t.Error("value is expired right after adding")
+		return
+	}
+
+	// Refresh time is between RefreshAhead and half of RefreshAhead before
+	// expiry
+	ahead := entry.cachedAt.Add(c.TTL).Sub(entry.refreshAt)
+	if ahead < c.RefreshAhead/2 || ahead > c.RefreshAhead {
+		t.Error("wrong refresh time:", ahead)
+	}
+
+	time.Sleep(c.TTL)
+	if !c.expired(entry) {
+		t.Error("value is not expired after TTL")
+	}
+}
+
+func TestLookupCacheSingleflight(t *testing.T) {
+
+	var calls atomic.Int32
+	c := newTestCache(&calls, 20*time.Millisecond)
+
+	// Concurrent Get calls of the same key call lookup once
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.Get("key")
+		}()
+	}
+	wg.Wait()
+
+	if n := calls.Load(); n != 1 {
+		t.Error("wrong number of lookups:", n)
+	}
+}
+
+func TestLookupCacheOnError(t *testing.T) {
+
+	var calls atomic.Int32
+	c := newTestCache(&calls, 0)
+
+	var errorsNum int
+	c.OnError = func(key string, err error) { errorsNum++ }
+
+	c.Get("key")
+	c.Get("")
+	c.Get("error")
+
+	if errorsNum != 1 {
+		t.Error("wrong number of OnError calls:", errorsNum)
+	}
+}
+
+func TestLookupCachePeek(t *testing.T) {
+
+	var calls atomic.Int32
+	c := newTestCache(&calls, 0)
+
+	if c.Contains("key") {
+		t.Error("not cached key is in cache")
+	}
+
+	start := time.Now()
+	c.Get("key")
+	entry, ok := c.Peek("key")
+	if !ok || entry.Value != 3 || entry.CachedAt.Before(start) {
+		t.Error("wrong peek result:", entry, ok)
+	}
+	if !c.Contains("key") {
+		t.Error("cached key is not in cache")
+	}
+
+	// Peek does not call lookup
+	c.Peek("other")
+	if n := calls.Load(); n != 1 {
+		t.Error("wrong number of lookups:", n)
+	}
+}
@@ -0,0 +1,93 @@
+package aws
+
+import (
+	"context"
+	"time"
+)
+
+// Clock is the source of the current time, timers and tickers of the package
+// functions: the cache TTLs, the retry backoff and wait delays, the watchers
+// poll intervals, the rate limiters, heartbeats and flush loops, and the
+// presigned URLs signing time. It is the SystemClock by default, use
+// WithClock and the FakeClock to test the time dependent code
+// deterministically.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+
+	// After returns the channel which receives the current time after the
+	// duration d.
+	After(d time.Duration) <-chan time.Time
+
+	// NewTicker returns the Ticker which channel receives the current time
+	// every period d. The d must be positive.
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker is the Clock ticker, it is stopped by Stop.
+type Ticker interface {
+	// C returns the ticks channel.
+	C() <-chan time.Time
+
+	// Stop stops the ticker, no more ticks are sent after Stop.
+	Stop()
+}
+
+// SystemClock is the Clock of the real time.
+var SystemClock Clock = systemClock{}
+
+// systemClock is the real time Clock.
+type systemClock struct{}
+
+// Now returns the current time.
+func (systemClock) Now() time.Time { return time.Now() }
+
+// After returns the time.After channel.
+func (systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// NewTicker returns the time.Ticker.
+func (systemClock) NewTicker(d time.Duration) Ticker {
+	return systemTicker{time.NewTicker(d)}
+}
+
+// systemTicker is the real time Ticker.
+type systemTicker struct{ *time.Ticker }
+
+// C returns the time.Ticker channel.
+func (t systemTicker) C() <-chan time.Time { return t.Ticker.C }
+
+// clockContextKey is the context key of the Aws Clock.
+type clockContextKey struct{}
+
+// WithClock sets the Clock of the package clients and caches.
+//
+// Parameters:
+//   - c: The clock, for example the FakeClock in tests.
+func WithClock(c Clock) Option {
+	return func(o *options) { o.clock = c }
+}
+
+// withClock returns the context with the Clock if it is set.
+func withClock(ctx context.Context, c Clock) context.Context {
+	if c == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, clockContextKey{}, c)
+}
+
+// clock returns the Clock of the context or the SystemClock.
+func clock(ctx context.Context) Clock {
+	if ctx != nil {
+		if c, ok := ctx.Value(clockContextKey{}).(Clock); ok {
+			return c
+		}
+	}
+	return SystemClock
+}
+
+// sleep waits for the duration d of the Clock.
+func sleep(c Clock, d time.Duration) {
+	<-c.After(d)
+}
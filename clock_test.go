@@ -0,0 +1,190 @@
+package aws
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// TestClockCacheTTL checks the LookupCache expiry with the FakeClock
+func TestClockCacheTTL(t *testing.T) {
+
+	clk := NewFakeClock(time.Now())
+	lookups := 0
+	cache := NewLookupCache(func(key string) (string, error) {
+		lookups++
+		return key, nil
+	}, nil)
+	cache.TTL = time.Minute
+	cache.Clock = clk
+
+	cache.Get("k")
+	clk.Advance(time.Minute - time.Second)
+	cache.Get("k")
+	if lookups != 1 {
+		t.Error("value expired before TTL:", lookups)
+	}
+	clk.Advance(time.Second)
+	cache.Get("k")
+	if lookups != 2 {
+		t.Error("value not expired after TTL:", lookups)
+	}
+}
+
+// TestClockWait checks the Polly task wait delays with the FakeClock
+func TestClockWait(t *testing.T) {
+
+	client := &pagesHTTPClient{bodies: []string{
+		`{"SynthesisTask":{"TaskId":"t-1","TaskStatus":"inProgress"}}`,
+		`{"SynthesisTask":{"TaskId":"t-1","TaskStatus":"inProgress"}}`,
+		`{"SynthesisTask":{"TaskId":"t-1","TaskStatus":"completed"}}`,
+	}}
+	clk := NewFakeClock(time.Now())
+	a := NewFromConfig(newPagesTestAws(client).Config(), WithClock(clk))
+
+	done := make(chan error)
+	go func() {
+		_, err := a.Polly.WaitForTask(context.Background(), "t-1")
+		done <- err
+	}()
+
+	// The first delay is pollyWaitDelay, the second is doubled
+	clk.BlockUntil(1)
+	clk.Advance(pollyWaitDelay)
+	clk.BlockUntil(1)
+	clk.Advance(pollyWaitDelay)
+	select {
+	case err := <-done:
+		t.Fatal("wait done before the second delay:", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+	clk.Advance(pollyWaitDelay)
+	if err := <-done; err != nil {
+		t.Error("wait:", err)
+	}
+}
+
+// TestClockTicker checks the FakeClock ticker ticks, dropped ticks and stop
+func TestClockTicker(t *testing.T) {
+
+	clk := NewFakeClock(time.Now())
+	ticker := clk.NewTicker(time.Second)
+	select {
+	case <-ticker.C():
+		t.Fatal("tick before period")
+	default:
+	}
+
+	// One tick is sent for several periods
+	clk.Advance(3 * time.Second)
+	if now := <-ticker.C(); !now.Equal(clk.Now()) {
+		t.Error("wrong tick time:", now)
+	}
+	select {
+	case <-ticker.C():
+		t.Error("dropped tick is sent")
+	default:
+	}
+	clk.Advance(time.Second)
+	<-ticker.C()
+
+	if clk.Waiters() != 1 {
+		t.Error("wrong ticker waiters:", clk.Waiters())
+	}
+	ticker.Stop()
+	clk.Advance(time.Second)
+	select {
+	case <-ticker.C():
+		t.Error("tick after stop")
+	default:
+	}
+	if clk.Waiters() != 0 {
+		t.Error("ticker is not removed:", clk.Waiters())
+	}
+}
+
+// TestClockPresign checks the presigned URL and form are signed at the
+// FakeClock time and expire by the clock
+func TestClockPresign(t *testing.T) {
+
+	signed := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	clk := NewFakeClock(signed)
+	fake := NewFakeS3("bucket")
+	fake.Clock = clk
+	a := NewFromConfig(fake.Config(), WithClock(clk))
+
+	url, header, err := a.S3.PresignPut("bucket", "a b.txt",
+		S3PresignOptions{Expires: time.Minute})
+	if err != nil || !strings.Contains(url, "X-Amz-Date=20200102T030405Z") {
+		t.Fatal("wrong presigned put:", url, err)
+	}
+	post, err := a.S3.PresignPost("bucket", "form.txt",
+		S3PresignOptions{Expires: time.Minute})
+	if err != nil || post.Fields["X-Amz-Date"] != "20200102T030405Z" {
+		t.Fatal("wrong presigned post:", post, err)
+	}
+
+	// The URL expires after the clock advance
+	put := func() int {
+		req, _ := http.NewRequest(http.MethodPut, url, strings.NewReader("a"))
+		req.Header = header
+		resp, err := fake.Do(req)
+		if err != nil {
+			t.Fatal("presigned put request:", err)
+		}
+		return resp.StatusCode
+	}
+	if status := put(); status != http.StatusOK {
+		t.Error("wrong put status:", status)
+	}
+	clk.Advance(time.Minute + time.Second)
+	if status := put(); status != http.StatusForbidden {
+		t.Error("wrong expired put status:", status)
+	}
+}
+
+// TestClockVisibility checks the SQS consumer extends the message visibility
+// by the FakeClock ticker while the handler is running
+func TestClockVisibility(t *testing.T) {
+
+	var mu sync.Mutex
+	client := &pagesHTTPClient{bodies: []string{`{}`, `{}`}}
+	targets := make(chan string, 2)
+	cfg := newPagesTestAws(client).Config()
+	cfg.HTTPClient = smithyhttp.ClientDoFunc(
+		func(r *http.Request) (*http.Response, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			targets <- r.Header.Get("X-Amz-Target")
+			return client.Do(r)
+		})
+	clk := NewFakeClock(time.Now())
+	a := NewFromConfig(cfg, WithClock(clk))
+
+	release := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		a.SQS.handle("https://sqs.us-east-1.amazonaws.com/1/q",
+			SQSMessage{ID: "m-1", ReceiptHandle: "r-1"},
+			func(SQSMessage) error { <-release; return nil },
+			SQSConsumeOptions{Visibility: time.Minute,
+				OnError: func(_ SQSMessage, err error) { t.Error(err) }})
+	}()
+
+	clk.BlockUntil(1)
+	clk.Advance(30 * time.Second)
+	if target := <-targets; target != "AmazonSQS.ChangeMessageVisibility" {
+		t.Error("wrong visibility request:", target)
+	}
+	close(release)
+	<-done
+	if target := <-targets; target != "AmazonSQS.DeleteMessage" {
+		t.Error("wrong delete request:", target)
+	}
+}
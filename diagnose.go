@@ -0,0 +1,261 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+// diagnoseWriteKey is the key prefix of the object written by the
+// CheckBucketWrite probe.
+const diagnoseWriteKey = ".aws-diagnose/"
+
+// DiagnoseCheck is the probe of the Aws Diagnose. Use the Check functions to
+// create the package probes or set the Run to add the custom probe.
+type DiagnoseCheck struct {
+	// Name is the check name shown in the report, for example
+	// "bucket data readable".
+	Name string
+
+	// Run runs the probe and returns the detail shown in the report, for
+	// example the caller ARN, or the error.
+	Run func(ctx context.Context, a Aws) (detail string, err error)
+}
+
+// DiagnoseResult is the result of the DiagnoseCheck.
+type DiagnoseResult struct {
+	// Name is the check name.
+	Name string
+
+	// Detail is the check detail, for example the caller ARN.
+	Detail string
+
+	// Err is the check error, nil if the check passed.
+	Err error
+
+	// Duration is the check duration.
+	Duration time.Duration
+}
+
+// DiagnoseReport is the report of the Aws Diagnose.
+type DiagnoseReport struct {
+	// Results are the checks results in the checks order.
+	Results []DiagnoseResult
+}
+
+// OK returns true if all checks passed.
+func (r DiagnoseReport) OK() bool {
+	return r.Err() == nil
+}
+
+// Err returns the joined errors of the failed checks prefixed with the
+// checks names, or nil if all checks passed.
+func (r DiagnoseReport) Err() error {
+	var errs []error
+	for _, res := range r.Results {
+		if res.Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", res.Name, res.Err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// String returns the report with one check per line:
+//
+//	ok   credentials: arn:aws:iam::123456789012:user/dev (120ms)
+//	FAIL bucket data readable: ... AccessDenied ... (80ms)
+func (r DiagnoseReport) String() string {
+	var b strings.Builder
+	for _, res := range r.Results {
+		status, detail := "ok  ", res.Detail
+		if res.Err != nil {
+			status, detail = "FAIL", res.Err.Error()
+		}
+		b.WriteString(status + " " + res.Name)
+		if detail != "" {
+			b.WriteString(": " + detail)
+		}
+		fmt.Fprintf(&b, " (%s)\n", res.Duration.Round(time.Millisecond))
+	}
+	return b.String()
+}
+
+// Diagnose runs the checks of the Aws configuration and returns the report
+// of all checks, so the onboarding of the new environment shows all
+// configuration problems at once:
+//
+//	report := a.Diagnose(ctx, aws.CheckCredentials(), aws.CheckRegion(),
+//		aws.CheckBucketRead("data"), aws.CheckFunction("api"))
+//	if !report.OK() {
+//		log.Fatal("aws configuration:\n", report)
+//	}
+//
+// The failed check does not stop the next checks.
+//
+// Parameters:
+//   - ctx: The context of the checks.
+//   - checks: The checks. Default are CheckCredentials and CheckRegion.
+//
+// Returns:
+//   - report: The checks report.
+func (a Aws) Diagnose(ctx context.Context, checks ...DiagnoseCheck) (
+	report DiagnoseReport) {
+
+	if len(checks) == 0 {
+		checks = []DiagnoseCheck{CheckCredentials(), CheckRegion()}
+	}
+	for _, check := range checks {
+		start := time.Now()
+		detail, err := check.Run(ctx, a)
+		report.Results = append(report.Results, DiagnoseResult{
+			Name:     check.Name,
+			Detail:   detail,
+			Err:      err,
+			Duration: time.Since(start),
+		})
+	}
+	return
+}
+
+// CheckCredentials checks the credentials are configured and valid. The
+// detail is the caller identity ARN.
+func CheckCredentials() DiagnoseCheck {
+	return DiagnoseCheck{Name: "credentials", Run: func(ctx context.Context,
+		a Aws) (detail string, err error) {
+
+		if a.cfg.Credentials == nil {
+			err = errors.New("credentials are not configured")
+			return
+		}
+		if _, err = a.cfg.Credentials.Retrieve(ctx); err != nil {
+			return
+		}
+		out, err := a.STS.Client.GetCallerIdentity(ctx,
+			&sts.GetCallerIdentityInput{})
+		if err != nil {
+			return
+		}
+		detail = aws.ToString(out.Arn)
+		return
+	}}
+}
+
+// CheckRegion checks the region is set and the service endpoints of the
+// region are resolvable. The detail is the region STS endpoint.
+func CheckRegion() DiagnoseCheck {
+	return DiagnoseCheck{Name: "region", Run: func(ctx context.Context,
+		a Aws) (detail string, err error) {
+
+		if a.cfg.Region == "" {
+			err = errors.New("region is not set")
+			return
+		}
+		endpoint, err := sts.NewDefaultEndpointResolverV2().ResolveEndpoint(
+			ctx, sts.EndpointParameters{Region: aws.String(a.cfg.Region)})
+		if err != nil {
+			return
+		}
+		detail = a.cfg.Region + " " + endpoint.URI.String()
+		return
+	}}
+}
+
+// CheckBucketRead checks the bucket exists and its objects can be listed.
+// The detail is the bucket region.
+//
+// Parameters:
+//   - bucket: The bucket name.
+func CheckBucketRead(bucket string) DiagnoseCheck {
+	return DiagnoseCheck{Name: "bucket " + bucket + " readable",
+		Run: func(ctx context.Context, a Aws) (detail string, err error) {
+			out, err := a.S3.Client.HeadBucket(ctx,
+				&s3.HeadBucketInput{Bucket: aws.String(bucket)})
+			if err != nil {
+				return
+			}
+			_, err = a.S3.Client.ListObjectsV2(ctx, &s3.ListObjectsV2Input{
+				Bucket:  aws.String(bucket),
+				MaxKeys: aws.Int32(1),
+			})
+			detail = aws.ToString(out.BucketRegion)
+			return
+		}}
+}
+
+// CheckBucketWrite checks the objects can be written to the bucket and
+// deleted. The probe object is written with the ".aws-diagnose/" key prefix
+// and deleted. The detail is the probe object key.
+//
+// Parameters:
+//   - bucket: The bucket name.
+func CheckBucketWrite(bucket string) DiagnoseCheck {
+	return DiagnoseCheck{Name: "bucket " + bucket + " writable",
+		Run: func(ctx context.Context, a Aws) (detail string, err error) {
+			key := diagnoseWriteKey +
+				strconv.FormatInt(time.Now().UnixNano(), 10)
+			_, err = a.S3.Client.PutObject(ctx, &s3.PutObjectInput{
+				Bucket: aws.String(bucket),
+				Key:    aws.String(key),
+				Body:   strings.NewReader("aws diagnose"),
+			})
+			if err != nil {
+				return
+			}
+			_, err = a.S3.Client.DeleteObject(ctx, &s3.DeleteObjectInput{
+				Bucket: aws.String(bucket),
+				Key:    aws.String(key),
+			})
+			detail = key
+			return
+		}}
+}
+
+// CheckFunction checks the Lambda function exists and the caller may invoke
+// it. The function is not executed, it is invoked with the DryRun
+// invocation type.
+//
+// Parameters:
+//   - funcName: The function name or ARN.
+func CheckFunction(funcName string) DiagnoseCheck {
+	return DiagnoseCheck{Name: "function " + funcName + " invocable",
+		Run: func(ctx context.Context, a Aws) (detail string, err error) {
+			_, err = a.Lambda.Client.Invoke(ctx, &lambda.InvokeInput{
+				FunctionName:   aws.String(funcName),
+				InvocationType: types.InvocationTypeDryRun,
+			})
+			return
+		}}
+}
+
+// CheckUserPool checks the Cognito user pool exists and can be described.
+// The detail is the pool name and the estimated number of users.
+//
+// Parameters:
+//   - userPoolId: The user pool ID.
+func CheckUserPool(userPoolId string) DiagnoseCheck {
+	return DiagnoseCheck{Name: "user pool " + userPoolId + " describable",
+		Run: func(ctx context.Context, a Aws) (detail string, err error) {
+			out, err := a.Cognito.Client.DescribeUserPool(ctx,
+				&cognitoidentityprovider.DescribeUserPoolInput{
+					UserPoolId: aws.String(userPoolId),
+				},
+			)
+			if err != nil || out.UserPool == nil {
+				return
+			}
+			detail = fmt.Sprintf("%s, %d users",
+				aws.ToString(out.UserPool.Name),
+				out.UserPool.EstimatedNumberOfUsers)
+			return
+		}}
+}
@@ -0,0 +1,75 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// TestDiagnose checks Diagnose runs all checks and reports the failed ones
+func TestDiagnose(t *testing.T) {
+
+	client := &pagesHTTPClient{bodies: []string{
+		`<GetCallerIdentityResponse><GetCallerIdentityResult>` +
+			`<Arn>arn:aws:iam::123456789012:user/dev</Arn>` +
+			`<Account>123456789012</Account>` +
+			`</GetCallerIdentityResult></GetCallerIdentityResponse>`,
+		``,
+		`{"__type":"ResourceNotFoundException","message":"no pool"}`,
+	}, statuses: []int{200, http.StatusNoContent, http.StatusBadRequest}}
+	a := newPagesTestAws(client)
+
+	report := a.Diagnose(context.Background(), CheckCredentials(),
+		CheckRegion(), CheckFunction("api"), CheckUserPool("pool"))
+	if len(report.Results) != 4 {
+		t.Fatal("wrong results:", report.Results)
+	}
+	if r := report.Results[0]; r.Err != nil ||
+		r.Detail != "arn:aws:iam::123456789012:user/dev" {
+		t.Error("wrong credentials result:", r)
+	}
+	if r := report.Results[1]; r.Err != nil ||
+		r.Detail != "us-east-1 https://sts.us-east-1.amazonaws.com" {
+		t.Error("wrong region result:", r)
+	}
+	if r := report.Results[2]; r.Err != nil {
+		t.Error("wrong function result:", r)
+	}
+	if !strings.Contains(client.requests[0], "Action=GetCallerIdentity") {
+		t.Error("wrong credentials request:", client.requests[0])
+	}
+	if report.OK() || !errors.Is(report.Err(), ErrNotFound) ||
+		!strings.Contains(report.String(),
+			"FAIL user pool pool describable: ") {
+		t.Error("wrong report:", report.Err(), "\n", report)
+	}
+
+	// Buckets
+	s3 := NewFakeS3("data")
+	a = NewFromConfig(s3.Config())
+	report = a.Diagnose(context.Background(), CheckBucketRead("data"),
+		CheckBucketWrite("data"), CheckBucketRead("none"))
+	if r := report.Results[0]; r.Err != nil {
+		t.Error("wrong bucket read result:", r)
+	}
+	if r := report.Results[1]; r.Err != nil ||
+		!strings.HasPrefix(r.Detail, diagnoseWriteKey) {
+		t.Error("wrong bucket write result:", r)
+	}
+	if keys := s3.Keys("data"); len(keys) != 0 {
+		t.Error("probe object is not deleted:", keys)
+	}
+	if !errors.Is(report.Results[2].Err, ErrNotFound) {
+		t.Error("wrong missing bucket result:", report.Results[2])
+	}
+
+	// Default checks
+	a = NewFromConfig(a.Config())
+	a.cfg.Region = ""
+	report = a.Diagnose(context.Background())
+	if len(report.Results) != 2 || report.Results[1].Err == nil {
+		t.Error("wrong default report:", report)
+	}
+}
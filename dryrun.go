@@ -0,0 +1,208 @@
+package aws
+
+import (
+	"context"
+	"encoding/xml"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// dryRunPrefixes are the name prefixes of the destructive operations skipped
+// in the dry-run mode. The S3 copies overwrite the destination objects.
+var dryRunPrefixes = []string{"Delete", "AdminDelete", "BatchDelete",
+	"Purge", "Terminate", "CopyObject", "UploadPartCopy"}
+
+// dryRunBodies are the successful responses bodies of the skipped operations
+// which results must not be empty.
+var dryRunBodies = map[string]string{
+	"CopyObject":     "<CopyObjectResult></CopyObjectResult>",
+	"UploadPartCopy": "<CopyPartResult></CopyPartResult>",
+}
+
+// DryRunOperation is the destructive operation skipped in the dry-run mode.
+type DryRunOperation struct {
+	// Service is the AWS service ID, for example "S3".
+	Service string
+
+	// Operation is the operation name, for example "DeleteObject".
+	Operation string
+
+	// Resource is the resource name, for example the S3 bucket or the
+	// Cognito user pool ID.
+	Resource string
+
+	// Key is the item of the resource, for example the S3 object key or the
+	// Cognito username.
+	Key string
+
+	// Keys are the S3 object keys of the DeleteObjects operation.
+	Keys []string
+}
+
+// String returns the operation with the resource and keys.
+func (o DryRunOperation) String() string {
+	s := o.Service + " " + o.Operation
+	switch {
+	case o.Resource != "" && o.Key != "":
+		s += " " + o.Resource + "/" + o.Key
+	case o.Resource != "" || o.Key != "":
+		s += " " + o.Resource + o.Key
+	}
+	if len(o.Keys) > 0 {
+		s += " [" + strings.Join(o.Keys, ", ") + "]"
+	}
+	return s
+}
+
+// dryRunContextKey is the context key of the skipped operation response
+// body.
+type dryRunContextKey struct{}
+
+// dryRunModeKey is the context key of the Aws dry-run mode.
+type dryRunModeKey struct{}
+
+// WithDryRun enables the dry-run mode which previews the cleanup jobs: the
+// destructive operations of the package clients, which names start with
+// Delete, AdminDelete, BatchDelete, Purge or Terminate, and the S3
+// CopyObject and UploadPartCopy, are reported and not sent to AWS. They
+// return the successful empty responses, the other operations are executed,
+// so S3 DeleteFolder lists the real objects and reports their deletes, and
+// S3 Copy and Move report the copy by one CopyObject:
+//
+//	preview := aws.NewFromConfig(a.Config(), aws.WithDryRun(
+//		func(op aws.DryRunOperation) { fmt.Println("would run", op) }))
+//	_, err := preview.S3.DeleteFolder("bucket", "tmp")
+//
+// Parameters:
+//   - report: The function called with every skipped operation. Nil logs
+//     the operations with the standard logger.
+func WithDryRun(report func(op DryRunOperation)) Option {
+	if report == nil {
+		report = func(op DryRunOperation) { log.Println("dry run:", op) }
+	}
+	return func(o *options) { o.dryRun = report }
+}
+
+// withDryRunMode returns the context with the dry-run mode if it is enabled.
+func withDryRunMode(ctx context.Context, o options) context.Context {
+	if o.dryRun == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, dryRunModeKey{}, true)
+}
+
+// isDryRun returns true if the context is in the dry-run mode. The package
+// functions use it to skip the waits for the skipped operations results.
+func isDryRun(ctx context.Context) bool {
+	dryRun, _ := ctx.Value(dryRunModeKey{}).(bool)
+	return dryRun
+}
+
+// withDryRun returns the AWS config with the middleware which skips the
+// destructive operations of all clients created from it.
+func withDryRun(cfg aws.Config, o options) aws.Config {
+	if o.dryRun == nil {
+		return cfg
+	}
+	cfg.APIOptions = append(slices.Clip(cfg.APIOptions),
+		func(stack *middleware.Stack) error {
+			err := stack.Initialize.Add(middleware.InitializeMiddlewareFunc(
+				"DryRun",
+				func(ctx context.Context, in middleware.InitializeInput,
+					next middleware.InitializeHandler) (
+					out middleware.InitializeOutput, md middleware.Metadata,
+					err error) {
+
+					op, body, ok := dryRunOperation(ctx, in.Parameters)
+					if ok {
+						o.dryRun(op)
+						ctx = context.WithValue(ctx, dryRunContextKey{}, body)
+					}
+					return next.HandleInitialize(ctx, in)
+				},
+			), middleware.After)
+			if err != nil {
+				return err
+			}
+
+			// Return the response of the skipped operation instead of
+			// sending the request
+			return stack.Deserialize.Add(middleware.DeserializeMiddlewareFunc(
+				"DryRunResponse",
+				func(ctx context.Context, in middleware.DeserializeInput,
+					next middleware.DeserializeHandler) (
+					out middleware.DeserializeOutput, md middleware.Metadata,
+					err error) {
+
+					body, ok := ctx.Value(dryRunContextKey{}).(string)
+					if !ok {
+						return next.HandleDeserialize(ctx, in)
+					}
+					req, _ := in.Request.(*smithyhttp.Request)
+					if body == "" && req != nil && strings.Contains(
+						req.Header.Get("Content-Type"), "json") {
+						body = "{}"
+					}
+					out.RawResponse = &smithyhttp.Response{
+						Response: &http.Response{
+							StatusCode:    http.StatusOK,
+							Header:        http.Header{},
+							Body:          io.NopCloser(strings.NewReader(body)),
+							ContentLength: int64(len(body)),
+						},
+					}
+					return
+				},
+			), middleware.After)
+		},
+	)
+	return cfg
+}
+
+// dryRunOperation returns the destructive operation of the context and the
+// body of its successful response, ok is false for other operations.
+func dryRunOperation(ctx context.Context, params any) (op DryRunOperation,
+	body string, ok bool) {
+
+	op = DryRunOperation{
+		Service:   middleware.GetServiceID(ctx),
+		Operation: middleware.GetOperationName(ctx),
+	}
+	ok = slices.ContainsFunc(dryRunPrefixes, func(prefix string) bool {
+		return strings.HasPrefix(op.Operation, prefix)
+	})
+	if !ok {
+		return
+	}
+	op.Resource = opField(params, opResourceFields)
+	op.Key = opField(params, opKeyFields)
+	body = dryRunBodies[op.Operation]
+
+	// The S3 DeleteObjects response contains the deleted keys
+	input, isDeleteObjects := params.(*s3.DeleteObjectsInput)
+	if !isDeleteObjects || input.Delete == nil {
+		return
+	}
+	type deleted struct{ Key string }
+	var result struct {
+		XMLName xml.Name  `xml:"DeleteResult"`
+		Deleted []deleted `xml:"Deleted"`
+	}
+	for _, obj := range input.Delete.Objects {
+		op.Keys = append(op.Keys, aws.ToString(obj.Key))
+		result.Deleted = append(result.Deleted,
+			deleted{aws.ToString(obj.Key)})
+	}
+	data, _ := xml.Marshal(result)
+	body = string(data)
+
+	return
+}
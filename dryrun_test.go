@@ -0,0 +1,110 @@
+package aws
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// TestDryRun checks the destructive operations are reported and not sent
+func TestDryRun(t *testing.T) {
+
+	client := &pagesHTTPClient{bodies: []string{
+		`<ListBucketResult><Contents><Key>tmp/a</Key></Contents>` +
+			`<Contents><Key>tmp/b</Key></Contents></ListBucketResult>`,
+	}}
+	var ops []string
+	a := NewFromConfig(newPagesTestAws(client).Config(),
+		WithDryRun(func(op DryRunOperation) {
+			ops = append(ops, op.String())
+		}))
+	ctx := context.Background()
+
+	if n, err := a.S3.DeleteFolder("bucket", "tmp"); err != nil || n != 2 {
+		t.Error("delete folder:", n, err)
+	}
+	out, err := a.S3.Client.DeleteObjects(ctx, &s3.DeleteObjectsInput{
+		Bucket: aws.String("bucket"),
+		Delete: &types.Delete{Objects: []types.ObjectIdentifier{
+			{Key: aws.String("k1")}, {Key: aws.String("k2")}}},
+	})
+	if err != nil || len(out.Deleted) != 2 {
+		t.Error("delete objects:", out, err)
+	}
+	_, err = a.Cognito.AdminDeleteUser(ctx,
+		&cognitoidentityprovider.AdminDeleteUserInput{
+			UserPoolId: aws.String("pool"), Username: aws.String("alice")})
+	if err != nil {
+		t.Error("cognito delete user:", err)
+	}
+	if err = a.Dynamo.DeleteTable("table"); err != nil {
+		t.Error("dynamo delete table:", err)
+	}
+	if err = a.SNS.DeleteTopic("arn:aws:sns:us-east-1:1:t"); err != nil {
+		t.Error("sns delete topic:", err)
+	}
+
+	if len(client.requests) != 1 {
+		t.Error("destructive requests sent:", len(client.requests))
+	}
+	want := []string{
+		"S3 DeleteObjects bucket [tmp/a, tmp/b, tmp/, tmp]",
+		"S3 DeleteObjects bucket [k1, k2]",
+		"Cognito Identity Provider AdminDeleteUser pool/alice",
+		"DynamoDB DeleteTable table",
+		"SNS DeleteTopic arn:aws:sns:us-east-1:1:t",
+	}
+	if strings.Join(ops, "\n") != strings.Join(want, "\n") {
+		t.Error("wrong operations:\n" + strings.Join(ops, "\n"))
+	}
+}
+
+// TestDryRunMove checks the S3 Copy and Move in the dry-run mode are
+// reported and send the read requests only
+func TestDryRunMove(t *testing.T) {
+
+	fake := NewFakeS3("bucket")
+	if err := NewFromConfig(fake.Config()).S3.Set("bucket", "old",
+		[]byte("data")); err != nil {
+		t.Fatal("set:", err)
+	}
+	var methods []string
+	cfg := fake.Config()
+	cfg.HTTPClient = smithyhttp.ClientDoFunc(
+		func(r *http.Request) (*http.Response, error) {
+			methods = append(methods, r.Method)
+			return fake.Do(r)
+		})
+	var ops []string
+	a := NewFromConfig(cfg, WithDryRun(func(op DryRunOperation) {
+		ops = append(ops, op.String())
+	}))
+
+	if err := a.S3.Move("bucket", "old", "new"); err != nil {
+		t.Error("move:", err)
+	}
+	err := a.S3.Copy("bucket", "old", "bucket", "large",
+		S3CopyOptions{MultipartThreshold: 1, PartSize: 1})
+	if err != nil {
+		t.Error("multipart copy:", err)
+	}
+
+	if m := strings.Join(methods, ","); m != "HEAD,HEAD" {
+		t.Error("write requests sent:", m)
+	}
+	if keys := fake.Keys("bucket"); strings.Join(keys, ",") != "old" {
+		t.Error("objects changed:", keys)
+	}
+	want := "S3 CopyObject bucket/new\nS3 DeleteObject bucket/old\n" +
+		"S3 CopyObject bucket/large"
+	if strings.Join(ops, "\n") != want {
+		t.Error("wrong operations:\n" + strings.Join(ops, "\n"))
+	}
+}
@@ -0,0 +1,542 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// Package sentinel errors. The service errors returned by the package wrap
+// one of these errors, so they can be checked with errors.Is independently of
+// the service which returned them:
+//
+//	if errors.Is(err, aws.ErrNotFound) { ... }
+//
+// The original service error is kept in the errors chain and is available
+// with errors.As or Aws.AwsError.
+var (
+	// ErrNotFound means the requested resource does not exist.
+	ErrNotFound = errors.New("not found")
+
+	// ErrAccessDenied means the caller is not allowed to execute the request
+	// or its credentials are invalid or expired.
+	ErrAccessDenied = errors.New("access denied")
+
+	// ErrThrottled means the request was rejected by the service rate limits.
+	ErrThrottled = errors.New("throttled")
+
+	// ErrPreconditionFailed means the request condition is not met.
+	ErrPreconditionFailed = errors.New("precondition failed")
+
+	// ErrConflict means the request conflicts with the current state of the
+	// resource, for example the resource already exists.
+	ErrConflict = errors.New("conflict")
+)
+
+// errorCodes maps AWS service error codes to the package sentinel errors.
+var errorCodes = map[string]error{
+
+	// Not found
+	"NoSuchKey":                 ErrNotFound,
+	"NoSuchBucket":              ErrNotFound,
+	"NoSuchUpload":              ErrNotFound,
+	"NoSuchEntity":              ErrNotFound,
+	"NoSuchHostedZone":          ErrNotFound,
+	"NoSuchChange":              ErrNotFound,
+	"NoSuchJob":                 ErrNotFound,
+	"ClusterNotFoundException":  ErrNotFound,
+	"NotFound":                  ErrNotFound,
+	"ResourceNotFoundException": ErrNotFound,
+	"UserNotFoundException":     ErrNotFound,
+	"GroupNotFoundException":    ErrNotFound,
+	"QueueDoesNotExist":         ErrNotFound,
+	"NotFoundException":         ErrNotFound,
+	"ParameterNotFound":         ErrNotFound,
+	"ParameterVersionNotFound":  ErrNotFound,
+	"ExecutionDoesNotExist":     ErrNotFound,
+	"StateMachineDoesNotExist":  ErrNotFound,
+	"ActivityDoesNotExist":      ErrNotFound,
+
+	"AWS.SimpleQueueService.NonExistentQueue": ErrNotFound,
+
+	// EC2, ECR, Rekognition and Polly not found
+	"InvalidInstanceID.NotFound":     ErrNotFound,
+	"ImageNotFound":                  ErrNotFound,
+	"ImageNotFoundException":         ErrNotFound,
+	"RepositoryNotFoundException":    ErrNotFound,
+	"InvalidS3ObjectException":       ErrNotFound,
+	"SynthesisTaskNotFoundException": ErrNotFound,
+
+	// Access denied
+	"AccessDenied":                ErrAccessDenied,
+	"AccessDeniedException":       ErrAccessDenied,
+	"NotAuthorizedException":      ErrAccessDenied,
+	"ExpiredToken":                ErrAccessDenied,
+	"ExpiredTokenException":       ErrAccessDenied,
+	"InvalidAccessKeyId":          ErrAccessDenied,
+	"SignatureDoesNotMatch":       ErrAccessDenied,
+	"UnrecognizedClientException": ErrAccessDenied,
+	"AuthorizationError":          ErrAccessDenied,
+
+	// Throttled
+	"Throttling":                             ErrThrottled,
+	"ThrottlingException":                    ErrThrottled,
+	"ThrottledException":                     ErrThrottled,
+	"TooManyRequestsException":               ErrThrottled,
+	"TooManyFailedAttemptsException":         ErrThrottled,
+	"LimitExceededException":                 ErrThrottled,
+	"RequestLimitExceeded":                   ErrThrottled,
+	"SlowDown":                               ErrThrottled,
+	"ProvisionedThroughputExceededException": ErrThrottled,
+	"PriorRequestNotComplete":                ErrThrottled,
+	"ModelNotReadyException":                 ErrThrottled,
+
+	// Precondition failed
+	"PreconditionFailed":              ErrPreconditionFailed,
+	"ConditionalCheckFailedException": ErrPreconditionFailed,
+
+	// Conflict
+	"ConflictException":            ErrConflict,
+	"TransactionConflictException": ErrConflict,
+	"ResourceConflictException":    ErrConflict,
+	"UsernameExistsException":      ErrConflict,
+	"AliasExistsException":         ErrConflict,
+	"AlreadyExistsException":       ErrConflict,
+	"ResourceExistsException":      ErrConflict,
+	"ParameterAlreadyExists":       ErrConflict,
+	"ExecutionAlreadyExists":       ErrConflict,
+	"BucketAlreadyExists":          ErrConflict,
+	"BucketAlreadyOwnedByYou":      ErrConflict,
+	"OperationAborted":             ErrConflict,
+	"IncorrectInstanceState":       ErrConflict,
+}
+
+// errorStatuses maps HTTP status codes of the errors with unknown error code
+// to the package sentinel errors.
+var errorStatuses = map[int]error{
+	http.StatusNotFound:           ErrNotFound,
+	http.StatusForbidden:          ErrAccessDenied,
+	http.StatusTooManyRequests:    ErrThrottled,
+	http.StatusPreconditionFailed: ErrPreconditionFailed,
+	http.StatusConflict:           ErrConflict,
+}
+
+// Error is the AWS service error returned (wrapped) by all package functions.
+// It replaces the AWS SDK error types, use errors.As to get it:
+//
+//	var awsErr *aws.Error
+//	if errors.As(err, &awsErr) {
+//		log.Println(awsErr.Code(), awsErr.RequestID(), awsErr.HTTPStatus())
+//	}
+//
+// The Error wraps the package sentinel error of the service error, if any, the
+// caller error registered with WithErrorMapping, if any, and the original AWS
+// SDK error.
+type Error struct {
+	code      string
+	message   string
+	requestID string
+	hostID    string
+	status    int
+
+	sentinel error
+	mapped   error
+	err      error
+}
+
+// Code returns the service error code, for example "NoSuchKey".
+func (e *Error) Code() string { return e.code }
+
+// Message returns the service error message.
+func (e *Error) Message() string { return e.message }
+
+// RequestID returns the service request ID.
+func (e *Error) RequestID() string { return e.requestID }
+
+// HostID returns the S3 host ID. It is empty for other services.
+func (e *Error) HostID() string { return e.hostID }
+
+// HTTPStatus returns the HTTP status code of the service response or zero if
+// there was no response.
+func (e *Error) HTTPStatus() int { return e.status }
+
+// Error returns the original service error message.
+func (e *Error) Error() string { return e.err.Error() }
+
+// Unwrap returns the sentinel, the caller mapped and the original service
+// errors.
+func (e *Error) Unwrap() (errs []error) {
+	for _, err := range []error{e.sentinel, e.mapped} {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return append(errs, e.err)
+}
+
+// translateError wraps the service error with the Error. The err is returned
+// unchanged if it is not a service error or is already translated.
+func translateError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	// Skip already translated errors
+	var e *Error
+	if errors.As(err, &e) {
+		return err
+	}
+	e = &Error{err: err}
+
+	// Get service error code and message
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		e.code, e.message = apiErr.ErrorCode(), apiErr.ErrorMessage()
+	}
+
+	// Get response details
+	var statusErr interface{ HTTPStatusCode() int }
+	if errors.As(err, &statusErr) {
+		e.status = statusErr.HTTPStatusCode()
+	}
+	var requestErr interface{ ServiceRequestID() string }
+	if errors.As(err, &requestErr) {
+		e.requestID = requestErr.ServiceRequestID()
+	}
+	var hostErr interface{ ServiceHostID() string }
+	if errors.As(err, &hostErr) {
+		e.hostID = hostErr.ServiceHostID()
+	}
+
+	// Skip not service errors
+	if e.code == "" && e.status == 0 {
+		return err
+	}
+
+	// Find sentinel by error code or by HTTP status code
+	var ok bool
+	if e.sentinel, ok = errorCodes[e.code]; !ok {
+		e.sentinel = errorStatuses[e.status]
+	}
+
+	return e
+}
+
+// entryError returns the Error of the batch entry which failed in the
+// successful batch request, for example the SQS SendMessageBatch entry.
+func entryError(code, message string) *Error {
+	e := &Error{code: code, message: message, sentinel: errorCodes[code]}
+	e.err = fmt.Errorf("%s: %s", code, message)
+	return e
+}
+
+// mapError adds the caller error registered for the service error code to
+// the translated service error.
+func mapError(err error, mapping map[string]error) error {
+	var e *Error
+	if len(mapping) == 0 || !errors.As(err, &e) {
+		return err
+	}
+	if mapped, ok := mapping[e.code]; ok {
+		e.mapped = mapped
+	}
+	return err
+}
+
+// withErrorTranslation returns the AWS config with the middleware which
+// translates the service errors of all clients created from it, wraps them
+// with the OpError and calls the error hook.
+func withErrorTranslation(cfg aws.Config, o options) aws.Config {
+	cfg.APIOptions = append(slices.Clip(cfg.APIOptions),
+		func(stack *middleware.Stack) error {
+			return stack.Initialize.Add(middleware.InitializeMiddlewareFunc(
+				"TranslateError",
+				func(ctx context.Context, in middleware.InitializeInput,
+					next middleware.InitializeHandler) (
+					out middleware.InitializeOutput, md middleware.Metadata,
+					err error) {
+
+					out, md, err = next.HandleInitialize(ctx, in)
+					if err != nil {
+						err = newOpError(ctx, in.Parameters,
+							mapError(translateError(err), o.errorMapping))
+						if o.errorHook != nil {
+							o.errorHook(middleware.GetServiceID(ctx),
+								middleware.GetOperationName(ctx), err)
+						}
+					}
+					return
+				},
+			), middleware.Before)
+		},
+	)
+	return cfg
+}
+
+// IsNotFound returns true if err means the requested resource does not exist,
+// for example S3 NoSuchKey, HeadObject 404 NotFound or Cognito user not found
+// errors.
+func IsNotFound(err error) bool {
+	return errors.Is(translateError(err), ErrNotFound)
+}
+
+// IsThrottled returns true if err means the request was rejected by the
+// service rate limits, for example Cognito TooManyRequestsException or S3
+// SlowDown errors.
+func IsThrottled(err error) bool {
+	return errors.Is(translateError(err), ErrThrottled)
+}
+
+// IsAccessDenied returns true if err means the caller is not allowed to
+// execute the request, for example AccessDenied or ExpiredToken errors.
+func IsAccessDenied(err error) bool {
+	return errors.Is(translateError(err), ErrAccessDenied)
+}
+
+// OpError is the error with the context of the failed operation. All service
+// errors returned by the package are wrapped with OpError:
+//
+//	var opErr *aws.OpError
+//	if errors.As(err, &opErr) {
+//		log.Println(opErr.Service, opErr.Operation, opErr.Resource, opErr.Key)
+//	}
+type OpError struct {
+	// Service is the AWS service ID, for example "S3" or "Lambda".
+	Service string
+
+	// Operation is the name of the failed operation, for example "GetObject".
+	Operation string
+
+	// Resource is the name of the resource of the operation, for example S3
+	// bucket, Cognito user pool ID or Lambda function name.
+	Resource string
+
+	// Key is the item of the resource, for example S3 object key, Cognito
+	// username or user sub.
+	Key string
+
+	// Err is the operation error.
+	Err error
+
+	// inner is true if the error is wrapped with smithy.OperationError which
+	// already contains the service and the operation names
+	inner bool
+}
+
+// opResourceFields are the names of the operation input fields which contain
+// the resource name, in priority order.
+var opResourceFields = []string{"Bucket", "UserPoolId", "FunctionName",
+	"IdentityPoolId", "TableName", "StreamArn",
+	"QueueUrl", "QueueName", "TopicArn", "SubscriptionArn", "PhoneNumber",
+	"TemplateName", "SecretId", "KeyId", "LogGroupName", "Namespace",
+	"StreamName", "StreamARN", "DeliveryStreamName", "StateMachineArn",
+	"ExecutionArn", "ActivityArn", "RoleArn", "RoleName",
+	"PolicySourceArn", "RepositoryName", "HostedZoneId", "ConnectionId",
+	"Cluster", "ModelId", "Name", "Path"}
+
+// opKeyFields are the names of the operation input fields which contain the
+// item of the resource, in priority order.
+var opKeyFields = []string{"Key", "Prefix", "Username", "IdentityId",
+	"ShardId", "ReceiptHandle", "LogStreamName"}
+
+// Error returns the error message with the operation context.
+func (e *OpError) Error() string {
+	msg := e.Err.Error()
+	if r := e.resource(); r != "" {
+		msg = r + ": " + msg
+	}
+	if e.inner {
+		return msg
+	}
+	return fmt.Sprintf("operation error %s: %s, %s", e.Service, e.Operation, msg)
+}
+
+// Unwrap returns the operation error.
+func (e *OpError) Unwrap() error { return e.Err }
+
+// resource returns the resource and the key joined with slash.
+func (e *OpError) resource() string {
+	switch {
+	case e.Key == "":
+		return e.Resource
+	case e.Resource == "":
+		return e.Key
+	}
+	return e.Resource + "/" + e.Key
+}
+
+// newOpError creates OpError from the operation context and the operation
+// input parameters.
+func newOpError(ctx context.Context, params any, err error) error {
+	return &OpError{
+		Service:   middleware.GetServiceID(ctx),
+		Operation: middleware.GetOperationName(ctx),
+		Resource:  opField(params, opResourceFields),
+		Key:       opField(params, opKeyFields),
+		Err:       err,
+		inner:     true,
+	}
+}
+
+// opField returns the value of the first not empty string field of the
+// operation input parameters from the names list.
+func opField(params any, names []string) string {
+	v := reflect.ValueOf(params)
+	if v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return ""
+	}
+
+	for _, name := range names {
+		f := v.FieldByName(name)
+		if f.Kind() == reflect.Pointer && !f.IsNil() {
+			f = f.Elem()
+		}
+		if f.Kind() == reflect.String && f.String() != "" {
+			return f.String()
+		}
+	}
+	return ""
+}
+
+// throttleRetryDelay is the retry delay returned by RetryAfter for throttling
+// errors without the Retry-After header.
+const throttleRetryDelay = time.Second
+
+// IsRetryable returns true if the failed request may succeed when retried,
+// for example throttling, timeout, connection or transient 5xx errors. The
+// requests failed with the not found, access denied or canceled context
+// errors are not retryable.
+//
+// The package clients already retry requests with the AWS SDK retryer, use
+// IsRetryable in the own retry loops on top of the package functions.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	// Use AWS SDK errors classification
+	switch retry.IsErrorRetryables(retry.DefaultRetryables).IsErrorRetryable(err) {
+	case aws.TrueTernary:
+		return true
+	case aws.FalseTernary:
+		return false
+	}
+
+	// Use package errors classification. The Cognito brute force protection
+	// error is classified as throttling but should not be retried
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) &&
+		apiErr.ErrorCode() == "TooManyFailedAttemptsException" {
+		return false
+	}
+	return IsThrottled(err)
+}
+
+// RetryAfter returns the delay before the retry of the failed request. The
+// delay is taken from the Retry-After response header if it exists. The
+// header HTTP date is counted from the Clock of the request context, so the
+// Aws created with WithClock uses its clock. The throttling errors without
+// the header return the default delay of one second. Zero is returned for
+// other errors.
+func RetryAfter(err error) time.Duration {
+	if err == nil {
+		return 0
+	}
+
+	// Get delay from Retry-After header, seconds or HTTP date
+	var respErr interface{ HTTPResponse() *smithyhttp.Response }
+	if errors.As(err, &respErr) && respErr.HTTPResponse() != nil &&
+		respErr.HTTPResponse().Response != nil {
+
+		resp := respErr.HTTPResponse()
+		header := resp.Header.Get("Retry-After")
+		if seconds, e := strconv.Atoi(header); e == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second
+		}
+		if date, e := http.ParseTime(header); e == nil {
+			clk := SystemClock
+			if resp.Request != nil {
+				clk = clock(resp.Request.Context())
+			}
+			return max(date.Sub(clk.Now()), 0)
+		}
+	}
+
+	if IsThrottled(err) {
+		return throttleRetryDelay
+	}
+	return 0
+}
+
+// BatchError is the error of the batch operation which failed for some of its
+// items. It contains the errors of all failed items, use errors.Is and
+// errors.As to check them.
+type BatchError struct {
+	// Total is the number of items in the batch.
+	Total int
+
+	// Failed contains the errors of the failed items.
+	Failed []*BatchItemError
+}
+
+// BatchItemError is the error of one item of the batch operation.
+type BatchItemError struct {
+	// Item is the name of the failed item, for example S3 object key.
+	Item string
+
+	// Err is the item error.
+	Err error
+}
+
+// Error returns the item error message with the item name.
+func (e *BatchItemError) Error() string { return e.Item + ": " + e.Err.Error() }
+
+// Unwrap returns the item error.
+func (e *BatchItemError) Unwrap() error { return e.Err }
+
+// Error returns the number of failed items and the first item error message.
+func (e *BatchError) Error() string {
+	if len(e.Failed) == 0 {
+		return fmt.Sprintf("0 of %d items failed", e.Total)
+	}
+	return fmt.Sprintf("%d of %d items failed, first error: %s",
+		len(e.Failed), e.Total, e.Failed[0])
+}
+
+// Unwrap returns the errors of the failed items.
+func (e *BatchError) Unwrap() (errs []error) {
+	for _, item := range e.Failed {
+		errs = append(errs, item)
+	}
+	return
+}
+
+// add adds the item result to the batch error.
+func (e *BatchError) add(item string, err error) {
+	e.Total++
+	if err != nil {
+		e.Failed = append(e.Failed, &BatchItemError{item, err})
+	}
+}
+
+// err returns the batch error if any of the items failed or nil.
+func (e *BatchError) err() error {
+	if len(e.Failed) == 0 {
+		return nil
+	}
+	return e
+}
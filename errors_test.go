@@ -0,0 +1,255 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// errorHTTPClient returns the HTTP response with the status and the body for
+// all requests.
+type errorHTTPClient struct {
+	status int
+	body   string
+}
+
+func (c errorHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: c.status,
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader(c.body)),
+		Request:    req,
+	}, nil
+}
+
+// newErrorTestAws creates Aws which clients receive the HTTP error response
+// for all requests.
+func newErrorTestAws(status int, body string, opts ...Option) *Aws {
+	return NewFromConfig(aws.Config{
+		Region:     "us-east-1",
+		HTTPClient: errorHTTPClient{status, body},
+		Credentials: aws.CredentialsProviderFunc(
+			func(context.Context) (aws.Credentials, error) {
+				return aws.Credentials{AccessKeyID: "id", SecretAccessKey: "key"},
+					nil
+			},
+		),
+	}, opts...)
+}
+
+// TestOpError checks the operation context of the service errors
+func TestOpError(t *testing.T) {
+
+	a := newErrorTestAws(http.StatusForbidden,
+		"<Error><Code>AccessDenied</Code><Message>Access Denied</Message></Error>")
+
+	_, err := a.S3.Get("bucket", "folder/key")
+	if err == nil {
+		t.Fatal("get does not return error")
+	}
+	t.Log(err)
+
+	var opErr *OpError
+	if !errors.As(err, &opErr) {
+		t.Fatal("error is not OpError")
+	}
+	if opErr.Service != "S3" || opErr.Operation != "GetObject" ||
+		opErr.Resource != "bucket" || opErr.Key != "folder/key" {
+		t.Errorf("wrong operation context: %+v", opErr)
+	}
+	if !strings.Contains(err.Error(), "bucket/folder/key") {
+		t.Error("error message does not contain bucket and key")
+	}
+	if !IsAccessDenied(err) {
+		t.Error("error is not access denied")
+	}
+
+	var awsErr *Error
+	if !errors.As(err, &awsErr) {
+		t.Fatal("error is not Error")
+	}
+	if awsErr.Code() != "AccessDenied" || awsErr.Message() != "Access Denied" ||
+		awsErr.HTTPStatus() != http.StatusForbidden {
+		t.Errorf("wrong error: %s %s %d", awsErr.Code(), awsErr.Message(),
+			awsErr.HTTPStatus())
+	}
+
+	// Errors created by package contain operation context
+	err = &OpError{Service: "Cognito Identity Provider", Operation: "Get",
+		Resource: "pool", Key: "sub", Err: ErrCognitoUserNotFound}
+	if !errors.Is(err, ErrCognitoUserNotFound) || !IsNotFound(err) {
+		t.Error("OpError does not unwrap error")
+	}
+	want := "operation error Cognito Identity Provider: Get, pool/sub: user not found"
+	if err.Error() != want {
+		t.Errorf("wrong error message %q, want %q", err.Error(), want)
+	}
+}
+
+// TestRetryable checks the retryability classification of errors
+func TestRetryable(t *testing.T) {
+
+	apiErr := func(code string) error {
+		return &smithy.GenericAPIError{Code: code}
+	}
+	respErr := func(status int, retryAfter string) error {
+		resp := &http.Response{StatusCode: status, Header: http.Header{}}
+		if retryAfter != "" {
+			resp.Header.Set("Retry-After", retryAfter)
+		}
+		return &smithyhttp.ResponseError{
+			Response: &smithyhttp.Response{Response: resp},
+			Err:      errors.New("response error"),
+		}
+	}
+
+	tests := []struct {
+		name       string
+		err        error
+		retryable  bool
+		retryAfter time.Duration
+	}{
+		{"nil", nil, false, 0},
+		{"SlowDown", apiErr("SlowDown"), true, throttleRetryDelay},
+		{"TooManyRequestsException", apiErr("TooManyRequestsException"), true,
+			throttleRetryDelay},
+		{"TooManyFailedAttemptsException",
+			apiErr("TooManyFailedAttemptsException"), false, throttleRetryDelay},
+		{"NoSuchKey", apiErr("NoSuchKey"), false, 0},
+		{"AccessDenied", apiErr("AccessDenied"), false, 0},
+		{"canceled", context.Canceled, false, 0},
+		{"503 Retry-After", respErr(503, "7"), true, 7 * time.Second},
+		{"429 Retry-After", respErr(429, "2"), true, 2 * time.Second},
+		{"500", respErr(500, ""), true, 0},
+	}
+	for _, test := range tests {
+		if got := IsRetryable(test.err); got != test.retryable {
+			t.Errorf("%s: IsRetryable %v, want %v", test.name, got,
+				test.retryable)
+		}
+		if got := RetryAfter(test.err); got != test.retryAfter {
+			t.Errorf("%s: RetryAfter %v, want %v", test.name, got,
+				test.retryAfter)
+		}
+	}
+}
+
+// TestRetryAfterDate checks the Retry-After HTTP date delay is counted from
+// the Aws Clock
+func TestRetryAfterDate(t *testing.T) {
+
+	clk := NewFakeClock(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC))
+	retryAt := clk.Now().Add(30 * time.Second).Format(http.TimeFormat)
+	cfg := newErrorTestAws(http.StatusOK, "").Config()
+	cfg.HTTPClient = smithyhttp.ClientDoFunc(
+		func(r *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusBadRequest,
+				Header:     http.Header{"Retry-After": {retryAt}},
+				Body: io.NopCloser(strings.NewReader(
+					`{"__type":"ParameterNotFound","message":""}`)),
+				Request: r,
+			}, nil
+		})
+	a := NewFromConfig(cfg, WithClock(clk))
+
+	_, err := a.SSM.GetParameter("/app/key")
+	if err == nil {
+		t.Fatal("get parameter does not return error")
+	}
+	if d := RetryAfter(err); d != 30*time.Second {
+		t.Error("wrong retry after date delay:", d)
+	}
+	clk.Advance(40 * time.Second)
+	if d := RetryAfter(err); d != 0 {
+		t.Error("wrong passed retry after date delay:", d)
+	}
+}
+
+// TestErrorHook checks the error hook is called for failed AWS calls
+func TestErrorHook(t *testing.T) {
+
+	var calls int
+	var service, op string
+	a := newErrorTestAws(http.StatusNotFound,
+		"<Error><Code>NoSuchKey</Code></Error>",
+		WithErrorHook(func(s, o string, err error) {
+			calls++
+			service, op = s, o
+			if !IsNotFound(err) {
+				t.Error("wrong hook error:", err)
+			}
+		}),
+	)
+
+	a.S3.Get("bucket", "key")
+	if calls != 1 || service != "S3" || op != "GetObject" {
+		t.Errorf("wrong hook calls %d: %s %s", calls, service, op)
+	}
+
+	// The Aws created from identity credentials uses the same options
+	if len(a.CognitoIdentity.opts) != 1 {
+		t.Error("options are not passed to Cognito Identity")
+	}
+}
+
+// TestErrorMapping checks the caller errors mapping
+func TestErrorMapping(t *testing.T) {
+
+	errMissing := errors.New("missing")
+	a := newErrorTestAws(http.StatusNotFound,
+		"<Error><Code>NoSuchKey</Code></Error>",
+		WithErrorMapping(map[string]error{"NoSuchKey": errMissing}),
+		WithErrorMapping(map[string]error{"AccessDenied": ErrConflict}),
+	)
+
+	_, err := a.S3.Get("bucket", "key")
+	if !errors.Is(err, errMissing) {
+		t.Error("error is not mapped:", err)
+	}
+	if !IsNotFound(err) {
+		t.Error("mapped error is not not found")
+	}
+	if errors.Is(err, ErrConflict) {
+		t.Error("error is mapped with wrong code")
+	}
+}
+
+// TestBatchError checks the batch error of failed items
+func TestBatchError(t *testing.T) {
+
+	var batchErr BatchError
+	batchErr.add("a", nil)
+	if batchErr.err() != nil {
+		t.Fatal("batch without failed items returns error")
+	}
+	batchErr.add("b", ErrAccessDenied)
+	batchErr.add("c", &OpError{Service: "S3", Operation: "DeleteObject",
+		Resource: "bucket", Key: "c", Err: ErrThrottled})
+
+	err := batchErr.err()
+	if err == nil {
+		t.Fatal("batch with failed items does not return error")
+	}
+	t.Log(err)
+	if !errors.Is(err, ErrAccessDenied) || !IsThrottled(err) {
+		t.Error("batch error does not unwrap item errors")
+	}
+	var e *BatchError
+	if !errors.As(err, &e) || e.Total != 3 || len(e.Failed) != 2 ||
+		e.Failed[0].Item != "b" {
+		t.Errorf("wrong batch error: %+v", e)
+	}
+	var opErr *OpError
+	if !errors.As(err, &opErr) || opErr.Key != "c" {
+		t.Error("batch error does not unwrap OpError")
+	}
+}
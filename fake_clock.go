@@ -0,0 +1,144 @@
+package aws
+
+import (
+	"sync"
+	"time"
+)
+
+// FakeClock is the manually advanced Clock for tests. The time is changed
+// only by Advance and Set, the After channels and tickers receive the time
+// when it reaches their deadlines:
+//
+//	clk := aws.NewFakeClock(time.Now())
+//	a := aws.NewFromConfig(cfg, aws.WithClock(clk))
+//	go a.Polly.WaitForTask(ctx, taskID)
+//	clk.BlockUntil(1)
+//	clk.Advance(2 * time.Second)
+type FakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []fakeClockWaiter
+	tickers []*fakeClockTicker
+}
+
+// fakeClockWaiter is the pending After channel.
+type fakeClockWaiter struct {
+	deadline time.Time
+	ch       chan time.Time
+}
+
+// fakeClockTicker is the FakeClock Ticker.
+type fakeClockTicker struct {
+	clock  *FakeClock
+	period time.Duration
+	next   time.Time
+	ch     chan time.Time
+}
+
+// NewFakeClock creates the FakeClock with the current time now.
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+// Now returns the current time of the clock.
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// After returns the channel which receives the time when the clock is
+// advanced by the duration d. The not positive d fires immediately.
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- c.now
+		return ch
+	}
+	c.waiters = append(c.waiters, fakeClockWaiter{c.now.Add(d), ch})
+	return ch
+}
+
+// NewTicker returns the Ticker which channel receives the time every period
+// d of the clock advance. The ticks are dropped for the slow receiver as by
+// the time.Ticker.
+func (c *FakeClock) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("non-positive interval for FakeClock.NewTicker")
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &fakeClockTicker{clock: c, period: d, next: c.now.Add(d),
+		ch: make(chan time.Time, 1)}
+	c.tickers = append(c.tickers, t)
+	return t
+}
+
+// C returns the ticks channel.
+func (t *fakeClockTicker) C() <-chan time.Time { return t.ch }
+
+// Stop removes the ticker from the clock.
+func (t *fakeClockTicker) Stop() {
+	c := t.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i := range c.tickers {
+		if c.tickers[i] == t {
+			c.tickers = append(c.tickers[:i], c.tickers[i+1:]...)
+			break
+		}
+	}
+}
+
+// Advance advances the clock by the duration d.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.Set(c.Now().Add(d))
+}
+
+// Set sets the current time of the clock and fires the After channels and
+// tickers which deadlines are reached.
+func (c *FakeClock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = now
+	waiters := c.waiters[:0]
+	for _, w := range c.waiters {
+		if now.Before(w.deadline) {
+			waiters = append(waiters, w)
+			continue
+		}
+		w.ch <- now
+	}
+	c.waiters = waiters
+	for _, t := range c.tickers {
+		if now.Before(t.next) {
+			continue
+		}
+		select {
+		case t.ch <- now:
+		default:
+		}
+		for !now.Before(t.next) {
+			t.next = t.next.Add(t.period)
+		}
+	}
+}
+
+// Waiters returns the number of the pending After channels and the running
+// tickers.
+func (c *FakeClock) Waiters() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.waiters) + len(c.tickers)
+}
+
+// BlockUntil waits until the clock has at least n pending After channels and
+// running tickers, use it to advance the clock after the tested code started
+// to wait.
+func (c *FakeClock) BlockUntil(n int) {
+	for c.Waiters() < n {
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,539 @@
+package aws
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+)
+
+const (
+	// fakeCognitoMaxLimit is the default and maximum number of users and
+	// groups in the list response.
+	fakeCognitoMaxLimit = 60
+
+	// fakeCognitoTarget is the X-Amz-Target prefix of the Cognito user pools
+	// requests.
+	fakeCognitoTarget = "AWSCognitoIdentityProviderService."
+)
+
+// fakeCognitoFilterAttributes are the attributes supported by the ListUsers
+// filter.
+var fakeCognitoFilterAttributes = []string{"username", "email",
+	"phone_number", "name", "given_name", "family_name",
+	"preferred_username", "cognito:user_status", "status", "sub"}
+
+// FakeCognito is the in-memory Cognito user pools backend for tests. It
+// implements the AWS SDK HTTP client and serves the Cognito user pools
+// requests of the clients created from its Config, so the Aws Cognito
+// functions and the Cognito Cache work offline and deterministically:
+//
+//	fake := aws.NewFakeCognito("pool")
+//	fake.AddUser("pool", "alice", map[string]string{"email": "a@b.c"})
+//	a := aws.NewFromConfig(fake.Config())
+//	user, err := a.Cognito.Get("pool", sub)
+//
+// The users are created with the attributes and the generated sub, the
+// ListUsers filter, limit and pagination, groups and the users of groups
+// are supported. The missing user pools, users and groups return the
+// ResourceNotFoundException and UserNotFoundException errors.
+type FakeCognito struct {
+	mu    sync.Mutex
+	pools map[string]*fakeCognitoPool
+	subs  int
+}
+
+// fakeCognitoPool is the FakeCognito user pool.
+type fakeCognitoPool struct {
+	users  map[string]*fakeCognitoUser
+	groups map[string]*fakeCognitoGroup
+}
+
+// fakeCognitoUser is the FakeCognito user.
+type fakeCognitoUser struct {
+	username   string
+	attributes map[string]string
+	status     string
+	enabled    bool
+	created    time.Time
+	modified   time.Time
+}
+
+// fakeCognitoGroup is the FakeCognito group.
+type fakeCognitoGroup struct {
+	description string
+	precedence  *int32
+	users       map[string]bool
+}
+
+// NewFakeCognito creates the FakeCognito with the empty user pools.
+func NewFakeCognito(userPoolIds ...string) (f *FakeCognito) {
+	f = &FakeCognito{pools: make(map[string]*fakeCognitoPool)}
+	for _, id := range userPoolIds {
+		f.CreateUserPool(id)
+	}
+	return
+}
+
+// CreateUserPool creates the empty user pool if it does not exist.
+func (f *FakeCognito) CreateUserPool(userPoolId string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.pools[userPoolId] == nil {
+		f.pools[userPoolId] = &fakeCognitoPool{
+			users:  make(map[string]*fakeCognitoUser),
+			groups: make(map[string]*fakeCognitoGroup),
+		}
+	}
+}
+
+// AddUser adds the confirmed and enabled user to the user pool, the pool is
+// created if it does not exist. The existing user attributes are replaced.
+//
+// Parameters:
+//   - userPoolId: The user pool ID.
+//   - username: The user name.
+//   - attributes: The user attributes, the sub is generated if missing.
+//   - groups: The names of the groups the user is added to, the groups are
+//     created if they do not exist.
+//
+// Returns:
+//   - sub: The user sub.
+func (f *FakeCognito) AddUser(userPoolId, username string,
+	attributes map[string]string, groups ...string) (sub string) {
+
+	f.CreateUserPool(userPoolId)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	pool := f.pools[userPoolId]
+	user := f.newUser(username, attributes)
+	user.status = "CONFIRMED"
+	pool.users[username] = user
+	for _, name := range groups {
+		if pool.groups[name] == nil {
+			pool.groups[name] = &fakeCognitoGroup{
+				users: make(map[string]bool)}
+		}
+		pool.groups[name].users[username] = true
+	}
+	return user.attributes["sub"]
+}
+
+// newUser creates the user with the generated sub.
+func (f *FakeCognito) newUser(username string,
+	attributes map[string]string) *fakeCognitoUser {
+
+	now := time.Now().UTC().Truncate(time.Second)
+	user := &fakeCognitoUser{
+		username:   username,
+		attributes: maps.Clone(attributes),
+		status:     "FORCE_CHANGE_PASSWORD",
+		enabled:    true,
+		created:    now,
+		modified:   now,
+	}
+	if user.attributes == nil {
+		user.attributes = make(map[string]string)
+	}
+	if user.attributes["sub"] == "" {
+		f.subs++
+		user.attributes["sub"] = fmt.Sprintf(
+			"00000000-0000-4000-8000-%012d", f.subs)
+	}
+	return user
+}
+
+// Config returns the AWS config with the static test credentials which
+// clients send requests to the FakeCognito.
+func (f *FakeCognito) Config() aws.Config {
+	return aws.Config{
+		Region:      "us-east-1",
+		HTTPClient:  f,
+		Credentials: credentials.NewStaticCredentialsProvider("id", "key", ""),
+	}
+}
+
+// Do serves the Cognito user pools request, it implements the AWS SDK HTTP
+// client. The requests of other services return the
+// UnknownOperationException.
+func (f *FakeCognito) Do(r *http.Request) (*http.Response, error) {
+	status, body := f.serve(r)
+	return &http.Response{
+		StatusCode: status,
+		Status:     http.StatusText(status),
+		Header: http.Header{
+			"Content-Type":     {"application/x-amz-json-1.1"},
+			"X-Amzn-Requestid": {"fake"},
+		},
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       r,
+	}, nil
+}
+
+// fakeCognitoRequest is the union of the served requests parameters.
+type fakeCognitoRequest struct {
+	UserPoolId      string
+	Username        string
+	GroupName       string
+	Description     string
+	Precedence      *int32
+	Filter          string
+	Limit           int
+	PaginationToken string
+	NextToken       string
+	AttributesToGet []string
+	Permanent       bool
+
+	UserAttributes []struct{ Name, Value string }
+
+	UserAttributeNames []string
+}
+
+// fakeCognitoError is the Cognito error.
+type fakeCognitoError struct {
+	Type    string `json:"__type"`
+	Message string `json:"message"`
+}
+
+// Error returns the error message.
+func (e fakeCognitoError) Error() string { return e.Message }
+
+// serve serves the Cognito request and returns the response status and
+// body.
+func (f *FakeCognito) serve(r *http.Request) (status int, body []byte) {
+	var req fakeCognitoRequest
+	op, ok := strings.CutPrefix(r.Header.Get("X-Amz-Target"),
+		fakeCognitoTarget)
+	var resp any
+	err := error(fakeCognitoError{"UnknownOperationException",
+		"Unknown operation " + op})
+	if ok && r.Body != nil {
+		err = json.NewDecoder(r.Body).Decode(&req)
+	}
+	if err == nil {
+		f.mu.Lock()
+		resp, err = f.operation(op, &req)
+		f.mu.Unlock()
+	}
+
+	status = http.StatusOK
+	if err != nil {
+		e, ok := err.(fakeCognitoError)
+		if !ok {
+			e = fakeCognitoError{"InvalidParameterException", err.Error()}
+		}
+		status, resp = http.StatusBadRequest, e
+	}
+	if resp == nil {
+		resp = struct{}{}
+	}
+	body, _ = json.Marshal(resp)
+	return
+}
+
+// operation executes the Cognito operation.
+func (f *FakeCognito) operation(op string, req *fakeCognitoRequest) (
+	resp any, err error) {
+
+	pool := f.pools[req.UserPoolId]
+	if pool == nil {
+		err = fakeCognitoError{"ResourceNotFoundException",
+			"User pool " + req.UserPoolId + " does not exist."}
+		return
+	}
+
+	// Operations of the user pool
+	switch op {
+	case "DescribeUserPool":
+		resp = map[string]any{"UserPool": map[string]any{
+			"Id":                     req.UserPoolId,
+			"EstimatedNumberOfUsers": len(pool.users),
+		}}
+		return
+	case "AdminCreateUser":
+		if pool.users[req.Username] != nil {
+			err = fakeCognitoError{"UsernameExistsException",
+				"User account already exists"}
+			return
+		}
+		attributes := make(map[string]string)
+		for _, a := range req.UserAttributes {
+			attributes[a.Name] = a.Value
+		}
+		user := f.newUser(req.Username, attributes)
+		pool.users[req.Username] = user
+		resp = map[string]any{"User": user.json("Attributes", nil)}
+		return
+	case "ListUsers":
+		resp, err = pool.listUsers(req)
+		return
+	case "CreateGroup":
+		if pool.groups[req.GroupName] != nil {
+			err = fakeCognitoError{"GroupExistsException",
+				"A group with the name already exists."}
+			return
+		}
+		group := &fakeCognitoGroup{description: req.Description,
+			precedence: req.Precedence, users: make(map[string]bool)}
+		pool.groups[req.GroupName] = group
+		resp = map[string]any{"Group": group.json(req)}
+		return
+	case "ListUsersInGroup":
+		group := pool.groups[req.GroupName]
+		if group == nil {
+			err = fakeCognitoError{"ResourceNotFoundException",
+				"Group not found."}
+			return
+		}
+		var users []*fakeCognitoUser
+		for _, username := range slices.Sorted(maps.Keys(group.users)) {
+			users = append(users, pool.users[username])
+		}
+		page, next, e := fakeCognitoPage(users, req.Limit, req.NextToken)
+		resp = map[string]any{"Users": fakeCognitoUsers(page, nil),
+			"NextToken": next}
+		err = e
+		return
+	}
+
+	// Operations of the user
+	user := pool.users[req.Username]
+	if user == nil {
+		err = fakeCognitoError{"UserNotFoundException",
+			"User does not exist."}
+		return
+	}
+	now := time.Now().UTC().Truncate(time.Second)
+	switch op {
+	case "AdminGetUser":
+		resp = user.json("UserAttributes", nil)
+	case "AdminUpdateUserAttributes":
+		for _, a := range req.UserAttributes {
+			user.attributes[a.Name] = a.Value
+		}
+		user.modified = now
+	case "AdminDeleteUserAttributes":
+		for _, name := range req.UserAttributeNames {
+			delete(user.attributes, name)
+		}
+		user.modified = now
+	case "AdminDeleteUser":
+		delete(pool.users, req.Username)
+		for _, group := range pool.groups {
+			delete(group.users, req.Username)
+		}
+	case "AdminEnableUser", "AdminDisableUser":
+		user.enabled = op == "AdminEnableUser"
+		user.modified = now
+	case "AdminConfirmSignUp":
+		user.status = "CONFIRMED"
+		user.modified = now
+	case "AdminSetUserPassword":
+		if req.Permanent {
+			user.status = "CONFIRMED"
+		} else {
+			user.status = "FORCE_CHANGE_PASSWORD"
+		}
+		user.modified = now
+	case "AdminAddUserToGroup", "AdminRemoveUserFromGroup":
+		group := pool.groups[req.GroupName]
+		if group == nil {
+			err = fakeCognitoError{"ResourceNotFoundException",
+				"Group not found."}
+			return
+		}
+		if op == "AdminAddUserToGroup" {
+			group.users[req.Username] = true
+		} else {
+			delete(group.users, req.Username)
+		}
+	case "AdminListGroupsForUser":
+		var groups []map[string]any
+		for _, name := range slices.Sorted(maps.Keys(pool.groups)) {
+			if pool.groups[name].users[req.Username] {
+				groups = append(groups, pool.groups[name].json(
+					&fakeCognitoRequest{UserPoolId: req.UserPoolId,
+						GroupName: name}))
+			}
+		}
+		page, next, e := fakeCognitoPage(groups, req.Limit, req.NextToken)
+		resp = map[string]any{"Groups": page, "NextToken": next}
+		err = e
+	default:
+		err = fakeCognitoError{"UnknownOperationException",
+			"Unknown operation " + op}
+	}
+	return
+}
+
+// listUsers lists the users of the pool matching the ListUsers filter in
+// the user name order.
+func (p *fakeCognitoPool) listUsers(req *fakeCognitoRequest) (resp any,
+	err error) {
+
+	match, err := fakeCognitoFilter(req.Filter)
+	if err != nil {
+		return
+	}
+	var users []*fakeCognitoUser
+	for _, username := range slices.Sorted(maps.Keys(p.users)) {
+		if match(p.users[username]) {
+			users = append(users, p.users[username])
+		}
+	}
+	page, next, err := fakeCognitoPage(users, req.Limit,
+		req.PaginationToken)
+	if err != nil {
+		return
+	}
+	resp = map[string]any{
+		"Users":           fakeCognitoUsers(page, req.AttributesToGet),
+		"PaginationToken": next,
+	}
+	return
+}
+
+// json returns the JSON user with the attributes field name and the
+// attributes filtered by names if not empty.
+func (u *fakeCognitoUser) json(attributesField string,
+	names []string) map[string]any {
+
+	attributes := []map[string]string{}
+	for _, name := range slices.Sorted(maps.Keys(u.attributes)) {
+		if len(names) == 0 || slices.Contains(names, name) {
+			attributes = append(attributes, map[string]string{
+				"Name": name, "Value": u.attributes[name]})
+		}
+	}
+	return map[string]any{
+		"Username":             u.username,
+		attributesField:        attributes,
+		"UserStatus":           u.status,
+		"Enabled":              u.enabled,
+		"UserCreateDate":       u.created.Unix(),
+		"UserLastModifiedDate": u.modified.Unix(),
+	}
+}
+
+// json returns the JSON group.
+func (g *fakeCognitoGroup) json(req *fakeCognitoRequest) map[string]any {
+	group := map[string]any{"GroupName": req.GroupName,
+		"UserPoolId": req.UserPoolId}
+	if g.description != "" {
+		group["Description"] = g.description
+	}
+	if g.precedence != nil {
+		group["Precedence"] = *g.precedence
+	}
+	return group
+}
+
+// fakeCognitoUsers returns the JSON users list.
+func fakeCognitoUsers(users []*fakeCognitoUser,
+	names []string) []map[string]any {
+
+	list := []map[string]any{}
+	for _, user := range users {
+		list = append(list, user.json("Attributes", names))
+	}
+	return list
+}
+
+// fakeCognitoPage returns the page of items which starts from the token
+// index and the next page token, empty for the last page.
+func fakeCognitoPage[T any](items []T, limit int, token string) (
+	page []T, next *string, err error) {
+
+	if limit <= 0 || limit > fakeCognitoMaxLimit {
+		limit = fakeCognitoMaxLimit
+	}
+	start := 0
+	if token != "" {
+		if start, err = strconv.Atoi(token); err != nil || start < 0 ||
+			start > len(items) {
+			err = fakeCognitoError{"InvalidParameterException",
+				"Invalid pagination token"}
+			return
+		}
+	}
+	end := min(start+limit, len(items))
+	page = items[start:end]
+	if end < len(items) {
+		next = aws.String(strconv.Itoa(end))
+	}
+	return
+}
+
+// fakeCognitoFilter parses the ListUsers filter `attribute = "value"` or
+// `attribute ^= "value"` and returns the users match function. The value
+// may be quoted with double or single quotes and contain the backslash
+// escaped quotes.
+func fakeCognitoFilter(filter string) (match func(*fakeCognitoUser) bool,
+	err error) {
+
+	filter = strings.TrimSpace(filter)
+	if filter == "" {
+		return func(*fakeCognitoUser) bool { return true }, nil
+	}
+	invalid := fakeCognitoError{"InvalidParameterException",
+		"Error while parsing filter."}
+
+	// Attribute name and operator
+	i := strings.IndexAny(filter, "^= ")
+	if i <= 0 {
+		return nil, invalid
+	}
+	name, rest := filter[:i], strings.TrimSpace(filter[i:])
+	rest, prefix := strings.CutPrefix(rest, "^=")
+	if !prefix {
+		var ok bool
+		if rest, ok = strings.CutPrefix(rest, "="); !ok {
+			return nil, invalid
+		}
+	}
+	if !slices.Contains(fakeCognitoFilterAttributes, name) {
+		return nil, fakeCognitoError{"InvalidParameterException",
+			"Invalid search attribute " + name}
+	}
+
+	// Quoted value
+	rest = strings.TrimSpace(rest)
+	if len(rest) < 2 || (rest[0] != '"' && rest[0] != '\'') ||
+		rest[len(rest)-1] != rest[0] {
+		return nil, invalid
+	}
+	quote := rest[:1]
+	value := strings.ReplaceAll(rest[1:len(rest)-1], `\`+quote, quote)
+
+	return func(u *fakeCognitoUser) bool {
+		var v string
+		switch name {
+		case "username":
+			v = u.username
+		case "cognito:user_status":
+			v, value = strings.ToUpper(u.status), strings.ToUpper(value)
+		case "status":
+			v = "Disabled"
+			if u.enabled {
+				v = "Enabled"
+			}
+		default:
+			v = u.attributes[name]
+		}
+		if prefix {
+			return strings.HasPrefix(v, value)
+		}
+		return v == value
+	}, nil
+}
@@ -0,0 +1,110 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+)
+
+// TestFakeCognito checks the Cognito functions and cache with the
+// FakeCognito backend
+func TestFakeCognito(t *testing.T) {
+
+	fake := NewFakeCognito()
+	sub := fake.AddUser("pool", "alice",
+		map[string]string{"email": "alice@example.com"}, "admins")
+	fake.AddUser("pool", "bob", map[string]string{"email": "bob@test.com"})
+	a := NewFromConfig(fake.Config())
+	ctx := context.Background()
+
+	_, err := a.Cognito.AdminCreateUser(ctx,
+		&cognitoidentityprovider.AdminCreateUserInput{
+			UserPoolId: aws.String("pool"),
+			Username:   aws.String("carol"),
+			UserAttributes: []types.AttributeType{{Name: aws.String("email"),
+				Value: aws.String("carol@example.com")}},
+		})
+	if err != nil {
+		t.Fatal("create user:", err)
+	}
+
+	// Get by sub and cache
+	user, err := a.Cognito.Get("pool", sub)
+	if err != nil || aws.ToString(user.Username) != "alice" {
+		t.Fatal("get:", user, err)
+	}
+	if user, err = a.Cognito.Cache.Get("pool", sub); err != nil ||
+		a.Cognito.UserAttributes(user)["email"] != "alice@example.com" {
+		t.Error("cache get:", user, err)
+	}
+	_, err = a.Cognito.Cache.Get("pool", "none")
+	if !errors.Is(err, ErrCognitoUserNotFound) || !IsNotFound(err) {
+		t.Error("cache get not found:", err)
+	}
+	if _, err = a.Cognito.Get("pool", "none"); err != ErrCognitoUserNotFound {
+		t.Error("get not found is not the bare sentinel:", err)
+	}
+	if n, err := a.Cognito.Length("pool"); err != nil || n != 3 {
+		t.Error("length:", n, err)
+	}
+
+	// Filters and pagination
+	list := func(filter string) (names []string, err error) {
+		for user, err := range a.Cognito.Users("pool", filter) {
+			if err != nil {
+				return nil, err
+			}
+			names = append(names, aws.ToString(user.Username))
+		}
+		return
+	}
+	for filter, want := range map[string]string{
+		``:                                  "alice,bob,carol",
+		`email ^= "alice"`:                  "alice",
+		`email="bob@test.com"`:              "bob",
+		`cognito:user_status = "confirmed"`: "alice,bob",
+		`status = "Enabled"`:                "alice,bob,carol",
+	} {
+		if names, err := list(filter); err != nil ||
+			strings.Join(names, ",") != want {
+			t.Errorf("filter %s: %v %v", filter, names, err)
+		}
+	}
+	if _, err = list(`custom:id = "1"`); err == nil {
+		t.Error("invalid filter attribute accepted")
+	}
+	users, next, err := a.Cognito.List("pool", 2, "", nil)
+	if err != nil || len(users) != 2 || next == nil {
+		t.Fatal("list page:", len(users), next, err)
+	}
+	if users, next, _ = a.Cognito.List("pool", 2, "", next); len(users) != 1 ||
+		next != nil {
+		t.Error("list last page:", len(users), next)
+	}
+
+	// Groups
+	users, _, err = a.Cognito.ListUsersInGroup("pool", "admins", 10, nil)
+	if err != nil || len(users) != 1 {
+		t.Error("users in group:", users, err)
+	}
+	_, _, err = a.Cognito.ListUsersInGroup("pool", "none", 10, nil)
+	if !IsNotFound(err) {
+		t.Error("group not found:", err)
+	}
+
+	// Not found users and pools
+	_, err = a.Cognito.AdminGetUser(ctx,
+		&cognitoidentityprovider.AdminGetUserInput{
+			UserPoolId: aws.String("pool"), Username: aws.String("none")})
+	if !IsNotFound(err) {
+		t.Error("user not found:", err)
+	}
+	if _, err = a.Cognito.Length("none"); !IsNotFound(err) {
+		t.Error("pool not found:", err)
+	}
+}
@@ -0,0 +1,144 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+)
+
+// FakeLambda is the LambdaInvoker test double. It records the invocations
+// and returns the responses scripted for the functions, so the Lambda Get
+// callers error handling and retries are tested without AWS:
+//
+//	fake := aws.NewFakeLambda()
+//	fake.On("func", aws.FakeLambdaResponse{Throttle: true},
+//		aws.FakeLambdaResponse{Payload: map[string]string{"id": "1"}})
+//	a.Lambda.Invoker = fake
+//
+// The invocation of the not scripted function returns the
+// ResourceNotFoundException error.
+type FakeLambda struct {
+	mu          sync.Mutex
+	responses   map[string][]FakeLambdaResponse
+	invocations []FakeLambdaInvocation
+}
+
+// FakeLambdaResponse is the scripted FakeLambda response.
+type FakeLambdaResponse struct {
+	// Payload is the function response. The []byte and string payloads are
+	// returned as is, other payloads are marshaled to JSON.
+	Payload any
+
+	// FunctionError is the function error type, for example "Unhandled".
+	// The Payload should contain the error details, for example
+	// {"errorMessage": "...", "errorType": "..."}.
+	FunctionError string
+
+	// Throttle returns the TooManyRequestsException error.
+	Throttle bool
+
+	// Err is the invocation error returned instead of the response.
+	Err error
+}
+
+// FakeLambdaInvocation is the recorded FakeLambda invocation.
+type FakeLambdaInvocation struct {
+	// FunctionName is the invoked function name.
+	FunctionName string
+
+	// Payload is the function request.
+	Payload []byte
+
+	// InvocationType is the invocation type, empty for the default
+	// RequestResponse.
+	InvocationType string
+}
+
+// NewFakeLambda creates the FakeLambda without scripted responses.
+func NewFakeLambda() *FakeLambda {
+	return &FakeLambda{responses: make(map[string][]FakeLambdaResponse)}
+}
+
+// On adds the responses of the function invocations. The responses are
+// returned in order, the last response is returned for all following
+// invocations.
+func (f *FakeLambda) On(funcName string, responses ...FakeLambdaResponse) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.responses[funcName] = append(f.responses[funcName], responses...)
+}
+
+// Invocations returns the recorded invocations.
+func (f *FakeLambda) Invocations() []FakeLambdaInvocation {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]FakeLambdaInvocation(nil), f.invocations...)
+}
+
+// Invoke records the invocation and returns the next scripted response of
+// the function, it implements the LambdaInvoker.
+func (f *FakeLambda) Invoke(ctx context.Context, params *lambda.InvokeInput,
+	optFns ...func(*lambda.Options)) (out *lambda.InvokeOutput, err error) {
+
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	// Record invocation and get next response
+	funcName := aws.ToString(params.FunctionName)
+	f.mu.Lock()
+	f.invocations = append(f.invocations, FakeLambdaInvocation{
+		FunctionName:   funcName,
+		Payload:        params.Payload,
+		InvocationType: string(params.InvocationType),
+	})
+	responses := f.responses[funcName]
+	var resp FakeLambdaResponse
+	if len(responses) > 0 {
+		resp = responses[0]
+		if len(responses) > 1 {
+			f.responses[funcName] = responses[1:]
+		}
+	}
+	f.mu.Unlock()
+
+	switch {
+	case len(responses) == 0:
+		err = translateError(&types.ResourceNotFoundException{
+			Message: aws.String("Function not found: " + funcName),
+		})
+		return
+	case resp.Throttle:
+		err = translateError(&types.TooManyRequestsException{
+			Message: aws.String("Rate Exceeded."),
+			Reason:  types.ThrottleReasonCallerRateLimitExceeded,
+		})
+		return
+	case resp.Err != nil:
+		err = resp.Err
+		return
+	}
+
+	out = &lambda.InvokeOutput{
+		StatusCode:      200,
+		ExecutedVersion: aws.String("$LATEST"),
+	}
+	if resp.FunctionError != "" {
+		out.FunctionError = aws.String(resp.FunctionError)
+	}
+	switch payload := resp.Payload.(type) {
+	case nil:
+	case []byte:
+		out.Payload = payload
+	case string:
+		out.Payload = []byte(payload)
+	default:
+		out.Payload, err = json.Marshal(payload)
+	}
+
+	return
+}
@@ -0,0 +1,55 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+// TestFakeLambda checks the Lambda Get with the scripted FakeLambda
+// responses
+func TestFakeLambda(t *testing.T) {
+
+	fake := NewFakeLambda()
+	fake.On("func", FakeLambdaResponse{Throttle: true},
+		FakeLambdaResponse{FunctionError: "Unhandled",
+			Payload: `{"errorMessage":"boom"}`},
+		FakeLambdaResponse{Payload: map[string]string{"id": "1"}})
+	a := NewFromConfig(aws.Config{Region: "us-east-1"})
+	a.Lambda.Invoker = fake
+
+	_, err := a.Lambda.Get("func", map[string]int{"n": 1})
+	if !IsThrottled(err) || !IsRetryable(err) {
+		t.Error("throttle:", err)
+	}
+	out, err := a.Lambda.Get("func", nil)
+	if err != nil || aws.ToString(out.FunctionError) != "Unhandled" ||
+		string(out.Payload) != `{"errorMessage":"boom"}` {
+		t.Error("function error:", out, err)
+	}
+	for range 2 {
+		out, err = a.Lambda.Get("func", nil)
+		if err != nil || string(out.Payload) != `{"id":"1"}` {
+			t.Error("payload:", out, err)
+		}
+	}
+	if _, err = a.Lambda.Get("none", nil); !IsNotFound(err) {
+		t.Error("not found:", err)
+	}
+
+	// Recorded invocations
+	calls := fake.Invocations()
+	if len(calls) != 5 || calls[0].FunctionName != "func" ||
+		string(calls[0].Payload) != `{"n":1}` {
+		t.Error("wrong invocations:", calls)
+	}
+
+	// Canceled context
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err = fake.Invoke(ctx, nil); !errors.Is(err, context.Canceled) {
+		t.Error("canceled:", err)
+	}
+}
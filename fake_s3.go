@@ -0,0 +1,676 @@
+package aws
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+)
+
+const (
+	// fakeS3MaxKeys is the default and maximum number of keys in the list
+	// response.
+	fakeS3MaxKeys = 1000
+
+	// fakeS3TimeFormat is the time format of the S3 XML responses.
+	fakeS3TimeFormat = "2006-01-02T15:04:05.000Z"
+)
+
+// FakeS3 is the in-memory S3 backend for tests. It implements the AWS SDK
+// HTTP client and serves the S3 object and bucket requests of the clients
+// created from its Config, so the Aws S3 functions and the S3 Client work
+// without a bucket or network:
+//
+//	fake := aws.NewFakeS3("bucket")
+//	a := aws.NewFromConfig(fake.Config())
+//	err := a.S3.Set("bucket", "key", []byte("data"))
+//
+// The objects Get with range and If-Match, Head, Put, Copy with If-Match,
+// Delete, DeleteObjects, the multipart uploads with the parts Put and Copy,
+// ListObjects and ListObjectsV2 with prefix, delimiter and pagination are
+// supported. The SSE-KMS objects get the unique not MD5 ETags. The missing
+// buckets and objects return the NoSuchBucket, NoSuchKey and Head NotFound
+// errors. The presigned URL
+// requests are served by Do or ServeHTTP without the signature check, the
+// expired URLs return AccessDenied.
+type FakeS3 struct {
+	// Clock is the time source of the presigned URLs expiry and the objects
+	// modification time. Default is the SystemClock.
+	Clock Clock
+
+	mu      sync.Mutex
+	buckets map[string]map[string]*fakeS3Object
+	uploads map[string]*fakeS3Upload
+	nextID  int
+}
+
+// fakeS3Object is the FakeS3 object.
+type fakeS3Object struct {
+	data        []byte
+	etag        string
+	contentType string
+	metadata    map[string]string
+	modified    time.Time
+	sse         string
+}
+
+// fakeS3Upload is the FakeS3 multipart upload.
+type fakeS3Upload struct {
+	bucket, key string
+	object      fakeS3Object
+	parts       map[int][]byte
+}
+
+// NewFakeS3 creates the FakeS3 with the empty buckets.
+func NewFakeS3(buckets ...string) (f *FakeS3) {
+	f = &FakeS3{
+		buckets: make(map[string]map[string]*fakeS3Object),
+		uploads: make(map[string]*fakeS3Upload),
+	}
+	for _, bucket := range buckets {
+		f.CreateBucket(bucket)
+	}
+	return
+}
+
+// CreateBucket creates the empty bucket if it does not exist.
+func (f *FakeS3) CreateBucket(bucket string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.buckets[bucket] == nil {
+		f.buckets[bucket] = make(map[string]*fakeS3Object)
+	}
+}
+
+// Keys returns the sorted object keys of the bucket.
+func (f *FakeS3) Keys(bucket string) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Sorted(maps.Keys(f.buckets[bucket]))
+}
+
+// Uploads returns the number of the not completed multipart uploads.
+func (f *FakeS3) Uploads() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.uploads)
+}
+
+// now returns the current time of the Clock.
+func (f *FakeS3) now() time.Time {
+	if f.Clock == nil {
+		return SystemClock.Now()
+	}
+	return f.Clock.Now()
+}
+
+// Config returns the AWS config with the static test credentials which
+// clients send requests to the FakeS3.
+func (f *FakeS3) Config() aws.Config {
+	return aws.Config{
+		Region:      "us-east-1",
+		HTTPClient:  f,
+		Credentials: credentials.NewStaticCredentialsProvider("id", "key", ""),
+	}
+}
+
+// Do serves the S3 request, it implements the AWS SDK HTTP client.
+func (f *FakeS3) Do(r *http.Request) (*http.Response, error) {
+	w := httptest.NewRecorder()
+	f.ServeHTTP(w, r)
+	resp := w.Result()
+	resp.Request = r
+	return resp, nil
+}
+
+// ServeHTTP serves the S3 request, use it with httptest.Server and the path
+// style S3 clients.
+func (f *FakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	bucket, key := fakeS3Location(r)
+	query := r.URL.Query()
+
+	// Presigned URL expiration
+	if date := query.Get("X-Amz-Date"); date != "" {
+		signed, _ := time.Parse("20060102T150405Z", date)
+		expires, _ := strconv.Atoi(query.Get("X-Amz-Expires"))
+		if f.now().After(signed.Add(time.Duration(expires) * time.Second)) {
+			fakeS3Error(w, http.StatusForbidden, "AccessDenied",
+				"Request has expired", key)
+			return
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// Bucket requests
+	if key == "" {
+		switch {
+		case r.Method == http.MethodPut:
+			if f.buckets[bucket] == nil {
+				f.buckets[bucket] = make(map[string]*fakeS3Object)
+			}
+		case f.buckets[bucket] == nil:
+			fakeS3Error(w, http.StatusNotFound, "NoSuchBucket",
+				"The specified bucket does not exist", "")
+		case r.Method == http.MethodHead:
+		case r.Method == http.MethodGet:
+			f.list(w, bucket, query)
+		case r.Method == http.MethodPost && query.Has("delete"):
+			f.deleteObjects(w, r, bucket)
+		default:
+			fakeS3Error(w, http.StatusNotImplemented, "NotImplemented",
+				"The request is not implemented by the fake", "")
+		}
+		return
+	}
+
+	objects := f.buckets[bucket]
+	if objects == nil {
+		fakeS3Error(w, http.StatusNotFound, "NoSuchBucket",
+			"The specified bucket does not exist", key)
+		return
+	}
+	if query.Has("uploads") || query.Has("uploadId") {
+		f.multipart(w, r, bucket, key)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		obj := objects[key]
+		if obj == nil {
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			fakeS3Error(w, http.StatusNotFound, "NoSuchKey",
+				"The specified key does not exist.", key)
+			return
+		}
+		if match := r.Header.Get("If-Match"); match != "" &&
+			match != obj.etag {
+			fakeS3Error(w, http.StatusPreconditionFailed,
+				"PreconditionFailed", "At least one of the pre-conditions "+
+					"you specified did not hold", key)
+			return
+		}
+		h := w.Header()
+		data, status := obj.data, http.StatusOK
+		if rng := r.Header.Get("Range"); rng != "" {
+			start, end, ok := fakeS3Range(rng, len(data))
+			if !ok {
+				fakeS3Error(w, http.StatusRequestedRangeNotSatisfiable,
+					"InvalidRange", "The requested range is not satisfiable",
+					key)
+				return
+			}
+			h.Set("Content-Range",
+				fmt.Sprintf("bytes %d-%d/%d", start, end, len(data)))
+			data, status = data[start:end+1], http.StatusPartialContent
+		}
+		h.Set("ETag", obj.etag)
+		h.Set("Last-Modified", obj.modified.Format(http.TimeFormat))
+		h.Set("Content-Type", obj.contentType)
+		h.Set("Content-Length", strconv.Itoa(len(data)))
+		if obj.sse != "" {
+			h.Set("X-Amz-Server-Side-Encryption", obj.sse)
+		}
+		for k, v := range obj.metadata {
+			h.Set("X-Amz-Meta-"+k, v)
+		}
+		w.WriteHeader(status)
+		if r.Method == http.MethodGet {
+			w.Write(data)
+		}
+
+	case http.MethodPut:
+		if r.Header.Get("X-Amz-Copy-Source") != "" {
+			f.copyObject(w, r, bucket, key)
+			return
+		}
+		var data []byte
+		if r.Body != nil {
+			data, _ = io.ReadAll(r.Body)
+		}
+		obj := f.newObject(r)
+		obj.data = data
+		obj.etag = f.objectETag(obj)
+		objects[key] = obj
+		w.Header().Set("ETag", obj.etag)
+
+	case http.MethodDelete:
+		delete(objects, key)
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		fakeS3Error(w, http.StatusNotImplemented, "NotImplemented",
+			"The request is not implemented by the fake", key)
+	}
+}
+
+// newObject returns the empty object with the content type and metadata of
+// the request headers.
+func (f *FakeS3) newObject(r *http.Request) *fakeS3Object {
+	obj := &fakeS3Object{
+		contentType: r.Header.Get("Content-Type"),
+		metadata:    make(map[string]string),
+		modified:    f.now().UTC().Truncate(time.Second),
+		sse:         r.Header.Get("X-Amz-Server-Side-Encryption"),
+	}
+	if obj.contentType == "" {
+		obj.contentType = "binary/octet-stream"
+	}
+	for k, v := range r.Header {
+		if name, ok := strings.CutPrefix(k, "X-Amz-Meta-"); ok {
+			obj.metadata[name] = v[0]
+		}
+	}
+	return obj
+}
+
+// objectETag returns the MD5 ETag of the object data, or the unique not MD5
+// ETag of the SSE-KMS encrypted object.
+func (f *FakeS3) objectETag(obj *fakeS3Object) string {
+	if !strings.HasPrefix(obj.sse, "aws:kms") {
+		return fakeS3ETag(obj.data)
+	}
+	f.nextID++
+	return fakeS3ETag(fmt.Appendf(slices.Clip(obj.data), "%d", f.nextID))
+}
+
+// copySource returns the object of the x-amz-copy-source "bucket/key"
+// header, it writes the error response if the object does not exist or does
+// not match the x-amz-copy-source-if-match ETag.
+func (f *FakeS3) copySource(w http.ResponseWriter,
+	r *http.Request) (src *fakeS3Object, ok bool) {
+
+	source, _, _ := strings.Cut(r.Header.Get("X-Amz-Copy-Source"), "?")
+	source, _ = url.PathUnescape(strings.TrimPrefix(source, "/"))
+	srcBucket, srcKey, _ := strings.Cut(source, "/")
+	if f.buckets[srcBucket] == nil {
+		fakeS3Error(w, http.StatusNotFound, "NoSuchBucket",
+			"The specified bucket does not exist", srcKey)
+		return
+	}
+	src = f.buckets[srcBucket][srcKey]
+	if src == nil {
+		fakeS3Error(w, http.StatusNotFound, "NoSuchKey",
+			"The specified key does not exist.", srcKey)
+		return
+	}
+	if match := r.Header.Get("X-Amz-Copy-Source-If-Match"); match != "" &&
+		match != src.etag {
+		fakeS3Error(w, http.StatusPreconditionFailed, "PreconditionFailed",
+			"At least one of the pre-conditions you specified did not hold",
+			srcKey)
+		return
+	}
+	ok = true
+	return
+}
+
+// copyObject copies the object from the x-amz-copy-source "bucket/key".
+func (f *FakeS3) copyObject(w http.ResponseWriter, r *http.Request, bucket,
+	key string) {
+
+	src, ok := f.copySource(w, r)
+	if !ok {
+		return
+	}
+	obj := *src
+	obj.modified = f.now().UTC().Truncate(time.Second)
+	obj.etag = f.objectETag(&obj)
+	f.buckets[bucket][key] = &obj
+
+	fakeS3XML(w, struct {
+		XMLName      xml.Name `xml:"CopyObjectResult"`
+		ETag         string
+		LastModified string
+	}{ETag: obj.etag, LastModified: obj.modified.Format(fakeS3TimeFormat)})
+}
+
+// multipart serves the multipart upload create, part Put or Copy, complete
+// and abort requests.
+func (f *FakeS3) multipart(w http.ResponseWriter, r *http.Request, bucket,
+	key string) {
+
+	query := r.URL.Query()
+
+	// Create upload
+	if r.Method == http.MethodPost && query.Has("uploads") {
+		f.nextID++
+		id := strconv.Itoa(f.nextID)
+		f.uploads[id] = &fakeS3Upload{bucket: bucket, key: key,
+			object: *f.newObject(r), parts: make(map[int][]byte)}
+		fakeS3XML(w, struct {
+			XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+			Bucket   string
+			Key      string
+			UploadId string
+		}{Bucket: bucket, Key: key, UploadId: id})
+		return
+	}
+
+	upload := f.uploads[query.Get("uploadId")]
+	if upload == nil || upload.bucket != bucket || upload.key != key {
+		fakeS3Error(w, http.StatusNotFound, "NoSuchUpload",
+			"The specified upload does not exist", key)
+		return
+	}
+	switch r.Method {
+
+	// Upload or copy part
+	case http.MethodPut:
+		number, _ := strconv.Atoi(query.Get("partNumber"))
+		if r.Header.Get("X-Amz-Copy-Source") == "" {
+			var data []byte
+			if r.Body != nil {
+				data, _ = io.ReadAll(r.Body)
+			}
+			upload.parts[number] = data
+			w.Header().Set("ETag", fakeS3ETag(data))
+			return
+		}
+		src, ok := f.copySource(w, r)
+		if !ok {
+			return
+		}
+		data := src.data
+		if rng := r.Header.Get("X-Amz-Copy-Source-Range"); rng != "" {
+			start, end, ok := fakeS3Range(rng, len(data))
+			if !ok {
+				fakeS3Error(w, http.StatusRequestedRangeNotSatisfiable,
+					"InvalidRange", "The requested range is not "+
+						"satisfiable", key)
+				return
+			}
+			data = data[start : end+1]
+		}
+		upload.parts[number] = data
+		fakeS3XML(w, struct {
+			XMLName      xml.Name `xml:"CopyPartResult"`
+			ETag         string
+			LastModified string
+		}{ETag: fakeS3ETag(data),
+			LastModified: f.now().UTC().Format(fakeS3TimeFormat)})
+
+	// Complete upload with the listed parts
+	case http.MethodPost:
+		var req struct {
+			Parts []struct {
+				PartNumber int
+				ETag       string
+			} `xml:"Part"`
+		}
+		if err := xml.NewDecoder(r.Body).Decode(&req); err != nil {
+			fakeS3Error(w, http.StatusBadRequest, "MalformedXML",
+				err.Error(), key)
+			return
+		}
+		var data, sums []byte
+		for i, part := range req.Parts {
+			content, ok := upload.parts[part.PartNumber]
+			if !ok || part.ETag != fakeS3ETag(content) ||
+				(i > 0 && part.PartNumber <= req.Parts[i-1].PartNumber) {
+				fakeS3Error(w, http.StatusBadRequest, "InvalidPart",
+					"One or more of the specified parts could not be "+
+						"found", key)
+				return
+			}
+			data = append(data, content...)
+			sum := md5.Sum(content)
+			sums = append(sums, sum[:]...)
+		}
+		obj := upload.object
+		sum := md5.Sum(sums)
+		obj.data = data
+		obj.etag = fmt.Sprintf(`"%s-%d"`, hex.EncodeToString(sum[:]),
+			len(req.Parts))
+		f.buckets[bucket][key] = &obj
+		delete(f.uploads, query.Get("uploadId"))
+		fakeS3XML(w, struct {
+			XMLName xml.Name `xml:"CompleteMultipartUploadResult"`
+			Bucket  string
+			Key     string
+			ETag    string
+		}{Bucket: bucket, Key: key, ETag: obj.etag})
+
+	// Abort upload
+	case http.MethodDelete:
+		delete(f.uploads, query.Get("uploadId"))
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		fakeS3Error(w, http.StatusNotImplemented, "NotImplemented",
+			"The request is not implemented by the fake", key)
+	}
+}
+
+// deleteObjects deletes the objects of the DeleteObjects request.
+func (f *FakeS3) deleteObjects(w http.ResponseWriter, r *http.Request,
+	bucket string) {
+
+	var req struct {
+		Objects []struct{ Key string } `xml:"Object"`
+		Quiet   bool
+	}
+	if err := xml.NewDecoder(r.Body).Decode(&req); err != nil {
+		fakeS3Error(w, http.StatusBadRequest, "MalformedXML",
+			err.Error(), "")
+		return
+	}
+	type deleted struct{ Key string }
+	var res struct {
+		XMLName xml.Name  `xml:"DeleteResult"`
+		Deleted []deleted `xml:"Deleted"`
+	}
+	for _, obj := range req.Objects {
+		delete(f.buckets[bucket], obj.Key)
+		if !req.Quiet {
+			res.Deleted = append(res.Deleted, deleted{obj.Key})
+		}
+	}
+	fakeS3XML(w, res)
+}
+
+// fakeS3Content is the object of the list response.
+type fakeS3Content struct {
+	Key          string
+	LastModified string
+	ETag         string
+	Size         int
+	StorageClass string
+}
+
+// fakeS3Prefix is the common prefix of the list response.
+type fakeS3Prefix struct {
+	Prefix string
+}
+
+// list lists the bucket objects with the ListObjects or ListObjectsV2
+// query.
+func (f *FakeS3) list(w http.ResponseWriter, bucket string,
+	query url.Values) {
+
+	v2 := query.Get("list-type") == "2"
+	prefix, delimiter := query.Get("prefix"), query.Get("delimiter")
+	maxKeys := fakeS3MaxKeys
+	if n, err := strconv.Atoi(query.Get("max-keys")); err == nil &&
+		n >= 0 && n < maxKeys {
+		maxKeys = n
+	}
+	after := query.Get("marker")
+	if v2 {
+		after = max(query.Get("start-after"),
+			query.Get("continuation-token"))
+	}
+
+	// Collect keys and common prefixes after the marker in the key order
+	var contents []fakeS3Content
+	var prefixes []fakeS3Prefix
+	truncated, next := false, ""
+	for _, key := range slices.Sorted(maps.Keys(f.buckets[bucket])) {
+		if !strings.HasPrefix(key, prefix) || key <= after {
+			continue
+		}
+		common := ""
+		if delimiter != "" {
+			i := strings.Index(key[len(prefix):], delimiter)
+			if i >= 0 {
+				common = key[:len(prefix)+i+len(delimiter)]
+				if common <= after || (len(prefixes) > 0 &&
+					prefixes[len(prefixes)-1].Prefix == common) {
+					continue
+				}
+			}
+		}
+		if len(contents)+len(prefixes) == maxKeys {
+			truncated = true
+			break
+		}
+		if common != "" {
+			prefixes = append(prefixes, fakeS3Prefix{common})
+			next = common
+			continue
+		}
+		obj := f.buckets[bucket][key]
+		contents = append(contents, fakeS3Content{
+			Key:          key,
+			LastModified: obj.modified.Format(fakeS3TimeFormat),
+			ETag:         obj.etag,
+			Size:         len(obj.data),
+			StorageClass: "STANDARD",
+		})
+		next = key
+	}
+	if !truncated {
+		next = ""
+	}
+
+	res := struct {
+		XMLName               xml.Name `xml:"ListBucketResult"`
+		Name                  string
+		Prefix                string
+		Delimiter             string `xml:",omitempty"`
+		Marker                *string
+		NextMarker            string `xml:",omitempty"`
+		ContinuationToken     string `xml:",omitempty"`
+		NextContinuationToken string `xml:",omitempty"`
+		KeyCount              *int
+		MaxKeys               int
+		IsTruncated           bool
+		Contents              []fakeS3Content
+		CommonPrefixes        []fakeS3Prefix
+	}{
+		Name:        bucket,
+		Prefix:      prefix,
+		Delimiter:   delimiter,
+		MaxKeys:     maxKeys,
+		IsTruncated: truncated,
+		Contents:    contents,
+
+		CommonPrefixes: prefixes,
+	}
+	if v2 {
+		count := len(contents) + len(prefixes)
+		res.KeyCount = &count
+		res.ContinuationToken = query.Get("continuation-token")
+		res.NextContinuationToken = next
+	} else {
+		marker := query.Get("marker")
+		res.Marker = &marker
+		res.NextMarker = next
+	}
+	fakeS3XML(w, res)
+}
+
+// fakeS3Location returns the bucket and key of the virtual hosted or path
+// style request.
+func fakeS3Location(r *http.Request) (bucket, key string) {
+	path := strings.TrimPrefix(r.URL.Path, "/")
+	host := r.URL.Host
+	if host == "" {
+		host = r.Host
+	}
+	if i := strings.Index(host, ".s3."); i > 0 {
+		return host[:i], path
+	}
+	bucket, key, _ = strings.Cut(path, "/")
+	return
+}
+
+// fakeS3Range returns the first and last bytes of the "bytes=first-last",
+// "bytes=first-" or "bytes=-suffix" range of the object size, ok is false if
+// the range is not satisfiable.
+func fakeS3Range(rng string, size int) (start, end int, ok bool) {
+	first, last, found := strings.Cut(strings.TrimPrefix(rng, "bytes="), "-")
+	if !found {
+		return
+	}
+	end = size - 1
+	var err error
+	switch {
+	case first == "":
+		var suffix int
+		suffix, err = strconv.Atoi(last)
+		start = max(size-suffix, 0)
+	case last == "":
+		start, err = strconv.Atoi(first)
+	default:
+		if start, err = strconv.Atoi(first); err == nil {
+			end, err = strconv.Atoi(last)
+			end = min(end, size-1)
+		}
+	}
+	ok = err == nil && start <= end && start < size
+	return
+}
+
+// fakeS3ETag returns the quoted MD5 ETag of the data.
+func fakeS3ETag(data []byte) string {
+	sum := md5.Sum(data)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+// fakeS3XML writes the XML response.
+func fakeS3XML(w http.ResponseWriter, v any) {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	if err := xml.NewEncoder(&buf).Encode(v); err != nil {
+		fakeS3Error(w, http.StatusInternalServerError, "InternalError",
+			err.Error(), "")
+		return
+	}
+	w.Header().Set("Content-Type", "application/xml")
+	w.Write(buf.Bytes())
+}
+
+// fakeS3Error writes the S3 error response.
+func fakeS3Error(w http.ResponseWriter, status int, code, message,
+	key string) {
+
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(message))
+	msg := buf.String()
+	buf.Reset()
+	xml.EscapeText(&buf, []byte(key))
+	fmt.Fprintf(w, "%s<Error><Code>%s</Code><Message>%s</Message>"+
+		"<Key>%s</Key><RequestId>fake</RequestId></Error>", xml.Header,
+		code, msg, buf.String())
+}
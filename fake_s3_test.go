@@ -0,0 +1,123 @@
+package aws
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// TestFakeS3 checks the S3 functions with the FakeS3 backend
+func TestFakeS3(t *testing.T) {
+
+	fake := NewFakeS3("bucket")
+	a := NewFromConfig(fake.Config())
+
+	for _, key := range []string{"dir/a", "dir/b", "dir/sub/c", "top"} {
+		if err := a.S3.Set("bucket", key, []byte(key)); err != nil {
+			t.Fatal("set:", err)
+		}
+	}
+	data, err := a.S3.Get("bucket", "dir/sub/c")
+	if err != nil || string(data) != "dir/sub/c" {
+		t.Fatal("get:", string(data), err)
+	}
+	info, err := a.S3.Info("bucket", "dir/a")
+	if err != nil || aws.ToString(info.ETag) != fakeS3ETag([]byte("dir/a")) ||
+		aws.ToInt64(info.ContentLength) != 5 {
+		t.Fatal("info:", info, err)
+	}
+
+	// Not found errors
+	if _, err = a.S3.Get("bucket", "none"); !IsNotFound(err) {
+		t.Error("get not found:", err)
+	}
+	if _, err = a.S3.Info("bucket", "none"); !IsNotFound(err) {
+		t.Error("info not found:", err)
+	}
+	if _, err = a.S3.Get("none", "dir/a"); !IsNotFound(err) {
+		t.Error("get no bucket:", err)
+	}
+
+	// Listing with delimiter and pages
+	keys, err := a.S3.List("bucket", "dir/")
+	if err != nil || strings.Join(keys, ",") != "dir/a,dir/b,dir/sub/c" {
+		t.Error("list:", keys, err)
+	}
+	keys, _ = a.S3.List("bucket", "dir/", ListObjects{Delimiter: "/"})
+	if strings.Join(keys, ",") != "dir/sub/" {
+		t.Error("list prefixes:", keys)
+	}
+	keys, _ = a.S3.List("bucket", "", ListObjects{MaxKeys: 2,
+		Marker: "dir/a"})
+	if strings.Join(keys, ",") != "dir/b,dir/sub/c" {
+		t.Error("list page:", keys)
+	}
+	var v2 []string
+	paginator := s3.NewListObjectsV2Paginator(a.S3.Client,
+		&s3.ListObjectsV2Input{Bucket: aws.String("bucket"),
+			MaxKeys: aws.Int32(1)})
+	for paginator.HasMorePages() {
+		out, err := paginator.NextPage(context.Background())
+		if err != nil {
+			t.Fatal("list v2:", err)
+		}
+		for _, obj := range out.Contents {
+			v2 = append(v2, aws.ToString(obj.Key))
+		}
+	}
+	if strings.Join(v2, ",") != "dir/a,dir/b,dir/sub/c,top" {
+		t.Error("list v2:", v2)
+	}
+
+	// Copy
+	_, err = a.S3.Client.CopyObject(context.Background(), &s3.CopyObjectInput{
+		Bucket:     aws.String("bucket"),
+		Key:        aws.String("copy"),
+		CopySource: aws.String("bucket/top"),
+	})
+	if data, _ = a.S3.Get("bucket", "copy"); err != nil ||
+		string(data) != "top" {
+		t.Error("copy:", string(data), err)
+	}
+
+	// Presigned get, the expired URL is denied
+	presign := s3.NewPresignClient(a.S3.Client)
+	req, err := presign.PresignGetObject(context.Background(),
+		&s3.GetObjectInput{Bucket: aws.String("bucket"),
+			Key: aws.String("top")}, s3.WithPresignExpires(time.Minute))
+	if err != nil {
+		t.Fatal("presign:", err)
+	}
+	get := func(signed time.Time) int {
+		u, _ := url.Parse(req.URL)
+		query := u.Query()
+		query.Set("X-Amz-Date", signed.UTC().Format("20060102T150405Z"))
+		u.RawQuery = query.Encode()
+		r, _ := http.NewRequest(req.Method, u.String(), nil)
+		resp, _ := fake.Do(r)
+		return resp.StatusCode
+	}
+	if status := get(time.Now()); status != http.StatusOK {
+		t.Error("presigned get:", status)
+	}
+	if status := get(time.Now().Add(-time.Hour)); status != http.StatusForbidden {
+		t.Error("expired presigned get:", status)
+	}
+
+	// Delete
+	if n, err := a.S3.DeleteFolder("bucket", "dir/"); err != nil || n != 3 {
+		t.Error("delete folder:", n, err)
+	}
+	if err = a.S3.Delete("bucket", "copy"); err != nil {
+		t.Error("delete:", err)
+	}
+	if keys := fake.Keys("bucket"); strings.Join(keys, ",") != "top" {
+		t.Error("wrong keys after delete:", keys)
+	}
+}
@@ -0,0 +1,181 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// FaultInjection is the rule of the faults injected into the AWS calls by
+// WithFaultInjection. The rates are the probabilities from 0 to 1 of the
+// fault in every call attempt, the faults are checked in the order throttle,
+// server error, latency.
+type FaultInjection struct {
+	// Service is the AWS service ID the rule applies to, for example "S3"
+	// or "DynamoDB". Empty means all services.
+	Service string
+
+	// Operations are the operation names the rule applies to, for example
+	// "GetObject". Empty means all operations of the service.
+	Operations []string
+
+	// ThrottleRate is the probability of the throttling error, for example
+	// S3 SlowDown or DynamoDB ThrottlingException.
+	ThrottleRate float64
+
+	// ServerErrorRate is the probability of the HTTP 500 internal error.
+	ServerErrorRate float64
+
+	// LatencyRate is the probability of the delay of the call.
+	LatencyRate float64
+
+	// Latency is the delay of the call. The delay is waited with the Aws
+	// Clock.
+	Latency time.Duration
+}
+
+// matches returns true if the rule applies to the service operation.
+func (f FaultInjection) matches(service, op string) bool {
+	if f.Service != "" && !strings.EqualFold(f.Service, service) {
+		return false
+	}
+	return len(f.Operations) == 0 || slices.Contains(f.Operations, op)
+}
+
+// WithFaultInjection enables the fault injection mode for the resilience
+// tests: the AWS calls of the package clients randomly fail with the
+// throttling and server errors or are delayed. The faults are injected in
+// every call attempt instead of sending the request, so the errors pass the
+// AWS SDK retries, the package error translation and the error hook as the
+// real ones:
+//
+//	chaos := aws.NewFromConfig(cfg, aws.WithFaultInjection(
+//		aws.FaultInjection{Service: "DynamoDB", ThrottleRate: 0.2},
+//		aws.FaultInjection{Service: "S3", Operations: []string{"GetObject"},
+//			LatencyRate: 0.5, Latency: 2 * time.Second},
+//	))
+//
+// The first rule matching the service and operation is applied.
+//
+// Parameters:
+//   - faults: The fault injection rules.
+func WithFaultInjection(faults ...FaultInjection) Option {
+	return func(o *options) { o.faults = append(o.faults, faults...) }
+}
+
+// withFaultInjection returns the AWS config with the middleware which
+// injects the faults into the calls of all clients created from it.
+func withFaultInjection(cfg aws.Config, o options) aws.Config {
+	if len(o.faults) == 0 {
+		return cfg
+	}
+	cfg.APIOptions = append(slices.Clip(cfg.APIOptions),
+		func(stack *middleware.Stack) error {
+			return stack.Deserialize.Add(middleware.DeserializeMiddlewareFunc(
+				"FaultInjection",
+				func(ctx context.Context, in middleware.DeserializeInput,
+					next middleware.DeserializeHandler) (
+					out middleware.DeserializeOutput, md middleware.Metadata,
+					err error) {
+
+					service := middleware.GetServiceID(ctx)
+					op := middleware.GetOperationName(ctx)
+					i := slices.IndexFunc(o.faults, func(f FaultInjection) bool {
+						return f.matches(service, op)
+					})
+					if i < 0 {
+						return next.HandleDeserialize(ctx, in)
+					}
+					f := o.faults[i]
+					req, _ := in.Request.(*smithyhttp.Request)
+
+					switch {
+					case rand.Float64() < f.ThrottleRate:
+						out.RawResponse = faultResponse(service, req, true)
+						return
+					case rand.Float64() < f.ServerErrorRate:
+						out.RawResponse = faultResponse(service, req, false)
+						return
+					case rand.Float64() < f.LatencyRate:
+						select {
+						case <-clock(ctx).After(f.Latency):
+						case <-ctx.Done():
+							err = ctx.Err()
+							return
+						}
+					}
+					return next.HandleDeserialize(ctx, in)
+				},
+			), middleware.After)
+		},
+	)
+	return cfg
+}
+
+// faultResponse returns the throttling or internal error response in the
+// service protocol format.
+func faultResponse(service string, req *smithyhttp.Request,
+	throttle bool) *smithyhttp.Response {
+
+	status, code := http.StatusInternalServerError, "InternalFailure"
+	if throttle {
+		status, code = http.StatusBadRequest, "ThrottlingException"
+	}
+	message := "injected fault"
+	header := http.Header{}
+	var body string
+
+	form := req != nil && strings.HasPrefix(req.Header.Get("Content-Type"),
+		"application/x-www-form-urlencoded")
+	switch {
+
+	// REST-XML S3 errors are not wrapped
+	case service == "S3":
+		code = "InternalError"
+		if throttle {
+			status, code = http.StatusServiceUnavailable, "SlowDown"
+		}
+		body = fmt.Sprintf("<Error><Code>%s</Code><Message>%s</Message>"+
+			"</Error>", code, message)
+
+	// EC2 query errors
+	case service == "EC2":
+		if throttle {
+			code = "RequestLimitExceeded"
+		}
+		body = fmt.Sprintf("<Response><Errors><Error><Code>%s</Code>"+
+			"<Message>%s</Message></Error></Errors></Response>", code, message)
+
+	// Query and Route 53 REST-XML errors
+	case form || service == "Route 53":
+		if throttle {
+			code = "Throttling"
+		}
+		body = fmt.Sprintf("<ErrorResponse><Error><Code>%s</Code>"+
+			"<Message>%s</Message></Error></ErrorResponse>", code, message)
+
+	// JSON errors
+	default:
+		header.Set("X-Amzn-ErrorType", code)
+		header.Set("Content-Type", "application/x-amz-json-1.1")
+		body = fmt.Sprintf(`{"__type":%q,"message":%q}`, code, message)
+	}
+
+	return &smithyhttp.Response{
+		Response: &http.Response{
+			StatusCode:    status,
+			Header:        header,
+			Body:          io.NopCloser(strings.NewReader(body)),
+			ContentLength: int64(len(body)),
+		},
+	}
+}
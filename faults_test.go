@@ -0,0 +1,100 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// TestFaultInjection checks the injected faults in the services protocols
+func TestFaultInjection(t *testing.T) {
+
+	// S3 throttling, the request is not sent
+	fake := NewFakeS3("bucket")
+	cfg := fake.Config()
+	cfg.RetryMaxAttempts = 1
+	var hooked []string
+	a := NewFromConfig(cfg, WithFaultInjection(
+		FaultInjection{Service: "S3", Operations: []string{"PutObject"},
+			ThrottleRate: 1},
+		FaultInjection{Service: "s3", ServerErrorRate: 1},
+	), WithErrorHook(func(service, op string, err error) {
+		hooked = append(hooked, op)
+	}))
+	err := a.S3.Set("bucket", "key", []byte("data"))
+	var e *Error
+	if !IsThrottled(err) || !errors.As(err, &e) || e.Code() != "SlowDown" ||
+		len(fake.Keys("bucket")) != 0 {
+		t.Error("wrong s3 throttling:", err)
+	}
+
+	// S3 server error of other operations
+	_, err = a.S3.Get("bucket", "key")
+	if !errors.As(err, &e) || e.Code() != "InternalError" ||
+		e.HTTPStatus() != http.StatusInternalServerError || !IsRetryable(err) {
+		t.Error("wrong s3 server error:", err)
+	}
+	if len(hooked) != 2 || hooked[0] != "PutObject" {
+		t.Error("wrong hooked errors:", hooked)
+	}
+
+	// JSON and query protocols
+	client := &pagesHTTPClient{bodies: []string{`{}`}}
+	a = newPagesTestAws(client)
+	cfg = a.Config()
+	cfg.RetryMaxAttempts = 1
+	a = NewFromConfig(cfg, WithFaultInjection(
+		FaultInjection{Service: "DynamoDB", ThrottleRate: 1},
+		FaultInjection{Service: "STS", ThrottleRate: 1},
+	))
+	var item map[string]any
+	err = a.Dynamo.Get("table", map[string]string{"id": "1"}, &item)
+	if !errors.As(err, &e) || e.Code() != "ThrottlingException" {
+		t.Error("wrong dynamo throttling:", err)
+	}
+	_, err = a.STS.GetCallerIdentity()
+	if !errors.As(err, &e) || e.Code() != "Throttling" {
+		t.Error("wrong sts throttling:", err)
+	}
+	if len(client.requests) != 0 {
+		t.Error("faulted requests are sent:", client.requests)
+	}
+
+	// Latency waits for the Aws clock
+	clk := NewFakeClock(time.Now())
+	a = NewFromConfig(fake.Config(), WithClock(clk), WithFaultInjection(
+		FaultInjection{LatencyRate: 1, Latency: time.Minute}))
+	done := make(chan error)
+	go func() { done <- a.S3.Set("bucket", "key", []byte("data")) }()
+	clk.BlockUntil(1)
+	select {
+	case err = <-done:
+		t.Fatal("call is not delayed:", err)
+	default:
+	}
+	clk.Advance(time.Minute)
+	if err = <-done; err != nil || len(fake.Keys("bucket")) != 1 {
+		t.Error("wrong delayed call:", err)
+	}
+
+	// Latency is canceled with the context
+	clk = NewFakeClock(time.Now())
+	a = NewFromConfig(fake.Config(), WithClock(clk), WithFaultInjection(
+		FaultInjection{LatencyRate: 1, Latency: time.Minute}))
+	ctx, cancel := context.WithCancel(withClock(context.Background(), clk))
+	go func() {
+		_, err := a.S3.Client.HeadBucket(ctx,
+			&s3.HeadBucketInput{Bucket: aws.String("bucket")})
+		done <- err
+	}()
+	clk.BlockUntil(1)
+	cancel()
+	if err = <-done; !errors.Is(err, context.Canceled) {
+		t.Error("wrong canceled call:", err)
+	}
+}
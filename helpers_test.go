@@ -0,0 +1,51 @@
+package aws
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// pagesHTTPClient returns the response bodies one by one for the requests and
+// saves the request bodies. The response statuses are taken from statuses
+// one by one, the status is 200 when statuses are empty. The requests after
+// the last body fail with the error, so the test fails instead of panicking.
+type pagesHTTPClient struct {
+	bodies   []string
+	statuses []int
+	requests []string
+}
+
+func (c *pagesHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	var data []byte
+	if req.Body != nil {
+		data, _ = io.ReadAll(req.Body)
+	}
+	c.requests = append(c.requests, string(data))
+	if len(c.bodies) == 0 {
+		return nil, fmt.Errorf("pagesHTTPClient: no response for request %d "+
+			"%s %s", len(c.requests), req.Method, req.URL)
+	}
+	body := c.bodies[0]
+	c.bodies = c.bodies[1:]
+	status := http.StatusOK
+	if len(c.statuses) > 0 {
+		status, c.statuses = c.statuses[0], c.statuses[1:]
+	}
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+// newPagesTestAws creates Aws which clients receive the response bodies one
+// by one.
+func newPagesTestAws(client *pagesHTTPClient) *Aws {
+	a := newErrorTestAws(http.StatusOK, "")
+	cfg := a.Config()
+	cfg.HTTPClient = client
+	return NewFromConfig(cfg)
+}
@@ -0,0 +1,79 @@
+package aws
+
+// Option is the Aws option used by NewFromConfig.
+type Option func(*options)
+
+// options contains the Aws options.
+type options struct {
+	// errorHook is called for every failed AWS call
+	errorHook func(service, op string, err error)
+
+	// errorMapping maps AWS error codes to the caller errors
+	errorMapping map[string]error
+
+	// xray emits the X-Ray subsegments of AWS calls
+	xray *xrayEmitter
+
+	// vcr records or replays the HTTP requests of AWS calls
+	vcr *vcr
+
+	// clock is the time source of the clients and caches
+	clock Clock
+
+	// dryRun reports the skipped destructive operations in dry-run mode
+	dryRun func(op DryRunOperation)
+
+	// faults are the fault injection rules of the resilience tests
+	faults []FaultInjection
+
+	// breaker contains the circuits of the services calls
+	breaker *circuitBreaker
+
+	// payloadLog logs the redacted payloads of the calls in debug mode
+	payloadLog *payloadRedactor
+}
+
+// newOptions creates options from the Option list.
+func newOptions(opts ...Option) (o options) {
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return
+}
+
+// WithErrorHook sets the hook which is called for every failed AWS call of
+// the package clients. Use it to count error codes and alert on error spikes
+// without instrumenting each call site. The err is wrapped with OpError and
+// Error, the hook is called after the AWS SDK retries.
+//
+// Parameters:
+//   - hook: The function called with the AWS service ID, the operation name
+//     and the operation error. It should not block.
+func WithErrorHook(hook func(service, op string, err error)) Option {
+	return func(o *options) { o.errorHook = hook }
+}
+
+// WithErrorMapping registers the mapping from AWS error codes to the caller
+// errors. The errors returned by the package functions wrap the mapped error,
+// so they can be checked with errors.Is without AWS SDK error knowledge:
+//
+//	a := aws.NewFromConfig(cfg, aws.WithErrorMapping(map[string]error{
+//		"NoSuchKey": storage.ErrMissing,
+//	}))
+//	...
+//	if errors.Is(err, storage.ErrMissing) { ... }
+//
+// The mappings of several WithErrorMapping options are merged.
+//
+// Parameters:
+//   - mapping: The map of AWS error codes to the caller errors.
+func WithErrorMapping(mapping map[string]error) Option {
+	return func(o *options) {
+		if o.errorMapping == nil {
+			o.errorMapping = make(map[string]error, len(mapping))
+		}
+		for code, err := range mapping {
+			o.errorMapping[code] = err
+		}
+	}
+}
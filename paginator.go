@@ -0,0 +1,110 @@
+package aws
+
+import "iter"
+
+// Paginator reads the pages of the paginated AWS API. It is the one
+// pagination mechanism of the package listings: S3 ListPages, Cognito
+// UsersPages, Lambda FunctionsPages, SQS QueuesPages, SES
+// SuppressedAddressesPages and DynamoDB QueryPages and ScanPages. The pages
+// may be read one by one:
+//
+//	p := a.Cognito.UsersPages(userPoolId, "")
+//	for p.HasMorePages() {
+//		users, err := p.NextPage()
+//		...
+//	}
+//
+// or ranged over with Pages and Items:
+//
+//	for user, err := range a.Cognito.UsersPages(userPoolId, "").Items() {
+//		...
+//	}
+type Paginator[T any] struct {
+	next func() (items []T, more bool, err error)
+	done bool
+}
+
+// NewPaginator creates the Paginator which reads the pages with the next
+// function. The next function keeps the pagination token of its API, it
+// returns the page items and true if there are more pages.
+//
+// Parameters:
+//   - next: The function which requests the next page.
+//
+// Returns:
+//   - p: The paginator.
+func NewPaginator[T any](next func() (items []T, more bool, err error)) (
+	p *Paginator[T]) {
+
+	return &Paginator[T]{next: next}
+}
+
+// HasMorePages returns true if there are more pages to read. It is true
+// before the first page is read and false after the error.
+func (p *Paginator[T]) HasMorePages() bool {
+	return !p.done
+}
+
+// NextPage reads the next page.
+//
+// Returns:
+//   - items: The page items, nil if there are no more pages.
+//   - err: An error if the page request fails. The paginator stops after
+//     the error.
+func (p *Paginator[T]) NextPage() (items []T, err error) {
+	if p.done {
+		return
+	}
+	items, more, err := p.next()
+	p.done = !more || err != nil
+	return
+}
+
+// Pages returns iterator over the pages. The next page is requested when the
+// previous page is processed. The iteration stops after the first error.
+func (p *Paginator[T]) Pages() iter.Seq2[[]T, error] {
+	return func(yield func([]T, error) bool) {
+		for p.HasMorePages() {
+			items, err := p.NextPage()
+			if !yield(items, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// Items returns iterator over the items of all pages. The next page is
+// requested when the items of the previous page are iterated. The iteration
+// stops after the first error.
+func (p *Paginator[T]) Items() iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for items, err := range p.Pages() {
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// All reads the items of all pages.
+//
+// Returns:
+//   - items: The items of all pages, the items read before the error if it
+//     occurs.
+//   - err: An error if a page request fails.
+func (p *Paginator[T]) All() (items []T, err error) {
+	for page, err := range p.Pages() {
+		if err != nil {
+			return items, err
+		}
+		items = append(items, page...)
+	}
+	return
+}
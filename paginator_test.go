@@ -0,0 +1,122 @@
+package aws
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+// TestPaginator checks the Paginator reads the pages one by one and stops
+// after the last page or the error
+func TestPaginator(t *testing.T) {
+
+	pages := [][]int{{1, 2}, {}, {3}}
+	var calls int
+	newPaginator := func(fail error) *Paginator[int] {
+		calls = 0
+		return NewPaginator(func() (items []int, more bool, err error) {
+			if calls == 1 && fail != nil {
+				return nil, true, fail
+			}
+			items = pages[calls]
+			calls++
+			return items, calls < len(pages), nil
+		})
+	}
+
+	items, err := newPaginator(nil).All()
+	if err != nil || fmt.Sprint(items) != "[1 2 3]" || calls != 3 {
+		t.Error("wrong all items:", items, err, calls)
+	}
+
+	// Stop iteration
+	for item, err := range newPaginator(nil).Items() {
+		if err != nil || item != 1 {
+			t.Error("wrong item:", item, err)
+		}
+		break
+	}
+	if calls != 1 {
+		t.Error("next page is requested after break:", calls)
+	}
+
+	// Error stops the paginator
+	fail := errors.New("fail")
+	p := newPaginator(fail)
+	if items, err = p.All(); !errors.Is(err, fail) ||
+		fmt.Sprint(items) != "[1 2]" || p.HasMorePages() {
+		t.Error("wrong error result:", items, err)
+	}
+	if items, err = p.NextPage(); items != nil || err != nil {
+		t.Error("wrong page after error:", items, err)
+	}
+}
+
+// TestPaginatorServices checks the service listings paginators
+func TestPaginatorServices(t *testing.T) {
+
+	// S3 objects pages
+	fake := NewFakeS3("bucket")
+	a := NewFromConfig(fake.Config())
+	for _, key := range []string{"a", "b", "c", "d", "e"} {
+		if err := a.S3.Set("bucket", key, []byte(key)); err != nil {
+			t.Fatal("set:", err)
+		}
+	}
+	var pages []string
+	for keys, err := range a.S3.ListPages("bucket", "",
+		ListObjects{MaxKeys: 2}).Pages() {
+		if err != nil {
+			t.Fatal("s3 pages:", err)
+		}
+		pages = append(pages, strings.Join(keys, ""))
+	}
+	if strings.Join(pages, ",") != "ab,cd,e" {
+		t.Error("wrong s3 pages:", pages)
+	}
+
+	// Cognito users pages of 60 users
+	cognito := NewFakeCognito("pool")
+	for i := range 61 {
+		cognito.AddUser("pool", fmt.Sprint("user", i), nil)
+	}
+	a = NewFromConfig(cognito.Config())
+	p := a.Cognito.UsersPages("pool", "")
+	if users, err := p.NextPage(); err != nil || len(users) != 60 ||
+		!p.HasMorePages() {
+		t.Error("wrong first users page:", len(users), err)
+	}
+	if users, err := p.NextPage(); err != nil || len(users) != 1 ||
+		p.HasMorePages() {
+		t.Error("wrong last users page:", len(users), err)
+	}
+
+	// Lambda functions and SQS queues
+	client := &pagesHTTPClient{bodies: []string{
+		`{"Functions":[{"FunctionName":"a"}],"NextMarker":"m1"}`,
+		`{"Functions":[{"FunctionName":"b"}]}`,
+		`{"QueueUrls":["https://sqs/1/jobs"],"NextToken":"t1"}`,
+		`{"QueueUrls":["https://sqs/1/jobs-dlq"]}`,
+	}}
+	a = newPagesTestAws(client)
+	var names []string
+	for f, err := range a.Lambda.Functions() {
+		if err != nil {
+			t.Fatal("functions:", err)
+		}
+		names = append(names, aws.ToString(f.FunctionName))
+	}
+	if strings.Join(names, ",") != "a,b" {
+		t.Error("wrong functions:", names)
+	}
+	urls, err := a.SQS.QueuesPages("jobs").All()
+	if err != nil || strings.Join(urls, ",") !=
+		"https://sqs/1/jobs,https://sqs/1/jobs-dlq" ||
+		!strings.Contains(client.requests[3], `"NextToken":"t1"`) ||
+		!strings.Contains(client.requests[3], `"QueueNamePrefix":"jobs"`) {
+		t.Error("wrong queues:", urls, err, client.requests[3])
+	}
+}
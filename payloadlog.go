@@ -0,0 +1,308 @@
+package aws
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+const (
+	// payloadLogMaxBytes is the default maximum length of the logged
+	// payload.
+	payloadLogMaxBytes = 4096
+
+	// payloadLogReadLimit is the maximum size of the payload read to be
+	// redacted and logged. The larger payloads are logged by their size.
+	payloadLogReadLimit = 1 << 20
+
+	// payloadRedacted replaces the redacted values.
+	payloadRedacted = "REDACTED"
+)
+
+// payloadLogServices are the AWS service IDs which payloads are logged by
+// default.
+var payloadLogServices = []string{"S3", "Lambda",
+	"Cognito Identity Provider"}
+
+// payloadSecrets are the attributes which are always redacted.
+var payloadSecrets = []string{"Password", "TemporaryPassword",
+	"PreviousPassword", "ProposedPassword", "AccessToken", "IdToken",
+	"RefreshToken", "SecretHash", "Session", "ClientSecret",
+	"SecretAccessKey", "SecretKey", "SessionToken"}
+
+// PayloadLogOptions are the optional parameters of WithPayloadLogging.
+type PayloadLogOptions struct {
+	// Services are the AWS service IDs which payloads are logged. Default
+	// are "S3", "Lambda" and "Cognito Identity Provider".
+	Services []string
+
+	// RedactAttributes are the attribute names which values are redacted in
+	// any place of the JSON and XML payloads, for example "email" or
+	// "phone_number". The Cognito user attributes are redacted by their
+	// names too. The passwords, tokens and secret keys are always redacted.
+	RedactAttributes []string
+
+	// RedactPaths are the dot separated paths of the JSON payload values
+	// which are redacted, for example "UserAttributes" or "user.*.ssn". The
+	// "*" matches any attribute, the arrays are passed through, so the path
+	// "Users.Username" redacts the names of all users.
+	RedactPaths []string
+
+	// MaxBytes is the maximum length of the logged payload, the longer
+	// payloads are truncated. Default is 4096.
+	MaxBytes int
+
+	// Log is called with every logged call. Nil logs the calls with the
+	// standard logger.
+	Log func(entry PayloadLogEntry)
+}
+
+// PayloadLogEntry is the logged AWS call.
+type PayloadLogEntry struct {
+	// Service is the AWS service ID, for example "S3".
+	Service string
+
+	// Operation is the operation name, for example "GetObject".
+	Operation string
+
+	// Status is the HTTP response status, zero if the request failed.
+	Status int
+
+	// Duration is the HTTP request duration.
+	Duration time.Duration
+
+	// Request is the redacted request payload.
+	Request string
+
+	// Response is the redacted response payload.
+	Response string
+
+	// Err is the HTTP request error.
+	Err error
+}
+
+// String returns the entry in one line.
+func (e PayloadLogEntry) String() string {
+	s := fmt.Sprintf("%s %s %d %s request: %s response: %s", e.Service,
+		e.Operation, e.Status, e.Duration.Round(time.Millisecond), e.Request,
+		e.Response)
+	if e.Err != nil {
+		s += " error: " + e.Err.Error()
+	}
+	return s
+}
+
+// WithPayloadLogging enables the debug mode which logs the request and
+// response payloads of the S3, Lambda and Cognito calls. The payloads are
+// redacted before they are logged: the values of the passwords, tokens and
+// the configured attributes and paths are replaced by "REDACTED", and the
+// long payloads are truncated. The JSON and XML payloads are logged, other
+// payloads like the S3 objects data are logged by their size:
+//
+//	a := aws.NewFromConfig(cfg, aws.WithPayloadLogging(aws.PayloadLogOptions{
+//		RedactAttributes: []string{"email", "phone_number"},
+//		RedactPaths:      []string{"card.number"},
+//	}))
+//
+// Every HTTP attempt is logged, so the retried calls are logged several
+// times.
+//
+// Parameters:
+//   - opts: The optional logging parameters.
+func WithPayloadLogging(opts ...PayloadLogOptions) Option {
+	var o PayloadLogOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	if len(o.Services) == 0 {
+		o.Services = payloadLogServices
+	}
+	if o.MaxBytes <= 0 {
+		o.MaxBytes = payloadLogMaxBytes
+	}
+	if o.Log == nil {
+		o.Log = func(entry PayloadLogEntry) { log.Println("aws:", entry) }
+	}
+	r := newPayloadRedactor(o)
+	return func(o *options) { o.payloadLog = r }
+}
+
+// withPayloadLogging returns the AWS config with the middleware which logs
+// the payloads of the calls of all clients created from it.
+func withPayloadLogging(cfg aws.Config, o options) aws.Config {
+	r := o.payloadLog
+	if r == nil {
+		return cfg
+	}
+	cfg.APIOptions = append(slices.Clip(cfg.APIOptions),
+		func(stack *middleware.Stack) error {
+			return stack.Deserialize.Add(middleware.DeserializeMiddlewareFunc(
+				"PayloadLog",
+				func(ctx context.Context, in middleware.DeserializeInput,
+					next middleware.DeserializeHandler) (
+					out middleware.DeserializeOutput, md middleware.Metadata,
+					err error) {
+
+					entry := PayloadLogEntry{
+						Service:   middleware.GetServiceID(ctx),
+						Operation: middleware.GetOperationName(ctx),
+					}
+					req, ok := in.Request.(*smithyhttp.Request)
+					if !ok || !slices.ContainsFunc(r.opts.Services,
+						func(s string) bool {
+							return strings.EqualFold(s, entry.Service)
+						}) {
+						return next.HandleDeserialize(ctx, in)
+					}
+					entry.Request = r.request(req)
+
+					start := time.Now()
+					out, md, err = next.HandleDeserialize(ctx, in)
+					entry.Duration = time.Since(start)
+					entry.Err = err
+					if resp, ok := out.RawResponse.(*smithyhttp.Response); ok {
+						entry.Status = resp.StatusCode
+						entry.Response = r.response(resp)
+					}
+					r.opts.Log(entry)
+					return
+				},
+			), middleware.After)
+		},
+	)
+	return cfg
+}
+
+// payloadRedactor redacts and truncates the logged payloads.
+type payloadRedactor struct {
+	opts  PayloadLogOptions
+	names map[string]bool
+	paths [][]string
+	xml   *regexp.Regexp
+}
+
+// newPayloadRedactor creates the redactor of the options.
+func newPayloadRedactor(o PayloadLogOptions) *payloadRedactor {
+	r := &payloadRedactor{opts: o, names: make(map[string]bool)}
+	var quoted []string
+	for _, name := range slices.Concat(payloadSecrets, o.RedactAttributes) {
+		r.names[strings.ToLower(name)] = true
+		quoted = append(quoted, regexp.QuoteMeta(name))
+	}
+	r.xml = regexp.MustCompile(`(?i)(<(` + strings.Join(quoted, "|") +
+		`)>)[^<]*(</)`)
+	for _, path := range o.RedactPaths {
+		r.paths = append(r.paths, strings.Split(path, "."))
+	}
+	return r
+}
+
+// request returns the redacted request payload. The not seekable streams
+// are not read.
+func (r *payloadRedactor) request(req *smithyhttp.Request) string {
+	stream := req.GetStream()
+	switch {
+	case stream == nil:
+		return ""
+	case !req.IsStreamSeekable():
+		return "[stream]"
+	}
+	data, _ := io.ReadAll(io.LimitReader(stream, payloadLogReadLimit+1))
+	if err := req.RewindStream(); err != nil {
+		return "[stream]"
+	}
+	return r.redact(req.Header.Get("Content-Type"), data)
+}
+
+// response returns the redacted response payload. The read part of the body
+// is returned to the response.
+func (r *payloadRedactor) response(resp *smithyhttp.Response) string {
+	if resp.Body == nil {
+		return ""
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, payloadLogReadLimit+1))
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(data), resp.Body), resp.Body}
+	return r.redact(resp.Header.Get("Content-Type"), data)
+}
+
+// redact returns the redacted and truncated payload.
+func (r *payloadRedactor) redact(contentType string, data []byte) string {
+	if len(data) > payloadLogReadLimit {
+		return fmt.Sprintf("[more than %d bytes]", payloadLogReadLimit)
+	}
+
+	var s string
+	var v any
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	switch {
+	case len(bytes.TrimSpace(data)) == 0:
+		return ""
+	case d.Decode(&v) == nil:
+		v = r.redactJSON(v, nil)
+		out, _ := json.Marshal(v)
+		s = string(out)
+	case strings.Contains(contentType, "xml") ||
+		bytes.HasPrefix(bytes.TrimSpace(data), []byte("<")):
+		s = r.xml.ReplaceAllString(string(data),
+			"${1}"+payloadRedacted+"${3}")
+	default:
+		return fmt.Sprintf("[%d bytes]", len(data))
+	}
+
+	if len(s) > r.opts.MaxBytes {
+		s = fmt.Sprintf("%s...[%d bytes]", s[:r.opts.MaxBytes], len(s))
+	}
+	return s
+}
+
+// redactJSON returns the JSON value with the redacted attributes. The path
+// is the attributes path of the value.
+func (r *payloadRedactor) redactJSON(v any, path []string) any {
+	switch v := v.(type) {
+	case []any:
+		for i := range v {
+			v[i] = r.redactJSON(v[i], path)
+		}
+	case map[string]any:
+
+		// Cognito attribute {"Name": "email", "Value": "..."}
+		name, _ := v["Name"].(string)
+		if _, ok := v["Value"]; ok && r.names[strings.ToLower(name)] {
+			v["Value"] = payloadRedacted
+		}
+		for key, value := range v {
+			p := append(slices.Clip(path), key)
+			if r.names[strings.ToLower(key)] || r.matches(p) {
+				v[key] = payloadRedacted
+				continue
+			}
+			v[key] = r.redactJSON(value, p)
+		}
+	}
+	return v
+}
+
+// matches returns true if the attributes path matches one of the redacted
+// paths.
+func (r *payloadRedactor) matches(path []string) bool {
+	return slices.ContainsFunc(r.paths, func(p []string) bool {
+		return slices.EqualFunc(p, path, func(a, b string) bool {
+			return a == "*" || strings.EqualFold(a, b)
+		})
+	})
+}
@@ -0,0 +1,88 @@
+package aws
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestPayloadLogging checks the payloads are logged redacted and truncated
+func TestPayloadLogging(t *testing.T) {
+
+	var entries []PayloadLogEntry
+	opts := PayloadLogOptions{
+		RedactAttributes: []string{"email", "ETag"},
+		RedactPaths:      []string{"user.*.ssn"},
+		Log: func(entry PayloadLogEntry) {
+			entries = append(entries, entry)
+		},
+	}
+
+	// Cognito user attributes and passwords
+	cognito := NewFakeCognito("pool")
+	cognito.AddUser("pool", "user", map[string]string{
+		"email": "user@example.com", "name": "User"})
+	a := NewFromConfig(cognito.Config(), WithPayloadLogging(opts))
+	if _, _, err := a.Cognito.List("pool", 10, "", nil); err != nil {
+		t.Fatal("list:", err)
+	}
+	err := a.Cognito.SetPermanentPassword("pool", "user", "Secret-123")
+	if err != nil {
+		t.Fatal("set password:", err)
+	}
+	if len(entries) != 2 || entries[0].Operation != "ListUsers" ||
+		entries[0].Status != 200 ||
+		strings.Contains(entries[0].Response, "user@example.com") ||
+		!strings.Contains(entries[0].Response, `"Value":"User"`) ||
+		strings.Contains(entries[1].Request, "Secret-123") ||
+		!strings.Contains(entries[1].Request, `"Password":"REDACTED"`) {
+		t.Fatal("wrong cognito entries:", entries)
+	}
+
+	// Lambda payload paths and truncation, SQS is not logged
+	entries = nil
+	client := &pagesHTTPClient{bodies: []string{
+		`{"result":"` + strings.Repeat("x", 100) + `"}`,
+		`{"QueueUrl":"https://sqs/1/jobs"}`,
+	}}
+	opts.MaxBytes = 45
+	a = NewFromConfig(newPagesTestAws(client).Config(),
+		WithPayloadLogging(opts))
+	_, err = a.Lambda.Get("api", map[string]any{
+		"user": map[string]any{"home": map[string]string{"ssn": "123-45"}},
+		"id":   7,
+	})
+	if err != nil {
+		t.Fatal("lambda:", err)
+	}
+	if _, err = a.SQS.QueueURL("jobs"); err != nil {
+		t.Fatal("sqs:", err)
+	}
+	if len(entries) != 1 ||
+		entries[0].Request != `{"id":7,"user":{"home":{"ssn":"REDACTED"}}}` ||
+		entries[0].Response != `{"result":"`+strings.Repeat("x", 34)+
+			`...[113 bytes]` {
+		t.Fatal("wrong lambda entries:", entries)
+	}
+
+	// S3 object data and XML responses, the response body is not changed
+	entries = nil
+	opts.MaxBytes = 0
+	s3 := NewFakeS3("bucket")
+	a = NewFromConfig(s3.Config(), WithPayloadLogging(opts))
+	if err = a.S3.Set("bucket", "key", []byte{0, 1, 2}); err != nil {
+		t.Fatal("set:", err)
+	}
+	data, err := a.S3.Get("bucket", "key")
+	if err != nil || string(data) != "\x00\x01\x02" {
+		t.Fatal("get:", data, err)
+	}
+	if _, err = a.S3.List("bucket", ""); err != nil {
+		t.Fatal("list:", err)
+	}
+	if len(entries) != 3 || entries[0].Request != "[3 bytes]" ||
+		entries[1].Response != "[3 bytes]" ||
+		!strings.Contains(entries[2].Response, "<ETag>REDACTED</ETag>") ||
+		!strings.Contains(entries[2].Response, "<Key>key</Key>") {
+		t.Error("wrong s3 entries:", entries)
+	}
+}
@@ -0,0 +1,321 @@
+package aws
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+)
+
+// ErrVCRNotRecorded is returned by the AWS calls in the VCR replay mode when
+// the fixture has no recorded response of the request.
+var ErrVCRNotRecorded = errors.New("vcr interaction not recorded")
+
+// VCRMode is the WithVCR mode.
+type VCRMode int
+
+const (
+	// VCRReplay replays the fixture responses without network, the not
+	// recorded requests fail with ErrVCRNotRecorded.
+	VCRReplay VCRMode = iota
+
+	// VCRRecord sends the requests to AWS and records the responses to the
+	// fixture, the existing fixture is replaced.
+	VCRRecord
+)
+
+// vcrSecrets matches the credentials and tokens in the XML and JSON bodies.
+var vcrSecrets = regexp.MustCompile(`(<(SecretAccessKey|SessionToken)>)` +
+	`[^<]*(</)|("(SecretAccessKey|SecretKey|SessionToken|AccessToken|` +
+	`IdToken|RefreshToken)"\s*:\s*")(?:[^"\\]|\\.)*(")`)
+
+// vcrSkipHeaders are the response headers which are not recorded.
+var vcrSkipHeaders = []string{"Set-Cookie", "Date", "Connection"}
+
+// VCROptions are the optional parameters of WithVCR.
+type VCROptions struct {
+	// Mode is the VCRReplay or VCRRecord mode. Default is VCRReplay.
+	Mode VCRMode
+
+	// Sanitize changes the recorded interaction before it is saved, for
+	// example to replace the account IDs. The credentials and tokens of the
+	// responses are replaced by "REDACTED" before it is called.
+	Sanitize func(i *VCRInteraction)
+}
+
+// VCRInteraction is the recorded request and response.
+type VCRInteraction struct {
+	Request  VCRRequest  `json:"request"`
+	Response VCRResponse `json:"response"`
+}
+
+// VCRRequest is the recorded request. The request headers are not
+// recorded, except the X-Amz-Target of the JSON protocol services.
+type VCRRequest struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	Target string `json:"target,omitempty"`
+	Body   string `json:"body,omitempty"`
+	Base64 bool   `json:"base64,omitempty"`
+}
+
+// VCRResponse is the recorded response.
+type VCRResponse struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+	Base64 bool        `json:"base64,omitempty"`
+}
+
+// WithVCR records the HTTP requests and responses of the package clients to
+// the JSON fixture file or replays them offline. Record the fixture once
+// with the AWS credentials and run the tests with the replay mode without
+// credentials or network:
+//
+//	mode := aws.VCRReplay
+//	if os.Getenv("VCR") == "record" {
+//		mode = aws.VCRRecord
+//	}
+//	cfg := aws.Config{Region: "us-east-1"}
+//	a := aws.NewFromConfig(cfg, aws.WithVCR("testdata/list.json",
+//		aws.VCROptions{Mode: mode}))
+//
+// The replayed request matches the first not replayed interaction with the
+// same method, URL without the signature parameters, X-Amz-Target header
+// and body, or with the same request except the body if there is no such
+// interaction. The replay mode sets the static test credentials if the
+// config has no credentials.
+//
+// Parameters:
+//   - path: The fixture file path.
+//   - opts: The optional VCR parameters.
+func WithVCR(path string, opts ...VCROptions) Option {
+	var o VCROptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	v := &vcr{path: path, opts: o}
+	return func(o *options) { o.vcr = v }
+}
+
+// vcr is the recording and replaying HTTP client.
+type vcr struct {
+	path string
+	opts VCROptions
+
+	mu           sync.Mutex
+	loaded       bool
+	err          error
+	interactions []VCRInteraction
+	replayed     []bool
+}
+
+// vcrClient is the vcr HTTP client of the config with the next client.
+type vcrClient struct {
+	*vcr
+	next aws.HTTPClient
+}
+
+// vcrError is the replay error which is not retried by the AWS SDK.
+type vcrError struct{ err error }
+
+// Error returns the error message.
+func (e vcrError) Error() string { return e.err.Error() }
+
+// Unwrap returns the replay error.
+func (e vcrError) Unwrap() error { return e.err }
+
+// RetryableError returns false to stop the AWS SDK retries.
+func (e vcrError) RetryableError() bool { return false }
+
+// withVCR returns the AWS config which HTTP client records or replays the
+// requests.
+func withVCR(cfg aws.Config, o options) aws.Config {
+	if o.vcr == nil {
+		return cfg
+	}
+	next := cfg.HTTPClient
+	if next == nil {
+		next = awshttp.NewBuildableClient()
+	}
+	cfg.HTTPClient = vcrClient{o.vcr, next}
+	if o.vcr.opts.Mode == VCRReplay && cfg.Credentials == nil {
+		cfg.Credentials = credentials.NewStaticCredentialsProvider("id",
+			"key", "")
+	}
+	return cfg
+}
+
+// Do records or replays the request.
+func (c vcrClient) Do(r *http.Request) (resp *http.Response, err error) {
+	var body []byte
+	if r.Body != nil {
+		if body, err = io.ReadAll(r.Body); err != nil {
+			return
+		}
+		r.Body.Close()
+	}
+	req := VCRRequest{
+		Method: r.Method,
+		URL:    vcrURL(r.URL),
+		Target: r.Header.Get("X-Amz-Target"),
+	}
+	req.Body, req.Base64 = vcrBody(body)
+
+	if c.opts.Mode == VCRReplay {
+		return c.replay(r, req)
+	}
+
+	// Send request and record response
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	if resp, err = c.next.Do(r); err != nil {
+		return
+	}
+	data, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+	i := VCRInteraction{Request: req, Response: VCRResponse{
+		Status: resp.StatusCode,
+		Header: resp.Header.Clone(),
+	}}
+	for _, h := range vcrSkipHeaders {
+		i.Response.Header.Del(h)
+	}
+	i.Response.Body, i.Response.Base64 = vcrBody(data)
+	if !i.Response.Base64 {
+		i.Response.Body = vcrRedact(i.Response.Body)
+	}
+	if c.opts.Sanitize != nil {
+		c.opts.Sanitize(&i)
+	}
+	err = c.record(i)
+
+	return
+}
+
+// record adds the interaction to the fixture and saves it.
+func (v *vcr) record(i VCRInteraction) (err error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.interactions = append(v.interactions, i)
+	data, err := json.MarshalIndent(struct {
+		Interactions []VCRInteraction `json:"interactions"`
+	}{v.interactions}, "", "  ")
+	if err != nil {
+		return
+	}
+	if err = os.MkdirAll(filepath.Dir(v.path), 0o755); err != nil {
+		return
+	}
+	return os.WriteFile(v.path, data, 0o644)
+}
+
+// replay returns the recorded response of the request.
+func (v *vcr) replay(r *http.Request, req VCRRequest) (resp *http.Response,
+	err error) {
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	// Load fixture
+	if !v.loaded {
+		v.loaded = true
+		var data []byte
+		var fixture struct{ Interactions []VCRInteraction }
+		if data, v.err = os.ReadFile(v.path); v.err == nil {
+			v.err = json.Unmarshal(data, &fixture)
+		}
+		v.interactions = fixture.Interactions
+		v.replayed = make([]bool, len(v.interactions))
+	}
+	if v.err != nil {
+		return nil, vcrError{fmt.Errorf("vcr fixture: %w", v.err)}
+	}
+
+	// Find the same request, or the same request with other body
+	found := -1
+	for n, i := range v.interactions {
+		if v.replayed[n] || i.Request.Method != req.Method ||
+			i.Request.URL != req.URL || i.Request.Target != req.Target {
+			continue
+		}
+		if i.Request.Body == req.Body {
+			found = n
+			break
+		}
+		if found < 0 {
+			found = n
+		}
+	}
+	if found < 0 {
+		return nil, vcrError{fmt.Errorf("%w: %s %s %s", ErrVCRNotRecorded,
+			req.Method, req.URL, req.Target)}
+	}
+	v.replayed[found] = true
+
+	recorded := v.interactions[found].Response
+	body := []byte(recorded.Body)
+	if recorded.Base64 {
+		body, err = base64.StdEncoding.DecodeString(recorded.Body)
+		if err != nil {
+			return nil, vcrError{fmt.Errorf("vcr fixture: %w", err)}
+		}
+	}
+	header := recorded.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	resp = &http.Response{
+		StatusCode:    recorded.Status,
+		Status:        http.StatusText(recorded.Status),
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       r,
+	}
+	return
+}
+
+// vcrURL returns the request URL without the signature query parameters.
+func vcrURL(u *url.URL) string {
+	c := *u
+	query := c.Query()
+	for name := range query {
+		if strings.HasPrefix(name, "X-Amz-") {
+			query.Del(name)
+		}
+	}
+	c.RawQuery = query.Encode()
+	return c.String()
+}
+
+// vcrRedact replaces the credentials and tokens in the body by "REDACTED".
+func vcrRedact(body string) string {
+	return vcrSecrets.ReplaceAllString(body, "${1}${4}REDACTED${3}${6}")
+}
+
+// vcrBody returns the body as the string, or as the base64 string if it is
+// not valid UTF-8.
+func vcrBody(data []byte) (body string, base64Body bool) {
+	if utf8.Valid(data) {
+		return string(data), false
+	}
+	return base64.StdEncoding.EncodeToString(data), true
+}
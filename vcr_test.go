@@ -0,0 +1,74 @@
+package aws
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+// TestVCR checks the Cognito users pages recorded from the FakeCognito and
+// replayed offline
+func TestVCR(t *testing.T) {
+
+	fake := NewFakeCognito()
+	for _, name := range []string{"a1", "a2", "a3", "b1"} {
+		fake.AddUser("pool", name, map[string]string{"name": name})
+	}
+	users := func(a *Aws) (names []string, err error) {
+		var pagination *string
+		for {
+			var list []UserType
+			list, pagination, err = a.Cognito.List("pool", 2,
+				`name ^= "a"`, pagination)
+			if err != nil {
+				return
+			}
+			for _, user := range list {
+				names = append(names, aws.ToString(user.Username))
+			}
+			if pagination == nil {
+				return
+			}
+		}
+	}
+
+	// Record
+	path := filepath.Join(t.TempDir(), "vcr", "cognito.json")
+	a := NewFromConfig(fake.Config(), WithVCR(path,
+		VCROptions{Mode: VCRRecord, Sanitize: func(i *VCRInteraction) {
+			i.Response.Body = strings.ReplaceAll(i.Response.Body,
+				"00000000-0000-4000", "sanitized")
+		}}))
+	recorded, err := users(a)
+	if err != nil || strings.Join(recorded, ",") != "a1,a2,a3" {
+		t.Fatal("record:", recorded, err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || !strings.Contains(string(data), "sanitized") ||
+		strings.Contains(string(data), "00000000-0000-4000") {
+		t.Fatal("fixture not sanitized:", string(data), err)
+	}
+
+	// Replay without the backend and credentials
+	a = NewFromConfig(aws.Config{Region: "us-east-1"}, WithVCR(path))
+	replayed, err := users(a)
+	if err != nil || !slices.Equal(recorded, replayed) {
+		t.Fatal("replay:", replayed, err)
+	}
+	if _, err = users(a); !errors.Is(err, ErrVCRNotRecorded) {
+		t.Error("not recorded:", err)
+	}
+
+	// Secrets are redacted
+	body := vcrRedact(`<SessionToken>t</SessionToken>` +
+		`{"AccessToken": "a\"b","IdToken":"i"}`)
+	if body != `<SessionToken>REDACTED</SessionToken>`+
+		`{"AccessToken": "REDACTED","IdToken":"REDACTED"}` {
+		t.Error("secrets not redacted:", body)
+	}
+}
@@ -0,0 +1,412 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+const (
+	// xrayDaemonAddress is the default X-Ray daemon UDP address.
+	xrayDaemonAddress = "127.0.0.1:2000"
+
+	// xrayDaemonHeader is the header of the documents sent to the X-Ray
+	// daemon.
+	xrayDaemonHeader = `{"format":"json","version":1}` + "\n"
+
+	// xrayTraceHeader is the HTTP header of the X-Ray trace.
+	xrayTraceHeader = "X-Amzn-Trace-Id"
+
+	// xrayLambdaTraceEnv is the environment variable with the Lambda
+	// invocation trace header.
+	xrayLambdaTraceEnv = "_X_AMZN_TRACE_ID"
+
+	// xrayLambdaTraceKey is the context key of the trace header set by the
+	// aws-lambda-go runtime.
+	xrayLambdaTraceKey = "x-amzn-trace-id"
+)
+
+// XRayOptions are the optional parameters of WithXRay.
+type XRayOptions struct {
+	// DaemonAddress is the X-Ray daemon UDP address. Default is the
+	// AWS_XRAY_DAEMON_ADDRESS environment variable set in Lambda, or
+	// "127.0.0.1:2000".
+	DaemonAddress string
+
+	// Emit replaces sending the segment documents to the X-Ray daemon, for
+	// example to log or test them.
+	Emit func(document []byte)
+}
+
+// WithXRay traces the AWS calls of the package clients with X-Ray. Every
+// call made within the trace opens the subsegment named by the AWS service
+// with the operation, region, request ID and error, and passes the trace
+// header to the service. The trace is the XRaySegment context, the Lambda
+// invocation context or the Lambda _X_AMZN_TRACE_ID environment variable.
+// The package functions without ctx parameter use the Lambda environment
+// variable, so their calls are traced only in Lambda. The calls outside of
+// the trace and the not sampled traces are not emitted.
+//
+// Parameters:
+//   - opts: The optional X-Ray parameters.
+func WithXRay(opts ...XRayOptions) Option {
+	var o XRayOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	e := newXRayEmitter(o)
+	return func(o *options) { o.xray = e }
+}
+
+// XRaySegment is the X-Ray segment or subsegment, see Aws XRaySegment.
+type XRaySegment struct {
+	mu      sync.Mutex
+	emitter *xrayEmitter
+	sampled bool
+	doc     xrayDocument
+}
+
+// xrayDocument is the X-Ray segment document.
+type xrayDocument struct {
+	Name        string                    `json:"name"`
+	ID          string                    `json:"id"`
+	TraceID     string                    `json:"trace_id"`
+	ParentID    string                    `json:"parent_id,omitempty"`
+	Type        string                    `json:"type,omitempty"`
+	Namespace   string                    `json:"namespace,omitempty"`
+	StartTime   float64                   `json:"start_time"`
+	EndTime     float64                   `json:"end_time"`
+	Error       bool                      `json:"error,omitempty"`
+	Throttle    bool                      `json:"throttle,omitempty"`
+	Fault       bool                      `json:"fault,omitempty"`
+	Cause       *xrayCause                `json:"cause,omitempty"`
+	HTTP        *xrayHTTP                 `json:"http,omitempty"`
+	AWS         map[string]any            `json:"aws,omitempty"`
+	Annotations map[string]any            `json:"annotations,omitempty"`
+	Metadata    map[string]map[string]any `json:"metadata,omitempty"`
+}
+
+// xrayCause is the X-Ray segment error cause.
+type xrayCause struct {
+	Exceptions []xrayException `json:"exceptions"`
+}
+
+// xrayException is the X-Ray segment exception.
+type xrayException struct {
+	ID      string `json:"id"`
+	Type    string `json:"type,omitempty"`
+	Message string `json:"message"`
+}
+
+// xrayHTTP is the X-Ray segment HTTP response.
+type xrayHTTP struct {
+	Response struct {
+		Status int `json:"status"`
+	} `json:"response"`
+}
+
+// xrayContextKey is the context key of the current XRaySegment.
+type xrayContextKey struct{}
+
+// XRaySegment begins the custom X-Ray segment. The segment is the
+// subsegment of the ctx trace, see WithXRay, or the new trace segment if ctx
+// is not traced. Pass the returned context to the package functions with
+// ctx parameter to trace their calls as the segment subsegments, and Close
+// the segment when done. The segment is not emitted if the Aws is created
+// without WithXRay.
+//
+// Parameters:
+//   - ctx: The parent context.
+//   - name: The segment name.
+//
+// Returns:
+//   - segmentCtx: The context with the segment.
+//   - segment: The segment.
+func (a Aws) XRaySegment(ctx context.Context, name string) (
+	segmentCtx context.Context, segment *XRaySegment) {
+
+	emitter := newOptions(a.opts...).xray
+	segment = newXRaySegment(ctx, emitter, name)
+	if segment == nil {
+		segment = &XRaySegment{
+			emitter: emitter,
+			sampled: true,
+			doc: xrayDocument{
+				Name:      name,
+				ID:        xrayID(),
+				TraceID:   xrayTraceID(),
+				StartTime: xrayTime(time.Now()),
+			},
+		}
+	}
+	segmentCtx = context.WithValue(ctx, xrayContextKey{}, segment)
+	return
+}
+
+// TraceHeader returns the X-Amzn-Trace-Id header to pass the trace to the
+// downstream services.
+func (s *XRaySegment) TraceHeader() string {
+	sampled := "0"
+	if s.sampled {
+		sampled = "1"
+	}
+	return fmt.Sprintf("Root=%s;Parent=%s;Sampled=%s", s.doc.TraceID,
+		s.doc.ID, sampled)
+}
+
+// AddAnnotation adds the indexed annotation used in the trace filter
+// expressions. The value must be the string, number or bool.
+func (s *XRaySegment) AddAnnotation(key string, value any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.doc.Annotations == nil {
+		s.doc.Annotations = make(map[string]any)
+	}
+	s.doc.Annotations[key] = value
+}
+
+// AddMetadata adds the not indexed metadata value in the default namespace.
+func (s *XRaySegment) AddMetadata(key string, value any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.doc.Metadata == nil {
+		s.doc.Metadata = map[string]map[string]any{"default": {}}
+	}
+	s.doc.Metadata["default"][key] = value
+}
+
+// Close closes the segment and emits it. The not nil err marks the segment
+// as failed, the server errors are marked as faults.
+func (s *XRaySegment) Close(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.doc.EndTime = xrayTime(time.Now())
+	if err != nil {
+		s.doc.Error = !s.doc.Fault
+		s.doc.Throttle = errors.Is(err, ErrThrottled)
+		s.doc.Cause = &xrayCause{Exceptions: []xrayException{
+			{ID: xrayID(), Type: fmt.Sprintf("%T", err),
+				Message: err.Error()},
+		}}
+	}
+	if !s.sampled || s.emitter == nil {
+		return
+	}
+	s.emitter.emit(s.doc)
+}
+
+// newXRaySegment returns the new subsegment of the ctx trace, or nil if the
+// ctx is not traced.
+func newXRaySegment(ctx context.Context, emitter *xrayEmitter,
+	name string) *XRaySegment {
+
+	root, parent, sampled := xrayParent(ctx)
+	if root == "" || parent == "" {
+		return nil
+	}
+	return &XRaySegment{
+		emitter: emitter,
+		sampled: sampled,
+		doc: xrayDocument{
+			Name:      name,
+			ID:        xrayID(),
+			TraceID:   root,
+			ParentID:  parent,
+			Type:      "subsegment",
+			StartTime: xrayTime(time.Now()),
+		},
+	}
+}
+
+// xrayParent returns the trace ID, parent segment ID and sampling decision
+// of the ctx segment, Lambda invocation context or Lambda environment.
+func xrayParent(ctx context.Context) (root, parent string, sampled bool) {
+	if s, ok := ctx.Value(xrayContextKey{}).(*XRaySegment); ok {
+		return s.doc.TraceID, s.doc.ID, s.sampled
+	}
+	header, _ := ctx.Value(xrayLambdaTraceKey).(string)
+	if header == "" {
+		header = os.Getenv(xrayLambdaTraceEnv)
+	}
+	sampled = true
+	for _, field := range strings.Split(header, ";") {
+		key, value, _ := strings.Cut(strings.TrimSpace(field), "=")
+		switch key {
+		case "Root":
+			root = value
+		case "Parent":
+			parent = value
+		case "Sampled":
+			sampled = value != "0"
+		}
+	}
+	return
+}
+
+// withXRay returns the AWS config with the middleware which traces the
+// calls of all clients created from it with X-Ray subsegments and passes the
+// trace header to the services.
+func withXRay(cfg aws.Config, o options) aws.Config {
+	if o.xray == nil {
+		return cfg
+	}
+	cfg.APIOptions = append(slices.Clip(cfg.APIOptions),
+		func(stack *middleware.Stack) error {
+			err := stack.Initialize.Add(middleware.InitializeMiddlewareFunc(
+				"XRaySubsegment",
+				func(ctx context.Context, in middleware.InitializeInput,
+					next middleware.InitializeHandler) (
+					out middleware.InitializeOutput, md middleware.Metadata,
+					err error) {
+
+					segment := newXRaySegment(ctx, o.xray,
+						middleware.GetServiceID(ctx))
+					if segment == nil {
+						return next.HandleInitialize(ctx, in)
+					}
+					segment.doc.Namespace = "aws"
+					ctx = context.WithValue(ctx, xrayContextKey{}, segment)
+
+					out, md, err = next.HandleInitialize(ctx, in)
+					segment.response(ctx, md, err)
+					segment.Close(err)
+					return
+				},
+			), middleware.Before)
+			if err != nil {
+				return err
+			}
+			return stack.Build.Add(middleware.BuildMiddlewareFunc(
+				"XRayTraceHeader",
+				func(ctx context.Context, in middleware.BuildInput,
+					next middleware.BuildHandler) (
+					middleware.BuildOutput, middleware.Metadata, error) {
+
+					s, ok := ctx.Value(xrayContextKey{}).(*XRaySegment)
+					req, isHTTP := in.Request.(*smithyhttp.Request)
+					if ok && isHTTP {
+						req.Header.Set(xrayTraceHeader, s.TraceHeader())
+					}
+					return next.HandleBuild(ctx, in)
+				},
+			), middleware.After)
+		},
+	)
+	return cfg
+}
+
+// response adds the AWS call operation, request ID and HTTP status to the
+// subsegment.
+func (s *XRaySegment) response(ctx context.Context, md middleware.Metadata,
+	err error) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.doc.AWS = map[string]any{
+		"operation": middleware.GetOperationName(ctx),
+		"region":    awsmiddleware.GetRegion(ctx),
+	}
+	if id, ok := awsmiddleware.GetRequestIDMetadata(md); ok {
+		s.doc.AWS["request_id"] = id
+	}
+
+	status := 0
+	raw := awsmiddleware.GetRawResponse(md)
+	var respErr *smithyhttp.ResponseError
+	if errors.As(err, &respErr) {
+		status = respErr.HTTPStatusCode()
+	} else if r, ok := raw.(*smithyhttp.Response); ok {
+		status = r.StatusCode
+	}
+	if status > 0 {
+		s.doc.HTTP = &xrayHTTP{}
+		s.doc.HTTP.Response.Status = status
+	}
+	s.doc.Fault = status >= 500
+}
+
+// xrayEmitter sends the segment documents to the X-Ray daemon.
+type xrayEmitter struct {
+	mu   sync.Mutex
+	addr string
+	conn net.Conn
+	emit func(doc xrayDocument)
+}
+
+// newXRayEmitter creates the X-Ray emitter.
+func newXRayEmitter(o XRayOptions) (e *xrayEmitter) {
+	e = &xrayEmitter{addr: o.DaemonAddress}
+	if e.addr == "" {
+		e.addr = xrayDaemonAddr(os.Getenv("AWS_XRAY_DAEMON_ADDRESS"))
+	}
+	e.emit = func(doc xrayDocument) {
+		data, err := json.Marshal(doc)
+		if err != nil {
+			return
+		}
+		if o.Emit != nil {
+			o.Emit(data)
+			return
+		}
+		e.send(data)
+	}
+	return
+}
+
+// send sends the segment document to the X-Ray daemon. The errors are
+// ignored, the tracing must not break the traced calls.
+func (e *xrayEmitter) send(data []byte) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.conn == nil {
+		conn, err := net.Dial("udp", e.addr)
+		if err != nil {
+			return
+		}
+		e.conn = conn
+	}
+	e.conn.Write(append([]byte(xrayDaemonHeader), data...))
+}
+
+// xrayDaemonAddr returns the UDP address of the X-Ray daemon address
+// variable in the "host:port" or "udp:host:port tcp:host:port" format.
+func xrayDaemonAddr(env string) string {
+	for _, addr := range strings.Fields(env) {
+		if udp, ok := strings.CutPrefix(addr, "udp:"); ok {
+			return udp
+		}
+		if !strings.HasPrefix(addr, "tcp:") {
+			return addr
+		}
+	}
+	return xrayDaemonAddress
+}
+
+// xrayID returns the new 64 bit segment ID.
+func xrayID() string {
+	return fmt.Sprintf("%016x", rand.Uint64())
+}
+
+// xrayTraceID returns the new trace ID.
+func xrayTraceID() string {
+	return fmt.Sprintf("1-%08x-%08x%016x", time.Now().Unix(), rand.Uint32(),
+		rand.Uint64())
+}
+
+// xrayTime returns the X-Ray segment time in seconds.
+func xrayTime(t time.Time) float64 {
+	return float64(t.UnixMicro()) / 1e6
+}
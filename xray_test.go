@@ -0,0 +1,119 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// xrayHTTPClient returns the response with status and records the X-Ray
+// trace headers of the requests.
+type xrayHTTPClient struct {
+	status  int
+	body    string
+	headers []string
+}
+
+func (c *xrayHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	c.headers = append(c.headers, req.Header.Get(xrayTraceHeader))
+	return &http.Response{
+		StatusCode: c.status,
+		Header:     http.Header{"X-Amzn-Requestid": {"req-1"}},
+		Body:       io.NopCloser(strings.NewReader(c.body)),
+		Request:    req,
+	}, nil
+}
+
+// TestXRaySubsegments checks X-Ray subsegments of the Lambda and custom
+// segment traced calls and trace header propagation
+func TestXRaySubsegments(t *testing.T) {
+
+	var docs []map[string]any
+	emit := func(document []byte) {
+		var doc map[string]any
+		if err := json.Unmarshal(document, &doc); err != nil {
+			t.Error("wrong document:", err)
+		}
+		docs = append(docs, doc)
+	}
+	client := &xrayHTTPClient{status: http.StatusOK,
+		body: `{"Parameter":{"Name":"p","Value":"v"}}`}
+	cfg := newErrorTestAws(http.StatusOK, "").Config()
+	cfg.HTTPClient = client
+	cfg.RetryMaxAttempts = 1
+	a := NewFromConfig(cfg, WithXRay(XRayOptions{Emit: emit}))
+
+	// Not traced call
+	t.Setenv(xrayLambdaTraceEnv, "")
+	if _, err := a.SSM.GetParameter("p"); err != nil || len(docs) != 0 ||
+		client.headers[0] != "" {
+		t.Fatal("not traced call is emitted:", docs, err)
+	}
+
+	// Lambda invocation trace
+	const root = "1-5759e988-bd862e3fe1be46a994272793"
+	t.Setenv(xrayLambdaTraceEnv, "Root="+root+
+		";Parent=53995c3f42cd8ad8;Sampled=1")
+	if _, err := a.SSM.GetParameter("p"); err != nil || len(docs) != 1 {
+		t.Fatal("traced call is not emitted:", docs, err)
+	}
+	doc := docs[0]
+	awsDoc, _ := doc["aws"].(map[string]any)
+	if doc["name"] != "SSM" || doc["trace_id"] != root ||
+		doc["parent_id"] != "53995c3f42cd8ad8" ||
+		doc["type"] != "subsegment" || awsDoc["operation"] != "GetParameter" ||
+		awsDoc["request_id"] != "req-1" {
+		t.Error("wrong subsegment:", doc)
+	}
+	if !strings.Contains(client.headers[1], "Root="+root+";Parent="+
+		doc["id"].(string)) {
+		t.Error("wrong trace header:", client.headers[1])
+	}
+
+	// Custom segment with the failed call subsegment
+	t.Setenv(xrayLambdaTraceEnv, "")
+	client.status = http.StatusInternalServerError
+	client.body = `{"__type":"InternalServerError","message":"boom"}`
+	ctx, segment := a.XRaySegment(context.Background(), "job")
+	segment.AddAnnotation("tenant", "t1")
+	_, err := a.SQS.Client.ListQueues(ctx, nil)
+	segment.Close(err)
+	if err == nil || len(docs) != 3 {
+		t.Fatal("wrong custom segment documents:", docs, err)
+	}
+	sub, seg := docs[1], docs[2]
+	if sub["parent_id"] != seg["id"] || sub["fault"] != true ||
+		sub["error"] != nil || seg["type"] != nil ||
+		seg["annotations"].(map[string]any)["tenant"] != "t1" ||
+		seg["trace_id"] == root {
+		t.Error("wrong custom segment:", sub, seg)
+	}
+
+	// Not sampled trace
+	t.Setenv(xrayLambdaTraceEnv, "Root="+root+
+		";Parent=53995c3f42cd8ad8;Sampled=0")
+	client.status = http.StatusOK
+	client.body = `{"Parameter":{"Name":"p","Value":"v"}}`
+	if _, err := a.SSM.GetParameter("p"); err != nil || len(docs) != 3 ||
+		!strings.HasSuffix(client.headers[len(client.headers)-1],
+			"Sampled=0") {
+		t.Error("not sampled call is emitted:", docs, err)
+	}
+}
+
+// TestXRayDaemonAddr checks X-Ray daemon address parsing
+func TestXRayDaemonAddr(t *testing.T) {
+	for env, addr := range map[string]string{
+		"":                                    xrayDaemonAddress,
+		"169.254.79.129:2000":                 "169.254.79.129:2000",
+		"tcp:10.0.0.1:2000 udp:10.0.0.2:2000": "10.0.0.2:2000",
+		"udp:10.0.0.3:2000":                   "10.0.0.3:2000",
+	} {
+		if got := xrayDaemonAddr(env); got != addr {
+			t.Errorf("wrong address of %q: %s", env, got)
+		}
+	}
+}